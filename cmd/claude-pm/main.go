@@ -0,0 +1,23 @@
+// ABOUTME: Compatibility entry point for the old claude-pm binary name
+// ABOUTME: Runs the same command tree as claudeup so behavior never diverges
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/claudeup/claudeup/internal/commands"
+)
+
+var version = "dev" // Injected at build time via -ldflags
+
+func main() {
+	commands.SetVersion(version)
+	commands.SetCommandName("claude-pm")
+	commands.SetRenameNote("Note: claude-pm has been renamed to claudeup; this alias will be removed in a future release.")
+
+	if err := commands.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -38,6 +38,11 @@ case "$ARCH" in
     aarch64) ARCH="arm64" ;;
 esac
 
+# A shell under Rosetta reports x86_64 on Apple silicon; install the native build
+if [[ "$OS" == "darwin" && "$ARCH" == "amd64" && "$(sysctl -n sysctl.proc_translated 2>/dev/null)" == "1" ]]; then
+    ARCH="arm64"
+fi
+
 # Check supported platform
 if [[ "$OS" != "linux" && "$OS" != "darwin" ]]; then
     echo "This installer is for macOS and Linux."
@@ -54,59 +59,76 @@ if [[ -z "$VERSION" ]]; then
     fi
 fi
 
-BINARY_URL="https://github.com/$REPO/releases/download/$VERSION/${BINARY_NAME}-${OS}-${ARCH}"
-
 # Check for existing installation
 EXISTING_VERSION=""
 if command -v "$BINARY_NAME" &> /dev/null; then
     EXISTING_VERSION=$("$BINARY_NAME" --version 2>/dev/null | head -1 || echo "")
 fi
 
+# Someone upgrading from the old name also gets the claude-pm alias, so scripts keep working
+OLD_BINARY_NAME="claude-pm"
+UPDATE_ALIAS=false
+if command -v "$OLD_BINARY_NAME" &> /dev/null; then
+    UPDATE_ALIAS=true
+fi
+
 # Create temp directory
 TMP_DIR=$(mktemp -d)
 trap 'rm -rf $TMP_DIR' EXIT
 
-# Download binary
-echo "Downloading $BINARY_NAME $VERSION for $OS-$ARCH..."
-if ! curl -fsSL "$BINARY_URL" -o "$TMP_DIR/$BINARY_NAME"; then
-    echo "Failed to download $BINARY_NAME."
-    echo "The release may not exist for your platform: $OS-$ARCH"
-    echo "Check available releases at: https://github.com/$REPO/releases"
-    exit 1
-fi
-
-chmod +x "$TMP_DIR/$BINARY_NAME"
-
-# Verify checksum if requested
 if [[ "$VERIFY" == true ]]; then
-    echo "Verifying checksum..."
     CHECKSUMS_URL="https://github.com/$REPO/releases/download/$VERSION/checksums.txt"
     if ! curl -fsSL "$CHECKSUMS_URL" -o "$TMP_DIR/checksums.txt"; then
         echo "Failed to download checksums file."
         exit 1
     fi
+fi
 
-    EXPECTED=$(grep "${BINARY_NAME}-${OS}-${ARCH}$" "$TMP_DIR/checksums.txt" | awk '{print $1}')
+# download fetches a release binary into the temp directory, checking its checksum if requested
+download() {
+    local name="$1"
+    local url="https://github.com/$REPO/releases/download/$VERSION/${name}-${OS}-${ARCH}"
 
-    # Use shasum on macOS, sha256sum on Linux
-    if command -v sha256sum &> /dev/null; then
-        ACTUAL=$(sha256sum "$TMP_DIR/$BINARY_NAME" | awk '{print $1}')
-    elif command -v shasum &> /dev/null; then
-        ACTUAL=$(shasum -a 256 "$TMP_DIR/$BINARY_NAME" | awk '{print $1}')
-    else
-        echo "Neither sha256sum nor shasum found. Cannot verify checksum."
+    echo "Downloading $name $VERSION for $OS-$ARCH..."
+    if ! curl -fsSL "$url" -o "$TMP_DIR/$name"; then
+        echo "Failed to download $name."
+        echo "The release may not exist for your platform: $OS-$ARCH"
+        echo "Check available releases at: https://github.com/$REPO/releases"
         exit 1
     fi
 
-    if [[ "$EXPECTED" != "$ACTUAL" ]]; then
-        echo "Checksum verification failed!"
-        echo "Expected: $EXPECTED"
-        echo "Got:      $ACTUAL"
-        echo "This could indicate a corrupted download or tampering."
-        echo "Aborting installation."
-        exit 1
+    chmod +x "$TMP_DIR/$name"
+
+    if [[ "$VERIFY" == true ]]; then
+        echo "Verifying checksum..."
+        local expected actual
+        expected=$(grep "${name}-${OS}-${ARCH}$" "$TMP_DIR/checksums.txt" | awk '{print $1}')
+
+        # Use shasum on macOS, sha256sum on Linux
+        if command -v sha256sum &> /dev/null; then
+            actual=$(sha256sum "$TMP_DIR/$name" | awk '{print $1}')
+        elif command -v shasum &> /dev/null; then
+            actual=$(shasum -a 256 "$TMP_DIR/$name" | awk '{print $1}')
+        else
+            echo "Neither sha256sum nor shasum found. Cannot verify checksum."
+            exit 1
+        fi
+
+        if [[ "$expected" != "$actual" ]]; then
+            echo "Checksum verification failed!"
+            echo "Expected: $expected"
+            echo "Got:      $actual"
+            echo "This could indicate a corrupted download or tampering."
+            echo "Aborting installation."
+            exit 1
+        fi
+        echo "Checksum verified."
     fi
-    echo "Checksum verified."
+}
+
+download "$BINARY_NAME"
+if [[ "$UPDATE_ALIAS" == true ]]; then
+    download "$OLD_BINARY_NAME"
 fi
 
 # Determine install location
@@ -140,6 +162,14 @@ if [[ "$USED_SUDO" == false ]]; then
     mv "$TMP_DIR/$BINARY_NAME" "$INSTALL_DIR/$BINARY_NAME"
 fi
 
+if [[ "$UPDATE_ALIAS" == true ]]; then
+    if [[ "$USED_SUDO" == true ]]; then
+        sudo mv "$TMP_DIR/$OLD_BINARY_NAME" "$INSTALL_DIR/$OLD_BINARY_NAME"
+    else
+        mv "$TMP_DIR/$OLD_BINARY_NAME" "$INSTALL_DIR/$OLD_BINARY_NAME"
+    fi
+fi
+
 # Report result
 NEW_VERSION=$("$INSTALL_DIR/$BINARY_NAME" --version 2>/dev/null | head -1 || echo "$VERSION")
 
@@ -153,6 +183,10 @@ else
     echo "Installed $BINARY_NAME $NEW_VERSION to $INSTALL_DIR/$BINARY_NAME"
 fi
 
+if [[ "$UPDATE_ALIAS" == true ]]; then
+    echo "Updated the $OLD_BINARY_NAME alias in $INSTALL_DIR; it will be removed in a future release"
+fi
+
 # Check PATH
 if [[ "$INSTALL_DIR" == "$HOME/.local/bin" ]]; then
     if [[ ":$PATH:" != *":$HOME/.local/bin:"* ]]; then
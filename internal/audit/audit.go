@@ -0,0 +1,264 @@
+// ABOUTME: Inspects a plugin's contents in its marketplace clone before installation
+// ABOUTME: Lists hooks, scripts, MCP servers, and tool permissions, and flags risky patterns
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/marketplace"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// Severity of a finding
+const (
+	SeverityHigh = "high"
+	SeverityWarn = "warn"
+)
+
+// Report is the result of auditing one plugin
+type Report struct {
+	Plugin      string      `json:"plugin"`
+	Dir         string      `json:"dir"`
+	Hooks       []string    `json:"hooks"`
+	Scripts     []string    `json:"scripts"`
+	MCPServers  []MCPServer `json:"mcpServers"`
+	Permissions []string    `json:"permissions"`
+	Findings    []Finding   `json:"findings"`
+}
+
+// MCPServer is an MCP server the plugin will register
+type MCPServer struct {
+	Name    string   `json:"name"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// Finding is a risky pattern found in the plugin's files
+type Finding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Text     string `json:"text"`
+}
+
+// Risky reports whether the audit found any high-severity patterns
+func (r *Report) Risky() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeverityHigh {
+			return true
+		}
+	}
+	return false
+}
+
+type rule struct {
+	name     string
+	severity string
+	pattern  *regexp.Regexp
+}
+
+// rules are checked line by line against hooks, scripts, and manifests
+var rules = []rule{
+	{"pipe-to-shell", SeverityHigh, regexp.MustCompile(`(curl|wget)\b[^|]*\|\s*(sudo\s+)?(ba|z)?sh\b`)},
+	{"decode-and-exec", SeverityHigh, regexp.MustCompile(`base64\s+(-d|--decode)[^|]*\|\s*(ba|z)?sh\b`)},
+	{"eval-remote", SeverityHigh, regexp.MustCompile(`eval\s+"?\$\((curl|wget)`)},
+	{"recursive-delete-home", SeverityHigh, regexp.MustCompile(`rm\s+-[a-zA-Z]*r[a-zA-Z]*f?\s+(~|\$HOME|/)(\s|/?$|/\*)`)},
+	{"sudo", SeverityWarn, regexp.MustCompile(`\bsudo\s+`)},
+	{"write-outside-plugin", SeverityWarn, regexp.MustCompile(`>>?\s*(~|\$HOME|/etc|/usr)/`)},
+	{"shell-profile-edit", SeverityHigh, regexp.MustCompile(`\.(bashrc|zshrc|profile|bash_profile)\b.*>>|>>\s*\S*\.(bashrc|zshrc|profile|bash_profile)\b`)},
+	{"world-writable", SeverityWarn, regexp.MustCompile(`chmod\s+(-R\s+)?777\b`)},
+}
+
+var scriptExtensions = map[string]bool{
+	".sh": true, ".bash": true, ".zsh": true, ".py": true, ".js": true, ".mjs": true, ".ts": true, ".rb": true, ".pl": true,
+}
+
+var allowedToolsLine = regexp.MustCompile(`^(allowed-tools|allowedTools|tools):\s*(.+)$`)
+
+// Plugin audits pluginName ("name@marketplace") using the marketplace clone
+// recorded in known_marketplaces.json
+func Plugin(claudeDir, pluginName string) (*Report, error) {
+	dir, err := LocatePlugin(claudeDir, pluginName)
+	if err != nil {
+		return nil, err
+	}
+	return Dir(pluginName, dir)
+}
+
+// LocatePlugin finds a plugin's source directory inside its marketplace clone
+func LocatePlugin(claudeDir, pluginName string) (string, error) {
+	base, marketplaceName, ok := strings.Cut(pluginName, "@")
+	if !ok || base == "" || marketplaceName == "" {
+		return "", fmt.Errorf("plugin must be given as name@marketplace, got %q", pluginName)
+	}
+
+	marketplaces, err := state.LoadMarketplaces(claudeDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to load marketplaces: %w", err)
+	}
+	meta, ok := marketplaces[marketplaceName]
+	if !ok || meta.InstallLocation == "" {
+		return "", fmt.Errorf("marketplace %q is not installed", marketplaceName)
+	}
+	if dir, ok := marketplace.PluginDir(meta.InstallLocation, base); ok {
+		return dir, nil
+	}
+
+	return "", fmt.Errorf("plugin %q not found in marketplace %q", base, marketplaceName)
+}
+
+// Dir audits a plugin directory
+func Dir(pluginName, dir string) (*Report, error) {
+	report := &Report{Plugin: pluginName, Dir: dir}
+
+	// Manifest: inline MCP servers and hooks
+	if data, err := os.ReadFile(filepath.Join(dir, ".claude-plugin", "plugin.json")); err == nil {
+		var manifest struct {
+			MCPServers map[string]MCPServer `json:"mcpServers"`
+			Hooks      json.RawMessage      `json:"hooks"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("invalid plugin.json: %w", err)
+		}
+		addMCPServers(report, manifest.MCPServers)
+		if len(manifest.Hooks) > 0 && string(manifest.Hooks) != "null" {
+			report.Hooks = append(report.Hooks, ".claude-plugin/plugin.json")
+		}
+	}
+
+	// Standalone .mcp.json
+	if data, err := os.ReadFile(filepath.Join(dir, ".mcp.json")); err == nil {
+		var mcpFile struct {
+			MCPServers map[string]MCPServer `json:"mcpServers"`
+		}
+		if json.Unmarshal(data, &mcpFile) == nil {
+			addMCPServers(report, mcpFile.MCPServers)
+		}
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || d.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, _ := filepath.Rel(dir, path)
+		ext := filepath.Ext(path)
+
+		if strings.HasPrefix(rel, "hooks"+string(filepath.Separator)) && ext == ".json" {
+			report.Hooks = append(report.Hooks, rel)
+		}
+		if scriptExtensions[ext] || hasShebang(path) {
+			report.Scripts = append(report.Scripts, rel)
+		}
+		if ext == ".md" {
+			report.Permissions = append(report.Permissions, allowedTools(path, rel)...)
+		}
+		if scriptExtensions[ext] || ext == ".json" || hasShebang(path) {
+			report.Findings = append(report.Findings, scanFile(path, rel)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(report.Hooks)
+	sort.Strings(report.Scripts)
+	sort.Strings(report.Permissions)
+	return report, nil
+}
+
+func addMCPServers(report *Report, servers map[string]MCPServer) {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := servers[name]
+		s.Name = name
+		report.MCPServers = append(report.MCPServers, s)
+	}
+}
+
+func scanFile(path, rel string) []Finding {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var findings []Finding
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		for _, r := range rules {
+			if r.pattern.MatchString(text) {
+				findings = append(findings, Finding{
+					File:     rel,
+					Line:     line,
+					Rule:     r.name,
+					Severity: r.severity,
+					Text:     strings.TrimSpace(text),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// allowedTools returns the tool permissions declared in a markdown file's frontmatter
+func allowedTools(path, rel string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "---" {
+		return nil
+	}
+
+	var perms []string
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "---" {
+			break
+		}
+		if m := allowedToolsLine.FindStringSubmatch(text); m != nil {
+			perms = append(perms, fmt.Sprintf("%s: %s", rel, m[2]))
+		}
+	}
+	return perms
+}
+
+func hasShebang(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, 2)
+	n, _ := f.Read(buf)
+	return n == 2 && string(buf) == "#!"
+}
@@ -0,0 +1,176 @@
+// ABOUTME: Finds slash commands, agents, and MCP servers defined by more than one plugin
+// ABOUTME: Clashing names only show up inside Claude Code as the wrong command or agent running
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kinds of definitions that can conflict
+const (
+	KindCommand = "command"
+	KindAgent   = "agent"
+	KindMCP     = "mcp"
+)
+
+// Definitions are the names one source adds to Claude Code: a plugin, a
+// profile's own MCP servers, or the user's ~/.claude commands and agents
+type Definitions struct {
+	Source     string
+	Commands   []string
+	Agents     []string
+	MCPServers []string
+}
+
+// Conflict is a name defined by more than one source
+type Conflict struct {
+	Kind    string   `json:"kind"`
+	Name    string   `json:"name"`
+	Sources []string `json:"sources"`
+}
+
+// PluginDefinitions reads the commands, agents, and MCP servers of the
+// plugin in dir. Commands and agents come from the conventional commands/
+// and agents/ directories; an agent is named by its frontmatter, or else
+// its file.
+func PluginDefinitions(pluginName, dir string) (Definitions, error) {
+	defs := UserDefinitions(pluginName, dir)
+
+	servers, err := manifestMCPServers(dir)
+	if err != nil {
+		return defs, err
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, ".mcp.json")); err == nil {
+		var mcpFile struct {
+			MCPServers map[string]MCPServer `json:"mcpServers"`
+		}
+		if json.Unmarshal(data, &mcpFile) == nil {
+			for name, s := range mcpFile.MCPServers {
+				servers[name] = s
+			}
+		}
+	}
+	for name := range servers {
+		defs.MCPServers = append(defs.MCPServers, name)
+	}
+	sort.Strings(defs.MCPServers)
+	return defs, nil
+}
+
+// UserDefinitions reads the commands and agents under dir, such as the
+// user's own ~/.claude/commands and ~/.claude/agents
+func UserDefinitions(source, dir string) Definitions {
+	defs := Definitions{Source: source}
+	for _, path := range markdownFiles(filepath.Join(dir, "commands")) {
+		defs.Commands = append(defs.Commands, strings.TrimSuffix(filepath.Base(path), ".md"))
+	}
+	for _, path := range markdownFiles(filepath.Join(dir, "agents")) {
+		name := frontmatterValue(path, "name")
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(path), ".md")
+		}
+		defs.Agents = append(defs.Agents, name)
+	}
+	sort.Strings(defs.Commands)
+	sort.Strings(defs.Agents)
+	return defs
+}
+
+// Conflicts lists every command, agent, and MCP server name that more than
+// one source defines, sorted by kind and name. Sources keep their order.
+func Conflicts(defs []Definitions) []Conflict {
+	type key struct{ kind, name string }
+	sources := make(map[key][]string)
+	add := func(kind, source string, names []string) {
+		for _, name := range names {
+			k := key{kind, name}
+			if n := len(sources[k]); n == 0 || sources[k][n-1] != source {
+				sources[k] = append(sources[k], source)
+			}
+		}
+	}
+	for _, d := range defs {
+		add(KindCommand, d.Source, d.Commands)
+		add(KindAgent, d.Source, d.Agents)
+		add(KindMCP, d.Source, d.MCPServers)
+	}
+
+	var conflicts []Conflict
+	for k, s := range sources {
+		if len(s) > 1 {
+			conflicts = append(conflicts, Conflict{Kind: k.kind, Name: k.name, Sources: s})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Kind != conflicts[j].Kind {
+			return conflicts[i].Kind < conflicts[j].Kind
+		}
+		return conflicts[i].Name < conflicts[j].Name
+	})
+	return conflicts
+}
+
+// manifestMCPServers returns the MCP servers declared inline in
+// .claude-plugin/plugin.json
+func manifestMCPServers(dir string) (map[string]MCPServer, error) {
+	servers := make(map[string]MCPServer)
+	data, err := os.ReadFile(filepath.Join(dir, ".claude-plugin", "plugin.json"))
+	if os.IsNotExist(err) {
+		return servers, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest struct {
+		MCPServers map[string]MCPServer `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	for name, s := range manifest.MCPServers {
+		servers[name] = s
+	}
+	return servers, nil
+}
+
+// markdownFiles lists the .md files under dir, including subdirectories
+func markdownFiles(dir string) []string {
+	var files []string
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && filepath.Ext(path) == ".md" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+// frontmatterValue returns the value of key in a markdown file's frontmatter
+func frontmatterValue(path, key string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "---" {
+		return ""
+	}
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "---" {
+			break
+		}
+		if value, ok := strings.CutPrefix(text, key+":"); ok {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+// ABOUTME: Tests for reading plugin definitions and finding name conflicts
+// ABOUTME: Builds fake plugin directories with commands, agents, and MCP servers
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPluginDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "commands", "review.md"), "Review the diff.\n")
+	writeFile(t, filepath.Join(dir, "commands", "git", "commit.md"), "Commit.\n")
+	writeFile(t, filepath.Join(dir, "agents", "helper.md"), "---\nname: \"code-reviewer\"\ndescription: Reviews code\n---\nBody\n")
+	writeFile(t, filepath.Join(dir, "agents", "plain.md"), "No frontmatter\n")
+	writeFile(t, filepath.Join(dir, ".claude-plugin", "plugin.json"), `{"name": "p", "mcpServers": {"github": {"command": "gh-mcp"}}}`)
+	writeFile(t, filepath.Join(dir, ".mcp.json"), `{"mcpServers": {"db": {"command": "pg-mcp"}}}`)
+
+	defs, err := PluginDefinitions("p@m", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defs.Source != "p@m" {
+		t.Errorf("Unexpected source %q", defs.Source)
+	}
+	assertNames(t, "commands", defs.Commands, "commit", "review")
+	assertNames(t, "agents", defs.Agents, "code-reviewer", "plain")
+	assertNames(t, "MCP servers", defs.MCPServers, "db", "github")
+}
+
+func TestPluginDefinitionsInvalidManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".claude-plugin", "plugin.json"), `{`)
+	if _, err := PluginDefinitions("p@m", dir); err == nil {
+		t.Error("Expected an invalid plugin.json to fail")
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	conflicts := Conflicts([]Definitions{
+		{Source: "~/.claude", Commands: []string{"deploy"}},
+		{Source: "a@m", Commands: []string{"review", "lint"}, Agents: []string{"reviewer"}, MCPServers: []string{"github"}},
+		{Source: "b@m", Commands: []string{"review", "review"}, Agents: []string{"reviewer"}},
+		{Source: "c@m", Commands: []string{"deploy"}, MCPServers: []string{"github"}},
+	})
+
+	want := []Conflict{
+		{Kind: KindAgent, Name: "reviewer", Sources: []string{"a@m", "b@m"}},
+		{Kind: KindCommand, Name: "deploy", Sources: []string{"~/.claude", "c@m"}},
+		{Kind: KindCommand, Name: "review", Sources: []string{"a@m", "b@m"}},
+		{Kind: KindMCP, Name: "github", Sources: []string{"a@m", "c@m"}},
+	}
+	if len(conflicts) != len(want) {
+		t.Fatalf("Expected %d conflicts, got %+v", len(want), conflicts)
+	}
+	for i, w := range want {
+		c := conflicts[i]
+		if c.Kind != w.Kind || c.Name != w.Name || len(c.Sources) != len(w.Sources) || c.Sources[0] != w.Sources[0] || c.Sources[1] != w.Sources[1] {
+			t.Errorf("Conflict %d: expected %+v, got %+v", i, w, c)
+		}
+	}
+}
+
+func assertNames(t *testing.T, what string, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("Expected %s %v, got %v", what, want, got)
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %s %v, got %v", what, want, got)
+			return
+		}
+	}
+}
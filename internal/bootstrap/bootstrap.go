@@ -0,0 +1,164 @@
+// ABOUTME: Generates self-contained shell scripts that onboard a new macOS or Linux machine
+// ABOUTME: The script installs a checksum-verified claudeup release, writes the profile, and runs setup unattended
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+// DefaultReleaseURL is where claudeup releases and their checksums.txt are
+// published, one directory per tag
+const DefaultReleaseURL = "https://github.com/claudeup/claudeup/releases/download"
+
+// Options describes the script to generate
+type Options struct {
+	Profile        *profile.Profile
+	Version        string // release tag to install, or "latest" to look it up when the script runs
+	ReleaseURL     string // defaults to DefaultReleaseURL
+	AssumeExisting string // setup --assume-existing answer
+	Installer      string // setup --installer, or "" for its default
+	GeneratedBy    string // claudeup version writing the script
+}
+
+// Script renders the bootstrap script for opts
+func Script(opts Options) (string, error) {
+	if opts.Profile == nil {
+		return "", fmt.Errorf("no profile to bootstrap")
+	}
+	if err := profile.ValidateName(opts.Profile.Name); err != nil {
+		return "", err
+	}
+	if strings.ContainsAny(opts.Profile.Name, "\n\r") {
+		return "", fmt.Errorf("invalid profile name %q", opts.Profile.Name)
+	}
+	if opts.Version == "" {
+		opts.Version = "latest"
+	}
+	if opts.ReleaseURL == "" {
+		opts.ReleaseURL = DefaultReleaseURL
+	}
+
+	data, err := json.MarshalIndent(opts.Profile, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode profile: %w", err)
+	}
+	setup := []string{"setup", "--profile", opts.Profile.Name, "--yes"}
+	if opts.AssumeExisting != "" {
+		setup = append(setup, "--assume-existing", opts.AssumeExisting)
+	}
+	if opts.Installer != "" {
+		setup = append(setup, "--installer", opts.Installer)
+	}
+	quoted := make([]string, len(setup))
+	for i, arg := range setup {
+		quoted[i] = shellQuote(arg)
+	}
+
+	var b bytes.Buffer
+	err = scriptTemplate.Execute(&b, map[string]string{
+		"GeneratedBy": opts.GeneratedBy,
+		"Profile":     opts.Profile.Name,
+		"ProfileJSON": string(data),
+		"Version":     shellQuote(opts.Version),
+		"ReleaseURL":  shellQuote(opts.ReleaseURL),
+		"ProfilePath": shellQuote(opts.Profile.Name + ".json"),
+		"SetupArgs":   strings.Join(quoted, " "),
+	})
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// The profile is written with a quoted heredoc, so nothing in it is expanded
+var scriptTemplate = template.Must(template.New("bootstrap").Parse(`#!/bin/bash
+# Generated by 'claudeup bootstrap'{{if .GeneratedBy}} {{.GeneratedBy}}{{end}} for macOS and Linux.
+# Installs claudeup, verified against the release checksums, then sets up
+# Claude Code with the {{.Profile}} profile without asking questions.
+#
+# CLAUDEUP_INSTALL_DIR sets where claudeup goes (default ~/.local/bin).
+
+set -euo pipefail
+
+VERSION={{.Version}}
+RELEASE_URL={{.ReleaseURL}}
+INSTALL_DIR="${CLAUDEUP_INSTALL_DIR:-$HOME/.local/bin}"
+PROFILES_DIR="$HOME/.claudeup/profiles"
+
+case "$(uname -s)" in
+    Darwin) OS=darwin ;;
+    Linux)  OS=linux ;;
+    *)
+        echo "Unsupported OS $(uname -s): this script is for macOS and Linux." >&2
+        exit 1
+        ;;
+esac
+case "$(uname -m)" in
+    x86_64|amd64)  ARCH=amd64 ;;
+    arm64|aarch64) ARCH=arm64 ;;
+    *)
+        echo "Unsupported architecture $(uname -m)." >&2
+        exit 1
+        ;;
+esac
+# A shell under Rosetta reports x86_64 on Apple silicon; install the native build
+if [[ $OS == darwin && $ARCH == amd64 && "$(sysctl -n sysctl.proc_translated 2>/dev/null)" == 1 ]]; then
+    ARCH=arm64
+fi
+
+if [[ "$VERSION" == latest ]]; then
+    VERSION=$(curl -fsSL "https://api.github.com/repos/claudeup/claudeup/releases/latest" | grep '"tag_name"' | sed -E 's/.*"([^"]+)".*/\1/')
+    if [[ -z "$VERSION" ]]; then
+        echo "Failed to look up the latest claudeup release." >&2
+        exit 1
+    fi
+fi
+
+ASSET="claudeup-$OS-$ARCH"
+TMP_DIR=$(mktemp -d)
+trap 'rm -rf "$TMP_DIR"' EXIT
+
+echo "Downloading claudeup $VERSION for $OS-$ARCH..."
+curl -fsSL "$RELEASE_URL/$VERSION/$ASSET" -o "$TMP_DIR/claudeup"
+curl -fsSL "$RELEASE_URL/$VERSION/checksums.txt" -o "$TMP_DIR/checksums.txt"
+
+EXPECTED=$(awk -v asset="$ASSET" '$2 == asset { print $1 }' "$TMP_DIR/checksums.txt")
+if command -v sha256sum >/dev/null 2>&1; then
+    ACTUAL=$(sha256sum "$TMP_DIR/claudeup" | awk '{ print $1 }')
+else
+    ACTUAL=$(shasum -a 256 "$TMP_DIR/claudeup" | awk '{ print $1 }')
+fi
+if [[ -z "$EXPECTED" || "$EXPECTED" != "$ACTUAL" ]]; then
+    echo "Checksum verification failed for $ASSET $VERSION." >&2
+    echo "Expected: ${EXPECTED:-no entry in checksums.txt}" >&2
+    echo "Got:      $ACTUAL" >&2
+    exit 1
+fi
+echo "✓ Checksum verified"
+
+mkdir -p "$INSTALL_DIR"
+chmod +x "$TMP_DIR/claudeup"
+mv "$TMP_DIR/claudeup" "$INSTALL_DIR/claudeup"
+echo "✓ Installed claudeup $VERSION to $INSTALL_DIR/claudeup"
+
+PROFILE_PATH="$PROFILES_DIR/"{{.ProfilePath}}
+mkdir -p "$(dirname "$PROFILE_PATH")"
+cat > "$PROFILE_PATH" <<'CLAUDEUP_PROFILE'
+{{.ProfileJSON}}
+CLAUDEUP_PROFILE
+echo "✓ Wrote $PROFILE_PATH"
+
+# The Claude CLI installer also puts claude in ~/.local/bin
+export PATH="$INSTALL_DIR:$HOME/.local/bin:$PATH"
+"$INSTALL_DIR/claudeup" {{.SetupArgs}}
+`))
@@ -0,0 +1,134 @@
+// ABOUTME: Tests for bootstrap script generation
+// ABOUTME: Runs generated scripts against a fake release directory to check verification and setup
+package bootstrap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+// fakeRelease writes a release directory whose claudeup records its
+// arguments, returning the release URL and the arguments file
+func fakeRelease(t *testing.T, version string, corrupt bool) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	argsFile := filepath.Join(t.TempDir(), "args")
+	binary := "#!/bin/sh\necho \"$@\" > " + argsFile + "\n"
+	asset := "claudeup-" + runtime.GOOS + "-" + runtime.GOARCH
+
+	if err := os.MkdirAll(filepath.Join(dir, version), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, version, asset), []byte(binary), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(binary))
+	checksum := hex.EncodeToString(sum[:])
+	if corrupt {
+		checksum = strings.Repeat("0", 64)
+	}
+	checksums := checksum + "  " + asset + "\n" + strings.Repeat("1", 64) + "  claudeup-windows-amd64.exe\n"
+	if err := os.WriteFile(filepath.Join(dir, version, "checksums.txt"), []byte(checksums), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return "file://" + dir, argsFile
+}
+
+func runScript(t *testing.T, script, home string) (string, error) {
+	t.Helper()
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("bootstrap scripts are for macOS and Linux")
+	}
+	for _, tool := range []string{"bash", "curl"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not available", tool)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "bootstrap.sh")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("bash", path)
+	cmd.Env = append(os.Environ(), "HOME="+home, "CLAUDEUP_INSTALL_DIR=")
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func TestScriptInstallsAndRunsSetup(t *testing.T) {
+	releaseURL, argsFile := fakeRelease(t, "v1.4.0", false)
+	p := &profile.Profile{Name: "team/backend", Plugins: []string{"tool@acme"}}
+	script, err := Script(Options{Profile: p, Version: "v1.4.0", ReleaseURL: releaseURL, AssumeExisting: "save-as=old", Installer: "npm"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := t.TempDir()
+	out, err := runScript(t, script, home)
+	if err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "✓ Checksum verified") {
+		t.Errorf("Expected the checksum to be verified:\n%s", out)
+	}
+
+	written, err := profile.Load(filepath.Join(home, ".claudeup", "profiles"), "team/backend")
+	if err != nil || len(written.Plugins) != 1 || written.Plugins[0] != "tool@acme" {
+		t.Errorf("Expected the profile written under its namespace, got %+v, %v", written, err)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("Expected the installed claudeup to run: %v\n%s", err, out)
+	}
+	if got := strings.TrimSpace(string(args)); got != "setup --profile team/backend --yes --assume-existing save-as=old --installer npm" {
+		t.Errorf("Unexpected setup arguments %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".local", "bin", "claudeup")); err != nil {
+		t.Errorf("Expected claudeup in ~/.local/bin: %v", err)
+	}
+}
+
+func TestScriptRefusesChecksumMismatch(t *testing.T) {
+	releaseURL, argsFile := fakeRelease(t, "v1.4.0", true)
+	script, err := Script(Options{Profile: &profile.Profile{Name: "work"}, Version: "v1.4.0", ReleaseURL: releaseURL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := t.TempDir()
+	out, err := runScript(t, script, home)
+	if err == nil || !strings.Contains(out, "Checksum verification failed") {
+		t.Fatalf("Expected the script to fail verification, got %v:\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".local", "bin", "claudeup")); err == nil {
+		t.Error("Expected an unverified claudeup not to be installed")
+	}
+	if _, err := os.Stat(argsFile); err == nil {
+		t.Error("Expected an unverified claudeup not to run")
+	}
+}
+
+func TestScriptQuotesNames(t *testing.T) {
+	p := &profile.Profile{Name: "it's $(whoami)"}
+	script, err := Script(Options{Profile: p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(script, `'setup' '--profile' 'it'\''s $(whoami)' '--yes'`) {
+		t.Errorf("Expected the profile name single-quoted in the setup command:\n%s", script)
+	}
+	if !strings.Contains(script, "VERSION='latest'") {
+		t.Error("Expected the latest release by default")
+	}
+
+	if _, err := Script(Options{Profile: &profile.Profile{Name: "../x"}}); err == nil {
+		t.Error("Expected an invalid profile name to be refused")
+	}
+}
@@ -0,0 +1,489 @@
+// ABOUTME: Offline bundles packaging a profile with its marketplace clones and plugin caches
+// ABOUTME: Bundles are gzipped tarballs that can be restored without network access
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// FormatVersion is the bundle layout version written to the manifest
+const FormatVersion = 1
+
+const (
+	manifestFile    = "manifest.json"
+	profileFile     = "profile.json"
+	marketplacesDir = "marketplaces"
+	pluginsDir      = "plugins"
+)
+
+// Manifest describes the contents of a bundle
+type Manifest struct {
+	Version      int           `json:"version"`
+	Profile      string        `json:"profile"`
+	Created      time.Time     `json:"created"`
+	Marketplaces []Marketplace `json:"marketplaces"`
+	Plugins      []Plugin      `json:"plugins"`
+}
+
+// Marketplace is a marketplace clone included in a bundle
+type Marketplace struct {
+	Name   string                  `json:"name"`
+	Source state.MarketplaceSource `json:"source"`
+	Commit string                  `json:"commit,omitempty"`
+}
+
+// Plugin is an installed plugin included in a bundle. Path is relative to
+// the Claude directory. Archived is false when the plugin lives inside a
+// bundled marketplace clone and is restored along with it.
+type Plugin struct {
+	Name     string               `json:"name"`
+	Path     string               `json:"path"`
+	Archived bool                 `json:"archived"`
+	Metadata state.PluginMetadata `json:"metadata"`
+}
+
+// Create writes a bundle for p to w. Every plugin in the profile must be
+// installed, and every marketplace it uses must be cloned locally.
+func Create(w io.Writer, p *profile.Profile, claudeDir string) (*Manifest, error) {
+	p, err := p.ForThisMachine()
+	if err != nil {
+		return nil, err
+	}
+	registry, err := state.LoadPlugins(claudeDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugins: %w", err)
+	}
+	marketplaces, err := state.LoadMarketplaces(claudeDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load marketplaces: %w", err)
+	}
+
+	m := &Manifest{Version: FormatVersion, Profile: p.Name, Created: time.Now().UTC()}
+
+	// Marketplaces listed in the profile, plus those its plugins come from
+	needed := make(map[string]bool)
+	for _, pm := range p.Marketplaces {
+		key := pm.DisplayName()
+		name := ""
+		for n, meta := range marketplaces {
+			if profile.RegisteredMarketplace(meta.Source).DisplayName() == key {
+				name = n
+				break
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("marketplace %s is not installed", pm.DisplayName())
+		}
+		needed[name] = true
+	}
+	for _, name := range p.Plugins {
+		if i := strings.LastIndex(name, "@"); i >= 0 {
+			needed[name[i+1:]] = true
+		}
+	}
+
+	var names []string
+	for name := range needed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var roots []string
+	for _, name := range names {
+		meta, ok := marketplaces[name]
+		if !ok {
+			return nil, fmt.Errorf("marketplace %s is not installed", name)
+		}
+		if _, err := os.Stat(meta.InstallLocation); err != nil {
+			return nil, fmt.Errorf("marketplace %s clone is missing: %w", name, err)
+		}
+		m.Marketplaces = append(m.Marketplaces, Marketplace{
+			Name:   name,
+			Source: meta.Source,
+			Commit: headCommit(meta.InstallLocation),
+		})
+		roots = append(roots, meta.InstallLocation)
+	}
+
+	for _, name := range p.Plugins {
+		meta, ok := registry.GetPlugin(name)
+		if !ok {
+			meta, ok = disabledPlugin(name)
+		}
+		if !ok || !meta.PathExists() {
+			return nil, fmt.Errorf("plugin %s is not installed (run 'claudeup profile use %s' first)", name, p.Name)
+		}
+		rel, err := filepath.Rel(claudeDir, meta.InstallPath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("plugin %s is installed outside %s", name, claudeDir)
+		}
+		m.Plugins = append(m.Plugins, Plugin{
+			Name:     name,
+			Path:     filepath.ToSlash(rel),
+			Archived: !insideAny(meta.InstallPath, roots),
+			Metadata: meta,
+		})
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeJSON(tw, manifestFile, m); err != nil {
+		return nil, err
+	}
+	if err := writeJSON(tw, profileFile, p); err != nil {
+		return nil, err
+	}
+	for _, mp := range m.Marketplaces {
+		if err := addTree(tw, marketplaces[mp.Name].InstallLocation, path.Join(marketplacesDir, mp.Name)); err != nil {
+			return nil, fmt.Errorf("failed to add marketplace %s: %w", mp.Name, err)
+		}
+	}
+	for _, pl := range m.Plugins {
+		if !pl.Archived {
+			continue
+		}
+		if err := addTree(tw, pl.Metadata.InstallPath, path.Join(pluginsDir, pl.Name)); err != nil {
+			return nil, fmt.Errorf("failed to add plugin %s: %w", pl.Name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// disabledPlugin returns the saved metadata of a plugin turned off with
+// 'claudeup disable', which is no longer in Claude's registry
+func disabledPlugin(name string) (state.PluginMetadata, bool) {
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		return state.PluginMetadata{}, false
+	}
+	d, ok := cfg.GetDisabledPlugin(name)
+	if !ok {
+		return state.PluginMetadata{}, false
+	}
+	return state.PluginMetadata{
+		Scope:        "user",
+		Version:      d.Version,
+		InstalledAt:  d.InstalledAt,
+		LastUpdated:  d.LastUpdated,
+		InstallPath:  d.InstallPath,
+		GitCommitSha: d.GitCommitSha,
+		IsLocal:      d.IsLocal,
+	}, true
+}
+
+// Extract unpacks a bundle into dir and returns its manifest and profile
+func Extract(r io.Reader, dir string) (*Manifest, *profile.Profile, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a bundle: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("corrupt bundle: %w", err)
+		}
+		if err := extractEntry(tr, hdr, dir); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var m Manifest
+	if err := readJSON(filepath.Join(dir, manifestFile), &m); err != nil {
+		return nil, nil, fmt.Errorf("bundle has no valid manifest: %w", err)
+	}
+	if m.Version > FormatVersion {
+		return nil, nil, fmt.Errorf("bundle format version %d is newer than supported (%d); upgrade claudeup", m.Version, FormatVersion)
+	}
+	var p profile.Profile
+	if err := readJSON(filepath.Join(dir, profileFile), &p); err != nil {
+		return nil, nil, fmt.Errorf("bundle has no valid profile: %w", err)
+	}
+	return &m, &p, nil
+}
+
+// Install moves the marketplace clones and plugin caches extracted into dir
+// into claudeDir and registers them, replacing any existing copies.
+// dir should be on the same filesystem as claudeDir.
+func Install(dir string, m *Manifest, claudeDir string) error {
+	// Restoring replaces its targets, so a manifest naming paths outside
+	// Claude's plugins directory must be refused before anything is removed
+	if err := m.validatePaths(claudeDir); err != nil {
+		return err
+	}
+
+	// A machine that never ran Claude Code has no registries yet
+	marketplaces, err := state.LoadMarketplaces(claudeDir)
+	if os.IsNotExist(err) {
+		marketplaces, err = make(state.MarketplaceRegistry), nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load marketplaces: %w", err)
+	}
+	registry, err := state.LoadPlugins(claudeDir)
+	if os.IsNotExist(err) {
+		registry = &state.PluginRegistry{Version: 2, Plugins: make(map[string][]state.PluginMetadata)}
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load plugins: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for _, mp := range m.Marketplaces {
+		target := filepath.Join(claudeDir, "plugins", "marketplaces", mp.Name)
+		if err := moveDir(filepath.Join(dir, marketplacesDir, mp.Name), target); err != nil {
+			return fmt.Errorf("failed to restore marketplace %s: %w", mp.Name, err)
+		}
+		marketplaces[mp.Name] = state.MarketplaceMetadata{
+			Source:          mp.Source,
+			InstallLocation: target,
+			LastUpdated:     now.Format(time.RFC3339),
+		}
+	}
+
+	for _, pl := range m.Plugins {
+		target := filepath.Join(claudeDir, filepath.FromSlash(pl.Path))
+		if pl.Archived {
+			if err := moveDir(filepath.Join(dir, pluginsDir, pl.Name), target); err != nil {
+				return fmt.Errorf("failed to restore plugin %s: %w", pl.Name, err)
+			}
+		}
+		meta := pl.Metadata
+		meta.InstallPath = target
+		registry.SetPlugin(pl.Name, meta)
+	}
+
+	if err := state.SaveMarketplaces(claudeDir, marketplaces); err != nil {
+		return fmt.Errorf("failed to save marketplaces: %w", err)
+	}
+	if err := state.SavePlugins(claudeDir, registry); err != nil {
+		return fmt.Errorf("failed to save plugins: %w", err)
+	}
+	return nil
+}
+
+// validatePaths checks that every marketplace and plugin the manifest
+// restores stays inside the bundle and inside claudeDir's plugins directory
+func (m *Manifest) validatePaths(claudeDir string) error {
+	pluginsRoot := filepath.Join(claudeDir, "plugins")
+	for _, mp := range m.Marketplaces {
+		if !isPathElement(mp.Name) {
+			return fmt.Errorf("bundle manifest has an invalid marketplace name %q", mp.Name)
+		}
+	}
+	for _, pl := range m.Plugins {
+		if pl.Archived && !isPathElement(pl.Name) {
+			return fmt.Errorf("bundle manifest has an invalid plugin name %q", pl.Name)
+		}
+		local, err := filepath.Localize(pl.Path)
+		if err != nil || !strictlyInside(filepath.Join(claudeDir, local), pluginsRoot) {
+			return fmt.Errorf("bundle manifest puts plugin %s at %q, outside %s", pl.Name, pl.Path, pluginsRoot)
+		}
+	}
+	return nil
+}
+
+// isPathElement reports whether name is a single file name, not a path
+func isPathElement(name string) bool {
+	return name != "" && name != "." && filepath.IsLocal(name) && !strings.ContainsAny(name, `/\`)
+}
+
+// strictlyInside reports whether p is below root, and isn't root itself
+func strictlyInside(p, root string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != "." && filepath.IsLocal(rel)
+}
+
+// PluginNames returns the names of the plugins in the bundle
+func (m *Manifest) PluginNames() []string {
+	names := make([]string, len(m.Plugins))
+	for i, p := range m.Plugins {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func headCommit(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func insideAny(p string, roots []string) bool {
+	for _, root := range roots {
+		if rel, err := filepath.Rel(root, p); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func moveDir(src, dst string) error {
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("missing from bundle: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	return os.Rename(src, dst)
+}
+
+func writeJSON(tw *tar.Writer, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+func readJSON(p string, v interface{}) error {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// addTree writes the directory tree at src into the archive under prefix.
+// Regular files, directories, and symlinks are preserved.
+func addTree(tw *tar.Writer, src, prefix string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(prefix, filepath.ToSlash(rel))
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		} else if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+func extractEntry(tr *tar.Reader, hdr *tar.Header, dir string) error {
+	name := path.Clean(hdr.Name)
+	local, err := filepath.Localize(name)
+	if err != nil {
+		return fmt.Errorf("bundle entry %q escapes the bundle", hdr.Name)
+	}
+	target := filepath.Join(dir, local)
+	// A symlink extracted earlier could redirect this entry out of dir
+	if through := symlinkOnPath(dir, local); through != "" {
+		return fmt.Errorf("bundle entry %q goes through symlink %q", hdr.Name, filepath.ToSlash(through))
+	}
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(target, 0755)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode).Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	case tar.TypeSymlink:
+		resolved := path.Join(path.Dir(name), hdr.Linkname)
+		if _, err := filepath.Localize(resolved); err != nil || path.IsAbs(hdr.Linkname) {
+			return fmt.Errorf("bundle symlink %q points outside the bundle", hdr.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return os.Symlink(hdr.Linkname, target)
+	}
+	return nil
+}
+
+// symlinkOnPath returns the first of local's ancestors below dir, or local
+// itself, that is a symlink, or "" if none is
+func symlinkOnPath(dir, local string) string {
+	p := ""
+	for _, elem := range strings.Split(local, string(filepath.Separator)) {
+		p = filepath.Join(p, elem)
+		info, err := os.Lstat(filepath.Join(dir, p))
+		if err != nil {
+			return ""
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return p
+		}
+	}
+	return ""
+}
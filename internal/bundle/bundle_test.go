@@ -0,0 +1,204 @@
+// ABOUTME: Tests for creating and restoring offline bundles
+// ABOUTME: Round-trips a fake Claude directory through a bundle into a fresh one
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+func setupClaudeDir(t *testing.T) string {
+	t.Helper()
+
+	claudeDir := t.TempDir()
+	market := filepath.Join(claudeDir, "plugins", "marketplaces", "market")
+	cached := filepath.Join(claudeDir, "plugins", "cache", "market", "cached", "1.0.0")
+	local := filepath.Join(market, "plugins", "local")
+
+	writeFile(t, filepath.Join(market, ".claude-plugin", "marketplace.json"), `{"plugins": []}`)
+	writeFile(t, filepath.Join(local, "commands", "local.md"), "# Local\n")
+	writeFile(t, filepath.Join(cached, "commands", "cached.md"), "# Cached\n")
+
+	if err := state.SaveMarketplaces(claudeDir, state.MarketplaceRegistry{
+		"market": {Source: state.MarketplaceSource{Source: "github", Repo: "org/market"}, InstallLocation: market},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := &state.PluginRegistry{Version: 2, Plugins: map[string][]state.PluginMetadata{}}
+	registry.SetPlugin("cached@market", state.PluginMetadata{Scope: "user", Version: "1.0.0", InstallPath: cached})
+	registry.SetPlugin("local@market", state.PluginMetadata{Scope: "user", InstallPath: local, IsLocal: true})
+	if err := state.SavePlugins(claudeDir, registry); err != nil {
+		t.Fatal(err)
+	}
+	return claudeDir
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	src := setupClaudeDir(t)
+	p := &profile.Profile{
+		Name:         "offline",
+		Plugins:      []string{"cached@market", "local@market"},
+		Marketplaces: []profile.Marketplace{{Source: "github", Repo: "org/market"}},
+	}
+
+	var buf bytes.Buffer
+	m, err := Create(&buf, p, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Marketplaces) != 1 || len(m.Plugins) != 2 {
+		t.Fatalf("Expected 1 marketplace and 2 plugins, got %+v", m)
+	}
+
+	dst := t.TempDir()
+	staging := filepath.Join(dst, "staging")
+	gotManifest, gotProfile, err := Extract(&buf, staging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotProfile.Name != "offline" || len(gotProfile.Plugins) != 2 {
+		t.Errorf("Expected profile to round trip, got %+v", gotProfile)
+	}
+
+	if err := Install(staging, gotManifest, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []string{
+		"plugins/marketplaces/market/.claude-plugin/marketplace.json",
+		"plugins/marketplaces/market/plugins/local/commands/local.md",
+		"plugins/cache/market/cached/1.0.0/commands/cached.md",
+	} {
+		if _, err := os.Stat(filepath.Join(dst, filepath.FromSlash(f))); err != nil {
+			t.Errorf("Expected %s to be restored: %v", f, err)
+		}
+	}
+
+	registry, err := state.LoadPlugins(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"cached@market", "local@market"} {
+		meta, ok := registry.GetPlugin(name)
+		if !ok || !meta.PathExists() {
+			t.Errorf("Expected %s registered at an existing path, got %+v", name, meta)
+		}
+	}
+
+	marketplaces, err := state.LoadMarketplaces(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marketplaces["market"].Source.Repo != "org/market" {
+		t.Errorf("Expected market registered, got %+v", marketplaces)
+	}
+}
+
+func TestCreateRequiresInstalledPlugins(t *testing.T) {
+	src := setupClaudeDir(t)
+	p := &profile.Profile{Name: "broken", Plugins: []string{"missing@market"}}
+
+	if _, err := Create(&bytes.Buffer{}, p, src); err == nil {
+		t.Error("Expected error for plugin that is not installed")
+	}
+}
+
+func TestExtractRejectsEscapingPaths(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "../evil", Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+	tw.Write([]byte("x"))
+	tw.Close()
+	gz.Close()
+
+	if _, _, err := Extract(&buf, t.TempDir()); err == nil {
+		t.Error("Expected error for entry outside the bundle")
+	}
+}
+
+func TestExtractRejectsChainedSymlinks(t *testing.T) {
+	tests := map[string][]*tar.Header{
+		"symlink through a symlink": {
+			{Name: "d/", Typeflag: tar.TypeDir, Mode: 0755},
+			{Name: "d/a", Typeflag: tar.TypeSymlink, Linkname: ".."},
+			{Name: "d/a/b", Typeflag: tar.TypeSymlink, Linkname: "../x"},
+		},
+		"file through a symlink": {
+			{Name: "d/", Typeflag: tar.TypeDir, Mode: 0755},
+			{Name: "d/a", Typeflag: tar.TypeSymlink, Linkname: ".."},
+			{Name: "d/a/b", Typeflag: tar.TypeSymlink, Linkname: ".."},
+			{Name: "d/a/b/evil", Typeflag: tar.TypeReg, Mode: 0644},
+		},
+	}
+	for name, headers := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gz)
+			for _, hdr := range headers {
+				if err := tw.WriteHeader(hdr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tw.Close()
+			gz.Close()
+
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "bundle")
+			if _, _, err := Extract(&buf, dir); err == nil || !strings.Contains(err.Error(), "goes through symlink") {
+				t.Errorf("Expected the entry through a symlink refused, got %v", err)
+			}
+			if entries, _ := os.ReadDir(parent); len(entries) != 1 {
+				t.Errorf("Expected nothing written beside the bundle, got %v", entries)
+			}
+		})
+	}
+}
+
+func TestInstallRejectsTraversalManifest(t *testing.T) {
+	tests := map[string]*Manifest{
+		"marketplace name": {Marketplaces: []Marketplace{{Name: "../../.."}}},
+		"plugin path":      {Plugins: []Plugin{{Name: "x@m", Path: "../../../home/x", Archived: true}}},
+		"plugin name":      {Plugins: []Plugin{{Name: "../../x", Path: "plugins/cache/m/x", Archived: true}}},
+		"plugins root":     {Plugins: []Plugin{{Name: "x@m", Path: "plugins"}}},
+	}
+	for name, m := range tests {
+		t.Run(name, func(t *testing.T) {
+			home := t.TempDir()
+			claudeDir := filepath.Join(home, ".claude")
+			keep := filepath.Join(home, "keep.txt")
+			writeFile(t, keep, "precious")
+			writeFile(t, filepath.Join(claudeDir, "plugins", "cache", "m", "other", "f"), "x")
+
+			if err := Install(t.TempDir(), m, claudeDir); err == nil {
+				t.Fatal("Expected the manifest to be refused")
+			}
+			if _, err := os.Stat(keep); err != nil {
+				t.Errorf("Expected files outside the Claude directory untouched: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(claudeDir, "plugins", "cache", "m", "other", "f")); err != nil {
+				t.Errorf("Expected installed plugins untouched: %v", err)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,114 @@
+// ABOUTME: Conventional-commit parsing and semantic version comparison
+// ABOUTME: Classifies the commits between two plugin versions for update summaries
+package changelog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version bump levels
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+)
+
+// Commit is one parsed commit message
+type Commit struct {
+	Hash     string `json:"commit"`
+	Type     string `json:"type,omitempty"` // empty when the subject isn't conventional
+	Scope    string `json:"scope,omitempty"`
+	Summary  string `json:"summary"`
+	Breaking bool   `json:"breaking,omitempty"`
+	Security bool   `json:"security,omitempty"`
+}
+
+// header matches "type(scope)!: summary"
+var header = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+var cveID = regexp.MustCompile(`\bCVE-\d{4}-\d+\b`)
+
+// Parse reads a commit message. A "!" after the type or a BREAKING CHANGE
+// footer marks a breaking change; a security type or scope, or a CVE ID in
+// the subject, marks a security fix.
+func Parse(hash, message string) Commit {
+	message = strings.TrimSpace(message)
+	subject, body, _ := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
+
+	c := Commit{Hash: hash, Summary: subject}
+	if m := header.FindStringSubmatch(subject); m != nil {
+		c.Type = strings.ToLower(m[1])
+		c.Scope = strings.ToLower(m[2])
+		c.Breaking = m[3] == "!"
+		c.Summary = m[4]
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			c.Breaking = true
+		}
+	}
+	c.Security = c.Type == "security" || c.Scope == "security" || cveID.MatchString(subject)
+	return c
+}
+
+// Label is the commit's type and scope as written, e.g. "fix(auth)!"
+func (c Commit) Label() string {
+	if c.Type == "" {
+		return ""
+	}
+	label := c.Type
+	if c.Scope != "" {
+		label += "(" + c.Scope + ")"
+	}
+	if c.Breaking {
+		label += "!"
+	}
+	return label
+}
+
+// Bump returns how far to is ahead of from: BumpMajor, BumpMinor,
+// BumpPatch, or empty if either isn't a version or to isn't newer
+func Bump(from, to string) string {
+	a, okA := parseSemver(from)
+	b, okB := parseSemver(to)
+	if !okA || !okB {
+		return ""
+	}
+	for i, level := range []string{BumpMajor, BumpMinor, BumpPatch} {
+		if b[i] > a[i] {
+			return level
+		}
+		if b[i] < a[i] {
+			return ""
+		}
+	}
+	return ""
+}
+
+// parseSemver reads "v1.2.3"; missing minor and patch parts are 0 and
+// pre-release or build suffixes are ignored
+func parseSemver(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return parts, false
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) > 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
@@ -0,0 +1,52 @@
+// ABOUTME: Tests for conventional-commit parsing and version bumps
+// ABOUTME: Covers breaking and security markers and non-conventional subjects
+package changelog
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		message  string
+		want     Commit
+		wantText string
+	}{
+		{"feat(ui): add dark mode", Commit{Type: "feat", Scope: "ui", Summary: "add dark mode"}, "feat(ui)"},
+		{"fix!: drop node 16", Commit{Type: "fix", Summary: "drop node 16", Breaking: true}, "fix!"},
+		{"refactor: rename hooks\n\nBREAKING CHANGE: hook names changed", Commit{Type: "refactor", Summary: "rename hooks", Breaking: true}, "refactor!"},
+		{"fix(security): escape shell args", Commit{Type: "fix", Scope: "security", Summary: "escape shell args", Security: true}, "fix(security)"},
+		{"security: rotate signing key", Commit{Type: "security", Summary: "rotate signing key", Security: true}, "security"},
+		{"Bump lodash for CVE-2021-23337", Commit{Summary: "Bump lodash for CVE-2021-23337", Security: true}, ""},
+		{"Update README", Commit{Summary: "Update README"}, ""},
+	}
+	for _, tt := range tests {
+		got := Parse("abc", tt.message)
+		tt.want.Hash = "abc"
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.message, got, tt.want)
+		}
+		if got.Label() != tt.wantText {
+			t.Errorf("Parse(%q).Label() = %q, want %q", tt.message, got.Label(), tt.wantText)
+		}
+	}
+}
+
+func TestBump(t *testing.T) {
+	tests := []struct {
+		from, to, want string
+	}{
+		{"1.2.0", "1.4.1", BumpMinor},
+		{"1.2.0", "2.0.0", BumpMajor},
+		{"v1.2.0", "v1.2.3", BumpPatch},
+		{"1.2", "1.2.1", BumpPatch},
+		{"1.2.0-beta.1", "1.2.0", ""},
+		{"2.0.0", "1.9.9", ""},
+		{"1.0.0", "1.0.0", ""},
+		{"", "1.0.0", ""},
+		{"abc1234", "1.0.0", ""},
+	}
+	for _, tt := range tests {
+		if got := Bump(tt.from, tt.to); got != tt.want {
+			t.Errorf("Bump(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// ABOUTME: Named checkpoints holding exact copies of Claude's registry files under ~/.claudeup/checkpoints
+// ABOUTME: Restoring writes the files back byte for byte, including state claudeup's profiles can't model
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// Target is a file checkpoints copy
+type Target struct {
+	Name string // where the copy is kept in a checkpoint, with forward slashes
+	Path string // where the file lives
+}
+
+// Targets returns the files a checkpoint holds: Claude's settings, plugin
+// and marketplace registries, and .claude.json, and claudeup's config,
+// which keeps the metadata of disabled plugins
+func Targets(claudeDir, claudeJSONPath, claudeupDir string) []Target {
+	return []Target{
+		{"settings.json", filepath.Join(claudeDir, "settings.json")},
+		{"plugins/installed_plugins.json", filepath.Join(claudeDir, "plugins", "installed_plugins.json")},
+		{"plugins/known_marketplaces.json", filepath.Join(claudeDir, "plugins", "known_marketplaces.json")},
+		{".claude.json", claudeJSONPath},
+		{".claudeup/config.json", filepath.Join(claudeupDir, "config.json")},
+	}
+}
+
+// File records one target as it was when the checkpoint was created
+type File struct {
+	Name    string      `json:"name"`
+	Missing bool        `json:"missing,omitempty"` // the file didn't exist, so restoring deletes it
+	Mode    fs.FileMode `json:"mode,omitempty"`
+	Size    int64       `json:"size"`
+	SHA256  string      `json:"sha256,omitempty"`
+}
+
+// Checkpoint is the manifest of a stored checkpoint
+type Checkpoint struct {
+	Label   string    `json:"label"`
+	Created time.Time `json:"created"`
+	Files   []File    `json:"files"`
+}
+
+const manifestFile = "checkpoint.json"
+
+var labelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// DefaultDir returns the checkpoint storage directory
+func DefaultDir() string {
+	return pathctx.Default().Claudeup("checkpoints")
+}
+
+// ValidateLabel checks that label can name a checkpoint directory
+func ValidateLabel(label string) error {
+	if !labelPattern.MatchString(label) {
+		return fmt.Errorf("invalid checkpoint label %q: use letters, digits, '.', '_', and '-'", label)
+	}
+	return nil
+}
+
+// Create copies each target into a new checkpoint named label in dir
+func Create(dir, label string, targets []Target) (*Checkpoint, error) {
+	if err := ValidateLabel(label); err != nil {
+		return nil, err
+	}
+	root := filepath.Join(dir, label)
+	if _, err := os.Stat(root); err == nil {
+		return nil, fmt.Errorf("checkpoint %q already exists", label)
+	}
+
+	// Copy into a temporary directory so a failure leaves no partial checkpoint
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(dir, "."+label+"-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	cp := &Checkpoint{Label: label, Created: time.Now().UTC()}
+	for _, t := range targets {
+		data, err := os.ReadFile(t.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			cp.Files = append(cp.Files, File{Name: t.Name, Missing: true})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", t.Path, err)
+		}
+		info, err := os.Stat(t.Path)
+		if err != nil {
+			return nil, err
+		}
+		dst := filepath.Join(tmp, "files", filepath.FromSlash(t.Name))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(dst, data, 0600); err != nil {
+			return nil, err
+		}
+		cp.Files = append(cp.Files, File{Name: t.Name, Mode: info.Mode().Perm(), Size: int64(len(data)), SHA256: digest(data)})
+	}
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, manifestFile), data, 0644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, root); err != nil {
+		return nil, fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// Load reads the manifest of the checkpoint named label
+func Load(dir, label string) (*Checkpoint, error) {
+	if err := ValidateLabel(label); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, label, manifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("checkpoint %q not found", label)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("checkpoint %q: %w", label, err)
+	}
+	return &cp, nil
+}
+
+// List returns the checkpoints in dir, oldest first
+func List(dir string) ([]*Checkpoint, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cps []*Checkpoint
+	for _, e := range entries {
+		if !e.IsDir() || ValidateLabel(e.Name()) != nil {
+			continue
+		}
+		if cp, err := Load(dir, e.Name()); err == nil {
+			cps = append(cps, cp)
+		}
+	}
+	sort.SliceStable(cps, func(i, j int) bool { return cps[i].Created.Before(cps[j].Created) })
+	return cps, nil
+}
+
+// Delete removes the checkpoint named label
+func Delete(dir, label string) error {
+	if _, err := Load(dir, label); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(dir, label))
+}
+
+// Restore writes the targets back exactly as the checkpoint named label
+// holds them, deleting those that didn't exist then. Every stored copy is
+// checked against its recorded hash before anything is written. Returns
+// the names of the files that changed.
+func Restore(dir, label string, targets []Target) ([]string, error) {
+	cp, err := Load(dir, label)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string][]byte)
+	for _, f := range cp.Files {
+		if f.Missing {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, label, "files", filepath.FromSlash(f.Name)))
+		if err != nil {
+			return nil, fmt.Errorf("checkpoint %q is incomplete: %w", label, err)
+		}
+		if digest(data) != f.SHA256 {
+			return nil, fmt.Errorf("checkpoint %q is corrupt: %s doesn't match its recorded hash", label, f.Name)
+		}
+		stored[f.Name] = data
+	}
+
+	var changed []string
+	for _, t := range targets {
+		f, ok := cp.find(t.Name)
+		if !ok {
+			continue
+		}
+		current, err := os.ReadFile(t.Path)
+		exists := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return changed, fmt.Errorf("failed to read %s: %w", t.Path, err)
+		}
+
+		if f.Missing {
+			if exists {
+				if err := os.Remove(t.Path); err != nil {
+					return changed, err
+				}
+				changed = append(changed, t.Name)
+			}
+			continue
+		}
+		if exists && digest(current) == f.SHA256 {
+			continue
+		}
+		if err := restoreFile(t.Path, stored[t.Name], f.Mode); err != nil {
+			return changed, fmt.Errorf("failed to restore %s: %w", t.Path, err)
+		}
+		changed = append(changed, t.Name)
+	}
+	return changed, nil
+}
+
+func (cp *Checkpoint) find(name string) (File, bool) {
+	for _, f := range cp.Files {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return File{}, false
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// restoreFile writes a checkpointed file back in place, creating its
+// directory if it has since been removed
+func restoreFile(path string, data []byte, mode fs.FileMode) error {
+	if mode == 0 {
+		mode = 0644
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return state.WriteFileAtomic(path, data, mode)
+}
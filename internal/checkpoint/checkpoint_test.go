@@ -0,0 +1,120 @@
+// ABOUTME: Tests for creating, listing, restoring, and deleting checkpoints
+// ABOUTME: Checks byte-for-byte round trips, deleting files that didn't exist, and refusing corrupt copies
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func setupTargets(t *testing.T) (string, []Target) {
+	t.Helper()
+	home := t.TempDir()
+	targets := Targets(filepath.Join(home, ".claude"), filepath.Join(home, ".claude.json"), filepath.Join(home, ".claudeup"))
+	writeFile(t, targets[0].Path, "{\"model\": \"opus\",\n  \"x\": 1}\n")
+	writeFile(t, targets[3].Path, `{"mcpServers":{}, "unmodelled": [1, 2]}`)
+	return filepath.Join(home, ".claudeup", "checkpoints"), targets
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	dir, targets := setupTargets(t)
+	settings, installed, claudeJSON := targets[0].Path, targets[1].Path, targets[3].Path
+	original := readFile(t, settings)
+
+	cp, err := Create(dir, "before-experiment", targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cp.Files) != len(targets) || cp.Files[0].Missing || !cp.Files[1].Missing {
+		t.Errorf("Expected settings stored and installed_plugins.json missing, got %+v", cp.Files)
+	}
+	if _, err := Create(dir, "before-experiment", targets); err == nil {
+		t.Error("Expected an existing label to be refused")
+	}
+
+	writeFile(t, settings, `{"model": "sonnet"}`)
+	writeFile(t, installed, `{"version": 2}`)
+	changed, err := Restore(dir, "before-experiment", targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"settings.json", "plugins/installed_plugins.json"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("Expected %v changed, got %v", want, changed)
+	}
+	if got := readFile(t, settings); got != original {
+		t.Errorf("Expected settings restored byte for byte, got %q", got)
+	}
+	if _, err := os.Stat(installed); !os.IsNotExist(err) {
+		t.Error("Expected a file created after the checkpoint to be deleted")
+	}
+	if readFile(t, claudeJSON) != `{"mcpServers":{}, "unmodelled": [1, 2]}` {
+		t.Error("Expected an unchanged file left alone")
+	}
+
+	if changed, err := Restore(dir, "before-experiment", targets); err != nil || len(changed) != 0 {
+		t.Errorf("Expected nothing to change restoring again, got %v, %v", changed, err)
+	}
+}
+
+func TestListAndDelete(t *testing.T) {
+	dir, targets := setupTargets(t)
+	for _, label := range []string{"one", "two"} {
+		if _, err := Create(dir, label, targets); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Create(dir, "../escape", targets); err == nil {
+		t.Error("Expected a label with a path to be refused")
+	}
+
+	cps, err := List(dir)
+	if err != nil || len(cps) != 2 || cps[0].Label != "one" {
+		t.Fatalf("Expected two checkpoints oldest first, got %v, %v", cps, err)
+	}
+	if err := Delete(dir, "one"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(dir, "one"); err == nil {
+		t.Error("Expected deleting a missing checkpoint to fail")
+	}
+	if cps, _ := List(dir); len(cps) != 1 || cps[0].Label != "two" {
+		t.Errorf("Expected only two left, got %v", cps)
+	}
+}
+
+func TestRestoreRefusesCorruptCheckpoint(t *testing.T) {
+	dir, targets := setupTargets(t)
+	if _, err := Create(dir, "cp", targets); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "cp", "files", ".claude.json"), "{}")
+	writeFile(t, targets[0].Path, "changed")
+
+	if _, err := Restore(dir, "cp", targets); err == nil {
+		t.Fatal("Expected a corrupt copy to be refused")
+	}
+	if readFile(t, targets[0].Path) != "changed" {
+		t.Error("Expected nothing written when a copy is corrupt")
+	}
+}
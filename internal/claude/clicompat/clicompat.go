@@ -0,0 +1,148 @@
+// ABOUTME: Compatibility matrix for the Claude CLI versions claudeup drives during apply
+// ABOUTME: The matrix is embedded so doctor can warn without network access
+package clicompat
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+//go:embed matrix.json
+var matrixJSON []byte
+
+// Status classifies an installed Claude CLI version against the matrix
+type Status string
+
+const (
+	// Unknown means the version couldn't be parsed
+	Unknown Status = "unknown"
+	// Unsupported versions have known problems with claudeup's apply logic
+	Unsupported Status = "unsupported"
+	// Older versions predate the tested range but have no known problems
+	Older Status = "older"
+	// Tested versions are within the range claudeup has been tested with
+	Tested Status = "tested"
+	// Newer versions are past the tested range
+	Newer Status = "newer"
+)
+
+// Matrix lists the Claude CLI versions claudeup has been tested with
+type Matrix struct {
+	// TestedFrom is the oldest tested version
+	TestedFrom string `json:"testedFrom"`
+	// TestedThrough is the newest tested version; missing parts match
+	// anything, so "2" covers every 2.x release
+	TestedThrough string `json:"testedThrough"`
+	// Known lists versions with problems
+	Known []KnownIssue `json:"known"`
+}
+
+// KnownIssue describes a problem affecting every version below Below
+type KnownIssue struct {
+	Below string `json:"below"`
+	Note  string `json:"note"`
+}
+
+// Result is the outcome of checking a version against the matrix
+type Result struct {
+	Version string   `json:"version"`
+	Status  Status   `json:"status"`
+	Notes   []string `json:"notes,omitempty"`
+}
+
+// Default returns the matrix embedded in claudeup
+func Default() Matrix {
+	var m Matrix
+	if err := json.Unmarshal(matrixJSON, &m); err != nil {
+		panic("clicompat: invalid embedded matrix: " + err.Error())
+	}
+	return m
+}
+
+// Check classifies version, as printed by 'claude --version'
+func (m Matrix) Check(version string) Result {
+	r := Result{Version: version}
+	if len(Parse(version)) == 0 {
+		r.Status = Unknown
+		return r
+	}
+
+	for _, k := range m.Known {
+		if Compare(version, k.Below) < 0 {
+			r.Notes = append(r.Notes, k.Note)
+		}
+	}
+
+	through := len(Parse(m.TestedThrough))
+	switch {
+	case len(r.Notes) > 0:
+		r.Status = Unsupported
+	case Compare(version, m.TestedFrom) < 0:
+		r.Status = Older
+	case compareParts(Parse(version), Parse(m.TestedThrough), through) > 0:
+		r.Status = Newer
+	default:
+		r.Status = Tested
+	}
+	return r
+}
+
+// Compare returns -1, 0, or 1 as a is older than, equal to, or newer than b
+func Compare(a, b string) int {
+	pa, pb := Parse(a), Parse(b)
+	n := len(pa)
+	if len(pb) > n {
+		n = len(pb)
+	}
+	return compareParts(pa, pb, n)
+}
+
+// compareParts compares the first n numeric parts, treating missing parts as 0
+func compareParts(a, b []int, n int) int {
+	for i := 0; i < n; i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Parse extracts numeric parts from a version string
+// Handles formats like "1.0.72", "claude 1.0.72", "v1.0.72", and
+// "2.0.14 (Claude Code)"
+func Parse(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	version = strings.TrimPrefix(version, "claude ")
+
+	parts := strings.Split(version, ".")
+	nums := make([]int, 0, len(parts))
+
+	for _, part := range parts {
+		// Extract numeric portion (handles cases like "72-beta")
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			break
+		}
+		num, err := strconv.Atoi(part[:end])
+		if err != nil {
+			break
+		}
+		nums = append(nums, num)
+	}
+	return nums
+}
@@ -0,0 +1,61 @@
+// ABOUTME: Unit tests for the Claude CLI compatibility matrix
+// ABOUTME: Covers version comparison and each compatibility status
+package clicompat
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	m := Matrix{
+		TestedFrom:    "2.0.0",
+		TestedThrough: "2.1",
+		Known:         []KnownIssue{{Below: "1.0.80", Note: "broken"}},
+	}
+
+	tests := []struct {
+		version string
+		want    Status
+	}{
+		{"1.0.72", Unsupported},
+		{"1.0.90", Older},
+		{"2.0.0", Tested},
+		{"2.0.14 (Claude Code)", Tested},
+		{"2.1.99", Tested},
+		{"2.2.0", Newer},
+		{"3.0.0", Newer},
+		{"unknown", Unknown},
+	}
+
+	for _, tt := range tests {
+		r := m.Check(tt.version)
+		if r.Status != tt.want {
+			t.Errorf("Check(%q) = %s, want %s", tt.version, r.Status, tt.want)
+		}
+		if (r.Status == Unsupported) != (len(r.Notes) > 0) {
+			t.Errorf("Check(%q) notes = %v", tt.version, r.Notes)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.80", "1.0.80", 0},
+		{"1.0", "1.0.80", -1},
+		{"v2.0.1", "2.0.0", 1},
+		{"2.0.14 (Claude Code)", "2.0.14", 0},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultMatrixParses(t *testing.T) {
+	m := Default()
+	if m.TestedFrom == "" || m.TestedThrough == "" {
+		t.Errorf("Embedded matrix is incomplete: %+v", m)
+	}
+}
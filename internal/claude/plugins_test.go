@@ -262,3 +262,80 @@ func TestPluginRegistryJSONMarshaling(t *testing.T) {
 		t.Error("Plugin version mismatch after JSON round-trip")
 	}
 }
+
+func TestLoadPluginsNormalizesV1(t *testing.T) {
+	tempDir := t.TempDir()
+	pluginsDir := filepath.Join(tempDir, "plugins")
+	if err := os.MkdirAll(pluginsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	v1 := `{"version": 1, "plugins": {"test-plugin@test-marketplace": {"version": "1.0.0", "installPath": "/test/path", "gitCommitSha": "abc123"}}}`
+	if err := os.WriteFile(filepath.Join(pluginsDir, "installed_plugins.json"), []byte(v1), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadPlugins(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Version != 2 {
+		t.Errorf("Expected normalized version 2, got %d", loaded.Version)
+	}
+
+	plugin, exists := loaded.GetPlugin("test-plugin@test-marketplace")
+	if !exists {
+		t.Fatal("Plugin should exist after normalization")
+	}
+	if plugin.Scope != "user" || plugin.InstallPath != "/test/path" || plugin.GitCommitSha != "abc123" {
+		t.Errorf("Unexpected normalized metadata: %+v", plugin)
+	}
+}
+
+func TestMigratePlugins(t *testing.T) {
+	tempDir := t.TempDir()
+	pluginsDir := filepath.Join(tempDir, "plugins")
+	if err := os.MkdirAll(pluginsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	registryFile := filepath.Join(pluginsDir, "installed_plugins.json")
+	v1 := `{"version": 1, "plugins": {"test-plugin@test-marketplace": {"version": "1.0.0"}}}`
+	if err := os.WriteFile(registryFile, []byte(v1), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	backupPath, err := MigratePlugins(tempDir)
+	if err != nil {
+		t.Fatalf("MigratePlugins failed: %v", err)
+	}
+	if backupPath == "" {
+		t.Fatal("Expected a backup path for a v1 registry")
+	}
+
+	backup, err := os.ReadFile(backupPath)
+	if err != nil {
+		t.Fatalf("Backup should exist: %v", err)
+	}
+	if string(backup) != v1 {
+		t.Error("Backup should contain the original v1 content")
+	}
+
+	version, err := PluginsSchemaVersion(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 2 {
+		t.Errorf("Expected v2 after migration, got %v", version)
+	}
+
+	// Second migration is a no-op
+	backupPath, err = MigratePlugins(tempDir)
+	if err != nil {
+		t.Fatalf("Second MigratePlugins failed: %v", err)
+	}
+	if backupPath != "" {
+		t.Errorf("Expected no backup for an already current registry, got %s", backupPath)
+	}
+}
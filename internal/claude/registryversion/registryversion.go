@@ -0,0 +1,86 @@
+// ABOUTME: Detects the schema version of Claude Code's installed_plugins.json
+// ABOUTME: Defines the legacy v1 shape so callers can normalize it to v2
+package registryversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Version identifies an installed_plugins.json schema
+type Version int
+
+const (
+	// Unknown means the schema could not be determined
+	Unknown Version = 0
+	// V1 maps each plugin name to a single metadata object
+	V1 Version = 1
+	// V2 maps each plugin name to an array of per-scope metadata objects
+	V2 Version = 2
+)
+
+// Current is the newest schema claudeup understands and writes
+const Current = V2
+
+// String returns a short display form such as "v2"
+func (v Version) String() string {
+	if v == Unknown {
+		return "unknown"
+	}
+	return fmt.Sprintf("v%d", int(v))
+}
+
+// PluginMetadataV1 is a plugin entry in the v1 schema (no scopes)
+type PluginMetadataV1 struct {
+	Version      string `json:"version"`
+	InstalledAt  string `json:"installedAt"`
+	LastUpdated  string `json:"lastUpdated"`
+	InstallPath  string `json:"installPath"`
+	GitCommitSha string `json:"gitCommitSha"`
+	IsLocal      bool   `json:"isLocal"`
+}
+
+// RegistryV1 is the v1 installed_plugins.json structure
+type RegistryV1 struct {
+	Version int                         `json:"version"`
+	Plugins map[string]PluginMetadataV1 `json:"plugins"`
+}
+
+// Detect inspects raw installed_plugins.json content and reports its schema.
+// The shape of the plugin entries wins over the declared version field,
+// since older Claude Code releases did not always bump it.
+func Detect(data []byte) (Version, error) {
+	var probe struct {
+		Version int                        `json:"version"`
+		Plugins map[string]json.RawMessage `json:"plugins"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return Unknown, fmt.Errorf("invalid plugin registry: %w", err)
+	}
+
+	for name, raw := range probe.Plugins {
+		trimmed := bytes.TrimSpace(raw)
+		if len(trimmed) == 0 {
+			continue
+		}
+		switch trimmed[0] {
+		case '[':
+			return V2, nil
+		case '{':
+			return V1, nil
+		default:
+			return Unknown, fmt.Errorf("unexpected entry for plugin %q in registry", name)
+		}
+	}
+
+	// Empty registry: fall back to the declared version
+	switch {
+	case probe.Version == int(V2):
+		return V2, nil
+	case probe.Version <= int(V1):
+		return V1, nil
+	default:
+		return Version(probe.Version), fmt.Errorf("unsupported plugin registry version %d (newest supported: %d)", probe.Version, Current)
+	}
+}
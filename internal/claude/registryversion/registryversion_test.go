@@ -0,0 +1,45 @@
+// ABOUTME: Unit tests for plugin registry schema detection
+// ABOUTME: Covers v1, v2, empty, mislabeled, and unsupported registries
+package registryversion
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Version
+		wantErr bool
+	}{
+		{"v2 with arrays", `{"version": 2, "plugins": {"a@m": [{"scope": "user"}]}}`, V2, false},
+		{"v1 with objects", `{"version": 1, "plugins": {"a@m": {"version": "1.0"}}}`, V1, false},
+		{"v1 without version field", `{"plugins": {"a@m": {"version": "1.0"}}}`, V1, false},
+		{"objects labeled v2", `{"version": 2, "plugins": {"a@m": {"version": "1.0"}}}`, V1, false},
+		{"empty v2", `{"version": 2, "plugins": {}}`, V2, false},
+		{"empty v1", `{"version": 1, "plugins": {}}`, V1, false},
+		{"future version", `{"version": 3, "plugins": {}}`, Version(3), true},
+		{"invalid entry", `{"version": 2, "plugins": {"a@m": "oops"}}`, Unknown, true},
+		{"invalid json", `{not json`, Unknown, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Detect([]byte(tc.data))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Detect() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("Detect() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVersionString(t *testing.T) {
+	if V2.String() != "v2" {
+		t.Errorf("Expected v2, got %s", V2.String())
+	}
+	if Unknown.String() != "unknown" {
+		t.Errorf("Expected unknown, got %s", Unknown.String())
+	}
+}
@@ -0,0 +1,83 @@
+// ABOUTME: Detects a running Claude Code that may be writing the files claudeup changes
+// ABOUTME: Lists claude processes with ps and checks for the lock Claude Code holds on .claude.json
+package claudeproc
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockStaleAfter is how long a lock can go untouched before it's treated as
+// left behind by a process that exited without releasing it
+const lockStaleAfter = 30 * time.Second
+
+// Process is a running Claude Code process
+type Process struct {
+	PID     int
+	Command string
+}
+
+// Running lists Claude Code processes other than this one. Platforms
+// without ps report none.
+func Running(ctx context.Context) ([]Process, error) {
+	if runtime.GOOS == "windows" {
+		return nil, nil
+	}
+	out, err := exec.CommandContext(ctx, "ps", "-A", "-o", "pid=", "-o", "args=").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parsePS(string(out), os.Getpid()), nil
+}
+
+// parsePS picks the Claude Code processes out of "pid args" lines
+func parsePS(out string, self int) []Process {
+	var procs []Process
+	for _, line := range strings.Split(out, "\n") {
+		pidField, args, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		pid, err := strconv.Atoi(pidField)
+		if err != nil || pid == self {
+			continue
+		}
+		args = strings.TrimSpace(args)
+		if isClaude(args) {
+			procs = append(procs, Process{PID: pid, Command: args})
+		}
+	}
+	return procs
+}
+
+// isClaude matches the native claude binary and the npm package run by node
+// or bun
+func isClaude(args string) bool {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return false
+	}
+	if filepath.Base(fields[0]) == "claude" {
+		return true
+	}
+	switch filepath.Base(fields[0]) {
+	case "node", "nodejs", "bun":
+		return len(fields) > 1 && (filepath.Base(fields[1]) == "claude" ||
+			strings.Contains(fields[1], "@anthropic-ai/claude-code/"))
+	}
+	return false
+}
+
+// Locked reports whether Claude Code holds the lock on path. The lock is a
+// path.lock directory kept fresh while a write is in progress; one that
+// hasn't been touched recently is ignored.
+func Locked(path string) bool {
+	info, err := os.Stat(path + ".lock")
+	return err == nil && time.Since(info.ModTime()) < lockStaleAfter
+}
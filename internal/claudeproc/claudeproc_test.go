@@ -0,0 +1,63 @@
+// ABOUTME: Unit tests for Claude Code process and lock detection
+// ABOUTME: Covers ps output parsing and lock freshness
+package claudeproc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParsePS(t *testing.T) {
+	out := `    1 /sbin/init
+  200 claude
+  201 /Users/me/.local/bin/claude --resume
+  202 node /usr/local/bin/claude
+  203 node /opt/node/lib/node_modules/@anthropic-ai/claude-code/cli.js
+  204 claudeup profile use work
+  205 vim claude.md
+  206 node server.js
+  300 claude
+`
+	procs := parsePS(out, 300)
+
+	var pids []int
+	for _, p := range procs {
+		pids = append(pids, p.PID)
+	}
+	want := []int{200, 201, 202, 203}
+	if len(pids) != len(want) {
+		t.Fatalf("parsePS() pids = %v, want %v", pids, want)
+	}
+	for i := range want {
+		if pids[i] != want[i] {
+			t.Errorf("parsePS() pids = %v, want %v", pids, want)
+		}
+	}
+	if procs[1].Command != "/Users/me/.local/bin/claude --resume" {
+		t.Errorf("Command = %q", procs[1].Command)
+	}
+}
+
+func TestLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".claude.json")
+	if Locked(path) {
+		t.Error("Expected no lock")
+	}
+
+	if err := os.Mkdir(path+".lock", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !Locked(path) {
+		t.Error("Expected a fresh lock to be held")
+	}
+
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path+".lock", old, old); err != nil {
+		t.Fatal(err)
+	}
+	if Locked(path) {
+		t.Error("Expected a stale lock to be ignored")
+	}
+}
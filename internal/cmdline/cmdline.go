@@ -0,0 +1,66 @@
+// ABOUTME: Builds external commands that also run on Windows, where npm installs claude and npx as .cmd shims
+// ABOUTME: cmd.exe runs those shims and re-parses their arguments, so each is quoted for it or refused
+package cmdline
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Command returns exec.CommandContext(ctx, name, args...), except that on
+// Windows a name resolving to a .cmd or .bat file gets a command line quoted
+// for cmd.exe. Arguments cmd.exe would expand or split however they're
+// quoted, such as %PATH% or a double quote, are refused rather than passed
+// on mangled.
+func Command(ctx context.Context, name string, args ...string) (*exec.Cmd, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if runtime.GOOS != "windows" || !IsBatch(cmd.Path) {
+		return cmd, nil
+	}
+	line, err := batchLine(cmd.Path, args)
+	if err != nil {
+		return nil, err
+	}
+	setCommandLine(cmd, line)
+	return cmd, nil
+}
+
+// Shell returns a command running script with sh -c. On Windows without
+// sh on the PATH, as Git for Windows puts it, cmd.exe runs script instead.
+func Shell(ctx context.Context, script string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("sh"); err != nil {
+			cmd := exec.CommandContext(ctx, "cmd.exe")
+			// /s keeps the script as typed between the outer quotes
+			setCommandLine(cmd, `cmd.exe /d /s /c "`+script+`"`)
+			return cmd
+		}
+	}
+	return exec.CommandContext(ctx, "sh", "-c", script)
+}
+
+// IsBatch reports whether path is a batch file, which Windows runs with
+// cmd.exe
+func IsBatch(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".cmd" || ext == ".bat"
+}
+
+// batchLine quotes path and every argument in double quotes, inside which
+// cmd.exe leaves & | < > ^ ( ) alone. Backslashes ending an argument are
+// doubled so the program doesn't read the closing quote as escaped.
+func batchLine(path string, args []string) (string, error) {
+	parts := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{path}, args...) {
+		if i := strings.IndexAny(arg, "\"%\r\n"); i >= 0 {
+			return "", fmt.Errorf("can't pass %q to %s: cmd.exe would reinterpret %q", arg, filepath.Base(path), arg[i])
+		}
+		trailing := len(arg) - len(strings.TrimRight(arg, `\`))
+		parts = append(parts, `"`+arg+strings.Repeat(`\`, trailing)+`"`)
+	}
+	return strings.Join(parts, " "), nil
+}
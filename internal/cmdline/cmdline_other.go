@@ -0,0 +1,9 @@
+//go:build !windows
+
+// ABOUTME: Non-Windows half of cmdline, where batch files aren't run specially
+// ABOUTME: Never called: Command only prepares command lines on Windows
+package cmdline
+
+import "os/exec"
+
+func setCommandLine(cmd *exec.Cmd, line string) {}
@@ -0,0 +1,68 @@
+// ABOUTME: Tests for building commands that run through Windows batch shims
+// ABOUTME: Checks the quoting cmd.exe sees and the arguments it would mangle
+package cmdline
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBatchLine(t *testing.T) {
+	line, err := batchLine(`C:\npm\claude.cmd`, []string{"mcp", "add", "-e", "TOKEN=a&b|c", "db", `C:\data\`, "(x) <y> ^z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"C:\npm\claude.cmd" "mcp" "add" "-e" "TOKEN=a&b|c" "db" "C:\data\\" "(x) <y> ^z"`
+	if line != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, line)
+	}
+
+	for _, arg := range []string{`say "hi"`, "%PATH%", "two\nlines"} {
+		if _, err := batchLine(`C:\npm\claude.cmd`, []string{arg}); err == nil || !strings.Contains(err.Error(), "claude.cmd") {
+			t.Errorf("Expected %q to be refused, got %v", arg, err)
+		}
+	}
+}
+
+func TestIsBatch(t *testing.T) {
+	for path, want := range map[string]bool{`C:\npm\npx.CMD`: true, "tool.bat": true, `C:\bin\claude.exe`: false, "/usr/bin/claude": false} {
+		if got := IsBatch(path); got != want {
+			t.Errorf("IsBatch(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestShell(t *testing.T) {
+	t.Setenv("CMDLINE_TEST_VALUE", "a b")
+	script := `echo "$CMDLINE_TEST_VALUE"`
+	if _, err := exec.LookPath("sh"); err != nil {
+		if runtime.GOOS != "windows" {
+			t.Skip("sh not available")
+		}
+		script = "echo %CMDLINE_TEST_VALUE%"
+	}
+	out, err := Shell(context.Background(), script).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "a b" {
+		t.Errorf("Expected the variable echoed, got %q", got)
+	}
+}
+
+func TestCommandElsewhereUsesGoQuoting(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("batch files are quoted for cmd.exe on Windows")
+	}
+	cmd, err := Command(context.Background(), "/opt/tool.cmd", "%PATH%", `"quoted"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cmd.Args, []string{"/opt/tool.cmd", "%PATH%", `"quoted"`}) {
+		t.Errorf("Unexpected args %v", cmd.Args)
+	}
+}
@@ -0,0 +1,12 @@
+// ABOUTME: Windows half of cmdline: hands a prepared command line to CreateProcess as is
+// ABOUTME: Go's own quoting follows the C runtime's rules, which cmd.exe doesn't
+package cmdline
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func setCommandLine(cmd *exec.Cmd, line string) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: line}
+}
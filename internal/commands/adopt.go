@@ -0,0 +1,275 @@
+// ABOUTME: adopt command building a curated starter profile from an unmanaged setup
+// ABOUTME: Groups plugins by marketplace, flags broken ones via doctor, and asks what to keep
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <name>",
+	Short: "Build a clean starter profile from your current setup",
+	Long: `Analyzes the plugins, marketplaces, and MCP servers Claude Code has now and
+builds a profile from the ones you choose to keep.
+
+Unlike 'profile save', which snapshots everything as it is, adopt runs the
+doctor checks first and flags what is stale or broken: plugins whose install
+path is gone, plugins from marketplaces that are missing, plugins failing
+their own checks, and MCP servers whose command isn't installed. Items are
+offered grouped by marketplace, with broken ones left out by default.
+Marketplaces no kept plugin comes from are dropped.
+
+With -y the suggested selection is saved without asking. The profile is
+saved but not applied; 'profile use' then removes what was left out.`,
+	Example: `  claudeup adopt mine
+  claudeup adopt mine -y`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdopt,
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+}
+
+// adoptItem is a plugin or MCP server offered for the adopted profile
+type adoptItem struct {
+	Name    string
+	Problem string // why the item is flagged, if it is
+	Broken  bool   // broken items start out unselected
+}
+
+// adoptGroup is a set of items offered together: the plugins of one
+// marketplace, or the MCP servers
+type adoptGroup struct {
+	Title       string
+	Marketplace string // registry name; "" for MCP servers
+	Items       []adoptItem
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	name := args[0]
+	profilesDir := getProfilesDir()
+	if _, err := os.Stat(filepath.Join(profilesDir, name+".json")); err == nil {
+		return fmt.Errorf("profile %q already exists; pick another name", name)
+	}
+
+	claudeJSONPath := profile.DefaultClaudeJSONPath()
+	snapshot, err := profile.Snapshot(name, claudeDir, claudeJSONPath)
+	if err != nil {
+		return fmt.Errorf("failed to read current setup: %w", err)
+	}
+	marketplaces, err := state.LoadMarketplaces(claudeDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to load marketplaces: %w", err)
+	}
+
+	out.Println("Checking your setup...")
+	report, err := collectDoctorReport(cmd.Context(), claudeDir)
+	if err != nil {
+		return err
+	}
+
+	groups := planAdoption(snapshot, marketplaces, report)
+	if len(groups) == 0 {
+		out.Println("Nothing to adopt: no plugins or MCP servers are installed.")
+		return nil
+	}
+	showAdoptionPlan(out, groups)
+
+	var plugins, servers []string
+	dropped := 0
+	for _, g := range groups {
+		kept, err := chooseAdopted(g)
+		if err != nil {
+			return err
+		}
+		dropped += len(g.Items) - len(kept)
+		if g.Marketplace == "" {
+			servers = append(servers, kept...)
+		} else {
+			plugins = append(plugins, kept...)
+		}
+	}
+
+	p := buildAdoptedProfile(name, snapshot, marketplaces, plugins, servers)
+	if err := profile.Save(profilesDir, p); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	out.Println()
+	out.Printf("✓ Saved profile %q: %d plugins, %d MCP servers, %d marketplaces\n", name, len(p.Plugins), len(p.MCPServers), len(p.Marketplaces))
+	for _, s := range unpinnedNpxServers(p) {
+		out.Printf("  → %s runs an unpinned npx package; 'claudeup mcp pin %s' locks its version\n", s, name)
+	}
+	if dropped > 0 {
+		out.Printf("  → %d items were left out; 'claudeup profile use %s' removes them from Claude Code\n", dropped, name)
+	}
+	out.Printf("  → Review it with 'claudeup profile show %s'\n", name)
+	return nil
+}
+
+// planAdoption groups the snapshot's plugins by marketplace and its MCP
+// servers together, flagging problems found by doctor
+func planAdoption(snapshot *profile.Profile, marketplaces state.MarketplaceRegistry, report *DoctorReport) []adoptGroup {
+	problems := make(map[string]adoptItem)
+	for _, issue := range report.PathIssues {
+		if issue.CanAutoFix {
+			problems[issue.PluginName] = adoptItem{Problem: "stale install path; 'claudeup doctor --fix' repairs it"}
+		} else {
+			problems[issue.PluginName] = adoptItem{Problem: "install path is missing", Broken: true}
+		}
+	}
+	missingClones := make(map[string]bool)
+	for _, m := range report.Marketplaces {
+		missingClones[m.Name] = !m.OK
+	}
+	for _, c := range report.Checks {
+		plugin, check, ok := strings.Cut(c.Name, "/")
+		if ok && !c.OK() && slices.Contains(snapshot.Plugins, plugin) {
+			problems[plugin] = adoptItem{Problem: "fails its " + check + " check", Broken: true}
+		}
+	}
+
+	var groups []adoptGroup
+	byMarketplace := make(map[string]int)
+	for _, plugin := range snapshot.Plugins {
+		item := problems[plugin]
+		item.Name = plugin
+		_, mkt, _ := strings.Cut(plugin, "@")
+		meta, registered := marketplaces[mkt]
+		switch {
+		case !registered:
+			item.Problem, item.Broken = "marketplace "+mkt+" isn't registered", true
+		case missingClones[mkt]:
+			item.Problem, item.Broken = "marketplace clone is missing", true
+		}
+		if item.Problem == "" && slices.Contains(snapshot.Disabled.Plugins, plugin) {
+			item.Problem = "disabled; stays disabled in the profile"
+		}
+
+		i, ok := byMarketplace[mkt]
+		if !ok {
+			title := "Marketplace " + mkt
+			if registered {
+				title += " (" + profile.RegisteredMarketplace(meta.Source).DisplayName() + ")"
+			}
+			groups = append(groups, adoptGroup{Title: title, Marketplace: mkt})
+			i = len(groups) - 1
+			byMarketplace[mkt] = i
+		}
+		groups[i].Items = append(groups[i].Items, item)
+	}
+	slices.SortFunc(groups, func(a, b adoptGroup) int { return strings.Compare(a.Marketplace, b.Marketplace) })
+
+	if len(snapshot.MCPServers) > 0 {
+		mcpGroup := adoptGroup{Title: "MCP servers"}
+		for _, s := range snapshot.MCPServers {
+			item := adoptItem{Name: s.Name}
+			if _, err := exec.LookPath(s.Command); err != nil {
+				item.Problem, item.Broken = fmt.Sprintf("command %q not found", s.Command), true
+			}
+			mcpGroup.Items = append(mcpGroup.Items, item)
+		}
+		groups = append(groups, mcpGroup)
+	}
+	return groups
+}
+
+func showAdoptionPlan(out ui.Printer, groups []adoptGroup) {
+	for _, g := range groups {
+		out.Println()
+		out.Printf("━━━ %s ━━━\n", g.Title)
+		for _, item := range g.Items {
+			switch {
+			case item.Broken:
+				out.Printf("  ✗ %s: %s\n", item.Name, item.Problem)
+			case item.Problem != "":
+				out.Printf("  ⚠ %s: %s\n", item.Name, item.Problem)
+			default:
+				out.Printf("  ✓ %s\n", item.Name)
+			}
+		}
+	}
+}
+
+// chooseAdopted asks which of the group's items to keep, suggesting all
+// but the broken ones, and returns their names
+func chooseAdopted(g adoptGroup) ([]string, error) {
+	options := make([]string, len(g.Items))
+	var defaults []int
+	for i, item := range g.Items {
+		options[i] = item.Name
+		if !item.Broken {
+			defaults = append(defaults, i)
+		}
+	}
+	chosen, err := ui.ChooseMany("Keep from "+g.Title+":", options, defaults)
+	if err != nil {
+		return nil, err
+	}
+	kept := make([]string, len(chosen))
+	for i, idx := range chosen {
+		kept[i] = g.Items[idx].Name
+	}
+	return kept, nil
+}
+
+// buildAdoptedProfile keeps the chosen plugins and MCP servers from the
+// snapshot, and the marketplaces the kept plugins come from
+func buildAdoptedProfile(name string, snapshot *profile.Profile, marketplaces state.MarketplaceRegistry, plugins, servers []string) *profile.Profile {
+	p := &profile.Profile{
+		Name:        name,
+		Description: "Adopted from an existing Claude Code setup",
+		Plugins:     plugins,
+	}
+	seen := make(map[string]bool)
+	for _, plugin := range plugins {
+		_, mkt, _ := strings.Cut(plugin, "@")
+		if meta, ok := marketplaces[mkt]; ok && !seen[mkt] {
+			seen[mkt] = true
+			p.Marketplaces = append(p.Marketplaces, profile.RegisteredMarketplace(meta.Source))
+		}
+		if slices.Contains(snapshot.Disabled.Plugins, plugin) {
+			p.Disabled.Plugins = append(p.Disabled.Plugins, plugin)
+		}
+	}
+	for _, s := range snapshot.MCPServers {
+		if slices.Contains(servers, s.Name) {
+			p.MCPServers = append(p.MCPServers, s)
+		}
+	}
+	// Plugin MCP servers turned off stay off, if their plugin is kept
+	for _, ref := range snapshot.Disabled.MCPServers {
+		plugin, _, _ := strings.Cut(ref, ":")
+		if slices.Contains(plugins, plugin) {
+			p.Disabled.MCPServers = append(p.Disabled.MCPServers, ref)
+		}
+	}
+	return p
+}
+
+// unpinnedNpxServers names the profile's npx MCP servers without a pinned
+// version
+func unpinnedNpxServers(p *profile.Profile) []string {
+	var names []string
+	for _, s := range p.MCPServers {
+		if s.Version == "" && len(s.NpmPackages()) == 1 {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
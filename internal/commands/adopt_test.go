@@ -0,0 +1,102 @@
+// ABOUTME: Tests for 'claudeup adopt'
+// ABOUTME: Plans an adoption from a fixed snapshot and doctor report, and builds the profile
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/doctor"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+func testAdoption() (*profile.Profile, state.MarketplaceRegistry, *DoctorReport) {
+	snapshot := &profile.Profile{
+		Plugins: []string{"ok@sp", "gone@sp", "stale@sp", "off@sp", "lost@gone", "odd@acme"},
+		MCPServers: []profile.MCPServer{
+			{Name: "ctx", Command: "sh", Args: []string{"-c", "true"}},
+			{Name: "nope", Command: "definitely-not-installed-mcp"},
+		},
+		Disabled: profile.DisabledConfig{Plugins: []string{"off@sp"}, MCPServers: []string{"ok@sp:db", "gone@sp:x"}},
+	}
+	marketplaces := state.MarketplaceRegistry{
+		"sp":   {Source: state.MarketplaceSource{Source: "github", Repo: "obra/superpowers"}, InstallLocation: "/mp/sp"},
+		"acme": {Source: state.MarketplaceSource{Source: "github", Repo: "acme/plugins"}, InstallLocation: "/mp/acme"},
+	}
+	report := &DoctorReport{
+		Marketplaces: []MarketplaceCheck{{Name: "sp", OK: true}, {Name: "acme", OK: true}},
+		PathIssues: []PathIssue{
+			{PluginName: "gone@sp", IssueType: "not_found"},
+			{PluginName: "stale@sp", IssueType: "missing_subdirectory", CanAutoFix: true},
+		},
+		Checks: []doctor.Result{{Name: "odd@acme/selftest", Status: doctor.StatusFailed}},
+	}
+	return snapshot, marketplaces, report
+}
+
+func TestPlanAdoption(t *testing.T) {
+	groups := planAdoption(testAdoption())
+	if len(groups) != 4 {
+		t.Fatalf("Expected acme, gone, sp, and MCP groups, got %+v", groups)
+	}
+	if groups[0].Marketplace != "acme" || groups[1].Marketplace != "gone" || groups[2].Marketplace != "sp" || groups[3].Marketplace != "" {
+		t.Errorf("Unexpected group order %+v", groups)
+	}
+	if !strings.Contains(groups[2].Title, "obra/superpowers") {
+		t.Errorf("Expected the marketplace source in the title, got %q", groups[2].Title)
+	}
+
+	items := make(map[string]adoptItem)
+	for _, g := range groups {
+		for _, item := range g.Items {
+			items[item.Name] = item
+		}
+	}
+	tests := []struct {
+		name    string
+		broken  bool
+		problem string
+	}{
+		{"ok@sp", false, ""},
+		{"gone@sp", true, "install path is missing"},
+		{"stale@sp", false, "stale install path"},
+		{"off@sp", false, "disabled"},
+		{"lost@gone", true, "isn't registered"},
+		{"odd@acme", true, "fails its selftest check"},
+		{"ctx", false, ""},
+		{"nope", true, "not found"},
+	}
+	for _, tt := range tests {
+		item := items[tt.name]
+		if item.Broken != tt.broken || (tt.problem == "") != (item.Problem == "") || !strings.Contains(item.Problem, tt.problem) {
+			t.Errorf("%s: expected broken=%v problem %q, got %+v", tt.name, tt.broken, tt.problem, item)
+		}
+	}
+}
+
+func TestBuildAdoptedProfile(t *testing.T) {
+	snapshot, marketplaces, _ := testAdoption()
+	p := buildAdoptedProfile("mine", snapshot, marketplaces, []string{"ok@sp", "off@sp"}, []string{"ctx"})
+
+	if strings.Join(p.Plugins, " ") != "ok@sp off@sp" || len(p.MCPServers) != 1 || p.MCPServers[0].Name != "ctx" {
+		t.Errorf("Unexpected items %v %+v", p.Plugins, p.MCPServers)
+	}
+	if len(p.Marketplaces) != 1 || p.Marketplaces[0].Repo != "obra/superpowers" {
+		t.Errorf("Expected only the kept plugins' marketplace, got %+v", p.Marketplaces)
+	}
+	if strings.Join(p.Disabled.Plugins, " ") != "off@sp" || strings.Join(p.Disabled.MCPServers, " ") != "ok@sp:db" {
+		t.Errorf("Expected disabled items of kept plugins only, got %+v", p.Disabled)
+	}
+}
+
+func TestUnpinnedNpxServers(t *testing.T) {
+	p := &profile.Profile{MCPServers: []profile.MCPServer{
+		{Name: "loose", Command: "npx", Args: []string{"-y", "@acme/mcp"}},
+		{Name: "pinned", Command: "npx", Args: []string{"-y", "@acme/mcp@1.2.0"}, Package: "@acme/mcp", Version: "1.2.0"},
+		{Name: "local", Command: "node", Args: []string{"server.js"}},
+	}}
+	if got := unpinnedNpxServers(p); len(got) != 1 || got[0] != "loose" {
+		t.Errorf("Expected only the unpinned npx server, got %v", got)
+	}
+}
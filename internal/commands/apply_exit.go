@@ -0,0 +1,42 @@
+// ABOUTME: --fail-on-error for commands that apply a profile
+// ABOUTME: Turns partial failures into a non-zero exit, on by default in CI
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+var failOnError bool
+
+// ciEnvVars are set by common CI systems
+var ciEnvVars = []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "BUILDKITE", "CIRCLECI", "JENKINS_URL", "TF_BUILD"}
+
+// runningInCI reports whether a CI system's environment variable is set
+func runningInCI() bool {
+	for _, name := range ciEnvVars {
+		if v := os.Getenv(name); v != "" && v != "false" && v != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+func addFailOnErrorFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&failOnError, "fail-on-error", runningInCI(), "Exit non-zero if any change fails (default true in CI)")
+}
+
+// applyExitError returns an error when changes failed and --fail-on-error
+// is set, so the command exits non-zero after reporting its results
+func applyExitError(result *profile.ApplyResult) error {
+	if !failOnError || len(result.Errors) == 0 {
+		return nil
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return fmt.Errorf("%d of %d changes failed", failed, len(result.Steps))
+	}
+	return fmt.Errorf("apply finished with %d errors", len(result.Errors))
+}
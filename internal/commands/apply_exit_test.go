@@ -0,0 +1,61 @@
+// ABOUTME: Tests for --fail-on-error and its CI default
+// ABOUTME: Clears CI variables so results don't depend on where tests run
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+func clearCIEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range ciEnvVars {
+		t.Setenv(name, "")
+	}
+}
+
+func TestRunningInCI(t *testing.T) {
+	clearCIEnv(t)
+	if runningInCI() {
+		t.Error("Expected no CI without CI variables")
+	}
+
+	t.Setenv("CI", "false")
+	if runningInCI() {
+		t.Error("Expected CI=false not to count as CI")
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	if !runningInCI() {
+		t.Error("Expected GITHUB_ACTIONS=true to count as CI")
+	}
+}
+
+func TestApplyExitError(t *testing.T) {
+	defer func(v bool) { failOnError = v }(failOnError)
+
+	result := &profile.ApplyResult{
+		Steps: []profile.ApplyStep{
+			{Result: profile.StepDone},
+			{Result: profile.StepFailed, Err: errors.New("boom")},
+		},
+		Errors: []error{errors.New("boom")},
+	}
+
+	failOnError = false
+	if err := applyExitError(result); err != nil {
+		t.Errorf("Expected no error without --fail-on-error, got %v", err)
+	}
+
+	failOnError = true
+	err := applyExitError(result)
+	if err == nil || err.Error() != "1 of 2 changes failed" {
+		t.Errorf("Expected '1 of 2 changes failed', got %v", err)
+	}
+
+	if err := applyExitError(&profile.ApplyResult{}); err != nil {
+		t.Errorf("Expected no error for a clean apply, got %v", err)
+	}
+}
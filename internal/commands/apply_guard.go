@@ -0,0 +1,75 @@
+// ABOUTME: The checks a profile apply passes first and what it records once done
+// ABOUTME: Shared by 'profile use' and the HTTP API of 'claudeup serve', so both guard and record applies alike
+package commands
+
+import (
+	"context"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/snapshot"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+// checkApply refuses an apply while Claude Code is running, or when a
+// plugin it would install fails the configured audit
+func checkApply(ctx context.Context, out ui.Printer, diff *profile.Diff) error {
+	if err := checkClaudeNotRunning(ctx, out); err != nil {
+		return err
+	}
+	return auditPluginsForApply(out, diff.PluginsToInstall)
+}
+
+// recordApply records a successful apply of p: the saved profiles named
+// in saved are stamped, p becomes the active profile, remembering the one
+// it replaced and before, the snapshot taken first, for 'profile back',
+// and p and the state's content hash are kept for drift unless only part
+// of it was applied. Addons layer on top of whatever is active, so they
+// change neither. Checksums of the installed plugins are recorded for
+// 'claudeup verify'. Returns why the active profile couldn't be saved.
+func recordApply(out ui.Printer, p *profile.Profile, saved, before string, result *profile.ApplyResult, partial bool) error {
+	stampApplied(out, saved)
+	var err error
+	if !p.IsAddon() {
+		err = recordActiveProfile(out, p.Name, before)
+		if !partial {
+			recordAppliedProfile(out, p)
+		}
+	}
+	recordPluginChecksums(out, claudeDir, append(result.PluginsInstalled, result.PluginsAlreadyPresent...))
+	return err
+}
+
+// beforeSwitchKey carries the ID of the snapshot taken before an apply
+// through the API's apply hooks
+type beforeSwitchKey struct{}
+
+// serveBeforeApply checks an apply requested through the API as 'profile
+// use' does, and snapshots the state for 'profile back'
+func serveBeforeApply(out ui.Printer) func(context.Context, *profile.Profile, *profile.Diff) (context.Context, error) {
+	return func(ctx context.Context, p *profile.Profile, diff *profile.Diff) (context.Context, error) {
+		if err := checkApply(ctx, out, diff); err != nil {
+			return ctx, err
+		}
+		before := snapshotBeforeSwitch(out, p, profile.DefaultClaudeJSONPath())
+		return context.WithValue(ctx, beforeSwitchKey{}, before), nil
+	}
+}
+
+// serveAfterApply records an apply made through the API as 'profile use'
+// does, or drops the snapshot taken before it when it failed
+func serveAfterApply(out ui.Printer) func(context.Context, *profile.Profile, *profile.ApplyResult, error) error {
+	return func(ctx context.Context, p *profile.Profile, result *profile.ApplyResult, applyErr error) error {
+		before, _ := ctx.Value(beforeSwitchKey{}).(string)
+		if applyErr != nil {
+			if before != "" {
+				snapshot.Remove(snapshot.DefaultDir(), before)
+			}
+			return nil
+		}
+		applied, err := p.ForThisMachine()
+		if err != nil {
+			return err
+		}
+		return recordApply(out, applied, p.Name, before, result, false)
+	}
+}
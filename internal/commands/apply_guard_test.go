@@ -0,0 +1,64 @@
+// ABOUTME: Tests for the steps shared by 'profile use' and applies through the HTTP API
+// ABOUTME: Checks an API apply records the active, previous, and applied profile as the CLI does
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/snapshot"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func TestServeAfterApplyRecordsLikeProfileUse(t *testing.T) {
+	paths := pathctx.ForHome(t.TempDir())
+	defer pathctx.Override(paths)()
+	oldClaudeDir := claudeDir
+	claudeDir = paths.ClaudeDir
+	defer func() { claudeDir = oldClaudeDir }()
+	out := ui.NewPrinter(&strings.Builder{}, &strings.Builder{}, false)
+
+	if err := setActiveProfile("backend"); err != nil {
+		t.Fatal(err)
+	}
+	before := &snapshot.Snapshot{ID: "1-before-frontend"}
+	if err := snapshot.Save(snapshot.DefaultDir(), before); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), beforeSwitchKey{}, before.ID)
+	p := &profile.Profile{Name: "frontend", Plugins: []string{"a@m"}}
+	if err := serveAfterApply(out)(ctx, p, &profile.ApplyResult{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Preferences.ActiveProfile != "frontend" || cfg.PreviousProfile != (config.PreviousProfile{Name: "backend", Snapshot: before.ID}) {
+		t.Errorf("Expected frontend active with backend remembered for 'profile back', got %q and %+v", cfg.Preferences.ActiveProfile, cfg.PreviousProfile)
+	}
+	if _, err := os.Stat(filepath.Join(getAppliedDir(), "frontend.json")); err != nil {
+		t.Errorf("Expected the applied profile recorded: %v", err)
+	}
+
+	// A failed apply drops the snapshot taken before it
+	failed := &snapshot.Snapshot{ID: "2-before-backend"}
+	if err := snapshot.Save(snapshot.DefaultDir(), failed); err != nil {
+		t.Fatal(err)
+	}
+	ctx = context.WithValue(context.Background(), beforeSwitchKey{}, failed.ID)
+	if err := serveAfterApply(out)(ctx, &profile.Profile{Name: "backend"}, nil, errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(snapshot.DefaultDir(), failed.ID+".json")); !os.IsNotExist(err) {
+		t.Error("Expected the failed apply's snapshot removed")
+	}
+}
@@ -0,0 +1,32 @@
+// ABOUTME: --purge for commands that apply a profile
+// ABOUTME: Deletes the cached copies of plugins the apply uninstalled
+package commands
+
+import (
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+var purgeCaches bool
+
+func addPurgeFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&purgeCaches, "purge", false, "Delete the cached copies of uninstalled plugins")
+}
+
+// purgeCandidates records where plugins are installed before an apply, so
+// the caches of the ones it uninstalls can be found afterwards. Without
+// --purge there is nothing to record.
+func purgeCandidates(claudeDir string) map[string]string {
+	if !purgeCaches {
+		return nil
+	}
+	return profile.InstallPaths(claudeDir)
+}
+
+// purgeRemovedPlugins deletes the caches of plugins the apply uninstalled
+func purgeRemovedPlugins(claudeDir string, candidates map[string]string, result *profile.ApplyResult) {
+	if candidates == nil {
+		return
+	}
+	profile.PurgePluginCaches(claudeDir, candidates, result)
+}
@@ -0,0 +1,110 @@
+// ABOUTME: Bootstrap command that writes a shell script onboarding a new machine with a profile
+// ABOUTME: The script installs a verified claudeup release and runs setup without asking questions
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/bootstrap"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bootstrapProfile        string
+	bootstrapOutput         string
+	bootstrapRelease        string
+	bootstrapAssumeExisting string
+	bootstrapInstaller      string
+)
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Write a script that sets up a new machine with a profile",
+	Long: `Writes a self-contained bash script for macOS and Linux, for onboarding docs
+and MDM tooling. The script downloads a claudeup release, checks it against
+the release's checksums.txt, installs it to ~/.local/bin (or
+$CLAUDEUP_INSTALL_DIR), writes the profile into ~/.claudeup/profiles, and
+runs 'claudeup setup --yes' with it.
+
+The profile is copied into the script, so it needn't exist on the new
+machine; addons given as "base +addon" are merged into it. The script
+installs the release this claudeup was built from, or the latest release
+for development builds.`,
+	Example: `  claudeup bootstrap --profile work -o bootstrap.sh
+  claudeup bootstrap --profile "work +team/tools" --release v1.4.0 -o bootstrap.sh`,
+	Args: cobra.NoArgs,
+	RunE: runBootstrap,
+}
+
+func init() {
+	rootCmd.AddCommand(bootstrapCmd)
+	bootstrapCmd.Flags().StringVar(&bootstrapProfile, "profile", "", "Profile the script sets up, with any +addons")
+	bootstrapCmd.Flags().StringVarP(&bootstrapOutput, "output", "o", "", "Script file to write (default: standard output)")
+	bootstrapCmd.Flags().StringVar(&bootstrapRelease, "release", "", "claudeup release tag to install, or latest (default: this version)")
+	bootstrapCmd.Flags().StringVar(&bootstrapAssumeExisting, "assume-existing", "save-as=my-previous-setup", "How setup handles an existing installation: save-as=<name>, continue, or abort")
+	bootstrapCmd.Flags().StringVar(&bootstrapInstaller, "installer", "", "How setup installs the Claude CLI: auto, brew, npm, winget, download, or script")
+	bootstrapCmd.MarkFlagRequired("profile")
+}
+
+func runBootstrap(cmd *cobra.Command, args []string) error {
+	if _, err := parseAssumeExisting(bootstrapAssumeExisting); err != nil {
+		return err
+	}
+	if bootstrapInstaller != "" {
+		if _, err := pickInstaller(bootstrapInstaller, func(string) (string, error) { return "", nil }); err != nil {
+			return err
+		}
+	}
+
+	base, addons, err := loadProfileArgs(getProfilesDir(), nil, strings.Fields(bootstrapProfile))
+	if err != nil {
+		return err
+	}
+	if base == nil {
+		return fmt.Errorf("no base profile in %q", bootstrapProfile)
+	}
+	p := base
+	if len(addons) > 0 {
+		if p, err = profile.Merge(base, addons...); err != nil {
+			return err
+		}
+	}
+
+	version := bootstrapVersion(bootstrapRelease, cmd.Root().Version)
+	script, err := bootstrap.Script(bootstrap.Options{
+		Profile:        p,
+		Version:        version,
+		AssumeExisting: bootstrapAssumeExisting,
+		Installer:      bootstrapInstaller,
+		GeneratedBy:    cmd.Root().Version,
+	})
+	if err != nil {
+		return err
+	}
+
+	if bootstrapOutput == "" {
+		fmt.Print(script)
+		return nil
+	}
+	if err := os.WriteFile(bootstrapOutput, []byte(script), 0755); err != nil {
+		return fmt.Errorf("failed to write script: %w", err)
+	}
+	ui.PrinterFrom(cmd.Context()).Printf("✓ Wrote %s: installs claudeup %s and sets up %s\n", bootstrapOutput, version, p.Name)
+	return nil
+}
+
+// bootstrapVersion is the release the script installs: --release, else
+// this build's version when it's a release tag, else the latest release
+func bootstrapVersion(release, version string) string {
+	if release != "" {
+		return release
+	}
+	if strings.HasPrefix(version, "v") && !strings.Contains(version, "-") {
+		return version
+	}
+	return "latest"
+}
@@ -0,0 +1,22 @@
+// ABOUTME: Tests for the bootstrap command
+// ABOUTME: Checks which claudeup release a generated script installs
+package commands
+
+import "testing"
+
+func TestBootstrapVersion(t *testing.T) {
+	tests := []struct {
+		release, version, want string
+	}{
+		{"", "v1.4.0", "v1.4.0"},
+		{"", "dev", "latest"},
+		{"", "v1.4.0-3-gabc123", "latest"},
+		{"v1.2.0", "v1.4.0", "v1.2.0"},
+		{"latest", "v1.4.0", "latest"},
+	}
+	for _, tt := range tests {
+		if got := bootstrapVersion(tt.release, tt.version); got != tt.want {
+			t.Errorf("bootstrapVersion(%q, %q) = %q, want %q", tt.release, tt.version, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// ABOUTME: Bundle subcommands for offline, air-gapped profile installs
+// ABOUTME: Packages a profile with its marketplaces and plugin caches and restores it without network
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/claudeup/claudeup/internal/bundle"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var bundleOutput string
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Package profiles for machines without network access",
+	Long: `Bundles package a profile together with the marketplace clones (at their
+current commits) and plugin caches it needs, so it can be applied on a
+machine that cannot reach GitHub or other marketplace sources.`,
+}
+
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create <profile>",
+	Short: "Package a profile and its plugins into a bundle",
+	Long: `Packages a profile into a gzipped tarball. Every plugin in the profile must
+already be installed on this machine - apply the profile first.`,
+	Example: `  claudeup bundle create backend -o backend.tar.gz`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    runBundleCreate,
+}
+
+var bundleApplyCmd = &cobra.Command{
+	Use:   "apply <bundle.tar.gz>",
+	Short: "Apply a bundle without network access",
+	Long: `Restores the marketplaces and plugins from a bundle, then applies the
+bundled profile's MCP servers and removals. Marketplaces and plugins are
+never fetched from the network.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBundleApply,
+}
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleCreateCmd)
+	bundleCmd.AddCommand(bundleApplyCmd)
+
+	bundleCreateCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "Bundle file to write (default <profile>.tar.gz)")
+	addFailOnErrorFlag(bundleApplyCmd)
+	addForceFlag(bundleApplyCmd)
+	addReplayFlags(bundleApplyCmd)
+}
+
+func runBundleCreate(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	name := args[0]
+	p, err := loadProfileWithFallback(getProfilesDir(), name)
+	if err != nil {
+		return fmt.Errorf("profile %q not found: %w", name, err)
+	}
+
+	output := bundleOutput
+	if output == "" {
+		output = name + ".tar.gz"
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	m, err := bundle.Create(f, p, claudeDir)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return err
+	}
+
+	for _, mp := range m.Marketplaces {
+		commit := mp.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if commit == "" {
+			commit = "unknown commit"
+		}
+		out.Printf("  ✓ Marketplace %s (%s)\n", mp.Name, commit)
+	}
+	for _, pl := range m.Plugins {
+		out.Printf("  ✓ %s\n", pl.Name)
+	}
+	out.Println()
+	out.Printf("✓ Bundle written to %s\n", output)
+	return nil
+}
+
+func runBundleApply(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	claudeJSONPath := profile.DefaultClaudeJSONPath()
+
+	// Stage next to the destination so restored directories can be renamed into place
+	stagingParent := filepath.Join(claudeDir, "plugins")
+	if err := os.MkdirAll(stagingParent, 0755); err != nil {
+		return err
+	}
+	staging, err := os.MkdirTemp(stagingParent, ".claudeup-bundle-")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	m, p, err := bundle.Extract(f, staging)
+	if err != nil {
+		return err
+	}
+
+	diff, err := profile.ComputeDiff(p, claudeDir, claudeJSONPath)
+	if err != nil {
+		return fmt.Errorf("failed to compute changes: %w", err)
+	}
+
+	executor, finish, err := claudeExecutor(out)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	out.Printf("Bundle: %s (created %s)\n", m.Profile, m.Created.Local().Format("2006-01-02 15:04"))
+	out.Printf("  %d marketplaces, %d plugins\n", len(m.Marketplaces), len(m.Plugins))
+	out.Println()
+	showDiff(out, diff)
+	out.Println()
+
+	if err := checkClaudeNotRunning(cmd.Context(), out); err != nil {
+		return err
+	}
+	if !confirmProceed(out) {
+		out.Println("Cancelled.")
+		return nil
+	}
+
+	out.Println()
+	out.Println("Restoring from bundle...")
+	ctx := applyStarting(cmd.Context(), out, "bundle", p.Name, diff)
+	if err := bundle.Install(staging, m, claudeDir); err != nil {
+		return err
+	}
+
+	// Plugins and marketplaces now come from the bundle; only run the
+	// local-only steps (removals and MCP servers) through the CLI
+	offline := *diff
+	offline.PluginsToInstall = nil
+	offline.MarketplacesToAdd = nil
+
+	result, err := profile.ApplyPlanned(ctx, &offline, claudeDir, buildSecretChain(), executor)
+	recordApplyFrom(ctx, out, "bundle", p.Name, diff, result, err)
+	if err != nil {
+		return applyFailed(out, result, err)
+	}
+	result.PluginsInstalled = m.PluginNames()
+	for _, mp := range m.Marketplaces {
+		result.MarketplacesAdded = append(result.MarketplacesAdded, mp.Name)
+	}
+	result.Steps = append(bundleSteps(m), result.Steps...)
+
+	showApplyResults(out, result)
+
+	// Keep a copy of the profile so it shows up in 'profile list'
+	if err := profile.Save(getProfilesDir(), p); err != nil {
+		out.Printf("  ⚠ Could not save profile: %v\n", err)
+	}
+	stampApplied(out, p.Name)
+	if err := setActiveProfile(p.Name); err != nil {
+		out.Printf("  ⚠ Could not save active profile: %v\n", err)
+	}
+	recordAppliedProfile(out, p)
+	recordPluginChecksums(out, claudeDir, m.PluginNames())
+
+	out.Println()
+	if len(result.Errors) > 0 {
+		out.Println("⚠ Bundle applied with errors")
+		return applyExitError(result)
+	}
+	out.Println("✓ Bundle applied!")
+	return nil
+}
+
+// bundleSteps lists what was restored from the bundle for the summary table
+func bundleSteps(m *bundle.Manifest) []profile.ApplyStep {
+	var steps []profile.ApplyStep
+	for _, mp := range m.Marketplaces {
+		steps = append(steps, profile.ApplyStep{Item: profile.DiffItem{Action: "restore", Subsystem: profile.SubsystemMarketplaces, Name: mp.Name}, Result: profile.StepDone})
+	}
+	for _, name := range m.PluginNames() {
+		steps = append(steps, profile.ApplyStep{Item: profile.DiffItem{Action: "restore", Subsystem: profile.SubsystemPlugins, Name: name}, Result: profile.StepDone})
+	}
+	return steps
+}
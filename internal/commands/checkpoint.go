@@ -0,0 +1,205 @@
+// ABOUTME: checkpoint subcommands saving and restoring exact copies of Claude's registry files
+// ABOUTME: Implements create, restore, list, and delete; restore keeps the state it replaces as pre-restore
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/checkpoint"
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// preRestoreCheckpoint holds the state the last restore replaced
+const preRestoreCheckpoint = "pre-restore"
+
+var checkpointCmd = &cobra.Command{
+	Use:   "checkpoint",
+	Short: "Save and restore exact copies of Claude's configuration files",
+	Long: `Checkpoints copy Claude's configuration files as they are, byte for byte,
+under ~/.claudeup/checkpoints:
+
+  settings.json                    settings and enabled plugins
+  plugins/installed_plugins.json   installed plugins
+  plugins/known_marketplaces.json  marketplaces
+  .claude.json                     MCP servers, projects, and everything else
+  .claudeup/config.json            claudeup's config and disabled plugins
+
+Restoring writes them back exactly, so it also brings back what profiles and
+snapshots can't represent. Plugin and marketplace files themselves aren't
+copied; run 'claudeup doctor' after restoring a checkpoint whose plugins
+have since been removed.`,
+}
+
+var checkpointCreateCmd = &cobra.Command{
+	Use:   "create <label>",
+	Short: "Save the current configuration files",
+	Example: `  claudeup checkpoint create before-experiment
+  claudeup profile use experimental
+  claudeup checkpoint restore before-experiment`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCheckpointCreate,
+}
+
+var checkpointRestoreCmd = &cobra.Command{
+	Use:   "restore <label>",
+	Short: "Write a checkpoint's files back",
+	Long: `Writes the checkpoint's files back byte for byte, and deletes those that
+didn't exist when it was created. Every copy is checked against its
+recorded hash before anything is written.
+
+The files it replaces are saved first as the pre-restore checkpoint, so
+'claudeup checkpoint restore pre-restore' undoes a restore.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCheckpointRestore,
+}
+
+var checkpointListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List checkpoints",
+	Args:  cobra.NoArgs,
+	RunE:  runCheckpointList,
+}
+
+var checkpointDeleteCmd = &cobra.Command{
+	Use:   "delete <label>",
+	Short: "Delete a checkpoint",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runCheckpointDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(checkpointCmd)
+	checkpointCmd.AddCommand(checkpointCreateCmd, checkpointRestoreCmd, checkpointListCmd, checkpointDeleteCmd)
+	addForceFlag(checkpointRestoreCmd)
+}
+
+// checkpointTargets returns the files checkpoints copy on this machine
+func checkpointTargets() []checkpoint.Target {
+	return checkpoint.Targets(claudeDir, profile.DefaultClaudeJSONPath(), pathctx.Default().ClaudeupDir)
+}
+
+func runCheckpointCreate(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	label := args[0]
+	if label == preRestoreCheckpoint {
+		return fmt.Errorf("%q is kept for the state a restore replaces; pick another label", label)
+	}
+	cp, err := checkpoint.Create(checkpoint.DefaultDir(), label, checkpointTargets())
+	if err != nil {
+		return err
+	}
+	out.Printf("✓ Checkpoint %s created (%s)\n", cp.Label, describeCheckpoint(cp))
+	return nil
+}
+
+func runCheckpointRestore(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	label := args[0]
+	dir := checkpoint.DefaultDir()
+	cp, err := checkpoint.Load(dir, label)
+	if err != nil {
+		return err
+	}
+	if err := checkClaudeNotRunning(cmd.Context(), out); err != nil {
+		return err
+	}
+
+	if !config.YesFlag {
+		out.Promptf("Overwrite Claude's configuration files with checkpoint %s from %s? [y/N]: ", label, cp.Created.Local().Format("2006-01-02 15:04"))
+		choice := promptChoice(out, "", "n")
+		if choice != "y" && choice != "yes" {
+			out.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	targets := checkpointTargets()
+	if label != preRestoreCheckpoint {
+		if err := savePreRestore(dir, targets); err != nil {
+			return fmt.Errorf("failed to save the current files before restoring: %w", err)
+		}
+	}
+
+	changed, err := checkpoint.Restore(dir, label, targets)
+	for _, name := range changed {
+		out.Printf("  ✓ %s\n", name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		out.Printf("✓ Files already match checkpoint %s\n", label)
+		return nil
+	}
+	out.Printf("✓ Restored checkpoint %s\n", label)
+	if label != preRestoreCheckpoint {
+		out.Printf("  → Undo with 'claudeup checkpoint restore %s'\n", preRestoreCheckpoint)
+	}
+	return nil
+}
+
+// savePreRestore replaces the pre-restore checkpoint with the current files
+func savePreRestore(dir string, targets []checkpoint.Target) error {
+	if _, err := checkpoint.Load(dir, preRestoreCheckpoint); err == nil {
+		if err := checkpoint.Delete(dir, preRestoreCheckpoint); err != nil {
+			return err
+		}
+	}
+	_, err := checkpoint.Create(dir, preRestoreCheckpoint, targets)
+	return err
+}
+
+func runCheckpointList(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	cps, err := checkpoint.List(checkpoint.DefaultDir())
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	if len(cps) == 0 {
+		out.Println("No checkpoints saved.")
+		out.Println("Save one with: claudeup checkpoint create <label>")
+		return nil
+	}
+	for _, cp := range cps {
+		out.Printf("  %-24s %s  %s\n", cp.Label, cp.Created.Local().Format("2006-01-02 15:04:05"), describeCheckpoint(cp))
+	}
+	return nil
+}
+
+func runCheckpointDelete(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if err := checkpoint.Delete(checkpoint.DefaultDir(), args[0]); err != nil {
+		return err
+	}
+	out.Printf("✓ Deleted checkpoint %s\n", args[0])
+	return nil
+}
+
+// describeCheckpoint summarizes the files a checkpoint holds, such as
+// "4 files, 18.2 KB; no known_marketplaces.json"
+func describeCheckpoint(cp *checkpoint.Checkpoint) string {
+	var size int64
+	var count int
+	var missing []string
+	for _, f := range cp.Files {
+		if f.Missing {
+			missing = append(missing, f.Name[strings.LastIndex(f.Name, "/")+1:])
+			continue
+		}
+		count++
+		size += f.Size
+	}
+	s := fmt.Sprintf("%d files, %s", count, formatSize(int(size)))
+	if len(missing) > 0 {
+		s += "; no " + strings.Join(missing, ", ")
+	}
+	return s
+}
@@ -0,0 +1,170 @@
+// ABOUTME: ci check validates a repository's pinned profile (.claudeup.json) for pull request checks
+// ABOUTME: Reports problems with line numbers, as GitHub annotations when running in GitHub Actions
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// pinnedProfileFile is the profile a repository commits for everyone
+// working in it, applied with 'profile use --file .claudeup.json'
+const pinnedProfileFile = ".claudeup.json"
+
+var (
+	ciCheckFile     string
+	ciCheckRegistry bool
+	ciCheckFormat   string
+)
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Checks for continuous integration",
+}
+
+var ciCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate the repository's pinned profile",
+	Long: `Validates the profile a repository commits as .claudeup.json, without
+applying it or needing Claude Code installed:
+
+  Schema        the file parses and has no unknown fields or wrong types
+  References    plugins are name@marketplace from a declared marketplace,
+                and disabled groups exist
+  Secrets       every $VAR an MCP server uses is declared, and every secret
+                source has the field its type needs
+  Pins          npx MCP servers are pinned with 'mcp pin', and each pin
+                still matches the package its args run
+
+--registry also checks each pinned integrity hash against the npm registry.
+
+Under GitHub Actions each problem is also printed as an annotation on its
+line of the file. Exits non-zero if there are errors; warnings, such as a
+plugin whose marketplace name can't be confirmed, don't fail the check.`,
+	Example: `  claudeup ci check
+  claudeup ci check --file profiles/team.json --registry
+  claudeup ci check --format json`,
+	Args: cobra.NoArgs,
+	RunE: runCICheck,
+}
+
+func init() {
+	rootCmd.AddCommand(ciCmd)
+	ciCmd.AddCommand(ciCheckCmd)
+	ciCheckCmd.Flags().StringVar(&ciCheckFile, "file", pinnedProfileFile, "Profile file to check")
+	ciCheckCmd.Flags().BoolVar(&ciCheckRegistry, "registry", false, "Check pinned npm integrity hashes against the registry")
+	ciCheckCmd.Flags().StringVar(&ciCheckFormat, "format", "", "Print the report as json or yaml")
+}
+
+// ciCheckReport is the machine-readable form of 'ci check'
+type ciCheckReport struct {
+	File     string            `json:"file"`
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
+	Problems []profile.Problem `json:"problems"`
+}
+
+func runCICheck(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if err := validateFormat("format", ciCheckFormat); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(ciCheckFile)
+	if os.IsNotExist(err) && ciCheckFile == pinnedProfileFile {
+		return fmt.Errorf("no %s here; commit the team's profile there, or pass --file", pinnedProfileFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, problems := profile.ValidateDocument(cmd.Context(), data, profile.ValidateOptions{Registry: ciCheckRegistry})
+	report := ciCheckReport{File: ciCheckFile, Problems: problems}
+	if report.Problems == nil {
+		report.Problems = []profile.Problem{}
+	}
+	for _, p := range problems {
+		if p.Severity == profile.ProblemError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+
+	if ciCheckFormat != "" {
+		if err := printFormatted(ciCheckFormat, report); err != nil {
+			return err
+		}
+	} else {
+		showCICheck(out, report)
+		if os.Getenv("GITHUB_ACTIONS") == "true" {
+			file := annotationPath(ciCheckFile, os.Getenv("GITHUB_WORKSPACE"))
+			for _, p := range problems {
+				out.Println(githubAnnotation(file, p))
+			}
+		}
+	}
+
+	if report.Errors > 0 {
+		return fmt.Errorf("%s has %d errors", ciCheckFile, report.Errors)
+	}
+	return nil
+}
+
+func showCICheck(out ui.Printer, report ciCheckReport) {
+	out.Printf("Checking %s\n", report.File)
+	if len(report.Problems) == 0 {
+		out.Println("  ✓ No problems found")
+		return
+	}
+	for _, p := range report.Problems {
+		glyph := "✗"
+		if p.Severity == profile.ProblemWarning {
+			glyph = "⚠"
+		}
+		where := ""
+		if p.Line > 0 {
+			where = fmt.Sprintf("line %d: ", p.Line)
+		}
+		out.Printf("  %s %s%s\n", glyph, where, p.Message)
+	}
+	out.Println()
+	out.Printf("%d errors, %d warnings\n", report.Errors, report.Warnings)
+}
+
+// annotationPath makes file relative to the workspace GitHub resolves
+// annotation paths against
+func annotationPath(file, workspace string) string {
+	abs, err := filepath.Abs(file)
+	if workspace == "" || err != nil {
+		return filepath.ToSlash(file)
+	}
+	if rel, err := filepath.Rel(workspace, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(file)
+}
+
+// githubAnnotation formats p as a GitHub Actions workflow command, which
+// the runner shows on the file's line in the pull request
+func githubAnnotation(file string, p profile.Problem) string {
+	props := "file=" + escapeAnnotationProperty(file)
+	if p.Line > 0 {
+		props += fmt.Sprintf(",line=%d", p.Line)
+	}
+	return fmt.Sprintf("::%s %s,title=claudeup ci check::%s", p.Severity, props, escapeAnnotationData(p.Message))
+}
+
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
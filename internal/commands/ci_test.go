@@ -0,0 +1,39 @@
+// ABOUTME: Tests for ci check's GitHub annotations
+// ABOUTME: Checks the workflow command format, escaping, and paths relative to the workspace
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+func TestGithubAnnotation(t *testing.T) {
+	tests := []struct {
+		file    string
+		problem profile.Problem
+		want    string
+	}{
+		{".claudeup.json", profile.Problem{Severity: profile.ProblemError, Line: 3, Message: `plugin "bare" must be written name@marketplace`},
+			`::error file=.claudeup.json,line=3,title=claudeup ci check::plugin "bare" must be written name@marketplace`},
+		{"team,a.json", profile.Problem{Severity: profile.ProblemWarning, Message: "100% sure\nnot"},
+			"::warning file=team%2Ca.json,title=claudeup ci check::100%25 sure%0Anot"},
+	}
+	for _, tt := range tests {
+		if got := githubAnnotation(tt.file, tt.problem); got != tt.want {
+			t.Errorf("Expected %s, got %s", tt.want, got)
+		}
+	}
+}
+
+func TestAnnotationPath(t *testing.T) {
+	workspace := t.TempDir()
+	file := filepath.Join(workspace, "config", ".claudeup.json")
+	if got := annotationPath(file, workspace); got != "config/.claudeup.json" {
+		t.Errorf("Expected a path relative to the workspace, got %s", got)
+	}
+	if got := annotationPath(".claudeup.json", ""); got != ".claudeup.json" {
+		t.Errorf("Expected the path unchanged outside Actions, got %s", got)
+	}
+}
@@ -0,0 +1,67 @@
+// ABOUTME: Refuses to change Claude Code's files while Claude Code is running
+// ABOUTME: Both processes write .claude.json and the plugin registry; --force overrides
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/claudeproc"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// ignoreRunningClaudeEnv skips the check, for tests and wrappers that
+// manage Claude Code themselves
+const ignoreRunningClaudeEnv = "CLAUDEUP_IGNORE_RUNNING_CLAUDE"
+
+var forceFlag bool
+
+func addForceFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&forceFlag, "force", false, "Make changes even while Claude Code is running")
+}
+
+// checkClaudeNotRunning fails when Claude Code is running or holds the lock
+// on .claude.json, since it may overwrite what claudeup writes. With --force
+// it only warns.
+func checkClaudeNotRunning(ctx context.Context, out ui.Printer) error {
+	if os.Getenv(ignoreRunningClaudeEnv) != "" {
+		return nil
+	}
+	busy := claudeBusy(ctx, out)
+	if busy == "" {
+		return nil
+	}
+	if forceFlag {
+		out.Warnf("⚠ %s; continuing because of --force\n", busy)
+		return nil
+	}
+	return fmt.Errorf("%s and may overwrite these changes; quit it first or pass --force", busy)
+}
+
+// claudeBusy describes what shows Claude Code is in use, or returns ""
+func claudeBusy(ctx context.Context, out ui.Printer) string {
+	claudeJSONPath := profile.DefaultClaudeJSONPath()
+	if claudeproc.Locked(claudeJSONPath) {
+		return "Claude Code is writing " + claudeJSONPath
+	}
+
+	procs, err := claudeproc.Running(ctx)
+	if err != nil {
+		out.Verbosef("Couldn't check for a running Claude Code: %v\n", err)
+		return ""
+	}
+	if len(procs) == 0 {
+		return ""
+	}
+	pids := make([]string, len(procs))
+	for i, p := range procs {
+		pids[i] = strconv.Itoa(p.PID)
+		out.Verbosef("  %d %s\n", p.PID, p.Command)
+	}
+	return "Claude Code is running (pid " + strings.Join(pids, ", ") + ")"
+}
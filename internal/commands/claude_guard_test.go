@@ -0,0 +1,53 @@
+// ABOUTME: Tests for refusing changes while Claude Code is running
+// ABOUTME: Uses the .claude.json lock so no real process is needed
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func TestCheckClaudeNotRunning(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", dir)
+	t.Setenv(ignoreRunningClaudeEnv, "")
+	// No ps, so only the lock counts
+	t.Setenv("PATH", t.TempDir())
+	defer func() { forceFlag = false }()
+
+	var buf bytes.Buffer
+	out := ui.NewPrinter(&buf, &buf, false)
+	ctx := context.Background()
+
+	if err := checkClaudeNotRunning(ctx, out); err != nil {
+		t.Fatalf("Expected no error without Claude Code, got %v", err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, ".claude.json.lock"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := checkClaudeNotRunning(ctx, out)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("Expected an error suggesting --force, got %v", err)
+	}
+
+	forceFlag = true
+	if err := checkClaudeNotRunning(ctx, out); err != nil {
+		t.Errorf("Expected --force to continue, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "continuing because of --force") {
+		t.Errorf("Expected a warning, got %q", buf.String())
+	}
+
+	forceFlag = false
+	t.Setenv(ignoreRunningClaudeEnv, "1")
+	if err := checkClaudeNotRunning(ctx, out); err != nil {
+		t.Errorf("Expected %s to skip the check, got %v", ignoreRunningClaudeEnv, err)
+	}
+}
@@ -0,0 +1,255 @@
+// ABOUTME: Installs or upgrades the Claude CLI for setup, preferring a package manager
+// ABOUTME: Falls back to a checksum-verified download; piping the install script to bash needs --installer script
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/cmdline"
+	"github.com/claudeup/claudeup/internal/platform"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var claudeInstallerFlag string
+
+func addInstallerFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&claudeInstallerFlag, "installer", "auto", "How to install or upgrade the Claude CLI: auto, brew, npm, winget, download, or script")
+}
+
+// claudeReleasesURL is where the official installer downloads Claude CLI
+// builds and the manifest with their checksums from
+var claudeReleasesURL = "https://storage.googleapis.com/claude-code-dist-86c565f3-f756-42ad-8dfa-d59b1c096819/claude-code-releases"
+
+// claudeInstaller is one way to install or upgrade the Claude CLI
+type claudeInstaller struct {
+	Name    string
+	Install []string // command line; none for the verified download
+	Upgrade []string
+	Remote  bool // executes a script fetched from the internet
+}
+
+// claudeInstallers are the --installer choices, package managers first in
+// the order auto tries them
+var claudeInstallers = []claudeInstaller{
+	{Name: "brew", Install: []string{"brew", "install", "--cask", "claude-code"}, Upgrade: []string{"brew", "upgrade", "--cask", "claude-code"}},
+	{Name: "npm", Install: []string{"npm", "install", "-g", "@anthropic-ai/claude-code"}, Upgrade: []string{"npm", "install", "-g", "@anthropic-ai/claude-code@latest"}},
+	{Name: "winget", Install: []string{"winget", "install", "--exact", "--id", "Anthropic.ClaudeCode"}, Upgrade: []string{"winget", "upgrade", "--exact", "--id", "Anthropic.ClaudeCode"}},
+	{Name: "download"},
+	{Name: "script", Install: []string{"bash", "-c", "curl -fsSL https://claude.ai/install.sh | bash"}, Upgrade: []string{"bash", "-c", "curl -fsSL https://claude.ai/install.sh | bash"}, Remote: true},
+}
+
+// pickInstaller resolves an --installer value. auto takes the first package
+// manager on PATH, else the verified download; it never picks the script.
+func pickInstaller(name string, lookPath func(string) (string, error)) (claudeInstaller, error) {
+	for _, inst := range claudeInstallers {
+		if name == "auto" && inst.Install != nil && !inst.Remote {
+			if _, err := lookPath(inst.Install[0]); err != nil {
+				continue
+			}
+			return inst, nil
+		}
+		if name == "auto" && inst.Name == "download" || name == inst.Name {
+			return inst, nil
+		}
+	}
+	return claudeInstaller{}, fmt.Errorf("unknown installer %q: use auto, brew, npm, winget, download, or script", name)
+}
+
+// pickUpgrader resolves an --installer value for upgrading the claude at
+// claudePath. auto uses the package manager that installed it, or else the
+// CLI's own 'claude update'.
+func pickUpgrader(name, claudePath string, lookPath func(string) (string, error)) (claudeInstaller, error) {
+	if name != "auto" {
+		return pickInstaller(name, lookPath)
+	}
+	if resolved, err := filepath.EvalSymlinks(claudePath); err == nil {
+		claudePath = resolved
+	}
+	path := filepath.ToSlash(claudePath)
+	switch {
+	case strings.Contains(path, "/Caskroom/") || strings.Contains(path, "/Cellar/"):
+		return pickInstaller("brew", lookPath)
+	case strings.Contains(path, "/node_modules/") || cmdline.IsBatch(path):
+		// On Windows npm installs a claude.cmd shim beside node_modules
+		return pickInstaller("npm", lookPath)
+	}
+	return claudeInstaller{Name: "claude", Upgrade: []string{claudePath, "update"}}, nil
+}
+
+// command describes what the installer runs, for the prompt
+func (i claudeInstaller) command(upgrade bool) string {
+	args := i.Install
+	if upgrade {
+		args = i.Upgrade
+	}
+	if args == nil {
+		return "download the latest claude from " + claudeReleasesURL + " and verify its SHA-256"
+	}
+	if i.Remote {
+		return args[len(args)-1]
+	}
+	return strings.Join(args, " ")
+}
+
+// run installs, or upgrades, the Claude CLI with its output on the terminal
+func (i claudeInstaller) run(ctx context.Context, out ui.Printer, upgrade bool) error {
+	args := i.Install
+	if upgrade {
+		args = i.Upgrade
+	}
+	if args == nil {
+		return installClaudeDownload(ctx, out)
+	}
+	cmd, err := cmdline.Command(ctx, args[0], args[1:]...)
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// claudeManifest is the release manifest listing each platform's build
+type claudeManifest struct {
+	Platforms map[string]struct {
+		Checksum string `json:"checksum"`
+	} `json:"platforms"`
+}
+
+// installClaudeDownload fetches the latest stable Claude CLI build, checks
+// it against the SHA-256 in the release manifest, and runs its own
+// 'install', which puts it on PATH
+func installClaudeDownload(ctx context.Context, out ui.Printer) error {
+	// The host's architecture, so an amd64 claudeup under Rosetta still
+	// installs the native arm64 build
+	host := platform.Host()
+	build, err := claudePlatform(host.OS, host.Arch, host.Libc == platform.LibcMusl)
+	if err != nil {
+		return err
+	}
+	version, err := fetchURL(ctx, claudeReleasesURL+"/stable")
+	if err != nil {
+		return fmt.Errorf("failed to find the latest version: %w", err)
+	}
+	release := claudeReleasesURL + "/" + strings.TrimSpace(string(version))
+	data, err := fetchURL(ctx, release+"/manifest.json")
+	if err != nil {
+		return fmt.Errorf("failed to fetch the release manifest: %w", err)
+	}
+	var manifest claudeManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("failed to parse the release manifest: %w", err)
+	}
+	want := manifest.Platforms[build].Checksum
+	if want == "" {
+		return fmt.Errorf("release %s has no build for %s", strings.TrimSpace(string(version)), build)
+	}
+
+	dir, err := os.MkdirTemp("", "claude-install")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	name := "claude"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	bin := filepath.Join(dir, name)
+	sum, err := downloadFile(ctx, release+"/"+build+"/"+name, bin)
+	if err != nil {
+		return fmt.Errorf("failed to download claude: %w", err)
+	}
+	if !strings.EqualFold(sum, want) {
+		return fmt.Errorf("checksum mismatch for claude %s: expected %s, got %s", strings.TrimSpace(string(version)), want, sum)
+	}
+	if err := os.Chmod(bin, 0755); err != nil {
+		return err
+	}
+	out.Printf("  ✓ Verified claude %s (sha256 %s)\n", strings.TrimSpace(string(version)), sum)
+
+	cmd := exec.CommandContext(ctx, bin, "install")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// claudePlatform names the release build for an OS and architecture
+func claudePlatform(goos, goarch string, musl bool) (string, error) {
+	arch := map[string]string{"amd64": "x64", "arm64": "arm64"}[goarch]
+	if arch == "" {
+		return "", fmt.Errorf("no Claude CLI build for %s/%s", goos, goarch)
+	}
+	switch goos {
+	case "darwin":
+		return "darwin-" + arch, nil
+	case "linux":
+		if musl {
+			return "linux-" + arch + "-musl", nil
+		}
+		return "linux-" + arch, nil
+	case "windows":
+		return "win32-" + arch, nil
+	}
+	return "", fmt.Errorf("no Claude CLI build for %s/%s", goos, goarch)
+}
+
+// fetchURL returns the body of a successful GET
+func fetchURL(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// downloadFile copies the body of url to dest and returns its SHA-256
+func downloadFile(ctx context.Context, url, dest string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,158 @@
+// ABOUTME: Tests for installing the Claude CLI
+// ABOUTME: Covers choosing an installer and the checksum-verified download
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/platform"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func lookPathFor(found ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, f := range found {
+			if f == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestPickInstaller(t *testing.T) {
+	tests := []struct {
+		name    string
+		onPath  []string
+		want    string
+		wantErr bool
+	}{
+		{"auto", []string{"npm", "brew"}, "brew", false},
+		{"auto", []string{"npm"}, "npm", false},
+		{"auto", []string{"bash", "curl"}, "download", false},
+		{"script", nil, "script", false},
+		{"npm", nil, "npm", false},
+		{"pip", nil, "", true},
+	}
+	for _, tt := range tests {
+		got, err := pickInstaller(tt.name, lookPathFor(tt.onPath...))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s", tt.name, got.Name)
+			}
+			continue
+		}
+		if err != nil || got.Name != tt.want {
+			t.Errorf("%s with %v: expected %s, got %s, %v", tt.name, tt.onPath, tt.want, got.Name, err)
+		}
+	}
+}
+
+func TestPickUpgrader(t *testing.T) {
+	lookPath := lookPathFor("brew", "npm")
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/opt/homebrew/Caskroom/claude-code/1.0.0/claude", "brew"},
+		{"/usr/local/lib/node_modules/@anthropic-ai/claude-code/cli.js", "npm"},
+		{`C:\Users\me\AppData\Roaming\npm\claude.cmd`, "npm"},
+		{"/home/me/.local/bin/claude", "claude"},
+	}
+	for _, tt := range tests {
+		got, err := pickUpgrader("auto", tt.path, lookPath)
+		if err != nil || got.Name != tt.want {
+			t.Errorf("%s: expected %s, got %s, %v", tt.path, tt.want, got.Name, err)
+		}
+	}
+
+	got, _ := pickUpgrader("auto", "/home/me/.local/bin/claude", lookPath)
+	if cmd := got.command(true); cmd != "/home/me/.local/bin/claude update" {
+		t.Errorf("Expected claude's own updater, got %q", cmd)
+	}
+	if got, _ := pickUpgrader("npm", "/opt/homebrew/Caskroom/claude-code/1.0.0/claude", lookPath); got.Name != "npm" {
+		t.Errorf("Expected --installer to win over detection, got %s", got.Name)
+	}
+}
+
+func TestInstallClaudeDownload(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake claude is a shell script")
+	}
+	host := platform.Host()
+	build, err := claudePlatform(host.OS, host.Arch, host.Libc == platform.LibcMusl)
+	if err != nil {
+		t.Skip(err)
+	}
+	marker := filepath.Join(t.TempDir(), "installed")
+	binary := "#!/bin/sh\necho \"$1\" > " + marker + "\n"
+	sum := sha256.Sum256([]byte(binary))
+	checksum := hex.EncodeToString(sum[:])
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/stable":
+			w.Write([]byte("2.0.1\n"))
+		case "/2.0.1/manifest.json":
+			w.Write([]byte(`{"platforms": {"` + build + `": {"checksum": "` + checksum + `"}}}`))
+		case "/2.0.1/" + build + "/claude":
+			w.Write([]byte(binary))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(url string) { claudeReleasesURL = url }(claudeReleasesURL)
+	claudeReleasesURL = srv.URL
+
+	var buf strings.Builder
+	if err := installClaudeDownload(context.Background(), ui.NewPrinter(&buf, &buf, false)); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if data, err := os.ReadFile(marker); err != nil || strings.TrimSpace(string(data)) != "install" {
+		t.Errorf("Expected the downloaded claude to run 'install', got %q, %v", data, err)
+	}
+	if !strings.Contains(buf.String(), "Verified claude 2.0.1") {
+		t.Errorf("Expected the verified version in output, got %q", buf.String())
+	}
+
+	os.Remove(marker)
+	checksum = strings.Repeat("0", 64)
+	err = installClaudeDownload(context.Background(), ui.NewPrinter(&buf, &buf, false))
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Expected a checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("Expected a binary failing verification not to run")
+	}
+}
+
+func TestClaudePlatform(t *testing.T) {
+	tests := []struct {
+		goos, goarch string
+		musl         bool
+		want         string
+	}{
+		{"darwin", "arm64", false, "darwin-arm64"},
+		{"linux", "amd64", false, "linux-x64"},
+		{"linux", "arm64", true, "linux-arm64-musl"},
+		{"windows", "amd64", false, "win32-x64"},
+	}
+	for _, tt := range tests {
+		if got, err := claudePlatform(tt.goos, tt.goarch, tt.musl); err != nil || got != tt.want {
+			t.Errorf("%s/%s: expected %s, got %s, %v", tt.goos, tt.goarch, tt.want, got, err)
+		}
+	}
+	if _, err := claudePlatform("linux", "386", false); err == nil {
+		t.Error("Expected no build for linux/386")
+	}
+}
@@ -4,8 +4,9 @@ package commands
 
 import (
 	"fmt"
+	"os"
 
-	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/state"
 	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +16,7 @@ var (
 	cleanupDryRun    bool
 	cleanupFixOnly   bool
 	cleanupRemoveOnly bool
+	cleanupOrphanedConfig bool
 )
 
 var cleanupCmd = &cobra.Command{
@@ -26,7 +28,10 @@ By default, this command:
   1. Fixes plugins with correctable path issues (missing subdirectories)
   2. Removes plugin entries that are truly broken (no valid path found)
 
-Use --fix-only or --remove-only for granular control.`,
+Use --fix-only or --remove-only for granular control.
+
+With --orphaned-config, cleanup instead removes entries in settings.json and
+~/.claude.json that refer to plugins or MCP servers that no longer exist.`,
 	RunE: runCleanup,
 }
 
@@ -36,22 +41,46 @@ func init() {
 	cleanupCmd.Flags().BoolVar(&cleanupDryRun, "dry-run", false, "Show what would happen without making changes")
 	cleanupCmd.Flags().BoolVar(&cleanupFixOnly, "fix-only", false, "Only fix path issues, don't remove entries")
 	cleanupCmd.Flags().BoolVar(&cleanupRemoveOnly, "remove-only", false, "Only remove broken entries, don't fix paths")
+	cleanupCmd.Flags().BoolVar(&cleanupOrphanedConfig, "orphaned-config", false, "Remove config entries referring to uninstalled plugins and MCP servers")
+	addForceFlag(cleanupCmd)
 }
 
 func runCleanup(cmd *cobra.Command, args []string) error {
+	if !cleanupDryRun {
+		if err := checkWritable(cmd); err != nil {
+			return err
+		}
+	}
+	out := ui.PrinterFrom(cmd.Context())
+
 	// Validate flag combinations
 	if cleanupFixOnly && cleanupRemoveOnly {
 		return fmt.Errorf("cannot use --fix-only and --remove-only together")
 	}
+	if cleanupOrphanedConfig {
+		if cleanupFixOnly || cleanupRemoveOnly {
+			return fmt.Errorf("--orphaned-config cannot be combined with --fix-only or --remove-only")
+		}
+		return runCleanupOrphanedConfig(cmd.Context(), out)
+	}
 
 	// Load plugins
-	plugins, err := claude.LoadPlugins(claudeDir)
+	plugins, err := state.LoadPlugins(claudeDir)
 	if err != nil {
 		return fmt.Errorf("failed to load plugins: %w", err)
 	}
 
+	// Marketplace clones are used to find where misplaced plugins live
+	marketplaces, err := state.LoadMarketplaces(claudeDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to load marketplaces: %w", err)
+		}
+		marketplaces = make(state.MarketplaceRegistry)
+	}
+
 	// Analyze issues
-	pathIssues := analyzePathIssues(plugins)
+	pathIssues := analyzePathIssues(plugins, marketplaces)
 
 	// Separate fixable and unfixable issues
 	fixableIssues := []PathIssue{}
@@ -82,41 +111,44 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 
 	// Check if there's anything to do
 	if len(fixableIssues) == 0 && len(unfixableIssues) == 0 {
-		fmt.Println("✓ No issues found")
+		out.Println("✓ No issues found")
 		return nil
 	}
 
 	// Show what will be done
 	if len(fixableIssues) > 0 {
 		if cleanupDryRun {
-			fmt.Printf("Would fix %d path issues:\n\n", len(fixableIssues))
+			out.Printf("Would fix %d path issues:\n\n", len(fixableIssues))
 		} else {
-			fmt.Printf("Found %d fixable path issues:\n\n", len(fixableIssues))
+			out.Printf("Found %d fixable path issues:\n\n", len(fixableIssues))
 		}
 		for _, issue := range fixableIssues {
-			fmt.Printf("  %s\n", issue.PluginName)
-			fmt.Printf("    %s → %s\n", issue.InstallPath, issue.ExpectedPath)
+			out.Printf("  %s\n", issue.PluginName)
+			out.Printf("    %s → %s\n", issue.InstallPath, issue.ExpectedPath)
 		}
-		fmt.Println()
+		out.Println()
 	}
 
 	if len(unfixableIssues) > 0 {
 		if cleanupDryRun {
-			fmt.Printf("Would remove %d broken plugin entries:\n\n", len(unfixableIssues))
+			out.Printf("Would remove %d broken plugin entries:\n\n", len(unfixableIssues))
 		} else {
-			fmt.Printf("Found %d plugins to remove:\n\n", len(unfixableIssues))
+			out.Printf("Found %d plugins to remove:\n\n", len(unfixableIssues))
 		}
 		for _, issue := range unfixableIssues {
-			fmt.Printf("  • %s\n", issue.PluginName)
-			fmt.Printf("    Path: %s\n", issue.InstallPath)
+			out.Printf("  • %s\n", issue.PluginName)
+			out.Printf("    Path: %s\n", issue.InstallPath)
 		}
-		fmt.Println()
+		out.Println()
 	}
 
 	if cleanupDryRun {
-		fmt.Println("Run without --dry-run to apply these changes")
+		out.Println("Run without --dry-run to apply these changes")
 		return nil
 	}
+	if err := checkClaudeNotRunning(cmd.Context(), out); err != nil {
+		return err
+	}
 
 	// Apply fixes with prompt
 	fixed := 0
@@ -155,28 +187,28 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 	}
 
 	// Save updated plugins
-	if err := claude.SavePlugins(claudeDir, plugins); err != nil {
+	if err := state.SavePlugins(claudeDir, plugins); err != nil {
 		return fmt.Errorf("failed to save plugins: %w", err)
 	}
 
 	// Report results
-	fmt.Println()
+	out.Println()
 	if fixed > 0 {
-		fmt.Printf("✓ Fixed %d plugin paths\n", fixed)
+		out.Printf("✓ Fixed %d plugin paths\n", fixed)
 	}
 	if removed > 0 {
-		fmt.Printf("✓ Removed %d plugin entries\n", removed)
+		out.Printf("✓ Removed %d plugin entries\n", removed)
 	}
 
 	if cleanupReinstall && removed > 0 {
-		fmt.Println("\nTo reinstall these plugins, use:")
+		out.Println("\nTo reinstall these plugins, use:")
 		for _, issue := range removedIssues {
-			fmt.Printf("  claude plugin install %s\n", issue.PluginName)
+			out.Printf("  claude plugin install %s\n", issue.PluginName)
 		}
 	}
 
 	if fixed > 0 || removed > 0 {
-		fmt.Println("\nRun 'claudeup status' to verify the changes")
+		out.Println("\nRun 'claudeup status' to verify the changes")
 	}
 
 	return nil
@@ -0,0 +1,136 @@
+// ABOUTME: cleanup --orphaned-config removes settings entries for plugins and MCP servers that are gone
+// ABOUTME: Shows each dangling entry as a diff line and asks before rewriting the files
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/mcp"
+	"github.com/claudeup/claudeup/internal/orphans"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func runCleanupOrphanedConfig(ctx context.Context, out ui.Printer) error {
+	claudeJSONPath := profile.DefaultClaudeJSONPath()
+
+	known, err := knownPluginsAndServers(claudeJSONPath)
+	if err != nil {
+		return err
+	}
+
+	var refs []orphans.Ref
+	settingsPath := filepath.Join(claudeDir, "settings.json")
+	found, err := orphans.FindInSettings(settingsPath, known)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", settingsPath, err)
+	}
+	refs = append(refs, found...)
+
+	found, err = orphans.FindInClaudeJSON(claudeJSONPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", claudeJSONPath, err)
+	}
+	refs = append(refs, found...)
+
+	if len(refs) == 0 {
+		out.Println("✓ No orphaned config entries found")
+		return nil
+	}
+
+	if cleanupDryRun {
+		out.Printf("Would remove %d orphaned config entries:\n\n", len(refs))
+	} else {
+		out.Printf("Found %d orphaned config entries:\n\n", len(refs))
+	}
+	file := ""
+	for _, r := range refs {
+		if r.File != file {
+			if file != "" {
+				out.Println()
+			}
+			file = r.File
+			out.Printf("  %s\n", file)
+		}
+		out.Printf("    - %s: %s\n", r.Location(), r.Value)
+		out.Printf("      (%s)\n", r.Reason)
+	}
+	out.Println()
+
+	if cleanupDryRun {
+		out.Println("Run without --dry-run to apply these changes")
+		return nil
+	}
+	if err := checkClaudeNotRunning(ctx, out); err != nil {
+		return err
+	}
+
+	confirm, err := ui.ConfirmYesNo("Remove these entries?")
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+
+	backups, err := orphans.Remove(refs)
+	if err != nil {
+		return err
+	}
+
+	out.Println()
+	out.Printf("✓ Removed %d orphaned config entries\n", len(refs))
+	for _, b := range backups {
+		out.Printf("  Backup: %s\n", b)
+	}
+	return nil
+}
+
+// knownPluginsAndServers lists every installed or claudeup-disabled plugin
+// and every MCP server defined anywhere Claude looks for them
+func knownPluginsAndServers(claudeJSONPath string) (orphans.Known, error) {
+	known := orphans.Known{Plugins: make(map[string]bool), MCPServers: make(map[string]bool)}
+
+	plugins, err := state.LoadPlugins(claudeDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return known, fmt.Errorf("failed to load plugins: %w", err)
+		}
+		plugins = &state.PluginRegistry{Plugins: make(map[string][]state.PluginMetadata)}
+	}
+	for name := range plugins.Plugins {
+		known.Plugins[name] = true
+	}
+
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		return known, fmt.Errorf("failed to load config: %w", err)
+	}
+	for name := range cfg.DisabledPlugins {
+		known.Plugins[name] = true
+	}
+
+	cwd, _ := os.Getwd()
+	defs, err := mcp.CollectDefinitions(plugins, claudeJSONPath, cwd, nil)
+	if err != nil {
+		return known, err
+	}
+	for _, d := range defs {
+		known.MCPServers[d.Name] = true
+	}
+
+	projectServers, err := orphans.ProjectServers(claudeJSONPath)
+	if err != nil && !os.IsNotExist(err) {
+		return known, fmt.Errorf("failed to read %s: %w", claudeJSONPath, err)
+	}
+	for name := range projectServers {
+		known.MCPServers[name] = true
+	}
+
+	return known, nil
+}
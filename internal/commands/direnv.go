@@ -0,0 +1,66 @@
+// ABOUTME: direnv subcommands for pinning a directory to a profile
+// ABOUTME: Prints an .envrc snippet that evaluates 'claudeup profile env --for-direnv'
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var direnvHookApply bool
+
+var direnvCmd = &cobra.Command{
+	Use:   "direnv",
+	Short: "Pin directories to profiles with direnv",
+	Long: `Integrates with direnv (https://direnv.net) so entering a directory exports
+CLAUDEUP_PROFILE and the profile's environment, and leaving it unloads them.`,
+}
+
+var direnvHookCmd = &cobra.Command{
+	Use:   "hook [profile]",
+	Short: "Print an .envrc snippet that loads a profile",
+	Long: `Prints lines to add to a directory's .envrc. With a profile name the
+directory is pinned to that profile; without one the profile is chosen when
+direnv loads, from workspace mappings and project detection.
+
+--apply also applies the profile on entering the directory when it isn't
+already active.`,
+	Example: `  claudeup direnv hook backend >> .envrc && direnv allow
+  claudeup direnv hook --apply >> .envrc`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDirenvHook,
+}
+
+func init() {
+	rootCmd.AddCommand(direnvCmd)
+	direnvCmd.AddCommand(direnvHookCmd)
+	direnvHookCmd.Flags().BoolVar(&direnvHookApply, "apply", false, "Apply the profile on entering the directory")
+}
+
+func runDirenvHook(cmd *cobra.Command, args []string) error {
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+		if _, err := loadProfileWithFallback(getProfilesDir(), name); err != nil {
+			return fmt.Errorf("profile %q not found: %w", name, err)
+		}
+	}
+	fmt.Print(direnvSnippet(name, direnvHookApply))
+	return nil
+}
+
+// direnvSnippet returns the .envrc lines that load a profile
+func direnvSnippet(name string, apply bool) string {
+	command := []string{"claudeup", "profile", "env", "--for-direnv"}
+	if apply {
+		command = append(command, "--apply")
+	}
+	comment := "# claudeup: load the profile for this directory"
+	if name != "" {
+		command = append(command, posixQuote(name))
+		comment = fmt.Sprintf("# claudeup: pin this directory to the %s profile", name)
+	}
+	return fmt.Sprintf("%s\neval \"$(%s)\"\n", comment, strings.Join(command, " "))
+}
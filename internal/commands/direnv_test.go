@@ -0,0 +1,61 @@
+// ABOUTME: Tests for the direnv snippet and choosing a directory's profile
+// ABOUTME: Checks pinned and unpinned snippets and that workspaces win over detection
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+func TestDirenvSnippet(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply bool
+		want  string
+	}{
+		{"", false, "# claudeup: load the profile for this directory\neval \"$(claudeup profile env --for-direnv)\"\n"},
+		{"it's", true, "# claudeup: pin this directory to the it's profile\neval \"$(claudeup profile env --for-direnv --apply 'it'\\''s')\"\n"},
+	}
+	for _, tt := range tests {
+		if got := direnvSnippet(tt.name, tt.apply); got != tt.want {
+			t.Errorf("direnvSnippet(%q, %v) = %q, want %q", tt.name, tt.apply, got, tt.want)
+		}
+	}
+}
+
+func TestProfileForDirPrefersWorkspace(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	profilesDir := t.TempDir()
+	work := t.TempDir()
+	for _, name := range []string{"work", "other"} {
+		if err := profile.Save(profilesDir, &profile.Profile{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := profileForDir(profilesDir, work)
+	if err != nil || p != nil {
+		t.Fatalf("Expected no match without a workspace, got %v, %v", p, err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.SetWorkspace(work, "work")
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	p, err = profileForDir(profilesDir, filepath.Join(work, "api"))
+	if err != nil || p == nil || p.Name != "work" {
+		t.Fatalf("Expected the workspace profile, got %v, %v", p, err)
+	}
+
+	cfg.SetWorkspace(work, "missing")
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := profileForDir(profilesDir, work); err == nil {
+		t.Error("Expected an error for a workspace whose profile is missing")
+	}
+}
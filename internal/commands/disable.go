@@ -7,6 +7,7 @@ import (
 
 	"github.com/claudeup/claudeup/internal/claude"
 	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -26,9 +27,14 @@ Example:
 
 func init() {
 	rootCmd.AddCommand(disableCmd)
+	addForceFlag(disableCmd)
 }
 
 func runDisable(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
 	pluginName := args[0]
 
 	// Load config
@@ -39,7 +45,7 @@ func runDisable(cmd *cobra.Command, args []string) error {
 
 	// Check if already disabled
 	if cfg.IsPluginDisabled(pluginName) {
-		fmt.Printf("✓ Plugin %s is already disabled\n", pluginName)
+		out.Printf("✓ Plugin %s is already disabled\n", pluginName)
 		return nil
 	}
 
@@ -54,6 +60,9 @@ func runDisable(cmd *cobra.Command, args []string) error {
 	if !exists {
 		return fmt.Errorf("plugin not found: %s", pluginName)
 	}
+	if err := checkClaudeNotRunning(cmd.Context(), out); err != nil {
+		return err
+	}
 
 	// Save plugin metadata to config
 	disabledPlugin := config.DisabledPlugin{
@@ -78,9 +87,9 @@ func runDisable(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to save plugins: %w", err)
 	}
 
-	fmt.Printf("✓ Disabled %s\n\n", pluginName)
-	fmt.Println("Plugin commands, agents, skills, and MCP servers are now unavailable")
-	fmt.Println("Run 'claudeup enable", pluginName+"' to re-enable")
+	out.Printf("✓ Disabled %s\n\n", pluginName)
+	out.Println("Plugin commands, agents, skills, and MCP servers are now unavailable")
+	out.Println("Run 'claudeup enable", pluginName+"' to re-enable")
 
 	return nil
 }
@@ -3,81 +3,227 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
-	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/claude/clicompat"
+	"github.com/claudeup/claudeup/internal/claude/registryversion"
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/doctor"
+	"github.com/claudeup/claudeup/internal/marketplace"
+	"github.com/claudeup/claudeup/internal/mcp"
+	"github.com/claudeup/claudeup/internal/platform"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+var (
+	doctorMigrate   bool
+	doctorJSON      bool
+	doctorScheduled bool
+	doctorReport    bool
+	doctorOutput    string
+
+	doctorNoPluginChecks bool
+)
+
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Diagnose common issues with Claude Code installation",
-	Long:  `Run diagnostics to identify and explain issues with plugins, marketplaces, and paths.`,
-	RunE:  runDoctor,
+	Long: `Run diagnostics to identify and explain issues with plugins, marketplaces, and paths.
+
+Installed plugins can add their own checks in claudeup/checks.json or as
+executables in claudeup/checks/; --no-plugin-checks skips them.
+
+With --report, gathers versions, the diagnostics, your config, registry
+summaries, recent history, and the last apply into a tarball to attach to a
+bug report. Secrets and your home directory are redacted, and every file can
+be reviewed before the tarball is written.`,
+	RunE: runDoctor,
 }
 
 func init() {
 	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorMigrate, "migrate", false, "Upgrade legacy registry files to the current schema (keeps a backup)")
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Print the report as JSON")
+	doctorCmd.Flags().BoolVar(&doctorScheduled, "scheduled", false, "Record the result in history and notify (used by 'claudeup schedule')")
+	doctorCmd.Flags().MarkHidden("scheduled")
+	doctorCmd.Flags().BoolVar(&doctorReport, "report", false, "Write a sanitized diagnostic tarball for bug reports")
+	doctorCmd.Flags().BoolVar(&doctorNoPluginChecks, "no-plugin-checks", false, "Skip checks shipped by installed plugins")
+	doctorCmd.Flags().StringVarP(&doctorOutput, "output", "o", "", "Report file to write with --report (default claudeup-report-<time>.tar.gz)")
 }
 
 type PathIssue struct {
-	PluginName    string
-	InstallPath   string
-	ExpectedPath  string
-	IssueType     string
-	CanAutoFix    bool
+	PluginName   string `json:"pluginName"`
+	InstallPath  string `json:"installPath"`
+	ExpectedPath string `json:"expectedPath,omitempty"`
+	IssueType    string `json:"issueType"`
+	CanAutoFix   bool   `json:"canAutoFix"`
 }
 
-func runDoctor(cmd *cobra.Command, args []string) error {
-	fmt.Println("Running diagnostics...")
+// Built-in checks run by collectDoctorReport
+const (
+	checkMarketplaces = "marketplaces"
+	checkPaths        = "paths"
+	checkMCP          = "mcp"
+	checkCLI          = "cli"
+)
 
-	// Load plugins (gracefully handle fresh installs with no plugins)
-	plugins, err := claude.LoadPlugins(claudeDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			plugins = &claude.PluginRegistry{Plugins: make(map[string][]claude.PluginMetadata)}
-		} else {
-			return fmt.Errorf("failed to load plugins: %w", err)
+// DoctorReport is the data gathered by doctor, independent of how it's shown
+type DoctorReport struct {
+	SchemaVersion string             `json:"schemaVersion"`
+	PluginCount   int                `json:"pluginCount"`
+	Marketplaces  []MarketplaceCheck `json:"marketplaces"`
+	PathIssues    []PathIssue        `json:"pathIssues"`
+	MCPConflicts  []mcp.Conflict     `json:"mcpConflicts"`
+	ClaudeCLI     CLICheck           `json:"claudeCLI"`
+	Host          platform.Platform  `json:"host"`
+	Checks        []doctor.Result    `json:"checks"` // how each check finished, including registered ones
+
+	elapsed time.Duration // wall time for all checks, for --verbose
+}
+
+// builtinChecks have sections of their own in doctor's output
+var builtinChecks = map[string]bool{checkMarketplaces: true, checkPaths: true, checkMCP: true, checkCLI: true}
+
+// check returns the result of the named check
+func (r *DoctorReport) check(name string) doctor.Result {
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c
 		}
 	}
+	return doctor.Result{Name: name, Status: doctor.StatusOK}
+}
 
-	// Load marketplaces (gracefully handle fresh installs)
-	marketplaces, err := claude.LoadMarketplaces(claudeDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			marketplaces = make(claude.MarketplaceRegistry)
-		} else {
-			return fmt.Errorf("failed to load marketplaces: %w", err)
+// CLICheck compares the installed Claude CLI against the versions
+// claudeup's apply logic has been tested with
+type CLICheck struct {
+	Found bool `json:"found"`
+	clicompat.Result
+	TestedRange string `json:"testedRange"`
+	// Binary is the platform the claude executable was built for; nil for
+	// an npm shim or anything else that isn't a native binary
+	Binary *platform.Binary `json:"binary,omitempty"`
+	// Mismatch says why Binary won't run natively on the host
+	Mismatch string `json:"platformMismatch,omitempty"`
+	// Emulated is set when the host runs a mismatched Binary anyway, under
+	// Rosetta or Windows' x64 emulation
+	Emulated bool `json:"emulated,omitempty"`
+}
+
+// HasIssue reports whether the CLI is missing, outside the tested range, or
+// built for another platform
+func (c CLICheck) HasIssue() bool {
+	if !c.Found || c.Mismatch != "" {
+		return true
+	}
+	return c.Status == clicompat.Unsupported || c.Status == clicompat.Older || c.Status == clicompat.Newer
+}
+
+// MarketplaceCheck records whether a marketplace directory exists
+type MarketplaceCheck struct {
+	Name            string `json:"name"`
+	InstallLocation string `json:"installLocation"`
+	OK              bool   `json:"ok"`
+}
+
+// IssueCount returns the number of problems found
+func (r *DoctorReport) IssueCount() int {
+	count := len(r.PathIssues) + len(r.MCPConflicts)
+	for _, c := range r.Checks {
+		if !c.OK() {
+			count++
+		}
+	}
+	// A CLI check that didn't finish is already counted
+	if r.ClaudeCLI.HasIssue() && r.check(checkCLI).OK() {
+		count++
+	}
+	for _, m := range r.Marketplaces {
+		if !m.OK {
+			count++
+		}
+	}
+	if r.SchemaVersion != registryversion.Current.String() && r.SchemaVersion != "" {
+		count++
+	}
+	if r.Host.Translated {
+		count++
+	}
+	return count
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	if doctorMigrate {
+		if err := checkWritable(cmd); err != nil {
+			return err
 		}
 	}
+	out := ui.PrinterFrom(cmd.Context())
+	if doctorReport {
+		return runDoctorReport(cmd.Context(), out)
+	}
+	if doctorJSON {
+		return runDoctorJSON(cmd.Context(), out)
+	}
+
+	out.Println("Running diagnostics...")
+
+	// Check registry schema before loading, so migration happens first
+	out.Println("━━━ Checking Registry Schema ━━━")
+	start := time.Now()
+	schemaIssues := checkRegistrySchema(out)
+	showCheckTime(out, time.Since(start))
+	out.Println()
+
+	report, err := collectDoctorReport(cmd.Context(), claudeDir)
+	if err != nil {
+		return err
+	}
+
+	out.Println("━━━ Checking Claude CLI ━━━")
+	if showCheckFailure(out, report.check(checkCLI)) {
+		showClaudeCLICheck(out, report.ClaudeCLI)
+	}
+	showHostCheck(out, report.Host)
+	showCheckTime(out, report.check(checkCLI).Duration)
+	out.Println()
 
 	// Check marketplaces
-	fmt.Println("━━━ Checking Marketplaces ━━━")
+	out.Println("━━━ Checking Marketplaces ━━━")
 	marketplaceIssues := 0
-	for name, marketplace := range marketplaces {
-		if _, err := os.Stat(marketplace.InstallLocation); os.IsNotExist(err) {
-			fmt.Printf("  ✗ %s: Directory not found at %s\n", name, marketplace.InstallLocation)
+	marketplacesDone := showCheckFailure(out, report.check(checkMarketplaces))
+	for _, m := range report.Marketplaces {
+		if !m.OK {
+			out.Printf("  ✗ %s: Directory not found at %s\n", m.Name, m.InstallLocation)
 			marketplaceIssues++
 		} else {
-			fmt.Printf("  ✓ %s\n", name)
+			out.Printf("  ✓ %s\n", m.Name)
 		}
 	}
-	if marketplaceIssues == 0 {
-		fmt.Println("  All marketplaces OK")
+	if marketplacesDone && marketplaceIssues == 0 {
+		out.Println("  All marketplaces OK")
 	}
-	fmt.Println()
+	showCheckTime(out, report.check(checkMarketplaces).Duration)
+	out.Println()
 
 	// Analyze path issues
-	fmt.Println("━━━ Analyzing Plugin Paths ━━━")
-	pathIssues := analyzePathIssues(plugins)
+	out.Println("━━━ Analyzing Plugin Paths ━━━")
+	pathIssues := report.PathIssues
 
-	if len(pathIssues) == 0 {
-		fmt.Println("  ✓ All plugin paths are valid")
-	} else {
+	pathsDone := showCheckFailure(out, report.check(checkPaths))
+	if pathsDone && len(pathIssues) == 0 {
+		out.Println("  ✓ All plugin paths are valid")
+	} else if len(pathIssues) > 0 {
 		// Group by issue type
 		byType := make(map[string][]PathIssue)
 		for _, issue := range pathIssues {
@@ -86,62 +232,420 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 
 		// Report fixable issues
 		if fixable, ok := byType["missing_subdirectory"]; ok {
-			fmt.Printf("  ⚠ %d plugins with fixable path issues:\n", len(fixable))
+			out.Printf("  ⚠ %d plugins with fixable path issues:\n", len(fixable))
 			for _, issue := range fixable {
-				fmt.Printf("    - %s\n", issue.PluginName)
-				fmt.Printf("      Current:  %s\n", issue.InstallPath)
-				fmt.Printf("      Expected: %s\n", issue.ExpectedPath)
+				out.Printf("    - %s\n", issue.PluginName)
+				out.Printf("      Current:  %s\n", issue.InstallPath)
+				out.Printf("      Expected: %s\n", issue.ExpectedPath)
 			}
 		}
 
 		// Report truly missing plugins
 		if missing, ok := byType["not_found"]; ok {
 			if len(byType["missing_subdirectory"]) > 0 {
-				fmt.Println()
+				out.Println()
 			}
-			fmt.Printf("  ✗ %d plugins with missing directories:\n", len(missing))
+			out.Printf("  ✗ %d plugins with missing directories:\n", len(missing))
 			for _, issue := range missing {
-				fmt.Printf("    - %s\n", issue.PluginName)
-				fmt.Printf("      Path: %s\n", issue.InstallPath)
+				out.Printf("    - %s\n", issue.PluginName)
+				out.Printf("      Path: %s\n", issue.InstallPath)
 			}
 		}
 
 		// Unified recommendation
-		fmt.Println("\n  → Run 'claudeup cleanup' to fix and remove these issues")
-		fmt.Println("     (use --fix-only or --remove-only for granular control)")
+		out.Println("\n  → Run 'claudeup cleanup' to fix and remove these issues")
+		out.Println("     (use --fix-only or --remove-only for granular control)")
+	}
+	showCheckTime(out, report.check(checkPaths).Duration)
+	out.Println()
+
+	out.Println("━━━ Checking MCP Servers ━━━")
+	if showCheckFailure(out, report.check(checkMCP)) {
+		showMCPConflicts(out, report.MCPConflicts)
+	}
+	showCheckTime(out, report.check(checkMCP).Duration)
+	out.Println()
+
+	// Registered and plugin checks show whatever they printed
+	failedChecks := 0
+	for _, c := range report.Checks {
+		if builtinChecks[c.Name] {
+			continue
+		}
+		out.Printf("━━━ %s ━━━\n", c.Title)
+		if showCheckFailure(out, c) {
+			out.Println("  ✓ OK")
+		}
+		for _, line := range strings.Split(c.Output, "\n") {
+			if line != "" {
+				out.Printf("    %s\n", line)
+			}
+		}
+		showCheckTime(out, c.Duration)
+		out.Println()
+	}
+	for _, c := range report.Checks {
+		if !c.OK() {
+			failedChecks++
+		}
 	}
-	fmt.Println()
 
 	// Summary
-	fmt.Println("━━━ Summary ━━━")
-	fmt.Printf("  Marketplaces: %d installed", len(marketplaces))
+	out.Println("━━━ Summary ━━━")
+	out.Printf("  Marketplaces: %d installed", len(report.Marketplaces))
 	if marketplaceIssues > 0 {
-		fmt.Printf(", %d issues", marketplaceIssues)
+		out.Printf(", %d issues", marketplaceIssues)
 	}
-	fmt.Println()
+	out.Println()
 
-	fmt.Printf("  Plugins:      %d installed", len(plugins.Plugins))
+	out.Printf("  Plugins:      %d installed", report.PluginCount)
 	if len(pathIssues) > 0 {
-		fmt.Printf(", %d issues", len(pathIssues))
+		out.Printf(", %d issues", len(pathIssues))
+	}
+	out.Println()
+
+	if schemaIssues > 0 {
+		out.Printf("  Registry:     %d schema issues\n", schemaIssues)
 	}
-	fmt.Println()
 
-	if len(pathIssues) > 0 || marketplaceIssues > 0 {
-		fmt.Println("\nRun the suggested commands to fix these issues.")
+	if len(report.MCPConflicts) > 0 {
+		out.Printf("  MCP servers:  %d defined more than once\n", len(report.MCPConflicts))
+	}
+
+	if report.ClaudeCLI.HasIssue() && report.check(checkCLI).OK() {
+		out.Printf("  Claude CLI:   %s\n", cliSummary(report.ClaudeCLI))
+	}
+
+	if report.Host.Translated {
+		out.Println("  claudeup:     amd64 build under Rosetta")
+	}
+	if failedChecks > 0 {
+		out.Printf("  Checks:       %d failed or timed out\n", failedChecks)
+	}
+	out.Verbosef("  Checks took %s in total\n", formatStepDuration(report.elapsed))
+
+	if len(pathIssues) > 0 || marketplaceIssues > 0 || schemaIssues > 0 || len(report.MCPConflicts) > 0 || report.ClaudeCLI.HasIssue() || report.Host.Translated || failedChecks > 0 {
+		out.Println("\nRun the suggested commands to fix these issues.")
 	} else {
-		fmt.Println("\n✓ No issues detected!")
+		out.Println("\n✓ No issues detected!")
 	}
 
 	return nil
 }
 
-func analyzePathIssues(plugins *claude.PluginRegistry) []PathIssue {
+// showCheckTime prints how long a check took when verbose
+func showCheckTime(out ui.Printer, d time.Duration) {
+	out.Verbosef("  took %s\n", formatStepDuration(d))
+}
+
+// showCheckFailure prints why a check didn't finish, and reports whether
+// it did
+func showCheckFailure(out ui.Printer, c doctor.Result) bool {
+	if c.OK() {
+		return true
+	}
+	if c.Status == doctor.StatusTimeout {
+		out.Printf("  ✗ Check %s\n", c.Error)
+	} else {
+		out.Printf("  ✗ Check failed: %s\n", c.Error)
+	}
+	return false
+}
+
+func runDoctorJSON(ctx context.Context, out ui.Printer) error {
+	report, err := collectDoctorReport(ctx, claudeDir)
+	if doctorScheduled {
+		issues := 0
+		if report != nil {
+			issues = report.IssueCount()
+		}
+		recordScheduledResult(out, "doctor", issues, fmt.Sprintf("doctor found %d issues", issues), err)
+		reportDrift(ctx, out)
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(report)
+}
+
+// checkRegistrySchema reports the installed_plugins.json schema version and
+// migrates legacy files when --migrate is set. Returns the number of issues.
+func checkRegistrySchema(out ui.Printer) int {
+	version, err := state.PluginsSchemaVersion(claudeDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			out.Println("  ✓ No plugin registry yet")
+			return 0
+		}
+		out.Printf("  ✗ installed_plugins.json: %v\n", err)
+		return 1
+	}
+
+	if version == registryversion.Current {
+		out.Printf("  ✓ installed_plugins.json: %s\n", version)
+		return 0
+	}
+
+	if !doctorMigrate {
+		out.Printf("  ⚠ installed_plugins.json: %s (legacy format, current is %s)\n", version, registryversion.Current)
+		out.Println("\n  → Run 'claudeup doctor --migrate' to upgrade it (a backup is kept)")
+		return 1
+	}
+
+	backupPath, err := state.MigratePlugins(claudeDir)
+	if err != nil {
+		out.Printf("  ✗ installed_plugins.json: migration failed: %v\n", err)
+		return 1
+	}
+	out.Printf("  ✓ installed_plugins.json: migrated %s → %s\n", version, registryversion.Current)
+	out.Printf("    Backup: %s\n", backupPath)
+	return 0
+}
+
+// collectDoctorReport loads Claude state and runs the doctor checks
+// concurrently without printing anything
+func collectDoctorReport(ctx context.Context, claudeDir string) (*DoctorReport, error) {
+	report := &DoctorReport{Marketplaces: []MarketplaceCheck{}, PathIssues: []PathIssue{}, Host: platform.Host()}
+
+	if version, err := state.PluginsSchemaVersion(claudeDir); err == nil {
+		report.SchemaVersion = version.String()
+	}
+
+	// Load plugins (gracefully handle fresh installs with no plugins)
+	plugins, err := state.LoadPlugins(claudeDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			plugins = &state.PluginRegistry{Plugins: make(map[string][]state.PluginMetadata)}
+		} else {
+			return nil, fmt.Errorf("failed to load plugins: %w", err)
+		}
+	}
+
+	// Load marketplaces (gracefully handle fresh installs)
+	marketplaces, err := state.LoadMarketplaces(claudeDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			marketplaces = make(state.MarketplaceRegistry)
+		} else {
+			return nil, fmt.Errorf("failed to load marketplaces: %w", err)
+		}
+	}
+	report.PluginCount = len(plugins.Plugins)
+
+	// Each check fills in its own variable; only checks that finished are
+	// copied into the report, since a timed-out one may still be running
+	var (
+		marketplaceChecks []MarketplaceCheck
+		pathIssues        []PathIssue
+		conflicts         []mcp.Conflict
+		cli               CLICheck
+	)
+	checks := []doctor.Check{
+		{Name: checkCLI, Timeout: 5 * time.Second, Run: func(ctx context.Context) (string, error) {
+			cli = checkClaudeCLI(ctx, report.Host)
+			return "", nil
+		}},
+		{Name: checkMarketplaces, Run: func(ctx context.Context) (string, error) {
+			for name, marketplace := range marketplaces {
+				_, statErr := os.Stat(marketplace.InstallLocation)
+				marketplaceChecks = append(marketplaceChecks, MarketplaceCheck{
+					Name:            name,
+					InstallLocation: marketplace.InstallLocation,
+					OK:              !os.IsNotExist(statErr),
+				})
+			}
+			sort.Slice(marketplaceChecks, func(i, j int) bool {
+				return marketplaceChecks[i].Name < marketplaceChecks[j].Name
+			})
+			return "", nil
+		}},
+		{Name: checkPaths, Run: func(ctx context.Context) (string, error) {
+			pathIssues = analyzePathIssues(plugins, marketplaces)
+			return "", nil
+		}},
+		{Name: checkMCP, Run: func(ctx context.Context) (string, error) {
+			conflicts = findMCPConflicts(plugins)
+			return "", nil
+		}},
+	}
+	checks = append(checks, doctor.Registered()...)
+	if !doctorNoPluginChecks {
+		checks = append(checks, pluginChecks(plugins)...)
+	}
+
+	start := time.Now()
+	report.Checks = doctor.Run(ctx, checks)
+	report.elapsed = time.Since(start)
+
+	if report.check(checkCLI).OK() {
+		report.ClaudeCLI = cli
+	} else {
+		// Unknown rather than missing, so it isn't counted twice
+		report.ClaudeCLI = CLICheck{Found: true}
+	}
+	if report.check(checkMarketplaces).OK() && marketplaceChecks != nil {
+		report.Marketplaces = marketplaceChecks
+	}
+	if report.check(checkPaths).OK() && pathIssues != nil {
+		report.PathIssues = pathIssues
+	}
+	if report.check(checkMCP).OK() {
+		report.MCPConflicts = conflicts
+	}
+	return report, nil
+}
+
+// checkClaudeCLI runs 'claude --version' and checks it against the embedded
+// compatibility matrix
+func checkClaudeCLI(ctx context.Context, host platform.Platform) CLICheck {
+	m := clicompat.Default()
+	through := m.TestedThrough
+	if len(clicompat.Parse(through)) < 3 {
+		through += ".x"
+	}
+	check := CLICheck{TestedRange: m.TestedFrom + " through " + through}
+
+	path, err := exec.LookPath("claude")
+	if err != nil {
+		return check
+	}
+	check.Found = true
+	check.Result = m.Check(claudeVersion(ctx))
+	if b, err := platform.ReadBinary(path); err == nil {
+		check.Binary = &b
+		check.Mismatch = b.Mismatch(host)
+		check.Emulated = check.Mismatch != "" && b.Emulated(host)
+	}
+	return check
+}
+
+func cliSummary(c CLICheck) string {
+	if !c.Found {
+		return "not installed"
+	}
+	if c.Mismatch != "" {
+		return fmt.Sprintf("%s (%s)", c.Version, c.Mismatch)
+	}
+	return fmt.Sprintf("%s (%s)", c.Version, c.Status)
+}
+
+func showClaudeCLICheck(out ui.Printer, c CLICheck) {
+	if !c.Found {
+		out.Println("  ✗ claude not found on PATH")
+		out.Println("\n  → Run 'claudeup setup' to install it")
+		return
+	}
+
+	switch c.Status {
+	case clicompat.Tested:
+		out.Printf("  ✓ claude %s (tested with %s)\n", c.Version, c.TestedRange)
+	case clicompat.Unknown:
+		out.Printf("  ⚠ Could not determine the claude version (got %q)\n", c.Version)
+	case clicompat.Unsupported:
+		out.Printf("  ✗ claude %s has known problems with profile apply:\n", c.Version)
+		for _, note := range c.Notes {
+			out.Printf("    - %s\n", note)
+		}
+		out.Println("\n  → Run 'claude update' to upgrade")
+	case clicompat.Older:
+		out.Printf("  ⚠ claude %s is older than claudeup has been tested with (%s)\n", c.Version, c.TestedRange)
+		out.Println("\n  → Run 'claude update' to upgrade")
+	case clicompat.Newer:
+		out.Printf("  ⚠ claude %s is newer than claudeup has been tested with (%s)\n", c.Version, c.TestedRange)
+		out.Println("\n  → If profile apply misbehaves, run 'claude install <version>' to switch to a tested release")
+	}
+	showPlatformCheck(out, c)
+}
+
+// showPlatformCheck flags a claude binary built for another architecture
+// or C library than the host's
+func showPlatformCheck(out ui.Printer, c CLICheck) {
+	if c.Binary == nil {
+		return
+	}
+	if c.Mismatch == "" {
+		out.Verbosef("  ✓ claude is a %s build\n", c.Binary)
+		return
+	}
+	if c.Emulated {
+		out.Printf("  ⚠ claude is %s, so it runs under emulation and starts slower\n", c.Mismatch)
+	} else {
+		out.Printf("  ✗ claude is %s and won't start\n", c.Mismatch)
+	}
+	out.Println("\n  → Remove it, then run 'claudeup setup --installer download' to install the build for this machine")
+}
+
+// showHostCheck warns when claudeup itself is an amd64 build running under
+// Rosetta, which also leads installers to pick amd64 builds
+func showHostCheck(out ui.Printer, host platform.Platform) {
+	out.Verbosef("  Host: %s\n", host)
+	if !host.Translated {
+		return
+	}
+	out.Println("  ⚠ claudeup is the darwin/amd64 build running under Rosetta")
+	out.Println("\n  → Reinstall claudeup with install.sh, which picks the darwin/arm64 build")
+}
+
+// findMCPConflicts looks for MCP server names defined in more than one of the
+// project, the user config, and enabled plugins
+func findMCPConflicts(plugins *state.PluginRegistry) []mcp.Conflict {
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	cwd, _ := os.Getwd()
+
+	defs, err := mcp.CollectDefinitions(plugins, profile.DefaultClaudeJSONPath(), cwd, cfg.IsMCPServerDisabled)
+	if err != nil {
+		return []mcp.Conflict{}
+	}
+	conflicts := mcp.FindConflicts(defs)
+	if conflicts == nil {
+		conflicts = []mcp.Conflict{}
+	}
+	return conflicts
+}
+
+func showMCPConflicts(out ui.Printer, conflicts []mcp.Conflict) {
+	if len(conflicts) == 0 {
+		out.Println("  ✓ No duplicate MCP server names")
+		return
+	}
+
+	for i, c := range conflicts {
+		if i > 0 {
+			out.Println()
+		}
+		note := ""
+		if c.Identical {
+			note = " (identical definitions)"
+		}
+		out.Printf("  ⚠ %s is defined %d times%s:\n", c.Name, len(c.Definitions), note)
+		for j, d := range c.Definitions {
+			marker := " "
+			if j == 0 && !c.Ambiguous {
+				marker = "*"
+			}
+			out.Printf("    %s %s\n", marker, d.Location())
+		}
+		if c.Ambiguous {
+			out.Println("    Claude has no precedence rule between these; either may load")
+		} else {
+			out.Printf("    Claude uses the %s definition\n", c.Definitions[0].Source)
+		}
+		for _, s := range c.Suggestions() {
+			out.Printf("    → %s\n", s)
+		}
+	}
+}
+
+func analyzePathIssues(plugins *state.PluginRegistry, marketplaces state.MarketplaceRegistry) []PathIssue {
 	var issues []PathIssue
 
 	for name, plugin := range plugins.GetAllPlugins() {
 		if !plugin.PathExists() {
 			// Check if this is a fixable path issue
-			expectedPath := getExpectedPath(name, plugin.InstallPath)
+			expectedPath := getExpectedPath(marketplaces, name, plugin.InstallPath)
 			if expectedPath != "" && pathExists(expectedPath) {
 				issues = append(issues, PathIssue{
 					PluginName:   name,
@@ -169,43 +673,41 @@ func analyzePathIssues(plugins *claude.PluginRegistry) []PathIssue {
 	return issues
 }
 
-func getExpectedPath(pluginName, currentPath string) string {
-	// Based on fix-plugin-paths.sh logic
-	if strings.Contains(currentPath, "claude-code-plugins") {
-		// Add /plugins/ subdirectory
-		base := filepath.Dir(currentPath)
-		plugin := filepath.Base(currentPath)
-		return filepath.Join(base, "plugins", plugin)
-	}
-	if strings.Contains(currentPath, "claude-code-templates") {
-		base := filepath.Dir(currentPath)
-		plugin := filepath.Base(currentPath)
-		return filepath.Join(base, "plugins", plugin)
+// getExpectedPath returns where a plugin with a stale install path actually
+// lives in its marketplace clone, or "" if it can't be found. Only paths
+// inside a marketplace clone are corrected.
+func getExpectedPath(marketplaces state.MarketplaceRegistry, pluginName, currentPath string) string {
+	base, marketplaceName, ok := strings.Cut(pluginName, "@")
+	if !ok {
+		return ""
 	}
-	if strings.Contains(currentPath, "anthropic-agent-skills") {
-		base := filepath.Dir(currentPath)
-		plugin := filepath.Base(currentPath)
-		return filepath.Join(base, "skills", plugin)
-	}
-	if strings.Contains(currentPath, "every-marketplace") {
-		base := filepath.Dir(currentPath)
-		plugin := filepath.Base(currentPath)
-		return filepath.Join(base, "plugins", plugin)
+
+	for _, root := range marketplaceRoots(marketplaces, marketplaceName, currentPath) {
+		if !strings.HasPrefix(currentPath, root+string(filepath.Separator)) {
+			continue
+		}
+		if dir, ok := marketplace.PluginDir(root, base); ok && dir != currentPath {
+			return dir
+		}
 	}
-	if strings.Contains(currentPath, "awesome-claude-code-plugins") {
-		base := filepath.Dir(currentPath)
-		plugin := filepath.Base(currentPath)
-		return filepath.Join(base, "plugins", plugin)
+	return ""
+}
+
+// marketplaceRoots returns candidate clone directories for a marketplace:
+// the location recorded in known_marketplaces.json, and the
+// marketplaces/<name> ancestor of the plugin's current path
+func marketplaceRoots(marketplaces state.MarketplaceRegistry, name, currentPath string) []string {
+	var roots []string
+	if meta, ok := marketplaces[name]; ok && meta.InstallLocation != "" {
+		roots = append(roots, filepath.Clean(meta.InstallLocation))
 	}
-	if strings.Contains(currentPath, "tanzu-cf-architect") {
-		// Remove duplicate directory name
-		parts := strings.Split(currentPath, string(filepath.Separator))
-		lastPart := parts[len(parts)-1]
-		if len(parts) >= 2 && parts[len(parts)-2] == lastPart {
-			return filepath.Join(parts[:len(parts)-1]...)
+	for dir := filepath.Dir(currentPath); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == name && filepath.Base(filepath.Dir(dir)) == "marketplaces" {
+			roots = append(roots, dir)
+			break
 		}
 	}
-	return ""
+	return roots
 }
 
 func pathExists(path string) bool {
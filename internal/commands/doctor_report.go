@@ -0,0 +1,274 @@
+// ABOUTME: doctor --report gathers sanitized diagnostics into a tarball for bug reports
+// ABOUTME: Shows each file for review before writing; secrets and home paths are redacted
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/diagnostics"
+	"github.com/claudeup/claudeup/internal/history"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/platform"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+// reportHistoryEntries and reportLogLines bound how much history goes in a report
+const (
+	reportHistoryEntries = 50
+	reportLogLines       = 200
+)
+
+// runDoctorReport collects the report, lets the user review it, and writes
+// the tarball
+func runDoctorReport(ctx context.Context, out ui.Printer) error {
+	redactor := diagnostics.Redactor{Home: pathctx.From(ctx).Home}
+	files, err := collectReportFiles(ctx, redactor)
+	if err != nil {
+		return err
+	}
+
+	path := doctorOutput
+	if path == "" {
+		path = "claudeup-report-" + time.Now().Format("20060102-150405") + ".tar.gz"
+	}
+
+	out.Println("━━━ Diagnostic Report ━━━")
+	out.Println("  Secrets and your home directory are redacted. The report will contain:")
+	out.Println()
+	for i, f := range files {
+		out.Printf("  %d. %-18s %s\n", i+1, f.Name, formatSize(len(f.Data)))
+	}
+	out.Println()
+
+	if !reviewReport(out, files) {
+		out.Println("Cancelled.")
+		return nil
+	}
+
+	dir := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".gz"), ".tar")
+	if err := diagnostics.WriteTarball(path, dir, files); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	out.Printf("✓ Wrote %s\n", path)
+	out.Println("  → Attach it to an issue at https://github.com/claudeup/claudeup/issues")
+	return nil
+}
+
+// reviewReport shows files on request until the user writes or cancels
+func reviewReport(out ui.Printer, files []diagnostics.File) bool {
+	if config.YesFlag {
+		return true
+	}
+	for {
+		choice := strings.ToLower(promptChoice(out, "Number to view a file, w to write the report, q to cancel", "w"))
+		switch choice {
+		case "w", "write":
+			return true
+		case "q", "quit":
+			return false
+		}
+		n, err := strconv.Atoi(choice)
+		if err != nil || n < 1 || n > len(files) {
+			out.Printf("  Enter 1-%d, w, or q\n", len(files))
+			continue
+		}
+		f := files[n-1]
+		out.Println()
+		out.Printf("━━━ %s ━━━\n", f.Name)
+		out.Println(strings.TrimRight(string(f.Data), "\n"))
+		out.Println()
+	}
+}
+
+// collectReportFiles gathers versions, the doctor report, config, registry
+// summaries, recent history, and the last apply, all redacted
+func collectReportFiles(ctx context.Context, r diagnostics.Redactor) ([]diagnostics.File, error) {
+	var files []diagnostics.File
+	add := func(name string, data []byte) {
+		files = append(files, diagnostics.File{Name: name, Data: data})
+	}
+	addJSON := func(name string, v interface{}) error {
+		data, err := r.Value(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		add(name, append(data, '\n'))
+		return nil
+	}
+
+	add("versions.txt", []byte(reportVersions()))
+
+	report, err := collectDoctorReport(ctx, claudeDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := addJSON("doctor.json", report); err != nil {
+		return nil, err
+	}
+
+	if data, err := os.ReadFile(config.Path()); err == nil {
+		redacted, err := r.JSON(data)
+		if err != nil {
+			// An unparseable config is itself worth reporting
+			redacted = []byte(r.Text(string(data)))
+		}
+		add("config.json", append(redacted, '\n'))
+	}
+
+	add("registry.txt", []byte(r.Text(registrySummary())))
+
+	entries, err := history.Load(history.DefaultPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	if last, ok := history.LastApply(entries); ok {
+		if err := addJSON("last-apply.json", last); err != nil {
+			return nil, err
+		}
+	}
+	if len(entries) > reportHistoryEntries {
+		entries = entries[len(entries)-reportHistoryEntries:]
+	}
+	var hist bytes.Buffer
+	for _, e := range entries {
+		data, err := r.Value(e)
+		if err != nil {
+			continue
+		}
+		// One compact line per entry, like the log itself
+		var line bytes.Buffer
+		if err := json.Compact(&line, data); err == nil {
+			hist.Write(line.Bytes())
+			hist.WriteByte('\n')
+		}
+	}
+	if hist.Len() > 0 {
+		add("history.jsonl", hist.Bytes())
+	}
+
+	logPath := pathctx.Default().Claudeup("schedule.log")
+	if lines, err := tailLines(logPath, reportLogLines); err == nil && len(lines) > 0 {
+		add("schedule.log", []byte(r.Text(strings.Join(lines, "\n"))+"\n"))
+	}
+
+	return files, nil
+}
+
+func reportVersions() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "claudeup: %s\n", rootCmd.Version)
+	fmt.Fprintf(&b, "claude:   %s\n", getClaudeVersion())
+	fmt.Fprintf(&b, "os/arch:  %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	host := platform.Host()
+	if host.Translated {
+		fmt.Fprintf(&b, "host:     %s, claudeup under Rosetta\n", host)
+	} else {
+		fmt.Fprintf(&b, "host:     %s\n", host)
+	}
+	fmt.Fprintf(&b, "go:       %s\n", runtime.Version())
+	if cfg, err := config.LoadExisting(); err == nil && cfg.Preferences.ActiveProfile != "" {
+		fmt.Fprintf(&b, "profile:  %s\n", cfg.Preferences.ActiveProfile)
+	}
+	return b.String()
+}
+
+// registrySummary lists installed plugins, marketplaces, and MCP servers
+// without their full definitions, which can hold secrets
+func registrySummary() string {
+	var b strings.Builder
+
+	b.WriteString("Plugins:\n")
+	if plugins, err := state.LoadPlugins(claudeDir); err != nil {
+		fmt.Fprintf(&b, "  (could not read: %v)\n", err)
+	} else {
+		all := plugins.GetAllPlugins()
+		names := make([]string, 0, len(all))
+		for name := range all {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			meta := all[name]
+			fmt.Fprintf(&b, "  %s  version=%s scope=%s commit=%.7s exists=%t\n", name, orDash(meta.Version), orDash(meta.Scope), meta.GitCommitSha, meta.PathExists())
+		}
+	}
+
+	b.WriteString("\nMarketplaces:\n")
+	if marketplaces, err := state.LoadMarketplaces(claudeDir); err != nil {
+		fmt.Fprintf(&b, "  (could not read: %v)\n", err)
+	} else {
+		names := make([]string, 0, len(marketplaces))
+		for name := range marketplaces {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			m := marketplaces[name]
+			fmt.Fprintf(&b, "  %s  source=%s %s updated=%s\n", name, m.Source.Source, m.Source.Key(), orDash(m.LastUpdated))
+		}
+	}
+
+	b.WriteString("\nMCP servers (user):\n")
+	if servers, err := state.LoadMCPServers(profile.DefaultClaudeJSONPath()); err != nil {
+		fmt.Fprintf(&b, "  (could not read: %v)\n", err)
+	} else {
+		names := make([]string, 0, len(servers))
+		for name := range servers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			s := servers[name]
+			fmt.Fprintf(&b, "  %s  command=%s args=%d\n", name, orDash(s.Command), len(s.Args))
+		}
+	}
+	return b.String()
+}
+
+// tailLines returns the last n lines of the file at path
+func tailLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func formatSize(n int) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	size := float64(n) / 1024
+	for _, unit := range []string{"KB", "MB", "GB"} {
+		if size < 1024 || unit == "GB" {
+			return fmt.Sprintf("%.1f %s", size, unit)
+		}
+		size /= 1024
+	}
+	return ""
+}
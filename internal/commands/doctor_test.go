@@ -0,0 +1,72 @@
+// ABOUTME: Tests for doctor's plugin path analysis and Claude CLI platform check
+// ABOUTME: Verifies stale paths are corrected for marketplaces with any layout
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/claude/clicompat"
+	"github.com/claudeup/claudeup/internal/platform"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func TestGetExpectedPathUsesMarketplaceManifest(t *testing.T) {
+	marketplacesDir := filepath.Join(t.TempDir(), "plugins", "marketplaces")
+	root := filepath.Join(marketplacesDir, "never-heard-of-it")
+	os.MkdirAll(filepath.Join(root, ".claude-plugin"), 0755)
+	os.WriteFile(filepath.Join(root, ".claude-plugin", "marketplace.json"),
+		[]byte(`{"plugins": [{"name": "widget", "source": "./src/widget"}]}`), 0644)
+	os.MkdirAll(filepath.Join(root, "src", "widget"), 0755)
+
+	stale := filepath.Join(root, "widget")
+	want := filepath.Join(root, "src", "widget")
+
+	// Found through the marketplace directory in the stale path
+	if got := getExpectedPath(state.MarketplaceRegistry{}, "widget@never-heard-of-it", stale); got != want {
+		t.Errorf("getExpectedPath() = %q, want %q", got, want)
+	}
+
+	// Paths outside the marketplace clone are never rewritten
+	if got := getExpectedPath(state.MarketplaceRegistry{}, "widget@never-heard-of-it", "/elsewhere/widget"); got != "" {
+		t.Errorf("Expected no fix for a path outside the clone, got %q", got)
+	}
+}
+
+func TestShowClaudeCLICheckFlagsPlatformMismatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		binary   platform.Binary
+		host     platform.Platform
+		want     string
+		hasIssue bool
+	}{
+		{"native", platform.Binary{OS: "linux", Archs: []string{"arm64"}, Libc: platform.LibcGlibc},
+			platform.Platform{OS: "linux", Arch: "arm64", Libc: platform.LibcGlibc}, "✓ claude 2.0.1", false},
+		{"wrong arch", platform.Binary{OS: "linux", Archs: []string{"amd64"}, Libc: platform.LibcGlibc},
+			platform.Platform{OS: "linux", Arch: "arm64", Libc: platform.LibcGlibc}, "✗ claude is built for amd64, but this machine is arm64 and won't start", true},
+		{"glibc on alpine", platform.Binary{OS: "linux", Archs: []string{"amd64"}, Libc: platform.LibcGlibc},
+			platform.Platform{OS: "linux", Arch: "amd64", Libc: platform.LibcMusl}, "✗ claude is linked against glibc, but this system uses musl", true},
+		{"rosetta", platform.Binary{OS: "darwin", Archs: []string{"amd64"}},
+			platform.Platform{OS: "darwin", Arch: "arm64"}, "⚠ claude is built for amd64, but this machine is arm64, so it runs under emulation", true},
+	}
+	for _, tt := range tests {
+		b := tt.binary
+		c := CLICheck{Found: true, Result: clicompat.Result{Version: "2.0.1", Status: clicompat.Tested}, Binary: &b}
+		c.Mismatch = b.Mismatch(tt.host)
+		c.Emulated = c.Mismatch != "" && b.Emulated(tt.host)
+
+		var buf bytes.Buffer
+		showClaudeCLICheck(ui.NewPrinter(&buf, &buf, false), c)
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("%s: expected %q in:\n%s", tt.name, tt.want, buf.String())
+		}
+		if c.HasIssue() != tt.hasIssue {
+			t.Errorf("%s: expected HasIssue %v", tt.name, tt.hasIssue)
+		}
+	}
+}
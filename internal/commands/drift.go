@@ -0,0 +1,75 @@
+// ABOUTME: Drift between the active profile and Claude's current state
+// ABOUTME: Compares content hashes recorded at apply before falling back to a full comparison
+package commands
+
+import (
+	"fmt"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+// profileDrift counts the items that have changed since the profile name
+// was applied, or returns -1 if it can't tell. When the state's content
+// hash matches the one recorded at apply, nothing else is read.
+func profileDrift(name string) int {
+	current, err := profile.Snapshot("current", claudeDir, profile.DefaultClaudeJSONPath())
+	if err != nil {
+		return -1
+	}
+	if recorded, err := profile.LoadContentHash(getAppliedDir(), name); err == nil && recorded == profile.ContentHash(current) {
+		return 0
+	}
+	base, err := appliedBase(name)
+	if err != nil {
+		return -1
+	}
+	overrides, err := profile.LoadOverrides()
+	if err != nil {
+		return -1
+	}
+	return profile.Drift(base, current, overrides)
+}
+
+// describeDrift summarizes the active profile's drift for status
+func describeDrift(name string) string {
+	switch drift := profileDrift(name); {
+	case drift < 0:
+		return ""
+	case drift == 0:
+		return " (✓ in sync)"
+	case drift == 1:
+		return " (⚠ 1 change since applied)"
+	default:
+		return fmt.Sprintf(" (⚠ %d changes since applied)", drift)
+	}
+}
+
+// appliedBase returns the copy of the profile that was last applied, or the
+// saved profile if there is none
+func appliedBase(name string) (*profile.Profile, error) {
+	if p, err := profile.Load(getAppliedDir(), name); err == nil {
+		return p, nil
+	}
+	return loadProfileWithFallback(getProfilesDir(), name)
+}
+
+// recordContentHash records the hash of the current state after p was
+// applied, but only if the state matches p; otherwise drift is measured in
+// full until the next apply
+func recordContentHash(out ui.Printer, p *profile.Profile) {
+	appliedDir := getAppliedDir()
+	current, err := profile.Snapshot("current", claudeDir, profile.DefaultClaudeJSONPath())
+	var overrides *profile.Overrides
+	if err == nil {
+		overrides, err = profile.LoadOverrides()
+	}
+	if err == nil && profile.Drift(p, current, overrides) == 0 {
+		err = profile.SaveContentHash(appliedDir, p.Name, profile.ContentHash(current))
+	} else {
+		err = profile.RemoveContentHash(appliedDir, p.Name)
+	}
+	if err != nil {
+		out.Warnf("  Warning: could not record the applied state: %v\n", err)
+	}
+}
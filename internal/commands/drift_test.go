@@ -0,0 +1,44 @@
+// ABOUTME: Tests for measuring drift from the active profile
+// ABOUTME: Checks the recorded content hash short-circuits the comparison and is dropped when stale
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func TestProfileDriftUsesRecordedHash(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	oldClaudeDir := claudeDir
+	claudeDir = filepath.Join(home, ".claude")
+	defer func() { claudeDir = oldClaudeDir }()
+	profile.ResetSnapshotCache()
+
+	p := &profile.Profile{Name: "frontend", Plugins: []string{"a@m"}}
+	out := ui.NewPrinter(os.Stdout, os.Stderr, true)
+	recordAppliedProfile(out, p)
+	if _, err := profile.LoadContentHash(getAppliedDir(), "frontend"); err == nil {
+		t.Error("Expected no hash recorded when the state doesn't match the profile")
+	}
+	if got := profileDrift("frontend"); got != 1 {
+		t.Errorf("profileDrift() = %d, want 1", got)
+	}
+
+	// A matching recorded hash is trusted without comparing to the profile
+	current, _ := profile.Snapshot("current", claudeDir, profile.DefaultClaudeJSONPath())
+	if err := profile.SaveContentHash(getAppliedDir(), "frontend", profile.ContentHash(current)); err != nil {
+		t.Fatal(err)
+	}
+	if got := profileDrift("frontend"); got != 0 {
+		t.Errorf("profileDrift() = %d with a matching hash, want 0", got)
+	}
+	if got := describeDrift("frontend"); got != " (✓ in sync)" {
+		t.Errorf("describeDrift() = %q", got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/claudeup/claudeup/internal/claude"
 	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -27,9 +28,14 @@ Example:
 
 func init() {
 	rootCmd.AddCommand(enableCmd)
+	addForceFlag(enableCmd)
 }
 
 func runEnable(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
 	pluginName := args[0]
 
 	// Load config
@@ -54,6 +60,9 @@ func runEnable(cmd *cobra.Command, args []string) error {
 	if plugins.PluginExists(pluginName) {
 		return fmt.Errorf("plugin %s is already enabled", pluginName)
 	}
+	if err := checkClaudeNotRunning(cmd.Context(), out); err != nil {
+		return err
+	}
 
 	// Restore plugin to registry
 	pluginMeta := claude.PluginMetadata{
@@ -75,9 +84,9 @@ func runEnable(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to save plugins: %w", err)
 	}
 
-	fmt.Printf("✓ Enabled %s\n\n", pluginName)
-	fmt.Println("Plugin commands, agents, skills, and MCP servers are now available")
-	fmt.Println("Run 'claudeup disable", pluginName+"' to disable again")
+	out.Printf("✓ Enabled %s\n\n", pluginName)
+	out.Println("Plugin commands, agents, skills, and MCP servers are now available")
+	out.Println("Run 'claudeup disable", pluginName+"' to disable again")
 
 	return nil
 }
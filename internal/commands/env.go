@@ -0,0 +1,137 @@
+// ABOUTME: env command printing shell export statements for a profile
+// ABOUTME: Secrets are resolved on demand and only written to stdout, never to disk
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var envShell string
+
+var envCmd = &cobra.Command{
+	Use:   "env [profile]",
+	Short: "Print shell exports for a profile's environment and secrets",
+	Long: `Prints export statements for the profile's shellEnv variables and the
+secrets its MCP servers need, resolved from env, 1Password, Bitwarden,
+SOPS, or keychain. Values are only written to stdout - evaluate the output
+in your shell.
+
+Without an argument the active profile is used.`,
+	Example: `  eval "$(claudeup env)"
+  claudeup env backend --shell fish | source
+  claudeup env backend --shell powershell | Invoke-Expression`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runEnv,
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.Flags().StringVar(&envShell, "shell", "", "Output syntax: sh, bash, zsh, fish, or powershell (default: from $SHELL)")
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func runEnv(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		cfg, err := config.Load()
+		if err == nil {
+			name = cfg.Preferences.ActiveProfile
+		}
+		if name == "" {
+			return fmt.Errorf("no active profile; pass a profile name or run 'claudeup profile use <name>'")
+		}
+	}
+
+	p, err := loadProfileWithFallback(getProfilesDir(), name)
+	if err != nil {
+		return fmt.Errorf("profile %q not found: %w", name, err)
+	}
+
+	shell := envShell
+	if shell == "" {
+		shell = defaultShell()
+	}
+
+	return printProfileEnv(out, p, shell)
+}
+
+// printProfileEnv prints the profile's shellEnv and secrets as exports,
+// with this machine's env overrides
+func printProfileEnv(out ui.Printer, p *profile.Profile, shell string) error {
+	p, err := p.ForThisMachine()
+	if err != nil {
+		return err
+	}
+	vars, errs := p.ResolveShellEnv(buildSecretChain())
+	// Warnings go to stderr so they don't end up in eval'd output
+	for _, err := range errs {
+		out.Warnf("⚠ %v\n", err)
+	}
+
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		if !envNamePattern.MatchString(k) {
+			out.Warnf("⚠ Skipping invalid variable name %q\n", k)
+			continue
+		}
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		fmt.Println(formatExport(shell, k, vars[k]))
+	}
+	return nil
+}
+
+// defaultShell names the shell from $SHELL. Windows has no $SHELL, and
+// its shell is PowerShell.
+func defaultShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return strings.TrimSuffix(filepath.Base(shell), ".exe")
+	}
+	if runtime.GOOS == "windows" {
+		return "powershell"
+	}
+	return ""
+}
+
+// formatExport renders one assignment in the given shell's syntax
+func formatExport(shell, name, value string) string {
+	switch shell {
+	case "fish":
+		return fmt.Sprintf("set -gx %s %s;", name, fishQuote(value))
+	case "powershell", "pwsh":
+		return fmt.Sprintf("$env:%s = %s", name, powershellQuote(value))
+	}
+	return fmt.Sprintf("export %s=%s", name, posixQuote(value))
+}
+
+func posixQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// powershellQuote single-quotes s; inside single quotes PowerShell expands
+// nothing, and a quote is written twice. Curly quotes count as quotes too.
+func powershellQuote(s string) string {
+	return "'" + strings.NewReplacer("'", "''", "‘", "‘‘", "’", "’’").Replace(s) + "'"
+}
+
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,25 @@
+// ABOUTME: Tests for env command output formatting
+// ABOUTME: Ensures values with quotes survive eval in POSIX shells, fish, and PowerShell
+package commands
+
+import "testing"
+
+func TestFormatExport(t *testing.T) {
+	tests := []struct {
+		shell string
+		value string
+		want  string
+	}{
+		{"bash", "plain", "export KEY='plain'"},
+		{"zsh", "it's $HOME", `export KEY='it'\''s $HOME'`},
+		{"fish", `it's a\b`, `set -gx KEY 'it\'s a\\b';`},
+		{"powershell", `it's $env:HOME`, `$env:KEY = 'it''s $env:HOME'`},
+		{"pwsh", "C:\\Users\\me", `$env:KEY = 'C:\Users\me'`},
+	}
+
+	for _, tt := range tests {
+		if got := formatExport(tt.shell, "KEY", tt.value); got != tt.want {
+			t.Errorf("formatExport(%s, %q) = %s, want %s", tt.shell, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+// ABOUTME: explain command answering "why is this installed?" for a plugin, MCP server, or marketplace
+// ABOUTME: Joins Claude's registries, claudeup's disabled items, saved profiles, and the history log
+package commands
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/history"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var explainFormat string
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <plugin|mcp-server|marketplace>",
+	Short: "Show where an installed plugin, MCP server, or marketplace came from",
+	Long: `Explains why an item is installed: the last apply that changed it, the
+marketplace and commit a plugin came from, which profiles list it and whether
+the active profile is one of them, whether it is disabled, and where it is on
+disk.
+
+Plugins can be named with or without their @marketplace. Marketplaces can be
+named by their registry name or their repo, URL, or path. Every kind of item
+with the name is explained.
+
+Applies are recorded from this version on; items installed earlier, or by
+hand, show no apply.`,
+	Example: `  claudeup explain tdd@superpowers
+  claudeup explain context7
+  claudeup explain obra/superpowers --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().StringVar(&explainFormat, "format", "", "Print the explanation as json or yaml")
+}
+
+// explanation is what 'claudeup explain' reports about one item
+type explanation struct {
+	Kind        string       `json:"kind"` // plugin, mcp, or marketplace
+	Name        string       `json:"name"`
+	Installed   bool         `json:"installed"`
+	Disabled    bool         `json:"disabled,omitempty"`
+	Location    string       `json:"location,omitempty"`
+	Version     string       `json:"version,omitempty"`
+	InstalledAt string       `json:"installedAt,omitempty"`
+	Marketplace string       `json:"marketplace,omitempty"` // a plugin's marketplace, or a marketplace's source
+	Commit      string       `json:"commit,omitempty"`      // marketplace commit a plugin was installed from
+	Command     string       `json:"command,omitempty"`     // an MCP server's command line
+	Plugins     []string     `json:"plugins,omitempty"`     // installed plugins from a marketplace
+	Profiles    []profileRef `json:"profiles,omitempty"`
+	LastApply   *applyRef    `json:"lastApply,omitempty"`
+}
+
+// profileRef is a profile that lists the item
+type profileRef struct {
+	Profile string `json:"profile"`
+	Active  bool   `json:"active,omitempty"`
+	Via     string `json:"via"` // where in the profile, e.g. "plugins" or "group testing"
+}
+
+// applyRef is the last recorded apply that changed the item
+type applyRef struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Profile string    `json:"profile"`
+	Source  string    `json:"source,omitempty"`
+}
+
+// explainSources is everything an explanation is joined from
+type explainSources struct {
+	plugins        *state.PluginRegistry
+	marketplaces   state.MarketplaceRegistry
+	mcpServers     map[string]state.MCPServer
+	claudeJSONPath string
+	cfg            *config.GlobalConfig
+	profiles       []*profile.Profile
+	history        []history.Entry
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if err := validateFormat("format", explainFormat); err != nil {
+		return err
+	}
+
+	src, err := loadExplainSources()
+	if err != nil {
+		return err
+	}
+	found := src.explain(args[0])
+	if len(found) == 0 {
+		return fmt.Errorf("nothing named %q is installed or listed in a profile", args[0])
+	}
+
+	if explainFormat != "" {
+		return printFormatted(explainFormat, found)
+	}
+	for i, e := range found {
+		if i > 0 {
+			out.Println()
+		}
+		showExplanation(out, e, src.cfg.Preferences.ActiveProfile)
+	}
+	return nil
+}
+
+func loadExplainSources() (*explainSources, error) {
+	src := &explainSources{claudeJSONPath: profile.DefaultClaudeJSONPath()}
+	var err error
+
+	// A fresh install has none of these files yet
+	if src.plugins, err = state.LoadPlugins(claudeDir); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load plugins: %w", err)
+	}
+	if src.marketplaces, err = state.LoadMarketplaces(claudeDir); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load marketplaces: %w", err)
+	}
+	if src.mcpServers, err = state.LoadMCPServers(src.claudeJSONPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load MCP servers: %w", err)
+	}
+	if src.cfg, err = config.LoadExisting(); err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if src.profiles, err = getAllProfiles(getProfilesDir()); err != nil {
+		return nil, err
+	}
+	if src.history, err = history.Load(history.DefaultPath()); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return src, nil
+}
+
+// explain returns an explanation for every plugin, MCP server, and
+// marketplace that query names
+func (src *explainSources) explain(query string) []explanation {
+	var found []explanation
+	for _, name := range src.pluginNames(query) {
+		found = append(found, src.explainPlugin(name))
+	}
+	if e, ok := src.explainMCP(query); ok {
+		found = append(found, e)
+	}
+	if e, ok := src.explainMarketplace(query); ok {
+		found = append(found, e)
+	}
+	return found
+}
+
+// pluginNames returns the installed or listed plugins that query names,
+// as name@marketplace or as a bare name
+func (src *explainSources) pluginNames(query string) []string {
+	var known []string
+	if src.plugins != nil {
+		for name := range src.plugins.Plugins {
+			known = append(known, name)
+		}
+	}
+	for name := range src.cfg.DisabledPlugins {
+		known = append(known, name)
+	}
+	for _, p := range src.profiles {
+		known = append(known, p.Plugins...)
+		known = append(known, p.Disabled.Plugins...)
+		for _, plugins := range p.Groups {
+			known = append(known, plugins...)
+		}
+		if p.SetupWizard != nil {
+			for _, c := range p.SetupWizard.Categories {
+				known = append(known, c.Plugins...)
+			}
+		}
+	}
+
+	var names []string
+	for _, name := range known {
+		if (name == query || strings.HasPrefix(name, query+"@")) && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (src *explainSources) explainPlugin(name string) explanation {
+	e := explanation{Kind: "plugin", Name: name}
+	var meta state.PluginMetadata
+	if src.plugins != nil {
+		meta, e.Installed = src.plugins.GetPlugin(name)
+	}
+	if d, ok := src.cfg.GetDisabledPlugin(name); ok {
+		e.Installed, e.Disabled = true, true
+		meta = state.PluginMetadata{Version: d.Version, InstalledAt: d.InstalledAt, InstallPath: d.InstallPath, GitCommitSha: d.GitCommitSha}
+	}
+	e.Location, e.Version, e.InstalledAt, e.Commit = meta.InstallPath, meta.Version, meta.InstalledAt, meta.GitCommitSha
+
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		e.Marketplace = name[i+1:]
+		if mp, ok := src.marketplaces[e.Marketplace]; ok {
+			e.Marketplace = mp.Source.Key()
+		}
+	}
+
+	for _, p := range src.profiles {
+		var via []string
+		if slices.Contains(p.Plugins, name) {
+			via = append(via, "plugins")
+		}
+		for _, g := range p.GroupNames() {
+			if slices.Contains(p.Groups[g], name) {
+				if p.GroupEnabled(g) {
+					via = append(via, "group "+g)
+				} else {
+					via = append(via, "group "+g+" (disabled)")
+				}
+			}
+		}
+		if p.SetupWizard != nil {
+			for _, c := range p.SetupWizard.Categories {
+				if slices.Contains(c.Plugins, name) {
+					via = append(via, "setup wizard category "+c.Name)
+				}
+			}
+		}
+		if slices.Contains(p.Disabled.Plugins, name) {
+			via = append(via, "disabled")
+		}
+		e.Profiles = src.addRef(e.Profiles, p, via)
+	}
+	e.LastApply = src.lastApply(profile.SubsystemPlugins, name)
+	return e
+}
+
+func (src *explainSources) explainMCP(name string) (explanation, bool) {
+	e := explanation{Kind: "mcp", Name: name}
+	if s, ok := src.mcpServers[name]; ok {
+		e.Installed = true
+		e.Location = src.claudeJSONPath
+		e.Command = strings.TrimSpace(s.Command + " " + strings.Join(s.Args, " "))
+	}
+	for _, ref := range src.cfg.DisabledMCPServers {
+		if strings.HasSuffix(ref, ":"+name) {
+			e.Disabled = true
+		}
+	}
+
+	for _, p := range src.profiles {
+		var via []string
+		for _, s := range p.MCPServers {
+			if s.Name == name {
+				via = append(via, "mcpServers")
+			}
+		}
+		for _, s := range p.ProvidedMCPServers {
+			if s.Name == name {
+				via = append(via, "provided by plugin "+s.Plugin)
+			}
+		}
+		for _, ref := range p.Disabled.MCPServers {
+			if strings.HasSuffix(ref, ":"+name) {
+				via = append(via, "disabled")
+			}
+		}
+		e.Profiles = src.addRef(e.Profiles, p, via)
+	}
+	e.LastApply = src.lastApply(profile.SubsystemMCP, name)
+	return e, e.Installed || e.Disabled || len(e.Profiles) > 0
+}
+
+func (src *explainSources) explainMarketplace(query string) (explanation, bool) {
+	e := explanation{Kind: "marketplace", Name: query, Marketplace: query}
+	for name, meta := range src.marketplaces {
+		if name == query || meta.Source.Key() == query {
+			e.Name, e.Installed = name, true
+			e.Location = meta.InstallLocation
+			e.Marketplace = profile.RegisteredMarketplace(meta.Source).DisplayName()
+			break
+		}
+	}
+
+	if e.Installed && src.plugins != nil {
+		for plugin := range src.plugins.Plugins {
+			if strings.HasSuffix(plugin, "@"+e.Name) {
+				e.Plugins = append(e.Plugins, plugin)
+			}
+		}
+		sort.Strings(e.Plugins)
+	}
+
+	for _, p := range src.profiles {
+		var via []string
+		for _, m := range p.Marketplaces {
+			if m.DisplayName() == e.Marketplace {
+				via = append(via, "marketplaces")
+			}
+		}
+		e.Profiles = src.addRef(e.Profiles, p, via)
+	}
+	e.LastApply = src.lastApply(profile.SubsystemMarketplaces, e.Marketplace)
+	return e, e.Installed || len(e.Profiles) > 0
+}
+
+// addRef appends a reference to p when via says where p lists the item
+func (src *explainSources) addRef(refs []profileRef, p *profile.Profile, via []string) []profileRef {
+	if len(via) == 0 {
+		return refs
+	}
+	return append(refs, profileRef{
+		Profile: p.Name,
+		Active:  p.Name == src.cfg.Preferences.ActiveProfile,
+		Via:     strings.Join(via, ", "),
+	})
+}
+
+func (src *explainSources) lastApply(sub profile.Subsystem, name string) *applyRef {
+	entry, change, ok := history.LastChange(src.history, string(sub), name)
+	if !ok {
+		return nil
+	}
+	return &applyRef{Time: entry.Time, Action: change.Action, Profile: entry.Profile, Source: entry.Source}
+}
+
+func showExplanation(out ui.Printer, e explanation, active string) {
+	kind := "Plugin"
+	switch e.Kind {
+	case "mcp":
+		kind = "MCP server"
+	case "marketplace":
+		kind = "Marketplace"
+	}
+	out.Printf("━━━ %s %s ━━━\n", kind, e.Name)
+
+	field := func(label, value string) {
+		if value != "" {
+			out.Printf("  %-13s %s\n", label+":", value)
+		}
+	}
+	switch {
+	case e.Disabled && e.Kind == "plugin":
+		field("Installed", "yes, disabled with 'claudeup disable'")
+	case e.Disabled:
+		field("Installed", "disabled with 'claudeup mcp disable'")
+	case e.Installed:
+		field("Installed", "yes")
+	default:
+		field("Installed", "no")
+	}
+	field("Version", e.Version)
+	field("Location", e.Location)
+	field("Command", e.Command)
+
+	source := e.Marketplace
+	if e.Kind != "marketplace" && e.Commit != "" {
+		source += " at commit " + shortCommit(e.Commit)
+	}
+	if e.Kind == "marketplace" {
+		field("Source", source)
+	} else {
+		field("Marketplace", source)
+	}
+	if len(e.Plugins) > 0 {
+		field("Plugins", strings.Join(e.Plugins, ", "))
+	}
+	field("Installed at", e.InstalledAt)
+
+	if a := e.LastApply; a != nil {
+		field("Last change", fmt.Sprintf("%s by applying %s (%s) on %s", pastTense(a.Action), a.Profile, orDash(a.Source), formatDate(a.Time, "2006-01-02 15:04")))
+	} else if e.Installed {
+		field("Last change", "no recorded apply; installed by hand or before claudeup recorded changes")
+	}
+
+	for i, ref := range e.Profiles {
+		label := "Profiles:"
+		if i > 0 {
+			label = ""
+		}
+		name := ref.Profile
+		if ref.Active {
+			name += " (active)"
+		}
+		out.Printf("  %-13s %s: %s\n", label, name, ref.Via)
+	}
+
+	out.Println()
+	switch {
+	case active == "":
+		out.Println("→ No profile is active")
+	case slices.ContainsFunc(e.Profiles, func(r profileRef) bool { return r.Active }):
+		out.Printf("→ The active profile %s lists it\n", active)
+	case e.Installed:
+		out.Printf("⚠ The active profile %s doesn't list it\n", active)
+	default:
+		out.Printf("→ Not installed, and the active profile %s doesn't list it\n", active)
+	}
+}
+
+// pastTense turns an apply action into what it did to the item
+func pastTense(action string) string {
+	switch action {
+	case "add":
+		return "added"
+	case "install":
+		return "installed"
+	case "remove":
+		return "removed"
+	}
+	return action + "d" // disable, enable
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,116 @@
+// ABOUTME: Tests for 'claudeup explain'
+// ABOUTME: Joins fixed registries, config, profiles, and history without touching disk
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/history"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func testExplainSources() *explainSources {
+	cfg := config.DefaultConfig()
+	cfg.Preferences.ActiveProfile = "backend"
+	cfg.DisabledPlugins = map[string]config.DisabledPlugin{"old@sp": {Version: "0.1.0"}}
+
+	return &explainSources{
+		plugins: &state.PluginRegistry{Plugins: map[string][]state.PluginMetadata{
+			"tdd@sp":   {{Scope: "user", Version: "1.2.0", InstallPath: "/cache/tdd", GitCommitSha: "abcdef1234567"}},
+			"stray@sp": {{Scope: "user", Version: "0.3.0"}},
+		}},
+		marketplaces: state.MarketplaceRegistry{
+			"sp": {Source: state.MarketplaceSource{Source: "github", Repo: "obra/superpowers"}, InstallLocation: "/mp/sp"},
+		},
+		mcpServers:     map[string]state.MCPServer{"ctx": {Command: "npx", Args: []string{"-y", "ctx"}}},
+		claudeJSONPath: "/home/.claude.json",
+		cfg:            cfg,
+		profiles: []*profile.Profile{
+			{
+				Name:         "backend",
+				Plugins:      []string{"core@sp"},
+				Groups:       map[string][]string{"testing": {"tdd@sp"}},
+				Marketplaces: []profile.Marketplace{{Source: "github", Repo: "obra/superpowers"}},
+				MCPServers:   []profile.MCPServer{{Name: "ctx", Command: "npx"}},
+			},
+			{Name: "frontend", Plugins: []string{"tdd@sp"}, Disabled: profile.DisabledConfig{Plugins: []string{"old@sp"}}},
+		},
+		history: []history.Entry{
+			{Time: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC), Action: "apply", Profile: "frontend", Source: "cli",
+				Done: []history.Change{{Action: "install", Subsystem: "plugins", Name: "tdd@sp"}}},
+		},
+	}
+}
+
+func TestExplainPlugin(t *testing.T) {
+	found := testExplainSources().explain("tdd")
+	if len(found) != 1 {
+		t.Fatalf("Expected one plugin, got %+v", found)
+	}
+	e := found[0]
+	if e.Kind != "plugin" || e.Name != "tdd@sp" || !e.Installed || e.Disabled {
+		t.Errorf("Unexpected explanation %+v", e)
+	}
+	if e.Marketplace != "obra/superpowers" || e.Commit != "abcdef1234567" || e.Location != "/cache/tdd" {
+		t.Errorf("Expected marketplace, commit, and location from the registries, got %+v", e)
+	}
+	if len(e.Profiles) != 2 || e.Profiles[0].Via != "group testing" || !e.Profiles[0].Active || e.Profiles[1].Via != "plugins" {
+		t.Errorf("Unexpected profiles %+v", e.Profiles)
+	}
+	if e.LastApply == nil || e.LastApply.Profile != "frontend" || e.LastApply.Action != "install" {
+		t.Errorf("Expected the frontend apply, got %+v", e.LastApply)
+	}
+}
+
+func TestExplainDisabledPlugin(t *testing.T) {
+	found := testExplainSources().explain("old@sp")
+	if len(found) != 1 || !found[0].Installed || !found[0].Disabled || found[0].Version != "0.1.0" {
+		t.Fatalf("Expected the disabled plugin with its saved metadata, got %+v", found)
+	}
+	if len(found[0].Profiles) != 1 || found[0].Profiles[0].Via != "disabled" {
+		t.Errorf("Unexpected profiles %+v", found[0].Profiles)
+	}
+}
+
+func TestExplainMCPAndMarketplace(t *testing.T) {
+	src := testExplainSources()
+
+	found := src.explain("ctx")
+	if len(found) != 1 || found[0].Kind != "mcp" || found[0].Command != "npx -y ctx" || found[0].Location != "/home/.claude.json" {
+		t.Fatalf("Unexpected MCP explanation %+v", found)
+	}
+
+	for _, query := range []string{"sp", "obra/superpowers"} {
+		found = src.explain(query)
+		if len(found) != 1 || found[0].Kind != "marketplace" || found[0].Name != "sp" || found[0].Location != "/mp/sp" {
+			t.Fatalf("Unexpected marketplace explanation for %s: %+v", query, found)
+		}
+		if strings.Join(found[0].Plugins, " ") != "stray@sp tdd@sp" {
+			t.Errorf("Expected the installed plugins from the marketplace, got %v", found[0].Plugins)
+		}
+		if len(found[0].Profiles) != 1 || found[0].Profiles[0].Profile != "backend" {
+			t.Errorf("Unexpected profiles %+v", found[0].Profiles)
+		}
+	}
+
+	if found := src.explain("nothing"); len(found) != 0 {
+		t.Errorf("Expected nothing, got %+v", found)
+	}
+}
+
+func TestShowExplanationFlagsUnlistedItems(t *testing.T) {
+	src := testExplainSources()
+	var buf bytes.Buffer
+	showExplanation(ui.NewPrinter(&buf, &buf, false), src.explain("stray")[0], "backend")
+
+	output := buf.String()
+	if !strings.Contains(output, "no recorded apply") || !strings.Contains(output, "⚠ The active profile backend doesn't list it") {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+}
@@ -0,0 +1,194 @@
+// ABOUTME: Plugin extensions: doctor checks and 'claudeup x' commands shipped by installed plugins
+// ABOUTME: Discovers enabled plugins' claudeup/ directories and runs what they provide
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/doctor"
+	"github.com/claudeup/claudeup/internal/extensions"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var extensionCmd = &cobra.Command{
+	Use:   "x [plugin] [command] [args...]",
+	Short: "Run a command provided by an installed plugin",
+	Long: `Runs a command that an installed plugin ships for claudeup, passing any
+further arguments through. Plugins declare commands in claudeup/checks.json
+or ship executables in claudeup/commands/.
+
+With no arguments, lists every plugin command; with only a plugin, lists
+that plugin's. A plugin can be named without its marketplace when the name
+is unambiguous. Disabled plugins are skipped.`,
+	Example: `  claudeup x
+  claudeup x lint@acme
+  claudeup x lint fmt --check`,
+	DisableFlagParsing: true,
+	SilenceUsage:       true,
+	RunE:               runExtension,
+}
+
+func init() {
+	rootCmd.AddCommand(extensionCmd)
+}
+
+func runExtension(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+		return cmd.Help()
+	}
+
+	plugins, errs, err := discoverExtensions()
+	if err != nil {
+		return err
+	}
+	for name, err := range errs {
+		out.Printf("⚠ %s: %v\n", name, err)
+	}
+
+	if len(args) == 0 {
+		showExtensionCommands(out, plugins)
+		return nil
+	}
+	p, err := findExtensionPlugin(plugins, args[0])
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		showExtensionCommands(out, []*extensions.Plugin{p})
+		return nil
+	}
+
+	for _, c := range p.Commands {
+		if c.Name == args[1] {
+			if err := c.Exec(cmd.Context(), args[2:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+				return fmt.Errorf("%s %s: %w", p.Name, c.Name, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s has no command %q (run 'claudeup x %s' to list them)", p.Name, args[1], p.Name)
+}
+
+// discoverExtensions loads the extensions of every enabled, installed plugin
+func discoverExtensions() ([]*extensions.Plugin, map[string]error, error) {
+	registry, err := state.LoadPlugins(claudeDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to load plugins: %w", err)
+	}
+	plugins, errs := extensions.Discover(enabledPluginDirs(registry))
+	return plugins, errs, nil
+}
+
+// enabledPluginDirs maps each installed plugin that isn't disabled to its
+// install directory
+func enabledPluginDirs(registry *state.PluginRegistry) map[string]string {
+	disabled := map[string]config.DisabledPlugin{}
+	if cfg, err := config.LoadExisting(); err == nil {
+		disabled = cfg.DisabledPlugins
+	}
+	dirs := map[string]string{}
+	for name, meta := range registry.GetAllPlugins() {
+		if _, off := disabled[name]; off || !meta.PathExists() {
+			continue
+		}
+		dirs[name] = meta.InstallPath
+	}
+	return dirs
+}
+
+// findExtensionPlugin matches name against full plugin names, then against
+// names without the marketplace
+func findExtensionPlugin(plugins []*extensions.Plugin, name string) (*extensions.Plugin, error) {
+	var matches []*extensions.Plugin
+	for _, p := range plugins {
+		if p.Name == name {
+			return p, nil
+		}
+		if base, _, _ := strings.Cut(p.Name, "@"); base == name {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no installed plugin %q provides claudeup commands (run 'claudeup x' to list them)", name)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, p := range matches {
+		names[i] = p.Name
+	}
+	return nil, fmt.Errorf("%q matches more than one plugin: %s", name, strings.Join(names, ", "))
+}
+
+func showExtensionCommands(out ui.Printer, plugins []*extensions.Plugin) {
+	shown := false
+	for _, p := range plugins {
+		if len(p.Commands) == 0 {
+			continue
+		}
+		if !shown {
+			out.Println("━━━ Plugin Commands ━━━")
+		}
+		shown = true
+		out.Printf("  %s\n", p.Name)
+		w := tabwriter.NewWriter(out.Out(), 0, 0, 2, ' ', 0)
+		for _, c := range p.Commands {
+			fmt.Fprintf(w, "    %s\t%s\n", c.Name, c.Description)
+		}
+		w.Flush()
+	}
+	if !shown {
+		out.Println("No installed plugin provides claudeup commands.")
+	}
+}
+
+// pluginChecks turns the checks shipped by enabled plugins into doctor
+// checks. A plugin whose claudeup/ directory can't be read gets a failing
+// check saying why.
+func pluginChecks(registry *state.PluginRegistry) []doctor.Check {
+	plugins, errs := extensions.Discover(enabledPluginDirs(registry))
+
+	var checks []doctor.Check
+	for _, p := range plugins {
+		for _, c := range p.Checks {
+			title := c.Description
+			if title == "" {
+				title = c.Name
+			}
+			checks = append(checks, doctor.Check{
+				Name:    p.Name + "/" + c.Name,
+				Title:   title + " (" + p.Name + ")",
+				Timeout: c.Timeout,
+				Run:     c.RunCheck,
+			})
+		}
+	}
+
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		err := errs[name]
+		checks = append(checks, doctor.Check{
+			Name:  name + "/" + extensions.ManifestFile,
+			Title: "Plugin checks (" + name + ")",
+			Run:   func(context.Context) (string, error) { return "", err },
+		})
+	}
+	return checks
+}
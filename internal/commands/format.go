@@ -0,0 +1,70 @@
+// ABOUTME: Machine-readable output helpers shared by commands with --json or --*-format flags
+// ABOUTME: YAML output reuses the JSON field names so both formats share one schema
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Machine-readable output formats
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printYAML writes v to stdout as YAML, keyed by its JSON field names
+func printYAML(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	// JSON is valid YAML; decoding into a node keeps the field order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle clears the flow style that JSON input leaves on every node, so
+// the output uses normal indented YAML
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// validateFormat checks a --*-format flag value
+func validateFormat(flag, format string) error {
+	switch format {
+	case "", formatJSON, formatYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid --%s %q (use json or yaml)", flag, format)
+}
+
+// printFormatted writes v in the given machine-readable format
+func printFormatted(format string, v interface{}) error {
+	if format == formatYAML {
+		return printYAML(v)
+	}
+	return printJSON(v)
+}
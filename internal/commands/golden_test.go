@@ -0,0 +1,139 @@
+// ABOUTME: Golden-file tests for command output, captured through an injected ui.Printer
+// ABOUTME: Run 'go test ./internal/commands -run Golden -update' to rewrite testdata/golden
+package commands
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// goldenEnv is a fake home with one marketplace and two plugins: "tools"
+// provides MCP servers, "widget" has a stale install path
+func goldenEnv(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	// Keep the real claude CLI out of the output
+	t.Setenv("PATH", t.TempDir())
+	// Golden files are English whatever the machine's locale
+	t.Setenv("CLAUDEUP_LANG", "en")
+	profile.ResetSnapshotCache()
+
+	dir := filepath.Join(home, ".claude")
+	marketplace := filepath.Join(dir, "plugins", "marketplaces", "acme")
+	tools := filepath.Join(marketplace, "plugins", "tools")
+	os.MkdirAll(filepath.Join(tools, ".claude-plugin"), 0755)
+	os.WriteFile(filepath.Join(tools, ".claude-plugin", "plugin.json"),
+		[]byte(`{"name": "tools", "mcpServers": {"db": {"command": "pg-mcp"}}}`), 0644)
+	os.MkdirAll(filepath.Join(marketplace, "plugins", "widget"), 0755)
+
+	if err := claude.SaveMarketplaces(dir, claude.MarketplaceRegistry{
+		"acme": {Source: claude.MarketplaceSource{Source: "github", Repo: "acme/plugins"}, InstallLocation: marketplace},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	registry := &claude.PluginRegistry{Version: 2, Plugins: make(map[string][]claude.PluginMetadata)}
+	registry.SetPlugin("tools@acme", claude.PluginMetadata{Scope: "user", Version: "1.0.0", InstallPath: tools})
+	registry.SetPlugin("widget@acme", claude.PluginMetadata{Scope: "user", Version: "1.0.0", InstallPath: filepath.Join(marketplace, "widget")})
+	if err := claude.SavePlugins(dir, registry); err != nil {
+		t.Fatal(err)
+	}
+	return home
+}
+
+// runForGolden executes a command with its output captured by an injected
+// Printer. Paths under home are replaced with $HOME.
+func runForGolden(t *testing.T, home string, args ...string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	ctx := ui.WithPrinter(context.Background(), ui.NewPrinter(&buf, &buf, false))
+
+	args = append(args, "--claude-dir", filepath.Join(home, ".claude"))
+	target, _, err := rootCmd.Find(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cobra keeps a subcommand's context between runs; set it explicitly
+	target.SetContext(ctx)
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	defer func() {
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("%s: %v", strings.Join(args, " "), err)
+	}
+	return strings.ReplaceAll(buf.String(), home, "$HOME")
+}
+
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".golden")
+	if *updateGolden {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Missing golden file (run with -update): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Output differs from %s\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+func TestGoldenOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"doctor", []string{"doctor"}},
+		{"cleanup-dry-run", []string{"cleanup", "--dry-run"}},
+		{"mcp-list", []string{"mcp", "list"}},
+		{"profile-show-default", []string{"profile", "show", "default"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := goldenEnv(t)
+			assertGolden(t, tt.name, runForGolden(t, home, tt.args...))
+		})
+	}
+}
+
+func TestGoldenQuiet(t *testing.T) {
+	home := goldenEnv(t)
+	var buf bytes.Buffer
+	ctx := ui.WithPrinter(context.Background(), ui.NewPrinter(&buf, &buf, true))
+	cmd := doctorCmd
+	cmd.SetContext(ctx)
+	defer cmd.SetContext(nil)
+
+	oldClaudeDir := claudeDir
+	claudeDir = filepath.Join(home, ".claude")
+	defer func() { claudeDir = oldClaudeDir }()
+	if err := runDoctor(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output from a quiet printer, got %q", buf.String())
+	}
+}
@@ -0,0 +1,188 @@
+// ABOUTME: lint command reporting plugins in a profile that define the same names
+// ABOUTME: Checks slash commands, agents, and MCP servers across plugins, the profile, and ~/.claude
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/audit"
+	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var lintFormat string
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [profile]",
+	Short: "Find plugins that define the same commands, agents, or MCP servers",
+	Long: `Inspects the plugins a profile selects and reports names that more than
+one of them defines:
+
+  Commands      two plugins shipping /review make the bare command ambiguous
+  Agents        duplicate agent names, from frontmatter or the file name
+  MCP servers   a plugin server with the same name as another, or as one
+                of the profile's own servers
+
+Your own ~/.claude/commands and ~/.claude/agents are checked too. Plugins
+are read from their install directory, or from their marketplace clone
+when they aren't installed yet.
+
+Defaults to the active profile. Exits non-zero if anything conflicts.`,
+	Example: `  claudeup lint
+  claudeup lint backend
+  claudeup lint "backend +frontend" --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.Flags().StringVar(&lintFormat, "format", "", "Print the report as json or yaml")
+}
+
+// lintReport is the machine-readable form of 'lint'
+type lintReport struct {
+	Profile   string            `json:"profile"`
+	Plugins   int               `json:"plugins"`
+	Conflicts []audit.Conflict  `json:"conflicts"`
+	Skipped   map[string]string `json:"skipped,omitempty"` // plugins that couldn't be read, with why
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if err := validateFormat("format", lintFormat); err != nil {
+		return err
+	}
+
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		cfg, err := config.Load()
+		if err == nil {
+			name = cfg.Preferences.ActiveProfile
+		}
+		if name == "" {
+			return fmt.Errorf("no active profile; pass a profile name or run 'claudeup profile use <name>'")
+		}
+	}
+
+	p, err := loadProfileWithAddons(getProfilesDir(), strings.Fields(name))
+	if err != nil {
+		return err
+	}
+
+	report := lintProfile(p, claudeDir)
+	report.Profile = name
+	if lintFormat != "" {
+		if err := printFormatted(lintFormat, report); err != nil {
+			return err
+		}
+	} else {
+		showLintReport(out, report)
+	}
+
+	if len(report.Conflicts) > 0 {
+		return fmt.Errorf("%d conflicts found", len(report.Conflicts))
+	}
+	return nil
+}
+
+// lintProfile collects the definitions of p's enabled plugins, its own MCP
+// servers, and the user's commands and agents, and finds the clashes
+func lintProfile(p *profile.Profile, claudeDir string) lintReport {
+	var report lintReport
+	installed := make(map[string]string)
+	if registry, err := claude.LoadPlugins(claudeDir); err == nil {
+		for name, meta := range registry.GetAllPlugins() {
+			if meta.PathExists() {
+				installed[name] = meta.InstallPath
+			}
+		}
+	}
+
+	defs := []audit.Definitions{audit.UserDefinitions("~/.claude", claudeDir)}
+	if len(p.MCPServers) > 0 {
+		own := audit.Definitions{Source: "profile " + p.Name}
+		for _, m := range p.MCPServers {
+			own.MCPServers = append(own.MCPServers, m.Name)
+		}
+		defs = append(defs, own)
+	}
+
+	for _, plugin := range p.Plugins {
+		if slices.Contains(p.Disabled.Plugins, plugin) {
+			continue
+		}
+		dir, ok := installed[plugin]
+		if !ok {
+			var err error
+			if dir, err = audit.LocatePlugin(claudeDir, plugin); err != nil {
+				report.skip(plugin, err)
+				continue
+			}
+		}
+		d, err := audit.PluginDefinitions(plugin, dir)
+		if err != nil {
+			report.skip(plugin, fmt.Errorf("%s: %w", filepath.Join(dir, ".claude-plugin", "plugin.json"), err))
+			continue
+		}
+		defs = append(defs, d)
+		report.Plugins++
+	}
+
+	report.Conflicts = audit.Conflicts(defs)
+	return report
+}
+
+func (r *lintReport) skip(plugin string, err error) {
+	if r.Skipped == nil {
+		r.Skipped = make(map[string]string)
+	}
+	r.Skipped[plugin] = err.Error()
+}
+
+func showLintReport(out ui.Printer, report lintReport) {
+	out.Printf("Linting profile: %s\n", report.Profile)
+
+	if len(report.Conflicts) > 0 {
+		out.Println()
+		out.Println("━━━ Conflicts ━━━")
+		for _, c := range report.Conflicts {
+			out.Printf("  ✗ %s: %s\n", conflictLabel(c), strings.Join(c.Sources, ", "))
+		}
+	}
+
+	if len(report.Skipped) > 0 {
+		out.Println()
+		for _, plugin := range slices.Sorted(maps.Keys(report.Skipped)) {
+			out.Printf("  ⚠ Couldn't inspect %s: %s\n", plugin, report.Skipped[plugin])
+		}
+	}
+
+	out.Println()
+	if len(report.Conflicts) > 0 {
+		out.Printf("✗ %d conflicts among %d plugins\n", len(report.Conflicts), report.Plugins)
+		return
+	}
+	out.Printf("✓ No conflicts among %d plugins\n", report.Plugins)
+}
+
+func conflictLabel(c audit.Conflict) string {
+	switch c.Kind {
+	case audit.KindCommand:
+		return "command /" + c.Name
+	case audit.KindAgent:
+		return "agent " + c.Name
+	case audit.KindMCP:
+		return "MCP server " + c.Name
+	}
+	return c.Kind + " " + c.Name
+}
@@ -0,0 +1,59 @@
+// ABOUTME: Tests for 'claudeup lint'
+// ABOUTME: Lints a profile against a fake Claude directory with installed and cloned plugins
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+func writeLintFile(t *testing.T, path, content string) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLintProfile(t *testing.T) {
+	dir := t.TempDir()
+	clone := filepath.Join(dir, "plugins", "marketplaces", "m")
+	installed := filepath.Join(dir, "plugins", "cache", "m", "review", "1.0.0")
+
+	writeLintFile(t, filepath.Join(dir, "plugins", "known_marketplaces.json"),
+		`{"m": {"source": {"source": "github", "repo": "o/m"}, "installLocation": "`+clone+`"}}`)
+	writeLintFile(t, filepath.Join(dir, "plugins", "installed_plugins.json"),
+		`{"version": 2, "plugins": {"review@m": [{"scope": "user", "version": "1.0.0", "installPath": "`+installed+`"}]}}`)
+	writeLintFile(t, filepath.Join(installed, "commands", "review.md"), "Review.\n")
+	writeLintFile(t, filepath.Join(clone, "plugins", "pr", "commands", "review.md"), "Review a PR.\n")
+	writeLintFile(t, filepath.Join(clone, "plugins", "pr", ".mcp.json"), `{"mcpServers": {"github": {"command": "gh"}}}`)
+	writeLintFile(t, filepath.Join(clone, "plugins", "off", "commands", "review.md"), "Disabled.\n")
+	writeLintFile(t, filepath.Join(dir, "commands", "deploy.md"), "Deploy.\n")
+
+	p := &profile.Profile{
+		Name:       "team",
+		Plugins:    []string{"review@m", "pr@m", "off@m", "gone@nowhere"},
+		MCPServers: []profile.MCPServer{{Name: "github", Command: "npx"}},
+		Disabled:   profile.DisabledConfig{Plugins: []string{"off@m"}},
+	}
+	report := lintProfile(p, dir)
+
+	if report.Plugins != 2 {
+		t.Errorf("Expected 2 plugins inspected, got %d", report.Plugins)
+	}
+	if _, ok := report.Skipped["gone@nowhere"]; !ok || len(report.Skipped) != 1 {
+		t.Errorf("Expected the unknown marketplace's plugin skipped, got %v", report.Skipped)
+	}
+	if len(report.Conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %+v", report.Conflicts)
+	}
+	if c := report.Conflicts[0]; c.Name != "review" || len(c.Sources) != 2 || c.Sources[0] != "review@m" || c.Sources[1] != "pr@m" {
+		t.Errorf("Expected review from the installed and cloned plugins, got %+v", c)
+	}
+	if c := report.Conflicts[1]; c.Name != "github" || c.Sources[0] != "profile team" || c.Sources[1] != "pr@m" {
+		t.Errorf("Expected github from the profile and a plugin, got %+v", c)
+	}
+}
@@ -0,0 +1,313 @@
+// ABOUTME: marketplace gc converts marketplace clones to the configured clone strategy
+// ABOUTME: Re-clones shallow, blobless, or sparse, keeps the checked-out commit, and runs git gc
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/marketplace"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var marketplaceGCStrategy string
+
+var marketplaceGCCmd = &cobra.Command{
+	Use:   "gc [name...]",
+	Short: "Convert marketplace clones to the clone strategy and reclaim space",
+	Long: `Converts marketplace clones to the clone strategy set by the cloneStrategy
+preference in ~/.claudeup/config.json, or by --strategy:
+
+  full      every commit and file (the default)
+  blobless  every commit; file contents are downloaded when needed
+  sparse    blobless, and only the plugins named in saved profiles or
+            installed are checked out
+  shallow   only the latest commit; update can't show plugin changelogs
+
+A clone that doesn't match is cloned again from its remote with the new
+strategy, at the same commit, and swapped into place. Clones with local
+changes are left alone. Every clone then gets a git gc. Without names, all
+marketplaces are processed.
+
+'claudeup update' keeps converted clones within their strategy.`,
+	Example: `  claudeup marketplace gc
+  claudeup marketplace gc --strategy sparse
+  claudeup marketplace gc acme-tools --strategy full`,
+	RunE: runMarketplaceGC,
+}
+
+func init() {
+	marketplaceCmd.AddCommand(marketplaceGCCmd)
+	marketplaceGCCmd.Flags().StringVar(&marketplaceGCStrategy, "strategy", "", "Clone strategy: full, blobless, sparse, or shallow (default: the cloneStrategy preference)")
+}
+
+func runMarketplaceGC(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	ctx := cmd.Context()
+
+	strategy := marketplaceGCStrategy
+	if strategy == "" {
+		cfg, err := config.LoadExisting()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		strategy = cfg.Preferences.CloneStrategy
+	}
+	strategy, err := marketplace.ParseStrategy(strategy)
+	if err != nil {
+		return err
+	}
+
+	registry, err := claude.LoadMarketplaces(claudeDir)
+	if err != nil {
+		return fmt.Errorf("failed to load marketplaces: %w", err)
+	}
+	names := args
+	if len(names) == 0 {
+		for name := range registry {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	type clone struct {
+		name, dir, current string
+	}
+	var clones []clone
+	converting := 0
+	out.Printf("━━━ Marketplace Clones (%s) ━━━\n", strategy)
+	for _, name := range names {
+		meta, ok := registry[name]
+		if !ok {
+			return fmt.Errorf("marketplace %q is not installed", name)
+		}
+		dir := meta.InstallLocation
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+			out.Printf("  - %s: not a git clone, skipped\n", name)
+			continue
+		}
+		c := clone{name: name, dir: dir, current: marketplace.DetectStrategy(dir)}
+		if c.current == strategy {
+			out.Printf("  ✓ %s: %s\n", name, c.current)
+		} else {
+			out.Printf("  → %s: %s → %s\n", name, c.current, strategy)
+			converting++
+		}
+		clones = append(clones, c)
+	}
+	if len(clones) == 0 {
+		return nil
+	}
+	out.Println()
+	if converting > 0 && !confirmProceed(out) {
+		out.Println("Cancelled.")
+		return nil
+	}
+
+	failed := 0
+	for _, c := range clones {
+		if ctx.Err() != nil {
+			break
+		}
+		before := statPath(PathInfo{Path: c.dir, Dir: true}).Size
+		if err := gcMarketplace(ctx, out, c.name, c.dir, c.current, strategy); err != nil {
+			out.Printf("  ✗ %s: %v\n", c.name, err)
+			failed++
+			continue
+		}
+		after := statPath(PathInfo{Path: c.dir, Dir: true}).Size
+		out.Printf("  ✓ %s: %s → %s\n", c.name, formatSize(int(before)), formatSize(int(after)))
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("interrupted: %w", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d marketplaces failed", failed, len(clones))
+	}
+	return nil
+}
+
+// gcMarketplace brings one clone to strategy and compacts it
+func gcMarketplace(ctx context.Context, out ui.Printer, name, dir, current, strategy string) error {
+	var sparse []string
+	if strategy == marketplace.StrategySparse {
+		plugins, err := marketplacePlugins(name)
+		if err != nil {
+			return err
+		}
+		sparse = marketplace.SparseDirs(dir, plugins)
+	}
+
+	if current != strategy {
+		sameCommit, err := recloneMarketplace(ctx, dir, strategy, sparse)
+		if err != nil {
+			return err
+		}
+		if !sameCommit {
+			out.Printf("  ⚠ %s: the remote no longer has the checked-out commit; now at its latest\n", name)
+			out.Println("    → Run 'claudeup update' to bring installed plugins in line")
+		}
+	} else if strategy == marketplace.StrategySparse {
+		if err := setSparseCheckout(ctx, dir, sparse); err != nil {
+			return err
+		}
+	}
+
+	if _, err := gitOutput(ctx, dir, "gc", "--prune=now", "--quiet"); err != nil {
+		return fmt.Errorf("git gc failed: %w", err)
+	}
+	return nil
+}
+
+// recloneMarketplace replaces the clone at dir with a fresh clone made with
+// strategy and moves it to the commit dir had checked out. sameCommit is
+// false when the remote no longer has that commit.
+func recloneMarketplace(ctx context.Context, dir, strategy string, sparse []string) (sameCommit bool, err error) {
+	status, err := gitOutput(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("failed to read the clone's status: %w", err)
+	}
+	if status != "" {
+		return false, fmt.Errorf("%s has local changes; commit or discard them first", dir)
+	}
+	url, err := gitOutput(ctx, dir, "remote", "get-url", "origin")
+	if err != nil {
+		return false, fmt.Errorf("failed to read the clone's remote: %w", err)
+	}
+	head, err := gitOutput(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return false, fmt.Errorf("failed to read the checked-out commit: %w", err)
+	}
+	branch, _ := gitOutput(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD")
+
+	staging := dir + ".claudeup-new"
+	os.RemoveAll(staging)
+	args := append([]string{"clone"}, marketplace.CloneArgs(strategy)...)
+	if branch != "" && branch != "HEAD" {
+		args = append(args, "--branch", branch)
+	}
+	args = append(args, url, staging)
+	if err := runGit(ctx, remoteGit(ctx, filepath.Dir(dir), args...)); err != nil {
+		os.RemoveAll(staging)
+		return false, fmt.Errorf("git clone failed: %w", err)
+	}
+
+	if strategy == marketplace.StrategySparse {
+		if err := setSparseCheckout(ctx, staging, sparse); err != nil {
+			os.RemoveAll(staging)
+			return false, err
+		}
+	}
+	sameCommit = checkoutCommit(ctx, staging, strategy, head)
+
+	if err := swapDir(staging, dir); err != nil {
+		return false, err
+	}
+	return sameCommit, nil
+}
+
+// checkoutCommit moves a fresh clone's branch to commit, fetching it first
+// when the clone is too shallow to have it
+func checkoutCommit(ctx context.Context, dir, strategy, commit string) bool {
+	if current, _ := gitOutput(ctx, dir, "rev-parse", "HEAD"); current == commit {
+		return true
+	}
+	if strategy == marketplace.StrategyShallow {
+		if err := runGit(ctx, remoteGit(ctx, dir, "fetch", "--depth", "1", "origin", commit)); err != nil {
+			return false
+		}
+	}
+	_, err := gitOutput(ctx, dir, "reset", "--hard", "--quiet", commit)
+	return err == nil
+}
+
+// setSparseCheckout limits the clone at dir to dirs, or checks out
+// everything when dirs is nil
+func setSparseCheckout(ctx context.Context, dir string, dirs []string) error {
+	args := []string{"sparse-checkout", "disable"}
+	if dirs != nil {
+		args = append([]string{"sparse-checkout", "set", "--cone"}, dirs...)
+	}
+	if _, err := gitOutput(ctx, dir, args...); err != nil {
+		return fmt.Errorf("failed to update the sparse checkout: %w", err)
+	}
+	return nil
+}
+
+// marketplacePlugins names the plugins from marketplace that a sparse clone
+// must keep: those in saved profiles and those installed
+func marketplacePlugins(marketplaceName string) ([]string, error) {
+	seen := map[string]bool{}
+	add := func(ref string) {
+		name, market, ok := strings.Cut(ref, "@")
+		if ok && market == marketplaceName {
+			seen[name] = true
+		}
+	}
+
+	profiles, err := profile.List(getProfilesDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	for _, p := range profiles {
+		for _, ref := range p.Plugins {
+			add(ref)
+		}
+	}
+	if plugins, err := state.LoadPlugins(claudeDir); err == nil {
+		for ref := range plugins.GetAllPlugins() {
+			add(ref)
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// widenSparseClones adds plugins about to be installed to the sparse
+// checkout of their marketplace, so the install finds their files
+func widenSparseClones(ctx context.Context, out ui.Printer, plugins []string) {
+	byMarketplace := map[string][]string{}
+	for _, ref := range plugins {
+		if name, market, ok := strings.Cut(ref, "@"); ok {
+			byMarketplace[market] = append(byMarketplace[market], name)
+		}
+	}
+	if len(byMarketplace) == 0 {
+		return
+	}
+	registry, err := claude.LoadMarketplaces(claudeDir)
+	if err != nil {
+		return
+	}
+	for market, names := range byMarketplace {
+		meta, ok := registry[market]
+		if !ok || marketplace.DetectStrategy(meta.InstallLocation) != marketplace.StrategySparse {
+			continue
+		}
+		dirs := marketplace.SparseDirs(meta.InstallLocation, names)
+		args := append([]string{"sparse-checkout", "add"}, dirs...)
+		if dirs == nil {
+			args = []string{"sparse-checkout", "disable"}
+		}
+		if _, err := gitOutput(ctx, meta.InstallLocation, args...); err != nil {
+			out.Printf("  ⚠ Could not check out %s plugins from the sparse clone: %v\n", market, err)
+		}
+	}
+}
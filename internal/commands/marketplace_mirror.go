@@ -0,0 +1,155 @@
+// ABOUTME: marketplace mirror pushes a marketplace clone to an internal git host
+// ABOUTME: Then points known_marketplaces.json and saved profiles at the mirror
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var marketplaceMirrorTo string
+
+var marketplaceMirrorCmd = &cobra.Command{
+	Use:   "mirror <name> --to <git-url>",
+	Short: "Push a marketplace to an internal mirror and switch to it",
+	Long: `Pushes the installed marketplace's current branch and tags to the git URL
+given with --to, then points the marketplace and every saved profile that uses
+it at the mirror. Later updates pull from the mirror.
+
+To redirect whole organizations without mirroring each marketplace by hand,
+add URL rewrites to ~/.claudeup/config.json instead:
+
+  "urlRewrites": {"github.com/acme": "git.corp/mirror/acme"}`,
+	Example: `  claudeup marketplace mirror acme-tools --to https://git.corp/mirror/acme/tools.git`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    runMarketplaceMirror,
+}
+
+func init() {
+	marketplaceCmd.AddCommand(marketplaceMirrorCmd)
+	marketplaceMirrorCmd.Flags().StringVar(&marketplaceMirrorTo, "to", "", "Git URL of the mirror")
+	marketplaceMirrorCmd.MarkFlagRequired("to")
+}
+
+func runMarketplaceMirror(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	name := args[0]
+
+	registry, err := claude.LoadMarketplaces(claudeDir)
+	if err != nil {
+		return fmt.Errorf("failed to load marketplaces: %w", err)
+	}
+	meta, ok := registry[name]
+	if !ok {
+		names := make([]string, 0, len(registry))
+		for n := range registry {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("marketplace %q is not installed (installed: %s)", name, strings.Join(names, ", "))
+	}
+
+	oldSource := meta.Source.Key()
+	profiles, err := profilesUsingMarketplace(oldSource)
+	if err != nil {
+		return err
+	}
+
+	out.Printf("Mirroring %s\n", name)
+	out.Printf("  From: %s\n", oldSource)
+	out.Printf("  To:   %s\n", marketplaceMirrorTo)
+	for _, p := range profiles {
+		out.Printf("  → Profile %s will use the mirror\n", p.Name)
+	}
+	out.Println()
+	if !confirmProceed(out) {
+		out.Println("Cancelled.")
+		return nil
+	}
+
+	ctx := cmd.Context()
+	branch, err := gitOutput(ctx, meta.InstallLocation, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to read the current branch: %w", err)
+	}
+	if branch == "HEAD" {
+		return fmt.Errorf("%s is not on a branch; check one out before mirroring", meta.InstallLocation)
+	}
+	if _, err := gitOutput(ctx, meta.InstallLocation, "push", "--tags", marketplaceMirrorTo, "HEAD:refs/heads/"+branch); err != nil {
+		return fmt.Errorf("failed to push to mirror: %w", err)
+	}
+	out.Printf("✓ Pushed %s and tags to the mirror\n", branch)
+
+	if _, err := gitOutput(ctx, meta.InstallLocation, "remote", "set-url", "origin", marketplaceMirrorTo); err != nil {
+		return fmt.Errorf("failed to point the clone at the mirror: %w", err)
+	}
+	meta.Source.Source = "git"
+	meta.Source.Repo = ""
+	meta.Source.URL = marketplaceMirrorTo
+	registry[name] = meta
+	if err := claude.SaveMarketplaces(claudeDir, registry); err != nil {
+		return fmt.Errorf("failed to save marketplaces: %w", err)
+	}
+	out.Println("✓ Updated known_marketplaces.json")
+
+	mirror := profile.Marketplace{Source: "git", URL: marketplaceMirrorTo}
+	for _, p := range profiles {
+		for i, m := range p.Marketplaces {
+			if m.DisplayName() == oldSource {
+				p.Marketplaces[i] = mirror
+			}
+		}
+		if err := profile.Save(getProfilesDir(), p); err != nil {
+			out.Printf("  ⚠ Could not update profile %s: %v\n", p.Name, err)
+			continue
+		}
+		out.Printf("✓ Updated profile %s\n", p.Name)
+	}
+	return nil
+}
+
+// profilesUsingMarketplace returns the saved profiles that list source
+func profilesUsingMarketplace(source string) ([]*profile.Profile, error) {
+	all, err := profile.List(getProfilesDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	var using []*profile.Profile
+	for _, p := range all {
+		for _, m := range p.Marketplaces {
+			if m.DisplayName() == source {
+				using = append(using, p)
+				break
+			}
+		}
+	}
+	return using, nil
+}
+
+// gitOutput runs git in dir and returns its trimmed output, with stderr in
+// the error
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
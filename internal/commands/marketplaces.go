@@ -7,6 +7,7 @@ import (
 	"sort"
 
 	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +30,8 @@ func init() {
 }
 
 func runMarketplaceList(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+
 	// Load marketplaces
 	marketplaces, err := claude.LoadMarketplaces(claudeDir)
 	if err != nil {
@@ -43,18 +46,18 @@ func runMarketplaceList(cmd *cobra.Command, args []string) error {
 	sort.Strings(names)
 
 	// Print header
-	fmt.Printf("=== Installed Marketplaces (%d) ===\n\n", len(names))
+	out.Printf("=== Installed Marketplaces (%d) ===\n\n", len(names))
 
 	// Print each marketplace
 	for _, name := range names {
 		marketplace := marketplaces[name]
 
-		fmt.Printf("✓ %s\n", name)
-		fmt.Printf("   Source:     %s\n", marketplace.Source.Source)
-		fmt.Printf("   Repo:       %s\n", marketplace.Source.Repo)
-		fmt.Printf("   Location:   %s\n", marketplace.InstallLocation)
-		fmt.Printf("   Updated:    %s\n", marketplace.LastUpdated)
-		fmt.Println()
+		out.Printf("✓ %s\n", name)
+		out.Printf("   Source:     %s\n", marketplace.Source.Source)
+		out.Printf("   Repo:       %s\n", marketplace.Source.Repo)
+		out.Printf("   Location:   %s\n", marketplace.InstallLocation)
+		out.Printf("   Updated:    %s\n", marketplace.LastUpdated)
+		out.Println()
 	}
 
 	return nil
@@ -9,6 +9,7 @@ import (
 	"github.com/claudeup/claudeup/internal/claude"
 	"github.com/claudeup/claudeup/internal/config"
 	"github.com/claudeup/claudeup/internal/mcp"
+	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -61,6 +62,8 @@ func init() {
 }
 
 func runMCPList(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+
 	// Load plugins
 	plugins, err := claude.LoadPlugins(claudeDir)
 	if err != nil {
@@ -74,7 +77,7 @@ func runMCPList(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(mcpServers) == 0 {
-		fmt.Println("No MCP servers found in installed plugins.")
+		out.Println("No MCP servers found in installed plugins.")
 		return nil
 	}
 
@@ -84,7 +87,7 @@ func runMCPList(cmd *cobra.Command, args []string) error {
 	})
 
 	// Print header
-	fmt.Println("=== MCP Servers by Plugin ===")
+	out.Println("=== MCP Servers by Plugin ===")
 
 	// Count total servers
 	totalServers := 0
@@ -94,7 +97,7 @@ func runMCPList(cmd *cobra.Command, args []string) error {
 
 	// Print each plugin's MCP servers
 	for _, pluginServers := range mcpServers {
-		fmt.Printf("✓ %s\n", pluginServers.PluginName)
+		out.Printf("✓ %s\n", pluginServers.PluginName)
 
 		// Sort server names
 		serverNames := make([]string, 0, len(pluginServers.Servers))
@@ -106,24 +109,28 @@ func runMCPList(cmd *cobra.Command, args []string) error {
 		// Print each server
 		for _, serverName := range serverNames {
 			server := pluginServers.Servers[serverName]
-			fmt.Printf("   ✓ %s\n", serverName)
-			fmt.Printf("      Command: %s\n", server.Command)
+			out.Printf("   ✓ %s\n", serverName)
+			out.Printf("      Command: %s\n", server.Command)
 			if len(server.Args) > 0 {
-				fmt.Printf("      Args:    %v\n", server.Args)
+				out.Printf("      Args:    %v\n", server.Args)
 			}
 			if len(server.Env) > 0 {
-				fmt.Printf("      Env:     %d variables\n", len(server.Env))
+				out.Printf("      Env:     %d variables\n", len(server.Env))
 			}
 		}
-		fmt.Println()
+		out.Println()
 	}
 
-	fmt.Printf("Total: %d MCP servers from %d plugins\n", totalServers, len(mcpServers))
+	out.Printf("Total: %d MCP servers from %d plugins\n", totalServers, len(mcpServers))
 
 	return nil
 }
 
 func runMCPDisable(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
 	serverRef := args[0]
 
 	// Load config
@@ -134,7 +141,7 @@ func runMCPDisable(cmd *cobra.Command, args []string) error {
 
 	// Check if already disabled
 	if cfg.IsMCPServerDisabled(serverRef) {
-		fmt.Printf("✓ MCP server %s is already disabled\n", serverRef)
+		out.Printf("✓ MCP server %s is already disabled\n", serverRef)
 		return nil
 	}
 
@@ -146,15 +153,19 @@ func runMCPDisable(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	fmt.Printf("✓ Disabled MCP server %s\n\n", serverRef)
-	fmt.Println("This MCP server will no longer be loaded")
-	fmt.Printf("Run 'claudeup mcp enable %s' to re-enable\n", serverRef)
-	fmt.Println("\nNote: You may need to restart Claude Code for changes to take effect")
+	out.Printf("✓ Disabled MCP server %s\n\n", serverRef)
+	out.Println("This MCP server will no longer be loaded")
+	out.Printf("Run 'claudeup mcp enable %s' to re-enable\n", serverRef)
+	out.Println("\nNote: You may need to restart Claude Code for changes to take effect")
 
 	return nil
 }
 
 func runMCPEnable(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
 	serverRef := args[0]
 
 	// Load config
@@ -165,7 +176,7 @@ func runMCPEnable(cmd *cobra.Command, args []string) error {
 
 	// Check if it's disabled
 	if !cfg.IsMCPServerDisabled(serverRef) {
-		fmt.Printf("✓ MCP server %s is already enabled\n", serverRef)
+		out.Printf("✓ MCP server %s is already enabled\n", serverRef)
 		return nil
 	}
 
@@ -177,10 +188,10 @@ func runMCPEnable(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	fmt.Printf("✓ Enabled MCP server %s\n\n", serverRef)
-	fmt.Println("This MCP server will now be loaded")
-	fmt.Printf("Run 'claudeup mcp disable %s' to disable again\n", serverRef)
-	fmt.Println("\nNote: You may need to restart Claude Code for changes to take effect")
+	out.Printf("✓ Enabled MCP server %s\n\n", serverRef)
+	out.Println("This MCP server will now be loaded")
+	out.Printf("Run 'claudeup mcp disable %s' to disable again\n", serverRef)
+	out.Println("\nNote: You may need to restart Claude Code for changes to take effect")
 
 	return nil
 }
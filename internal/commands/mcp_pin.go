@@ -0,0 +1,153 @@
+// ABOUTME: mcp pin records exact npm versions and integrity hashes for npx MCP servers
+// ABOUTME: So 'profile use' installs the same package on every machine
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mcpPinUpdate bool
+	mcpPinFile   string
+)
+
+var mcpPinCmd = &cobra.Command{
+	Use:   "pin [profile]",
+	Short: "Pin npx MCP servers to exact npm versions",
+	Long: `Looks up the version each npx-launched MCP server in the profile currently
+resolves to and records it in the profile as package, version, and integrity.
+'profile use' then installs exactly that version and refuses to install it if
+the registry's integrity hash no longer matches. Defaults to the active profile.
+
+Servers that are already pinned are left alone unless --update is given, which
+re-resolves the version range in their args.
+
+--file pins the profile at a path instead of a saved one, such as the
+.claudeup.json a repository commits and 'claudeup ci check' validates.`,
+	Example: `  claudeup mcp pin backend
+  claudeup mcp pin --file .claudeup.json --update`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMCPPin,
+}
+
+func init() {
+	mcpCmd.AddCommand(mcpPinCmd)
+	mcpPinCmd.Flags().BoolVar(&mcpPinUpdate, "update", false, "Re-pin servers that are already pinned")
+	mcpPinCmd.Flags().StringVar(&mcpPinFile, "file", "", "Pin the profile at this path instead of a saved one")
+}
+
+func runMCPPin(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	p, name, save, err := profileToPin(args)
+	if err != nil {
+		return err
+	}
+
+	npx, changed, failed := 0, 0, 0
+	for i, s := range p.MCPServers {
+		if s.Version != "" && !mcpPinUpdate {
+			npx++
+			out.Printf("  • %s: pinned to %s@%s\n", s.Name, s.Package, s.Version)
+			continue
+		}
+		unpinned := s
+		unpinned.Package, unpinned.Version, unpinned.Integrity = "", "", ""
+		packages := unpinned.NpmPackages()
+		if len(packages) != 1 {
+			continue
+		}
+		npx++
+
+		pkg, _ := profile.SplitPackageSpec(packages[0])
+		pin, err := profile.ResolveNpmPin(cmd.Context(), packages[0])
+		if err != nil {
+			failed++
+			out.Printf("  ✗ %s: %v\n", s.Name, err)
+			continue
+		}
+		if s.Package == pkg && s.Version == pin.Version && s.Integrity == pin.Integrity {
+			out.Printf("  • %s: pinned to %s@%s\n", s.Name, pkg, pin.Version)
+			continue
+		}
+		p.MCPServers[i].Package = pkg
+		p.MCPServers[i].Version = pin.Version
+		p.MCPServers[i].Integrity = pin.Integrity
+		changed++
+		out.Printf("  ✓ %s: %s@%s\n", s.Name, pkg, pin.Version)
+	}
+
+	if changed > 0 {
+		if err := save(p); err != nil {
+			return fmt.Errorf("failed to save profile: %w", err)
+		}
+		out.Printf("\n✓ Pinned %d MCP servers in %s\n", changed, name)
+	} else if npx == 0 {
+		out.Println("No npx MCP servers to pin.")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d MCP servers could not be pinned", failed)
+	}
+	return nil
+}
+
+// profileToPin loads the profile named in args, the active profile, or the
+// one at --file, with its name for messages and how to save it again
+func profileToPin(args []string) (*profile.Profile, string, func(*profile.Profile) error, error) {
+	if mcpPinFile != "" {
+		if len(args) > 0 {
+			return nil, "", nil, fmt.Errorf("--file can't be used with a profile name")
+		}
+		data, err := os.ReadFile(mcpPinFile)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		p, err := profile.Read(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("invalid profile in %s: %w", mcpPinFile, err)
+		}
+		save := func(p *profile.Profile) error {
+			data, err := json.MarshalIndent(p, "", "  ")
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(mcpPinFile, append(data, '\n'), 0644)
+		}
+		return p, mcpPinFile, save, nil
+	}
+
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		cfg, err := config.Load()
+		if err == nil {
+			name = cfg.Preferences.ActiveProfile
+		}
+		if name == "" {
+			return nil, "", nil, fmt.Errorf("no active profile; pass a profile name or run 'claudeup profile use <name>'")
+		}
+	}
+
+	profilesDir := getProfilesDir()
+	name, err := resolveProfileName(profilesDir, name)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	p, err := profile.Load(profilesDir, name)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("profile %q not found in %s (save built-in profiles before pinning): %w", name, profilesDir, err)
+	}
+	save := func(p *profile.Profile) error { return profile.Save(profilesDir, p) }
+	return p, name, save, nil
+}
@@ -0,0 +1,175 @@
+// ABOUTME: mcp test starts one configured MCP server, runs the initialize handshake, and reports what it offers
+// ABOUTME: Finds the server in a profile, the project's .mcp.json, ~/.claude.json, or an installed plugin
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/mcp"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mcpTestProfile string
+	mcpTestTimeout time.Duration
+	mcpTestJSON    bool
+)
+
+var mcpTestCmd = &cobra.Command{
+	Use:   "test <server>",
+	Short: "Start an MCP server and show what it offers",
+	Long: `Starts an MCP server the way Claude Code would, performs the initialize
+handshake, lists its tools, resources, and prompts, and stops it - for
+debugging a server Claude doesn't see without starting Claude.
+
+The server is looked up like Claude Code does: the project's .mcp.json,
+then ~/.claude.json, then installed plugins. Name a plugin's server as
+<plugin>:<server> when several plugins ship one with the same name. With
+--profile, the server comes from that profile instead, with its secrets
+resolved.`,
+	Example: `  claudeup mcp test context7
+  claudeup mcp test superpowers-chrome@superpowers-marketplace:chrome
+  claudeup mcp test github --profile work --timeout 1m`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMCPTest,
+}
+
+func init() {
+	mcpCmd.AddCommand(mcpTestCmd)
+	mcpTestCmd.Flags().StringVar(&mcpTestProfile, "profile", "", "Test the server as defined in this profile")
+	mcpTestCmd.Flags().DurationVar(&mcpTestTimeout, "timeout", 30*time.Second, "How long the server has to start and answer")
+	mcpTestCmd.Flags().BoolVar(&mcpTestJSON, "json", false, "Print the report as JSON")
+}
+
+func runMCPTest(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	name := args[0]
+	launch, source, shown, err := findMCPLaunch(cmd, name)
+	if err != nil {
+		return err
+	}
+
+	if !mcpTestJSON {
+		out.Printf("Testing %s from %s\n", name, source)
+		out.Printf("  → %s\n", shown)
+		out.Println()
+	}
+	report, err := mcp.Handshake(cmd.Context(), launch, mcpTestTimeout)
+	if err != nil {
+		return fmt.Errorf("MCP server %s failed: %w", name, err)
+	}
+	if mcpTestJSON {
+		return printJSON(report)
+	}
+
+	server := strings.TrimSpace(report.ServerName + " " + report.ServerVersion)
+	if server == "" {
+		server = name
+	}
+	out.Printf("✓ %s answered in %s (protocol %s)\n", server, formatStepDuration(report.Elapsed), report.ProtocolVersion)
+	if len(report.Capabilities) > 0 {
+		out.Printf("  Capabilities: %s\n", strings.Join(report.Capabilities, ", "))
+	} else {
+		out.Println("  Capabilities: none")
+	}
+	if report.Instructions != "" {
+		out.Printf("  Instructions: %s\n", firstLine(report.Instructions))
+	}
+
+	var tools, resources, prompts [][2]string
+	for _, t := range report.Tools {
+		tools = append(tools, [2]string{t.Name, t.Description})
+	}
+	for _, r := range report.Resources {
+		resources = append(resources, [2]string{r.URI, r.Name})
+	}
+	for _, p := range report.Prompts {
+		prompts = append(prompts, [2]string{p.Name, p.Description})
+	}
+	showMCPOffers(out, report, "tools", "Tools", tools)
+	showMCPOffers(out, report, "resources", "Resources", resources)
+	showMCPOffers(out, report, "prompts", "Prompts", prompts)
+	return nil
+}
+
+// findMCPLaunch finds how to start the named server, where it's defined,
+// and its command line as shown to the user, without resolved secrets
+func findMCPLaunch(cmd *cobra.Command, name string) (mcp.Launch, string, string, error) {
+	cwd, _ := os.Getwd()
+	if mcpTestProfile != "" {
+		p, err := loadProfileWithFallback(getProfilesDir(), mcpTestProfile)
+		if err != nil {
+			return mcp.Launch{}, "", "", fmt.Errorf("profile %q not found: %w", mcpTestProfile, err)
+		}
+		for _, m := range p.MCPServers {
+			if m.Name != name {
+				continue
+			}
+			args, env, err := profile.MCPLaunch(cmd.Context(), m, buildSecretChain())
+			if err != nil {
+				return mcp.Launch{}, "", "", err
+			}
+			launch := mcp.Launch{Command: m.Command, Args: args, Env: env, Dir: cwd}
+			return launch, "profile " + p.Name, strings.Join(append([]string{m.Command}, m.PinnedArgs()...), " "), nil
+		}
+		return mcp.Launch{}, "", "", fmt.Errorf("profile %s has no MCP server %q", p.Name, name)
+	}
+
+	plugins, err := claude.LoadPlugins(claudeDir)
+	if err != nil {
+		return mcp.Launch{}, "", "", fmt.Errorf("failed to load plugins: %w", err)
+	}
+	defs, err := mcp.CollectDefinitions(plugins, profile.DefaultClaudeJSONPath(), cwd, nil)
+	if err != nil {
+		return mcp.Launch{}, "", "", err
+	}
+	def, err := mcp.Lookup(defs, name)
+	if err != nil {
+		return mcp.Launch{}, "", "", err
+	}
+	return def.Launch(cwd), def.Location(), strings.Join(append([]string{def.Server.Command}, def.Server.Args...), " "), nil
+}
+
+// showMCPOffers lists one kind of item, if the server announced the
+// capability for it
+func showMCPOffers(out ui.Printer, report *mcp.Report, capability, title string, items [][2]string) {
+	announced := false
+	for _, c := range report.Capabilities {
+		announced = announced || c == capability
+	}
+	if !announced {
+		return
+	}
+	out.Println()
+	out.Printf("━━━ %s ━━━\n", title)
+	if len(items) == 0 {
+		out.Println("  (none)")
+		return
+	}
+	width := 0
+	for _, item := range items {
+		width = max(width, len(item[0]))
+	}
+	for _, item := range items {
+		if detail := firstLine(item[1]); detail != "" {
+			out.Printf("  %-*s  %s\n", width, item[0], detail)
+		} else {
+			out.Printf("  %s\n", item[0])
+		}
+	}
+}
+
+// firstLine returns the first line of s, cut to fit a terminal row
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	if r := []rune(line); len(r) > 80 {
+		return string(r[:79]) + "…"
+	}
+	return line
+}
@@ -0,0 +1,47 @@
+// ABOUTME: Tests for mcp test
+// ABOUTME: Checks servers from a profile start with their secrets resolved but not shown
+package commands
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+func TestFindMCPLaunchFromProfile(t *testing.T) {
+	paths := pathctx.ForHome(t.TempDir())
+	defer pathctx.Override(paths)()
+	defer func() { mcpTestProfile = "" }()
+	t.Setenv("MCP_PROBE_TOKEN", "s3cret")
+
+	p := &profile.Profile{Name: "work", MCPServers: []profile.MCPServer{{
+		Name:    "github",
+		Command: "github-mcp",
+		Args:    []string{"--token", "$GITHUB_TOKEN"},
+		Secrets: map[string]profile.SecretRef{"GITHUB_TOKEN": {Sources: []profile.SecretSource{{Type: "env", Key: "MCP_PROBE_TOKEN"}}}},
+	}}}
+	if err := profile.Save(paths.ProfilesDir, p); err != nil {
+		t.Fatal(err)
+	}
+
+	mcpTestProfile = "work"
+	mcpTestCmd.SetContext(context.Background())
+	launch, source, shown, err := findMCPLaunch(mcpTestCmd, "github")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(launch.Args, []string{"--token", "s3cret"}) || !reflect.DeepEqual(launch.Env, []string{"GITHUB_TOKEN=s3cret"}) {
+		t.Errorf("Expected the secret resolved for the server, got %+v", launch)
+	}
+	if source != "profile work" || strings.Contains(shown, "s3cret") || shown != "github-mcp --token $GITHUB_TOKEN" {
+		t.Errorf("Expected the unresolved command shown, got %q from %q", shown, source)
+	}
+
+	if _, _, _, err := findMCPLaunch(mcpTestCmd, "slack"); err == nil || !strings.Contains(err.Error(), "no MCP server") {
+		t.Errorf("Expected a server missing from the profile to fail, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+// ABOUTME: mcp-server command exposing claudeup to Claude Code as an MCP server
+// ABOUTME: Speaks JSON-RPC over stdio; apply_profile requires explicit confirmation
+package commands
+
+import (
+	"context"
+	"os"
+
+	"github.com/claudeup/claudeup/internal/mcpserver"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/claudeup/claudeup/pkg/claudeup"
+	"github.com/spf13/cobra"
+)
+
+var mcpServerCmd = &cobra.Command{
+	Use:   "mcp-server",
+	Short: "Run claudeup as an MCP server over stdio",
+	Long: `Runs an MCP server so Claude Code can inspect and manage its own configuration
+through claudeup.
+
+Tools:
+  list_profiles   List saved profiles
+  show_diff       Show what applying a profile would change
+  apply_profile   Apply a profile (only with confirm=true, after the user approves)
+  doctor_report   Run diagnostics and return a JSON report
+
+Register it with Claude Code:
+  claude mcp add claudeup -- claudeup mcp-server`,
+	Args: cobra.NoArgs,
+	RunE: runMCPServer,
+}
+
+func init() {
+	rootCmd.AddCommand(mcpServerCmd)
+}
+
+func runMCPServer(cmd *cobra.Command, args []string) error {
+	// stdout carries the protocol, so claude CLI output must be captured and
+	// anything claudeup reports goes to stderr
+	out := ui.NewPrinter(os.Stderr, os.Stderr, ui.PrinterFrom(cmd.Context()).Quiet())
+	client, err := claudeup.New(claudeup.Options{
+		ClaudeDir:      claudeDir,
+		ClaudeJSONPath: profile.DefaultClaudeJSONPath(),
+		ProfilesDir:    getProfilesDir(),
+		Executor:       claudeup.CapturingExecutor(),
+		Secrets:        buildSecretChain(),
+		ReadOnly:       readOnlyReason() != "",
+	})
+	if err != nil {
+		return err
+	}
+
+	srv := mcpserver.New(mcpserver.Options{
+		Client:  client,
+		Version: rootCmd.Version,
+		Doctor: func() (interface{}, error) {
+			return collectDoctorReport(cmd.Context(), claudeDir)
+		},
+		BeforeApply: func(ctx context.Context, name string, diff *claudeup.Diff) context.Context {
+			return applyStarting(ctx, out, "mcp", name, diff)
+		},
+		AfterApply: func(ctx context.Context, name string, diff *claudeup.Diff, result *claudeup.ApplyResult) {
+			recordApplyFrom(ctx, out, "mcp", name, diff, result, nil)
+			setActiveProfile(name)
+			if p, err := loadProfileWithFallback(getProfilesDir(), name); err == nil {
+				recordAppliedProfile(out, p)
+			}
+		},
+	})
+
+	return srv.Serve(cmd.Context(), os.Stdin, os.Stdout)
+}
@@ -0,0 +1,139 @@
+// ABOUTME: mcp warm pre-fetches the npm packages of a profile's npx MCP servers
+// ABOUTME: So their first launch in Claude Code doesn't wait on the network
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/cmdline"
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var mcpWarmCmd = &cobra.Command{
+	Use:   "warm [profile]",
+	Short: "Pre-fetch npm packages for a profile's MCP servers",
+	Long: `Downloads the npm package of every MCP server in the profile that is
+launched with npx, so the first launch in Claude Code is fast and works
+offline. Defaults to the active profile.
+
+Set "warmMcp": true in the preferences of ~/.claudeup/config.json to warm
+newly installed servers automatically at the end of 'profile use' and 'setup'.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMCPWarm,
+}
+
+func init() {
+	mcpCmd.AddCommand(mcpWarmCmd)
+}
+
+func runMCPWarm(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		cfg, err := config.Load()
+		if err == nil {
+			name = cfg.Preferences.ActiveProfile
+		}
+		if name == "" {
+			return fmt.Errorf("no active profile; pass a profile name or run 'claudeup profile use <name>'")
+		}
+	}
+
+	p, err := loadProfileWithAddons(getProfilesDir(), strings.Fields(name))
+	if err != nil {
+		return err
+	}
+	if failed := warmMCPServers(cmd.Context(), out, p.MCPServers); failed > 0 {
+		return fmt.Errorf("%d packages could not be fetched", failed)
+	}
+	return nil
+}
+
+// warmAfterApply warms the npx servers an apply installed when the warmMcp
+// preference is on. Failures are only reported; the apply already succeeded.
+func warmAfterApply(ctx context.Context, out ui.Printer, p *profile.Profile, result *profile.ApplyResult) {
+	cfg, err := config.LoadExisting()
+	if err != nil || !cfg.Preferences.WarmMCP || len(result.MCPServersInstalled) == 0 {
+		return
+	}
+	installed := make(map[string]bool)
+	for _, name := range result.MCPServersInstalled {
+		installed[name] = true
+	}
+	var servers []profile.MCPServer
+	for _, s := range p.MCPServers {
+		if installed[s.Name] {
+			servers = append(servers, s)
+		}
+	}
+	out.Println()
+	warmMCPServers(ctx, out, servers)
+}
+
+// warmMCPServers fetches each npx server's packages into the npx cache and
+// returns how many failed
+func warmMCPServers(ctx context.Context, out ui.Printer, servers []profile.MCPServer) int {
+	var packages []string
+	seen := make(map[string]bool)
+	for _, s := range servers {
+		for _, pkg := range s.NpmPackages() {
+			if !seen[pkg] {
+				seen[pkg] = true
+				packages = append(packages, pkg)
+			}
+		}
+	}
+	if len(packages) == 0 {
+		out.Println("No MCP servers are launched with npx; nothing to warm.")
+		return 0
+	}
+	if _, err := exec.LookPath("npx"); err != nil {
+		out.Println("⚠ npx not found; install Node.js to warm MCP server packages")
+		return len(packages)
+	}
+
+	out.Println("━━━ Warming npx cache ━━━")
+	failed := 0
+	for _, pkg := range packages {
+		if ctx.Err() != nil {
+			out.Println("⚠ Interrupted")
+			return failed + 1
+		}
+		start := time.Now()
+		// Running a no-op through the package installs it and its
+		// dependencies into the npx cache. node is wherever npx is, unlike
+		// true on Windows.
+		cmd, err := cmdline.Command(ctx, "npx", "--yes", "--package", pkg, "--", "node", "--version")
+		if err != nil {
+			failed++
+			out.Printf("  ✗ %s: %v\n", pkg, err)
+			continue
+		}
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			failed++
+			out.Printf("  ✗ %s: %s\n", pkg, lastLine(output, err))
+			continue
+		}
+		out.Printf("  ✓ %s (%s)\n", pkg, formatStepDuration(time.Since(start)))
+	}
+	return failed
+}
+
+// lastLine returns the last non-empty line of output, or err if there is none
+func lastLine(output []byte, err error) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return last
+	}
+	return err.Error()
+}
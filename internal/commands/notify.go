@@ -0,0 +1,208 @@
+// ABOUTME: notify command for testing notification settings, plus the shared notification and webhook helpers
+// ABOUTME: Configured in the "notifications" and "webhooks" sections of ~/.claudeup/config.json
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/notify"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/telemetry"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/claudeup/claudeup/internal/webhook"
+	"github.com/spf13/cobra"
+)
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage notifications for background problems",
+	Long: `claudeup can notify you when an apply fails or a scheduled check finds
+problems, so background runs don't go unnoticed.
+
+Configure notifications in ~/.claudeup/config.json:
+
+  "notifications": {
+    "desktop": true,
+    "webhookUrl": "https://hooks.slack.com/services/..."
+  }
+
+Desktop notifications use osascript on macOS and notify-send on Linux.
+The webhook receives a JSON payload that Slack incoming webhooks accept.
+
+For observability or compliance tooling, the "webhooks" list receives every
+apply as it starts, succeeds, or fails, and drift found by scheduled checks:
+
+  "webhooks": [{
+    "url": "https://events.example.com/claudeup",
+    "events": ["apply-failed", "drift"],
+    "headers": {"Authorization": "Bearer $EVENTS_TOKEN"},
+    "template": "{\"text\": {{json .Profile}}, \"failed\": {{len .Failed}}}"
+  }]
+
+Without a template the body is the event as JSON. 'notify test' also sends
+a test event to every webhook.
+
+To export every apply as an OpenTelemetry trace, with a span for each claude
+command, secret resolution, and webhook delivery, name an OTLP/HTTP collector:
+
+  "telemetry": {"endpoint": "http://otel-collector:4318"}
+
+The OTEL_EXPORTER_OTLP_* environment variables override it.`,
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test notification to every configured target",
+	Args:  cobra.NoArgs,
+	RunE:  runNotifyTest,
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+}
+
+func runNotifyTest(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	n := loadNotifier()
+	hooks := loadWebhooks()
+	if n == nil && len(hooks) == 0 {
+		out.Println("No notifications configured.")
+		out.Println("  → Add a \"notifications\" section to ~/.claudeup/config.json (see 'claudeup notify --help')")
+		return nil
+	}
+
+	if n != nil {
+		err := n.Notify(notify.Event{
+			Kind:    "test",
+			Level:   notify.LevelInfo,
+			Title:   "claudeup",
+			Message: "Test notification - notifications are working",
+		})
+		if err != nil {
+			return err
+		}
+		out.Println("✓ Test notification sent")
+	}
+
+	if len(hooks) > 0 {
+		for _, hook := range hooks {
+			if err := webhook.Validate(hook); err != nil {
+				return err
+			}
+		}
+		e := webhook.Event{Event: webhook.EventTest, Version: rootCmd.Version}
+		if cfg, err := config.LoadExisting(); err == nil {
+			e.Profile = cfg.Preferences.ActiveProfile
+		}
+		if err := webhook.Send(cmd.Context(), nil, hooks, e); err != nil {
+			return err
+		}
+		out.Printf("✓ Test event sent to %d webhooks\n", len(hooks))
+	}
+	return nil
+}
+
+// loadNotifier returns the configured notifier, or nil if none is configured
+func loadNotifier() notify.Notifier {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	return notify.New(cfg.Notifications)
+}
+
+// sendNotification delivers e if notifications are configured.
+// Best-effort: failures only print a warning.
+func sendNotification(out ui.Printer, e notify.Event) {
+	n := loadNotifier()
+	if n == nil {
+		return
+	}
+	if err := n.Notify(e); err != nil {
+		out.Printf("  ⚠ Could not send notification: %v\n", err)
+	}
+}
+
+// loadWebhooks returns the configured webhooks
+func loadWebhooks() []config.Webhook {
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		return nil
+	}
+	return cfg.Webhooks
+}
+
+// sendWebhook posts e to the webhooks subscribed to it.
+// Best-effort: failures only print a warning.
+func sendWebhook(ctx context.Context, out ui.Printer, e webhook.Event) {
+	hooks := loadWebhooks()
+	if len(hooks) == 0 {
+		return
+	}
+	e.Version = rootCmd.Version
+	if err := webhook.Send(ctx, nil, hooks, e); err != nil {
+		out.Warnf("  ⚠ Could not send webhook: %v\n", err)
+	}
+}
+
+// applyStarting announces an apply to the webhooks before any change is
+// made. When telemetry is configured, the returned context carries the
+// apply's trace; pass it to the apply and to recordApplyFrom.
+func applyStarting(ctx context.Context, out ui.Printer, source, name string, diff *profile.Diff) context.Context {
+	ctx, _ = telemetry.StartTrace(ctx, loadExporter(), "apply",
+		telemetry.String("profile", name),
+		telemetry.String("source", source),
+		telemetry.Int("changes", diff.Count()),
+		telemetry.Int("plugins.install", len(diff.PluginsToInstall)),
+		telemetry.Int("plugins.remove", len(diff.PluginsToRemove)),
+		telemetry.Int("mcp.install", len(diff.MCPToInstall)),
+		telemetry.Int("mcp.remove", len(diff.MCPToRemove)),
+		telemetry.Int("marketplaces.add", len(diff.MarketplacesToAdd)),
+	)
+	sendWebhook(ctx, out, webhook.Event{Event: webhook.EventApplyStart, Profile: name, Source: source, Changes: diff.Count()})
+	return ctx
+}
+
+// loadExporter returns the configured trace exporter, or nil if telemetry
+// isn't configured
+func loadExporter() *telemetry.Exporter {
+	var cfg config.Telemetry
+	if global, err := config.LoadExisting(); err == nil {
+		cfg = global.Telemetry
+	}
+	return telemetry.NewExporter(cfg, rootCmd.Version)
+}
+
+// exportTrace finishes the trace in ctx and sends it to the collector.
+// Best-effort: failures only print a warning.
+func exportTrace(ctx context.Context, out ui.Printer, err error) {
+	span := telemetry.SpanFrom(ctx)
+	span.End(err)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := span.Export(ctx); err != nil {
+		out.Warnf("  ⚠ Could not export trace: %v\n", err)
+	}
+}
+
+// reportDrift records drift from the active profile found by a scheduled
+// check, notifying and sending the drift webhook when there is any
+func reportDrift(ctx context.Context, out ui.Printer) {
+	cfg, err := config.LoadExisting()
+	if err != nil || cfg.Preferences.ActiveProfile == "" {
+		return
+	}
+	name := cfg.Preferences.ActiveProfile
+	drift := profileDrift(name)
+	if drift < 0 {
+		return
+	}
+	recordScheduledResult(out, "drift", drift, fmt.Sprintf("%d items changed since %s was applied", drift, name), nil)
+	if drift > 0 {
+		sendWebhook(ctx, out, webhook.Event{Event: webhook.EventDrift, Profile: name, Source: "schedule", Drift: drift})
+	}
+}
@@ -0,0 +1,237 @@
+// ABOUTME: override subcommands managing this machine's overrides in ~/.claudeup/overrides.json
+// ABOUTME: Overrides add mounts, env, and disabled plugins to every profile applied here, without changing the profiles
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/sandbox"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var overrideListFormat string
+
+// overrideKinds are what an override can be
+var overrideKinds = []string{"mount", "env", "disable"}
+
+var overrideCmd = &cobra.Command{
+	Use:   "override",
+	Short: "Manage this machine's additions to every profile",
+	Long: `Overrides are changes this machine makes to every profile it applies,
+kept in ~/.claudeup/overrides.json rather than in the profiles, so a
+shared or synced profile never picks them up:
+
+  mount    host:container[:ro]   mounted in the sandbox, replacing a
+                                 profile mount at the same container path
+  env      KEY=VALUE             set in the shell environment and the
+                                 sandbox, replacing the profile's value
+  disable  plugin@marketplace    disabled wherever a profile installs it
+
+They take effect the next time a profile is applied. 'profile save'
+leaves plugins disabled by an override out of the saved profile.`,
+}
+
+var overrideAddCmd = &cobra.Command{
+	Use:   "add <mount|env|disable> <value>",
+	Short: "Add a mount, env variable, or disabled plugin",
+	Example: `  claudeup override add mount ~/src/acme-sdk:/workspace/sdk:ro
+  claudeup override add env JAVA_HOME=/opt/homebrew/opt/openjdk@21
+  claudeup override add disable gpu-tools@acme`,
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: overrideKinds,
+	RunE:      runOverrideAdd,
+}
+
+var overrideRemoveCmd = &cobra.Command{
+	Use:   "remove <mount|env|disable> <value>",
+	Short: "Remove an override",
+	Long: `Removes an override. A mount is named by its container path, and an env
+variable by its name.`,
+	Example: `  claudeup override remove mount /workspace/sdk
+  claudeup override remove env JAVA_HOME
+  claudeup override remove disable gpu-tools@acme`,
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: overrideKinds,
+	RunE:      runOverrideRemove,
+}
+
+var overrideListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List this machine's overrides",
+	Args:  cobra.NoArgs,
+	RunE:  runOverrideList,
+}
+
+func init() {
+	rootCmd.AddCommand(overrideCmd)
+	overrideCmd.AddCommand(overrideAddCmd, overrideRemoveCmd, overrideListCmd)
+	overrideListCmd.Flags().StringVar(&overrideListFormat, "format", "", "Print the overrides as json or yaml")
+}
+
+func checkOverrideKind(kind string) error {
+	if !slices.Contains(overrideKinds, kind) {
+		return fmt.Errorf("unknown override %q (expected %s)", kind, strings.Join(overrideKinds, ", "))
+	}
+	return nil
+}
+
+func runOverrideAdd(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	kind, value := args[0], args[1]
+	if err := checkOverrideKind(kind); err != nil {
+		return err
+	}
+	o, err := profile.LoadOverrides()
+	if err != nil {
+		return err
+	}
+
+	var added string
+	switch kind {
+	case "mount":
+		m, err := sandbox.ParseMount(value)
+		if err != nil {
+			return err
+		}
+		o.Mounts = slices.DeleteFunc(o.Mounts, func(existing profile.SandboxMount) bool {
+			return existing.Container == m.Container
+		})
+		o.Mounts = append(o.Mounts, profile.SandboxMount{Host: m.Host, Container: m.Container, ReadOnly: m.ReadOnly})
+		added = "mount " + formatOverrideMount(o.Mounts[len(o.Mounts)-1])
+	case "env":
+		key, val, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid env override %q (expected KEY=VALUE)", value)
+		}
+		if o.Env == nil {
+			o.Env = make(map[string]string)
+		}
+		o.Env[key] = val
+		added = "env " + key
+	case "disable":
+		if !strings.Contains(value, "@") {
+			return fmt.Errorf("invalid plugin %q (expected name@marketplace)", value)
+		}
+		if slices.Contains(o.DisabledPlugins, value) {
+			out.Printf("✓ %s is already disabled on this machine\n", value)
+			return nil
+		}
+		o.DisabledPlugins = append(o.DisabledPlugins, value)
+		added = "disabled plugin " + value
+	}
+
+	if err := profile.SaveOverrides(o); err != nil {
+		return fmt.Errorf("failed to save overrides: %w", err)
+	}
+	out.Printf("✓ Added %s\n", added)
+	out.Println("  Takes effect the next time a profile is applied")
+	return nil
+}
+
+func runOverrideRemove(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	kind, value := args[0], args[1]
+	if err := checkOverrideKind(kind); err != nil {
+		return err
+	}
+	o, err := profile.LoadOverrides()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	switch kind {
+	case "mount":
+		// Accept the whole host:container spec as well as the container path
+		container := value
+		if m, err := sandbox.ParseMount(value); err == nil {
+			container = m.Container
+		}
+		before := len(o.Mounts)
+		o.Mounts = slices.DeleteFunc(o.Mounts, func(m profile.SandboxMount) bool { return m.Container == container })
+		found = len(o.Mounts) < before
+	case "env":
+		_, found = o.Env[value]
+		delete(o.Env, value)
+	case "disable":
+		before := len(o.DisabledPlugins)
+		o.DisabledPlugins = slices.DeleteFunc(o.DisabledPlugins, func(p string) bool { return p == value })
+		found = len(o.DisabledPlugins) < before
+	}
+	if !found {
+		return fmt.Errorf("no %s override for %s", kind, value)
+	}
+
+	if err := profile.SaveOverrides(o); err != nil {
+		return fmt.Errorf("failed to save overrides: %w", err)
+	}
+	out.Printf("✓ Removed %s override for %s\n", kind, value)
+	return nil
+}
+
+func runOverrideList(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if err := validateFormat("format", overrideListFormat); err != nil {
+		return err
+	}
+	o, err := profile.LoadOverrides()
+	if err != nil {
+		return err
+	}
+	if overrideListFormat != "" {
+		return printFormatted(overrideListFormat, o)
+	}
+
+	if o.IsZero() {
+		out.Println("No overrides on this machine.")
+		out.Println("Add one with: claudeup override add <mount|env|disable> <value>")
+		return nil
+	}
+	out.Printf("Overrides in %s\n", profile.OverridesPath())
+	if len(o.Mounts) > 0 {
+		out.Println()
+		out.Println("━━━ Mounts ━━━")
+		for _, m := range o.Mounts {
+			out.Printf("  %s\n", formatOverrideMount(m))
+		}
+	}
+	if len(o.Env) > 0 {
+		out.Println()
+		out.Println("━━━ Env ━━━")
+		keys := make([]string, 0, len(o.Env))
+		for k := range o.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out.Printf("  %s=%s\n", k, o.Env[k])
+		}
+	}
+	if len(o.DisabledPlugins) > 0 {
+		out.Println()
+		out.Println("━━━ Disabled Plugins ━━━")
+		for _, p := range o.DisabledPlugins {
+			out.Printf("  %s\n", p)
+		}
+	}
+	return nil
+}
+
+func formatOverrideMount(m profile.SandboxMount) string {
+	s := m.Host + " → " + m.Container
+	if m.ReadOnly {
+		s += " (read-only)"
+	}
+	return s
+}
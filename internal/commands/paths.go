@@ -0,0 +1,184 @@
+// ABOUTME: paths command listing every file and directory claudeup reads or writes
+// ABOUTME: Shows whether each exists and how big it is, for diagnosing broken installations
+package commands
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/history"
+	"github.com/claudeup/claudeup/internal/integrity"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/secrets"
+	"github.com/claudeup/claudeup/internal/snapshot"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var pathsJSON bool
+
+var pathsCmd = &cobra.Command{
+	Use:     "paths",
+	Aliases: []string{"where"},
+	Short:   "Show the files and directories claudeup uses",
+	Long: `Lists every directory and file claudeup reads or writes: Claude's own
+configuration, and claudeup's profiles, config, history, sandbox state, and
+caches. Each entry shows whether it exists and its size; directory sizes
+include everything inside.
+
+--claude-dir and CLAUDE_CONFIG_DIR are taken into account.`,
+	Args: cobra.NoArgs,
+	RunE: runPaths,
+}
+
+func init() {
+	rootCmd.AddCommand(pathsCmd)
+	pathsCmd.Flags().BoolVar(&pathsJSON, "json", false, "Print the paths as JSON")
+}
+
+// PathInfo describes one location claudeup uses
+type PathInfo struct {
+	Group   string `json:"group"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Dir     bool   `json:"dir"`
+	Exists  bool   `json:"exists"`
+	Size    int64  `json:"size"`            // bytes; for directories, the files inside
+	Files   int    `json:"files,omitempty"` // files inside a directory
+	Error   string `json:"error,omitempty"` // set when the path exists but can't be read
+	Purpose string `json:"purpose"`
+}
+
+func runPaths(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	paths := collectPaths()
+	if pathsJSON {
+		return printJSON(paths)
+	}
+
+	group := ""
+	var w *tabwriter.Writer
+	for _, p := range paths {
+		if p.Group != group {
+			if w != nil {
+				w.Flush()
+				out.Println()
+			}
+			group = p.Group
+			out.Printf("━━━ %s ━━━\n", group)
+			w = tabwriter.NewWriter(out.Out(), 0, 0, 2, ' ', 0)
+		}
+		glyph := "✓"
+		if !p.Exists {
+			glyph = "-"
+		} else if p.Error != "" {
+			glyph = "✗"
+		}
+		fmt.Fprintf(w, "  %s %s\t%s\t%s\n", glyph, p.Name, p.Path, describePath(p))
+	}
+	if w != nil {
+		w.Flush()
+	}
+	return nil
+}
+
+// describePath summarizes an entry's state for the table
+func describePath(p PathInfo) string {
+	switch {
+	case !p.Exists:
+		return "not created"
+	case p.Error != "":
+		return p.Error
+	case p.Dir:
+		return fmt.Sprintf("%d files, %s", p.Files, formatSize(int(p.Size)))
+	}
+	return formatSize(int(p.Size))
+}
+
+// collectPaths lists the locations claudeup uses, in display order
+func collectPaths() []PathInfo {
+	dirs := pathctx.Default()
+	claudeupDir := dirs.ClaudeupDir
+	claudeup := func(name string) string { return dirs.Claudeup(name) }
+	claudeJSON := dirs.ClaudeJSON
+
+	var paths []PathInfo
+	add := func(group, name, path string, dir bool, purpose string) {
+		paths = append(paths, statPath(PathInfo{Group: group, Name: name, Path: path, Dir: dir, Purpose: purpose}))
+	}
+
+	const cc = "Claude Code"
+	add(cc, "Claude directory", claudeDir, true, "Claude Code configuration (--claude-dir)")
+	add(cc, "Settings", filepath.Join(claudeDir, "settings.json"), false, "enabled plugins and other settings")
+	add(cc, "Plugin registry", filepath.Join(claudeDir, "plugins", "installed_plugins.json"), false, "installed plugins")
+	add(cc, "Marketplace registry", filepath.Join(claudeDir, "plugins", "known_marketplaces.json"), false, "registered marketplaces")
+	add(cc, "Plugins", filepath.Join(claudeDir, "plugins"), true, "marketplace clones and plugin caches")
+	add(cc, "Claude config", claudeJSON, false, "user MCP servers")
+
+	const cu = "claudeup"
+	add(cu, "claudeup directory", claudeupDir, true, "everything claudeup stores")
+	add(cu, "Config", config.Path(), false, "preferences, disabled items, workspaces")
+	add(cu, "Profiles", getProfilesDir(), true, "saved profiles")
+	add(cu, "Applied profiles", getAppliedDir(), true, "last-applied copies for 'profile save', state hashes for drift checks")
+	add(cu, "History", history.DefaultPath(), false, "log of applies and scheduled checks")
+	add(cu, "Plugin checksums", integrity.DefaultPath(), false, "recorded by apply, checked by 'verify'")
+	add(cu, "Snapshots", snapshot.DefaultDir(), true, "saved states for 'snapshot restore'")
+	add(cu, "Archive marketplaces", claudeup("marketplaces"), true, "unpacked archive marketplaces")
+	add(cu, "Sandbox state", claudeup("sandboxes"), true, "per-profile sandbox home directories")
+	add(cu, "Prompt cache", claudeup("prompt.cache"), false, "drift count for 'prompt'")
+	add(cu, "Schedule log", claudeup("schedule.log"), false, "output of scheduled checks")
+	add(cu, "API token", claudeup("serve.token"), false, "bearer token for 'serve'")
+
+	const sec = "Secrets"
+	add(sec, "Env file", secretsEnvFile(), false, "values saved by the secret setup wizard")
+	add(sec, "SOPS file", claudeup(secrets.DefaultSopsFile), false, "default file for sops sources")
+	add(sec, "Secret cache", claudeup("secrets.cache"), false, "encrypted cache (secretCacheTtl)")
+	add(sec, "Cache key", secrets.DefaultKeyPath(), false, "session key for the secret cache")
+
+	return paths
+}
+
+// statPath fills in whether p exists and its size
+func statPath(p PathInfo) PathInfo {
+	info, err := os.Stat(p.Path)
+	if os.IsNotExist(err) {
+		return p
+	}
+	p.Exists = true
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	if !info.IsDir() {
+		if p.Dir {
+			p.Error = "not a directory"
+		}
+		p.Size = info.Size()
+		return p
+	}
+	if !p.Dir {
+		p.Error = "is a directory"
+		return p
+	}
+
+	err = filepath.WalkDir(p.Path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			if fi, err := d.Info(); err == nil {
+				p.Files++
+				p.Size += fi.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		p.Error = err.Error()
+	}
+	return p
+}
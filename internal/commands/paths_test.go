@@ -0,0 +1,53 @@
+// ABOUTME: Tests for the paths command's existence and size reporting
+// ABOUTME: Covers missing paths, files, directory totals, and kind mismatches
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatPath(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "profiles", "nested"), 0755)
+	os.WriteFile(filepath.Join(dir, "profiles", "a.json"), []byte("12345"), 0644)
+	os.WriteFile(filepath.Join(dir, "profiles", "nested", "b.json"), []byte("123"), 0644)
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte("{}"), 0644)
+
+	missing := statPath(PathInfo{Path: filepath.Join(dir, "history.jsonl")})
+	if missing.Exists || missing.Error != "" {
+		t.Errorf("missing path = %+v", missing)
+	}
+
+	file := statPath(PathInfo{Path: filepath.Join(dir, "config.json")})
+	if !file.Exists || file.Size != 2 || file.Files != 0 {
+		t.Errorf("file = %+v", file)
+	}
+
+	profiles := statPath(PathInfo{Path: filepath.Join(dir, "profiles"), Dir: true})
+	if !profiles.Exists || profiles.Size != 8 || profiles.Files != 2 {
+		t.Errorf("directory = %+v, want 2 files, 8 bytes", profiles)
+	}
+
+	if p := statPath(PathInfo{Path: filepath.Join(dir, "config.json"), Dir: true}); p.Error != "not a directory" {
+		t.Errorf("file where a directory belongs: error = %q", p.Error)
+	}
+	if p := statPath(PathInfo{Path: filepath.Join(dir, "profiles")}); p.Error != "is a directory" {
+		t.Errorf("directory where a file belongs: error = %q", p.Error)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int]string{
+		512:             "512 B",
+		2048:            "2.0 KB",
+		5 * 1024 * 1024: "5.0 MB",
+		3 << 30:         "3.0 GB",
+	}
+	for n, want := range tests {
+		if got := formatSize(n); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// ABOUTME: plugin audit command for inspecting a plugin's contents before installing it
+// ABOUTME: Also provides the audit gate used by profile use when pluginAudit is configured
+package commands
+
+import (
+	"fmt"
+
+	"github.com/claudeup/claudeup/internal/audit"
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Inspect individual plugins",
+}
+
+var pluginAuditCmd = &cobra.Command{
+	Use:   "audit <name@marketplace>",
+	Short: "Audit a plugin's contents before installing it",
+	Long: `Inspects a plugin in its marketplace clone and lists the hooks and scripts it
+ships, the MCP servers it registers (and what they run), and the tool
+permissions its commands and agents declare. Risky patterns such as
+"curl | bash" or writes to shell startup files are flagged.
+
+To audit plugins automatically during 'claudeup profile use', set
+"pluginAudit" in ~/.claudeup/config.json preferences to "warn" or "block".`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPluginAudit,
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginAuditCmd)
+}
+
+func runPluginAudit(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	report, err := audit.Plugin(claudeDir, args[0])
+	if err != nil {
+		return err
+	}
+
+	out.Printf("Plugin: %s\n", report.Plugin)
+	out.Printf("Source: %s\n", report.Dir)
+	out.Println()
+
+	printAuditList(out, "Hooks", report.Hooks)
+	printAuditList(out, "Scripts", report.Scripts)
+
+	out.Println("━━━ MCP Servers ━━━")
+	if len(report.MCPServers) == 0 {
+		out.Println("  (none)")
+	}
+	for _, s := range report.MCPServers {
+		out.Printf("  %s: %s %v\n", s.Name, s.Command, s.Args)
+	}
+	out.Println()
+
+	printAuditList(out, "Permissions", report.Permissions)
+
+	out.Println("━━━ Findings ━━━")
+	if len(report.Findings) == 0 {
+		out.Println("  ✓ No risky patterns found")
+		return nil
+	}
+	printAuditFindings(out, report)
+
+	return nil
+}
+
+func printAuditList(out ui.Printer, title string, items []string) {
+	out.Printf("━━━ %s ━━━\n", title)
+	if len(items) == 0 {
+		out.Println("  (none)")
+	}
+	for _, item := range items {
+		out.Printf("  %s\n", item)
+	}
+	out.Println()
+}
+
+func printAuditFindings(out ui.Printer, report *audit.Report) {
+	for _, f := range report.Findings {
+		glyph := "⚠"
+		if f.Severity == audit.SeverityHigh {
+			glyph = "✗"
+		}
+		out.Printf("  %s %s:%d [%s] %s\n", glyph, f.File, f.Line, f.Rule, f.Text)
+	}
+}
+
+// auditPluginsForApply runs the configured audit policy over plugins about
+// to be installed. Returns an error if the policy is "block" and any plugin
+// is risky; with "warn" findings are printed and the apply continues.
+func auditPluginsForApply(out ui.Printer, plugins []string) error {
+	cfg, err := config.Load()
+	if err != nil || cfg.Preferences.PluginAudit == "" || len(plugins) == 0 {
+		return nil
+	}
+	policy := cfg.Preferences.PluginAudit
+
+	var blocked []string
+	for _, name := range plugins {
+		report, err := audit.Plugin(claudeDir, name)
+		if err != nil {
+			out.Printf("  ⚠ Could not audit %s: %v\n", name, err)
+			continue
+		}
+		if len(report.Findings) == 0 {
+			continue
+		}
+		out.Printf("  Audit findings for %s:\n", name)
+		printAuditFindings(out, report)
+		if report.Risky() {
+			blocked = append(blocked, name)
+		}
+	}
+
+	if policy == "block" && len(blocked) > 0 {
+		return fmt.Errorf("plugin audit blocked %d plugins: %v (run 'claudeup plugin audit <name>' for details)", len(blocked), blocked)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 
 	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -27,6 +28,8 @@ func init() {
 }
 
 func runPluginsList(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+
 	// Load plugins
 	plugins, err := claude.LoadPlugins(claudeDir)
 	if err != nil {
@@ -65,20 +68,20 @@ func runPluginsList(cmd *cobra.Command, args []string) error {
 
 	// If summary only, just show stats
 	if pluginsSummary {
-		fmt.Println("=== Plugin Summary ===")
-		fmt.Printf("\nTotal:   %d plugins\n", len(names))
-		fmt.Printf("Enabled: %d\n", enabledCount)
+		out.Println("=== Plugin Summary ===")
+		out.Printf("\nTotal:   %d plugins\n", len(names))
+		out.Printf("Enabled: %d\n", enabledCount)
 		if staleCount > 0 {
-			fmt.Printf("Stale:   %d\n", staleCount)
+			out.Printf("Stale:   %d\n", staleCount)
 		}
-		fmt.Printf("\nBy Type:\n")
-		fmt.Printf("  Cached: %d (copied to ~/.claude/plugins/cache/)\n", cachedCount)
-		fmt.Printf("  Local:  %d (referenced from marketplace)\n", localCount)
+		out.Printf("\nBy Type:\n")
+		out.Printf("  Cached: %d (copied to ~/.claude/plugins/cache/)\n", cachedCount)
+		out.Printf("  Local:  %d (referenced from marketplace)\n", localCount)
 		return nil
 	}
 
 	// Print header
-	fmt.Printf("=== Installed Plugins (%d) ===\n\n", len(names))
+	out.Printf("=== Installed Plugins (%d) ===\n\n", len(names))
 
 	// Print each plugin
 	for _, name := range names {
@@ -91,24 +94,24 @@ func runPluginsList(cmd *cobra.Command, args []string) error {
 			statusText = "stale (path not found)"
 		}
 
-		fmt.Printf("%s %s\n", status, name)
-		fmt.Printf("   Version:    %s\n", plugin.Version)
-		fmt.Printf("   Status:     %s\n", statusText)
-		fmt.Printf("   Path:       %s\n", plugin.InstallPath)
-		fmt.Printf("   Installed:  %s\n", plugin.InstalledAt)
+		out.Printf("%s %s\n", status, name)
+		out.Printf("   Version:    %s\n", plugin.Version)
+		out.Printf("   Status:     %s\n", statusText)
+		out.Printf("   Path:       %s\n", plugin.InstallPath)
+		out.Printf("   Installed:  %s\n", plugin.InstalledAt)
 		if plugin.IsLocal {
-			fmt.Printf("   Type:       local\n")
+			out.Printf("   Type:       local\n")
 		} else {
-			fmt.Printf("   Type:       cached\n")
+			out.Printf("   Type:       cached\n")
 		}
-		fmt.Println()
+		out.Println()
 	}
 
 	// Print summary at the end
-	fmt.Println("━━━ Summary ━━━")
-	fmt.Printf("Total: %d plugins (%d cached, %d local)\n", len(names), cachedCount, localCount)
+	out.Println("━━━ Summary ━━━")
+	out.Printf("Total: %d plugins (%d cached, %d local)\n", len(names), cachedCount, localCount)
 	if staleCount > 0 {
-		fmt.Printf("⚠ %d stale plugins detected\n", staleCount)
+		out.Printf("⚠ %d stale plugins detected\n", staleCount)
 	}
 
 	return nil
@@ -0,0 +1,67 @@
+// ABOUTME: Reads a profile for 'profile use' from --file or stdin instead of the profiles directory
+// ABOUTME: Lets pipelines apply generated profiles without saving them first
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+// readAdhocProfile reads the profile given as - (stdin) or with --file, and
+// returns the remaining arguments. The profile is nil if neither was given.
+// A profile without a name is named after its file, or "stdin".
+func readAdhocProfile(stdin io.Reader, args []string) ([]string, *profile.Profile, error) {
+	var rest []string
+	fromStdin := false
+	for _, arg := range args {
+		if arg != "-" {
+			rest = append(rest, arg)
+			continue
+		}
+		if fromStdin {
+			return nil, nil, fmt.Errorf("- can only be given once")
+		}
+		fromStdin = true
+	}
+
+	var (
+		r      io.Reader
+		source string
+		name   string
+	)
+	switch {
+	case fromStdin && profileUseFile != "":
+		return nil, nil, fmt.Errorf("--file can't be used with -")
+	case fromStdin:
+		// Prompts would read the end of the piped profile and take the default
+		if !config.YesFlag && profileUseDiffFormat == "" {
+			return nil, nil, fmt.Errorf("reading the profile from stdin leaves nothing to answer prompts; pass -y or --diff-format")
+		}
+		r, source, name = stdin, "stdin", "stdin"
+	case profileUseFile != "":
+		f, err := os.Open(profileUseFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		r, source = f, profileUseFile
+		name = strings.TrimSuffix(filepath.Base(profileUseFile), filepath.Ext(profileUseFile))
+	default:
+		return args, nil, nil
+	}
+
+	p, err := profile.Read(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid profile from %s: %w", source, err)
+	}
+	if p.Name == "" {
+		p.Name = name
+	}
+	return rest, p, nil
+}
@@ -0,0 +1,55 @@
+// ABOUTME: Tests for reading ad-hoc profiles from --file and stdin
+// ABOUTME: Checks naming, the remaining arguments, and that stdin requires -y
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/config"
+)
+
+func TestReadAdhocProfileFromStdin(t *testing.T) {
+	defer func() { config.YesFlag = false }()
+	stdin := `{"plugins": ["a@m"]}`
+
+	if _, _, err := readAdhocProfile(strings.NewReader(stdin), []string{"-"}); err == nil || !strings.Contains(err.Error(), "-y") {
+		t.Errorf("Expected stdin without -y to be refused, got %v", err)
+	}
+
+	config.YesFlag = true
+	rest, p, err := readAdhocProfile(strings.NewReader(stdin), []string{"-", "+addon"})
+	if err != nil {
+		t.Fatalf("readAdhocProfile failed: %v", err)
+	}
+	if p.Name != "stdin" || len(p.Plugins) != 1 || len(rest) != 1 || rest[0] != "+addon" {
+		t.Errorf("Got profile %+v and args %v", p, rest)
+	}
+
+	if _, _, err := readAdhocProfile(strings.NewReader("not json"), []string{"-"}); err == nil || !strings.Contains(err.Error(), "invalid profile from stdin") {
+		t.Errorf("Expected a parse error, got %v", err)
+	}
+}
+
+func TestReadAdhocProfileFromFile(t *testing.T) {
+	defer func() { profileUseFile = "" }()
+	path := filepath.Join(t.TempDir(), "ci-profile.json")
+	if err := os.WriteFile(path, []byte(`{"plugins": ["a@m"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rest, p, err := readAdhocProfile(strings.NewReader(""), nil)
+	if err != nil || p != nil || rest != nil {
+		t.Fatalf("Expected nothing read without - or --file, got %v, %v, %v", rest, p, err)
+	}
+
+	profileUseFile = path
+	if _, p, err = readAdhocProfile(strings.NewReader(""), nil); err != nil || p.Name != "ci-profile" {
+		t.Errorf("Expected the profile named after its file, got %+v, %v", p, err)
+	}
+	if _, _, err := readAdhocProfile(strings.NewReader(""), []string{"-"}); err == nil {
+		t.Error("Expected --file with - to be refused")
+	}
+}
@@ -0,0 +1,83 @@
+// ABOUTME: profile back re-applies the profile that was active before the last switch
+// ABOUTME: 'profile use' records the previous profile and a snapshot of the state before switching
+package commands
+
+import (
+	"fmt"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/snapshot"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var profileBackCmd = &cobra.Command{
+	Use:   "back",
+	Short: "Re-apply the profile that was active before the last switch",
+	Long: `Undoes a 'profile use' that switched to another profile by applying the
+previous one again. Running it twice switches back, like 'cd -'.
+
+Before switching, 'profile use' snapshots the state as before-<profile>, so
+'claudeup snapshot diff before-<profile> current' shows what the switch
+changed. Only the snapshot of the latest switch is kept. Switches made with
+--local aren't tracked.`,
+	Example: `  claudeup profile use experimental
+  claudeup profile back`,
+	Args: cobra.NoArgs,
+	RunE: runProfileBack,
+}
+
+func init() {
+	profileCmd.AddCommand(profileBackCmd)
+	addFailOnErrorFlag(profileBackCmd)
+	addForceFlag(profileBackCmd)
+}
+
+func runProfileBack(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	previous := cfg.PreviousProfile.Name
+	if previous == "" {
+		return fmt.Errorf("no previous profile; 'profile back' undoes a 'profile use' that switched profiles")
+	}
+
+	out.Printf("Going back from %s to %s\n", globalProfileName(cfg), previous)
+	out.Println()
+	return runProfileUse(cmd, []string{previous})
+}
+
+// snapshotBeforeSwitch snapshots the state before 'profile use' replaces
+// the global active profile with p, returning the snapshot's ID, or "" when
+// it isn't a switch or the snapshot couldn't be taken
+func snapshotBeforeSwitch(out ui.Printer, p *profile.Profile, claudeJSONPath string) string {
+	cfg, err := config.LoadExisting()
+	if err != nil || profileUseLocal || p.IsAddon() {
+		return ""
+	}
+	if active := cfg.Preferences.ActiveProfile; active == "" || active == p.Name {
+		return ""
+	}
+	snap, err := snapshot.Take(snapshot.DefaultDir(), "before-"+p.Name, claudeDir, claudeJSONPath)
+	if err != nil {
+		out.Printf("  ⚠ Could not snapshot the state before switching: %v\n", err)
+		return ""
+	}
+	return snap.ID
+}
+
+// recordPreviousProfile remembers previous for 'profile back', along with
+// the snapshot taken before switching away from it. The snapshot of the
+// switch before is deleted.
+func recordPreviousProfile(cfg *config.GlobalConfig, previous, snapshotID string) {
+	if old := cfg.PreviousProfile.Snapshot; old != "" && old != snapshotID {
+		snapshot.Remove(snapshot.DefaultDir(), old)
+	}
+	cfg.PreviousProfile = config.PreviousProfile{Name: previous, Snapshot: snapshotID}
+}
@@ -0,0 +1,66 @@
+// ABOUTME: Tests for remembering the previous profile for 'profile back'
+// ABOUTME: Checks what a global switch records, that only the latest snapshot is kept, and the error with nothing to go back to
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/snapshot"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func TestRecordActiveProfileRemembersPrevious(t *testing.T) {
+	defer pathctx.Override(pathctx.ForHome(t.TempDir()))()
+	t.Chdir(t.TempDir())
+	out := ui.NewPrinter(&strings.Builder{}, &strings.Builder{}, false)
+
+	recordActiveProfile(out, "backend", "")
+	if cfg, _ := config.Load(); cfg.PreviousProfile.Name != "" {
+		t.Errorf("Expected no previous profile after the first use, got %+v", cfg.PreviousProfile)
+	}
+
+	dir := snapshot.DefaultDir()
+	first := &snapshot.Snapshot{ID: "1-before-frontend"}
+	second := &snapshot.Snapshot{ID: "2-before-backend"}
+	for _, snap := range []*snapshot.Snapshot{first, second} {
+		if err := snapshot.Save(dir, snap); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recordActiveProfile(out, "frontend", first.ID)
+	cfg, _ := config.Load()
+	if cfg.PreviousProfile != (config.PreviousProfile{Name: "backend", Snapshot: first.ID}) {
+		t.Errorf("Expected backend and its snapshot remembered, got %+v", cfg.PreviousProfile)
+	}
+
+	// Re-applying the active profile isn't a switch
+	recordActiveProfile(out, "frontend", "")
+	if cfg, _ := config.Load(); cfg.PreviousProfile.Name != "backend" {
+		t.Errorf("Expected re-applying to keep backend as previous, got %+v", cfg.PreviousProfile)
+	}
+
+	recordActiveProfile(out, "backend", second.ID)
+	cfg, _ = config.Load()
+	if cfg.PreviousProfile != (config.PreviousProfile{Name: "frontend", Snapshot: second.ID}) {
+		t.Errorf("Expected frontend remembered after switching back, got %+v", cfg.PreviousProfile)
+	}
+	if _, err := os.Stat(filepath.Join(dir, first.ID+".json")); !os.IsNotExist(err) {
+		t.Error("Expected the earlier switch's snapshot to be deleted")
+	}
+}
+
+func TestProfileBackWithoutPrevious(t *testing.T) {
+	defer pathctx.Override(pathctx.ForHome(t.TempDir()))()
+	profileBackCmd.SetContext(context.Background())
+	err := runProfileBack(profileBackCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "no previous profile") {
+		t.Errorf("Expected an error with nothing to go back to, got %v", err)
+	}
+}
@@ -4,19 +4,48 @@ package commands
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/claudeup/claudeup/internal/claude"
 	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/history"
+	"github.com/claudeup/claudeup/internal/i18n"
+	"github.com/claudeup/claudeup/internal/mcp"
+	"github.com/claudeup/claudeup/internal/notify"
+	"github.com/claudeup/claudeup/internal/pathctx"
 	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/secrets"
+	"github.com/claudeup/claudeup/internal/snapshot"
+	"github.com/claudeup/claudeup/internal/telemetry"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/claudeup/claudeup/internal/webhook"
 	"github.com/spf13/cobra"
 )
 
-var profileCreateFromFlag string
+var (
+	profileCreateFromFlag string
+	profileUseDiffFormat  string
+	profileUseOnly        []string
+	profileUseSkip        []string
+	profileUseInteractive bool
+	profileUseFile        string
+	profileUseStrict      bool
+	profileUseLocal       bool
+	profileListLong       bool
+	profileListTags       []string
+	profileSaveProvided   bool
+)
 
 var profileCmd = &cobra.Command{
 	Use:   "profile",
@@ -32,14 +61,62 @@ Use profiles to:
 var profileListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available profiles",
-	RunE:  runProfileList,
+	Long: `Lists saved and built-in profiles. The active profile is marked with *.
+
+--long adds each profile's tags, author, when it was created and last
+changed, and when and on which machine it was last applied. --tag shows only
+profiles with that tag; repeat it to require several.`,
+	Example: `  claudeup profile list --long
+  claudeup profile list --tag backend --tag go`,
+	RunE: runProfileList,
 }
 
 var profileUseCmd = &cobra.Command{
-	Use:   "use <name>",
+	Use:   "use <name|-> [+addon...]",
 	Short: "Apply a profile to Claude Code",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runProfileUse,
+	Long: `Applies a profile, installing what it lists and removing what it doesn't.
+
+Addon profiles ("type": "addon") can be layered on with +name. Addons only
+add items and never trigger removals; applying addons without a base profile
+leaves everything else untouched. Conflicting definitions (such as the same
+MCP server name with different commands) are reported and nothing is applied.
+
+With --diff-format json or yaml, the planned changes are printed in a
+versioned schema and nothing is applied.
+
+--only and --skip limit the apply to some subsystems (plugins, mcp,
+marketplaces); changes to the others are neither shown nor made.
+
+--interactive asks about each change in turn: y applies it, n skips it,
+a applies it and every remaining change, and q skips it and every
+remaining change.
+
+A profile that isn't saved can be applied from a path with --file, or from
+stdin with -, for pipelines that generate profiles. It gets the same preview
+and plugin audit and is never copied into the profiles directory. Reading
+stdin leaves nothing to answer prompts, so - needs -y or --diff-format.
+
+--strict refuses to apply, or plan, when Claude's configuration holds
+anything a profile can't represent, such as registry fields from a newer
+Claude Code or MCP servers using the http or sse transports, since the apply
+could lose it.
+
+--local makes the profile active only in the current directory and its
+subdirectories; 'profile current' and 'status' report it there, and the
+global active profile is left as it was. Plugins and MCP servers are still
+applied to your Claude configuration as usual.`,
+	Example: `  claudeup profile use backend
+  claudeup profile use backend +security-addon +data-addon
+  claudeup profile use +security-addon
+  claudeup profile use backend --diff-format json
+  claudeup profile use backend --only plugins
+  claudeup profile use backend --skip mcp
+  claudeup profile use team --interactive
+  claudeup profile use frontend --local
+  render-profile | claudeup profile use - -y
+  claudeup profile use --file ci-profile.json +security-addon`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runProfileUse,
 }
 
 var profileSaveCmd = &cobra.Command{
@@ -48,7 +125,18 @@ var profileSaveCmd = &cobra.Command{
 	Long: `Saves your current Claude Code configuration (plugins, MCP servers, marketplaces) to a profile.
 
 If no name is given, saves to the currently active profile.
-If the profile exists, prompts for confirmation unless -y is used.`,
+If the profile exists, prompts for confirmation unless -y is used.
+
+If the profile was edited on disk since it was last applied, each edited
+section (marketplaces, plugins, MCP servers, disabled) is shown as it was
+applied, as it is on disk, and as it is now, and you choose per section
+whether to keep the disk version or take the current state. With -y the
+current state is taken.
+
+--provided-mcp also records the MCP servers that installed plugins bring, in
+a read-only providedMCPServers section, so 'profile show' lists every server
+the profile yields. Apply ignores the section. Once a profile has it, later
+saves keep it up to date.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runProfileSave,
 }
@@ -67,14 +155,29 @@ With -y flag, uses the currently active profile as the source.`,
 var profileShowCmd = &cobra.Command{
 	Use:   "show <name>",
 	Short: "Display a profile's contents",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runProfileShow,
+	Long: `Shows a profile as it is saved, including its plugin groups.
+
+With --resolve, shows the effective profile instead: the profile with any
++addons merged in and its enabled plugin groups folded into the plugins,
+every list sorted, and each entry labelled with the profile or group it
+came from. This is what 'profile use' would apply. --write saves that
+flattened profile under a new name.`,
+	Example: `  claudeup profile show backend
+  claudeup profile show "backend +frontend" --resolve
+  claudeup profile show "backend +frontend" --write backend-frontend`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProfileShow,
 }
 
 var profileSuggestCmd = &cobra.Command{
 	Use:   "suggest",
 	Short: "Suggest a profile based on current directory",
-	RunE:  runProfileSuggest,
+	Long: `Suggests a profile for the current directory from workspace mappings
+and project detection, then offers to apply it.
+
+With --quiet nothing is printed and nothing is applied: the exit status is 0
+when a profile matches and 1 when none does. Add -y to apply the match.`,
+	RunE: runProfileSuggest,
 }
 
 var profileCurrentCmd = &cobra.Command{
@@ -93,10 +196,27 @@ func init() {
 	profileCmd.AddCommand(profileSuggestCmd)
 	profileCmd.AddCommand(profileCurrentCmd)
 
+	profileListCmd.Flags().BoolVarP(&profileListLong, "long", "l", false, "Show tags, author, and timestamps")
+	profileListCmd.Flags().StringSliceVar(&profileListTags, "tag", nil, "Only show profiles with this tag")
 	profileCreateCmd.Flags().StringVar(&profileCreateFromFlag, "from", "", "Source profile to copy from")
+	profileSaveCmd.Flags().BoolVar(&profileSaveProvided, "provided-mcp", false, "Also document MCP servers provided by plugins")
+	profileUseCmd.Flags().StringVar(&profileUseDiffFormat, "diff-format", "", "Print planned changes as json or yaml without applying")
+	profileUseCmd.Flags().StringSliceVar(&profileUseOnly, "only", nil, "Apply only these subsystems: plugins, mcp, marketplaces")
+	profileUseCmd.Flags().StringSliceVar(&profileUseSkip, "skip", nil, "Leave these subsystems untouched: plugins, mcp, marketplaces")
+	profileUseCmd.Flags().BoolVar(&profileUseInteractive, "interactive", false, "Confirm each change individually")
+	profileUseCmd.Flags().StringVar(&profileUseFile, "file", "", "Apply the profile at this path instead of a saved one")
+	profileUseCmd.Flags().BoolVar(&profileUseStrict, "strict", false, "Fail if Claude's configuration has anything a profile can't represent")
+	profileUseCmd.Flags().BoolVar(&profileUseLocal, "local", false, "Make the profile active only in the current directory, leaving the global one alone")
+	addFailOnErrorFlag(profileUseCmd)
+	addForceFlag(profileUseCmd)
+	addGrantPermissionsFlag(profileUseCmd)
+	addPurgeFlag(profileUseCmd)
+	addCategoryFlag(profileUseCmd)
+	addReplayFlags(profileUseCmd)
 }
 
 func runProfileList(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
 	profilesDir := getProfilesDir()
 
 	// Load user profiles from disk
@@ -108,7 +228,7 @@ func runProfileList(cmd *cobra.Command, args []string) error {
 	// Load embedded (built-in) profiles
 	embeddedProfiles, embeddedErr := profile.ListEmbeddedProfiles()
 	if embeddedErr != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to load built-in profiles: %v\n", embeddedErr)
+		out.Warnf("Warning: failed to load built-in profiles: %v\n", embeddedErr)
 		embeddedProfiles = []*profile.Profile{} // Prevent nil slice panic
 	}
 
@@ -118,145 +238,350 @@ func runProfileList(cmd *cobra.Command, args []string) error {
 		userProfileNames[p.Name] = true
 	}
 
-	// Get active profile from config
+	// Get the profile active here from config
 	cfg, _ := config.Load()
-	activeProfile := ""
-	if cfg != nil {
-		activeProfile = cfg.Preferences.ActiveProfile
-	}
+	activeProfile, _ := activeProfileHere(cfg)
 
-	// Check if we have any profiles to show
-	hasBuiltIn := false
+	// Built-in profiles not yet extracted to disk come first
+	var listed []listedProfile
 	for _, p := range embeddedProfiles {
 		if !userProfileNames[p.Name] {
-			hasBuiltIn = true
-			break
+			listed = append(listed, listedProfile{Profile: p, builtIn: true})
 		}
 	}
+	for _, p := range userProfiles {
+		listed = append(listed, listedProfile{Profile: p})
+	}
 
-	if len(userProfiles) == 0 && !hasBuiltIn {
-		fmt.Println("No profiles found.")
-		fmt.Println("Create one with: claudeup profile save <name>")
+	if len(listed) == 0 {
+		out.Println("No profiles found.")
+		out.Println("Create one with: claudeup profile save <name>")
 		return nil
 	}
 
-	fmt.Println("Available profiles:")
-	fmt.Println()
-
-	// Show built-in profiles first (ones not yet extracted to disk)
-	for _, p := range embeddedProfiles {
-		if userProfileNames[p.Name] {
-			continue // Skip if user has customized this profile
-		}
-
-		marker := "  "
-		if p.Name == activeProfile {
-			marker = "* "
-		}
+	listed = filterByTags(listed, profileListTags)
+	if len(listed) == 0 {
+		out.Printf("No profiles tagged %s.\n", strings.Join(profileListTags, ", "))
+		return nil
+	}
 
-		desc := p.Description
-		if desc == "" {
-			desc = "(no description)"
-		}
+	out.Println("Available profiles:")
+	out.Println()
 
-		fmt.Printf("%s%-20s %s [built-in]\n", marker, p.Name, desc)
-	}
+	if profileListLong {
+		showProfilesLong(out, listed, activeProfile)
+	} else {
+		for _, p := range listed {
+			marker := "  "
+			if p.Name == activeProfile {
+				marker = "* "
+			}
 
-	// Show user profiles
-	for _, p := range userProfiles {
-		marker := "  "
-		if p.Name == activeProfile {
-			marker = "* "
-		}
+			desc := p.Description
+			if desc == "" {
+				desc = "(no description)"
+			}
 
-		desc := p.Description
-		if desc == "" {
-			desc = "(no description)"
+			builtIn := ""
+			if p.builtIn {
+				builtIn = " [built-in]"
+			}
+			out.Printf("%s%-20s %s%s%s\n", marker, p.Name, desc, addonTag(p.Profile), builtIn)
 		}
-
-		fmt.Printf("%s%-20s %s\n", marker, p.Name, desc)
 	}
-
-	fmt.Println()
-	fmt.Println("Use 'claudeup profile show <name>' for details")
-	fmt.Println("Use 'claudeup profile use <name>' to apply a profile")
+	out.Println()
+	out.Println("Use 'claudeup profile show <name>' for details")
+	out.Println("Use 'claudeup profile use <name>' to apply a profile")
 
 	return nil
 }
 
 func runProfileUse(cmd *cobra.Command, args []string) error {
-	name := args[0]
+	out := ui.PrinterFrom(cmd.Context())
+	if err := validateFormat("diff-format", profileUseDiffFormat); err != nil {
+		return err
+	}
+	selected, err := profile.SelectSubsystems(profileUseOnly, profileUseSkip)
+	if err != nil {
+		return err
+	}
+	if profileUseInteractive && profileUseDiffFormat != "" {
+		return fmt.Errorf("--interactive can't be used with --diff-format")
+	}
 	profilesDir := getProfilesDir()
 
-	// Load the profile (try disk first, then embedded)
-	p, err := loadProfileWithFallback(profilesDir, name)
+	// A profile from --file or stdin takes the place of a saved base profile
+	args, adhoc, err := readAdhocProfile(cmd.InOrStdin(), args)
 	if err != nil {
-		return fmt.Errorf("profile %q not found: %w", name, err)
+		return err
+	}
+	if adhoc == nil && len(args) == 0 {
+		return fmt.Errorf("requires a profile name, - to read one from stdin, or --file")
+	}
+
+	// Load the profile and any +addons (try disk first, then embedded)
+	p, err := loadProfileOnto(profilesDir, adhoc, args)
+	if err != nil {
+		return err
+	}
+	// A plan can't stop to ask, so it uses the wizard's default categories
+	if p, err = runSetupWizard(cmd, out, p, profileUseDiffFormat == ""); err != nil {
+		return err
+	}
+	// Saved profiles are stamped as applied; an ad-hoc one has nowhere to go
+	saved := strings.Join(args, " ")
+	name := saved
+	if adhoc != nil {
+		name = strings.TrimSpace(adhoc.Name + " " + saved)
 	}
 
-	claudeDir := profile.DefaultClaudeDir()
 	claudeJSONPath := profile.DefaultClaudeJSONPath()
 
+	if profileUseStrict {
+		if err := checkRepresentable(out, claudeDir, claudeJSONPath); err != nil {
+			return err
+		}
+	}
+
 	// Compute and show diff
 	diff, err := profile.ComputeDiff(p, claudeDir, claudeJSONPath)
 	if err != nil {
 		return fmt.Errorf("failed to compute changes: %w", err)
 	}
+	diff = diff.Filter(selected)
 
-	if !hasDiffChanges(diff) {
-		fmt.Println("No changes needed - profile already matches current state.")
-		return nil
+	if profileUseDiffFormat != "" {
+		return printFormatted(profileUseDiffFormat, profile.NewPlan(name, diff))
+	}
+	if err := checkWritable(cmd); err != nil {
+		return err
 	}
 
-	fmt.Printf("Profile: %s\n", name)
-	fmt.Println()
-	showDiff(diff)
-	fmt.Println()
+	if !hasDiffChanges(diff) {
+		out.Println(i18n.T("No changes needed - profile already matches current state."))
+		showSkipped(out, diff)
+		// The state already matching is the usual reason to scope a profile to a project
+		if profileUseLocal && !p.IsAddon() {
+			if err := recordActiveProfile(out, p.Name, ""); err != nil {
+				out.Printf("  ⚠ %v\n", err)
+			}
+		}
+		return seedPermissions(out, p, claudeDir, claudeJSONPath)
+	}
 
-	if !confirmProceed() {
-		fmt.Println("Cancelled.")
-		return nil
+	executor, finish, err := claudeExecutor(out)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	out.Printf(i18n.T("Profile: %s\n"), name)
+	out.Println()
+	showDiff(out, diff)
+	out.Println()
+
+	partial := selected != nil
+	if profileUseInteractive && !config.YesFlag {
+		total := diff.Count()
+		diff = confirmEachChange(out, diff)
+		if !hasDiffChanges(diff) {
+			out.Println("No changes selected.")
+			return nil
+		}
+		partial = partial || diff.Count() < total
+		out.Println()
 	}
 
-	// Apply
-	fmt.Println()
-	fmt.Println("Applying profile...")
+	if err := checkApply(cmd.Context(), out, diff); err != nil {
+		return err
+	}
 
 	chain := buildSecretChain()
-	result, err := profile.Apply(p, claudeDir, claudeJSONPath, chain)
-	if err != nil {
-		return fmt.Errorf("failed to apply profile: %w", err)
+	if err := ensureProfileEnv(out, p, chain); err != nil {
+		return err
 	}
 
-	showApplyResults(result)
+	if !profileUseInteractive && !confirmProceed(out) {
+		out.Println(i18n.T("Cancelled."))
+		return nil
+	}
 
-	// Update active profile in config
-	cfg, err := config.Load()
+	// Apply, keeping what was there for 'profile back'
+	before := snapshotBeforeSwitch(out, p, claudeJSONPath)
+	out.Println()
+	out.Println(i18n.T("Applying profile..."))
+	ctx := applyStarting(cmd.Context(), out, "cli", name, diff)
+	widenSparseClones(ctx, out, diff.PluginsToInstall)
+	purgeable := purgeCandidates(claudeDir)
+
+	var result *profile.ApplyResult
+	if profileUseInteractive {
+		result, err = profile.ApplyPlanned(ctx, diff, claudeDir, chain, executor)
+		if result != nil {
+			result.Skipped = selected.Skipped()
+		}
+	} else {
+		result, err = profile.ApplySelected(ctx, p, claudeDir, claudeJSONPath, chain, executor, selected)
+	}
+	recordApplyFrom(ctx, out, "cli", name, diff, result, err)
 	if err != nil {
-		cfg = config.DefaultConfig()
+		if before != "" {
+			snapshot.Remove(snapshot.DefaultDir(), before)
+		}
+		return applyFailed(out, result, err)
 	}
-	cfg.Preferences.ActiveProfile = name
-	if err := config.Save(cfg); err != nil {
-		fmt.Printf("  ⚠ Could not save active profile: %v\n", err)
+	purgeRemovedPlugins(claudeDir, purgeable, result)
+
+	showApplyResults(out, result)
+	offerSecretWizard(cmd.Context(), out, result.UnresolvedSecrets, chain)
+	warmAfterApply(cmd.Context(), out, p, result)
+
+	if err := recordApply(out, p, saved, before, result, partial); err != nil {
+		out.Printf("  ⚠ %v\n", err)
 	}
 
 	// Silently clean up stale plugin entries
-	cleanupStalePlugins(claudeDir)
+	cleanupStalePlugins(out, claudeDir)
+
+	if err := seedPermissions(out, p, claudeDir, claudeJSONPath); err != nil {
+		out.Printf("  ⚠ Could not pre-seed permissions: %v\n", err)
+	}
+
+	out.Println()
+	if len(result.Errors) > 0 {
+		out.Println(i18n.T("⚠ Profile applied with errors"))
+		return applyExitError(result)
+	}
+	out.Println(i18n.T("✓ Profile applied!"))
+
+	return nil
+}
 
-	fmt.Println()
-	fmt.Println("✓ Profile applied!")
+// ensureProfileEnv checks the profile's env manifest before applying and
+// asks for required variables that have no value. Values from sources,
+// defaults, and the prompt are set for the rest of this run, so MCP server
+// secrets that read them resolve.
+func ensureProfileEnv(out ui.Printer, p *profile.Profile, chain *secrets.Chain) error {
+	statuses := p.CheckEnv(chain)
+	var missing []string
+	for _, s := range statuses {
+		if s.Missing() {
+			missing = append(missing, s.Name)
+		}
+	}
+	if len(missing) > 0 && config.YesFlag {
+		return fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
+	}
+	if len(missing) > 0 {
+		out.Println("Required environment variables are not set:")
+	}
+
+	for _, s := range statuses {
+		value := s.Value
+		if s.Missing() {
+			label := s.Name
+			if s.Var.Description != "" {
+				label += " (" + s.Var.Description + ")"
+			}
+			entered, err := ui.PromptSecret("  " + label)
+			if err != nil {
+				return err
+			}
+			if entered == "" {
+				return fmt.Errorf("required environment variable %s was not provided", s.Name)
+			}
+			value = entered
+		}
+		if s.Source != profile.EnvFromEnvironment && value != "" {
+			os.Setenv(s.Name, value)
+		}
+	}
 
+	if len(missing) > 0 {
+		out.Printf("  → Set for this run only; export %s in your shell to keep\n", strings.Join(missing, ", "))
+		out.Println()
+	}
 	return nil
 }
 
+// showEnvChecklist lists the profile's env manifest with whether each
+// variable is ready. Secret sources aren't consulted, so showing a profile
+// never triggers a password manager prompt.
+func showEnvChecklist(out ui.Printer, p *profile.Profile) {
+	out.Println("Environment:")
+	for _, s := range p.CheckEnv(nil) {
+		line := s.Name
+		if s.Var.Description != "" {
+			line += " - " + s.Var.Description
+		}
+		switch {
+		case s.Source == profile.EnvFromEnvironment:
+			out.Printf("  ✓ %s (set)\n", line)
+		case len(s.Var.Sources) > 0:
+			out.Printf("  → %s (resolved from %s on apply)\n", line, envSourceTypes(s.Var.Sources))
+		case s.Source == profile.EnvFromDefault:
+			out.Printf("  ✓ %s (default: %s)\n", line, s.Value)
+		case s.Var.Required:
+			out.Printf("  ✗ %s (required, not set)\n", line)
+		default:
+			out.Printf("  • %s (optional, not set)\n", line)
+		}
+	}
+	out.Println()
+}
+
+func envSourceTypes(sources []profile.SecretSource) string {
+	types := make([]string, len(sources))
+	for i, src := range sources {
+		types[i] = src.Type
+	}
+	return strings.Join(types, ", ")
+}
+
+// confirmEachChange asks about every change in diff and returns the diff of
+// accepted changes. "a" accepts the rest; "q" declines the rest.
+func confirmEachChange(out ui.Printer, diff *profile.Diff) *profile.Diff {
+	accepted := make(map[profile.DiffItem]bool)
+	items := diff.Items()
+	for i, item := range items {
+		answer := ""
+		for answer == "" {
+			switch strings.ToLower(promptChoice(out, fmt.Sprintf("  (%d/%d) %s? (y/n/a/q)", i+1, len(items), item), "n")) {
+			case "y", "yes":
+				answer = "y"
+			case "n", "no":
+				answer = "n"
+			case "a", "all":
+				answer = "a"
+			case "q", "quit":
+				answer = "q"
+			default:
+				out.Println("  Please answer y, n, a, or q")
+			}
+		}
+
+		if answer == "q" {
+			break
+		}
+		if answer == "a" {
+			for _, rest := range items[i:] {
+				accepted[rest] = true
+			}
+			break
+		}
+		accepted[item] = answer == "y"
+	}
+	return diff.Select(func(item profile.DiffItem) bool { return accepted[item] })
+}
+
 // cleanupStalePlugins removes plugin entries with invalid paths
 // This is called automatically after profile apply to clean up zombie entries
-func cleanupStalePlugins(claudeDir string) {
+func cleanupStalePlugins(out ui.Printer, claudeDir string) {
 	plugins, err := claude.LoadPlugins(claudeDir)
 	if err != nil {
 		// Only warn if not a simple "file not found" - that's expected on fresh installs
 		if !os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "  Warning: could not load plugins for cleanup: %v\n", err)
+			out.Warnf("  Warning: could not load plugins for cleanup: %v\n", err)
 		}
 		return
 	}
@@ -272,14 +597,18 @@ func cleanupStalePlugins(claudeDir string) {
 
 	if removed > 0 {
 		if err := claude.SavePlugins(claudeDir, plugins); err != nil {
-			fmt.Fprintf(os.Stderr, "  Warning: could not save cleaned plugins: %v\n", err)
+			out.Warnf("  Warning: could not save cleaned plugins: %v\n", err)
 		} else {
-			fmt.Printf("  Cleaned up %d stale plugin entries\n", removed)
+			out.Printf("  Cleaned up %d stale plugin entries\n", removed)
 		}
 	}
 }
 
 func runProfileSave(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
 	profilesDir := getProfilesDir()
 
 	// Determine profile name
@@ -293,48 +622,137 @@ func runProfileSave(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("no profile name given and no active profile set. Use 'claudeup profile save <name>' or 'claudeup profile use <name>' first")
 		}
 		name = cfg.Preferences.ActiveProfile
-		fmt.Printf("Saving to active profile: %s\n", name)
+		out.Printf("Saving to active profile: %s\n", name)
+	}
+	if err := profile.ValidateName(name); err != nil {
+		return err
+	}
+
+	claudeJSONPath := profile.DefaultClaudeJSONPath()
+
+	// Create snapshot
+	p, err := profile.Snapshot(name, claudeDir, claudeJSONPath)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot current state: %w", err)
+	}
+	// This machine's overrides stay out of the saved profile
+	overrides, err := profile.LoadOverrides()
+	if err != nil {
+		return err
 	}
+	p = p.WithoutOverrides(overrides)
 
 	// Check if profile already exists
+	includeProvided := profileSaveProvided
 	existingPath := filepath.Join(profilesDir, name+".json")
 	if _, err := os.Stat(existingPath); err == nil {
-		if !config.YesFlag {
-			fmt.Printf("Profile %q already exists. Overwrite? [y/N]: ", name)
-			choice := promptChoice("", "n")
+		var conflicts []profile.SectionConflict
+		disk, diskErr := profile.Load(profilesDir, name)
+		if diskErr == nil && len(disk.ProvidedMCPServers) > 0 {
+			includeProvided = true
+		}
+		applied, appliedErr := profile.Load(getAppliedDir(), name)
+		if diskErr == nil && appliedErr == nil {
+			conflicts = profile.FindSaveConflicts(applied, disk, p)
+		}
+
+		if len(conflicts) > 0 {
+			keepDisk, ok := resolveSaveConflicts(out, name, conflicts)
+			if !ok {
+				out.Println("Cancelled.")
+				return nil
+			}
+			p = profile.MergeSections(disk, p, keepDisk)
+		} else if !config.YesFlag {
+			out.Promptf("Profile %q already exists. Overwrite? [y/N]: ", name)
+			choice := promptChoice(out, "", "n")
 			if choice != "y" && choice != "yes" {
-				fmt.Println("Cancelled.")
+				out.Println("Cancelled.")
 				return nil
 			}
 		}
 	}
 
-	claudeDir := profile.DefaultClaudeDir()
-	claudeJSONPath := profile.DefaultClaudeJSONPath()
-
-	// Create snapshot
-	p, err := profile.Snapshot(name, claudeDir, claudeJSONPath)
-	if err != nil {
-		return fmt.Errorf("failed to snapshot current state: %w", err)
+	if includeProvided {
+		provided, err := providedMCPServers(claudeDir)
+		if err != nil {
+			return err
+		}
+		// Only servers the saved profile actually yields
+		p.ProvidedMCPServers = nil
+		for _, m := range provided {
+			if slices.Contains(p.Plugins, m.Plugin) && !slices.Contains(p.Disabled.MCPServers, m.Plugin+":"+m.Name) {
+				p.ProvidedMCPServers = append(p.ProvidedMCPServers, m)
+			}
+		}
 	}
 
 	// Save
 	if err := profile.Save(profilesDir, p); err != nil {
 		return fmt.Errorf("failed to save profile: %w", err)
 	}
+	recordAppliedProfile(out, p)
 
-	fmt.Printf("✓ Saved profile %q\n", name)
-	fmt.Println()
-	fmt.Printf("  MCP Servers:   %d\n", len(p.MCPServers))
-	fmt.Printf("  Marketplaces:  %d\n", len(p.Marketplaces))
-	fmt.Printf("  Plugins:       %d\n", len(p.Plugins))
+	out.Printf("✓ Saved profile %q\n", name)
+	out.Println()
+	out.Printf("  MCP Servers:   %d\n", len(p.MCPServers))
+	if includeProvided {
+		out.Printf("  Plugin MCP:    %d (documented only)\n", len(p.ProvidedMCPServers))
+	}
+	out.Printf("  Marketplaces:  %d\n", len(p.Marketplaces))
+	out.Printf("  Plugins:       %d\n", len(p.Plugins))
 
 	return nil
 }
 
+// providedMCPServers lists the MCP servers defined by installed plugins,
+// sorted by plugin and server name
+func providedMCPServers(claudeDir string) ([]profile.ProvidedMCPServer, error) {
+	plugins, err := claude.LoadPlugins(claudeDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load plugins: %w", err)
+	}
+	discovered, err := mcp.DiscoverMCPServers(plugins)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover MCP servers: %w", err)
+	}
+
+	var provided []profile.ProvidedMCPServer
+	for _, pl := range discovered {
+		for name, def := range pl.Servers {
+			provided = append(provided, profile.ProvidedMCPServer{
+				Name:    name,
+				Plugin:  pl.PluginName,
+				Command: def.Command,
+				Args:    def.Args,
+				Env:     slices.Sorted(maps.Keys(def.Env)),
+			})
+		}
+	}
+	sort.Slice(provided, func(i, j int) bool {
+		if provided[i].Plugin != provided[j].Plugin {
+			return provided[i].Plugin < provided[j].Plugin
+		}
+		return provided[i].Name < provided[j].Name
+	})
+	return provided, nil
+}
+
 func runProfileShow(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
 	name := args[0]
 	profilesDir := getProfilesDir()
+	if profileShowWrite != "" {
+		if err := checkWritable(cmd); err != nil {
+			return err
+		}
+	}
+	if profileShowResolve || profileShowWrite != "" {
+		return showResolvedProfile(out, profilesDir, name)
+	}
 
 	// Load the profile (try disk first, then embedded)
 	p, err := loadProfileWithFallback(profilesDir, name)
@@ -342,85 +760,187 @@ func runProfileShow(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("profile %q not found: %w", name, err)
 	}
 
-	fmt.Printf("Profile: %s\n", p.Name)
+	out.Printf("Profile: %s\n", p.Name)
 	if p.Description != "" {
-		fmt.Printf("Description: %s\n", p.Description)
+		out.Printf("Description: %s\n", p.Description)
+	}
+	if p.IsAddon() {
+		out.Println("Type: addon (only adds items; use with 'profile use <base> +" + p.Name + "')")
+	}
+	if len(p.Tags) > 0 {
+		out.Printf("Tags: %s\n", strings.Join(p.Tags, ", "))
+	}
+	if p.Author != "" {
+		out.Printf("Author: %s\n", p.Author)
+	}
+	if !p.UpdatedAt.IsZero() {
+		out.Printf("Updated: %s (created %s)\n", formatDate(p.UpdatedAt, "2006-01-02 15:04"), formatDate(p.CreatedAt, "2006-01-02"))
 	}
-	fmt.Println()
+	if !p.AppliedAt.IsZero() {
+		out.Printf("Last applied: %s on %s\n", formatDate(p.AppliedAt, "2006-01-02 15:04"), orDash(p.AppliedOn))
+	}
+	out.Println()
 
 	if len(p.MCPServers) > 0 {
-		fmt.Println("MCP Servers:")
+		out.Println("MCP Servers:")
 		for _, m := range p.MCPServers {
-			fmt.Printf("  - %s (%s)\n", m.Name, m.Command)
+			out.Printf("  - %s (%s)%s\n", m.Name, m.Command, machineNote(m.Condition))
 			if len(m.Secrets) > 0 {
 				for envVar := range m.Secrets {
-					fmt.Printf("      requires: %s\n", envVar)
+					out.Printf("      requires: %s\n", envVar)
 				}
 			}
 		}
-		fmt.Println()
+		out.Println()
+	}
+
+	if len(p.ProvidedMCPServers) > 0 {
+		out.Println("MCP Servers from plugins (installed by the plugins, not by apply):")
+		for _, m := range p.ProvidedMCPServers {
+			out.Printf("  - %s (%s) from %s\n", m.Name, m.Command, m.Plugin)
+		}
+		out.Println()
 	}
 
 	if len(p.Marketplaces) > 0 {
-		fmt.Println("Marketplaces:")
+		out.Println("Marketplaces:")
 		for _, m := range p.Marketplaces {
-			fmt.Printf("  - %s\n", m.DisplayName())
+			out.Printf("  - %s\n", m.DisplayName())
 		}
-		fmt.Println()
+		out.Println()
 	}
 
 	if len(p.Plugins) > 0 {
-		fmt.Println("Plugins:")
+		out.Println("Plugins:")
 		for _, plug := range p.Plugins {
-			fmt.Printf("  - %s\n", plug)
+			out.Printf("  - %s%s\n", plug, machineNote(p.PluginConditions[plug]))
 		}
-		fmt.Println()
+		out.Println()
+	}
+
+	if len(p.Groups) > 0 {
+		out.Println("Plugin groups:")
+		for _, name := range p.GroupNames() {
+			state := "enabled"
+			if !p.GroupEnabled(name) {
+				state = "disabled"
+			}
+			out.Printf("  %s (%s)%s: %s\n", name, state, machineNote(p.GroupConditions[name]), strings.Join(p.Groups[name], ", "))
+		}
+		out.Println()
+	}
+
+	if p.SetupWizard != nil && len(p.SetupWizard.Categories) > 0 {
+		out.Println("Setup wizard (optional plugins chosen on apply, * by default):")
+		for _, c := range p.SetupWizard.Categories {
+			marker := " "
+			if c.Default {
+				marker = "*"
+			}
+			out.Printf("  %s %s: %s\n", marker, c.Name, strings.Join(c.Plugins, ", "))
+		}
+		out.Println()
+	}
+
+	if !p.Permissions.IsEmpty() {
+		out.Println("Permissions (pre-seeded on apply once you agree):")
+		for _, rule := range p.Permissions.Allow {
+			out.Printf("  - allow %s\n", rule)
+		}
+		for _, dir := range p.Permissions.AdditionalDirectories {
+			out.Printf("  - directory %s\n", dir)
+		}
+		if p.Permissions.TrustProject {
+			out.Println("  - trust the project it's applied in")
+		}
+		out.Println()
+	}
+
+	if len(p.Env) > 0 {
+		showEnvChecklist(out, p)
 	}
 
 	return nil
 }
 
 func hasDiffChanges(diff *profile.Diff) bool {
-	return len(diff.PluginsToRemove) > 0 ||
-		len(diff.PluginsToInstall) > 0 ||
-		len(diff.MCPToRemove) > 0 ||
-		len(diff.MCPToInstall) > 0 ||
-		len(diff.MarketplacesToAdd) > 0
+	return diff.Count() > 0
 }
 
-func showDiff(diff *profile.Diff) {
+func showDiff(out ui.Printer, diff *profile.Diff) {
 	if len(diff.PluginsToRemove) > 0 || len(diff.MCPToRemove) > 0 {
-		fmt.Println("  Remove:")
+		out.Println(i18n.T("  Remove:"))
 		for _, p := range diff.PluginsToRemove {
-			fmt.Printf("    - %s\n", p)
+			out.Printf("    - %s\n", p)
 		}
 		for _, m := range diff.MCPToRemove {
-			fmt.Printf("    - MCP: %s\n", m)
+			out.Printf("    - MCP: %s\n", m)
 		}
 	}
 
 	if len(diff.PluginsToInstall) > 0 || len(diff.MCPToInstall) > 0 || len(diff.MarketplacesToAdd) > 0 {
-		fmt.Println("  Install:")
+		out.Println(i18n.T("  Install:"))
 		for _, m := range diff.MarketplacesToAdd {
-			fmt.Printf("    + Marketplace: %s\n", m.DisplayName())
+			out.Printf("    + Marketplace: %s\n", m.DisplayName())
 		}
 		for _, p := range diff.PluginsToInstall {
-			fmt.Printf("    + %s\n", p)
+			out.Printf("    + %s\n", p)
 		}
 		for _, m := range diff.MCPToInstall {
 			secretInfo := ""
 			if len(m.Secrets) > 0 {
 				for k := range m.Secrets {
-					secretInfo = fmt.Sprintf(" (requires %s)", k)
+					secretInfo = fmt.Sprintf(i18n.T(" (requires %s)"), k)
 					break
 				}
 			}
-			fmt.Printf("    + MCP: %s%s\n", m.Name, secretInfo)
+			out.Printf("    + MCP: %s%s\n", m.Name, secretInfo)
+		}
+	}
+
+	if len(diff.PluginsToDisable) > 0 || len(diff.MCPToDisable) > 0 {
+		out.Println(i18n.T("  Disable:"))
+		for _, p := range diff.PluginsToDisable {
+			out.Printf("    ✗ %s\n", p)
+		}
+		for _, m := range diff.MCPToDisable {
+			out.Printf("    ✗ MCP: %s\n", m)
+		}
+	}
+
+	if len(diff.PluginsToEnable) > 0 || len(diff.MCPToEnable) > 0 {
+		out.Println(i18n.T("  Enable:"))
+		for _, p := range diff.PluginsToEnable {
+			out.Printf("    ✓ %s\n", p)
+		}
+		for _, m := range diff.MCPToEnable {
+			out.Printf("    ✓ MCP: %s\n", m)
 		}
 	}
+	showSkipped(out, diff)
+}
+
+// showSkipped lists the entries a diff left out because this machine
+// doesn't meet their conditions
+func showSkipped(out ui.Printer, diff *profile.Diff) {
+	if len(diff.Skipped) == 0 {
+		return
+	}
+	out.Println(i18n.T("  Skipped (condition not met):"))
+	for _, s := range diff.Skipped {
+		kind := "MCP"
+		switch s.Kind {
+		case "plugin":
+			kind = "Plugin"
+		case "group":
+			kind = "Group"
+		}
+		out.Printf("    ○ %s: %s [%s]\n", kind, s.Name, s.Condition)
+	}
 }
 
 func runProfileSuggest(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
 	profilesDir := getProfilesDir()
 
 	// Get current directory
@@ -429,51 +949,208 @@ func runProfileSuggest(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get current directory: %w", err)
 	}
 
+	// Workspace mappings are explicit, so they win over file-based detection
+	var suggested *profile.Profile
+	if w, ok := currentWorkspace(); ok {
+		if p, err := loadProfileWithFallback(profilesDir, w.Profile); err == nil {
+			suggested = p
+			out.Printf("Workspace: %s\n", w.Path)
+		} else {
+			out.Printf("⚠ Workspace %s uses profile %q, which was not found\n", w.Path, w.Profile)
+		}
+	}
+
 	// Load all profiles
 	profiles, err := profile.List(profilesDir)
 	if err != nil {
 		return fmt.Errorf("failed to list profiles: %w", err)
 	}
 
-	if len(profiles) == 0 {
-		fmt.Println("No profiles available.")
-		fmt.Println("Create one with: claudeup profile save <name>")
+	if len(profiles) == 0 && suggested == nil {
+		if ui.Quiet() {
+			return fmt.Errorf("no profiles available")
+		}
+		out.Println("No profiles available.")
+		out.Println("Create one with: claudeup profile save <name>")
 		return nil
 	}
 
 	// Find matching profiles
-	suggested := profile.SuggestProfile(cwd, profiles)
+	if suggested == nil {
+		suggested = profile.SuggestProfile(cwd, profiles)
+	}
 
 	if suggested == nil {
-		fmt.Println("No profile matches the current directory.")
-		fmt.Println()
-		fmt.Println("Available profiles:")
+		if ui.Quiet() {
+			return fmt.Errorf("no profile matches the current directory")
+		}
+		out.Println("No profile matches the current directory.")
+		out.Println()
+		out.Println("Available profiles:")
 		for _, p := range profiles {
-			fmt.Printf("  - %s\n", p.Name)
+			out.Printf("  - %s\n", p.Name)
 		}
 		return nil
 	}
 
-	fmt.Printf("Suggested profile: %s\n", suggested.Name)
+	out.Printf("Suggested profile: %s\n", suggested.Name)
 	if suggested.Description != "" {
-		fmt.Printf("  %s\n", suggested.Description)
+		out.Printf("  %s\n", suggested.Description)
+	}
+	out.Println()
+
+	// With --quiet the exit code is the answer; only -y goes on to apply
+	if ui.Quiet() && !config.YesFlag {
+		return nil
 	}
-	fmt.Println()
 
-	fmt.Print("Apply this profile? [Y/n]: ")
-	choice := promptChoice("", "y")
+	out.Promptf("Apply this profile? [Y/n]: ")
+	choice := promptChoice(out, "", "y")
 	if choice == "y" || choice == "yes" || choice == "" {
 		// Run the use command
 		return runProfileUse(cmd, []string{suggested.Name})
 	}
 
-	fmt.Println("Cancelled.")
+	out.Println("Cancelled.")
 	return nil
 }
 
 // loadProfileWithFallback tries to load a profile from disk first,
 // falling back to embedded profiles if not found
+// listedProfile is a profile shown by 'profile list'
+type listedProfile struct {
+	*profile.Profile
+	builtIn bool
+}
+
+// filterByTags keeps the profiles that have every tag
+func filterByTags(profiles []listedProfile, tags []string) []listedProfile {
+	if len(tags) == 0 {
+		return profiles
+	}
+	var kept []listedProfile
+	for _, p := range profiles {
+		ok := true
+		for _, tag := range tags {
+			ok = ok && p.HasTag(tag)
+		}
+		if ok {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// showProfilesLong lists profiles as a table with their metadata
+func showProfilesLong(out ui.Printer, profiles []listedProfile, activeProfile string) {
+	w := tabwriter.NewWriter(out.Out(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "  NAME\tTAGS\tAUTHOR\tCREATED\tUPDATED\tLAST APPLIED")
+	for _, p := range profiles {
+		marker := "  "
+		if p.Name == activeProfile {
+			marker = "* "
+		}
+		author := p.Author
+		if p.builtIn {
+			author = "(built-in)"
+		}
+		applied := formatDate(p.AppliedAt, "2006-01-02 15:04")
+		if p.AppliedOn != "" && !p.AppliedAt.IsZero() {
+			applied += " on " + p.AppliedOn
+		}
+		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\n", marker, p.Name+addonTag(p.Profile),
+			orDash(strings.Join(p.Tags, ",")), orDash(author),
+			formatDate(p.CreatedAt, "2006-01-02"), formatDate(p.UpdatedAt, "2006-01-02"), applied)
+	}
+	w.Flush()
+}
+
+// formatDate formats t in local time, or "-" when it isn't set
+func formatDate(t time.Time, layout string) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(layout)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// machineNote describes the machines an entry is limited to, and whether
+// this is one of them
+func machineNote(c profile.Condition) string {
+	switch {
+	case c.IsZero():
+		return ""
+	case c.Matches(profile.ThisMachine()):
+		return " [" + c.String() + "]"
+	default:
+		return " [" + c.String() + "; not this machine]"
+	}
+}
+
+func addonTag(p *profile.Profile) string {
+	if p.IsAddon() {
+		return " [addon]"
+	}
+	return ""
+}
+
+// loadProfileWithAddons loads the base profile and +addon profiles named in
+// args and merges them. A single argument loads that profile unchanged.
+func loadProfileWithAddons(profilesDir string, args []string) (*profile.Profile, error) {
+	return loadProfileOnto(profilesDir, nil, args)
+}
+
+// loadProfileOnto is loadProfileWithAddons with the base profile, if any,
+// already loaded, as it applies on this machine. See ForThisMachine.
+func loadProfileOnto(profilesDir string, base *profile.Profile, args []string) (*profile.Profile, error) {
+	base, addons, err := loadProfileArgs(profilesDir, base, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(addons) == 0 {
+		return base.ForThisMachine()
+	}
+	merged, err := profile.Merge(base, addons...)
+	if err != nil {
+		return nil, err
+	}
+	return merged.ForThisMachine()
+}
+
+// loadProfileArgs loads the base profile and the +addons named in args,
+// without combining them
+func loadProfileArgs(profilesDir string, base *profile.Profile, args []string) (*profile.Profile, []*profile.Profile, error) {
+	var addons []*profile.Profile
+	for _, arg := range args {
+		name, isAddon := strings.CutPrefix(arg, "+")
+		p, err := loadProfileWithFallback(profilesDir, name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("profile %q not found: %w", name, err)
+		}
+		if isAddon {
+			addons = append(addons, p)
+			continue
+		}
+		if base != nil {
+			return nil, nil, fmt.Errorf("only one base profile can be used (got %q and %q); prefix addons with +", base.Name, name)
+		}
+		base = p
+	}
+	return base, addons, nil
+}
+
 func loadProfileWithFallback(profilesDir, name string) (*profile.Profile, error) {
+	name, err := resolveProfileName(profilesDir, name)
+	if err != nil {
+		return nil, err
+	}
+
 	// Try disk first
 	p, err := profile.Load(profilesDir, name)
 	if err == nil {
@@ -484,6 +1161,16 @@ func loadProfileWithFallback(profilesDir, name string) (*profile.Profile, error)
 	return profile.GetEmbeddedProfile(name)
 }
 
+// resolveProfileName finds the saved profile a name refers to, searching
+// the namespaces in the profileNamespaces preference for a bare name
+func resolveProfileName(profilesDir, name string) (string, error) {
+	var precedence []string
+	if cfg, err := config.LoadExisting(); err == nil {
+		precedence = cfg.Preferences.ProfileNamespaces
+	}
+	return profile.ResolveName(profilesDir, name, precedence)
+}
+
 // getAllProfiles returns all available profiles (user + embedded), with user profiles taking precedence
 func getAllProfiles(profilesDir string) ([]*profile.Profile, error) {
 	// Load user profiles
@@ -518,7 +1205,7 @@ func getAllProfiles(profilesDir string) ([]*profile.Profile, error) {
 }
 
 // promptProfileSelection displays an interactive menu to select a profile
-func promptProfileSelection(profilesDir, newName string) (*profile.Profile, error) {
+func promptProfileSelection(out ui.Printer, profilesDir, newName string) (*profile.Profile, error) {
 	profiles, err := getAllProfiles(profilesDir)
 	if err != nil {
 		return nil, err
@@ -528,17 +1215,17 @@ func promptProfileSelection(profilesDir, newName string) (*profile.Profile, erro
 		return nil, fmt.Errorf("no profiles available to copy from")
 	}
 
-	fmt.Printf("\nWhich profile should %q be based on?\n\n", newName)
+	out.Printf("\nWhich profile should %q be based on?\n\n", newName)
 	for i, p := range profiles {
 		desc := p.Description
 		if desc == "" {
 			desc = "(no description)"
 		}
-		fmt.Printf("  %d) %-20s %s\n", i+1, p.Name, desc)
+		out.Printf("  %d) %-20s %s\n", i+1, p.Name, desc)
 	}
-	fmt.Println()
+	out.Println()
 
-	fmt.Print("Enter number or name: ")
+	out.Promptf("Enter number or name: ")
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
 	if err != nil {
@@ -570,8 +1257,15 @@ func promptProfileSelection(profilesDir, newName string) (*profile.Profile, erro
 }
 
 func runProfileCreate(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
 	name := args[0]
 	profilesDir := getProfilesDir()
+	if err := profile.ValidateName(name); err != nil {
+		return err
+	}
 
 	// Check if target profile already exists
 	existingPath := filepath.Join(profilesDir, name+".json")
@@ -599,10 +1293,10 @@ func runProfileCreate(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("active profile %q not found: %w", cfg.Preferences.ActiveProfile, err)
 		}
-		fmt.Printf("Using active profile: %s\n", cfg.Preferences.ActiveProfile)
+		out.Printf("Using active profile: %s\n", cfg.Preferences.ActiveProfile)
 	} else {
 		// Interactive selection
-		sourceProfile, err = promptProfileSelection(profilesDir, name)
+		sourceProfile, err = promptProfileSelection(out, profilesDir, name)
 		if err != nil {
 			return err
 		}
@@ -616,46 +1310,242 @@ func runProfileCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to save profile: %w", err)
 	}
 
-	fmt.Printf("✓ Created profile %q (based on %q)\n", name, sourceProfile.Name)
-	fmt.Println()
-	fmt.Printf("  MCP Servers:   %d\n", len(newProfile.MCPServers))
-	fmt.Printf("  Marketplaces:  %d\n", len(newProfile.Marketplaces))
-	fmt.Printf("  Plugins:       %d\n", len(newProfile.Plugins))
+	out.Printf("✓ Created profile %q (based on %q)\n", name, sourceProfile.Name)
+	out.Println()
+	out.Printf("  MCP Servers:   %d\n", len(newProfile.MCPServers))
+	out.Printf("  Marketplaces:  %d\n", len(newProfile.Marketplaces))
+	out.Printf("  Plugins:       %d\n", len(newProfile.Plugins))
 
 	return nil
 }
 
 func runProfileCurrent(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+
 	// Use same pattern as runStatus - gracefully handle missing config
 	cfg, _ := config.Load()
-	activeProfile := ""
-	if cfg != nil {
-		activeProfile = cfg.Preferences.ActiveProfile
-	}
+	activeProfile, project := activeProfileHere(cfg)
 
 	if activeProfile == "" {
-		fmt.Println("No profile is currently active.")
-		fmt.Println("Use 'claudeup profile use <name>' to apply a profile.")
+		out.Println("No profile is currently active.")
+		out.Println("Use 'claudeup profile use <name>' to apply a profile.")
 		return nil
 	}
+	scope := ""
+	if project != "" {
+		scope = fmt.Sprintf(" (for %s)", project)
+	}
 
 	// Load the profile to show details
 	profilesDir := getProfilesDir()
 	p, err := loadProfileWithFallback(profilesDir, activeProfile)
 	if err != nil {
 		// Profile was set but can't be loaded - show name and error
-		fmt.Printf("Current profile: %s (details unavailable: %v)\n", activeProfile, err)
+		out.Printf("Current profile: %s%s (details unavailable: %v)\n", activeProfile, scope, err)
 		return nil
 	}
 
-	fmt.Printf("Current profile: %s\n", p.Name)
+	out.Printf("Current profile: %s%s\n", p.Name, scope)
 	if p.Description != "" {
-		fmt.Printf("  %s\n", p.Description)
+		out.Printf("  %s\n", p.Description)
+	}
+	out.Println()
+	out.Printf("  Marketplaces: %d\n", len(p.Marketplaces))
+	out.Printf("  Plugins:      %d\n", len(p.Plugins))
+	out.Printf("  MCP Servers:  %d\n", len(p.MCPServers))
+	if project != "" {
+		out.Printf("  Global:       %s\n", globalProfileName(cfg))
+	}
+
+	pinned := os.Getenv(profileEnvVar)
+	if resolved, _ := resolveProfileName(profilesDir, pinned); pinned != "" && resolved != p.Name {
+		out.Println()
+		out.Printf("⚠ This directory uses the %s profile; run 'claudeup profile use %s'\n", pinned, pinned)
+	}
+
+	return nil
+}
+
+// recordApplyFrom appends a profile apply to the history log, notifies on
+// failure, sends the outcome to webhooks, and exports the trace started by
+// applyStarting in ctx
+// All of it is best-effort and never fails the command
+func recordApplyFrom(ctx context.Context, out ui.Printer, source, name string, diff *profile.Diff, result *profile.ApplyResult, applyErr error) {
+	entry := history.Entry{Action: "apply", Profile: name, Source: source, Changes: diff.Count()}
+	if applyErr != nil {
+		entry.Error = applyErr.Error()
+	} else if result != nil && len(result.Errors) > 0 {
+		entry.Error = fmt.Sprintf("%d errors", len(result.Errors))
+	}
+	if result != nil {
+		entry.Failed = historyFailures(result)
+		entry.Done = historyChanges(result)
+		entry.Elapsed = result.Elapsed.Milliseconds()
+	}
+	history.Append(history.DefaultPath(), entry)
+
+	event := webhook.Event{Event: webhook.EventApplySuccess, Profile: name, Source: source, Changes: entry.Changes, Failed: entry.Failed, Error: entry.Error}
+	if entry.Error != "" {
+		event.Event = webhook.EventApplyFailed
+		sendNotification(out, notify.ApplyFailed(name, source, entry.Error))
+	}
+	// Sent even when the apply was interrupted
+	ctx = context.WithoutCancel(ctx)
+	sendWebhook(ctx, out, event)
+
+	var traceErr error
+	if entry.Error != "" {
+		traceErr = errors.New(entry.Error)
+	}
+	telemetry.SpanFrom(ctx).SetAttributes(telemetry.Int("failed", len(entry.Failed)))
+	exportTrace(ctx, out, traceErr)
+}
+
+// resolveSaveConflicts shows each conflicting section three ways and asks
+// whether to keep the on-disk version or take the current state. Returns
+// the sections to keep from disk, or false if the user cancels.
+func resolveSaveConflicts(out ui.Printer, name string, conflicts []profile.SectionConflict) (map[profile.Section]bool, bool) {
+	out.Printf("⚠ Profile %q was edited on disk since it was last applied\n", name)
+	out.Println()
+
+	keepDisk := make(map[profile.Section]bool)
+	for _, c := range conflicts {
+		out.Printf("━━━ %s ━━━\n", c.Section.Title())
+		out.Println("  On disk (since last apply):")
+		showSectionChanges(out, c.Disk)
+		out.Println("  Current state (since last apply):")
+		showSectionChanges(out, c.Current)
+
+		for {
+			choice := strings.ToLower(promptChoice(out, "  Keep [d]isk, take [c]urrent, or [q]uit?", "c"))
+			switch choice {
+			case "d", "disk":
+				keepDisk[c.Section] = true
+			case "c", "current":
+			case "q", "quit":
+				return nil, false
+			default:
+				out.Println("  Please answer d, c, or q")
+				continue
+			}
+			break
+		}
+		out.Println()
+	}
+	return keepDisk, true
+}
+
+func showSectionChanges(out ui.Printer, c profile.SectionChanges) {
+	if c.Empty() {
+		out.Println("    (unchanged)")
+		return
+	}
+	for _, item := range c.Added {
+		out.Printf("    + %s\n", item)
+	}
+	for _, item := range c.Removed {
+		out.Printf("    - %s\n", item)
+	}
+	for _, item := range c.Modified {
+		out.Printf("    ~ %s\n", item)
 	}
-	fmt.Println()
-	fmt.Printf("  Marketplaces: %d\n", len(p.Marketplaces))
-	fmt.Printf("  Plugins:      %d\n", len(p.Plugins))
-	fmt.Printf("  MCP Servers:  %d\n", len(p.MCPServers))
+}
+
+// getAppliedDir returns where copies of the last-applied profiles are kept
+func getAppliedDir() string {
+	return pathctx.Default().Claudeup("applied")
+}
 
+// recordAppliedProfile keeps a copy of p as the base for detecting on-disk
+// edits in 'profile save'. Best-effort; addon combinations are not recorded.
+func recordAppliedProfile(out ui.Printer, p *profile.Profile) {
+	if p == nil || p.IsAddon() || strings.Contains(p.Name, "+") {
+		return
+	}
+	if err := profile.SaveRecord(getAppliedDir(), p); err != nil {
+		out.Warnf("  Warning: could not record applied profile: %v\n", err)
+	}
+	recordContentHash(out, p)
+}
+
+// stampApplied records the apply in the metadata of each saved profile in
+// name, which may list addons as +addon
+func stampApplied(out ui.Printer, name string) {
+	profilesDir := getProfilesDir()
+	for _, arg := range strings.Fields(name) {
+		resolved, err := resolveProfileName(profilesDir, strings.TrimPrefix(arg, "+"))
+		if err == nil {
+			err = profile.RecordApplied(profilesDir, resolved)
+		}
+		if err != nil {
+			out.Warnf("  Warning: could not record apply in profile %s: %v\n", arg, err)
+		}
+	}
+}
+
+// recordActiveProfile saves name as the active profile after 'profile use':
+// for the current directory with --local, otherwise globally. A global
+// switch remembers the profile it replaced, and before, the ID of the
+// snapshot taken before switching, for 'profile back'. Returns why the
+// config couldn't be saved.
+func recordActiveProfile(out ui.Printer, name, before string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	cwd, cwdErr := os.Getwd()
+	switch {
+	case profileUseLocal && cwdErr != nil:
+		err = fmt.Errorf("current directory unknown: %w", cwdErr)
+	case profileUseLocal:
+		cfg.SetProjectProfile(cwd, name)
+		if err = config.Save(cfg); err == nil {
+			out.Printf("  → Active in %s; the global profile is still %s\n", cwd, globalProfileName(cfg))
+		}
+	default:
+		if active := cfg.Preferences.ActiveProfile; active != "" && active != name {
+			recordPreviousProfile(cfg, active, before)
+		}
+		cfg.Preferences.ActiveProfile = name
+		if err = config.Save(cfg); err == nil && cwdErr == nil {
+			if local, project := cfg.ActiveProfileFor(cwd); project != "" && local != name {
+				out.Printf("  ⚠ %s still uses %s (set with --local); run 'claudeup profile use %s --local' to change it\n", project, local, name)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("could not save the active profile: %w", err)
+	}
 	return nil
 }
+
+// activeProfileHere returns the profile active in the current directory,
+// and the project it was set for with --local, or "" for the global one
+func activeProfileHere(cfg *config.GlobalConfig) (name, project string) {
+	if cfg == nil {
+		return "", ""
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return cfg.Preferences.ActiveProfile, ""
+	}
+	return cfg.ActiveProfileFor(cwd)
+}
+
+// globalProfileName returns the global active profile, or "none"
+func globalProfileName(cfg *config.GlobalConfig) string {
+	if cfg.Preferences.ActiveProfile == "" {
+		return "none"
+	}
+	return cfg.Preferences.ActiveProfile
+}
+
+// setActiveProfile records name as the active profile in the global config
+func setActiveProfile(name string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	cfg.Preferences.ActiveProfile = name
+	return config.Save(cfg)
+}
@@ -8,7 +8,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
 	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
 )
 
 func TestLoadProfileWithFallback_LoadsFromDiskFirst(t *testing.T) {
@@ -97,6 +100,34 @@ func TestLoadProfileWithFallback_PrefersDiskOverEmbedded(t *testing.T) {
 	}
 }
 
+func TestLoadProfileWithFallback_ResolvesNamespaces(t *testing.T) {
+	defer pathctx.Override(pathctx.ForHome(t.TempDir()))()
+	profilesDir := t.TempDir()
+	for _, name := range []string{"team/backend", "personal/backend"} {
+		if err := profile.Save(profilesDir, &profile.Profile{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := loadProfileWithFallback(profilesDir, "backend"); err == nil || !strings.Contains(err.Error(), "several namespaces") {
+		t.Errorf("Expected an ambiguous name without a precedence, got %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Preferences.ProfileNamespaces = []string{"personal", "team"}
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	p, err := loadProfileWithFallback(profilesDir, "backend")
+	if err != nil || p.Name != "personal/backend" {
+		t.Errorf("Expected personal/backend by precedence, got %v, %v", p, err)
+	}
+	p, err = loadProfileWithFallback(profilesDir, "team/backend")
+	if err != nil || p.Name != "team/backend" {
+		t.Errorf("Expected the namespaced name as given, got %v, %v", p, err)
+	}
+}
+
 func TestPromptProfileSelection_ReturnsErrorOnEmptyInput(t *testing.T) {
 	tmpDir := t.TempDir()
 	profilesDir := filepath.Join(tmpDir, "profiles")
@@ -127,7 +158,7 @@ func TestPromptProfileSelection_ReturnsErrorOnEmptyInput(t *testing.T) {
 	defer func() { os.Stdin = oldStdin }()
 
 	// Call promptProfileSelection - should return error for empty input
-	_, err = promptProfileSelection(profilesDir, "new-profile")
+	_, err = promptProfileSelection(ui.Default(), profilesDir, "new-profile")
 	if err == nil {
 		t.Error("Expected error for empty input, got nil")
 	}
@@ -176,7 +207,7 @@ func TestPromptProfileSelection_ReturnsErrorOnInvalidNumber(t *testing.T) {
 			os.Stdin = r
 			defer func() { os.Stdin = oldStdin }()
 
-			_, err = promptProfileSelection(profilesDir, "new-profile")
+			_, err = promptProfileSelection(ui.Default(), profilesDir, "new-profile")
 			if err == nil {
 				t.Errorf("Expected error for input %q, got nil", tt.input)
 				return
@@ -215,7 +246,7 @@ func TestPromptProfileSelection_ReturnsErrorOnInvalidName(t *testing.T) {
 	os.Stdin = r
 	defer func() { os.Stdin = oldStdin }()
 
-	_, err = promptProfileSelection(profilesDir, "new-profile")
+	_, err = promptProfileSelection(ui.Default(), profilesDir, "new-profile")
 	if err == nil {
 		t.Error("Expected error for nonexistent profile name, got nil")
 		return
@@ -251,7 +282,7 @@ func TestPromptProfileSelection_ReturnsErrorOnIOError(t *testing.T) {
 	os.Stdin = r
 	defer func() { os.Stdin = oldStdin }()
 
-	_, err = promptProfileSelection(profilesDir, "new-profile")
+	_, err = promptProfileSelection(ui.Default(), profilesDir, "new-profile")
 	if err == nil {
 		t.Error("Expected error for EOF, got nil")
 		return
@@ -261,3 +292,106 @@ func TestPromptProfileSelection_ReturnsErrorOnIOError(t *testing.T) {
 		t.Errorf("Expected error containing 'failed to read input', got %q", err.Error())
 	}
 }
+
+func TestFilterByTags(t *testing.T) {
+	profiles := []listedProfile{
+		{Profile: &profile.Profile{Name: "api", Tags: []string{"backend", "go"}}},
+		{Profile: &profile.Profile{Name: "web", Tags: []string{"frontend"}}},
+		{Profile: &profile.Profile{Name: "default"}, builtIn: true},
+	}
+
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{nil, "api,web,default"},
+		{[]string{"backend"}, "api"},
+		{[]string{"Backend", "go"}, "api"},
+		{[]string{"backend", "frontend"}, ""},
+	}
+	for _, tt := range tests {
+		var names []string
+		for _, p := range filterByTags(profiles, tt.tags) {
+			names = append(names, p.Name)
+		}
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("filterByTags(%v) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestProvidedMCPServers(t *testing.T) {
+	claudeDir := t.TempDir()
+	pluginDir := filepath.Join(t.TempDir(), "db")
+	os.MkdirAll(filepath.Join(pluginDir, ".claude-plugin"), 0755)
+	os.WriteFile(filepath.Join(pluginDir, ".claude-plugin", "plugin.json"), []byte(`{
+		"name": "db",
+		"mcpServers": {
+			"pg": {"command": "pg-mcp", "args": ["--ro"], "env": {"PGPASSWORD": "secret", "PGHOST": "localhost"}},
+			"admin": {"command": "pg-admin"}
+		}
+	}`), 0644)
+	os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755)
+	registry := `{"version": 2, "plugins": {"db@m": [{"scope": "user", "installPath": "` + pluginDir + `", "version": "1"}]}}`
+	os.WriteFile(filepath.Join(claudeDir, "plugins", "installed_plugins.json"), []byte(registry), 0644)
+
+	provided, err := providedMCPServers(claudeDir)
+	if err != nil {
+		t.Fatalf("providedMCPServers failed: %v", err)
+	}
+	if len(provided) != 2 {
+		t.Fatalf("Expected 2 servers, got %d", len(provided))
+	}
+	if provided[0].Name != "admin" || provided[1].Name != "pg" {
+		t.Errorf("Expected servers sorted by name, got %s, %s", provided[0].Name, provided[1].Name)
+	}
+	pg := provided[1]
+	if pg.Plugin != "db@m" || pg.Command != "pg-mcp" {
+		t.Errorf("Unexpected server: %+v", pg)
+	}
+	if strings.Join(pg.Env, ",") != "PGHOST,PGPASSWORD" {
+		t.Errorf("Expected sorted env names only, got %v", pg.Env)
+	}
+
+	none, err := providedMCPServers(t.TempDir())
+	if err != nil || none != nil {
+		t.Errorf("Expected nothing without a plugin registry, got %v, %v", none, err)
+	}
+}
+
+func TestRecordActiveProfileLocal(t *testing.T) {
+	defer pathctx.Override(pathctx.ForHome(t.TempDir()))()
+	t.Chdir(t.TempDir())
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := profileUseLocal
+	t.Cleanup(func() { profileUseLocal = saved })
+	var buf strings.Builder
+	out := ui.NewPrinter(&buf, &buf, false)
+
+	profileUseLocal = true
+	recordActiveProfile(out, "frontend", "")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, project := activeProfileHere(cfg); name != "frontend" || project != cwd {
+		t.Errorf("Expected frontend for %s, got %s for %q", cwd, name, project)
+	}
+	if cfg.Preferences.ActiveProfile != "" {
+		t.Errorf("Expected --local to leave the global profile unset, got %s", cfg.Preferences.ActiveProfile)
+	}
+
+	// A global apply here leaves the project's entry and says so
+	profileUseLocal = false
+	recordActiveProfile(out, "backend", "")
+	cfg, _ = config.Load()
+	if name, _ := activeProfileHere(cfg); name != "frontend" || cfg.Preferences.ActiveProfile != "backend" {
+		t.Errorf("Expected frontend here and backend globally, got %s and %s", name, cfg.Preferences.ActiveProfile)
+	}
+	if !strings.Contains(buf.String(), "still uses frontend") {
+		t.Errorf("Expected a note about the project's profile, got %q", buf.String())
+	}
+}
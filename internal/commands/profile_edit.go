@@ -0,0 +1,270 @@
+// ABOUTME: profile edit opens a profile in $EDITOR and saves it only once it validates
+// ABOUTME: Shows the problems and offers to edit again, then shows a line diff before writing the file
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var profileEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Edit a profile in your editor, validating it before saving",
+	Long: `Opens the profile's file in $VISUAL or $EDITOR (vi, or notepad on Windows)
+and checks it when the editor exits, as 'claudeup ci check' does. If it has
+errors, they're listed with their lines and you can edit it again; nothing
+is written until it validates. Warnings are shown but don't stop the save.
+
+Before saving, the changes are shown as a line diff. The file is written as
+you edited it, and the content it replaces is kept for 'claudeup profile
+undo'. If the profile was saved by something else while you were editing,
+nothing is written and your edit is left in its temporary file. Editing a
+built-in profile saves your copy to the profiles directory.`,
+	Example: `  claudeup profile edit backend
+  EDITOR="code --wait" claudeup profile edit team/backend`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProfileEdit,
+}
+
+func init() {
+	profileCmd.AddCommand(profileEditCmd)
+}
+
+// runEditor opens path in the user's editor and waits for it to exit;
+// tests replace it
+var runEditor = func(ctx context.Context, path string) error {
+	editor := strings.Fields(os.Getenv("VISUAL"))
+	if len(editor) == 0 {
+		editor = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+		if runtime.GOOS == "windows" {
+			editor = []string{"notepad"}
+		}
+	}
+	c := exec.CommandContext(ctx, editor[0], append(editor[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+	return nil
+}
+
+func runProfileEdit(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	profilesDir := getProfilesDir()
+	name, err := resolveProfileName(profilesDir, args[0])
+	if err != nil {
+		return err
+	}
+	if err := profile.ValidateName(name); err != nil {
+		return err
+	}
+
+	path := filepath.Join(profilesDir, filepath.FromSlash(name)+".json")
+	original, err := os.ReadFile(path)
+	builtIn := errors.Is(err, fs.ErrNotExist)
+	if builtIn {
+		embedded, embeddedErr := profile.GetEmbeddedProfile(name)
+		if embeddedErr != nil {
+			return fmt.Errorf("profile %q not found; create it with 'claudeup profile create %s'", name, name)
+		}
+		if original, err = json.MarshalIndent(embedded, "", "  "); err != nil {
+			return err
+		}
+		original = append(original, '\n')
+		out.Printf("%s is built in; saving will keep your copy in %s\n", name, profilesDir)
+	} else if err != nil {
+		return fmt.Errorf("failed to read profile %q: %w", name, err)
+	}
+
+	tmp, err := os.CreateTemp("", "claudeup-"+strings.ReplaceAll(name, "/", "-")+"-*.json")
+	if err != nil {
+		return err
+	}
+	keepEdit := false
+	defer func() {
+		if !keepEdit {
+			os.Remove(tmp.Name())
+		}
+	}()
+	_, err = tmp.Write(original)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := runEditor(cmd.Context(), tmp.Name()); err != nil {
+			return err
+		}
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(edited, original) {
+			out.Println("No changes.")
+			return nil
+		}
+
+		problems := editProblems(cmd.Context(), name, edited)
+		errorCount := 0
+		for _, p := range problems {
+			if p.Severity == profile.ProblemError {
+				errorCount++
+			}
+		}
+		if len(problems) > 0 {
+			showCICheck(out, ciCheckReport{File: name, Errors: errorCount, Warnings: len(problems) - errorCount, Problems: problems})
+			out.Println()
+		}
+		if errorCount > 0 {
+			if config.YesFlag {
+				return fmt.Errorf("profile %q has %d errors; nothing was saved", name, errorCount)
+			}
+			if choice := promptChoice(out, "Edit again? (y/n)", "y"); choice != "y" && choice != "yes" {
+				out.Println("Discarded your changes; nothing was saved.")
+				return nil
+			}
+			continue
+		}
+
+		out.Println("━━━ Changes ━━━")
+		for _, line := range lineDiff(string(original), string(edited)) {
+			out.Println(line)
+		}
+		out.Println()
+		if !config.YesFlag {
+			switch choice := promptChoice(out, fmt.Sprintf("Save changes to %s? (y)es, (e)dit again, (n)o", name), "n"); choice {
+			case "y", "yes":
+			case "e", "edit":
+				continue
+			default:
+				out.Println("Discarded your changes; nothing was saved.")
+				return nil
+			}
+		}
+
+		// Don't overwrite a save made while the editor was open
+		current, err := os.ReadFile(path)
+		changed := err != nil || !bytes.Equal(current, original)
+		if builtIn {
+			changed = !errors.Is(err, fs.ErrNotExist)
+		}
+		if changed {
+			keepEdit = true
+			return fmt.Errorf("profile %q changed while you were editing it; nothing was saved, and your edit is in %s", name, tmp.Name())
+		}
+		if err := profile.SaveDocument(profilesDir, name, edited); err != nil {
+			return fmt.Errorf("failed to save profile %q: %w", name, err)
+		}
+		out.Printf("✓ Saved profile %s\n", name)
+		out.Printf("  → Undo with 'claudeup profile undo %s'\n", name)
+		if cfg, err := config.LoadExisting(); err == nil && cfg.Preferences.ActiveProfile == name {
+			out.Printf("  → Apply it with 'claudeup profile use %s'\n", name)
+		}
+		return nil
+	}
+}
+
+// editProblems validates an edited profile document. A profile outside a
+// namespace must keep its name, since it's loaded by its file's name.
+func editProblems(ctx context.Context, name string, data []byte) []profile.Problem {
+	p, problems := profile.ValidateDocument(ctx, data, profile.ValidateOptions{})
+	if p != nil && profile.Namespace(name) == "" && p.Name != name {
+		problems = append(problems, profile.Problem{
+			Severity: profile.ProblemError,
+			Message:  fmt.Sprintf("name is %q, but must stay %q to match the profile's file", p.Name, name),
+		})
+	}
+	return problems
+}
+
+// diffContext is how many unchanged lines lineDiff shows around a change
+const diffContext = 2
+
+// lineDiff compares before and after line by line, returning the changed
+// lines marked - and +, with a few unchanged lines around each change and
+// a header giving the line in after where each group starts
+func lineDiff(before, after string) []string {
+	a := strings.Split(strings.TrimSuffix(before, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(after, "\n"), "\n")
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type op struct {
+		mark byte // ' ', '-', or '+'
+		text string
+		line int // line in after, or where a removed line was
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{' ', a[i], j + 1})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', a[i], j + 1})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j], j + 1})
+			j++
+		}
+	}
+
+	// Keep the unchanged lines near a change
+	show := make([]bool, len(ops))
+	for k, o := range ops {
+		if o.mark == ' ' {
+			continue
+		}
+		for c := max(0, k-diffContext); c <= min(len(ops)-1, k+diffContext); c++ {
+			show[c] = true
+		}
+	}
+	var lines []string
+	for k, o := range ops {
+		if !show[k] {
+			continue
+		}
+		if k == 0 || !show[k-1] {
+			lines = append(lines, fmt.Sprintf("  @@ line %d @@", o.line))
+		}
+		lines = append(lines, fmt.Sprintf("  %c %s", o.mark, o.text))
+	}
+	return lines
+}
@@ -0,0 +1,133 @@
+// ABOUTME: Tests for editing a profile in an editor with validation before saving
+// ABOUTME: Stubs the editor to check invalid edits aren't written, valid ones are kept for undo, saves made meanwhile survive, and the line diff
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// editProfileWith runs 'profile edit' with an editor that writes content
+func editProfileWith(t *testing.T, name, content string) (string, error) {
+	t.Helper()
+	defer func(editor func(context.Context, string) error) { runEditor = editor }(runEditor)
+	runEditor = func(_ context.Context, path string) error {
+		return os.WriteFile(path, []byte(content), 0644)
+	}
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetContext(ui.WithPrinter(context.Background(), ui.NewPrinter(&buf, &buf, false)))
+	err := runProfileEdit(cmd, []string{name})
+	return buf.String(), err
+}
+
+func TestProfileEditSavesValidChanges(t *testing.T) {
+	paths := pathctx.ForHome(t.TempDir())
+	defer pathctx.Override(paths)()
+	defer func() { config.YesFlag = false }()
+	config.YesFlag = true
+
+	if err := profile.Save(paths.ProfilesDir, &profile.Profile{Name: "backend", Plugins: []string{"a@m"}}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(paths.ProfilesDir, "backend.json")
+	original, _ := os.ReadFile(path)
+
+	invalid := `{"name": "backend", "plugins": ["a@m"], "plugin": ["typo@m"]}`
+	output, err := editProfileWith(t, "backend", invalid)
+	if err == nil || !strings.Contains(output, `unknown field "plugin"`) {
+		t.Errorf("Expected the unknown field reported and an error, got %v:\n%s", err, output)
+	}
+	if current, _ := os.ReadFile(path); !bytes.Equal(current, original) {
+		t.Errorf("Expected an invalid edit not to be written, got %s", current)
+	}
+
+	if _, err := editProfileWith(t, "backend", `{"name": "frontend"}`); err == nil {
+		t.Error("Expected renaming the profile in its file to be refused")
+	}
+
+	edited := "{\n  \"name\": \"backend\",\n  \"plugins\": [\"b@m\"]\n}\n"
+	output, err = editProfileWith(t, "backend", edited)
+	if err != nil {
+		t.Fatalf("Expected a valid edit to save: %v\n%s", err, output)
+	}
+	if current, _ := os.ReadFile(path); string(current) != edited {
+		t.Errorf("Expected the file written as edited, got %s", current)
+	}
+	if !strings.Contains(output, "━━━ Changes ━━━") {
+		t.Errorf("Expected the changes shown, got:\n%s", output)
+	}
+	if versions, _ := profile.Versions(paths.ProfilesDir, "backend"); len(versions) != 1 {
+		t.Errorf("Expected the replaced content kept for undo, got %v", versions)
+	}
+}
+
+func TestProfileEditKeepsSaveMadeWhileEditing(t *testing.T) {
+	paths := pathctx.ForHome(t.TempDir())
+	defer pathctx.Override(paths)()
+	defer func() { config.YesFlag = false }()
+	config.YesFlag = true
+
+	if err := profile.Save(paths.ProfilesDir, &profile.Profile{Name: "backend", Plugins: []string{"a@m"}}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(paths.ProfilesDir, "backend.json")
+	meanwhile := []byte(`{"name": "backend", "plugins": ["other@m"]}`)
+
+	defer func(editor func(context.Context, string) error) { runEditor = editor }(runEditor)
+	var tmp string
+	runEditor = func(_ context.Context, edit string) error {
+		tmp = edit
+		if err := os.WriteFile(path, meanwhile, 0644); err != nil {
+			return err
+		}
+		return os.WriteFile(edit, []byte(`{"name": "backend", "plugins": ["b@m"]}`), 0644)
+	}
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetContext(ui.WithPrinter(context.Background(), ui.NewPrinter(&buf, &buf, false)))
+	err := runProfileEdit(cmd, []string{"backend"})
+	defer os.Remove(tmp)
+
+	if err == nil || !strings.Contains(err.Error(), "changed while you were editing") {
+		t.Errorf("Expected the save to be refused, got %v", err)
+	}
+	if current, _ := os.ReadFile(path); !bytes.Equal(current, meanwhile) {
+		t.Errorf("Expected the save made meanwhile kept, got %s", current)
+	}
+	if kept, _ := os.ReadFile(tmp); !strings.Contains(string(kept), "b@m") {
+		t.Errorf("Expected the edit left in %s, got %q", tmp, kept)
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\n"
+	after := "a\nb\nc\nD\ne\nf\ng\nh\ni\nj\n"
+	want := []string{
+		"  @@ line 2 @@",
+		"    b",
+		"    c",
+		"  - d",
+		"  + D",
+		"    e",
+		"    f",
+		"  @@ line 8 @@",
+		"    h",
+		"    i",
+		"  + j",
+	}
+	if got := lineDiff(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,134 @@
+// ABOUTME: profile env subcommand exporting CLAUDEUP_PROFILE for the current directory
+// ABOUTME: --for-direnv makes it safe to eval from an .envrc, optionally applying the profile
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// profileEnvVar names the profile pinned to the current directory
+const profileEnvVar = "CLAUDEUP_PROFILE"
+
+var (
+	profileEnvShell     string
+	profileEnvForDirenv bool
+	profileEnvApply     bool
+)
+
+var profileEnvCmd = &cobra.Command{
+	Use:   "env [profile]",
+	Short: "Print exports for the current directory's profile",
+	Long: `Prints an export of CLAUDEUP_PROFILE followed by the profile's shellEnv
+variables and MCP server secrets, like 'claudeup env'.
+
+Without an argument the profile is chosen for the current directory the way
+'profile suggest' does: a workspace mapping first, then project detection.
+
+--for-direnv is meant for an .envrc (see 'claudeup direnv hook'): the output
+is always in bash syntax, and a missing or unknown profile is a warning
+rather than an error so the rest of the .envrc still loads. --apply also
+applies the profile without asking when it isn't already active; its
+progress goes to stderr.`,
+	Example: `  eval "$(claudeup profile env)"
+  eval "$(claudeup profile env --for-direnv --apply)"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runProfileEnv,
+}
+
+func init() {
+	profileCmd.AddCommand(profileEnvCmd)
+	profileEnvCmd.Flags().StringVar(&profileEnvShell, "shell", "", "Output syntax: sh, bash, zsh, fish, or powershell (default: from $SHELL)")
+	profileEnvCmd.Flags().BoolVar(&profileEnvForDirenv, "for-direnv", false, "Print bash exports for an .envrc and never fail")
+	profileEnvCmd.Flags().BoolVar(&profileEnvApply, "apply", false, "Apply the profile if it isn't already active")
+	addForceFlag(profileEnvCmd)
+}
+
+func runProfileEnv(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	err := exportDirectoryProfile(cmd, out, args)
+	if err != nil && profileEnvForDirenv {
+		// A failing eval would leave the rest of the .envrc unloaded
+		out.Warnf("⚠ claudeup: %v\n", err)
+		return nil
+	}
+	return err
+}
+
+func exportDirectoryProfile(cmd *cobra.Command, out ui.Printer, args []string) error {
+	profilesDir := getProfilesDir()
+	var p *profile.Profile
+	if len(args) > 0 {
+		loaded, err := loadProfileWithFallback(profilesDir, args[0])
+		if err != nil {
+			return fmt.Errorf("profile %q not found: %w", args[0], err)
+		}
+		p = loaded
+	} else {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		if p, err = profileForDir(profilesDir, cwd); err != nil {
+			return err
+		}
+		if p == nil {
+			return fmt.Errorf("no profile matches %s; pass a profile name or run 'claudeup workspace add'", cwd)
+		}
+	}
+
+	shell := profileEnvShell
+	if profileEnvForDirenv {
+		shell = "bash"
+	} else if shell == "" {
+		shell = defaultShell()
+	}
+
+	if profileEnvApply {
+		if err := applyIfInactive(cmd, p.Name); err != nil {
+			out.Warnf("⚠ Failed to apply %s: %v\n", p.Name, err)
+		}
+	}
+
+	fmt.Println(formatExport(shell, profileEnvVar, p.Name))
+	return printProfileEnv(out, p, shell)
+}
+
+// profileForDir returns the profile for dir from its workspace mapping or,
+// failing that, project detection. Returns nil if nothing matches.
+func profileForDir(profilesDir, dir string) (*profile.Profile, error) {
+	if cfg, err := config.LoadExisting(); err == nil {
+		if w, ok := cfg.WorkspaceFor(dir); ok {
+			p, err := loadProfileWithFallback(profilesDir, w.Profile)
+			if err != nil {
+				return nil, fmt.Errorf("workspace %s uses profile %q, which was not found", w.Path, w.Profile)
+			}
+			return p, nil
+		}
+	}
+
+	profiles, err := profile.List(profilesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	return profile.SuggestProfile(dir, profiles), nil
+}
+
+// applyIfInactive applies a profile without asking unless it's already the
+// active one. Progress goes to stderr so stdout stays evaluable.
+func applyIfInactive(cmd *cobra.Command, name string) error {
+	if cfg, err := config.LoadExisting(); err == nil {
+		if active, _ := activeProfileHere(cfg); active == name {
+			return nil
+		}
+	}
+	config.YesFlag = true
+	stderr := ui.NewPrinter(os.Stderr, os.Stderr, ui.Quiet())
+	cmd.SetContext(ui.WithPrinter(cmd.Context(), stderr))
+	return runProfileUse(cmd, []string{name})
+}
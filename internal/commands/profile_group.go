@@ -0,0 +1,179 @@
+// ABOUTME: profile group enable/disable turns a profile's named plugin groups on and off
+// ABOUTME: Saves the profile, then installs or removes the group's plugins if it is active
+package commands
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var profileGroupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Turn a profile's plugin groups on and off",
+	Long: `Profiles can collect optional plugins into named groups:
+
+  "groups": {
+    "testing": ["tdd@superpowers", "coverage@acme"],
+    "docs": ["docs-writer@acme"]
+  }
+
+A group's plugins are applied with the profile unless the group is disabled.
+'profile show' lists the groups and whether each is enabled.`,
+}
+
+var profileGroupEnableCmd = &cobra.Command{
+	Use:   "enable <profile> <group>",
+	Short: "Enable a plugin group and install its plugins",
+	Long: `Enables a plugin group in a saved profile. If the profile is active, the
+group's plugins are installed right away; otherwise they are installed the
+next time the profile is applied.`,
+	Example: `  claudeup profile group enable backend testing`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProfileGroup(cmd, args[0], args[1], true)
+	},
+}
+
+var profileGroupDisableCmd = &cobra.Command{
+	Use:   "disable <profile> <group>",
+	Short: "Disable a plugin group and remove its plugins",
+	Long: `Disables a plugin group in a saved profile. If the profile is active, the
+group's plugins are uninstalled right away, except those the profile or
+another enabled group still lists.`,
+	Example: `  claudeup profile group disable backend docs`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProfileGroup(cmd, args[0], args[1], false)
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileGroupCmd)
+	profileGroupCmd.AddCommand(profileGroupEnableCmd, profileGroupDisableCmd)
+	for _, cmd := range []*cobra.Command{profileGroupEnableCmd, profileGroupDisableCmd} {
+		addFailOnErrorFlag(cmd)
+		addForceFlag(cmd)
+	}
+}
+
+func runProfileGroup(cmd *cobra.Command, name, group string, enabled bool) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+
+	profilesDir := getProfilesDir()
+	name, err := resolveProfileName(profilesDir, name)
+	if err != nil {
+		return err
+	}
+	p, err := profile.Load(profilesDir, name)
+	if err != nil {
+		return fmt.Errorf("profile %q not found in %s (save built-in profiles before changing their groups): %w", name, profilesDir, err)
+	}
+	if _, ok := p.Groups[group]; ok && p.GroupEnabled(group) == enabled {
+		out.Printf("✓ Group %s is already %s in %s\n", group, state, name)
+		return nil
+	}
+	if err := p.SetGroupEnabled(group, enabled); err != nil {
+		return err
+	}
+	if err := profile.Save(profilesDir, p); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	out.Printf("✓ Group %s %s in %s\n", group, state, name)
+
+	cfg, err := config.Load()
+	if err != nil || cfg.Preferences.ActiveProfile != name {
+		out.Printf("  Run 'claudeup profile use %s' to apply it\n", name)
+		return nil
+	}
+	return applyGroupChange(cmd, out, p, group, enabled)
+}
+
+// applyGroupChange installs or removes the group's plugins in the active
+// profile, leaving every other difference from the profile alone
+func applyGroupChange(cmd *cobra.Command, out ui.Printer, p *profile.Profile, group string, enabled bool) error {
+	full, err := profile.ComputeDiff(p, claudeDir, profile.DefaultClaudeJSONPath())
+	if err != nil {
+		return fmt.Errorf("failed to compute changes: %w", err)
+	}
+	diff := full.Select(groupFilter(p.Groups[group], enabled))
+	if !hasDiffChanges(diff) {
+		out.Println("  No changes needed")
+		return nil
+	}
+
+	executor, finish, err := claudeExecutor(out)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	out.Println()
+	showDiff(out, diff)
+	out.Println()
+
+	if err := checkClaudeNotRunning(cmd.Context(), out); err != nil {
+		return err
+	}
+	if !confirmProceed(out) {
+		out.Printf("Cancelled. The group is saved; run 'claudeup profile use %s' to apply it.\n", p.Name)
+		return nil
+	}
+	if err := auditPluginsForApply(out, diff.PluginsToInstall); err != nil {
+		return err
+	}
+
+	out.Println()
+	ctx := applyStarting(cmd.Context(), out, "cli", p.Name, diff)
+	result, err := profile.ApplyPlanned(ctx, diff, claudeDir, buildSecretChain(), executor)
+	recordApplyFrom(ctx, out, "cli", p.Name, diff, result, err)
+	if err != nil {
+		return applyFailed(out, result, err)
+	}
+	stampApplied(out, p.Name)
+	if applied, err := p.ForThisMachine(); err == nil {
+		recordAppliedProfile(out, applied)
+	} else {
+		out.Warnf("  Warning: could not record applied profile: %v\n", err)
+	}
+
+	showApplyResults(out, result)
+	recordPluginChecksums(out, claudeDir, append(result.PluginsInstalled, result.PluginsAlreadyPresent...))
+
+	out.Println()
+	if len(result.Errors) > 0 {
+		out.Println("⚠ Group applied with errors")
+		return applyExitError(result)
+	}
+	out.Println("✓ Group applied")
+	return nil
+}
+
+// groupFilter accepts the changes that bring a group's plugins in line:
+// installing them and their marketplaces when it's enabled, removing them
+// when it's disabled
+func groupFilter(plugins []string, enabled bool) func(profile.DiffItem) bool {
+	return func(item profile.DiffItem) bool {
+		if !enabled {
+			return item.Action == "remove" && item.Subsystem == profile.SubsystemPlugins && slices.Contains(plugins, item.Name)
+		}
+		switch item.Subsystem {
+		case profile.SubsystemMarketplaces:
+			return true
+		case profile.SubsystemPlugins:
+			return item.Action != "remove" && slices.Contains(plugins, item.Name)
+		}
+		return false
+	}
+}
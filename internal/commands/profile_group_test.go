@@ -0,0 +1,34 @@
+// ABOUTME: Tests for applying a plugin group change to the active profile
+// ABOUTME: Covers which planned changes groupFilter keeps
+package commands
+
+import (
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+func TestGroupFilter(t *testing.T) {
+	group := []string{"tdd@m"}
+	tests := []struct {
+		name    string
+		item    profile.DiffItem
+		enabled bool
+		want    bool
+	}{
+		{"install group plugin", profile.DiffItem{Action: "install", Subsystem: profile.SubsystemPlugins, Name: "tdd@m"}, true, true},
+		{"install other plugin", profile.DiffItem{Action: "install", Subsystem: profile.SubsystemPlugins, Name: "core@m"}, true, false},
+		{"add marketplace", profile.DiffItem{Action: "add", Subsystem: profile.SubsystemMarketplaces, Name: "org/m"}, true, true},
+		{"remove unrelated drift", profile.DiffItem{Action: "remove", Subsystem: profile.SubsystemPlugins, Name: "stray@m"}, false, false},
+		{"remove group plugin", profile.DiffItem{Action: "remove", Subsystem: profile.SubsystemPlugins, Name: "tdd@m"}, false, true},
+		{"no install when disabling", profile.DiffItem{Action: "install", Subsystem: profile.SubsystemPlugins, Name: "tdd@m"}, false, false},
+		{"no MCP changes", profile.DiffItem{Action: "install", Subsystem: profile.SubsystemMCP, Name: "ctx"}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := groupFilter(group, tt.enabled)(tt.item); got != tt.want {
+				t.Errorf("groupFilter(%v) = %v, want %v", tt.item, got, tt.want)
+			}
+		})
+	}
+}
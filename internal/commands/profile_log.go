@@ -0,0 +1,88 @@
+// ABOUTME: profile log shows a timeline of the plugins, MCP servers, and marketplaces a profile gained and lost
+// ABOUTME: Reads git history when the profile is committed, otherwise the copies claudeup keeps on each save
+package commands
+
+import (
+	"fmt"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var profileLogFormat string
+
+var profileLogCmd = &cobra.Command{
+	Use:   "log <name>",
+	Short: "Show how a profile changed over time",
+	Long: `Shows each revision of a profile, newest first, with the plugins, MCP
+servers, and marketplaces it added and removed. Plugins of enabled groups
+count as the profile's.
+
+When the profile's file is committed to a git repository, such as a synced
+profiles directory, the revisions are its commits; changes not committed
+yet aren't shown. Otherwise they're the versions claudeup keeps under
+~/.claudeup/profiles/.versions each time the profile is saved over, the
+last 50 of them, followed by its current file.`,
+	Example: `  claudeup profile log backend
+  claudeup profile log team/backend --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProfileLog,
+}
+
+func init() {
+	profileCmd.AddCommand(profileLogCmd)
+	profileLogCmd.Flags().StringVar(&profileLogFormat, "format", "", "Print the revisions as json or yaml")
+}
+
+// profileLogReport is the machine-readable form of 'profile log'
+type profileLogReport struct {
+	Profile   string             `json:"profile"`
+	Source    string             `json:"source"` // "git" or "versions"
+	Revisions []profile.Revision `json:"revisions"`
+}
+
+func runProfileLog(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if err := validateFormat("format", profileLogFormat); err != nil {
+		return err
+	}
+	profilesDir := getProfilesDir()
+	// A deleted profile still has its history
+	name, err := resolveProfileName(profilesDir, args[0])
+	if err != nil {
+		name = args[0]
+	}
+
+	revisions, source, err := profile.Log(profilesDir, name)
+	if err != nil {
+		return err
+	}
+	if profileLogFormat != "" {
+		return printFormatted(profileLogFormat, profileLogReport{Profile: name, Source: source, Revisions: revisions})
+	}
+
+	if len(revisions) == 0 {
+		out.Printf("No history for %s yet.\n", name)
+		out.Println("claudeup keeps the content a save replaces, or commit the profiles directory to git.")
+		return nil
+	}
+	from := "saved versions"
+	if source == profile.LogSourceGit {
+		from = "git"
+	}
+	out.Printf("History of %s (from %s)\n", name, from)
+	for _, r := range revisions {
+		out.Println()
+		line := fmt.Sprintf("%s  %s", r.ID, versionTime(r.Time, "2006-01-02 15:04"))
+		if r.Author != "" {
+			line += "  " + r.Author
+		}
+		if r.Message != "" {
+			line += "  " + r.Message
+		}
+		out.Println(line)
+		printVersionChanges(out, r.Changes)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// ABOUTME: Pre-seeds Claude Code permissions and project trust from a profile on apply
+// ABOUTME: Lists what would be added and asks first; --grant-permissions agrees up front
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var grantPermissions bool
+
+func addGrantPermissionsFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&grantPermissions, "grant-permissions", false, "Add the profile's permissions and project trust without asking")
+}
+
+// seedPermissions adds the profile's permissions that Claude Code doesn't
+// have yet. They widen what Claude may do unprompted, so the user has to
+// agree: at the prompt, or with --grant-permissions. -y alone skips them.
+func seedPermissions(out ui.Printer, p *profile.Profile, claudeDir, claudeJSONPath string) error {
+	if p.Permissions.IsEmpty() {
+		return nil
+	}
+	if err := p.Permissions.Validate(); err != nil {
+		return err
+	}
+	project, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	changes, err := profile.PendingPermissions(p, claudeDir, claudeJSONPath, project)
+	if err != nil {
+		return fmt.Errorf("failed to read permissions: %w", err)
+	}
+	if changes.IsEmpty() {
+		return nil
+	}
+
+	out.Println()
+	out.Println("Permissions to pre-seed:")
+	for _, rule := range changes.Allow {
+		out.Printf("  + allow %s\n", rule)
+	}
+	for _, dir := range changes.Directories {
+		out.Printf("  + directory %s\n", dir)
+	}
+	if changes.Trust != "" {
+		out.Printf("  + trust project %s\n", changes.Trust)
+	}
+
+	if !grantPermissions {
+		if config.YesFlag {
+			out.Println("  → Skipped; pass --grant-permissions to add them without asking")
+			return nil
+		}
+		choice := strings.ToLower(promptChoice(out, "Let Claude Code use these without asking?", "n"))
+		if choice != "y" && choice != "yes" {
+			out.Println("  → Skipped")
+			return nil
+		}
+	}
+
+	if err := profile.ApplyPermissions(changes, claudeDir, claudeJSONPath); err != nil {
+		return err
+	}
+	out.Println("  ✓ Permissions pre-seeded")
+	return nil
+}
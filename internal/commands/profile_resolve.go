@@ -0,0 +1,95 @@
+// ABOUTME: 'profile show --resolve' printing the effective profile with where each entry came from
+// ABOUTME: --write saves the resolved profile, addons and groups flattened, under a new name
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+var (
+	profileShowResolve bool
+	profileShowWrite   string
+)
+
+func init() {
+	profileShowCmd.Flags().BoolVar(&profileShowResolve, "resolve", false, "Show the effective profile after addons and plugin groups, with each entry's source")
+	profileShowCmd.Flags().StringVar(&profileShowWrite, "write", "", "Save the resolved profile under this name")
+}
+
+// showResolvedProfile resolves spec, a base profile and +addons, prints
+// it, and saves it when --write names a profile
+func showResolvedProfile(out ui.Printer, profilesDir, spec string) error {
+	base, addons, err := loadProfileArgs(profilesDir, nil, strings.Fields(spec))
+	if err != nil {
+		return err
+	}
+	resolved, pv, err := profile.Resolve(base, addons...)
+	if err != nil {
+		return err
+	}
+
+	if profileShowWrite == "" {
+		showResolution(out, spec, resolved, pv)
+		return nil
+	}
+
+	if _, err := os.Stat(filepath.Join(profilesDir, profileShowWrite+".json")); err == nil {
+		return fmt.Errorf("profile %q already exists; pick another name", profileShowWrite)
+	}
+	flat := resolved.Clone(profileShowWrite)
+	if flat.Description == "" {
+		flat.Description = "Resolved from " + spec
+	}
+	if err := profile.Save(profilesDir, flat); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	out.Printf("✓ Saved %s as %q: %d plugins, %d MCP servers, %d marketplaces\n", spec, profileShowWrite, len(flat.Plugins), len(flat.MCPServers), len(flat.Marketplaces))
+	return nil
+}
+
+// showResolution lists the resolved profile's entries by section, each
+// with the profile or group it came from
+func showResolution(out ui.Printer, spec string, p *profile.Profile, pv profile.Provenance) {
+	out.Printf("Profile: %s (resolved)\n", spec)
+	if p.Description != "" {
+		out.Printf("Description: %s\n", p.Description)
+	}
+
+	section := func(title, kind string, names []string) {
+		if len(names) == 0 {
+			return
+		}
+		out.Println()
+		out.Println(title + ":")
+		w := tabwriter.NewWriter(out.Out(), 0, 0, 3, ' ', 0)
+		for _, name := range names {
+			fmt.Fprintf(w, "  - %s\t← %s\n", name, pv.Of(kind, name))
+		}
+		w.Flush()
+	}
+
+	var marketplaces, servers []string
+	for _, m := range p.Marketplaces {
+		marketplaces = append(marketplaces, m.DisplayName())
+	}
+	for _, m := range p.MCPServers {
+		servers = append(servers, m.Name)
+	}
+	section("Marketplaces", profile.EntryMarketplace, marketplaces)
+	section("Plugins", profile.EntryPlugin, p.Plugins)
+	section("MCP Servers", profile.EntryMCPServer, servers)
+	section("Disabled plugins", profile.EntryDisabledPlugin, p.Disabled.Plugins)
+	section("Disabled MCP servers", profile.EntryDisabledMCP, p.Disabled.MCPServers)
+	section("Shell environment", profile.EntryEnv, slices.Sorted(maps.Keys(p.ShellEnv.Env)))
+	section("Allowed tools", profile.EntryAllow, p.Permissions.Allow)
+	section("Additional directories", profile.EntryDirectory, p.Permissions.AdditionalDirectories)
+}
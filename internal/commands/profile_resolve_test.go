@@ -0,0 +1,45 @@
+// ABOUTME: Tests for 'profile show --resolve' and --write
+// ABOUTME: Resolves saved profiles with an addon and saves the flattened result
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func TestShowResolvedProfile(t *testing.T) {
+	dir := t.TempDir()
+	profile.Save(dir, &profile.Profile{Name: "backend", Plugins: []string{"b@m"}, Groups: map[string][]string{"db": {"pg@m"}}})
+	profile.Save(dir, &profile.Profile{Name: "web", Type: profile.TypeAddon, Plugins: []string{"a@m"}})
+
+	var buf bytes.Buffer
+	out := ui.NewPrinter(&buf, &buf, false)
+	if err := showResolvedProfile(out, dir, "backend +web"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"a@m    ← web", "b@m    ← backend", "pg@m   ← backend (group db)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, buf.String())
+		}
+	}
+
+	profileShowWrite = "flat"
+	t.Cleanup(func() { profileShowWrite = "" })
+	if err := showResolvedProfile(out, dir, "backend +web"); err != nil {
+		t.Fatal(err)
+	}
+	flat, err := profile.Load(dir, "flat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(flat.Plugins, " ") != "a@m b@m pg@m" || len(flat.Groups) != 0 || flat.Description != "Resolved from backend +web" {
+		t.Errorf("Unexpected flattened profile %+v", flat)
+	}
+	if err := showResolvedProfile(out, dir, "backend"); err == nil {
+		t.Error("Expected --write to refuse an existing profile")
+	}
+}
@@ -0,0 +1,164 @@
+// ABOUTME: profile retry-failed re-runs only the changes that failed in the last apply
+// ABOUTME: Reads the failures from the history log and re-plans them against the current state
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/history"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var profileRetryFailedCmd = &cobra.Command{
+	Use:   "retry-failed",
+	Short: "Retry the changes that failed in the last apply",
+	Long: `Reads the last apply from ~/.claudeup/history.jsonl and retries only the
+changes that failed, instead of re-running every change in the profile.
+
+Failed changes that are no longer needed, e.g. a plugin that has since been
+installed by hand, are skipped. Changes that fail again are recorded so the
+command can be run again.`,
+	Args: cobra.NoArgs,
+	RunE: runProfileRetryFailed,
+}
+
+func init() {
+	profileCmd.AddCommand(profileRetryFailedCmd)
+	addFailOnErrorFlag(profileRetryFailedCmd)
+	addForceFlag(profileRetryFailedCmd)
+	addReplayFlags(profileRetryFailedCmd)
+}
+
+func runProfileRetryFailed(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+
+	entries, err := history.Load(history.DefaultPath())
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	last, ok := history.LastApply(entries)
+	if !ok {
+		out.Println("No applies recorded yet.")
+		return nil
+	}
+	if len(last.Failed) == 0 {
+		if last.Error != "" {
+			return fmt.Errorf("the last apply of %s stopped before any changes were recorded (%s); run 'claudeup profile use %s' again", last.Profile, last.Error, last.Profile)
+		}
+		out.Printf("✓ The last apply of %s had no failed changes\n", last.Profile)
+		return nil
+	}
+
+	p, err := loadProfileWithAddons(getProfilesDir(), strings.Fields(last.Profile))
+	if err != nil {
+		return err
+	}
+
+	current, err := profile.ComputeDiff(p, claudeDir, profile.DefaultClaudeJSONPath())
+	if err != nil {
+		return fmt.Errorf("failed to compute changes: %w", err)
+	}
+	diff := current.Select(retryFilter(last.Failed))
+
+	if !hasDiffChanges(diff) {
+		out.Printf("✓ The %d failed changes from the last apply of %s are no longer needed\n", len(last.Failed), last.Profile)
+		return nil
+	}
+
+	executor, finish, err := claudeExecutor(out)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	out.Printf("Retrying %d of %d failed changes from the last apply of %s\n", diff.Count(), len(last.Failed), last.Profile)
+	out.Println()
+	showDiff(out, diff)
+	out.Println()
+
+	if err := checkClaudeNotRunning(cmd.Context(), out); err != nil {
+		return err
+	}
+	if !confirmProceed(out) {
+		out.Println("Cancelled.")
+		return nil
+	}
+	if err := auditPluginsForApply(out, diff.PluginsToInstall); err != nil {
+		return err
+	}
+
+	chain := buildSecretChain()
+	if err := ensureProfileEnv(out, p, chain); err != nil {
+		return err
+	}
+
+	out.Println()
+	out.Println("Retrying...")
+	ctx := applyStarting(cmd.Context(), out, "retry", last.Profile, diff)
+	result, err := profile.ApplyPlanned(ctx, diff, claudeDir, chain, executor)
+	recordApplyFrom(ctx, out, "retry", last.Profile, diff, result, err)
+	if err != nil {
+		return applyFailed(out, result, err)
+	}
+	stampApplied(out, last.Profile)
+
+	showApplyResults(out, result)
+	offerSecretWizard(cmd.Context(), out, result.UnresolvedSecrets, chain)
+	recordPluginChecksums(out, claudeDir, append(result.PluginsInstalled, result.PluginsAlreadyPresent...))
+
+	out.Println()
+	if len(result.Errors) > 0 {
+		out.Println("⚠ Some changes failed again; run 'claudeup profile retry-failed' to retry them")
+		return applyExitError(result)
+	}
+	out.Println("✓ Failed changes applied")
+	return nil
+}
+
+// retryFilter accepts the planned changes that failed last time
+func retryFilter(failed []history.Failure) func(profile.DiffItem) bool {
+	want := make(map[profile.DiffItem]bool)
+	for _, f := range failed {
+		want[profile.DiffItem{Action: f.Action, Subsystem: profile.Subsystem(f.Subsystem), Name: f.Name}] = true
+	}
+	return func(item profile.DiffItem) bool {
+		return want[item]
+	}
+}
+
+// historyFailures lists the failed steps of result for the history log
+func historyFailures(result *profile.ApplyResult) []history.Failure {
+	var failures []history.Failure
+	for _, step := range result.Failed() {
+		failures = append(failures, history.Failure{
+			Action:    step.Item.Action,
+			Subsystem: string(step.Item.Subsystem),
+			Name:      step.Item.Name,
+			Error:     step.Err.Error(),
+			Duration:  step.Duration.Milliseconds(),
+		})
+	}
+	return failures
+}
+
+// historyChanges lists the changes result made, for the history log
+func historyChanges(result *profile.ApplyResult) []history.Change {
+	var changes []history.Change
+	for _, step := range result.Steps {
+		if step.Result == profile.StepDone {
+			changes = append(changes, history.Change{
+				Action:    step.Item.Action,
+				Subsystem: string(step.Item.Subsystem),
+				Name:      step.Item.Name,
+				Duration:  step.Duration.Milliseconds(),
+			})
+		}
+	}
+	return changes
+}
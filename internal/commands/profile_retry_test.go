@@ -0,0 +1,35 @@
+// ABOUTME: Tests for profile retry-failed
+// ABOUTME: Checks failed steps round-trip through the history log into a retry plan
+package commands
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+func TestRetryFilterSelectsFailedChanges(t *testing.T) {
+	result := &profile.ApplyResult{Steps: []profile.ApplyStep{
+		{Item: profile.DiffItem{Action: "install", Subsystem: profile.SubsystemPlugins, Name: "a@m"}, Result: profile.StepDone},
+		{Item: profile.DiffItem{Action: "install", Subsystem: profile.SubsystemPlugins, Name: "b@m"}, Result: profile.StepFailed, Err: errors.New("boom"), Duration: 1500 * time.Millisecond},
+		{Item: profile.DiffItem{Action: "install", Subsystem: profile.SubsystemMCP, Name: "db"}, Result: profile.StepFailed, Err: errors.New("boom")},
+	}}
+	failures := historyFailures(result)
+	if len(failures) != 2 || failures[0].Name != "b@m" || failures[0].Error != "boom" || failures[0].Duration != 1500 {
+		t.Fatalf("Expected the two failed steps, got %+v", failures)
+	}
+
+	diff := &profile.Diff{
+		PluginsToInstall: []string{"a@m", "b@m", "c@m"},
+		MCPToInstall:     []profile.MCPServer{{Name: "db"}, {Name: "web"}},
+	}
+	retry := diff.Select(retryFilter(failures))
+	if len(retry.PluginsToInstall) != 1 || retry.PluginsToInstall[0] != "b@m" {
+		t.Errorf("Expected only b@m to be retried, got %v", retry.PluginsToInstall)
+	}
+	if len(retry.MCPToInstall) != 1 || retry.MCPToInstall[0].Name != "db" {
+		t.Errorf("Expected only db to be retried, got %v", retry.MCPToInstall)
+	}
+}
@@ -0,0 +1,179 @@
+// ABOUTME: profile simulate applies a profile into a scratch Claude config directory
+// ABOUTME: Edits the registry files directly, without the claude CLI, so the result can be inspected
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileSimulateInto        string
+	profileSimulateFromCurrent bool
+	profileSimulateFormat      string
+)
+
+var profileSimulateCmd = &cobra.Command{
+	Use:   "simulate <name> [+addon...] --into <dir>",
+	Short: "Apply a profile into a scratch directory to see the files it produces",
+	Long: `Applies a profile into a scratch directory, treated as CLAUDE_CONFIG_DIR,
+by editing the registry files directly instead of running the claude CLI.
+Your real setup isn't touched. Afterwards the directory holds what the
+profile would leave behind:
+
+  settings.json                    enabled plugins
+  plugins/installed_plugins.json   installed plugins
+  plugins/known_marketplaces.json  marketplaces
+  .claude.json                     user and local MCP servers
+  project/.mcp.json                project MCP servers
+  .claudeup/config.json            disabled plugins and servers
+
+The directory starts empty, as on a fresh machine, unless --from-current
+copies those files, and your machine overrides, from your setup first. Running again applies on top of
+what the last run left.
+
+Secrets aren't resolved: MCP servers get $NAME placeholders where their
+values would go. Marketplaces from git aren't cloned, so their plugins are
+recorded without checking they exist; archive marketplaces are downloaded.`,
+	Example: `  claudeup profile simulate backend --into /tmp/backend
+  claudeup profile simulate backend +testing --into /tmp/backend --from-current
+  claudeup profile simulate backend --into /tmp/backend --format json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runProfileSimulate,
+}
+
+func init() {
+	profileCmd.AddCommand(profileSimulateCmd)
+	profileSimulateCmd.Flags().StringVar(&profileSimulateInto, "into", "", "Scratch directory to apply the profile into (required)")
+	profileSimulateCmd.Flags().BoolVar(&profileSimulateFromCurrent, "from-current", false, "Start from a copy of your current registry files instead of an empty directory")
+	profileSimulateCmd.Flags().StringVar(&profileSimulateFormat, "format", "", "Print the report as json or yaml")
+	_ = profileSimulateCmd.MarkFlagRequired("into")
+}
+
+// simulateReport is the machine-readable form of 'profile simulate'
+type simulateReport struct {
+	Profile string   `json:"profile"`
+	Dir     string   `json:"dir"`
+	Changes int      `json:"changes"`
+	Files   []string `json:"files"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+func runProfileSimulate(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if err := validateFormat("format", profileSimulateFormat); err != nil {
+		return err
+	}
+
+	dir, err := filepath.Abs(profileSimulateInto)
+	if err != nil {
+		return fmt.Errorf("invalid --into: %w", err)
+	}
+	current := pathctx.Default()
+	for _, p := range []string{current.Home, current.ClaudeDir, current.ClaudeupDir} {
+		if abs, err := filepath.Abs(p); err == nil && abs == dir {
+			return fmt.Errorf("--into %s is part of your real setup; pick a scratch directory", dir)
+		}
+	}
+
+	p, err := loadProfileWithAddons(getProfilesDir(), args)
+	if err != nil {
+		return err
+	}
+
+	if profileSimulateFromCurrent {
+		if err := seedSimulation(current, dir); err != nil {
+			return err
+		}
+	}
+
+	sim, err := profile.Simulate(cmd.Context(), p, dir)
+	if err != nil {
+		return err
+	}
+
+	report := simulateReport{Profile: p.Name, Dir: dir, Changes: sim.Diff.Count(), Files: sim.Files}
+	if report.Files == nil {
+		report.Files = []string{}
+	}
+	for _, e := range sim.Result.Errors {
+		report.Errors = append(report.Errors, e.Error())
+	}
+	if profileSimulateFormat != "" {
+		if err := printFormatted(profileSimulateFormat, report); err != nil {
+			return err
+		}
+	} else {
+		showSimulation(out, report, sim)
+	}
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d changes failed in the simulation", len(report.Errors))
+	}
+	return nil
+}
+
+func showSimulation(out ui.Printer, report simulateReport, sim *profile.Simulation) {
+	out.Printf("Simulating profile %s in %s\n", report.Profile, report.Dir)
+	out.Println()
+	if report.Changes == 0 {
+		out.Println("Nothing to change; the directory already matches the profile.")
+		return
+	}
+
+	out.Println("━━━ Changes ━━━")
+	showDiff(out, sim.Diff)
+	out.Println()
+	showApplyResults(out, sim.Result)
+	out.Println()
+
+	out.Println("━━━ Files ━━━")
+	if len(report.Files) == 0 {
+		out.Println("  No files changed")
+	}
+	for _, f := range report.Files {
+		out.Printf("  %s\n", filepath.Join(report.Dir, filepath.FromSlash(f)))
+	}
+}
+
+// seedSimulation copies the registry files of the real setup into an empty
+// scratch directory laid out the way profile.Simulate expects
+func seedSimulation(current pathctx.Paths, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("--from-current needs an empty directory, but %s has files in it", dir)
+	}
+
+	files := []struct{ src, dst string }{
+		{filepath.Join(current.ClaudeDir, "settings.json"), "settings.json"},
+		{filepath.Join(current.ClaudeDir, "plugins", "installed_plugins.json"), "plugins/installed_plugins.json"},
+		{filepath.Join(current.ClaudeDir, "plugins", "known_marketplaces.json"), "plugins/known_marketplaces.json"},
+		{current.ClaudeJSON, ".claude.json"},
+		{filepath.Join(current.ClaudeupDir, "config.json"), ".claudeup/config.json"},
+		{filepath.Join(current.ClaudeupDir, "overrides.json"), ".claudeup/overrides.json"},
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f.src); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(f.dst))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := copyFile(f.src, dst); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", f.src, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// ABOUTME: Tests for profile simulate's seeding of a scratch directory from the current setup
+// ABOUTME: Checks which registry files are copied and that a non-empty directory is refused
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+)
+
+func TestSeedSimulationCopiesRegistryFiles(t *testing.T) {
+	current := pathctx.ForHome(t.TempDir())
+	for path, content := range map[string]string{
+		filepath.Join(current.ClaudeDir, "settings.json"):                      `{"enabledPlugins": {}}`,
+		filepath.Join(current.ClaudeDir, "plugins", "known_marketplaces.json"): `{}`,
+		filepath.Join(current.ClaudeDir, "history.jsonl"):                      `{}`,
+		current.ClaudeJSON: `{"mcpServers": {}}`,
+	} {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "scratch")
+	if err := seedSimulation(current, dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"settings.json", "plugins/known_marketplaces.json", ".claude.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s to be copied: %v", name, err)
+		}
+	}
+	for _, name := range []string{"history.jsonl", "plugins/installed_plugins.json", ".claudeup/config.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected no %s, got %v", name, err)
+		}
+	}
+
+	if err := seedSimulation(current, dir); err == nil {
+		t.Error("Expected a directory with files in it to be refused")
+	}
+}
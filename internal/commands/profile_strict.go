@@ -0,0 +1,28 @@
+// ABOUTME: profile use --strict refuses to apply over state a profile can't represent
+// ABOUTME: Lists unknown registry fields, non-user plugin scopes, and non-stdio MCP servers
+package commands
+
+import (
+	"fmt"
+
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+// checkRepresentable fails when Claude's current configuration holds items
+// or fields that a profile can't represent, listing each of them
+func checkRepresentable(out ui.Printer, claudeDir, claudeJSONPath string) error {
+	issues, err := state.Unrepresentable(claudeDir, claudeJSONPath)
+	if err != nil {
+		return fmt.Errorf("--strict: failed to read Claude's configuration: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+
+	out.Warnf("✗ Claude's configuration has items a profile can't represent:\n")
+	for _, issue := range issues {
+		out.Warnf("  - %s\n", issue)
+	}
+	return fmt.Errorf("--strict: applying could lose what's listed above; drop --strict to apply anyway")
+}
@@ -0,0 +1,149 @@
+// ABOUTME: profile verify checks that an applied profile's stack actually works
+// ABOUTME: Reports pass/fail for each plugin, MCP server, marketplace, and secret
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileVerifyTimeout time.Duration
+	profileVerifyFormat  string
+)
+
+var profileVerifyCmd = &cobra.Command{
+	Use:   "verify [name]",
+	Short: "Check that a profile's plugins, MCP servers, and secrets work",
+	Long: `Checks the installed stack of a profile after it has been applied, and
+reports pass or fail for each item it declares:
+
+  Plugins       installed, with plugin.json, .mcp.json, and hooks.json parsing
+  MCP servers   the command starts and answers an MCP initialize request
+  Marketplaces  registered, and the clone is at the commit its plugins came from
+  Secrets       every secret and required env variable resolves
+
+Defaults to the active profile. Exits non-zero if any check fails, so it can
+be run at the end of onboarding scripts and in CI.`,
+	Example: `  claudeup profile verify
+  claudeup profile verify backend --timeout 30s
+  claudeup profile verify backend --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runProfileVerify,
+}
+
+func init() {
+	profileCmd.AddCommand(profileVerifyCmd)
+	profileVerifyCmd.Flags().DurationVar(&profileVerifyTimeout, "timeout", 10*time.Second, "How long each MCP server has to answer initialize")
+	profileVerifyCmd.Flags().StringVar(&profileVerifyFormat, "format", "", "Print the report as json or yaml")
+}
+
+// verifyReport is the machine-readable form of 'profile verify'
+type verifyReport struct {
+	Profile  string          `json:"profile"`
+	Passed   int             `json:"passed"`
+	Warnings int             `json:"warnings"`
+	Failed   int             `json:"failed"`
+	Checks   []profile.Check `json:"checks"`
+}
+
+func runProfileVerify(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if err := validateFormat("format", profileVerifyFormat); err != nil {
+		return err
+	}
+
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		cfg, err := config.Load()
+		if err == nil {
+			name = cfg.Preferences.ActiveProfile
+		}
+		if name == "" {
+			return fmt.Errorf("no active profile; pass a profile name or run 'claudeup profile use <name>'")
+		}
+	}
+
+	p, err := loadProfileWithAddons(getProfilesDir(), strings.Fields(name))
+	if err != nil {
+		return err
+	}
+
+	checks, err := p.HealthCheck(cmd.Context(), claudeDir, buildSecretChain(), profileVerifyTimeout)
+	if err != nil {
+		return err
+	}
+	report := verifyReport{Profile: name, Checks: checks}
+	for _, c := range checks {
+		switch c.Status {
+		case profile.CheckPass:
+			report.Passed++
+		case profile.CheckWarn:
+			report.Warnings++
+		case profile.CheckFail:
+			report.Failed++
+		}
+	}
+
+	if profileVerifyFormat != "" {
+		if err := printFormatted(profileVerifyFormat, report); err != nil {
+			return err
+		}
+	} else {
+		showVerifyReport(out, report)
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", report.Failed, len(checks))
+	}
+	return nil
+}
+
+func showVerifyReport(out ui.Printer, report verifyReport) {
+	out.Printf("Verifying profile: %s\n", report.Profile)
+	if len(report.Checks) == 0 {
+		out.Println()
+		out.Println("Nothing to check; the profile declares no plugins, MCP servers, marketplaces, or secrets.")
+		return
+	}
+
+	section := ""
+	for _, c := range report.Checks {
+		if c.Section != section {
+			section = c.Section
+			out.Println()
+			out.Printf("━━━ %s ━━━\n", section)
+		}
+		glyph := "✓"
+		switch c.Status {
+		case profile.CheckWarn:
+			glyph = "⚠"
+		case profile.CheckFail:
+			glyph = "✗"
+		}
+		if c.Detail != "" {
+			out.Printf("  %s %s: %s\n", glyph, c.Name, c.Detail)
+		} else {
+			out.Printf("  %s %s\n", glyph, c.Name)
+		}
+	}
+
+	out.Println()
+	summary := fmt.Sprintf("%d passed, %d failed", report.Passed, report.Failed)
+	if report.Warnings > 0 {
+		summary += fmt.Sprintf(", %d warnings", report.Warnings)
+	}
+	if report.Failed > 0 {
+		out.Printf("✗ %s\n", summary)
+	} else {
+		out.Printf("✓ %s\n", summary)
+	}
+}
@@ -0,0 +1,172 @@
+// ABOUTME: profile versions lists the earlier versions kept of a profile, and profile undo restores one
+// ABOUTME: Saving a profile keeps the content it replaces, hand edits included, under profiles/.versions
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var profileVersionsFormat string
+
+var profileVersionsCmd = &cobra.Command{
+	Use:   "versions <name>",
+	Short: "List the earlier versions kept of a profile",
+	Long: `Lists the earlier versions of a profile, newest first, with the plugins,
+MCP servers, and marketplaces restoring each would add (+) and remove (-).
+
+Each time claudeup saves a profile, the file it replaces is kept as it was,
+hand edits included, under ~/.claudeup/profiles/.versions, the last 50 per
+profile. Versions are numbered in the order they were kept. Restore one
+with 'claudeup profile undo'.`,
+	Example: `  claudeup profile versions backend
+  claudeup profile undo backend 12`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProfileVersions,
+}
+
+var profileUndoCmd = &cobra.Command{
+	Use:   "undo <name> [version]",
+	Short: "Restore the previous version of a profile",
+	Long: `Writes an earlier version of a profile back as its file, byte for byte:
+the version it had before it was last saved, or the one given, as listed by
+'claudeup profile versions'.
+
+Running undo again steps further back, one version each time, until the
+profile is saved. The content the first undo replaced is kept as the newest
+version, so restoring that version redoes them all. Undo only changes the
+profile's file; run 'claudeup profile use' to apply it.`,
+	Example: `  claudeup profile undo backend
+  claudeup profile undo backend 12`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runProfileUndo,
+}
+
+func init() {
+	profileCmd.AddCommand(profileVersionsCmd, profileUndoCmd)
+	profileVersionsCmd.Flags().StringVar(&profileVersionsFormat, "format", "", "Print the versions as json or yaml")
+}
+
+// profileVersionsReport is the machine-readable form of 'profile versions'
+type profileVersionsReport struct {
+	Profile  string            `json:"profile"`
+	Versions []profile.Version `json:"versions"`
+}
+
+// profileVersionName resolves name to a saved profile, or takes it as given
+// so a deleted profile's versions can still be restored
+func profileVersionName(profilesDir, name string) string {
+	if resolved, err := resolveProfileName(profilesDir, name); err == nil {
+		return resolved
+	}
+	return name
+}
+
+func runProfileVersions(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if err := validateFormat("format", profileVersionsFormat); err != nil {
+		return err
+	}
+	profilesDir := getProfilesDir()
+	name := profileVersionName(profilesDir, args[0])
+	versions, err := profile.Versions(profilesDir, name)
+	if err != nil {
+		return err
+	}
+	if profileVersionsFormat != "" {
+		return printFormatted(profileVersionsFormat, profileVersionsReport{Profile: name, Versions: versions})
+	}
+
+	if len(versions) == 0 {
+		out.Printf("No earlier versions of %s.\n", name)
+		out.Println("claudeup keeps the content a save replaces, starting with the next save.")
+		return nil
+	}
+	out.Printf("Earlier versions of %s, newest first\n", name)
+	for _, v := range versions {
+		out.Println()
+		out.Printf("%s  %s\n", v.ID, versionTime(v.Time, "2006-01-02 15:04:05"))
+		printVersionChanges(out, v.Changes)
+	}
+	out.Println()
+	out.Printf("Restore one with: claudeup profile undo %s <version>\n", name)
+	return nil
+}
+
+func runProfileUndo(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	profilesDir := getProfilesDir()
+	name := profileVersionName(profilesDir, args[0])
+	versions, err := profile.Versions(profilesDir, name)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("no earlier versions of profile %q", name)
+	}
+	id := ""
+	if len(args) == 2 {
+		id = args[1]
+	} else if id, err = profile.UndoTarget(profilesDir, name); err != nil {
+		return err
+	}
+	i := slices.IndexFunc(versions, func(v profile.Version) bool { return v.ID == id })
+	if i < 0 {
+		return fmt.Errorf("profile %q has no version %q; see 'claudeup profile versions %s'", name, id, name)
+	}
+	target := versions[i]
+
+	out.Printf("Restoring %s to version %s (%s)\n", name, target.ID, versionTime(target.Time, "2006-01-02 15:04:05"))
+	printVersionChanges(out, target.Changes)
+	if !config.YesFlag {
+		out.Promptf("Overwrite profile %s? [y/N]: ", name)
+		choice := promptChoice(out, "", "n")
+		if choice != "y" && choice != "yes" {
+			out.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	if _, err := profile.RestoreVersion(profilesDir, name, target.ID); err != nil {
+		return err
+	}
+	out.Printf("✓ Restored profile %s\n", name)
+	out.Printf("  → Run 'claudeup profile undo %s' again to step further back\n", name)
+	if cfg, err := config.LoadExisting(); err == nil && cfg.Preferences.ActiveProfile == name {
+		out.Printf("  → Apply it with 'claudeup profile use %s'\n", name)
+	}
+	return nil
+}
+
+// versionTime formats when a version was written. The oldest version of a
+// profile without updatedAt has no time.
+func versionTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return "time unknown"
+	}
+	return t.Local().Format(layout)
+}
+
+// printVersionChanges lists the items a revision added and removed, or
+// restoring a version would
+func printVersionChanges(out ui.Printer, changes []profile.RevisionChange) {
+	if len(changes) == 0 {
+		out.Println("  (no plugin, MCP server, or marketplace changes)")
+	}
+	for _, c := range changes {
+		sign := "+"
+		if c.Action == "remove" {
+			sign = "-"
+		}
+		out.Printf("  %s %s %s\n", sign, c.Kind, c.Name)
+	}
+}
@@ -0,0 +1,67 @@
+// ABOUTME: Runs a profile's setup wizard before it is applied
+// ABOUTME: Categories come from --category, the wizard's defaults, or a multi-select prompt
+package commands
+
+import (
+	"fmt"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var wizardCategories []string
+
+func addCategoryFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&wizardCategories, "category", nil, "Choose these setup wizard categories instead of asking")
+}
+
+// runSetupWizard resolves p's setup wizard into the profile to apply. The
+// categories come from --category when given, otherwise from the user when
+// ask is set, otherwise from the wizard's defaults.
+func runSetupWizard(cmd *cobra.Command, out ui.Printer, p *profile.Profile, ask bool) (*profile.Profile, error) {
+	w := p.SetupWizard
+	if w == nil {
+		if cmd.Flags().Changed("category") {
+			return nil, fmt.Errorf("profile %q has no setup wizard categories", p.Name)
+		}
+		return p, nil
+	}
+	if err := w.Validate(); err != nil {
+		return nil, fmt.Errorf("profile %q: %w", p.Name, err)
+	}
+
+	chosen := w.Defaults()
+	switch {
+	case cmd.Flags().Changed("category"):
+		chosen = wizardCategories
+	case ask:
+		options := make([]string, len(w.Categories))
+		var defaults []int
+		for i, c := range w.Categories {
+			options[i] = wizardOption(c)
+			if c.Default {
+				defaults = append(defaults, i)
+			}
+		}
+		picked, err := ui.ChooseMany(w.Question(), options, defaults)
+		if err != nil {
+			return nil, err
+		}
+		chosen = nil
+		for _, i := range picked {
+			chosen = append(chosen, w.Categories[i].Name)
+		}
+		out.Println()
+	}
+	return p.WithCategories(chosen)
+}
+
+// wizardOption is how a category is listed in the prompt
+func wizardOption(c profile.WizardCategory) string {
+	option := c.Name
+	if c.Description != "" {
+		option += " — " + c.Description
+	}
+	return fmt.Sprintf("%s (%d plugins)", option, len(c.Plugins))
+}
@@ -0,0 +1,96 @@
+// ABOUTME: prompt command printing a one-line profile status for shell prompts
+// ABOUTME: Caches the drift count by a hash of the state files so repeat calls read almost nothing
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print a one-line profile status for shell prompts",
+	Long: `Prints the active profile and whether Claude's configuration still matches
+it, for embedding in starship, powerlevel10k, and other prompts:
+
+  ⎈ frontend ✓     everything matches
+  ⎈ frontend ±3    three items were added, removed, or disabled since
+
+The count is cached until one of the files it depends on changes, so most
+calls only look at file sizes and times. Nothing is printed when no profile
+is active, and errors are never printed.`,
+	Example: `  # starship.toml
+  [custom.claudeup]
+  command = "claudeup prompt"
+  when = true`,
+	Args: cobra.NoArgs,
+	RunE: runPrompt,
+}
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+}
+
+// promptCache is the last computed status and the state it was computed from
+type promptCache struct {
+	Hash    string `json:"hash"`
+	Profile string `json:"profile"`
+	Drift   int    `json:"drift"` // -1 when the profile couldn't be loaded
+}
+
+func promptCachePath() string {
+	return pathctx.Default().Claudeup("prompt.cache")
+}
+
+func runPrompt(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadExisting()
+	if err != nil || cfg.Preferences.ActiveProfile == "" {
+		return nil
+	}
+	name := cfg.Preferences.ActiveProfile
+	fmt.Println(formatPrompt(name, promptDrift(name)))
+	return nil
+}
+
+// promptDrift returns the cached drift count for the active profile,
+// recomputing it when any file it depends on has changed
+func promptDrift(name string) int {
+	claudeJSONPath := profile.DefaultClaudeJSONPath()
+	hash := profile.StateHash(claudeDir, claudeJSONPath,
+		filepath.Join(getAppliedDir(), name+".json"),
+		filepath.Join(getAppliedDir(), name+".hash"),
+		filepath.Join(getProfilesDir(), name+".json"))
+
+	var cached promptCache
+	if data, err := os.ReadFile(promptCachePath()); err == nil && json.Unmarshal(data, &cached) == nil {
+		if cached.Hash == hash && cached.Profile == name {
+			return cached.Drift
+		}
+	}
+
+	drift := profileDrift(name)
+
+	// Best-effort; a prompt must never fail
+	if data, err := json.Marshal(promptCache{Hash: hash, Profile: name, Drift: drift}); err == nil {
+		os.WriteFile(promptCachePath(), data, 0644)
+	}
+	return drift
+}
+
+// formatPrompt renders the prompt segment for a drift count
+func formatPrompt(name string, drift int) string {
+	switch {
+	case drift < 0:
+		return "⎈ " + name
+	case drift == 0:
+		return "⎈ " + name + " ✓"
+	}
+	return fmt.Sprintf("⎈ %s ±%d", name, drift)
+}
@@ -0,0 +1,66 @@
+// ABOUTME: Tests for the prompt segment and its drift cache
+// ABOUTME: Checks the rendered glyphs and that a cached count is reused until a file changes
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+func TestFormatPrompt(t *testing.T) {
+	tests := []struct {
+		drift int
+		want  string
+	}{
+		{0, "⎈ frontend ✓"},
+		{3, "⎈ frontend ±3"},
+		{-1, "⎈ frontend"},
+	}
+	for _, tt := range tests {
+		if got := formatPrompt("frontend", tt.drift); got != tt.want {
+			t.Errorf("formatPrompt(%d) = %q, want %q", tt.drift, got, tt.want)
+		}
+	}
+}
+
+func TestPromptDriftCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	oldClaudeDir := claudeDir
+	claudeDir = filepath.Join(home, ".claude")
+	defer func() { claudeDir = oldClaudeDir }()
+	profile.ResetSnapshotCache()
+
+	if err := os.MkdirAll(filepath.Join(home, ".claudeup"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := profile.SaveRecord(getAppliedDir(), &profile.Profile{Name: "frontend", Plugins: []string{"a@m"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := promptDrift("frontend"); got != 1 {
+		t.Fatalf("promptDrift() = %d, want 1 for a missing plugin", got)
+	}
+
+	// A stale cache entry for the current state is trusted as is
+	hash := profile.StateHash(claudeDir, profile.DefaultClaudeJSONPath(),
+		filepath.Join(getAppliedDir(), "frontend.json"),
+		filepath.Join(getAppliedDir(), "frontend.hash"),
+		filepath.Join(getProfilesDir(), "frontend.json"))
+	os.WriteFile(promptCachePath(), []byte(`{"hash":"`+hash+`","profile":"frontend","drift":7}`), 0644)
+	if got := promptDrift("frontend"); got != 7 {
+		t.Errorf("promptDrift() = %d, want the cached 7", got)
+	}
+
+	later := time.Now().Add(time.Minute)
+	applied := filepath.Join(getAppliedDir(), "frontend.json")
+	os.Chtimes(applied, later, later)
+	if got := promptDrift("frontend"); got != 1 {
+		t.Errorf("promptDrift() = %d after the profile changed, want 1", got)
+	}
+}
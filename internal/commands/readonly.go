@@ -0,0 +1,39 @@
+// ABOUTME: Read-only mode for shared machines, set by CLAUDEUP_READONLY or the readOnly preference
+// ABOUTME: Commands that change Claude Code's or claudeup's configuration check it before changing anything
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// readOnlyEnv turns read-only mode on, or off with a false value such as 0
+const readOnlyEnv = "CLAUDEUP_READONLY"
+
+// readOnlyReason says what put claudeup in read-only mode, or returns ""
+// when it isn't. CLAUDEUP_READONLY, when set, wins over the preference.
+func readOnlyReason() string {
+	if v := os.Getenv(readOnlyEnv); v != "" {
+		if on, err := strconv.ParseBool(v); err == nil && !on {
+			return ""
+		}
+		return readOnlyEnv + " is set"
+	}
+	if cfg, err := config.LoadExisting(); err == nil && cfg.Preferences.ReadOnly {
+		return "readOnly is set in " + config.Path()
+	}
+	return ""
+}
+
+// checkWritable fails in read-only mode, naming the refused command
+func checkWritable(cmd *cobra.Command) error {
+	reason := readOnlyReason()
+	if reason == "" {
+		return nil
+	}
+	return fmt.Errorf("'%s' would change this machine's configuration, but claudeup is read-only (%s)", cmd.CommandPath(), reason)
+}
@@ -0,0 +1,55 @@
+// ABOUTME: Tests for read-only mode
+// ABOUTME: Checks the environment variable, the preference, and which wins
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/spf13/cobra"
+)
+
+func TestReadOnlyReason(t *testing.T) {
+	defer pathctx.Override(pathctx.ForHome(t.TempDir()))()
+
+	for _, v := range []string{"", "0", "false"} {
+		t.Setenv(readOnlyEnv, v)
+		if reason := readOnlyReason(); reason != "" {
+			t.Errorf("%s=%q: expected writable, got %q", readOnlyEnv, v, reason)
+		}
+	}
+	for _, v := range []string{"1", "true", "yes"} {
+		t.Setenv(readOnlyEnv, v)
+		if reason := readOnlyReason(); !strings.Contains(reason, readOnlyEnv) {
+			t.Errorf("%s=%q: expected read-only, got %q", readOnlyEnv, v, reason)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Preferences.ReadOnly = true
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(readOnlyEnv, "")
+	if reason := readOnlyReason(); !strings.Contains(reason, "readOnly is set") {
+		t.Errorf("Expected the preference to make it read-only, got %q", reason)
+	}
+	t.Setenv(readOnlyEnv, "0")
+	if reason := readOnlyReason(); reason != "" {
+		t.Errorf("Expected %s=0 to override the preference, got %q", readOnlyEnv, reason)
+	}
+}
+
+func TestCheckWritableNamesCommand(t *testing.T) {
+	t.Setenv(readOnlyEnv, "1")
+	parent := &cobra.Command{Use: "claudeup"}
+	child := &cobra.Command{Use: "cleanup"}
+	parent.AddCommand(child)
+
+	err := checkWritable(child)
+	if err == nil || !strings.Contains(err.Error(), "'claudeup cleanup'") || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("Expected a read-only error naming the command, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+// ABOUTME: --record and --replay for commands that run the claude CLI
+// ABOUTME: Captures claude invocations to a fixtures file, or answers them from one
+package commands
+
+import (
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	recordFixtures string
+	replayFixtures string
+)
+
+func addReplayFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&recordFixtures, "record", "", "Record each claude command and its result to this fixtures file")
+	cmd.Flags().StringVar(&replayFixtures, "replay", "", "Answer claude commands from this fixtures file instead of running claude")
+	cmd.MarkFlagsMutuallyExclusive("record", "replay")
+}
+
+// claudeExecutor returns the executor to run claude with, honouring
+// --record and --replay. Call finish when the command is done, whether or
+// not it succeeded, to save the recording or report what wasn't replayed.
+func claudeExecutor(out ui.Printer) (executor profile.CommandExecutor, finish func(), err error) {
+	switch {
+	case replayFixtures != "":
+		fixtures, err := profile.LoadFixtures(replayFixtures)
+		if err != nil {
+			return nil, nil, err
+		}
+		replayer := profile.NewReplayer(fixtures)
+		return replayer, func() {
+			unused := replayer.Unused()
+			if len(unused) == 0 {
+				return
+			}
+			out.Warnf("⚠ %d recorded claude commands were not replayed\n", len(unused))
+			for _, inv := range unused {
+				out.Verbosef("  %s\n", ui.CommandLine("claude", inv.Args))
+			}
+		}, nil
+
+	case recordFixtures != "":
+		recorder := profile.NewRecorder(&profile.DefaultExecutor{})
+		return recorder, func() {
+			fixtures := recorder.Fixtures()
+			fixtures.Claudeup = rootCmd.Version
+			if err := fixtures.Save(recordFixtures); err != nil {
+				out.Warnf("⚠ Failed to save the recording: %v\n", err)
+				return
+			}
+			out.Printf("→ Recorded %d claude commands to %s\n", len(fixtures.Invocations), recordFixtures)
+		}, nil
+	}
+	return &profile.DefaultExecutor{}, func() {}, nil
+}
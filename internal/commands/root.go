@@ -3,15 +3,23 @@
 package commands
 
 import (
+	"context"
+	"fmt"
 	"os"
-	"path/filepath"
+	"os/signal"
 
 	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/i18n"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	claudeDir string
+	claudeDir   string
+	quietFlag   bool
+	verboseFlag bool
+	renameNote  string
 )
 
 var rootCmd = &cobra.Command{
@@ -26,8 +34,17 @@ It provides visibility into and control over:
   - Plugin updates and maintenance`,
 }
 
+// Execute runs the command tree. The first Ctrl-C cancels the command's
+// context so long-running work can stop cleanly and report what finished;
+// a second one exits immediately.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return rootCmd.ExecuteContext(ctx)
 }
 
 // SetVersion sets the version for the root command
@@ -35,21 +52,85 @@ func SetVersion(version string) {
 	rootCmd.Version = version
 }
 
-func init() {
-	cobra.OnInitialize(initConfig)
+// SetCommandName changes the name shown in usage and help output,
+// for entry points that invoke the command tree under another name
+func SetCommandName(name string) {
+	rootCmd.Use = name
+}
 
-	// Global flags - respect CLAUDE_CONFIG_DIR if set
-	defaultClaudeDir := os.Getenv("CLAUDE_CONFIG_DIR")
-	if defaultClaudeDir == "" {
-		homeDir, _ := os.UserHomeDir()
-		defaultClaudeDir = filepath.Join(homeDir, ".claude")
-	}
+// SetRenameNote sets a note printed to stderr before each command, for
+// entry points kept under an old name. It's left out under --quiet and
+// when stderr isn't a terminal, so scripts and pipes never see it
+func SetRenameNote(note string) {
+	renameNote = note
+}
+
+func init() {
+	rootCmd.PersistentPreRun = initConfig
+	cobra.OnFinalize(func() { restorePaths() })
 
-	rootCmd.PersistentFlags().StringVar(&claudeDir, "claude-dir", defaultClaudeDir, "Claude installation directory")
+	// Global flags; an unset --claude-dir is resolved when a command runs
+	rootCmd.PersistentFlags().StringVar(&claudeDir, "claude-dir", "", "Claude installation directory (default $CLAUDE_CONFIG_DIR or ~/.claude)")
 	rootCmd.PersistentFlags().BoolVarP(&config.YesFlag, "yes", "y", false, "Skip all prompts, use defaults")
+	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "Suppress informational output; only errors are printed")
+	rootCmd.PersistentFlags().BoolVar(&verboseFlag, "verbose", false, "Show commands being run and extra detail (overrides the verboseOutput preference)")
 }
 
-func initConfig() {
+// restorePaths undoes the running command's pathctx.Override
+var restorePaths = func() {}
+
+func initConfig(cmd *cobra.Command, args []string) {
 	// Initialize configuration
-	// This will be called before any command runs
+	// This will be called before any command runs. The directories come
+	// first: preferences are read from them.
+	paths := resolvePaths(cmd.Context(), cmd.Flags().Changed("claude-dir"), claudeDir)
+	claudeDir = paths.ClaudeDir
+	cmd.SetContext(pathctx.With(cmd.Context(), paths))
+	restorePaths = pathctx.Override(paths)
+
+	ui.SetQuiet(quietFlag)
+	ui.SetVerbose(resolveVerbose(rootCmd.PersistentFlags().Changed("verbose"), verboseFlag))
+	i18n.SetLanguage(i18n.Detect(languagePreference()))
+	// Scripts only want the error itself, printed once by main, not the usage text
+	rootCmd.SilenceUsage = quietFlag
+	rootCmd.SilenceErrors = quietFlag
+
+	if renameNote != "" && !quietFlag && stderrIsTerminal() {
+		fmt.Fprintln(os.Stderr, renameNote)
+	}
+}
+
+func stderrIsTerminal() bool {
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// resolvePaths returns the directories every command works on: those
+// injected into ctx, or else the defaults under the home directory, with
+// --claude-dir, when given, moving the Claude directory
+func resolvePaths(ctx context.Context, flagSet bool, flag string) pathctx.Paths {
+	paths := pathctx.From(ctx)
+	if flagSet && flag != "" {
+		paths.ClaudeDir = flag
+	}
+	return paths
+}
+
+// resolveVerbose applies --verbose when it was given, including
+// --verbose=false, and the verboseOutput preference otherwise
+func resolveVerbose(flagSet, flag bool) bool {
+	if flagSet {
+		return flag
+	}
+	cfg, err := config.LoadExisting()
+	return err == nil && cfg.Preferences.VerboseOutput
+}
+
+// languagePreference returns the language preference, if one is set
+func languagePreference() string {
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		return ""
+	}
+	return cfg.Preferences.Language
 }
@@ -0,0 +1,94 @@
+// ABOUTME: Tests for the root command's global settings
+// ABOUTME: Resolves the directories commands work in, injected or from --claude-dir
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func TestResolvePaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+
+	if got := resolvePaths(context.Background(), false, ""); got.ClaudeDir != filepath.Join(home, ".claude") || got.ProfilesDir != filepath.Join(home, ".claudeup", "profiles") {
+		t.Errorf("Expected the layout under HOME, got %+v", got)
+	}
+
+	t.Setenv("CLAUDE_CONFIG_DIR", "/opt/claude")
+	if got := resolvePaths(context.Background(), false, ""); got.ClaudeDir != "/opt/claude" {
+		t.Errorf("Expected CLAUDE_CONFIG_DIR, got %q", got.ClaudeDir)
+	}
+
+	injected := pathctx.ForHome("/elsewhere")
+	ctx := pathctx.With(context.Background(), injected)
+	if got := resolvePaths(ctx, false, ""); got != injected {
+		t.Errorf("Expected the injected paths, got %+v", got)
+	}
+	if got := resolvePaths(ctx, true, "/flag/claude"); got.ClaudeDir != "/flag/claude" || got.ClaudeupDir != injected.ClaudeupDir {
+		t.Errorf("Expected --claude-dir to move only the Claude directory, got %+v", got)
+	}
+}
+
+// Commands run with injected paths leave the home directory alone
+func TestCommandsUseInjectedPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("CLAUDEUP_LANG", "en")
+	profile.ResetSnapshotCache()
+	t.Cleanup(profile.ResetSnapshotCache)
+
+	paths := pathctx.ForHome(t.TempDir())
+	os.MkdirAll(filepath.Join(paths.ClaudeDir, "plugins"), 0755)
+	os.WriteFile(paths.ClaudeJSON, []byte(`{"mcpServers": {"db": {"command": "pg-mcp"}}}`), 0644)
+
+	// An earlier run's --claude-dir would take precedence
+	flag := rootCmd.PersistentFlags().Lookup("claude-dir")
+	flag.Changed, claudeDir = false, ""
+
+	for _, args := range [][]string{{"profile", "save", "mine"}, {"profile", "list"}, {"paths"}} {
+		var buf bytes.Buffer
+		ctx := pathctx.With(ui.WithPrinter(context.Background(), ui.NewPrinter(&buf, &buf, false)), paths)
+		target, _, err := rootCmd.Find(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		target.SetContext(ctx)
+		rootCmd.SetArgs(args)
+		err = rootCmd.ExecuteContext(ctx)
+		rootCmd.SetArgs(nil)
+		if err != nil {
+			t.Fatalf("%v: %v\n%s", args, err, buf.String())
+		}
+		if args[0] == "paths" && !bytes.Contains(buf.Bytes(), []byte(paths.ProfilesDir)) {
+			t.Errorf("Expected paths to list the injected profiles directory, got:\n%s", buf.String())
+		}
+	}
+
+	saved, err := profile.Load(paths.ProfilesDir, "mine")
+	if err != nil {
+		t.Fatalf("Expected the profile saved to the injected directory: %v", err)
+	}
+	if len(saved.MCPServers) != 1 || saved.MCPServers[0].Name != "db" {
+		t.Errorf("Expected the injected .claude.json read, got %+v", saved.MCPServers)
+	}
+	if _, err := os.Stat(paths.Claudeup("config.json")); err != nil {
+		t.Errorf("Expected config written to the injected directory: %v", err)
+	}
+	if entries, _ := os.ReadDir(home); len(entries) != 0 {
+		t.Errorf("Expected HOME untouched, found %d entries", len(entries))
+	}
+	if pathctx.Default().Home != home {
+		t.Errorf("Expected the override undone after the command, got %+v", pathctx.Default())
+	}
+}
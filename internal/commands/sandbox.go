@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
 	"github.com/claudeup/claudeup/internal/profile"
 	"github.com/claudeup/claudeup/internal/sandbox"
-	"github.com/claudeup/claudeup/internal/secrets"
+	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +26,8 @@ var (
 	sandboxClean      bool
 	sandboxImage      string
 	sandboxEphemeral  bool
+	sandboxPrivileged bool
+	sandboxPublish    []string
 )
 
 var sandboxCmd = &cobra.Command{
@@ -31,21 +36,34 @@ var sandboxCmd = &cobra.Command{
 	Long: `Run Claude Code in an isolated Docker container for security.
 
 By default, runs an ephemeral session where nothing persists after exit.
-Use --profile to persist state between sessions.
+Use --profile to persist state between sessions. With --profile and
+--ephemeral, the session starts from a copy of the profile's state and the
+copy is discarded on exit, leaving the saved state untouched.
 
-The current working directory is mounted at /workspace unless --no-mount is used.`,
+The current working directory is mounted at /workspace unless --no-mount is used.
+
+The container runs as a non-root user with every capability dropped and
+privilege escalation disabled. Set sandbox.seccompProfile or
+sandbox.apparmorProfile in ~/.claudeup/config.json to confine it further.
+--privileged-ok runs it as root with Docker's default capabilities.`,
 	Example: `  # Ephemeral session
   claudeup sandbox
 
   # Persistent session using a profile
   claudeup sandbox --profile untrusted
 
+  # Start from the profile's state but keep none of the session's changes
+  claudeup sandbox --profile untrusted --ephemeral
+
   # Drop to bash instead of Claude CLI
   claudeup sandbox --shell
 
   # Add extra mount
   claudeup sandbox --mount ~/data:/data
 
+  # Reach a dev server in the sandbox at localhost:3000
+  claudeup sandbox --publish 3000
+
   # Reset a profile's sandbox state
   claudeup sandbox --clean --profile untrusted`,
 	RunE: runSandbox,
@@ -56,17 +74,20 @@ func init() {
 
 	sandboxCmd.Flags().StringVar(&sandboxProfile, "profile", "", "Profile for persistent state")
 	sandboxCmd.Flags().StringSliceVar(&sandboxMounts, "mount", nil, "Additional mounts (host:container[:ro])")
+	sandboxCmd.Flags().StringSliceVarP(&sandboxPublish, "publish", "p", nil, "Publish a container port on localhost ([host:]container[/udp])")
 	sandboxCmd.Flags().BoolVar(&sandboxNoMount, "no-mount", false, "Don't mount working directory")
 	sandboxCmd.Flags().StringSliceVar(&sandboxSecrets, "secret", nil, "Additional secrets to inject")
 	sandboxCmd.Flags().StringSliceVar(&sandboxNoSecrets, "no-secret", nil, "Secrets to exclude")
 	sandboxCmd.Flags().BoolVar(&sandboxShell, "shell", false, "Drop to bash instead of Claude CLI")
 	sandboxCmd.Flags().BoolVar(&sandboxClean, "clean", false, "Reset sandbox state for profile")
 	sandboxCmd.Flags().StringVar(&sandboxImage, "image", "", "Override sandbox image")
-	sandboxCmd.Flags().BoolVar(&sandboxEphemeral, "ephemeral", false, "Force ephemeral mode (no persistence)")
+	sandboxCmd.Flags().BoolVar(&sandboxEphemeral, "ephemeral", false, "Discard changes on exit; with --profile, start from a copy of its state")
+	sandboxCmd.Flags().BoolVar(&sandboxPrivileged, "privileged-ok", false, "Run as root with Docker's default capabilities")
 }
 
 func runSandbox(cmd *cobra.Command, args []string) error {
-	claudePMDir := filepath.Join(profile.MustHomeDir(), ".claudeup")
+	out := ui.PrinterFrom(cmd.Context())
+	claudePMDir := pathctx.From(cmd.Context()).ClaudeupDir
 
 	// Handle --clean
 	if sandboxClean {
@@ -76,7 +97,7 @@ func runSandbox(cmd *cobra.Command, args []string) error {
 		if err := sandbox.CleanState(claudePMDir, sandboxProfile); err != nil {
 			return err
 		}
-		fmt.Printf("✓ Cleaned sandbox state for profile %q\n", sandboxProfile)
+		out.Printf("✓ Cleaned sandbox state for profile %q\n", sandboxProfile)
 		return nil
 	}
 
@@ -88,23 +109,57 @@ func runSandbox(cmd *cobra.Command, args []string) error {
 
 	// Build options
 	opts := sandbox.Options{
-		Shell: sandboxShell,
-		Image: sandboxImage,
-		Env:   make(map[string]string),
+		Shell:        sandboxShell,
+		Image:        sandboxImage,
+		Env:          make(map[string]string),
+		PrivilegedOK: sandboxPrivileged,
+	}
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	opts.SeccompProfile = cfg.Sandbox.SeccompProfile
+	opts.AppArmorProfile = cfg.Sandbox.AppArmorProfile
+	if opts.Image == "" {
+		if opts.Image, err = sandbox.ImageForChannel(cfg.Sandbox.Channel); err != nil {
+			return err
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		return err
 	}
 
 	// Profile handling
-	if sandboxProfile != "" && !sandboxEphemeral {
+	var portSpecs []string
+	if sandboxProfile != "" {
 		opts.Profile = sandboxProfile
+		if sandboxEphemeral {
+			// Start from the profile's state, but throw the session's changes away
+			dir, cleanup, err := sandbox.CopyState(claudePMDir, sandboxProfile)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			opts.StateDir = dir
+		}
 
 		// Load profile for sandbox config
 		profilesDir := filepath.Join(claudePMDir, "profiles")
-		p, err := profile.Load(profilesDir, sandboxProfile)
+		name, err := resolveProfileName(profilesDir, sandboxProfile)
+		if err != nil {
+			return err
+		}
+		p, err := profile.Load(profilesDir, name)
 		if err != nil {
 			return fmt.Errorf("failed to load profile %q: %w", sandboxProfile, err)
 		}
-		// Apply profile's sandbox config (may be empty, that's fine)
+		// Apply profile's sandbox config (may be empty, that's fine), as it
+		// applies on this machine
+		if p, err = p.ForThisMachine(); err != nil {
+			return err
+		}
 		applyProfileSandboxConfig(&opts, p)
+		portSpecs = append(portSpecs, p.Sandbox.Ports...)
 	}
 
 	// Working directory mount
@@ -125,32 +180,57 @@ func runSandbox(cmd *cobra.Command, args []string) error {
 		opts.Mounts = append(opts.Mounts, mount)
 	}
 
+	// Published ports; --publish overrides a profile port on the same host port
+	for _, spec := range append(portSpecs, sandboxPublish...) {
+		port, err := sandbox.ParsePort(spec)
+		if err != nil {
+			return err
+		}
+		opts.Ports = addPort(opts.Ports, port)
+	}
+
 	// CLI secret overrides
 	opts.Secrets = append(opts.Secrets, sandboxSecrets...)
 	opts.ExcludeSecrets = append(opts.ExcludeSecrets, sandboxNoSecrets...)
 
 	// Resolve secrets
-	if err := resolveSecrets(&opts); err != nil {
+	if err := resolveSecrets(out, &opts); err != nil {
 		return fmt.Errorf("failed to resolve secrets: %w", err)
 	}
 
-	// Ensure image exists
-	if !runner.ImageExists(opts.Image) {
-		image := opts.Image
-		if image == "" {
-			image = sandbox.DefaultImage()
-		}
-		fmt.Printf("Pulling sandbox image %s...\n", image)
-		if err := runner.PullImage(opts.Image); err != nil {
+	// Ensure the image exists for the architecture Docker runs
+	opts.Platform = runner.EnginePlatform(cmd.Context())
+	image := opts.Image
+	if image == "" {
+		image = sandbox.DefaultImage()
+	}
+	local, present, err := runner.InspectImage(cmd.Context(), image)
+	if err != nil {
+		return err
+	}
+	if present && local.Platform != opts.Platform {
+		out.Printf("⚠ Local image %s is %s, but Docker runs %s\n", image, local.Platform, opts.Platform)
+		present = false
+	}
+	if !present {
+		out.Printf("Pulling sandbox image %s (%s)...\n", image, opts.Platform)
+		if err := runner.PullImage(cmd.Context(), image, opts.Platform); err != nil {
 			return fmt.Errorf("failed to pull image: %w", err)
 		}
 	}
 
 	// Show what we're doing
-	printSandboxInfo(opts)
+	printSandboxInfo(out, opts)
+
+	// Secret values are resolved into the environment; keep them off the terminal
+	var hide []string
+	for _, name := range opts.Secrets {
+		hide = append(hide, opts.Env[name])
+	}
+	out.Verbosef("→ %s\n", ui.CommandLine("docker", runner.RunArgs(opts), hide...))
 
 	// Run the sandbox
-	return runner.Run(opts)
+	return runner.Run(cmd.Context(), opts)
 }
 
 func applyProfileSandboxConfig(opts *sandbox.Options, p *profile.Profile) {
@@ -172,16 +252,24 @@ func applyProfileSandboxConfig(opts *sandbox.Options, p *profile.Profile) {
 	}
 }
 
-func resolveSecrets(opts *sandbox.Options) error {
+// addPort appends port, replacing any earlier port on the same host port
+// and protocol, which docker would reject as a conflict
+func addPort(ports []sandbox.Port, port sandbox.Port) []sandbox.Port {
+	for i, p := range ports {
+		if p.Host == port.Host && p.Protocol == port.Protocol {
+			ports[i] = port
+			return ports
+		}
+	}
+	return append(ports, port)
+}
+
+func resolveSecrets(out ui.Printer, opts *sandbox.Options) error {
 	if len(opts.Secrets) == 0 {
 		return nil
 	}
 
-	chain := secrets.NewChain(
-		secrets.NewEnvResolver(),
-		secrets.NewOnePasswordResolver(),
-		secrets.NewKeychainResolver(),
-	)
+	chain := buildSecretChain()
 
 	// Build exclusion set
 	excluded := make(map[string]bool)
@@ -197,7 +285,7 @@ func resolveSecrets(opts *sandbox.Options) error {
 
 		value, source, err := chain.Resolve(secretName)
 		if err != nil {
-			fmt.Printf("Warning: could not resolve secret %q: %v\n", secretName, err)
+			out.Printf("Warning: could not resolve secret %q: %v\n", secretName, err)
 			continue
 		}
 
@@ -208,23 +296,43 @@ func resolveSecrets(opts *sandbox.Options) error {
 	return nil
 }
 
-func printSandboxInfo(opts sandbox.Options) {
-	fmt.Println("━━━ Claude PM Sandbox ━━━")
+func printSandboxInfo(out ui.Printer, opts sandbox.Options) {
+	out.Println("━━━ Claude PM Sandbox ━━━")
 
-	if opts.Profile != "" {
-		fmt.Printf("Profile:  %s (persistent)\n", opts.Profile)
+	if opts.Profile != "" && opts.StateDir != "" {
+		out.Printf("Profile:  %s (ephemeral copy; changes are discarded on exit)\n", opts.Profile)
+	} else if opts.Profile != "" {
+		out.Printf("Profile:  %s (persistent)\n", opts.Profile)
 	} else {
-		fmt.Println("Mode:     ephemeral")
+		out.Println("Mode:     ephemeral")
 	}
 
 	if opts.WorkDir != "" {
-		fmt.Printf("Workdir:  %s → /workspace\n", opts.WorkDir)
+		out.Printf("Workdir:  %s → /workspace\n", opts.WorkDir)
 	} else {
-		fmt.Println("Workdir:  (none)")
+		out.Println("Workdir:  (none)")
 	}
 
 	if len(opts.Mounts) > 0 {
-		fmt.Printf("Mounts:   %d additional\n", len(opts.Mounts))
+		out.Printf("Mounts:   %d additional\n", len(opts.Mounts))
+	}
+
+	for i, p := range opts.Ports {
+		label := ""
+		if i == 0 {
+			label = "Ports:"
+		}
+		proto := ""
+		if p.Protocol != "" {
+			proto = "/" + p.Protocol
+		}
+		out.Printf("%-9s localhost:%d → %d%s\n", label, p.Host, p.Container, proto)
+	}
+	out.Printf("Host:     services on your machine are at %s:<port>\n", sandbox.HostGateway)
+	if runtime.GOOS == "linux" {
+		// Docker Desktop forwards to the host's loopback; Linux routes
+		// through the bridge, which loopback-only listeners don't see
+		out.Println("          (they must listen on 0.0.0.0, not 127.0.0.1, to be reachable)")
 	}
 
 	secretCount := 0
@@ -232,14 +340,26 @@ func printSandboxInfo(opts sandbox.Options) {
 		secretCount++
 	}
 	if secretCount > 0 {
-		fmt.Printf("Secrets:  %d injected\n", secretCount)
+		out.Printf("Secrets:  %d injected\n", secretCount)
+	}
+
+	if opts.PrivilegedOK {
+		out.Println("Security: root, default capabilities (--privileged-ok)")
+	} else {
+		out.Println("Security: non-root, no capabilities, no privilege escalation")
+	}
+	if opts.SeccompProfile != "" {
+		out.Printf("Seccomp:  %s\n", opts.SeccompProfile)
+	}
+	if opts.AppArmorProfile != "" {
+		out.Printf("AppArmor: %s\n", opts.AppArmorProfile)
 	}
 
 	if opts.Shell {
-		fmt.Println("Entry:    bash")
+		out.Println("Entry:    bash")
 	} else {
-		fmt.Println("Entry:    claude")
+		out.Println("Entry:    claude")
 	}
 
-	fmt.Println()
+	out.Println()
 }
@@ -0,0 +1,184 @@
+// ABOUTME: sandbox update-image pulls the sandbox image for the configured channel
+// ABOUTME: --check reports how far the local image is behind upstream, and notifies when scheduled
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/sandbox"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	imageCheckOnly bool
+	imageChannel   string
+	imageScheduled bool
+)
+
+var sandboxUpdateImageCmd = &cobra.Command{
+	Use:   "update-image",
+	Short: "Pull the latest sandbox image",
+	Long: `Pulls the sandbox image for the configured channel: "stable" (the default)
+or "nightly". --channel switches channels and saves the choice in
+~/.claudeup/config.json.
+
+--check pulls nothing and reports how far the local image is behind the
+registry's. It warns once the gap passes sandbox.staleAfterDays (14 by
+default). Checking needs docker buildx.`,
+	Example: `  claudeup sandbox update-image
+  claudeup sandbox update-image --channel nightly
+  claudeup sandbox update-image --check`,
+	Args: cobra.NoArgs,
+	RunE: runSandboxUpdateImage,
+}
+
+func init() {
+	sandboxCmd.AddCommand(sandboxUpdateImageCmd)
+	sandboxUpdateImageCmd.Flags().BoolVar(&imageCheckOnly, "check", false, "Report whether the local image is stale without pulling")
+	sandboxUpdateImageCmd.Flags().StringVar(&imageChannel, "channel", "", "Switch to this channel (stable or nightly) and pull it")
+	sandboxUpdateImageCmd.Flags().BoolVar(&imageScheduled, "scheduled", false, "Record the result in history and notify (used by 'claudeup schedule')")
+	sandboxUpdateImageCmd.Flags().MarkHidden("scheduled")
+}
+
+func runSandboxUpdateImage(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	channel := cfg.Sandbox.Channel
+	if imageChannel != "" {
+		channel = imageChannel
+	}
+	image, err := sandbox.ImageForChannel(channel)
+	if err != nil {
+		return err
+	}
+	if channel == "" {
+		channel = sandbox.ChannelStable
+	}
+
+	runner := sandbox.NewDockerRunner(pathctx.From(cmd.Context()).ClaudeupDir)
+	if imageCheckOnly {
+		return checkSandboxImage(cmd, out, runner, image, channel, staleAfter(cfg))
+	}
+
+	if err := runner.Available(); err != nil {
+		return fmt.Errorf("docker is required: %w", err)
+	}
+	before, _, err := runner.InspectImage(cmd.Context(), image)
+	if err != nil {
+		return err
+	}
+	engine := runner.EnginePlatform(cmd.Context())
+	out.Printf("Pulling %s (%s, %s)...\n", image, channel, engine)
+	if err := runner.PullImage(cmd.Context(), image, engine); err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	after, _, err := runner.InspectImage(cmd.Context(), image)
+	if err != nil {
+		return err
+	}
+	if after.ID == before.ID {
+		out.Printf("✓ %s is up to date (built %s)\n", image, after.Created.Local().Format("2006-01-02"))
+	} else {
+		out.Printf("✓ Updated %s (built %s)\n", image, after.Created.Local().Format("2006-01-02"))
+	}
+
+	if imageChannel != "" && imageChannel != cfg.Sandbox.Channel {
+		full, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		full.Sandbox.Channel = imageChannel
+		if err := config.Save(full); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		out.Printf("✓ Sandbox channel set to %s\n", imageChannel)
+	}
+	return nil
+}
+
+// checkSandboxImage compares the local image with the registry's. Scheduled
+// runs stay silent for users who have never pulled the image.
+func checkSandboxImage(cmd *cobra.Command, out ui.Printer, runner *sandbox.DockerRunner, image, channel string, limit time.Duration) error {
+	local, present, err := runner.InspectImage(cmd.Context(), image)
+	if imageScheduled && (err != nil || !present) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("docker is required: %w", err)
+	}
+
+	out.Printf("Image:    %s (%s)\n", image, channel)
+	if !present {
+		out.Println("Local:    not pulled")
+		out.Println("  → Run 'claudeup sandbox update-image' to pull it")
+		return nil
+	}
+	out.Printf("Local:    built %s\n", local.Created.Local().Format("2006-01-02"))
+
+	engine := runner.EnginePlatform(cmd.Context())
+	if local.Platform != engine {
+		if imageScheduled {
+			recordScheduledResult(out, "sandbox-image", 1, fmt.Sprintf("sandbox image %s is %s, but Docker runs %s; run 'claudeup sandbox update-image'", image, local.Platform, engine), nil)
+		}
+		out.Printf("⚠ Local image is %s, but Docker runs %s, so it runs under emulation\n", local.Platform, engine)
+		out.Printf("  → Run 'claudeup sandbox update-image' to pull the %s image\n", engine)
+		return nil
+	}
+
+	upstream, err := runner.UpstreamCreated(cmd.Context(), image, engine)
+	if err != nil {
+		if imageScheduled {
+			recordScheduledResult(out, "sandbox-image", 0, "", err)
+		}
+		return err
+	}
+	out.Printf("Upstream: built %s\n", upstream.Local().Format("2006-01-02"))
+
+	behind := upstream.Sub(local.Created)
+	stale := behind > limit
+	if imageScheduled {
+		count := 0
+		if stale {
+			count = 1
+		}
+		recordScheduledResult(out, "sandbox-image", count, fmt.Sprintf("sandbox image %s is %s behind upstream; run 'claudeup sandbox update-image'", image, formatDays(behind)), nil)
+	}
+
+	switch {
+	case behind <= 0:
+		out.Println("✓ Local image is current")
+	case stale:
+		out.Printf("⚠ Local image is %s behind upstream\n", formatDays(behind))
+		out.Println("  → Run 'claudeup sandbox update-image'")
+	default:
+		out.Printf("✓ Local image is %s behind upstream (warns after %s)\n", formatDays(behind), formatDays(limit))
+	}
+	return nil
+}
+
+// staleAfter returns how far behind the local image may fall before a warning
+func staleAfter(cfg *config.GlobalConfig) time.Duration {
+	if cfg.Sandbox.StaleAfterDays > 0 {
+		return time.Duration(cfg.Sandbox.StaleAfterDays) * 24 * time.Hour
+	}
+	return sandbox.DefaultStaleAfter
+}
+
+func formatDays(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	if days == 1 {
+		return "1 day"
+	}
+	if days < 1 {
+		return "less than a day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
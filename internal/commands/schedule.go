@@ -0,0 +1,208 @@
+// ABOUTME: schedule command for running update checks and doctor periodically in the background
+// ABOUTME: Installs a launchd agent (macOS) or systemd user timer (Linux) and reports its status
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/claudeup/claudeup/internal/history"
+	"github.com/claudeup/claudeup/internal/notify"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/schedule"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var scheduleEvery string
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Run maintenance checks periodically in the background",
+	Long: `Schedules 'claudeup update --check-only --json', 'claudeup doctor --json', and
+'claudeup sandbox update-image --check' to run on a fixed cadence using
+launchd (macOS) or a systemd user timer (Linux).
+
+Each run is recorded in ~/.claudeup/history.jsonl, output is appended to
+~/.claudeup/schedule.log, and configured notifications fire when updates are
+available, doctor finds issues, the configuration has drifted from the active
+profile, or the sandbox image falls behind (see 'claudeup notify --help').
+The image check is skipped if you've never pulled it.`,
+}
+
+var scheduleInstallCmd = &cobra.Command{
+	Use:     "install",
+	Short:   "Install the background maintenance job",
+	Example: `  claudeup schedule install --every daily`,
+	Args:    cobra.NoArgs,
+	RunE:    runScheduleInstall,
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove the background maintenance job",
+	Args:  cobra.NoArgs,
+	RunE:  runScheduleRemove,
+}
+
+var scheduleStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the job is installed and its latest results",
+	Args:  cobra.NoArgs,
+	RunE:  runScheduleStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleInstallCmd)
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
+	scheduleCmd.AddCommand(scheduleStatusCmd)
+
+	scheduleInstallCmd.Flags().StringVar(&scheduleEvery, "every", "daily", "Cadence: hourly, daily, weekly, or a duration like 6h")
+}
+
+func scheduleBackend() (schedule.Backend, error) {
+	return schedule.ForOS(runtime.GOOS, pathctx.Default().Home, nil)
+}
+
+func runScheduleInstall(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	interval, err := schedule.ParseInterval(scheduleEvery)
+	if err != nil {
+		return err
+	}
+	backend, err := scheduleBackend()
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate claudeup binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	logPath := pathctx.From(cmd.Context()).Claudeup("schedule.log")
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	plan := schedule.Plan{Executable: exe, Interval: interval, LogPath: logPath}
+	if err := backend.Install(plan); err != nil {
+		return fmt.Errorf("failed to install schedule: %w", err)
+	}
+
+	out.Printf("✓ Maintenance checks scheduled every %s\n", interval)
+	out.Printf("  Binary: %s\n", exe)
+	out.Printf("  Log:    %s\n", logPath)
+	return nil
+}
+
+func runScheduleRemove(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	backend, err := scheduleBackend()
+	if err != nil {
+		return err
+	}
+	st, err := backend.Status()
+	if err != nil {
+		return err
+	}
+	if !st.Installed {
+		out.Println("No schedule installed.")
+		return nil
+	}
+	if err := backend.Remove(); err != nil {
+		return fmt.Errorf("failed to remove schedule: %w", err)
+	}
+	out.Println("✓ Schedule removed")
+	return nil
+}
+
+func runScheduleStatus(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	backend, err := scheduleBackend()
+	if err != nil {
+		return err
+	}
+	st, err := backend.Status()
+	if err != nil {
+		return err
+	}
+
+	if !st.Installed {
+		out.Println("No schedule installed.")
+		out.Println("  → Run 'claudeup schedule install' to set one up")
+		return nil
+	}
+
+	out.Printf("Schedule: every %s\n", st.Interval)
+	out.Printf("Unit:     %s\n", st.UnitPath)
+	if st.Active {
+		out.Println("State:    ✓ active")
+	} else {
+		out.Println("State:    ⚠ installed but not loaded")
+	}
+
+	entries, _ := history.Load(history.DefaultPath())
+	latest := make(map[string]history.Entry)
+	for _, e := range entries {
+		if e.Source == "schedule" {
+			latest[e.Action] = e
+		}
+	}
+
+	out.Println()
+	out.Println("━━━ Latest Results ━━━")
+	for _, action := range []string{"update-check", "doctor", "drift", "sandbox-image"} {
+		e, ok := latest[action]
+		switch {
+		case !ok:
+			out.Printf("  - %s: not run yet\n", action)
+		case e.Error != "":
+			out.Printf("  ✗ %s (%s): %s\n", action, e.Time.Local().Format("2006-01-02 15:04"), e.Error)
+		case e.Changes > 0:
+			out.Printf("  ⚠ %s (%s): %d found\n", action, e.Time.Local().Format("2006-01-02 15:04"), e.Changes)
+		default:
+			out.Printf("  ✓ %s (%s): nothing to report\n", action, e.Time.Local().Format("2006-01-02 15:04"))
+		}
+	}
+	return nil
+}
+
+// recordScheduledResult logs a scheduled check to history and notifies when
+// it failed or found something. count is the number of updates or issues.
+func recordScheduledResult(out ui.Printer, action string, count int, summary string, checkErr error) {
+	entry := history.Entry{Action: action, Source: "schedule", Changes: count}
+	if checkErr != nil {
+		entry.Error = checkErr.Error()
+	}
+	history.Append(history.DefaultPath(), entry)
+
+	switch {
+	case checkErr != nil:
+		sendNotification(out, notify.Event{
+			Kind:    action + "-failed",
+			Level:   notify.LevelError,
+			Title:   "claudeup: scheduled " + action + " failed",
+			Message: checkErr.Error(),
+		})
+	case count > 0:
+		sendNotification(out, notify.Event{
+			Kind:    action,
+			Level:   notify.LevelWarning,
+			Title:   "claudeup: scheduled " + action,
+			Message: summary,
+		})
+	}
+}
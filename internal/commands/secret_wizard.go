@@ -0,0 +1,153 @@
+// ABOUTME: Guided setup for optional MCP server secrets an apply couldn't resolve
+// ABOUTME: Saves each value to the env file, Keychain, or 1Password, then re-adds the server
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/secrets"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+// secretsEnvFile is where values for env sources are saved
+func secretsEnvFile() string {
+	return pathctx.Default().Claudeup("secrets.env")
+}
+
+// secretStore is a place the wizard can save a secret. A nil save uses the
+// value for this run only.
+type secretStore struct {
+	label string
+	save  func(value string) error
+}
+
+// secretStores lists where a secret can be saved so the next apply finds
+// it: the locations its own sources read from
+func secretStores(ref profile.SecretRef) []secretStore {
+	var stores []secretStore
+	for _, src := range ref.Sources {
+		switch src.Type {
+		case "env":
+			stores = append(stores, secretStore{
+				label: fmt.Sprintf("Env file (%s in ~/.claudeup/secrets.env)", src.Key),
+				save:  func(v string) error { return secrets.StoreEnvFile(secretsEnvFile(), src.Key, v) },
+			})
+		case "keychain":
+			if runtime.GOOS == "darwin" {
+				stores = append(stores, secretStore{
+					label: fmt.Sprintf("Keychain (%s)", src.Service),
+					save:  func(v string) error { return secrets.StoreKeychain(src.Service, src.Account, v) },
+				})
+			}
+		case "1password":
+			if _, err := exec.LookPath("op"); err == nil {
+				stores = append(stores, secretStore{
+					label: fmt.Sprintf("1Password (%s)", src.Ref),
+					save:  func(v string) error { return secrets.StoreOnePassword(src.Ref, v) },
+				})
+			}
+		}
+	}
+	return append(stores, secretStore{label: "Don't save; use for this run only"})
+}
+
+// offerSecretWizard reports MCP servers installed without optional secrets
+// and offers to walk through setting each one
+func offerSecretWizard(ctx context.Context, out ui.Printer, unresolved []profile.UnresolvedSecret, chain *secrets.Chain) {
+	if len(unresolved) == 0 {
+		return
+	}
+
+	out.Println()
+	out.Println("━━━ Optional Secrets ━━━")
+	for _, u := range unresolved {
+		out.Printf("  ⚠ %s was installed without %s\n", u.Server.Name, u.EnvVar)
+	}
+	if config.YesFlag {
+		out.Println("  → Set them and run 'claudeup profile use' again to add them")
+		return
+	}
+	if choice := strings.ToLower(promptChoice(out, "Set them up now? (y/n)", "y")); choice != "y" && choice != "yes" {
+		return
+	}
+
+	// Group by server so each one is re-added once
+	var servers []profile.MCPServer
+	missing := make(map[string][]string)
+	for _, u := range unresolved {
+		if _, ok := missing[u.Server.Name]; !ok {
+			servers = append(servers, u.Server)
+		}
+		missing[u.Server.Name] = append(missing[u.Server.Name], u.EnvVar)
+	}
+
+	for _, server := range servers {
+		out.Println()
+		out.Printf("MCP server %s:\n", server.Name)
+		given := make(map[string]string)
+		for _, envVar := range missing[server.Name] {
+			value, ok := askSecret(ctx, out, envVar, server.Secrets[envVar])
+			if !ok {
+				return
+			}
+			if value != "" {
+				given[envVar] = value
+			}
+		}
+		if len(given) == 0 {
+			continue
+		}
+		if err := profile.ReinstallMCP(ctx, server, chain, &profile.DefaultExecutor{}, given); err != nil {
+			out.Printf("  ✗ %v\n", err)
+		} else {
+			out.Printf("  ✓ Re-added MCP server %s\n", server.Name)
+		}
+	}
+}
+
+// askSecret prompts for one secret and saves it where the user picks. It
+// returns "" for a skipped secret and false if the wizard should stop.
+func askSecret(ctx context.Context, out ui.Printer, envVar string, ref profile.SecretRef) (string, bool) {
+	label := envVar
+	if ref.Description != "" {
+		label += " (" + ref.Description + ")"
+	}
+	value, err := ui.PromptSecret("  " + label + ", empty to skip")
+	if err != nil {
+		out.Printf("  ✗ %v\n", err)
+		return "", false
+	}
+	if value == "" {
+		return "", true
+	}
+	if err := profile.ValidateSecret(ctx, envVar, value, ref.Validate); err != nil {
+		out.Printf("  ✗ %v\n", err)
+		return "", true
+	}
+
+	stores := secretStores(ref)
+	labels := make([]string, len(stores))
+	for i, s := range stores {
+		labels[i] = s.label
+	}
+	i, err := ui.Choose(fmt.Sprintf("  Where should %s be saved?", envVar), labels)
+	if err != nil {
+		out.Printf("  ✗ %v\n", err)
+		return "", false
+	}
+	if stores[i].save != nil {
+		if err := stores[i].save(value); err != nil {
+			out.Printf("  ✗ Could not save %s: %v\n", envVar, err)
+		} else {
+			out.Printf("  ✓ Saved %s\n", envVar)
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,71 @@
+// ABOUTME: secrets command group for managing the opt-in cache of resolved secrets
+// ABOUTME: 'secrets lock' purges the cache; the TTL comes from preferences.secretCacheTtl
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/secrets"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage cached secrets",
+	Long: `Secrets referenced by profiles and sandboxes are resolved from environment
+variables, 1Password, Bitwarden, or the macOS Keychain each time they are
+needed.
+
+To avoid repeated password manager prompts, set "secretCacheTtl" in
+~/.claudeup/config.json preferences (for example "15m"). Resolved secrets
+are then cached for that long in ~/.claudeup/secrets.cache, encrypted with
+a key kept in the session's runtime directory. Environment variables are
+never cached.`,
+}
+
+var secretsLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Purge cached secrets",
+	Long:  `Deletes the secret cache and its key, so the next apply asks each backend again.`,
+	Args:  cobra.NoArgs,
+	RunE:  runSecretsLock,
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsLockCmd)
+}
+
+func runSecretsLock(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	// Purge even when caching is off, in case it was turned off recently
+	if err := newSecretCache(0).Purge(); err != nil {
+		return fmt.Errorf("failed to purge secret cache: %w", err)
+	}
+	out.Println("✓ Secret cache purged")
+	return nil
+}
+
+// configuredSecretCache returns the secret cache when secretCacheTtl is
+// set, or nil when caching is off
+func configuredSecretCache() *secrets.Cache {
+	cfg, err := config.LoadExisting()
+	if err != nil || cfg.Preferences.SecretCacheTTL == "" {
+		return nil
+	}
+	ttl, err := time.ParseDuration(cfg.Preferences.SecretCacheTTL)
+	if err != nil || ttl <= 0 {
+		ui.Default().Warnf("⚠ Ignoring invalid secretCacheTtl %q; secrets will not be cached\n", cfg.Preferences.SecretCacheTTL)
+		return nil
+	}
+	return newSecretCache(ttl)
+}
+
+func newSecretCache(ttl time.Duration) *secrets.Cache {
+	path := pathctx.Default().Claudeup("secrets.cache")
+	return secrets.NewCache(path, secrets.DefaultKeyPath(), ttl)
+}
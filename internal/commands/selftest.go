@@ -0,0 +1,121 @@
+// ABOUTME: Selftest command that checks claudeup works end to end on this machine
+// ABOUTME: Applies a small profile to a throwaway Claude config and reports each stage
+package commands
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/claudeup/claudeup/internal/selftest"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	selftestRealCLI bool
+	selftestKeep    bool
+)
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check that claudeup works on this machine",
+	Long: `Applies a small profile to a throwaway Claude config and checks each stage:
+reading the current state, saving and loading a profile, planning the
+changes, applying them, and the state afterwards. Your own configuration is
+never read or changed.
+
+By default the claude CLI is replaced by a stand-in, so the test needs no
+network and finishes in well under a second. --real-cli runs the same steps
+again with the installed claude CLI against a local test marketplace.`,
+	Example: `  claudeup selftest
+  claudeup selftest --real-cli
+  claudeup selftest --keep`,
+	Args: cobra.NoArgs,
+	RunE: runSelftest,
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+	selftestCmd.Flags().BoolVar(&selftestRealCLI, "real-cli", false, "Also run the steps with the installed claude CLI")
+	selftestCmd.Flags().BoolVar(&selftestKeep, "keep", false, "Keep the throwaway config directories for inspection")
+}
+
+func runSelftest(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	ctx := cmd.Context()
+
+	var passed, total int
+	run := func(title string, realCLI bool) error {
+		env, err := selftest.NewEnv()
+		if err != nil {
+			return fmt.Errorf("failed to create a test config: %w", err)
+		}
+		defer func() {
+			env.Close(selftestKeep)
+			if selftestKeep {
+				out.Printf("  → Kept %s\n", env.Home)
+			}
+			out.Println()
+		}()
+
+		var steps []selftest.Step
+		if realCLI {
+			cli := &selftest.CLI{}
+			if err := env.Seed(false); err != nil {
+				return fmt.Errorf("failed to seed the test config: %w", err)
+			}
+			steps = append([]selftest.Step{cli.VersionStep()}, env.Steps(cli, false)...)
+		} else {
+			if err := env.Seed(true); err != nil {
+				return fmt.Errorf("failed to seed the test config: %w", err)
+			}
+			steps = env.Steps(env.Direct(), true)
+		}
+
+		out.Printf("━━━ %s ━━━\n", title)
+		results := selftest.Run(ctx, steps)
+		showSelftestResults(out, results)
+		for _, r := range results {
+			if r.OK() {
+				passed++
+			}
+		}
+		total += len(results)
+		return nil
+	}
+
+	if err := run("Self-test", false); err != nil {
+		return err
+	}
+	if selftestRealCLI {
+		if err := run("Self-test with the claude CLI", true); err != nil {
+			return err
+		}
+	}
+
+	if passed < total {
+		return fmt.Errorf("self-test failed: %d of %d steps passed", passed, total)
+	}
+	out.Printf("✓ All %d steps passed\n", total)
+	return nil
+}
+
+func showSelftestResults(out ui.Printer, results []selftest.Result) {
+	w := tabwriter.NewWriter(out.Out(), 0, 0, 2, ' ', 0)
+	for _, r := range results {
+		switch r.Status {
+		case selftest.StatusOK:
+			fmt.Fprintf(w, "  ✓ %s\t%s\n", r.Name, formatStepDuration(r.Duration))
+		case selftest.StatusSkipped:
+			fmt.Fprintf(w, "  - %s\tskipped\n", r.Name)
+		default:
+			fmt.Fprintf(w, "  ✗ %s\t%s\n", r.Name, formatStepDuration(r.Duration))
+		}
+	}
+	w.Flush()
+	for _, r := range results {
+		if r.Status == selftest.StatusFailed {
+			out.Printf("\n  %s: %s\n", r.Name, r.Error)
+		}
+	}
+}
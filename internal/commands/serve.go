@@ -0,0 +1,104 @@
+// ABOUTME: Serve command running the local HTTP API for GUI and editor frontends
+// ABOUTME: Binds to localhost only and requires a bearer token on every request
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/history"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/server"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/claudeup/claudeup/pkg/claudeup"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr  string
+	serveToken string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local HTTP API for managing Claude Code configuration",
+	Long: `Starts a small HTTP API on localhost so GUI frontends and editor plugins can
+manage profiles through claudeup instead of scraping CLI output.
+
+Every request must send "Authorization: Bearer <token>". Unless --token is
+given, a random token is generated and written to ~/.claudeup/serve.token
+(readable only by you).
+
+Endpoints:
+  GET  /v1/profiles               List profiles
+  GET  /v1/profiles/{name}        Show a profile
+  GET  /v1/profiles/{name}/diff   Changes applying the profile would make
+  POST /v1/profiles/{name}/apply  Apply the profile
+  GET  /v1/status                 Active profile and current state
+  GET  /v1/history                Recorded profile operations`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:7420", "Listen address (loopback only)")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Bearer token clients must send (default: generated)")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if err := server.CheckLoopback(serveAddr); err != nil {
+		return err
+	}
+
+	token := serveToken
+	tokenPath := pathctx.From(cmd.Context()).Claudeup("serve.token")
+	if token == "" {
+		var err error
+		token, err = server.GenerateToken()
+		if err != nil {
+			return fmt.Errorf("failed to generate token: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(tokenPath), 0755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		if err := os.WriteFile(tokenPath, []byte(token+"\n"), 0600); err != nil {
+			return fmt.Errorf("failed to write token file: %w", err)
+		}
+	}
+
+	client, err := claudeup.New(claudeup.Options{
+		ClaudeDir:      claudeDir,
+		ClaudeJSONPath: profile.DefaultClaudeJSONPath(),
+		ProfilesDir:    getProfilesDir(),
+		Secrets:        buildSecretChain(),
+		ReadOnly:       readOnlyReason() != "",
+	})
+	if err != nil {
+		return err
+	}
+
+	api := server.New(client, token, history.DefaultPath())
+	api.Notifier = loadNotifier()
+	api.BeforeApply = serveBeforeApply(out)
+	api.AfterApply = serveAfterApply(out)
+
+	srv := &http.Server{
+		Addr:              serveAddr,
+		Handler:           api.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	out.Printf("Serving claudeup API on http://%s\n", serveAddr)
+	if serveToken == "" {
+		out.Printf("  Token written to %s\n", tokenPath)
+	}
+	out.Println("Press Ctrl+C to stop.")
+
+	return srv.ListenAndServe()
+}
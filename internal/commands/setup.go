@@ -4,21 +4,30 @@ package commands
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/claudeup/claudeup/internal/claude/clicompat"
 	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/i18n"
+	"github.com/claudeup/claudeup/internal/pathctx"
 	"github.com/claudeup/claudeup/internal/profile"
 	"github.com/claudeup/claudeup/internal/secrets"
+	"github.com/claudeup/claudeup/internal/snapshot"
+	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	setupProfile string
+	setupProfile        string
+	setupFromSnapshot   string
+	setupAssumeExisting string
+	setupInstallClaude  bool
 )
 
 var setupCmd = &cobra.Command{
@@ -28,23 +37,55 @@ var setupCmd = &cobra.Command{
 
 Installs Claude CLI if missing, then applies the specified profile.
 If an existing installation is detected, offers to save current state
-as a profile before applying the new one.`,
+as a profile before applying the new one.
+
+Every question has a flag for unattended runs, such as a new machine
+provisioned by a dotfiles manager:
+
+  claudeup setup --profile work --assume-existing save-as=old --yes`,
 	RunE: runSetup,
 }
 
 func init() {
 	rootCmd.AddCommand(setupCmd)
 	setupCmd.Flags().StringVar(&setupProfile, "profile", "default", "Profile to apply")
+	setupCmd.Flags().StringVar(&setupFromSnapshot, "from-snapshot", "", "Apply the plugins, marketplaces, and MCP servers of a snapshot (ID, label, or file) instead of a profile")
+	setupCmd.Flags().StringVar(&setupAssumeExisting, "assume-existing", "", "Handle an existing installation without asking: save-as=<name>, continue, or abort")
+	setupCmd.Flags().BoolVar(&setupInstallClaude, "install-claude", true, "Install or upgrade the Claude CLI when missing or outdated; false fails instead")
+	setupCmd.MarkFlagsMutuallyExclusive("profile", "from-snapshot")
+	addFailOnErrorFlag(setupCmd)
+	addForceFlag(setupCmd)
+	addCategoryFlag(setupCmd)
+	addGrantPermissionsFlag(setupCmd)
+	addPurgeFlag(setupCmd)
+	addReplayFlags(setupCmd)
+	addInstallerFlag(setupCmd)
 }
 
 func runSetup(cmd *cobra.Command, args []string) error {
-	fmt.Println("━━━ Claude PM Setup ━━━")
-	fmt.Println()
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	assumed, err := parseAssumeExisting(setupAssumeExisting)
+	if err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	out.Println("━━━ Claude PM Setup ━━━")
+	out.Println()
 
-	// Step 1: Check for Claude CLI
-	if err := ensureClaudeCLI(); err != nil {
+	executor, finish, err := claudeExecutor(out)
+	if err != nil {
 		return err
 	}
+	defer finish()
+
+	// Step 1: Check for Claude CLI (not needed when replaying)
+	if replayFixtures == "" {
+		if err := ensureClaudeCLI(cmd.Context(), out); err != nil {
+			return err
+		}
+	}
 
 	// Step 2: Ensure profiles directory and default profiles exist
 	profilesDir := getProfilesDir()
@@ -53,58 +94,81 @@ func runSetup(cmd *cobra.Command, args []string) error {
 	}
 
 	// Step 4: Check for existing installation
-	claudeDir := profile.DefaultClaudeDir()
 	claudeJSONPath := profile.DefaultClaudeJSONPath()
 
 	existing, err := profile.Snapshot("existing", claudeDir, claudeJSONPath)
 	if err == nil && hasContent(existing) {
-		if err := handleExistingInstallation(existing, profilesDir); err != nil {
+		if err := handleExistingInstallation(out, existing, profilesDir, assumed); err != nil {
 			return err
 		}
 	}
 
 	// Step 5: Load and show the profile
-	p, err := profile.Load(profilesDir, setupProfile)
+	p, err := loadSetupProfile(profilesDir)
 	if err != nil {
-		return fmt.Errorf("failed to load profile %q: %w", setupProfile, err)
+		return err
 	}
 
-	fmt.Printf("Using profile: %s\n", p.Name)
+	out.Printf("Using profile: %s\n", p.Name)
 	if p.Description != "" {
-		fmt.Printf("  %s\n", p.Description)
+		out.Printf("  %s\n", p.Description)
+	}
+	out.Println()
+
+	if p, err = runSetupWizard(cmd, out, p, true); err != nil {
+		return err
 	}
-	fmt.Println()
+	showProfileSummary(out, p)
 
-	showProfileSummary(p)
+	chain := buildSecretChain()
+	if err := ensureProfileEnv(out, p, chain); err != nil {
+		return err
+	}
 
 	// Step 6: Confirm (unless --yes)
-	if !confirmProceed() {
-		fmt.Println("Setup cancelled.")
+	if err := checkClaudeNotRunning(cmd.Context(), out); err != nil {
+		return err
+	}
+	if !confirmProceed(out) {
+		out.Println("Setup cancelled.")
 		return nil
 	}
 
 	// Step 7: Apply the profile
-	fmt.Println()
-	fmt.Println("Applying profile...")
+	out.Println()
+	out.Println("Applying profile...")
 
-	chain := buildSecretChain()
-	result, err := profile.Apply(p, claudeDir, claudeJSONPath, chain)
+	purgeable := purgeCandidates(claudeDir)
+	result, err := profile.ApplyWithExecutor(cmd.Context(), p, claudeDir, claudeJSONPath, chain, executor)
 	if err != nil {
-		return fmt.Errorf("failed to apply profile: %w", err)
+		return applyFailed(out, result, err)
+	}
+	if setupFromSnapshot == "" {
+		stampApplied(out, p.Name)
 	}
+	purgeRemovedPlugins(claudeDir, purgeable, result)
 
 	// Step 8: Show results
-	showApplyResults(result)
+	showApplyResults(out, result)
+	offerSecretWizard(cmd.Context(), out, result.UnresolvedSecrets, chain)
+	warmAfterApply(cmd.Context(), out, p, result)
+	if err := seedPermissions(out, p, claudeDir, claudeJSONPath); err != nil {
+		out.Printf("  ⚠ Could not pre-seed permissions: %v\n", err)
+	}
 
 	// Step 9: Run doctor
-	fmt.Println()
-	fmt.Println("Running health check...")
+	out.Println()
+	out.Println("Running health check...")
 	if err := runDoctor(cmd, nil); err != nil {
-		fmt.Printf("  ⚠ Health check encountered issues: %v\n", err)
+		out.Printf("  ⚠ Health check encountered issues: %v\n", err)
 	}
 
-	fmt.Println()
-	fmt.Println("✓ Setup complete!")
+	out.Println()
+	if len(result.Errors) > 0 {
+		out.Println("⚠ Setup finished with errors")
+		return applyExitError(result)
+	}
+	out.Println("✓ Setup complete!")
 
 	return nil
 }
@@ -113,67 +177,72 @@ func runSetup(cmd *cobra.Command, args []string) error {
 // Versions before 1.0.80 have Ink raw mode issues when stdin is not properly connected
 const minClaudeVersion = "1.0.80"
 
-func ensureClaudeCLI() error {
-	fmt.Print("Checking for Claude CLI... ")
+func ensureClaudeCLI(ctx context.Context, out ui.Printer) error {
+	installer, err := pickInstaller(claudeInstallerFlag, exec.LookPath)
+	if err != nil {
+		return err
+	}
+	out.Print("Checking for Claude CLI... ")
 
-	if _, err := exec.LookPath("claude"); err == nil {
+	if path, err := exec.LookPath("claude"); err == nil {
 		version := getClaudeVersion()
 		if version != "unknown" && isVersionOutdated(version, minClaudeVersion) {
-			fmt.Printf("⚠ outdated (%s)\n", version)
-			fmt.Println()
-			fmt.Printf("Claude CLI version %s is installed, but version %s or newer is required.\n", version, minClaudeVersion)
-			fmt.Println("Older versions have known issues with terminal handling that cause setup to fail.")
-			fmt.Println()
-			return promptClaudeUpgrade(version)
+			out.Printf("⚠ outdated (%s)\n", version)
+			out.Println()
+			out.Printf("Claude CLI version %s is installed, but version %s or newer is required.\n", version, minClaudeVersion)
+			out.Println("Older versions have known issues with terminal handling that cause setup to fail.")
+			out.Println()
+			return promptClaudeUpgrade(ctx, out, path, version)
 		}
-		fmt.Printf("✓ found (%s)\n", version)
+		out.Printf("✓ found (%s)\n", version)
 		return nil
 	}
 
-	fmt.Println("not found")
-	fmt.Println()
-	fmt.Println("Claude CLI is required but not installed.")
-	fmt.Println()
+	out.Println("not found")
+	out.Println()
+	out.Println("Claude CLI is required but not installed.")
+	out.Println()
+	if !setupInstallClaude {
+		return fmt.Errorf("Claude CLI not installed, and --install-claude=false")
+	}
 
 	// Auto-install with --yes, otherwise ask
 	if !config.YesFlag {
-		fmt.Println("Would you like to install it now using the official installer?")
-		fmt.Println()
-		fmt.Println("  ⚠️  Warning: This will download and execute code from the internet.")
-		fmt.Println("     Command: curl -fsSL https://claude.ai/install.sh | bash")
-		fmt.Println()
-		choice := promptChoice("Install Claude CLI?", "y")
+		out.Printf("Would you like to install it now with %s?\n", installer.Name)
+		out.Println()
+		if installer.Remote {
+			out.Println("  ⚠️  Warning: This will download and execute code from the internet.")
+		}
+		out.Printf("     Command: %s\n", installer.command(false))
+		out.Println()
+		choice := promptChoice(out, "Install Claude CLI?", "y")
 		if strings.ToLower(choice) != "y" && strings.ToLower(choice) != "yes" {
-			fmt.Println()
-			fmt.Println("To install manually, visit: https://docs.anthropic.com/en/docs/claude-code/getting-started")
-			fmt.Println()
-			fmt.Println("Then run 'claudeup setup' again.")
+			out.Println()
+			out.Println("To install manually, visit: https://docs.anthropic.com/en/docs/claude-code/getting-started")
+			out.Println()
+			out.Println("Then run 'claudeup setup' again.")
 			return fmt.Errorf("Claude CLI not installed")
 		}
 	}
 
-	fmt.Println()
-	fmt.Println("Installing Claude CLI...")
+	out.Println()
+	out.Println("Installing Claude CLI...")
 
-	if err := runClaudeInstaller(); err != nil {
-		return fmt.Errorf("failed to install Claude CLI: %w", err)
+	if err := installer.run(ctx, out, false); err != nil {
+		return fmt.Errorf("failed to install Claude CLI with %s: %w", installer.Name, err)
 	}
 
-	fmt.Println("  ✓ Claude CLI installed")
+	out.Println("  ✓ Claude CLI installed")
 	return nil
 }
 
-// runClaudeInstaller runs the official Claude CLI installer script
-func runClaudeInstaller() error {
-	cmd := exec.Command("bash", "-c", "curl -fsSL https://claude.ai/install.sh | bash")
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+func getClaudeVersion() string {
+	return claudeVersion(context.Background())
 }
 
-func getClaudeVersion() string {
-	cmd := exec.Command("claude", "--version")
+// claudeVersion runs 'claude --version', giving up when ctx is done
+func claudeVersion(ctx context.Context) string {
+	cmd := exec.CommandContext(ctx, "claude", "--version")
 	output, err := cmd.Output()
 	if err != nil {
 		return "unknown"
@@ -182,78 +251,48 @@ func getClaudeVersion() string {
 }
 
 // isVersionOutdated returns true if current version is older than minimum version
-// Uses simple string comparison of semver-like versions (e.g., "1.0.72" vs "1.0.80")
 func isVersionOutdated(current, minimum string) bool {
-	currentParts := parseVersion(current)
-	minimumParts := parseVersion(minimum)
-
-	for i := 0; i < len(minimumParts); i++ {
-		if i >= len(currentParts) {
-			return true // Current has fewer parts, treat as older
-		}
-		if currentParts[i] < minimumParts[i] {
-			return true
-		}
-		if currentParts[i] > minimumParts[i] {
-			return false
-		}
-	}
-	return false
+	return clicompat.Compare(current, minimum) < 0
 }
 
 // parseVersion extracts numeric parts from a version string
-// Handles formats like "1.0.72", "claude 1.0.72", "v1.0.72"
 func parseVersion(version string) []int {
-	// Remove common prefixes
-	version = strings.TrimPrefix(version, "v")
-	version = strings.TrimPrefix(version, "claude ")
-
-	parts := strings.Split(version, ".")
-	nums := make([]int, 0, len(parts))
-
-	for _, part := range parts {
-		// Extract numeric portion (handles cases like "72-beta")
-		numStr := ""
-		for _, c := range part {
-			if c >= '0' && c <= '9' {
-				numStr += string(c)
-			} else {
-				break
-			}
-		}
-		if numStr != "" {
-			if num, err := strconv.Atoi(numStr); err == nil {
-				nums = append(nums, num)
-			}
-		}
-	}
-	return nums
+	return clicompat.Parse(version)
 }
 
 // promptClaudeUpgrade asks the user if they want to upgrade Claude CLI
-func promptClaudeUpgrade(currentVersion string) error {
+func promptClaudeUpgrade(ctx context.Context, out ui.Printer, claudePath, currentVersion string) error {
+	upgrader, err := pickUpgrader(claudeInstallerFlag, claudePath, exec.LookPath)
+	if err != nil {
+		return err
+	}
+	if !setupInstallClaude {
+		return fmt.Errorf("Claude CLI version %s is outdated (minimum: %s), and --install-claude=false", currentVersion, minClaudeVersion)
+	}
 	if !config.YesFlag {
-		fmt.Println("Would you like to upgrade Claude CLI now using the official installer?")
-		fmt.Println()
-		fmt.Println("  ⚠️  Warning: This will download and execute code from the internet.")
-		fmt.Println("     Command: curl -fsSL https://claude.ai/install.sh | bash")
-		fmt.Println()
-		choice := promptChoice("Upgrade Claude CLI?", "y")
+		out.Printf("Would you like to upgrade Claude CLI now with %s?\n", upgrader.Name)
+		out.Println()
+		if upgrader.Remote {
+			out.Println("  ⚠️  Warning: This will download and execute code from the internet.")
+		}
+		out.Printf("     Command: %s\n", upgrader.command(true))
+		out.Println()
+		choice := promptChoice(out, "Upgrade Claude CLI?", "y")
 		if strings.ToLower(choice) != "y" && strings.ToLower(choice) != "yes" {
-			fmt.Println()
-			fmt.Println("To upgrade manually, run:")
-			fmt.Println("  curl -fsSL https://claude.ai/install.sh | bash")
-			fmt.Println()
-			fmt.Println("Then run 'claudeup setup' again.")
+			out.Println()
+			out.Println("To upgrade manually, run:")
+			out.Printf("  %s\n", upgrader.command(true))
+			out.Println()
+			out.Println("Then run 'claudeup setup' again.")
 			return fmt.Errorf("Claude CLI version %s is outdated (minimum: %s)", currentVersion, minClaudeVersion)
 		}
 	}
 
-	fmt.Println()
-	fmt.Println("Upgrading Claude CLI...")
+	out.Println()
+	out.Println("Upgrading Claude CLI...")
 
-	if err := runClaudeInstaller(); err != nil {
-		return fmt.Errorf("failed to upgrade Claude CLI: %w", err)
+	if err := upgrader.run(ctx, out, true); err != nil {
+		return fmt.Errorf("failed to upgrade Claude CLI with %s: %w", upgrader.Name, err)
 	}
 
 	// Verify the upgrade succeeded
@@ -262,44 +301,100 @@ func promptClaudeUpgrade(currentVersion string) error {
 		return fmt.Errorf("Claude CLI upgrade did not resolve version issue (still %s, need %s)", newVersion, minClaudeVersion)
 	}
 
-	fmt.Printf("  ✓ Claude CLI upgraded to %s\n", newVersion)
+	out.Printf("  ✓ Claude CLI upgraded to %s\n", newVersion)
 	return nil
 }
 
 func getProfilesDir() string {
-	return filepath.Join(profile.MustHomeDir(), ".claudeup", "profiles")
+	return pathctx.Default().ProfilesDir
+}
+
+// loadSetupProfile loads the profile setup applies: --profile, or the
+// profile made from the snapshot named by --from-snapshot
+func loadSetupProfile(profilesDir string) (*profile.Profile, error) {
+	if setupFromSnapshot != "" {
+		snap, err := snapshot.Open(snapshot.DefaultDir(), setupFromSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		p := profile.FromState("snapshot "+snap.ID, snap.State)
+		p.Description = "Recorded " + snap.Taken.Local().Format("2006-01-02 15:04:05")
+		return p, nil
+	}
+
+	name, err := resolveProfileName(profilesDir, setupProfile)
+	if err != nil {
+		return nil, err
+	}
+	p, err := profile.Load(profilesDir, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profile %q: %w", setupProfile, err)
+	}
+	return p.ForThisMachine()
+}
+
+// existingChoice answers the existing installation question: s to save
+// as name, c to continue, or a to abort. The zero value asks.
+type existingChoice struct {
+	action string
+	name   string
+}
+
+// parseAssumeExisting parses --assume-existing
+func parseAssumeExisting(value string) (existingChoice, error) {
+	switch {
+	case value == "":
+		return existingChoice{}, nil
+	case value == "continue":
+		return existingChoice{action: "c"}, nil
+	case value == "abort":
+		return existingChoice{action: "a"}, nil
+	case strings.HasPrefix(value, "save-as="):
+		name := strings.TrimPrefix(value, "save-as=")
+		if err := profile.ValidateName(name); err != nil {
+			return existingChoice{}, err
+		}
+		return existingChoice{action: "s", name: name}, nil
+	}
+	return existingChoice{}, fmt.Errorf("invalid --assume-existing %q: use save-as=<name>, continue, or abort", value)
 }
 
 func hasContent(p *profile.Profile) bool {
 	return len(p.Plugins) > 0 || len(p.MCPServers) > 0 || len(p.Marketplaces) > 0
 }
 
-func handleExistingInstallation(existing *profile.Profile, profilesDir string) error {
-	fmt.Println("Existing Claude Code installation detected:")
-	fmt.Printf("  → %d MCP servers, %d marketplaces, %d plugins\n",
+func handleExistingInstallation(out ui.Printer, existing *profile.Profile, profilesDir string, assumed existingChoice) error {
+	out.Println("Existing Claude Code installation detected:")
+	out.Printf("  → %d MCP servers, %d marketplaces, %d plugins\n",
 		len(existing.MCPServers), len(existing.Marketplaces), len(existing.Plugins))
-	fmt.Println()
-	fmt.Println("Options:")
-	fmt.Println("  [s] Save current setup as a profile, then continue")
-	fmt.Println("  [c] Continue anyway (will replace current setup)")
-	fmt.Println("  [a] Abort")
-	fmt.Println()
+	out.Println()
 
-	choice := promptChoice("Choice", "s")
+	choice := assumed.action
+	if choice == "" {
+		out.Println("Options:")
+		out.Println("  [s] Save current setup as a profile, then continue")
+		out.Println("  [c] Continue anyway (will replace current setup)")
+		out.Println("  [a] Abort")
+		out.Println()
+		choice = promptChoice(out, "Choice", "s")
+	}
 
 	switch strings.ToLower(choice) {
 	case "s":
-		name := promptString("Profile name", "current")
+		name := assumed.name
+		if name == "" {
+			name = promptString(out, "Profile name", "current")
+		}
 		existing.Name = name
 		existing.Description = "Saved from existing installation"
 		if err := profile.Save(profilesDir, existing); err != nil {
 			return fmt.Errorf("failed to save profile: %w", err)
 		}
-		fmt.Printf("  ✓ Saved as '%s'\n", name)
-		fmt.Println()
+		out.Printf("  ✓ Saved as '%s'\n", name)
+		out.Println()
 	case "c":
-		fmt.Println("  Continuing without saving...")
-		fmt.Println()
+		out.Println("  Continuing without saving...")
+		out.Println()
 	case "a":
 		return fmt.Errorf("setup aborted by user")
 	default:
@@ -309,44 +404,44 @@ func handleExistingInstallation(existing *profile.Profile, profilesDir string) e
 	return nil
 }
 
-func showProfileSummary(p *profile.Profile) {
-	fmt.Println("Profile contents:")
+func showProfileSummary(out ui.Printer, p *profile.Profile) {
+	out.Println("Profile contents:")
 	if len(p.MCPServers) > 0 {
-		fmt.Printf("  MCP Servers:   %d\n", len(p.MCPServers))
+		out.Printf("  MCP Servers:   %d\n", len(p.MCPServers))
 		for _, m := range p.MCPServers {
-			fmt.Printf("    - %s\n", m.Name)
+			out.Printf("    - %s\n", m.Name)
 		}
 	}
 	if len(p.Marketplaces) > 0 {
-		fmt.Printf("  Marketplaces:  %d\n", len(p.Marketplaces))
+		out.Printf("  Marketplaces:  %d\n", len(p.Marketplaces))
 		for _, m := range p.Marketplaces {
-			fmt.Printf("    - %s\n", m.Repo)
+			out.Printf("    - %s\n", m.DisplayName())
 		}
 	}
 	if len(p.Plugins) > 0 {
-		fmt.Printf("  Plugins:       %d\n", len(p.Plugins))
+		out.Printf("  Plugins:       %d\n", len(p.Plugins))
 		for _, plug := range p.Plugins {
-			fmt.Printf("    - %s\n", plug)
+			out.Printf("    - %s\n", plug)
 		}
 	}
-	fmt.Println()
+	out.Println()
 }
 
-func confirmProceed() bool {
+func confirmProceed(out ui.Printer) bool {
 	if config.YesFlag {
 		return true
 	}
 
-	choice := promptChoice("Proceed?", "y")
+	choice := promptChoice(out, "Proceed?", "y")
 	return strings.ToLower(choice) == "y" || strings.ToLower(choice) == "yes"
 }
 
-func promptChoice(prompt, defaultValue string) string {
+func promptChoice(out ui.Printer, prompt, defaultValue string) string {
 	if config.YesFlag {
 		return defaultValue
 	}
 
-	fmt.Printf("%s [%s]: ", prompt, defaultValue)
+	out.Promptf("%s [%s]: ", prompt, defaultValue)
 	reader := bufio.NewReader(os.Stdin)
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
@@ -357,12 +452,12 @@ func promptChoice(prompt, defaultValue string) string {
 	return input
 }
 
-func promptString(prompt, defaultValue string) string {
+func promptString(out ui.Printer, prompt, defaultValue string) string {
 	if config.YesFlag {
 		return defaultValue
 	}
 
-	fmt.Printf("%s [%s]: ", prompt, defaultValue)
+	out.Promptf("%s [%s]: ", prompt, defaultValue)
 	reader := bufio.NewReader(os.Stdin)
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
@@ -374,41 +469,95 @@ func promptString(prompt, defaultValue string) string {
 }
 
 func buildSecretChain() *secrets.Chain {
-	return secrets.NewChain(
+	chain := secrets.NewChain(
 		secrets.NewEnvResolver(),
+		secrets.NewEnvFileResolver(secretsEnvFile()),
 		secrets.NewOnePasswordResolver(),
+		secrets.NewBitwardenResolver(),
+		secrets.NewSopsResolver(pathctx.Default().ClaudeupDir),
 		secrets.NewKeychainResolver(),
 	)
+	if cache := configuredSecretCache(); cache != nil {
+		chain.SetCache(cache)
+	}
+	return chain
 }
 
-func showApplyResults(result *profile.ApplyResult) {
-	if len(result.PluginsRemoved) > 0 {
-		fmt.Printf("  Removed %d plugins\n", len(result.PluginsRemoved))
-	}
-	if len(result.PluginsAlreadyRemoved) > 0 {
-		fmt.Printf("  ✓ %d plugins were already uninstalled\n", len(result.PluginsAlreadyRemoved))
+// applyFailed shows the changes that finished before an apply stopped, then
+// returns err wrapped for the caller
+func applyFailed(out ui.Printer, result *profile.ApplyResult, err error) error {
+	if result != nil {
+		out.Println(i18n.T("Finished before stopping:"))
+		showApplyResults(out, result)
+		out.Println()
 	}
-	if len(result.PluginsInstalled) > 0 {
-		fmt.Printf("  Installed %d plugins\n", len(result.PluginsInstalled))
+	return fmt.Errorf("failed to apply profile: %w", err)
+}
+
+func showApplyResults(out ui.Printer, result *profile.ApplyResult) {
+	if len(result.Steps) > 0 {
+		w := tabwriter.NewWriter(out.Out(), 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, i18n.T("  ITEM\tACTION\tRESULT\tDURATION"))
+		for _, step := range result.Steps {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", step.Item.Target(), step.Item.Action, stepResult(step), formatStepDuration(step.Duration))
+		}
+		w.Flush()
 	}
-	if len(result.PluginsAlreadyPresent) > 0 {
-		fmt.Printf("  ✓ %d plugins were already installed\n", len(result.PluginsAlreadyPresent))
+	if result.Elapsed > 0 {
+		out.Printf(i18n.T("  → Took %s\n"), formatStepDuration(result.Elapsed))
 	}
-	if len(result.MCPServersRemoved) > 0 {
-		fmt.Printf("  Removed %d MCP servers\n", len(result.MCPServersRemoved))
+	if slowest := slowestSteps(result); slowest != "" {
+		out.Printf(i18n.T("  → Slowest: %s\n"), slowest)
 	}
-	if len(result.MCPServersInstalled) > 0 {
-		fmt.Printf("  Installed %d MCP servers\n", len(result.MCPServersInstalled))
+	for _, sub := range result.Skipped {
+		out.Printf(i18n.T("  → Skipped %s\n"), sub)
 	}
-	if len(result.MarketplacesAdded) > 0 {
-		fmt.Printf("  Added %d marketplaces\n", len(result.MarketplacesAdded))
+	if len(result.PluginsPurged) > 0 {
+		out.Printf(i18n.T("  → Reclaimed %s from %d plugin caches\n"), formatSize(int(result.ReclaimedBytes)), len(result.PluginsPurged))
 	}
 
 	if len(result.Errors) > 0 {
-		fmt.Println()
-		fmt.Println("  ⚠ Some operations had errors:")
+		out.Println()
+		out.Println(i18n.T("  ⚠ Some operations had errors:"))
 		for _, err := range result.Errors {
-			fmt.Printf("    - %v\n", err)
+			out.Printf("    - %v\n", err)
 		}
 	}
 }
+
+// slowestSteps names the three slowest steps with their durations, when
+// there are more steps than that and the table is long enough to hide them
+func slowestSteps(result *profile.ApplyResult) string {
+	if len(result.Steps) <= 3 {
+		return ""
+	}
+	var slowest []string
+	for _, step := range result.Slowest(3) {
+		slowest = append(slowest, fmt.Sprintf("%s (%s)", step.Item.Target(), formatStepDuration(step.Duration)))
+	}
+	return strings.Join(slowest, ", ")
+}
+
+func stepResult(step profile.ApplyStep) string {
+	switch step.Result {
+	case profile.StepFailed:
+		return "✗ failed"
+	case profile.StepAlready:
+		return "✓ unchanged"
+	}
+	return "✓ done"
+}
+
+// formatStepDuration rounds to a readable precision; steps that weren't
+// timed, like plugins restored from a bundle, show as "-"
+func formatStepDuration(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "-"
+	case d < time.Millisecond:
+		return "<1ms"
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
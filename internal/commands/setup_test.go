@@ -0,0 +1,125 @@
+// ABOUTME: Tests for setup and the apply summary it shares with profile use
+// ABOUTME: Covers unattended answers, applying a snapshot, and reported timings
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/snapshot"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func TestParseAssumeExisting(t *testing.T) {
+	tests := []struct {
+		value string
+		want  existingChoice
+	}{
+		{"", existingChoice{}},
+		{"save-as=old", existingChoice{action: "s", name: "old"}},
+		{"save-as=team/old", existingChoice{action: "s", name: "team/old"}},
+		{"continue", existingChoice{action: "c"}},
+		{"abort", existingChoice{action: "a"}},
+	}
+	for _, tt := range tests {
+		if got, err := parseAssumeExisting(tt.value); err != nil || got != tt.want {
+			t.Errorf("%q: expected %+v, got %+v, %v", tt.value, tt.want, got, err)
+		}
+	}
+	for _, value := range []string{"save", "save-as=", "save-as=../x", "replace"} {
+		if _, err := parseAssumeExisting(value); err == nil {
+			t.Errorf("Expected %q to be refused", value)
+		}
+	}
+}
+
+func TestHandleExistingInstallationAssumed(t *testing.T) {
+	profilesDir := t.TempDir()
+	existing := &profile.Profile{Plugins: []string{"tool@acme"}}
+
+	var buf bytes.Buffer
+	out := ui.NewPrinter(&buf, &buf, false)
+	if err := handleExistingInstallation(out, existing, profilesDir, existingChoice{action: "s", name: "old"}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := profile.Load(profilesDir, "old")
+	if err != nil || len(saved.Plugins) != 1 {
+		t.Errorf("Expected the installation saved as old, got %+v, %v", saved, err)
+	}
+	if strings.Contains(buf.String(), "Options:") {
+		t.Errorf("Expected no question with an assumed answer:\n%s", buf.String())
+	}
+
+	if err := handleExistingInstallation(out, existing, profilesDir, existingChoice{action: "a"}); err == nil {
+		t.Error("Expected abort to stop setup")
+	}
+}
+
+func TestLoadSetupProfileFromSnapshot(t *testing.T) {
+	defer pathctx.Override(pathctx.ForHome(t.TempDir()))()
+	defer func() { setupFromSnapshot = "" }()
+
+	file := filepath.Join(t.TempDir(), "laptop.json")
+	snap := &snapshot.Snapshot{ID: "20260101-120000-laptop", Taken: time.Now(), State: &state.FullState{
+		Plugins:    map[string][]state.PluginMetadata{"tool@acme": {{Scope: "user"}}},
+		MCPServers: map[string]state.MCPServer{"db": {Command: "npx"}},
+	}}
+	if err := snapshot.Save(filepath.Dir(file), snap); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(filepath.Dir(file), snap.ID+".json"), file); err != nil {
+		t.Fatal(err)
+	}
+
+	setupFromSnapshot = file
+	p, err := loadSetupProfile(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Plugins) != 1 || p.Plugins[0] != "tool@acme" || len(p.MCPServers) != 1 || p.MCPServers[0].Command != "npx" {
+		t.Errorf("Expected the snapshot's plugins and MCP servers, got %+v", p)
+	}
+
+	setupFromSnapshot = "missing"
+	if _, err := loadSetupProfile(t.TempDir()); err == nil {
+		t.Error("Expected an unknown snapshot to fail")
+	}
+}
+
+func TestShowApplyResultsTimings(t *testing.T) {
+	step := func(sub profile.Subsystem, name string, d time.Duration) profile.ApplyStep {
+		return profile.ApplyStep{Item: profile.DiffItem{Action: "install", Subsystem: sub, Name: name}, Result: profile.StepDone, Duration: d}
+	}
+	result := &profile.ApplyResult{
+		Steps: []profile.ApplyStep{
+			step(profile.SubsystemMarketplaces, "acme/plugins", 8*time.Second),
+			step(profile.SubsystemPlugins, "a@acme", 200*time.Millisecond),
+			step(profile.SubsystemPlugins, "b@acme", 3*time.Second),
+			step(profile.SubsystemMCP, "db", 1500*time.Millisecond),
+		},
+		Elapsed: 12700 * time.Millisecond,
+	}
+
+	var buf bytes.Buffer
+	showApplyResults(ui.NewPrinter(&buf, &buf, false), result)
+	for _, want := range []string{
+		"→ Took 12.7s",
+		"→ Slowest: marketplace acme/plugins (8s), plugin b@acme (3s), MCP server db (1.5s)",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, buf.String())
+		}
+	}
+
+	result.Steps = result.Steps[:3]
+	if got := slowestSteps(result); got != "" {
+		t.Errorf("Expected no slowest line when the table shows every step, got %q", got)
+	}
+}
@@ -0,0 +1,174 @@
+// ABOUTME: Snapshot subcommands for recording and comparing full Claude state over time
+// ABOUTME: Implements take, list, and diff, independent of profiles
+package commands
+
+import (
+	"fmt"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/snapshot"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Record and compare the full Claude Code state over time",
+	Long: `Snapshots store the complete state (plugins, MCP servers, marketplaces, settings)
+under ~/.claudeup/snapshots so you can see exactly what changed between two
+points in time - for example, what a plugin's installer did to your config.
+
+Snapshots are independent of profiles.`,
+}
+
+var snapshotTakeCmd = &cobra.Command{
+	Use:   "take [label]",
+	Short: "Record the current state",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runSnapshotTake,
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded snapshots",
+	Args:  cobra.NoArgs,
+	RunE:  runSnapshotList,
+}
+
+var snapshotDiffFormat string
+
+var snapshotDiffCmd = &cobra.Command{
+	Use:   "diff <a> <b>",
+	Short: "Show what changed between two snapshots",
+	Long: `Shows what changed going from snapshot <a> to snapshot <b>.
+
+A snapshot can be referenced by ID, label, or unique ID prefix.
+Use "current" to compare against the live state.
+Use --diff-format json or yaml for a versioned, machine-readable report.`,
+	Example: `  claudeup snapshot take before
+  claude plugin install some-plugin@marketplace
+  claudeup snapshot diff before current
+  claudeup snapshot diff before current --diff-format yaml`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSnapshotDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotTakeCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotDiffCmd)
+
+	snapshotDiffCmd.Flags().StringVar(&snapshotDiffFormat, "diff-format", "", "Print changes as json or yaml")
+}
+
+func runSnapshotTake(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	label := ""
+	if len(args) > 0 {
+		label = args[0]
+	}
+
+	snap, err := snapshot.Take(snapshot.DefaultDir(), label, claudeDir, profile.DefaultClaudeJSONPath())
+	if err != nil {
+		return err
+	}
+
+	out.Printf("✓ Snapshot %s recorded\n", snap.ID)
+	out.Printf("  %d plugins, %d MCP servers, %d marketplaces, %d settings\n",
+		len(snap.State.Plugins), len(snap.State.MCPServers), len(snap.State.Marketplaces), len(snap.State.Settings))
+	return nil
+}
+
+func runSnapshotList(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	snaps, err := snapshot.List(snapshot.DefaultDir())
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	if len(snaps) == 0 {
+		out.Println("No snapshots recorded.")
+		out.Println("Record one with: claudeup snapshot take [label]")
+		return nil
+	}
+
+	for _, s := range snaps {
+		out.Printf("  %s  %s\n", s.ID, s.Taken.Local().Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+func runSnapshotDiff(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	if err := validateFormat("diff-format", snapshotDiffFormat); err != nil {
+		return err
+	}
+
+	a, err := resolveSnapshotState(args[0])
+	if err != nil {
+		return err
+	}
+	b, err := resolveSnapshotState(args[1])
+	if err != nil {
+		return err
+	}
+
+	changes := snapshot.Diff(a, b)
+	if snapshotDiffFormat != "" {
+		return printFormatted(snapshotDiffFormat, snapshot.NewDiffReport(args[0], args[1], changes))
+	}
+	if len(changes) == 0 {
+		out.Printf("No changes between %s and %s.\n", args[0], args[1])
+		return nil
+	}
+
+	out.Printf("Changes from %s to %s:\n", args[0], args[1])
+	section := ""
+	for _, c := range changes {
+		if c.Section != section {
+			section = c.Section
+			out.Println()
+			out.Printf("━━━ %s ━━━\n", snapshotSectionTitle(section))
+		}
+		marker := map[string]string{snapshot.Added: "+", snapshot.Removed: "-", snapshot.Changed: "~"}[c.Kind]
+		if c.Detail != "" {
+			out.Printf("  %s %s (%s)\n", marker, c.Name, c.Detail)
+		} else {
+			out.Printf("  %s %s\n", marker, c.Name)
+		}
+	}
+	return nil
+}
+
+// resolveSnapshotState loads a stored snapshot, or the live state for "current"
+func resolveSnapshotState(ref string) (*state.FullState, error) {
+	if ref == "current" {
+		st, err := state.Capture(claudeDir, profile.DefaultClaudeJSONPath())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read current state: %w", err)
+		}
+		return st, nil
+	}
+
+	snap, err := snapshot.Find(snapshot.DefaultDir(), ref)
+	if err != nil {
+		return nil, err
+	}
+	return snap.State, nil
+}
+
+func snapshotSectionTitle(section string) string {
+	switch section {
+	case "plugins":
+		return "Plugins"
+	case "marketplaces":
+		return "Marketplaces"
+	case "mcpServers":
+		return "MCP Servers"
+	case "settings":
+		return "Settings"
+	}
+	return section
+}
@@ -5,9 +5,12 @@ package commands
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/claudeup/claudeup/internal/claude"
 	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/i18n"
+	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +26,8 @@ func init() {
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+
 	// Load marketplaces
 	marketplaces, err := claude.LoadMarketplaces(claudeDir)
 	if err != nil {
@@ -36,20 +41,31 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Print header
-	printHeader("claudeup Status")
+	printHeader(out, i18n.T("claudeup Status"))
 
 	// Print active profile
 	cfg, _ := config.Load()
-	activeProfile := "none"
-	if cfg != nil && cfg.Preferences.ActiveProfile != "" {
-		activeProfile = cfg.Preferences.ActiveProfile
+	activeProfile, drift := "none", ""
+	name, project := activeProfileHere(cfg)
+	if name != "" {
+		activeProfile = name
+		drift = describeDrift(activeProfile)
+	}
+	out.Printf(i18n.T("\nActive Profile: %s%s\n"), activeProfile, drift)
+	if project != "" {
+		out.Printf("Project:        %s (global profile: %s)\n", project, globalProfileName(cfg))
+	}
+	if w, ok := currentWorkspace(); ok {
+		out.Printf("Workspace:      %s → %s\n", w.Path, w.Profile)
+		if w.Profile != activeProfile {
+			out.Printf("  ⚠ This directory's workspace uses %s (run 'claudeup profile use %s')\n", w.Profile, w.Profile)
+		}
 	}
-	fmt.Printf("\nActive Profile: %s\n", activeProfile)
 
 	// Print marketplaces
-	fmt.Println("\nMarketplaces (" + fmt.Sprint(len(marketplaces)) + ")")
+	out.Printf(i18n.T("\nMarketplaces (%d)\n"), len(marketplaces))
 	for name := range marketplaces {
-		fmt.Printf("  ✓ %s\n", name)
+		out.Printf("  ✓ %s\n", name)
 	}
 
 	// Count enabled/disabled plugins and detect issues
@@ -66,41 +82,41 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Print plugins summary
-	fmt.Printf("\nPlugins (%d total)\n", len(plugins.GetAllPlugins()))
-	fmt.Printf("  ✓ %d enabled\n", enabledCount)
+	out.Printf(i18n.T("\nPlugins (%d total)\n"), len(plugins.GetAllPlugins()))
+	out.Printf(i18n.T("  ✓ %d enabled\n"), enabledCount)
 	if len(disabledPlugins) > 0 {
-		fmt.Printf("  ✗ %d disabled\n", len(disabledPlugins))
+		out.Printf(i18n.T("  ✗ %d disabled\n"), len(disabledPlugins))
 		for _, name := range disabledPlugins {
-			fmt.Printf("    - %s\n", name)
+			out.Printf("    - %s\n", name)
 		}
 	}
 
 	// Print MCP servers placeholder
-	fmt.Println("\nMCP Servers")
-	fmt.Println("  → Run 'claudeup mcp list' for details")
+	out.Println(i18n.T("\nMCP Servers"))
+	out.Println(i18n.T("  → Run 'claudeup mcp list' for details"))
 
 	// Print issues if any
 	if len(stalePlugins) > 0 {
-		fmt.Println("\nIssues Detected")
-		fmt.Printf("  ⚠ %d plugins have stale paths\n", len(stalePlugins))
+		out.Println(i18n.T("\nIssues Detected"))
+		out.Printf(i18n.T("  ⚠ %d plugins have stale paths\n"), len(stalePlugins))
 		for _, name := range stalePlugins {
-			fmt.Printf("    - %s\n", name)
+			out.Printf("    - %s\n", name)
 		}
-		fmt.Println("  → Run 'claudeup doctor' for details")
+		out.Println(i18n.T("  → Run 'claudeup doctor' for details"))
 	}
 
 	return nil
 }
 
-func printHeader(title string) {
+func printHeader(out ui.Printer, title string) {
 	width := 40
 	border := "═"
-	padding := (width - len(title) - 2) / 2
+	padding := (width - utf8.RuneCountInString(title) - 2) / 2
 
-	fmt.Println("╔" + strings.Repeat(border, width) + "╗")
-	fmt.Printf("║%s%s%s║\n",
+	out.Println("╔" + strings.Repeat(border, width) + "╗")
+	out.Printf("║%s%s%s║\n",
 		strings.Repeat(" ", padding),
 		title,
-		strings.Repeat(" ", width-padding-len(title)))
-	fmt.Println("╚" + strings.Repeat(border, width) + "╝")
+		strings.Repeat(" ", width-padding-utf8.RuneCountInString(title)))
+	out.Println("╚" + strings.Repeat(border, width) + "╝")
 }
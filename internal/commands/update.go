@@ -3,19 +3,29 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
-	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/changelog"
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/diagnostics"
+	mkt "github.com/claudeup/claudeup/internal/marketplace"
+	"github.com/claudeup/claudeup/internal/state"
 	"github.com/claudeup/claudeup/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
 	updateCheckOnly bool
+	updateJSON      bool
+	updateScheduled bool
+	updateMajorOnly bool
+	updateSecurity  bool
 )
 
 var updateCmd = &cobra.Command{
@@ -24,101 +34,156 @@ var updateCmd = &cobra.Command{
 	Long: `Check if marketplaces or plugins have updates available and optionally apply them.
 
 By default, checks for updates and prompts to install them.
-Use --check-only to see what's available without making changes.`,
+Use --check-only to see what's available without making changes.
+
+Plugin updates show the plugin's own version from plugin.json, e.g.
+"1.2.0 → 1.4.1", and the commits that touched the plugin. Commits written
+as conventional commits ("feat:", "fix(auth)!:") are classified:
+--major-only keeps updates that bump the major version or include a
+breaking change, and --security-only keeps updates with a security fix
+(a "security" type or scope, or a CVE ID). Marketplaces are always checked.`,
 	RunE: runUpdate,
 }
 
 func init() {
 	rootCmd.AddCommand(updateCmd)
 	updateCmd.Flags().BoolVar(&updateCheckOnly, "check-only", false, "Check for updates without applying them")
+	updateCmd.Flags().BoolVar(&updateJSON, "json", false, "Print the check result as JSON (requires --check-only)")
+	updateCmd.Flags().BoolVar(&updateMajorOnly, "major-only", false, "Only show plugin updates with a major version bump or breaking change")
+	updateCmd.Flags().BoolVar(&updateSecurity, "security-only", false, "Only show plugin updates that include a security fix")
+	updateCmd.Flags().BoolVar(&updateScheduled, "scheduled", false, "Record the result in history and notify (used by 'claudeup schedule')")
+	updateCmd.Flags().MarkHidden("scheduled")
+	addForceFlag(updateCmd)
 }
 
 type MarketplaceUpdate struct {
-	Name          string
-	HasUpdate     bool
-	CurrentCommit string
-	LatestCommit  string
+	Name          string `json:"name"`
+	HasUpdate     bool   `json:"hasUpdate"`
+	CurrentCommit string `json:"currentCommit,omitempty"`
+	LatestCommit  string `json:"latestCommit,omitempty"`
 }
 
 type PluginUpdate struct {
-	Name          string
-	HasUpdate     bool
-	CurrentCommit string
-	LatestCommit  string
+	Name           string             `json:"name"`
+	HasUpdate      bool               `json:"hasUpdate"`
+	CurrentCommit  string             `json:"currentCommit,omitempty"`
+	LatestCommit   string             `json:"latestCommit,omitempty"`
+	CurrentVersion string             `json:"currentVersion,omitempty"`
+	LatestVersion  string             `json:"latestVersion,omitempty"`
+	Bump           string             `json:"bump,omitempty"`     // major, minor, or patch
+	Breaking       bool               `json:"breaking,omitempty"` // a commit is marked as a breaking change
+	Security       bool               `json:"security,omitempty"` // a commit is marked as a security fix
+	Changes        []changelog.Commit `json:"changes,omitempty"`  // commits that touched the plugin, newest first
+}
+
+// UpdateCheck is the machine-readable result of 'update --check-only --json'
+type UpdateCheck struct {
+	Marketplaces []MarketplaceUpdate `json:"marketplaces"`
+	Plugins      []PluginUpdate      `json:"plugins"`
+	Available    int                 `json:"available"`
 }
 
 func runUpdate(cmd *cobra.Command, args []string) error {
-	fmt.Println("Checking for updates...")
+	if !updateCheckOnly {
+		if err := checkWritable(cmd); err != nil {
+			return err
+		}
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	if updateJSON {
+		if !updateCheckOnly {
+			return fmt.Errorf("--json requires --check-only")
+		}
+		return runUpdateCheckJSON(cmd.Context(), out)
+	}
+
+	out.Println("Checking for updates...")
 
 	// Load marketplaces
-	marketplaces, err := claude.LoadMarketplaces(claudeDir)
+	marketplaces, err := state.LoadMarketplaces(claudeDir)
 	if err != nil {
 		return fmt.Errorf("failed to load marketplaces: %w", err)
 	}
 
 	// Load plugins
-	plugins, err := claude.LoadPlugins(claudeDir)
+	plugins, err := state.LoadPlugins(claudeDir)
 	if err != nil {
 		return fmt.Errorf("failed to load plugins: %w", err)
 	}
 
 	// Check marketplace updates
-	fmt.Println("━━━ Checking Marketplaces ━━━")
-	marketplaceUpdates := checkMarketplaceUpdates(marketplaces)
+	out.Println("━━━ Checking Marketplaces ━━━")
+	marketplaceUpdates := checkMarketplaceUpdates(cmd.Context(), marketplaces)
 
 	var outdatedMarketplaces []string
 	for _, update := range marketplaceUpdates {
 		if update.HasUpdate {
-			fmt.Printf("  ⚠ %s: Update available\n", update.Name)
+			out.Printf("  ⚠ %s: Update available\n", update.Name)
 			outdatedMarketplaces = append(outdatedMarketplaces, update.Name)
 		} else {
-			fmt.Printf("  ✓ %s: Up to date\n", update.Name)
+			out.Printf("  ✓ %s: Up to date\n", update.Name)
 		}
 	}
+	hintCloneStrategy(out, marketplaces)
 
 	// Check plugin updates
-	fmt.Println("\n━━━ Checking Plugins ━━━")
-	pluginUpdates := checkPluginUpdates(plugins, marketplaces)
+	out.Println("\n━━━ Checking Plugins ━━━")
+	pluginUpdates, hidden := filterPluginUpdates(checkPluginUpdates(cmd.Context(), plugins, marketplaces), updateMajorOnly, updateSecurity)
+	sort.Slice(pluginUpdates, func(i, j int) bool { return pluginUpdates[i].Name < pluginUpdates[j].Name })
 
 	var outdatedPlugins []string
 	for _, update := range pluginUpdates {
 		if update.HasUpdate {
-			fmt.Printf("  ⚠ %s: Update available\n", update.Name)
+			out.Printf("  ⚠ %s: %s\n", update.Name, versionSummary(update))
+			printPluginChanges(out, update)
 			outdatedPlugins = append(outdatedPlugins, update.Name)
 		}
 	}
 
-	if len(outdatedPlugins) == 0 {
-		fmt.Println("  ✓ All plugins up to date")
+	if len(outdatedPlugins) == 0 && hidden == 0 {
+		out.Println("  ✓ All plugins up to date")
+	}
+	if hidden > 0 {
+		noun := "updates"
+		if hidden == 1 {
+			noun = "update"
+		}
+		out.Printf("  %d other plugin %s hidden by %s\n", hidden, noun, filterNames(updateMajorOnly, updateSecurity))
 	}
 
 	// Summary
-	fmt.Println("\n━━━ Summary ━━━")
+	out.Println("\n━━━ Summary ━━━")
 	if len(outdatedMarketplaces) == 0 && len(outdatedPlugins) == 0 {
-		fmt.Println("✓ Everything is up to date!")
+		out.Println("✓ Everything is up to date!")
 		return nil
 	}
 
 	if updateCheckOnly {
 		if len(outdatedMarketplaces) > 0 {
-			fmt.Println("\nMarketplace updates available:")
+			out.Println("\nMarketplace updates available:")
 			for _, name := range outdatedMarketplaces {
-				fmt.Printf("  • %s\n", name)
+				out.Printf("  • %s\n", name)
 			}
 		}
 		if len(outdatedPlugins) > 0 {
-			fmt.Println("\nPlugin updates available:")
-			for _, name := range outdatedPlugins {
-				fmt.Printf("  • %s\n", name)
+			out.Println("\nPlugin updates available:")
+			for _, u := range pluginUpdates {
+				if u.HasUpdate {
+					out.Printf("  • %s (%s)\n", u.Name, versionSummary(u))
+				}
 			}
 		}
-		fmt.Println("\nRun without --check-only to apply updates")
+		out.Println("\nRun without --check-only to apply updates")
 		return nil
 	}
 
+	if err := checkClaudeNotRunning(cmd.Context(), out); err != nil {
+		return err
+	}
+
 	// Interactive selection for marketplaces
 	if len(outdatedMarketplaces) > 0 {
-		fmt.Println()
+		out.Println()
 		selectedMarketplaces, err := ui.SelectFromList(
 			"Select marketplaces to update:",
 			outdatedMarketplaces,
@@ -131,7 +196,7 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 
 	// Interactive selection for plugins
 	if len(outdatedPlugins) > 0 {
-		fmt.Println()
+		out.Println()
 		selectedPlugins, err := ui.SelectFromList(
 			"Select plugins to update:",
 			outdatedPlugins,
@@ -144,48 +209,123 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 
 	// Check if user selected anything
 	if len(outdatedMarketplaces) == 0 && len(outdatedPlugins) == 0 {
-		fmt.Println("No updates selected")
+		out.Println("No updates selected")
 		return nil
 	}
 
+	ctx := cmd.Context()
+
 	// Apply marketplace updates
 	if len(outdatedMarketplaces) > 0 {
-		fmt.Println("\n━━━ Updating Marketplaces ━━━")
+		out.Println("\n━━━ Updating Marketplaces ━━━")
 		for _, name := range outdatedMarketplaces {
-			if err := updateMarketplace(name, marketplaces[name].InstallLocation); err != nil {
-				fmt.Printf("  ✗ %s: %v\n", name, err)
+			if ctx.Err() != nil {
+				break
+			}
+			if err := updateMarketplace(ctx, name, marketplaces[name].InstallLocation); err != nil {
+				out.Printf("  ✗ %s: %v\n", name, err)
 			} else {
-				fmt.Printf("  ✓ %s: Updated\n", name)
+				out.Printf("  ✓ %s: Updated\n", name)
 			}
 		}
 	}
 
 	// Apply plugin updates
-	if len(outdatedPlugins) > 0 {
-		fmt.Println("\n━━━ Updating Plugins ━━━")
+	if len(outdatedPlugins) > 0 && ctx.Err() == nil {
+		out.Println("\n━━━ Updating Plugins ━━━")
+		var updated []string
 		for _, name := range outdatedPlugins {
-			if err := updatePlugin(name, plugins); err != nil {
-				fmt.Printf("  ✗ %s: %v\n", name, err)
+			if ctx.Err() != nil {
+				break
+			}
+			if err := updatePlugin(ctx, name, plugins); err != nil {
+				out.Printf("  ✗ %s: %v\n", name, err)
 			} else {
-				fmt.Printf("  ✓ %s: Updated\n", name)
+				out.Printf("  ✓ %s: Updated\n", name)
+				updated = append(updated, name)
 			}
 		}
 
-		// Save updated plugin registry
-		if err := claude.SavePlugins(claudeDir, plugins); err != nil {
+		// Save even when interrupted so plugins already updated are recorded
+		if err := state.SavePlugins(claudeDir, plugins); err != nil {
 			return fmt.Errorf("failed to save plugins: %w", err)
 		}
+
+		recordPluginChecksums(out, claudeDir, updated)
+	}
+
+	if err := ctx.Err(); err != nil {
+		out.Println("\n⚠ Interrupted; the updates marked ✓ above were applied")
+		return fmt.Errorf("interrupted: %w", err)
 	}
 
-	fmt.Println("\n✓ Updates complete!")
+	out.Println("\n✓ Updates complete!")
 
 	return nil
 }
 
-func checkMarketplaceUpdates(marketplaces claude.MarketplaceRegistry) []MarketplaceUpdate {
+func runUpdateCheckJSON(ctx context.Context, out ui.Printer) error {
+	check, err := collectUpdateCheck(ctx)
+	if updateScheduled {
+		available := 0
+		if check != nil {
+			available = check.Available
+		}
+		recordScheduledResult(out, "update-check", available, fmt.Sprintf("%d updates available", available), err)
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(check)
+}
+
+// collectUpdateCheck checks marketplaces and plugins for updates without printing
+func collectUpdateCheck(ctx context.Context) (*UpdateCheck, error) {
+	marketplaces, err := state.LoadMarketplaces(claudeDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load marketplaces: %w", err)
+	}
+	plugins, err := state.LoadPlugins(claudeDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugins: %w", err)
+	}
+
+	pluginUpdates, _ := filterPluginUpdates(checkPluginUpdates(ctx, plugins, marketplaces), updateMajorOnly, updateSecurity)
+	check := &UpdateCheck{
+		Marketplaces: checkMarketplaceUpdates(ctx, marketplaces),
+		Plugins:      pluginUpdates,
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(check.Marketplaces, func(i, j int) bool { return check.Marketplaces[i].Name < check.Marketplaces[j].Name })
+	sort.Slice(check.Plugins, func(i, j int) bool { return check.Plugins[i].Name < check.Plugins[j].Name })
+	for _, m := range check.Marketplaces {
+		if m.HasUpdate {
+			check.Available++
+		}
+	}
+	for _, p := range check.Plugins {
+		if p.HasUpdate {
+			check.Available++
+		}
+	}
+	if check.Marketplaces == nil {
+		check.Marketplaces = []MarketplaceUpdate{}
+	}
+	if check.Plugins == nil {
+		check.Plugins = []PluginUpdate{}
+	}
+	return check, nil
+}
+
+func checkMarketplaceUpdates(ctx context.Context, marketplaces state.MarketplaceRegistry) []MarketplaceUpdate {
 	var updates []MarketplaceUpdate
 
 	for name, marketplace := range marketplaces {
+		if ctx.Err() != nil {
+			break
+		}
 		// Fetch latest from remote
 		gitDir := filepath.Join(marketplace.InstallLocation, ".git")
 		if _, err := os.Stat(gitDir); os.IsNotExist(err) {
@@ -198,7 +338,7 @@ func checkMarketplaceUpdates(marketplaces claude.MarketplaceRegistry) []Marketpl
 		}
 
 		// Get current commit
-		currentCmd := exec.Command("git", "-C", marketplace.InstallLocation, "rev-parse", "HEAD")
+		currentCmd := exec.CommandContext(ctx, "git", "-C", marketplace.InstallLocation, "rev-parse", "HEAD")
 		currentOutput, err := currentCmd.Output()
 		if err != nil {
 			updates = append(updates, MarketplaceUpdate{
@@ -209,20 +349,20 @@ func checkMarketplaceUpdates(marketplaces claude.MarketplaceRegistry) []Marketpl
 		}
 		currentCommit := strings.TrimSpace(string(currentOutput))
 
-		// Fetch from remote
-		fetchCmd := exec.Command("git", "-C", marketplace.InstallLocation, "fetch", "origin")
-		fetchCmd.Run() // Ignore errors
+		// Fetch from remote, keeping shallow clones shallow
+		fetch := append([]string{"fetch"}, mkt.FetchArgs(mkt.DetectStrategy(marketplace.InstallLocation))...)
+		runGit(ctx, remoteGit(ctx, marketplace.InstallLocation, append(fetch, "origin")...)) // Ignore errors
 
 		// Get remote commit
-		remoteCmd := exec.Command("git", "-C", marketplace.InstallLocation, "rev-parse", "origin/HEAD")
+		remoteCmd := exec.CommandContext(ctx, "git", "-C", marketplace.InstallLocation, "rev-parse", "origin/HEAD")
 		remoteOutput, err := remoteCmd.Output()
 		if err != nil {
 			// Try main branch
-			remoteCmd = exec.Command("git", "-C", marketplace.InstallLocation, "rev-parse", "origin/main")
+			remoteCmd = exec.CommandContext(ctx, "git", "-C", marketplace.InstallLocation, "rev-parse", "origin/main")
 			remoteOutput, err = remoteCmd.Output()
 			if err != nil {
 				// Try master branch
-				remoteCmd = exec.Command("git", "-C", marketplace.InstallLocation, "rev-parse", "origin/master")
+				remoteCmd = exec.CommandContext(ctx, "git", "-C", marketplace.InstallLocation, "rev-parse", "origin/master")
 				remoteOutput, err = remoteCmd.Output()
 				if err != nil {
 					updates = append(updates, MarketplaceUpdate{
@@ -246,10 +386,13 @@ func checkMarketplaceUpdates(marketplaces claude.MarketplaceRegistry) []Marketpl
 	return updates
 }
 
-func checkPluginUpdates(plugins *claude.PluginRegistry, marketplaces claude.MarketplaceRegistry) []PluginUpdate {
+func checkPluginUpdates(ctx context.Context, plugins *state.PluginRegistry, marketplaces state.MarketplaceRegistry) []PluginUpdate {
 	var updates []PluginUpdate
 
 	for name, plugin := range plugins.GetAllPlugins() {
+		if ctx.Err() != nil {
+			break
+		}
 		// Skip if plugin path doesn't exist
 		if !plugin.PathExists() {
 			continue
@@ -274,7 +417,7 @@ func checkPluginUpdates(plugins *claude.PluginRegistry, marketplaces claude.Mark
 			continue
 		}
 
-		currentCmd := exec.Command("git", "-C", marketplacePath, "rev-parse", "HEAD")
+		currentCmd := exec.CommandContext(ctx, "git", "-C", marketplacePath, "rev-parse", "HEAD")
 		currentOutput, err := currentCmd.Output()
 		if err != nil {
 			continue
@@ -283,28 +426,97 @@ func checkPluginUpdates(plugins *claude.PluginRegistry, marketplaces claude.Mark
 
 		// Compare with plugin's gitCommitSha
 		if plugin.GitCommitSha != currentCommit {
-			updates = append(updates, PluginUpdate{
+			update := PluginUpdate{
 				Name:          name,
 				HasUpdate:     true,
-				CurrentCommit: plugin.GitCommitSha[:7],
-				LatestCommit:  currentCommit[:7],
-			})
+				CurrentCommit: fmt.Sprintf("%.7s", plugin.GitCommitSha),
+				LatestCommit:  fmt.Sprintf("%.7s", currentCommit),
+			}
+			describePluginUpdate(ctx, &update, marketplacePath, plugin.InstallPath, plugin.Version, plugin.GitCommitSha, currentCommit)
+			updates = append(updates, update)
 		}
 	}
 
 	return updates
 }
 
-func updateMarketplace(name, path string) error {
-	// Git pull to update
-	cmd := exec.Command("git", "-C", path, "pull", "--ff-only")
-	if err := cmd.Run(); err != nil {
+func updateMarketplace(ctx context.Context, name, path string) error {
+	strategy := mkt.DetectStrategy(path)
+	if strategy == mkt.StrategyShallow {
+		// A depth-1 fetch leaves no common history for a fast-forward, so
+		// move the branch to the fetched commit instead
+		if err := runGit(ctx, remoteGit(ctx, path, "fetch", "--depth", "1", "origin")); err != nil {
+			return fmt.Errorf("git fetch failed: %w", err)
+		}
+		if _, err := gitOutput(ctx, path, "reset", "--keep", "@{upstream}"); err != nil {
+			return fmt.Errorf("git reset failed: %w", err)
+		}
+	} else if err := runGit(ctx, remoteGit(ctx, path, "pull", "--ff-only")); err != nil {
 		return fmt.Errorf("git pull failed: %w", err)
 	}
+
+	// Profiles may name plugins the sparse checkout doesn't have yet
+	if strategy == mkt.StrategySparse {
+		plugins, err := marketplacePlugins(name)
+		if err != nil {
+			return err
+		}
+		return setSparseCheckout(ctx, path, mkt.SparseDirs(path, plugins))
+	}
 	return nil
 }
 
-func updatePlugin(name string, plugins *claude.PluginRegistry) error {
+// hintCloneStrategy points out clones that don't match the cloneStrategy
+// preference
+func hintCloneStrategy(out ui.Printer, marketplaces state.MarketplaceRegistry) {
+	cfg, err := config.LoadExisting()
+	if err != nil || cfg.Preferences.CloneStrategy == "" {
+		return
+	}
+	want, err := mkt.ParseStrategy(cfg.Preferences.CloneStrategy)
+	if err != nil {
+		out.Printf("  ⚠ %v\n", err)
+		return
+	}
+	var mismatched []string
+	for name, m := range marketplaces {
+		if _, err := os.Stat(filepath.Join(m.InstallLocation, ".git")); err != nil {
+			continue
+		}
+		if mkt.DetectStrategy(m.InstallLocation) != want {
+			mismatched = append(mismatched, name)
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		out.Printf("  → Not %s clones: %s. Run 'claudeup marketplace gc' to convert them\n", want, strings.Join(mismatched, ", "))
+	}
+}
+
+// remoteGit builds a git command in dir that talks to the remote, following
+// any configured URL rewrites to an internal mirror
+func remoteGit(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	var gitArgs []string
+	if cfg, err := config.LoadExisting(); err == nil {
+		gitArgs = cfg.GitInsteadOf()
+	}
+	gitArgs = append(gitArgs, "-C", dir)
+	return exec.CommandContext(ctx, "git", append(gitArgs, args...)...)
+}
+
+// runGit runs a git command quietly, or with --verbose shows the command
+// line and git's own output. Credentials in rewritten URLs are hidden.
+func runGit(ctx context.Context, cmd *exec.Cmd) error {
+	out := ui.PrinterFrom(ctx)
+	if out.Verbose() {
+		out.Verbosef("  → %s\n", diagnostics.Redactor{}.Text(ui.CommandLine(cmd.Args[0], cmd.Args[1:])))
+		cmd.Stdout = out.VerboseOut()
+		cmd.Stderr = out.VerboseOut()
+	}
+	return cmd.Run()
+}
+
+func updatePlugin(ctx context.Context, name string, plugins *state.PluginRegistry) error {
 	plugin, exists := plugins.GetPlugin(name)
 	if !exists {
 		return fmt.Errorf("plugin not found")
@@ -325,7 +537,7 @@ func updatePlugin(name string, plugins *claude.PluginRegistry) error {
 	}
 
 	// Get latest commit from marketplace
-	cmd := exec.Command("git", "-C", marketplacePath, "rev-parse", "HEAD")
+	cmd := exec.CommandContext(ctx, "git", "-C", marketplacePath, "rev-parse", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return fmt.Errorf("failed to get latest commit: %w", err)
@@ -355,24 +567,54 @@ func updatePlugin(name string, plugins *claude.PluginRegistry) error {
 			return fmt.Errorf("plugin source not found in marketplace")
 		}
 
-		// Remove old cached version
-		if err := os.RemoveAll(plugin.InstallPath); err != nil {
-			return fmt.Errorf("failed to remove old cached plugin: %w", err)
-		}
-
-		// Copy updated plugin to cache
-		if err := copyDir(sourcePath, plugin.InstallPath); err != nil {
+		// Copy next to the cache first so a failed copy never leaves a
+		// half-written plugin in place
+		if err := replaceDir(sourcePath, plugin.InstallPath); err != nil {
 			return fmt.Errorf("failed to copy updated plugin: %w", err)
 		}
 	}
 
-	// Update the gitCommitSha
+	// Update the gitCommitSha, and the version if the plugin declares one
 	plugin.GitCommitSha = latestCommit
+	if version := readPluginVersion(plugin.InstallPath); version != "" {
+		plugin.Version = version
+	}
 	plugins.SetPlugin(name, plugin)
 
 	return nil
 }
 
+// replaceDir copies src to a staging directory beside dst and then swaps
+// it into place, keeping the old dst until the copy has fully succeeded
+func replaceDir(src, dst string) error {
+	staging := dst + ".claudeup-new"
+	os.RemoveAll(staging)
+
+	if err := copyDir(src, staging); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+	return swapDir(staging, dst)
+}
+
+// swapDir moves staging into dst's place, restoring the old dst if the move
+// fails. staging is removed either way.
+func swapDir(staging, dst string) error {
+	backup := dst + ".claudeup-old"
+	os.RemoveAll(backup)
+
+	if err := os.Rename(dst, backup); err != nil && !os.IsNotExist(err) {
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to move %s aside: %w", dst, err)
+	}
+	if err := os.Rename(staging, dst); err != nil {
+		os.Rename(backup, dst)
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to move %s into place: %w", dst, err)
+	}
+	return os.RemoveAll(backup)
+}
+
 // copyDir recursively copies a directory
 func copyDir(src, dst string) error {
 	// Create destination directory
@@ -0,0 +1,177 @@
+// ABOUTME: Reads plugin versions and changelogs from marketplace git history
+// ABOUTME: Powers the "1.2.0 → 1.4.1" update summaries and the --major-only/--security-only filters
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/changelog"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+// maxShownChanges bounds the changelog printed under each plugin
+const maxShownChanges = 5
+
+// describePluginUpdate fills in u's versions and the commits that touched
+// the plugin between the installed commit and the marketplace's HEAD.
+// Anything git can't answer is left empty.
+func describePluginUpdate(ctx context.Context, u *PluginUpdate, marketplacePath, installPath, installedVersion, from, to string) {
+	rel, ok := pluginRelPath(marketplacePath, installPath)
+	if !ok {
+		return
+	}
+	u.CurrentVersion = gitPluginVersion(ctx, marketplacePath, from, rel)
+	if u.CurrentVersion == "" {
+		u.CurrentVersion = installedVersion
+	}
+	u.LatestVersion = gitPluginVersion(ctx, marketplacePath, to, rel)
+	u.Bump = changelog.Bump(u.CurrentVersion, u.LatestVersion)
+
+	if from == "" {
+		return
+	}
+	for _, c := range gitChanges(ctx, marketplacePath, from, to, rel) {
+		u.Breaking = u.Breaking || c.Breaking
+		u.Security = u.Security || c.Security
+		u.Changes = append(u.Changes, c)
+	}
+}
+
+// pluginRelPath returns the plugin's directory inside the marketplace, in
+// git's slash-separated form
+func pluginRelPath(marketplacePath, installPath string) (string, bool) {
+	rel, err := filepath.Rel(marketplacePath, installPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// gitPluginVersion reads the version field of the plugin's plugin.json at commit
+func gitPluginVersion(ctx context.Context, repo, commit, rel string) string {
+	if commit == "" {
+		return ""
+	}
+	spec := commit + ":" + path.Join(rel, ".claude-plugin", "plugin.json")
+	data, err := exec.CommandContext(ctx, "git", "-C", repo, "show", spec).Output()
+	if err != nil {
+		return ""
+	}
+	return parsePluginVersion(data)
+}
+
+// readPluginVersion reads the version field of an installed plugin's plugin.json
+func readPluginVersion(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, ".claude-plugin", "plugin.json"))
+	if err != nil {
+		return ""
+	}
+	return parsePluginVersion(data)
+}
+
+func parsePluginVersion(data []byte) string {
+	var manifest struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return ""
+	}
+	return manifest.Version
+}
+
+// gitChanges parses the commits in from..to that touched rel, newest first
+func gitChanges(ctx context.Context, repo, from, to, rel string) []changelog.Commit {
+	// Unit and record separators keep multi-line bodies intact
+	cmd := exec.CommandContext(ctx, "git", "-C", repo, "log", "--format=%H%x1f%B%x1e", from+".."+to, "--", rel)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	var commits []changelog.Commit
+	for _, record := range strings.Split(string(out), "\x1e") {
+		hash, message, ok := strings.Cut(strings.TrimSpace(record), "\x1f")
+		if !ok {
+			continue
+		}
+		commits = append(commits, changelog.Parse(hash, message))
+	}
+	return commits
+}
+
+// IsMajor reports whether the update bumps the major version or includes a
+// breaking change
+func (u PluginUpdate) IsMajor() bool {
+	return u.Bump == changelog.BumpMajor || u.Breaking
+}
+
+// filterPluginUpdates keeps the updates matching --major-only and
+// --security-only (either one, when both are set) and counts the rest
+func filterPluginUpdates(updates []PluginUpdate, majorOnly, securityOnly bool) (kept []PluginUpdate, hidden int) {
+	if !majorOnly && !securityOnly {
+		return updates, 0
+	}
+	for _, u := range updates {
+		if (majorOnly && u.IsMajor()) || (securityOnly && u.Security) {
+			kept = append(kept, u)
+		} else if u.HasUpdate {
+			hidden++
+		}
+	}
+	return kept, hidden
+}
+
+// filterNames names the active update filters for messages
+func filterNames(majorOnly, securityOnly bool) string {
+	switch {
+	case majorOnly && securityOnly:
+		return "--major-only and --security-only"
+	case majorOnly:
+		return "--major-only"
+	}
+	return "--security-only"
+}
+
+// versionSummary is "1.2.0 → 1.4.1" when both versions are known, and the
+// commits otherwise
+func versionSummary(u PluginUpdate) string {
+	summary := u.CurrentCommit + " → " + u.LatestCommit
+	if u.CurrentVersion != "" && u.LatestVersion != "" {
+		if u.CurrentVersion == u.LatestVersion {
+			summary = u.CurrentVersion + " (" + summary + ")"
+		} else {
+			summary = u.CurrentVersion + " → " + u.LatestVersion
+		}
+	}
+	var tags []string
+	if u.IsMajor() {
+		tags = append(tags, "major")
+	}
+	if u.Security {
+		tags = append(tags, "security")
+	}
+	if len(tags) > 0 {
+		summary += " [" + strings.Join(tags, ", ") + "]"
+	}
+	return summary
+}
+
+// printPluginChanges lists the newest commits in an update
+func printPluginChanges(out ui.Printer, u PluginUpdate) {
+	for i, c := range u.Changes {
+		if i == maxShownChanges {
+			out.Printf("      … %d more\n", len(u.Changes)-maxShownChanges)
+			break
+		}
+		line := c.Summary
+		if label := c.Label(); label != "" {
+			line = label + ": " + line
+		}
+		out.Printf("      %.7s %s\n", c.Hash, line)
+	}
+}
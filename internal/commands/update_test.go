@@ -0,0 +1,136 @@
+// ABOUTME: Tests for update helpers
+// ABOUTME: Verifies plugin cache replacement and version-aware update summaries
+package commands
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReplaceDirSwapsContents(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	dst := filepath.Join(root, "dst")
+	os.MkdirAll(filepath.Join(src, "commands"), 0755)
+	os.WriteFile(filepath.Join(src, "commands", "new.md"), []byte("new"), 0644)
+	os.MkdirAll(dst, 0755)
+	os.WriteFile(filepath.Join(dst, "old.md"), []byte("old"), 0644)
+
+	if err := replaceDir(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dst, "old.md")); !os.IsNotExist(err) {
+		t.Error("Expected old contents to be replaced")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "commands", "new.md")); string(data) != "new" {
+		t.Errorf("Expected new contents, got %q", data)
+	}
+	for _, leftover := range []string{dst + ".claudeup-new", dst + ".claudeup-old"} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be cleaned up", leftover)
+		}
+	}
+}
+
+func TestReplaceDirKeepsOldOnFailedCopy(t *testing.T) {
+	root := t.TempDir()
+	dst := filepath.Join(root, "dst")
+	os.MkdirAll(dst, 0755)
+	os.WriteFile(filepath.Join(dst, "old.md"), []byte("old"), 0644)
+
+	if err := replaceDir(filepath.Join(root, "missing"), dst); err == nil {
+		t.Fatal("Expected error copying from a missing source")
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dst, "old.md")); string(data) != "old" {
+		t.Error("Expected the existing plugin to be left intact")
+	}
+}
+
+func TestDescribePluginUpdate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	pluginDir := filepath.Join(repo, "plugins", "lint")
+	writeVersion := func(v string) {
+		os.MkdirAll(filepath.Join(pluginDir, ".claude-plugin"), 0755)
+		os.WriteFile(filepath.Join(pluginDir, ".claude-plugin", "plugin.json"), []byte(`{"name": "lint", "version": "`+v+`"}`), 0644)
+	}
+
+	git("init", "-q")
+	writeVersion("1.2.0")
+	git("add", "-A")
+	git("commit", "-q", "-m", "feat: initial release")
+	from := git("rev-parse", "HEAD")
+
+	os.WriteFile(filepath.Join(repo, "README.md"), []byte("docs"), 0644)
+	git("add", "-A")
+	git("commit", "-q", "-m", "docs: unrelated to the plugin")
+	writeVersion("1.4.1")
+	git("add", "-A")
+	git("commit", "-q", "-m", "fix(security): escape arguments")
+	to := git("rev-parse", "HEAD")
+
+	u := PluginUpdate{Name: "lint@tools", HasUpdate: true}
+	describePluginUpdate(context.Background(), &u, repo, pluginDir, "", from, to)
+
+	if u.CurrentVersion != "1.2.0" || u.LatestVersion != "1.4.1" || u.Bump != "minor" {
+		t.Errorf("versions = %s → %s (%s), want 1.2.0 → 1.4.1 (minor)", u.CurrentVersion, u.LatestVersion, u.Bump)
+	}
+	if len(u.Changes) != 1 || u.Changes[0].Summary != "escape arguments" {
+		t.Fatalf("Expected only the commit touching the plugin, got %+v", u.Changes)
+	}
+	if !u.Security || u.IsMajor() {
+		t.Errorf("Expected a non-major security update, got %+v", u)
+	}
+	if got := versionSummary(u); got != "1.2.0 → 1.4.1 [security]" {
+		t.Errorf("versionSummary = %q", got)
+	}
+}
+
+func TestFilterPluginUpdates(t *testing.T) {
+	updates := []PluginUpdate{
+		{Name: "major", HasUpdate: true, Bump: "major"},
+		{Name: "breaking", HasUpdate: true, Bump: "minor", Breaking: true},
+		{Name: "security", HasUpdate: true, Security: true},
+		{Name: "patch", HasUpdate: true, Bump: "patch"},
+	}
+	names := func(us []PluginUpdate) string {
+		var n []string
+		for _, u := range us {
+			n = append(n, u.Name)
+		}
+		return strings.Join(n, ",")
+	}
+
+	kept, hidden := filterPluginUpdates(updates, true, false)
+	if names(kept) != "major,breaking" || hidden != 2 {
+		t.Errorf("--major-only kept %s, hid %d", names(kept), hidden)
+	}
+	kept, hidden = filterPluginUpdates(updates, false, true)
+	if names(kept) != "security" || hidden != 3 {
+		t.Errorf("--security-only kept %s, hid %d", names(kept), hidden)
+	}
+	kept, hidden = filterPluginUpdates(updates, true, true)
+	if names(kept) != "major,breaking,security" || hidden != 1 {
+		t.Errorf("both filters kept %s, hid %d", names(kept), hidden)
+	}
+	if kept, hidden = filterPluginUpdates(updates, false, false); len(kept) != 4 || hidden != 0 {
+		t.Errorf("no filter kept %d, hid %d", len(kept), hidden)
+	}
+}
@@ -0,0 +1,113 @@
+// ABOUTME: Verify command that re-hashes installed plugins against recorded checksums
+// ABOUTME: Reports tampered, partially copied, and missing plugin directories
+package commands
+
+import (
+	"fmt"
+
+	"github.com/claudeup/claudeup/internal/integrity"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var verifyRecord bool
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify installed plugins against recorded checksums",
+	Long: `Re-hashes every installed plugin directory and compares it with the checksum
+claudeup recorded when the plugin was applied or updated.
+
+Use --record to accept the current contents as the new baseline.`,
+	Args: cobra.NoArgs,
+	RunE: runVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().BoolVar(&verifyRecord, "record", false, "Record current plugin contents as the trusted baseline")
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	plugins, err := state.LoadPlugins(claudeDir)
+	if err != nil {
+		return fmt.Errorf("failed to load plugins: %w", err)
+	}
+
+	manifestPath := integrity.DefaultPath()
+	manifest, err := integrity.Load(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	if verifyRecord {
+		if err := integrity.RecordAll(manifest, plugins); err != nil {
+			return err
+		}
+		if err := integrity.Save(manifestPath, manifest); err != nil {
+			return fmt.Errorf("failed to save checksums: %w", err)
+		}
+		out.Printf("✓ Recorded checksums for %d plugins\n", len(manifest))
+		return nil
+	}
+
+	out.Println("━━━ Verifying Plugins ━━━")
+	failed, unrecorded := 0, 0
+	for _, r := range integrity.Verify(manifest, plugins) {
+		switch r.Status {
+		case integrity.StatusOK:
+			out.Printf("  ✓ %s\n", r.Plugin)
+		case integrity.StatusModified:
+			failed++
+			out.Printf("  ✗ %s: contents changed since %s", r.Plugin, r.Expected.RecordedAt.Local().Format("2006-01-02 15:04"))
+			if r.Actual.Files != r.Expected.Files {
+				out.Printf(" (%d files, expected %d)", r.Actual.Files, r.Expected.Files)
+			}
+			out.Println()
+		case integrity.StatusMissing:
+			failed++
+			out.Printf("  ✗ %s: directory missing\n", r.Plugin)
+		case integrity.StatusUnrecorded:
+			unrecorded++
+			out.Printf("  ⚠ %s: no checksum recorded\n", r.Plugin)
+		}
+	}
+
+	if unrecorded > 0 {
+		out.Println("\n  → Run 'claudeup verify --record' to record checksums for unrecorded plugins")
+	}
+	if failed > 0 {
+		out.Println("  → Reinstall affected plugins, or run 'claudeup cleanup' for missing directories")
+		return fmt.Errorf("%d plugins failed verification", failed)
+	}
+
+	out.Println("\n✓ All recorded plugins verified")
+	return nil
+}
+
+// recordPluginChecksums records hashes for the named plugins after they
+// were installed or updated. Best-effort: failures only print a warning.
+func recordPluginChecksums(out ui.Printer, claudeDir string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	plugins, err := state.LoadPlugins(claudeDir)
+	if err != nil {
+		return
+	}
+	manifestPath := integrity.DefaultPath()
+	manifest, err := integrity.Load(manifestPath)
+	if err != nil {
+		out.Printf("  ⚠ Could not record plugin checksums: %v\n", err)
+		return
+	}
+	if err := integrity.RecordPlugins(manifest, plugins, names); err != nil {
+		out.Printf("  ⚠ Could not record plugin checksums: %v\n", err)
+		return
+	}
+	if err := integrity.Save(manifestPath, manifest); err != nil {
+		out.Printf("  ⚠ Could not record plugin checksums: %v\n", err)
+	}
+}
@@ -0,0 +1,156 @@
+// ABOUTME: workspace subcommands mapping directory trees to profiles
+// ABOUTME: Workspaces feed profile suggest and are shown in status
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Map directory trees to profiles",
+	Long: `Workspaces map a directory prefix to a profile, e.g. everything under ~/work
+uses the work profile and everything under ~/oss uses the oss profile.
+
+'claudeup profile suggest' prefers the workspace profile over per-project
+detection, and 'claudeup status' shows the workspace for the current directory.`,
+}
+
+var workspaceAddCmd = &cobra.Command{
+	Use:   "add <path> <profile>",
+	Short: "Use a profile for everything under a directory",
+	Example: `  claudeup workspace add '~/work/*' work
+  claudeup workspace add ~/oss oss`,
+	Args: cobra.ExactArgs(2),
+	RunE: runWorkspaceAdd,
+}
+
+var workspaceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List workspace mappings",
+	Args:  cobra.NoArgs,
+	RunE:  runWorkspaceList,
+}
+
+var workspaceRemoveCmd = &cobra.Command{
+	Use:   "remove <path>",
+	Short: "Remove a workspace mapping",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWorkspaceRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+	workspaceCmd.AddCommand(workspaceAddCmd)
+	workspaceCmd.AddCommand(workspaceListCmd)
+	workspaceCmd.AddCommand(workspaceRemoveCmd)
+}
+
+// workspacePath makes relative paths absolute, leaving ~ paths as typed
+func workspacePath(path string) (string, error) {
+	if pathctx.HomeRelative(path) || filepath.IsAbs(path) {
+		return path, nil
+	}
+	return filepath.Abs(path)
+}
+
+func runWorkspaceAdd(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	path, err := workspacePath(args[0])
+	if err != nil {
+		return err
+	}
+	name := args[1]
+	if _, err := loadProfileWithFallback(getProfilesDir(), name); err != nil {
+		return fmt.Errorf("profile %q not found: %w", name, err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	replaced := cfg.SetWorkspace(path, name)
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if replaced {
+		out.Printf("✓ Updated workspace %s → %s\n", path, name)
+	} else {
+		out.Printf("✓ Added workspace %s → %s\n", path, name)
+	}
+	return nil
+}
+
+func runWorkspaceList(cmd *cobra.Command, args []string) error {
+	out := ui.PrinterFrom(cmd.Context())
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if len(cfg.Workspaces) == 0 {
+		out.Println("No workspaces configured.")
+		out.Println("Add one with: claudeup workspace add <path> <profile>")
+		return nil
+	}
+
+	cwd, _ := os.Getwd()
+	current, inWorkspace := cfg.WorkspaceFor(cwd)
+	for _, w := range cfg.Workspaces {
+		marker := " "
+		if inWorkspace && w.Root() == current.Root() {
+			marker = "*"
+		}
+		out.Printf("%s %-30s → %s\n", marker, w.Path, w.Profile)
+	}
+	return nil
+}
+
+func runWorkspaceRemove(cmd *cobra.Command, args []string) error {
+	if err := checkWritable(cmd); err != nil {
+		return err
+	}
+	out := ui.PrinterFrom(cmd.Context())
+	path, err := workspacePath(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.RemoveWorkspace(path) {
+		return fmt.Errorf("no workspace configured for %s", path)
+	}
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	out.Printf("✓ Removed workspace %s\n", path)
+	return nil
+}
+
+// currentWorkspace returns the workspace containing the working directory
+func currentWorkspace() (config.Workspace, bool) {
+	cfg, err := config.Load()
+	if err != nil {
+		return config.Workspace{}, false
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return config.Workspace{}, false
+	}
+	return cfg.WorkspaceFor(cwd)
+}
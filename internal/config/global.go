@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
 )
 
 // GlobalConfig represents the global configuration file structure
@@ -14,6 +16,50 @@ type GlobalConfig struct {
 	DisabledMCPServers []string                  `json:"disabledMcpServers"`
 	ClaudeDir          string                    `json:"claudeDir,omitempty"`
 	Preferences        Preferences               `json:"preferences"`
+	Notifications      Notifications             `json:"notifications,omitzero"`
+	Workspaces         []Workspace               `json:"workspaces,omitempty"`
+	ProjectProfiles    map[string]string         `json:"projectProfiles,omitempty"` // absolute project path -> profile set by 'profile use --local'
+	URLRewrites        map[string]string         `json:"urlRewrites,omitempty"`     // marketplace URL prefix -> mirror prefix
+	Sandbox            Sandbox                   `json:"sandbox,omitzero"`
+	Webhooks           []Webhook                 `json:"webhooks,omitempty"`
+	Telemetry          Telemetry                 `json:"telemetry,omitzero"`
+	PreviousProfile    PreviousProfile           `json:"previousProfile,omitzero"` // for 'profile back'
+}
+
+// PreviousProfile is the global profile that was active before the last
+// 'profile use' switched to another one
+type PreviousProfile struct {
+	Name     string `json:"name"`
+	Snapshot string `json:"snapshot,omitempty"` // ID of the snapshot of the state before the switch
+}
+
+// Sandbox configures container confinement for 'claudeup sandbox'
+type Sandbox struct {
+	SeccompProfile  string `json:"seccompProfile,omitempty"`  // host path to a seccomp JSON profile
+	AppArmorProfile string `json:"apparmorProfile,omitempty"` // name of a loaded AppArmor profile
+	Channel         string `json:"channel,omitempty"`         // image channel: "stable" (default) or "nightly"
+	StaleAfterDays  int    `json:"staleAfterDays,omitempty"`  // warn when the local image is this far behind; 0 means 14
+}
+
+// Notifications configures where background problems are reported
+type Notifications struct {
+	Desktop    bool   `json:"desktop,omitempty"`    // osascript on macOS, notify-send on Linux
+	WebhookURL string `json:"webhookUrl,omitempty"` // generic JSON webhook or Slack incoming webhook
+}
+
+// Webhook posts claudeup events such as applies and drift to an external system
+type Webhook struct {
+	URL      string            `json:"url"`
+	Events   []string          `json:"events,omitempty"`   // apply-start, apply-success, apply-failed, drift; empty for all
+	Template string            `json:"template,omitempty"` // Go template for the request body; default is the event as JSON
+	Headers  map[string]string `json:"headers,omitempty"`  // $VAR in values is expanded from the environment
+}
+
+// Telemetry exports each apply as an OpenTelemetry trace over OTLP/HTTP.
+// The standard OTEL_EXPORTER_OTLP_* environment variables take precedence.
+type Telemetry struct {
+	Endpoint string            `json:"endpoint,omitempty"` // collector base URL such as http://otel:4318; /v1/traces is appended
+	Headers  map[string]string `json:"headers,omitempty"`  // $VAR in values is expanded from the environment
 }
 
 // DisabledPlugin stores metadata for a disabled plugin
@@ -28,19 +74,25 @@ type DisabledPlugin struct {
 
 // Preferences represents user preferences
 type Preferences struct {
-	AutoUpdate    bool   `json:"autoUpdate"`
-	VerboseOutput bool   `json:"verboseOutput"`
-	ActiveProfile string `json:"activeProfile,omitempty"`
-	SecretBackend string `json:"secretBackend,omitempty"`
+	AutoUpdate        bool     `json:"autoUpdate"`
+	VerboseOutput     bool     `json:"verboseOutput"`
+	ActiveProfile     string   `json:"activeProfile,omitempty"`
+	SecretBackend     string   `json:"secretBackend,omitempty"`
+	PluginAudit       string   `json:"pluginAudit,omitempty"`       // "", "warn", or "block" during profile use
+	SecretCacheTTL    string   `json:"secretCacheTtl,omitempty"`    // e.g. "15m"; empty leaves secret caching off
+	WarmMCP           bool     `json:"warmMcp,omitempty"`           // pre-fetch npx packages after profile use
+	CloneStrategy     string   `json:"cloneStrategy,omitempty"`     // marketplace clones: "full" (default), "shallow", "blobless", or "sparse"
+	Language          string   `json:"language,omitempty"`          // output language such as "es"; empty follows the locale
+	ProfileNamespaces []string `json:"profileNamespaces,omitempty"` // namespaces searched, in order, for a bare profile name
+	ReadOnly          bool     `json:"readOnly,omitempty"`          // refuse commands that change configuration, for shared machines
 }
 
 // DefaultConfig returns a new config with default values
 func DefaultConfig() *GlobalConfig {
-	homeDir, _ := os.UserHomeDir()
 	return &GlobalConfig{
 		DisabledPlugins:    make(map[string]DisabledPlugin),
 		DisabledMCPServers: []string{},
-		ClaudeDir:          filepath.Join(homeDir, ".claude"),
+		ClaudeDir:          pathctx.Default().ClaudeDir,
 		Preferences: Preferences{
 			AutoUpdate:    false,
 			VerboseOutput: false,
@@ -48,15 +100,14 @@ func DefaultConfig() *GlobalConfig {
 	}
 }
 
-// configPath returns the path to the global config file
-func configPath() string {
-	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, ".claudeup", "config.json")
+// Path returns the path to the global config file
+func Path() string {
+	return pathctx.Default().Claudeup("config.json")
 }
 
 // Load reads the global config file, creating it with defaults if it doesn't exist
 func Load() (*GlobalConfig, error) {
-	cfgPath := configPath()
+	cfgPath := Path()
 
 	// If config doesn't exist, create it with defaults
 	if _, err := os.Stat(cfgPath); os.IsNotExist(err) {
@@ -81,9 +132,18 @@ func Load() (*GlobalConfig, error) {
 	return &cfg, nil
 }
 
+// LoadExisting reads the global config without creating it, returning
+// defaults if it doesn't exist
+func LoadExisting() (*GlobalConfig, error) {
+	if _, err := os.Stat(Path()); os.IsNotExist(err) {
+		return DefaultConfig(), nil
+	}
+	return Load()
+}
+
 // Save writes the global config to disk
 func Save(cfg *GlobalConfig) error {
-	cfgPath := configPath()
+	cfgPath := Path()
 
 	// Ensure directory exists
 	dir := filepath.Dir(cfgPath)
@@ -127,6 +187,9 @@ func (c *GlobalConfig) DisablePlugin(pluginName string, metadata DisabledPlugin)
 	if c.IsPluginDisabled(pluginName) {
 		return false // Already disabled
 	}
+	if c.DisabledPlugins == nil {
+		c.DisabledPlugins = make(map[string]DisabledPlugin)
+	}
 	c.DisabledPlugins[pluginName] = metadata
 	return true
 }
@@ -0,0 +1,28 @@
+// ABOUTME: Per-project active profiles, set by 'claudeup profile use --local'
+// ABOUTME: A project's entry overrides the global active profile in it and its subdirectories
+package config
+
+import "path/filepath"
+
+// ActiveProfileFor returns the profile active in dir: the one set for dir
+// or its nearest parent project, or else the global active profile. project
+// is the directory the entry was set for, or "" for the global profile.
+func (c *GlobalConfig) ActiveProfileFor(dir string) (name, project string) {
+	for p := filepath.Clean(dir); ; p = filepath.Dir(p) {
+		if name, ok := c.ProjectProfiles[p]; ok {
+			return name, p
+		}
+		if filepath.Dir(p) == p {
+			return c.Preferences.ActiveProfile, ""
+		}
+	}
+}
+
+// SetProjectProfile makes name the active profile in the project at dir,
+// an absolute path, without changing the global active profile
+func (c *GlobalConfig) SetProjectProfile(dir, name string) {
+	if c.ProjectProfiles == nil {
+		c.ProjectProfiles = make(map[string]string)
+	}
+	c.ProjectProfiles[filepath.Clean(dir)] = name
+}
@@ -0,0 +1,41 @@
+// ABOUTME: Unit tests for per-project active profiles
+// ABOUTME: Covers parent projects, nested overrides, and falling back to the global profile
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestActiveProfileFor(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "api")
+	web := filepath.Join(root, "api", "web")
+
+	cfg := DefaultConfig()
+	cfg.Preferences.ActiveProfile = "default"
+	cfg.SetProjectProfile(api, "backend")
+	cfg.SetProjectProfile(web+string(filepath.Separator), "frontend")
+
+	tests := []struct {
+		dir         string
+		wantName    string
+		wantProject string
+	}{
+		{api, "backend", api},
+		{filepath.Join(api, "cmd", "server"), "backend", api},
+		{filepath.Join(web, "src"), "frontend", web},
+		{filepath.Join(root, "apiary"), "default", ""},
+		{root, "default", ""},
+	}
+	for _, tt := range tests {
+		name, project := cfg.ActiveProfileFor(tt.dir)
+		if name != tt.wantName || project != tt.wantProject {
+			t.Errorf("%s: expected %s from %q, got %s from %q", tt.dir, tt.wantName, tt.wantProject, name, project)
+		}
+	}
+
+	if global := cfg.Preferences.ActiveProfile; global != "default" {
+		t.Errorf("Expected the global profile left alone, got %s", global)
+	}
+}
@@ -0,0 +1,61 @@
+// ABOUTME: URL rewrites that point marketplace sources at an internal git mirror
+// ABOUTME: e.g. github.com/org -> git.corp/mirror/org, honoured by profile apply and update
+package config
+
+import "strings"
+
+// RewriteURL applies the rewrite with the longest matching prefix to url.
+// A prefix without a scheme matches any scheme, and a replacement without
+// one keeps url's scheme. Prefixes only match whole path segments, so
+// github.com/org doesn't match github.com/organization.
+func (c *GlobalConfig) RewriteURL(url string) (string, bool) {
+	scheme, rest := splitScheme(url)
+
+	best, bestTo := "", ""
+	for from, to := range c.URLRewrites {
+		fromScheme, fromRest := splitScheme(strings.TrimSuffix(from, "/"))
+		if fromScheme != "" && fromScheme != scheme {
+			continue
+		}
+		if !strings.HasPrefix(rest, fromRest) || len(fromRest) <= len(best) {
+			continue
+		}
+		if tail := rest[len(fromRest):]; tail != "" && tail[0] != '/' {
+			continue
+		}
+		best, bestTo = fromRest, strings.TrimSuffix(to, "/")
+	}
+	if best == "" {
+		return url, false
+	}
+
+	tail := rest[len(best):]
+	if toScheme, _ := splitScheme(bestTo); toScheme != "" || scheme == "" {
+		return bestTo + tail, true
+	}
+	return scheme + "://" + bestTo + tail, true
+}
+
+// GitInsteadOf returns "-c url.<to>.insteadOf=<from>" arguments that make
+// git follow the rewrites. Prefixes without a scheme are taken as https.
+func (c *GlobalConfig) GitInsteadOf() []string {
+	var args []string
+	for from, to := range c.URLRewrites {
+		if s, _ := splitScheme(from); s == "" {
+			from = "https://" + from
+		}
+		if s, _ := splitScheme(to); s == "" {
+			to = "https://" + to
+		}
+		// A trailing slash keeps git to whole path segments too
+		args = append(args, "-c", "url."+strings.TrimSuffix(to, "/")+"/.insteadOf="+strings.TrimSuffix(from, "/")+"/")
+	}
+	return args
+}
+
+func splitScheme(url string) (scheme, rest string) {
+	if s, r, ok := strings.Cut(url, "://"); ok {
+		return s, r
+	}
+	return "", url
+}
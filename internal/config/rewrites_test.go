@@ -0,0 +1,44 @@
+// ABOUTME: Tests for marketplace URL rewrites
+// ABOUTME: Covers prefix matching, scheme handling, and the git -c arguments
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRewriteURL(t *testing.T) {
+	cfg := &GlobalConfig{URLRewrites: map[string]string{
+		"github.com/org":             "git.corp/mirror/org",
+		"github.com/org/special":     "ssh://git@git.corp/special",
+		"https://gitlab.com/team/":   "https://git.corp/gitlab/team/",
+		"http://insecure.example/re": "git.corp/insecure",
+	}}
+
+	tests := []struct {
+		url  string
+		want string
+		ok   bool
+	}{
+		{"https://github.com/org/tools.git", "https://git.corp/mirror/org/tools.git", true},
+		{"https://github.com/org/special/x", "ssh://git@git.corp/special/x", true},
+		{"https://github.com/organization/tools", "https://github.com/organization/tools", false},
+		{"https://gitlab.com/team/repo", "https://git.corp/gitlab/team/repo", true},
+		{"https://insecure.example/re/x", "https://insecure.example/re/x", false},
+		{"http://insecure.example/re/x", "http://git.corp/insecure/x", true},
+	}
+	for _, tt := range tests {
+		got, ok := cfg.RewriteURL(tt.url)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("RewriteURL(%q) = %q, %v; want %q, %v", tt.url, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGitInsteadOf(t *testing.T) {
+	cfg := &GlobalConfig{URLRewrites: map[string]string{"github.com/org": "git.corp/mirror/org"}}
+	want := []string{"-c", "url.https://git.corp/mirror/org/.insteadOf=https://github.com/org/"}
+	if got := cfg.GitInsteadOf(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GitInsteadOf() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+// ABOUTME: Workspace groups mapping directory trees to profiles
+// ABOUTME: Coarser than per-project detection, e.g. ~/work/* uses the work profile
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+)
+
+// Workspace maps a directory tree to a profile
+type Workspace struct {
+	// Path is a directory prefix such as "~/work" or "~/work/*"
+	Path    string `json:"path"`
+	Profile string `json:"profile"`
+}
+
+// Root returns the workspace directory with ~ expanded and any trailing
+// "/*" or "/**" (on Windows also "\*" or "\**") removed
+func (w Workspace) Root() string {
+	p := w.Path
+	for _, sep := range []string{"/", string(filepath.Separator)} {
+		p = strings.TrimSuffix(strings.TrimSuffix(p, sep+"**"), sep+"*")
+	}
+	return filepath.Clean(pathctx.ExpandHome(p, pathctx.Default().Home))
+}
+
+// Contains reports whether dir is the workspace root or inside it
+func (w Workspace) Contains(dir string) bool {
+	rel, err := filepath.Rel(w.Root(), filepath.Clean(dir))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// WorkspaceFor returns the most specific workspace containing dir
+func (c *GlobalConfig) WorkspaceFor(dir string) (Workspace, bool) {
+	var best Workspace
+	found := false
+	for _, w := range c.Workspaces {
+		if w.Contains(dir) && (!found || len(w.Root()) > len(best.Root())) {
+			best, found = w, true
+		}
+	}
+	return best, found
+}
+
+// SetWorkspace maps path to profile, replacing an existing mapping for the
+// same directory. Returns true if a mapping was replaced.
+func (c *GlobalConfig) SetWorkspace(path, profile string) bool {
+	w := Workspace{Path: path, Profile: profile}
+	for i, existing := range c.Workspaces {
+		if existing.Root() == w.Root() {
+			c.Workspaces[i] = w
+			return true
+		}
+	}
+	c.Workspaces = append(c.Workspaces, w)
+	return false
+}
+
+// RemoveWorkspace deletes the mapping for path. Returns false if none existed.
+func (c *GlobalConfig) RemoveWorkspace(path string) bool {
+	root := Workspace{Path: path}.Root()
+	for i, existing := range c.Workspaces {
+		if existing.Root() == root {
+			c.Workspaces = append(c.Workspaces[:i], c.Workspaces[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
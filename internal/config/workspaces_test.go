@@ -0,0 +1,63 @@
+// ABOUTME: Unit tests for workspace group matching
+// ABOUTME: Covers ~ expansion, trailing globs, and most-specific-wins
+package config
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWorkspaceFor(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	cfg := DefaultConfig()
+	cfg.SetWorkspace("~/work/*", "work")
+	cfg.SetWorkspace("~/work/client-a", "client-a")
+	cfg.SetWorkspace("/srv/oss", "oss")
+	if runtime.GOOS == "windows" {
+		cfg.SetWorkspace(`~\clients\*`, "clients")
+	}
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{filepath.Join(home, "work"), "work"},
+		{filepath.Join(home, "work", "api", "cmd"), "work"},
+		{filepath.Join(home, "work", "client-a", "web"), "client-a"},
+		{"/srv/oss/project", "oss"},
+		{filepath.Join(home, "workshop"), ""},
+		{"/srv", ""},
+	}
+	if runtime.GOOS == "windows" {
+		tests = append(tests, struct{ dir, want string }{filepath.Join(home, "clients", "b"), "clients"})
+	}
+
+	for _, tt := range tests {
+		w, ok := cfg.WorkspaceFor(tt.dir)
+		if got := w.Profile; (tt.want == "" && ok) || got != tt.want {
+			t.Errorf("WorkspaceFor(%s) = %q, %v; want %q", tt.dir, got, ok, tt.want)
+		}
+	}
+}
+
+func TestSetAndRemoveWorkspace(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if replaced := cfg.SetWorkspace("/srv/oss/*", "oss"); replaced {
+		t.Error("Expected new mapping")
+	}
+	if replaced := cfg.SetWorkspace("/srv/oss", "oss-v2"); !replaced {
+		t.Error("Expected /srv/oss to replace /srv/oss/*")
+	}
+	if len(cfg.Workspaces) != 1 || cfg.Workspaces[0].Profile != "oss-v2" {
+		t.Errorf("Unexpected workspaces %+v", cfg.Workspaces)
+	}
+
+	if !cfg.RemoveWorkspace("/srv/oss/") || len(cfg.Workspaces) != 0 {
+		t.Errorf("Expected mapping removed, got %+v", cfg.Workspaces)
+	}
+}
@@ -0,0 +1,151 @@
+// ABOUTME: Sanitized diagnostic reports for attaching to bug reports
+// ABOUTME: Redacts secrets and home paths from text and JSON, and packs files into a tarball
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Redacted replaces secret values in a report
+const Redacted = "[REDACTED]"
+
+// File is one file in a report
+type File struct {
+	Name string
+	Data []byte
+}
+
+// secretKey matches JSON keys whose values are secrets
+var secretKey = regexp.MustCompile(`(?i)(token|secret|passw|api[_-]?key|authorization|credential|private[_-]?key|webhook|session|cookie)`)
+
+// secretPatterns match well-known credential formats in free text
+var secretPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]{8,}`), "${1}" + Redacted},
+	{regexp.MustCompile(`(://)[^/\s:@]+(?::[^/\s@]*)?@`), "${1}" + Redacted + "@"},
+	{regexp.MustCompile(`(?i)(\b[A-Z0-9_]*(?:TOKEN|SECRET|PASSWORD|API_?KEY)[A-Z0-9_]*\s*[=:]\s*)(?:"[^"]*"|'[^']*'|\S+)`), "${1}" + Redacted},
+	{regexp.MustCompile(`\b(?:sk|pk|rk)-[A-Za-z0-9_-]{16,}`), Redacted},
+	{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}`), Redacted},
+	{regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`), Redacted},
+	{regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`), Redacted},
+	{regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`), Redacted},
+}
+
+// Redactor removes secrets and the user's home directory from report content
+type Redactor struct {
+	Home string // replaced with ~ when set
+}
+
+// Text redacts credentials and home paths in free text such as logs and
+// error messages
+func (r Redactor) Text(s string) string {
+	for _, p := range secretPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	if r.Home != "" && r.Home != "/" {
+		s = strings.ReplaceAll(s, r.Home, "~")
+	}
+	return s
+}
+
+// JSON redacts a JSON document: values under secret-looking keys, at any
+// depth, and every value of an "env" or "headers" object are replaced, so
+// webhook URLs and telemetry headers never appear. Secret references under
+// "secrets" are kept. All other strings go through Text. The result is
+// indented.
+func (r Redactor) JSON(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(r.value(v, false), "", "  ")
+}
+
+// Value redacts v the way JSON does, for data that hasn't been encoded yet
+func (r Redactor) Value(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return r.JSON(data)
+}
+
+func (r Redactor) value(v interface{}, secret bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			// Environment values and headers such as X-Honeycomb-Team hold
+			// credentials under any name
+			if k == "env" || k == "headers" {
+				if m, ok := child.(map[string]interface{}); ok {
+					for name := range m {
+						m[name] = Redacted
+					}
+					continue
+				}
+			}
+			// Secret references name where each secret lives, not its value
+			if k == "secrets" && !secret {
+				if m, ok := child.(map[string]interface{}); ok {
+					for name, ref := range m {
+						m[name] = r.value(ref, false)
+					}
+					continue
+				}
+			}
+			v[k] = r.value(child, secret || secretKey.MatchString(k))
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.value(child, secret)
+		}
+		return v
+	case string:
+		if secret && v != "" {
+			return Redacted
+		}
+		return r.Text(v)
+	case nil, bool:
+		return v
+	default:
+		if secret {
+			return Redacted
+		}
+		return v
+	}
+}
+
+// WriteTarball writes files into a gzipped tarball at path, readable only
+// by the owner
+func WriteTarball(path, dir string, files []File) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, f := range files {
+		hdr := &tar.Header{Name: dir + "/" + f.Name, Mode: 0600, Size: int64(len(f.Data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.Data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
@@ -0,0 +1,140 @@
+// ABOUTME: Concurrent check runner for claudeup doctor
+// ABOUTME: Runs independent checks in parallel with per-check timeouts and records how long each took
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a check that doesn't set its own
+const DefaultTimeout = 10 * time.Second
+
+// Check outcomes
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusTimeout = "timeout"
+)
+
+// Check is one independent diagnostic. Run returns what the check has to
+// say, shown under its heading, and an error if it failed. It must not
+// share mutable state with other checks and should return promptly once ctx
+// is done.
+type Check struct {
+	Name    string
+	Title   string        // section heading; defaults to Name
+	Timeout time.Duration // 0 means DefaultTimeout
+	Run     func(ctx context.Context) (string, error)
+}
+
+// Result records how a check finished
+type Result struct {
+	Name     string        `json:"name"`
+	Title    string        `json:"-"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"-"`
+	Millis   int64         `json:"durationMs"`
+}
+
+// OK reports whether the check ran to completion without an error
+func (r Result) OK() bool {
+	return r.Status == StatusOK
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []Check
+)
+
+// Register adds a check to every doctor run, after the built-in ones.
+// Registering a name twice replaces the earlier check.
+func Register(c Check) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, existing := range registry {
+		if existing.Name == c.Name {
+			registry[i] = c
+			return
+		}
+	}
+	registry = append(registry, c)
+}
+
+// Registered returns the checks added with Register
+func Registered() []Check {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return append([]Check(nil), registry...)
+}
+
+// Run runs checks concurrently and returns their results in the order
+// given. A check that outlives its timeout is reported as timed out and
+// left to finish in the background; its side effects must be ignored.
+func Run(ctx context.Context, checks []Check) []Result {
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runOne(ctx, c)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func runOne(ctx context.Context, c Check) Result {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	title := c.Title
+	if title == "" {
+		title = c.Name
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	type outcome struct {
+		output string
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- outcome{err: fmt.Errorf("check panicked: %v", p)}
+			}
+		}()
+		output, err := c.Run(ctx)
+		done <- outcome{output, err}
+	}()
+
+	var o outcome
+	select {
+	case o = <-done:
+	case <-ctx.Done():
+		o.err = ctx.Err()
+	}
+	err := o.err
+
+	r := Result{Name: c.Name, Title: title, Status: StatusOK, Output: o.output, Duration: time.Since(start)}
+	r.Millis = r.Duration.Milliseconds()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		r.Status = StatusTimeout
+		r.Error = fmt.Sprintf("timed out after %s", timeout)
+	case err != nil:
+		r.Status = StatusFailed
+		r.Error = err.Error()
+	}
+	return r
+}
@@ -0,0 +1,78 @@
+// ABOUTME: Tests for the doctor check runner
+// ABOUTME: Covers concurrency, timeouts, failures, panics, and registration
+package doctor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunConcurrently(t *testing.T) {
+	sleep := func(ctx context.Context) (string, error) {
+		time.Sleep(100 * time.Millisecond)
+		return "slept", nil
+	}
+	checks := []Check{{Name: "a", Run: sleep}, {Name: "b", Run: sleep}, {Name: "c", Run: sleep}}
+
+	start := time.Now()
+	results := Run(context.Background(), checks)
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Expected checks to run concurrently, took %s", elapsed)
+	}
+	for i, r := range results {
+		if r.Name != checks[i].Name || !r.OK() || r.Output != "slept" {
+			t.Errorf("results[%d] = %+v, want %s ok", i, r, checks[i].Name)
+		}
+		if r.Duration < 100*time.Millisecond {
+			t.Errorf("%s took %s, expected at least 100ms", r.Name, r.Duration)
+		}
+	}
+}
+
+func TestRunOutcomes(t *testing.T) {
+	checks := []Check{
+		{Name: "slow", Timeout: 50 * time.Millisecond, Run: func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}},
+		{Name: "stuck", Timeout: 50 * time.Millisecond, Run: func(ctx context.Context) (string, error) {
+			time.Sleep(time.Second) // ignores ctx
+			return "", nil
+		}},
+		{Name: "broken", Run: func(ctx context.Context) (string, error) { return "", errors.New("no registry") }},
+		{Name: "panics", Run: func(ctx context.Context) (string, error) { panic("boom") }},
+	}
+
+	start := time.Now()
+	results := Run(context.Background(), checks)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected timeouts to bound the run, took %s", elapsed)
+	}
+
+	want := []struct{ status, err string }{
+		{StatusTimeout, "timed out after 50ms"},
+		{StatusTimeout, "timed out after 50ms"},
+		{StatusFailed, "no registry"},
+		{StatusFailed, "check panicked: boom"},
+	}
+	for i, w := range want {
+		if results[i].Status != w.status || results[i].Error != w.err {
+			t.Errorf("%s = %s %q, want %s %q", results[i].Name, results[i].Status, results[i].Error, w.status, w.err)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func() { registry = nil }()
+
+	Register(Check{Name: "lint", Title: "Lint"})
+	Register(Check{Name: "audit"})
+	Register(Check{Name: "lint", Title: "Lint v2"})
+
+	got := Registered()
+	if len(got) != 2 || got[0].Title != "Lint v2" || got[1].Name != "audit" {
+		t.Errorf("Registered() = %+v", got)
+	}
+}
@@ -0,0 +1,248 @@
+// ABOUTME: Discovers doctor checks and commands that installed plugins ship for claudeup
+// ABOUTME: Reads claudeup/checks.json and the claudeup/checks and claudeup/commands executables
+package extensions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/cmdline"
+)
+
+// Dir is where a plugin keeps its claudeup extensions
+const Dir = "claudeup"
+
+// ManifestFile declares checks and commands inside Dir
+const ManifestFile = "checks.json"
+
+// maxOutput bounds how much of a check's output is kept
+const maxOutput = 64 * 1024
+
+// Manifest is the format of claudeup/checks.json
+type Manifest struct {
+	Checks   []Entry `json:"checks"`
+	Commands []Entry `json:"commands"`
+}
+
+// Entry declares one check or command. Command's first element is a path
+// relative to the plugin, or a program on PATH; ${CLAUDE_PLUGIN_ROOT} is
+// expanded in every element.
+type Entry struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Command     []string `json:"command"`
+	Timeout     string   `json:"timeout,omitempty"` // checks only, e.g. "30s"
+}
+
+// Extension is a check or command from one plugin
+type Extension struct {
+	Plugin      string
+	Name        string
+	Description string
+	Command     []string
+	Timeout     time.Duration // 0 means the doctor default
+	Root        string        // the plugin's install directory
+}
+
+// Plugin holds the extensions one plugin provides
+type Plugin struct {
+	Name     string
+	Root     string
+	Checks   []Extension
+	Commands []Extension
+}
+
+// Load reads the extensions of the plugin installed at root. A plugin
+// without a claudeup directory has none.
+func Load(name, root string) (*Plugin, error) {
+	p := &Plugin{Name: name, Root: root}
+	dir := filepath.Join(root, Dir)
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", filepath.Join(Dir, ManifestFile), err)
+		}
+		if p.Checks, err = p.fromEntries(m.Checks, true); err != nil {
+			return nil, err
+		}
+		if p.Commands, err = p.fromEntries(m.Commands, false); err != nil {
+			return nil, err
+		}
+	}
+
+	p.Checks = p.addExecutables(p.Checks, filepath.Join(dir, "checks"))
+	p.Commands = p.addExecutables(p.Commands, filepath.Join(dir, "commands"))
+	return p, nil
+}
+
+func (p *Plugin) fromEntries(entries []Entry, isCheck bool) ([]Extension, error) {
+	var exts []Extension
+	for _, e := range entries {
+		if e.Name == "" || len(e.Command) == 0 {
+			return nil, fmt.Errorf("%s: every check and command needs a name and a command", filepath.Join(Dir, ManifestFile))
+		}
+		ext := Extension{Plugin: p.Name, Name: e.Name, Description: e.Description, Command: e.Command, Root: p.Root}
+		if isCheck && e.Timeout != "" {
+			d, err := time.ParseDuration(e.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("check %s: invalid timeout %q: %w", e.Name, e.Timeout, err)
+			}
+			ext.Timeout = d
+		}
+		exts = append(exts, ext)
+	}
+	return exts, nil
+}
+
+// addExecutables adds every executable file in dir, named after the file,
+// unless the manifest already declares that name
+func (p *Plugin) addExecutables(exts []Extension, dir string) []Extension {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return exts
+	}
+	declared := map[string]bool{}
+	for _, e := range exts {
+		declared[e.Name] = true
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !isExecutable(info) {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if declared[name] {
+			continue
+		}
+		exts = append(exts, Extension{Plugin: p.Name, Name: name, Command: []string{filepath.Join(dir, entry.Name())}, Root: p.Root})
+	}
+	return exts
+}
+
+// isExecutable reports whether info is a file the OS runs directly: one with
+// an execute bit, or on Windows, which has none, one with a program's
+// extension
+func isExecutable(info os.FileInfo) bool {
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(info.Name())) {
+		case ".exe", ".cmd", ".bat", ".com":
+			return true
+		}
+		return false
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// Discover loads the extensions of each plugin in installed (plugin name to
+// install directory), skipping plugins that provide none. Plugins whose
+// extensions can't be read are returned in errs.
+func Discover(installed map[string]string) (plugins []*Plugin, errs map[string]error) {
+	names := make([]string, 0, len(installed))
+	for name := range installed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p, err := Load(name, installed[name])
+		if err != nil {
+			if errs == nil {
+				errs = map[string]error{}
+			}
+			errs[name] = err
+			continue
+		}
+		if len(p.Checks) > 0 || len(p.Commands) > 0 {
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins, errs
+}
+
+// command builds the process for e, run from the current directory with
+// CLAUDE_PLUGIN_ROOT set to the plugin's directory
+func (e Extension) command(ctx context.Context, args []string) (*exec.Cmd, error) {
+	expand := func(s string) string {
+		return strings.ReplaceAll(s, "${CLAUDE_PLUGIN_ROOT}", e.Root)
+	}
+	argv := make([]string, 0, len(e.Command)+len(args))
+	for _, a := range e.Command {
+		argv = append(argv, expand(a))
+	}
+	argv = append(argv, args...)
+
+	program := argv[0]
+	if strings.ContainsAny(program, "/"+string(filepath.Separator)) && !filepath.IsAbs(program) {
+		program = filepath.Join(e.Root, program)
+	}
+	cmd, err := cmdline.Command(ctx, program, argv[1:]...)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = append(os.Environ(), "CLAUDE_PLUGIN_ROOT="+e.Root)
+	return cmd, nil
+}
+
+// RunCheck runs e as a doctor check and returns its combined output. A
+// non-zero exit fails the check.
+func (e Extension) RunCheck(ctx context.Context) (string, error) {
+	cmd, err := e.command(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	// Don't wait on background children holding the output open
+	cmd.WaitDelay = 2 * time.Second
+	var out bytes.Buffer
+	cmd.Stdout = &limitedWriter{w: &out, n: maxOutput}
+	cmd.Stderr = cmd.Stdout
+	err = cmd.Run()
+	return strings.TrimRight(out.String(), "\n"), err
+}
+
+// Exec runs e as a command with args and the given standard streams
+func (e Extension) Exec(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	cmd, err := e.command(ctx, args)
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// limitedWriter keeps the first n bytes and discards the rest
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.n > 0 {
+		keep := p
+		if len(keep) > l.n {
+			keep = keep[:l.n]
+		}
+		l.w.Write(keep)
+		l.n -= len(keep)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,79 @@
+// ABOUTME: Tests for plugin extension discovery and execution
+// ABOUTME: Covers checks.json entries, executable hooks, and running checks
+package extensions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, Dir, ManifestFile), `{
+		"checks": [{"name": "node", "description": "Node version", "command": ["./bin/node-check"], "timeout": "3s"}],
+		"commands": [{"name": "fmt", "command": ["./bin/fmt"]}]
+	}`, 0644)
+	writeFile(t, filepath.Join(root, Dir, "checks", "config.sh"), "#!/bin/sh\n", 0755)
+	writeFile(t, filepath.Join(root, Dir, "checks", "node"), "#!/bin/sh\n", 0755) // declared already
+	writeFile(t, filepath.Join(root, Dir, "checks", "README.md"), "not executable", 0644)
+	writeFile(t, filepath.Join(root, Dir, "commands", "hello"), "#!/bin/sh\n", 0755)
+
+	p, err := Load("lint@acme", root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Checks) != 2 || p.Checks[0].Name != "node" || p.Checks[0].Timeout != 3*time.Second || p.Checks[1].Name != "config" {
+		t.Errorf("Checks = %+v", p.Checks)
+	}
+	if len(p.Commands) != 2 || p.Commands[0].Name != "fmt" || p.Commands[1].Name != "hello" {
+		t.Errorf("Commands = %+v", p.Commands)
+	}
+}
+
+func TestLoadInvalidManifest(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, Dir, ManifestFile), `{"checks": [{"name": "x"}]}`, 0644)
+	if _, err := Load("lint@acme", root); err == nil {
+		t.Error("Expected an error for a check without a command")
+	}
+}
+
+func TestDiscoverSkipsPluginsWithoutExtensions(t *testing.T) {
+	with, without := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(with, Dir, "commands", "hello"), "#!/bin/sh\n", 0755)
+
+	plugins, errs := Discover(map[string]string{"a@m": with, "b@m": without})
+	if len(plugins) != 1 || plugins[0].Name != "a@m" || errs != nil {
+		t.Errorf("Discover = %+v, %v", plugins, errs)
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "bin", "check"), "#!/bin/sh\necho root=$CLAUDE_PLUGIN_ROOT arg=$1\necho oops >&2\nexit $2\n", 0755)
+
+	pass := Extension{Name: "check", Command: []string{"./bin/check", "${CLAUDE_PLUGIN_ROOT}", "0"}, Root: root}
+	out, err := pass.RunCheck(context.Background())
+	if err != nil {
+		t.Fatalf("RunCheck failed: %v", err)
+	}
+	if want := "root=" + root + " arg=" + root + "\noops"; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+
+	fail := Extension{Name: "check", Command: []string{"./bin/check", "x", "1"}, Root: root}
+	if _, err := fail.RunCheck(context.Background()); err == nil {
+		t.Error("Expected a non-zero exit to fail the check")
+	}
+}
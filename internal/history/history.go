@@ -0,0 +1,128 @@
+// ABOUTME: Append-only log of profile operations stored in ~/.claudeup/history.jsonl
+// ABOUTME: Lets users and frontends see what was applied, when, and from where
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+)
+
+// Entry is a single recorded operation
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Profile string    `json:"profile,omitempty"`
+	Source  string    `json:"source,omitempty"` // "cli", "serve", ...
+	Changes int       `json:"changes"`
+	Error   string    `json:"error,omitempty"`
+	Failed  []Failure `json:"failed,omitempty"`    // changes that failed, for 'profile retry-failed'
+	Done    []Change  `json:"done,omitempty"`      // changes made, for 'claudeup explain'
+	Elapsed int64     `json:"elapsedMs,omitempty"` // wall time of an apply, in milliseconds
+}
+
+// Change is a single change an apply made
+type Change struct {
+	Action    string `json:"action"`    // remove, install, add, disable, enable
+	Subsystem string `json:"subsystem"` // plugins, mcp, marketplaces
+	Name      string `json:"name"`
+	Duration  int64  `json:"durationMs,omitempty"` // milliseconds
+}
+
+// Failure is a single change that failed during an apply
+type Failure struct {
+	Action    string `json:"action"`    // remove, install, add, disable, enable
+	Subsystem string `json:"subsystem"` // plugins, mcp, marketplaces
+	Name      string `json:"name"`
+	Error     string `json:"error,omitempty"`
+	Duration  int64  `json:"durationMs,omitempty"` // milliseconds
+}
+
+// LastApply returns the most recent apply entry, or false if there is none
+func LastApply(entries []Entry) (Entry, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Action == "apply" {
+			return entries[i], true
+		}
+	}
+	return Entry{}, false
+}
+
+// LastChange returns the most recent apply that changed the named item, and
+// the change it made, or false if no recorded apply touched it
+func LastChange(entries []Entry, subsystem, name string) (Entry, Change, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		for _, c := range entries[i].Done {
+			if c.Subsystem == subsystem && c.Name == name {
+				return entries[i], c, true
+			}
+		}
+	}
+	return Entry{}, Change{}, false
+}
+
+// DefaultPath returns the path to the history log
+func DefaultPath() string {
+	return pathctx.Default().Claudeup("history.jsonl")
+}
+
+// Append adds an entry to the log at path, creating it if needed
+func Append(path string, e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Load reads all entries from the log, oldest first
+// A missing log is not an error and returns no entries
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("history line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, scanner.Err()
+}
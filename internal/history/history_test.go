@@ -0,0 +1,80 @@
+// ABOUTME: Tests for the history log
+// ABOUTME: Verifies append, load ordering, and missing-file handling
+package history
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.jsonl")
+
+	if err := Append(path, Entry{Action: "apply", Profile: "first", Source: "cli", Changes: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(path, Entry{Action: "apply", Profile: "second", Source: "serve", Error: "boom"}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Profile != "first" || entries[1].Profile != "second" {
+		t.Errorf("Expected entries in append order, got %+v", entries)
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("Expected Append to stamp the entry time")
+	}
+	if entries[1].Error != "boom" {
+		t.Errorf("Expected error to be recorded, got %q", entries[1].Error)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	entries, err := Load(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil {
+		t.Fatalf("Expected no error for missing history, got %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no entries, got %d", len(entries))
+	}
+}
+
+func TestLastApply(t *testing.T) {
+	entries := []Entry{
+		{Action: "apply", Profile: "first"},
+		{Action: "apply", Profile: "second", Failed: []Failure{{Action: "install", Subsystem: "plugins", Name: "a@m"}}},
+		{Action: "update", Source: "schedule"},
+	}
+
+	last, ok := LastApply(entries)
+	if !ok || last.Profile != "second" || len(last.Failed) != 1 {
+		t.Errorf("Expected the second apply with its failure, got %+v", last)
+	}
+
+	if _, ok := LastApply([]Entry{{Action: "update"}}); ok {
+		t.Error("Expected no apply in a log without one")
+	}
+}
+
+func TestLastChange(t *testing.T) {
+	entries := []Entry{
+		{Action: "apply", Profile: "first", Done: []Change{{Action: "install", Subsystem: "plugins", Name: "a@m"}}},
+		{Action: "apply", Profile: "second", Done: []Change{{Action: "disable", Subsystem: "plugins", Name: "a@m"}}},
+		{Action: "apply", Profile: "third", Done: []Change{{Action: "install", Subsystem: "mcp", Name: "a@m"}}},
+	}
+
+	e, c, ok := LastChange(entries, "plugins", "a@m")
+	if !ok || e.Profile != "second" || c.Action != "disable" {
+		t.Errorf("Expected the second apply's disable, got %+v %+v", e, c)
+	}
+
+	if _, _, ok := LastChange(entries, "marketplaces", "a@m"); ok {
+		t.Error("Expected no change for an item no apply touched")
+	}
+}
@@ -0,0 +1,158 @@
+// ABOUTME: Extracts i18n.T messages from the source tree into the locale catalogs
+// ABOUTME: Run with 'go generate ./internal/i18n'; -check fails when a catalog is out of date
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	root := flag.String("root", ".", "Module root to scan for i18n.T calls")
+	locales := flag.String("locales", "internal/i18n/locales", "Directory of <lang>.json catalogs")
+	check := flag.Bool("check", false, "Report out-of-date catalogs and exit 1 instead of rewriting them")
+	flag.Parse()
+
+	messages, err := extract(*root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "extract:", err)
+		os.Exit(1)
+	}
+	paths, err := filepath.Glob(filepath.Join(*locales, "*.json"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "extract:", err)
+		os.Exit(1)
+	}
+
+	stale := false
+	for _, path := range paths {
+		s, err := update(path, messages, !*check)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "extract:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: %d messages, %d added, %d removed, %d untranslated\n",
+			filepath.Base(path), len(messages), s.added, s.removed, s.untranslated)
+		stale = stale || s.added+s.removed > 0
+	}
+	if *check && stale {
+		fmt.Fprintln(os.Stderr, "extract: catalogs are out of date; run 'go generate ./internal/i18n'")
+		os.Exit(1)
+	}
+}
+
+// extract returns the string literals passed to i18n.T in the non-test Go
+// files under root, sorted and without duplicates
+func extract(root string) ([]string, error) {
+	found := make(map[string]bool)
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 1 || !isTranslateCall(call) {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				pos := fset.Position(call.Pos())
+				fmt.Fprintf(os.Stderr, "%s: i18n.T needs a string literal to be extracted\n", pos)
+				return true
+			}
+			if msg, err := strconv.Unquote(lit.Value); err == nil {
+				found[msg] = true
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]string, 0, len(found))
+	for msg := range found {
+		messages = append(messages, msg)
+	}
+	sort.Strings(messages)
+	return messages, nil
+}
+
+func isTranslateCall(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "T" {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "i18n"
+}
+
+type stats struct {
+	added, removed, untranslated int
+}
+
+// update makes the catalog at path list exactly messages, keeping existing
+// translations, and writes it back if write is set
+func update(path string, messages []string, write bool) (stats, error) {
+	var s stats
+	old := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &old); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+
+	catalog := make(map[string]string, len(messages))
+	for _, msg := range messages {
+		translated, ok := old[msg]
+		if !ok {
+			s.added++
+		}
+		if translated == "" {
+			s.untranslated++
+		}
+		catalog[msg] = translated
+	}
+	s.removed = len(old) + s.added - len(catalog)
+
+	if !write {
+		return s, nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(catalog); err != nil {
+		return s, err
+	}
+	return s, os.WriteFile(path, buf.Bytes(), 0644)
+}
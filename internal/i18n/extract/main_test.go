@@ -0,0 +1,71 @@
+// ABOUTME: Tests for message extraction and catalog updates
+// ABOUTME: Also fails when the bundled catalogs miss messages from the source tree
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	root := t.TempDir()
+	src := `package x
+
+import "github.com/claudeup/claudeup/internal/i18n"
+
+func f(s string) {
+	println(i18n.T("Hello, %s\n"), i18n.T("Bye"), i18n.T("Bye"))
+	println(i18n.T(s), T("not ours"))
+}
+`
+	os.WriteFile(filepath.Join(root, "x.go"), []byte(src), 0644)
+	os.WriteFile(filepath.Join(root, "x_test.go"), []byte(`package x; var _ = i18n.T("test only")`), 0644)
+
+	messages, err := extract(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(messages, "|"); got != "Bye|Hello, %s\n" {
+		t.Errorf("Unexpected messages %q", got)
+	}
+}
+
+func TestUpdateKeepsTranslations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "es.json")
+	os.WriteFile(path, []byte(`{"Bye": "Adiós", "Gone": "Ido"}`), 0644)
+
+	s, err := update(path, []string{"Bye", "Hello"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.added != 1 || s.removed != 1 || s.untranslated != 1 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+
+	var catalog map[string]string
+	data, _ := os.ReadFile(path)
+	json.Unmarshal(data, &catalog)
+	if len(catalog) != 2 || catalog["Bye"] != "Adiós" || catalog["Hello"] != "" {
+		t.Errorf("Unexpected catalog %v", catalog)
+	}
+}
+
+func TestBundledCatalogsAreCurrent(t *testing.T) {
+	messages, err := extract(filepath.Join("..", "..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, _ := filepath.Glob(filepath.Join("..", "locales", "*.json"))
+	for _, path := range paths {
+		s, err := update(path, messages, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.added+s.removed > 0 {
+			t.Errorf("%s is out of date (%d added, %d removed); run 'go generate ./internal/i18n'", filepath.Base(path), s.added, s.removed)
+		}
+	}
+}
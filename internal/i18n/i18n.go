@@ -0,0 +1,121 @@
+// ABOUTME: Translates user-facing CLI strings into the user's language
+// ABOUTME: Catalogs in locales/<lang>.json map each English source string to its translation
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:generate go run ./extract -root ../.. -locales locales
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// Source is the language messages are written in
+const Source = "en"
+
+var (
+	mu       sync.RWMutex
+	language = Source
+	catalog  map[string]string
+)
+
+// Languages returns the languages with a bundled catalog, English first
+func Languages() []string {
+	langs := []string{Source}
+	entries, _ := localeFS.ReadDir("locales")
+	for _, e := range entries {
+		langs = append(langs, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// Detect picks the language from CLAUDEUP_LANG, then preference (the
+// language setting in config.json), then the LC_ALL, LC_MESSAGES, and LANG
+// locale variables. Unset, C, and POSIX locales mean English.
+func Detect(preference string) string {
+	if lang := os.Getenv("CLAUDEUP_LANG"); lang != "" {
+		return normalize(lang)
+	}
+	if preference != "" {
+		return normalize(preference)
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(env); locale != "" {
+			return normalize(locale)
+		}
+	}
+	return Source
+}
+
+// normalize reduces a locale such as es_ES.UTF-8 or pt-BR to its language
+func normalize(locale string) string {
+	lang, _, _ := strings.Cut(locale, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	lang, _, _ = strings.Cut(strings.ReplaceAll(lang, "-", "_"), "_")
+	lang = strings.ToLower(lang)
+	if lang == "" || lang == "c" || lang == "posix" {
+		return Source
+	}
+	return lang
+}
+
+// SetLanguage switches messages to lang. A language without a bundled
+// catalog falls back to English and returns false.
+func SetLanguage(lang string) bool {
+	translations, ok := load(lang)
+
+	mu.Lock()
+	defer mu.Unlock()
+	language, catalog = Source, nil
+	if ok {
+		language, catalog = lang, translations
+	}
+	return ok || lang == Source
+}
+
+// Language returns the language messages are translated into
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T returns msg, an English message or format string, in the current
+// language. Messages without a translation are returned unchanged.
+func T(msg string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if translated := catalog[msg]; translated != "" {
+		return translated
+	}
+	return msg
+}
+
+// load reads the catalog for lang, leaving out untranslated messages
+func load(lang string) (map[string]string, bool) {
+	if lang == "" || lang == Source || strings.ContainsAny(lang, `/\.`) {
+		return nil, false
+	}
+	data, err := localeFS.ReadFile(path.Join("locales", lang+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var translations map[string]string
+	if err := json.Unmarshal(data, &translations); err != nil {
+		return nil, false
+	}
+	for msg, translated := range translations {
+		if translated == "" {
+			delete(translations, msg)
+		}
+	}
+	return translations, true
+}
@@ -0,0 +1,61 @@
+// ABOUTME: Tests for language detection and message translation
+// ABOUTME: Uses the bundled Spanish catalog
+package i18n
+
+import (
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		preference string
+		want       string
+	}{
+		{"nothing set", nil, "", "en"},
+		{"LANG", map[string]string{"LANG": "es_ES.UTF-8"}, "", "es"},
+		{"LC_ALL wins over LANG", map[string]string{"LC_ALL": "fr_FR", "LANG": "es_ES"}, "", "fr"},
+		{"C locale", map[string]string{"LANG": "C.UTF-8"}, "", "en"},
+		{"preference wins over locale", map[string]string{"LANG": "fr_FR"}, "es", "es"},
+		{"CLAUDEUP_LANG wins over all", map[string]string{"CLAUDEUP_LANG": "pt-BR", "LANG": "es_ES"}, "es", "pt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range []string{"CLAUDEUP_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+				t.Setenv(env, tt.env[env])
+			}
+			if got := Detect(tt.preference); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.preference, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	t.Cleanup(func() { SetLanguage(Source) })
+
+	if !SetLanguage("es") || Language() != "es" {
+		t.Fatalf("Expected the Spanish catalog to load, language is %q", Language())
+	}
+	if got := T("Cancelled."); got != "Cancelado." {
+		t.Errorf("Expected a translation, got %q", got)
+	}
+	if got := T("not in the catalog"); got != "not in the catalog" {
+		t.Errorf("Expected unknown messages unchanged, got %q", got)
+	}
+
+	if SetLanguage("xx") || Language() != Source {
+		t.Errorf("Expected an unknown language to fall back to English, got %q", Language())
+	}
+	if got := T("Cancelled."); got != "Cancelled." {
+		t.Errorf("Expected English after falling back, got %q", got)
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	langs := Languages()
+	if len(langs) < 2 || langs[0] != "en" || langs[1] != "es" {
+		t.Errorf("Expected English then the bundled catalogs, got %v", langs)
+	}
+}
@@ -0,0 +1,198 @@
+// ABOUTME: Content hashes for installed plugin directories, kept in ~/.claudeup/integrity.json
+// ABOUTME: Detects tampered or partially copied plugin caches by re-hashing them
+package integrity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// Record is the stored hash for one plugin
+type Record struct {
+	Hash        string    `json:"hash"`
+	Files       int       `json:"files"`
+	InstallPath string    `json:"installPath"`
+	RecordedAt  time.Time `json:"recordedAt"`
+}
+
+// Manifest maps plugin names to their recorded hashes
+type Manifest map[string]Record
+
+// Verification statuses
+const (
+	StatusOK         = "ok"
+	StatusModified   = "modified"
+	StatusMissing    = "missing"
+	StatusUnrecorded = "unrecorded"
+)
+
+// Result is the outcome of verifying one plugin
+type Result struct {
+	Plugin   string
+	Status   string
+	Expected Record
+	Actual   Record
+}
+
+// DefaultPath returns the path to the integrity manifest
+func DefaultPath() string {
+	return pathctx.Default().Claudeup("integrity.json")
+}
+
+// Load reads the manifest; a missing file yields an empty manifest
+func Load(path string) (Manifest, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return make(Manifest), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(Manifest)
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid integrity manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Save writes the manifest
+func Save(path string, m Manifest) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// HashDir computes a stable hash over every file's relative path, mode,
+// and contents. Version control metadata is ignored.
+func HashDir(dir string) (string, int, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, path := range files {
+		rel, _ := filepath.Rel(dir, path)
+		info, err := os.Lstat(path)
+		if err != nil {
+			return "", 0, err
+		}
+		fmt.Fprintf(h, "%s\x00%o\x00", filepath.ToSlash(rel), info.Mode().Perm())
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return "", 0, err
+			}
+			io.WriteString(h, target)
+		} else {
+			f, err := os.Open(path)
+			if err != nil {
+				return "", 0, err
+			}
+			_, err = io.Copy(h, f)
+			f.Close()
+			if err != nil {
+				return "", 0, err
+			}
+		}
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), len(files), nil
+}
+
+// RecordPlugins hashes the given plugins' install directories into m.
+// Plugins whose directory is missing are skipped.
+func RecordPlugins(m Manifest, plugins *state.PluginRegistry, names []string) error {
+	for _, name := range names {
+		meta, ok := plugins.GetPlugin(name)
+		if !ok || !meta.PathExists() {
+			continue
+		}
+		hash, files, err := HashDir(meta.InstallPath)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", name, err)
+		}
+		m[name] = Record{Hash: hash, Files: files, InstallPath: meta.InstallPath, RecordedAt: time.Now().UTC()}
+	}
+	return nil
+}
+
+// RecordAll hashes every installed plugin and drops records for plugins
+// that are no longer installed
+func RecordAll(m Manifest, plugins *state.PluginRegistry) error {
+	names := make([]string, 0, len(plugins.Plugins))
+	for name := range plugins.Plugins {
+		names = append(names, name)
+	}
+	for name := range m {
+		if !plugins.PluginExists(name) {
+			delete(m, name)
+		}
+	}
+	return RecordPlugins(m, plugins, names)
+}
+
+// Verify re-hashes every installed plugin and compares against m
+func Verify(m Manifest, plugins *state.PluginRegistry) []Result {
+	names := make([]string, 0, len(plugins.Plugins))
+	for name := range plugins.Plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []Result
+	for _, name := range names {
+		meta, _ := plugins.GetPlugin(name)
+		expected, recorded := m[name]
+		r := Result{Plugin: name, Expected: expected}
+
+		switch {
+		case !meta.PathExists():
+			r.Status = StatusMissing
+		case !recorded:
+			r.Status = StatusUnrecorded
+		default:
+			hash, files, err := HashDir(meta.InstallPath)
+			r.Actual = Record{Hash: hash, Files: files, InstallPath: meta.InstallPath}
+			if err != nil || hash != expected.Hash {
+				r.Status = StatusModified
+			} else {
+				r.Status = StatusOK
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
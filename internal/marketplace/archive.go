@@ -0,0 +1,198 @@
+// ABOUTME: Installs marketplaces published as .tar.gz archives
+// ABOUTME: Downloads or copies the archive, verifies its SHA-256, and unpacks it
+package marketplace
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveMarker is written into an unpacked archive so it can be traced
+// back to its source
+const ArchiveMarker = ".claudeup-archive.json"
+
+// ArchiveSource is the archive an unpacked marketplace came from
+type ArchiveSource struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// ReadArchiveSource returns the archive dir was unpacked from, or false if
+// it wasn't unpacked by InstallArchive
+func ReadArchiveSource(dir string) (ArchiveSource, bool) {
+	var src ArchiveSource
+	data, err := os.ReadFile(filepath.Join(dir, ArchiveMarker))
+	if err != nil || json.Unmarshal(data, &src) != nil {
+		return ArchiveSource{}, false
+	}
+	return src, true
+}
+
+// InstallArchive unpacks the .tar.gz at src.URL (http(s), file://, or a
+// local path) into dir, replacing what was there. A non-empty src.SHA256
+// must match the archive. An archive holding a single top-level directory
+// is unpacked from inside it, as GitHub release tarballs are. Nothing is
+// downloaded if dir already holds the same archive.
+func InstallArchive(ctx context.Context, src ArchiveSource, dir string) error {
+	if have, ok := ReadArchiveSource(dir); ok && have.URL == src.URL && src.SHA256 != "" && strings.EqualFold(have.SHA256, src.SHA256) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(dir), ".archive-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	download := filepath.Join(staging, "archive.tar.gz")
+	sum, err := fetchArchive(ctx, src.URL, download)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", src.URL, err)
+	}
+	if src.SHA256 != "" && !strings.EqualFold(sum, src.SHA256) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", src.URL, src.SHA256, sum)
+	}
+	src.SHA256 = sum
+
+	unpacked := filepath.Join(staging, "unpacked")
+	if err := extractTarGz(download, unpacked); err != nil {
+		return fmt.Errorf("failed to unpack %s: %w", src.URL, err)
+	}
+	root := unpacked
+	if entries, err := os.ReadDir(unpacked); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(unpacked, entries[0].Name())
+	}
+
+	marker, err := json.MarshalIndent(src, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(root, ArchiveMarker), marker, 0644); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(root, dir)
+}
+
+// fetchArchive copies the archive at url to dest and returns its SHA-256
+func fetchArchive(ctx context.Context, url, dest string) (string, error) {
+	var body io.ReadCloser
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		body = resp.Body
+	} else {
+		f, err := os.Open(fileURLPath(url))
+		if err != nil {
+			return "", err
+		}
+		body = f
+	}
+	defer body.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), body); err != nil {
+		out.Close()
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileURLPath returns the local path a file:// URL, or a plain path, names.
+// On Windows, file:///C:/x names C:\x.
+func fileURLPath(url string) string {
+	p := strings.TrimPrefix(url, "file://")
+	if len(p) > 1 && p[0] == '/' && filepath.VolumeName(p[1:]) != "" {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
+func extractTarGz(archive, dir string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Localize also refuses names Windows would resolve elsewhere, such
+		// as ..\x, C:x, or NUL
+		name, err := filepath.Localize(path.Clean(hdr.Name))
+		if err != nil {
+			return fmt.Errorf("entry %q escapes the archive", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		}
+		// Symlinks and other entry types are skipped; a marketplace only
+		// needs its files
+	}
+}
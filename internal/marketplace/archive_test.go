@@ -0,0 +1,104 @@
+// ABOUTME: Tests for installing marketplaces from .tar.gz archives
+// ABOUTME: Builds archives in a temp dir and checks checksums and layout handling
+package marketplace
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeArchive writes a .tar.gz of files and returns its path and SHA-256
+func writeArchive(t *testing.T, files map[string]string) (string, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+
+	path := filepath.Join(t.TempDir(), "tools.tar.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return path, hex.EncodeToString(sum[:])
+}
+
+func TestInstallArchive(t *testing.T) {
+	archive, sum := writeArchive(t, map[string]string{
+		"tools-1.0/.claude-plugin/marketplace.json": `{"name": "tools"}`,
+		"tools-1.0/plugins/lint/README.md":          "lint",
+	})
+	dir := filepath.Join(t.TempDir(), "marketplaces", "tools")
+
+	if err := InstallArchive(context.Background(), ArchiveSource{URL: archive, SHA256: sum}, dir); err != nil {
+		t.Fatalf("InstallArchive failed: %v", err)
+	}
+
+	// The single top-level directory is unpacked from inside
+	m, err := LoadManifest(dir)
+	if err != nil || m.Name != "tools" {
+		t.Fatalf("Expected the manifest at the root, got %v, %v", m, err)
+	}
+	src, ok := ReadArchiveSource(dir)
+	if !ok || src.URL != archive || src.SHA256 != sum {
+		t.Errorf("Expected the archive source to be recorded, got %+v", src)
+	}
+}
+
+func TestInstallArchiveChecksumMismatch(t *testing.T) {
+	archive, _ := writeArchive(t, map[string]string{"README.md": "hi"})
+	dir := filepath.Join(t.TempDir(), "tools")
+
+	err := InstallArchive(context.Background(), ArchiveSource{URL: archive, SHA256: strings.Repeat("0", 64)}, dir)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Expected a checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("Expected nothing to be unpacked after a mismatch")
+	}
+}
+
+func TestInstallArchiveRejectsEscapingEntries(t *testing.T) {
+	names := []string{"../evil", "/etc/evil"}
+	if runtime.GOOS == "windows" {
+		names = append(names, `..\evil`, "C:evil", "tools/NUL")
+	}
+	for _, name := range names {
+		archive, _ := writeArchive(t, map[string]string{name: "x"})
+		err := InstallArchive(context.Background(), ArchiveSource{URL: archive}, filepath.Join(t.TempDir(), "tools"))
+		if err == nil || !strings.Contains(err.Error(), "escapes") {
+			t.Errorf("Expected %q to be rejected, got %v", name, err)
+		}
+	}
+}
+
+func TestInstallArchiveFromFileURL(t *testing.T) {
+	archive, sum := writeArchive(t, map[string]string{".claude-plugin/marketplace.json": `{"name": "tools"}`, "README.md": "tools"})
+	url := "file://" + filepath.ToSlash(archive)
+	if runtime.GOOS == "windows" {
+		url = "file:///" + filepath.ToSlash(archive)
+	}
+	dir := filepath.Join(t.TempDir(), "tools")
+	if err := InstallArchive(context.Background(), ArchiveSource{URL: url, SHA256: sum}, dir); err != nil {
+		t.Fatalf("InstallArchive failed: %v", err)
+	}
+	if m, err := LoadManifest(dir); err != nil || m.Name != "tools" {
+		t.Errorf("Expected the archive unpacked, got %v, %v", m, err)
+	}
+}
@@ -0,0 +1,115 @@
+// ABOUTME: Clone strategies for marketplace git repositories
+// ABOUTME: Maps full, shallow, blobless, and sparse clones to git arguments and detects a clone's strategy
+package marketplace
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Clone strategies
+const (
+	// StrategyFull is a plain clone with all history and files
+	StrategyFull = "full"
+	// StrategyShallow keeps only the latest commit
+	StrategyShallow = "shallow"
+	// StrategyBlobless keeps all commits but downloads file contents on demand
+	StrategyBlobless = "blobless"
+	// StrategySparse is a blobless clone that checks out only the plugins
+	// claudeup needs
+	StrategySparse = "sparse"
+)
+
+// Strategies lists the clone strategies in order of size
+var Strategies = []string{StrategyFull, StrategyBlobless, StrategySparse, StrategyShallow}
+
+// ParseStrategy validates a configured strategy; empty means full
+func ParseStrategy(s string) (string, error) {
+	switch s {
+	case "":
+		return StrategyFull, nil
+	case StrategyFull, StrategyShallow, StrategyBlobless, StrategySparse:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown clone strategy %q (expected one of %s)", s, strings.Join(Strategies, ", "))
+}
+
+// CloneArgs returns the extra git clone arguments for strategy
+func CloneArgs(strategy string) []string {
+	switch strategy {
+	case StrategyShallow:
+		return []string{"--depth", "1"}
+	case StrategyBlobless:
+		return []string{"--filter=blob:none"}
+	case StrategySparse:
+		return []string{"--filter=blob:none", "--sparse"}
+	}
+	return nil
+}
+
+// FetchArgs returns the extra git fetch arguments that keep a clone within
+// strategy. Partial clones remember their filter, so only shallow clones
+// need one.
+func FetchArgs(strategy string) []string {
+	if strategy == StrategyShallow {
+		return []string{"--depth", "1"}
+	}
+	return nil
+}
+
+// DetectStrategy reports which strategy the clone at dir was made with
+func DetectStrategy(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, ".git", "shallow")); err == nil {
+		return StrategyShallow
+	}
+	if gitConfig(dir, "core.sparseCheckout") == "true" {
+		return StrategySparse
+	}
+	if gitConfig(dir, "remote.origin.partialclonefilter") != "" {
+		return StrategyBlobless
+	}
+	return StrategyFull
+}
+
+func gitConfig(dir, key string) string {
+	out, err := exec.Command("git", "-C", dir, "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// SparseDirs returns the directories a sparse checkout of the clone at root
+// needs for plugins, relative to root and slash-separated. The manifest
+// directory is always included. A plugin that isn't checked out yet gets
+// every place it might live, since git ignores patterns that match nothing.
+// It returns nil when a plugin is the whole clone and nothing can be left out.
+func SparseDirs(root string, plugins []string) []string {
+	seen := map[string]bool{".claude-plugin": true}
+	for _, name := range plugins {
+		candidates := pluginCandidates(root, name)
+		if dir, ok := PluginDir(root, name); ok {
+			candidates = []string{dir}
+		}
+		for _, dir := range candidates {
+			rel, err := filepath.Rel(root, dir)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			if rel == "." {
+				return nil
+			}
+			seen[filepath.ToSlash(rel)] = true
+		}
+	}
+	dirs := make([]string, 0, len(seen))
+	for dir := range seen {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
@@ -0,0 +1,83 @@
+// ABOUTME: Unit tests for marketplace clone strategies
+// ABOUTME: Covers strategy parsing, detection on real clones, and sparse directory selection
+package marketplace
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseStrategy(t *testing.T) {
+	if s, err := ParseStrategy(""); err != nil || s != StrategyFull {
+		t.Errorf("ParseStrategy(\"\") = %q, %v; want full", s, err)
+	}
+	if s, err := ParseStrategy("sparse"); err != nil || s != StrategySparse {
+		t.Errorf("ParseStrategy(sparse) = %q, %v", s, err)
+	}
+	if _, err := ParseStrategy("deep"); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
+
+func TestDetectStrategy(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	origin := filepath.Join(root, "origin")
+	git("init", "-q", origin)
+	writeManifest(t, origin, `{"name": "acme"}`)
+	git("-C", origin, "add", "-A")
+	git("-C", origin, "commit", "-q", "-m", "one")
+	git("-C", origin, "commit", "-q", "--allow-empty", "-m", "two")
+	git("-C", origin, "config", "uploadpack.allowFilter", "true")
+
+	url := "file://" + filepath.ToSlash(origin)
+	if runtime.GOOS == "windows" {
+		url = "file:///" + filepath.ToSlash(origin)
+	}
+	for _, strategy := range Strategies {
+		clone := filepath.Join(root, strategy)
+		git(append(append([]string{"clone", "-q"}, CloneArgs(strategy)...), url, clone)...)
+		if got := DetectStrategy(clone); got != strategy {
+			t.Errorf("DetectStrategy(%s clone) = %s", strategy, got)
+		}
+	}
+}
+
+func TestSparseDirs(t *testing.T) {
+	root := t.TempDir()
+	writeManifest(t, root, `{
+		"name": "acme",
+		"plugins": [
+			{"name": "lint", "source": "./tools/lint"},
+			{"name": "self", "source": "./"}
+		]
+	}`)
+	mkdir(t, filepath.Join(root, "tools", "lint"))
+
+	got := strings.Join(SparseDirs(root, []string{"lint"}), ",")
+	if got != ".claude-plugin,tools/lint" {
+		t.Errorf("SparseDirs(lint) = %s", got)
+	}
+
+	// Not checked out yet: every possible location
+	got = strings.Join(SparseDirs(root, []string{"fmt"}), ",")
+	if got != ".claude-plugin,fmt,plugins/fmt,skills/fmt" {
+		t.Errorf("SparseDirs(fmt) = %s", got)
+	}
+
+	if dirs := SparseDirs(root, []string{"lint", "self"}); dirs != nil {
+		t.Errorf("Expected nil when a plugin is the whole clone, got %v", dirs)
+	}
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Reads a marketplace clone's .claude-plugin/marketplace.json
+// ABOUTME: Finds where each plugin lives inside the clone, for any marketplace layout
+package marketplace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Manifest is the subset of .claude-plugin/marketplace.json claudeup uses
+type Manifest struct {
+	Name     string `json:"name"`
+	Metadata struct {
+		// PluginRoot is prepended to plugin sources that are bare names
+		PluginRoot string `json:"pluginRoot"`
+	} `json:"metadata"`
+	Plugins []ManifestPlugin `json:"plugins"`
+}
+
+// ManifestPlugin is one plugin entry in the marketplace manifest
+type ManifestPlugin struct {
+	Name string `json:"name"`
+	// Source is a relative path for plugins inside the marketplace, or an
+	// object describing a remote source
+	Source json.RawMessage `json:"source"`
+}
+
+// LocalSource returns the plugin's relative source path, or "" if the
+// plugin comes from somewhere outside the marketplace
+func (p ManifestPlugin) LocalSource() string {
+	var source string
+	if json.Unmarshal(p.Source, &source) == nil {
+		return source
+	}
+	return ""
+}
+
+// LoadManifest reads .claude-plugin/marketplace.json from a marketplace clone
+func LoadManifest(root string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(root, ".claude-plugin", "marketplace.json"))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// PluginDir returns the directory of plugin name inside the marketplace clone
+// at root. The manifest's declared source wins; otherwise the conventional
+// plugins/, skills/, and top-level directories are tried.
+func PluginDir(root, name string) (string, bool) {
+	for _, dir := range pluginCandidates(root, name) {
+		if isDir(dir) {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// pluginCandidates lists where plugin name may live inside the clone at
+// root, most likely first
+func pluginCandidates(root, name string) []string {
+	var candidates []string
+
+	if m, err := LoadManifest(root); err == nil {
+		for _, p := range m.Plugins {
+			if p.Name != name {
+				continue
+			}
+			if source := p.LocalSource(); source != "" {
+				candidates = append(candidates, filepath.Join(root, source))
+				if m.Metadata.PluginRoot != "" {
+					candidates = append(candidates, filepath.Join(root, m.Metadata.PluginRoot, source))
+				}
+			}
+		}
+		if m.Metadata.PluginRoot != "" {
+			candidates = append(candidates, filepath.Join(root, m.Metadata.PluginRoot, name))
+		}
+	}
+
+	candidates = append(candidates,
+		filepath.Join(root, "plugins", name),
+		filepath.Join(root, "skills", name),
+		filepath.Join(root, name),
+	)
+	return candidates
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
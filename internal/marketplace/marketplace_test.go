@@ -0,0 +1,67 @@
+// ABOUTME: Unit tests for locating plugins inside marketplace clones
+// ABOUTME: Covers manifest sources, pluginRoot, and directory conventions
+package marketplace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func mkdir(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeManifest(t *testing.T, root, content string) {
+	t.Helper()
+	mkdir(t, filepath.Join(root, ".claude-plugin"))
+	if err := os.WriteFile(filepath.Join(root, ".claude-plugin", "marketplace.json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPluginDir(t *testing.T) {
+	root := t.TempDir()
+	writeManifest(t, root, `{
+		"name": "acme",
+		"metadata": {"pluginRoot": "./extensions"},
+		"plugins": [
+			{"name": "lint", "source": "./tools/lint"},
+			{"name": "remote", "source": {"source": "github", "repo": "acme/remote"}},
+			{"name": "self", "source": "./"}
+		]
+	}`)
+	mkdir(t, filepath.Join(root, "tools", "lint"))
+	mkdir(t, filepath.Join(root, "extensions", "fmt"))
+	mkdir(t, filepath.Join(root, "skills", "pdf"))
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"lint", filepath.Join(root, "tools", "lint")},
+		{"fmt", filepath.Join(root, "extensions", "fmt")},
+		{"pdf", filepath.Join(root, "skills", "pdf")},
+		{"self", root},
+		{"remote", ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := PluginDir(root, tt.name)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("PluginDir(%s) = %q, %v; want %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestPluginDirWithoutManifest(t *testing.T) {
+	root := t.TempDir()
+	mkdir(t, filepath.Join(root, "plugins", "review"))
+
+	if got, ok := PluginDir(root, "review"); !ok || got != filepath.Join(root, "plugins", "review") {
+		t.Errorf("PluginDir(review) = %q, %v", got, ok)
+	}
+}
@@ -0,0 +1,201 @@
+// ABOUTME: Finds MCP servers with the same name defined in several places
+// ABOUTME: Ranks definitions by Claude's precedence: project, then user, then plugin
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// Definition sources, from highest to lowest precedence
+const (
+	SourceProject = "project" // .mcp.json in the project directory
+	SourceUser    = "user"    // mcpServers in ~/.claude.json
+	SourcePlugin  = "plugin"  // mcpServers in a plugin's plugin.json
+)
+
+var precedence = map[string]int{SourceProject: 0, SourceUser: 1, SourcePlugin: 2}
+
+// Definition is one place an MCP server is defined
+type Definition struct {
+	Name   string           `json:"name"`
+	Source string           `json:"source"`
+	Plugin string           `json:"plugin,omitempty"`
+	Path   string           `json:"path"`
+	Server ServerDefinition `json:"server"`
+}
+
+// Ref returns the <plugin>:<server> reference used by 'claudeup mcp disable'
+func (d Definition) Ref() string {
+	return d.Plugin + ":" + d.Name
+}
+
+// Location describes where the definition lives
+func (d Definition) Location() string {
+	if d.Source == SourcePlugin {
+		return "plugin " + d.Plugin
+	}
+	return fmt.Sprintf("%s (%s)", d.Source, d.Path)
+}
+
+// Conflict is an MCP server name defined more than once
+type Conflict struct {
+	Name string `json:"name"`
+
+	// Definitions are sorted by precedence; the first one wins unless Ambiguous
+	Definitions []Definition `json:"definitions"`
+
+	// Ambiguous is set when the top definitions share a precedence level,
+	// e.g. two plugins shipping a server with the same name
+	Ambiguous bool `json:"ambiguous"`
+
+	// Identical is set when every definition runs the same command
+	Identical bool `json:"identical"`
+}
+
+// Suggestions returns commands that leave a single definition of the server
+func (c Conflict) Suggestions() []string {
+	var out []string
+	for _, d := range c.Definitions[1:] {
+		switch d.Source {
+		case SourcePlugin:
+			out = append(out, "claudeup mcp disable "+d.Ref())
+		case SourceUser:
+			out = append(out, fmt.Sprintf("claude mcp remove %s --scope user", d.Name))
+		}
+	}
+	return out
+}
+
+// CollectDefinitions gathers MCP server definitions from the project's
+// .mcp.json, the user's .claude.json, and installed plugins. Plugin servers
+// for which disabled returns true are skipped, since Claude won't load them.
+func CollectDefinitions(registry *claude.PluginRegistry, claudeJSONPath, projectDir string, disabled func(ref string) bool) ([]Definition, error) {
+	var defs []Definition
+
+	projectPath := filepath.Join(projectDir, ".mcp.json")
+	project, err := LoadProjectServers(projectPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", projectPath, err)
+	}
+	for name, server := range project {
+		defs = append(defs, Definition{Name: name, Source: SourceProject, Path: projectPath, Server: server})
+	}
+
+	user, err := state.LoadMCPServers(claudeJSONPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", claudeJSONPath, err)
+	}
+	for name, server := range user {
+		defs = append(defs, Definition{
+			Name:   name,
+			Source: SourceUser,
+			Path:   claudeJSONPath,
+			Server: ServerDefinition{Command: server.Command, Args: server.Args, Env: server.Env},
+		})
+	}
+
+	plugins, err := DiscoverMCPServers(registry)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range plugins {
+		for name, server := range p.Servers {
+			d := Definition{Name: name, Source: SourcePlugin, Plugin: p.PluginName, Path: p.PluginPath, Server: server}
+			if disabled != nil && disabled(d.Ref()) {
+				continue
+			}
+			defs = append(defs, d)
+		}
+	}
+
+	return defs, nil
+}
+
+// LoadProjectServers reads the mcpServers section of a project .mcp.json
+func LoadProjectServers(path string) (map[string]ServerDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		MCPServers map[string]ServerDefinition `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return file.MCPServers, nil
+}
+
+// FindConflicts groups definitions by server name and returns the names
+// defined more than once, sorted by name
+func FindConflicts(defs []Definition) []Conflict {
+	byName := make(map[string][]Definition)
+	for _, d := range defs {
+		byName[d.Name] = append(byName[d.Name], d)
+	}
+
+	var conflicts []Conflict
+	for name, list := range byName {
+		if len(list) < 2 {
+			continue
+		}
+		sortByPrecedence(list)
+
+		identical := true
+		for _, d := range list[1:] {
+			if !reflect.DeepEqual(d.Server, list[0].Server) {
+				identical = false
+			}
+		}
+
+		conflicts = append(conflicts, Conflict{
+			Name:        name,
+			Definitions: list,
+			Ambiguous:   list[0].Source == list[1].Source,
+			Identical:   identical,
+		})
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Name < conflicts[j].Name
+	})
+	return conflicts
+}
+
+// Lookup finds the definition Claude would load for a server name, or for
+// a <plugin>:<server> reference the one that plugin ships
+func Lookup(defs []Definition, ref string) (Definition, error) {
+	var matches []Definition
+	for _, d := range defs {
+		if d.Name == ref || d.Source == SourcePlugin && d.Ref() == ref {
+			matches = append(matches, d)
+		}
+	}
+	if len(matches) == 0 {
+		return Definition{}, fmt.Errorf("no MCP server %q in the project's .mcp.json, ~/.claude.json, or installed plugins", ref)
+	}
+	sortByPrecedence(matches)
+	if len(matches) > 1 && matches[0].Source == SourcePlugin && matches[1].Source == SourcePlugin {
+		return Definition{}, fmt.Errorf("several plugins define MCP server %q: name one as %s or %s", ref, matches[0].Ref(), matches[1].Ref())
+	}
+	return matches[0], nil
+}
+
+// sortByPrecedence orders definitions of one name with the one Claude
+// loads first
+func sortByPrecedence(list []Definition) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if precedence[list[i].Source] != precedence[list[j].Source] {
+			return precedence[list[i].Source] < precedence[list[j].Source]
+		}
+		return list[i].Plugin < list[j].Plugin
+	})
+}
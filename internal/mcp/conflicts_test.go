@@ -0,0 +1,91 @@
+// ABOUTME: Unit tests for duplicate MCP server detection
+// ABOUTME: Covers precedence ordering, ambiguous plugin clashes, and disabled servers
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/claude"
+)
+
+func writePluginJSON(t *testing.T, dir string, servers map[string]ServerDefinition) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, ".claude-plugin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(PluginJSON{Name: filepath.Base(dir), MCPServers: servers})
+	if err := os.WriteFile(filepath.Join(dir, ".claude-plugin", "plugin.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	tempDir := t.TempDir()
+
+	projectDir := filepath.Join(tempDir, "project")
+	os.MkdirAll(projectDir, 0755)
+	os.WriteFile(filepath.Join(projectDir, ".mcp.json"), []byte(`{"mcpServers": {"github": {"command": "gh-mcp"}}}`), 0644)
+
+	claudeJSON := filepath.Join(tempDir, ".claude.json")
+	os.WriteFile(claudeJSON, []byte(`{"mcpServers": {"github": {"command": "npx", "args": ["github-mcp"]}}}`), 0644)
+
+	writePluginJSON(t, filepath.Join(tempDir, "a"), map[string]ServerDefinition{
+		"github": {Command: "npx", Args: []string{"github-mcp"}},
+		"db":     {Command: "pg-mcp"},
+	})
+	writePluginJSON(t, filepath.Join(tempDir, "b"), map[string]ServerDefinition{"db": {Command: "mysql-mcp"}})
+	writePluginJSON(t, filepath.Join(tempDir, "c"), map[string]ServerDefinition{"db": {Command: "sqlite-mcp"}})
+
+	registry := &claude.PluginRegistry{Version: 2, Plugins: make(map[string][]claude.PluginMetadata)}
+	for _, name := range []string{"a", "b", "c"} {
+		registry.SetPlugin(name+"@m", claude.PluginMetadata{InstallPath: filepath.Join(tempDir, name)})
+	}
+
+	disabled := func(ref string) bool { return ref == "c@m:db" }
+	defs, err := CollectDefinitions(registry, claudeJSON, projectDir, disabled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts := FindConflicts(defs)
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %+v", conflicts)
+	}
+
+	db := conflicts[0]
+	if db.Name != "db" || !db.Ambiguous || db.Identical || len(db.Definitions) != 2 {
+		t.Errorf("Unexpected db conflict %+v", db)
+	}
+	if got := db.Suggestions(); !reflect.DeepEqual(got, []string{"claudeup mcp disable b@m:db"}) {
+		t.Errorf("Unexpected db suggestions %v", got)
+	}
+
+	gh := conflicts[1]
+	var sources []string
+	for _, d := range gh.Definitions {
+		sources = append(sources, d.Source)
+	}
+	if gh.Ambiguous || !reflect.DeepEqual(sources, []string{SourceProject, SourceUser, SourcePlugin}) {
+		t.Errorf("Expected project > user > plugin, got %v (ambiguous %v)", sources, gh.Ambiguous)
+	}
+	want := []string{"claude mcp remove github --scope user", "claudeup mcp disable a@m:github"}
+	if got := gh.Suggestions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggestions = %v, want %v", got, want)
+	}
+}
+
+func TestFindConflictsIdentical(t *testing.T) {
+	server := ServerDefinition{Command: "npx", Args: []string{"ctx"}}
+	conflicts := FindConflicts([]Definition{
+		{Name: "ctx", Source: SourceUser, Server: server},
+		{Name: "ctx", Source: SourcePlugin, Plugin: "p@m", Server: server},
+		{Name: "solo", Source: SourceUser},
+	})
+	if len(conflicts) != 1 || !conflicts[0].Identical || conflicts[0].Definitions[0].Source != SourceUser {
+		t.Errorf("Unexpected conflicts %+v", conflicts)
+	}
+}
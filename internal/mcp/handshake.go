@@ -0,0 +1,366 @@
+// ABOUTME: One-shot MCP session against a stdio server: initialize, then list what it offers
+// ABOUTME: Backs 'claudeup mcp test' for debugging servers without starting Claude Code
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/cmdline"
+)
+
+// ProtocolVersion is the MCP revision claudeup asks for in initialize
+const ProtocolVersion = "2025-06-18"
+
+// maxPages bounds how many pages of a list are fetched
+const maxPages = 20
+
+// Launch is how to start a stdio MCP server
+type Launch struct {
+	Command string
+	Args    []string
+	Env     []string // added to claudeup's environment, as KEY=value
+	Dir     string
+}
+
+// Launch returns how Claude Code starts the server: ${VAR} and
+// ${VAR:-default} in its command, args, and env are expanded, and a plugin
+// server also gets ${CLAUDE_PLUGIN_ROOT}
+func (d Definition) Launch(dir string) Launch {
+	lookup := os.LookupEnv
+	l := Launch{Dir: dir}
+	if d.Source == SourcePlugin {
+		l.Env = append(l.Env, "CLAUDE_PLUGIN_ROOT="+d.Path)
+		lookup = func(key string) (string, bool) {
+			if key == "CLAUDE_PLUGIN_ROOT" {
+				return d.Path, true
+			}
+			return os.LookupEnv(key)
+		}
+	}
+	l.Command = expandVars(d.Server.Command, lookup)
+	for _, arg := range d.Server.Args {
+		l.Args = append(l.Args, expandVars(arg, lookup))
+	}
+	keys := make([]string, 0, len(d.Server.Env))
+	for k := range d.Server.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		l.Env = append(l.Env, k+"="+expandVars(d.Server.Env[k], lookup))
+	}
+	return l
+}
+
+// expandVars replaces ${VAR} and ${VAR:-default}; a bare $VAR is left alone
+func expandVars(s string, lookup func(string) (string, bool)) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			break
+		}
+		b.WriteString(s[:start])
+		name, fallback, hasFallback := strings.Cut(s[start+2:start+end], ":-")
+		if value, ok := lookup(name); ok && (value != "" || !hasFallback) {
+			b.WriteString(value)
+		} else {
+			b.WriteString(fallback)
+		}
+		s = s[start+end+1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// Report is what a server said about itself in one session
+type Report struct {
+	ServerName      string        `json:"serverName"`
+	ServerVersion   string        `json:"serverVersion,omitempty"`
+	ProtocolVersion string        `json:"protocolVersion"`
+	Capabilities    []string      `json:"capabilities"`
+	Instructions    string        `json:"instructions,omitempty"`
+	Tools           []Tool        `json:"tools"`
+	Resources       []Resource    `json:"resources"`
+	Prompts         []Prompt      `json:"prompts"`
+	Elapsed         time.Duration `json:"-"`
+}
+
+// MarshalJSON reports Elapsed in milliseconds
+func (r Report) MarshalJSON() ([]byte, error) {
+	type report Report
+	out := struct {
+		report
+		Elapsed int64 `json:"elapsedMs"`
+	}{report(r), r.Elapsed.Milliseconds()}
+	return json.Marshal(out)
+}
+
+// Tool is a tool the server offers
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Resource is a resource the server offers
+type Resource struct {
+	URI  string `json:"uri"`
+	Name string `json:"name,omitempty"`
+}
+
+// Prompt is a prompt template the server offers
+type Prompt struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Handshake starts the server, initializes a session, lists the tools,
+// resources, and prompts its capabilities announce, and stops it. The whole
+// session must finish within timeout. Errors end with the last line the
+// server wrote to stderr, which usually says what went wrong.
+func Handshake(ctx context.Context, l Launch, timeout time.Duration) (*Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	path, err := exec.LookPath(l.Command)
+	if err != nil {
+		return nil, fmt.Errorf("command %s not found", l.Command)
+	}
+	cmd, err := cmdline.Command(ctx, path, l.Args...)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = append(os.Environ(), l.Env...)
+	cmd.Dir = l.Dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// A server that spawns children (npx, uvx) can hold the pipes open
+	cmd.WaitDelay = time.Second
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start: %w", err)
+	}
+	s := &session{stdin: stdin, pending: make(map[int64]chan rpcResponse), done: make(chan struct{})}
+	go s.read(stdout)
+
+	report, err := s.run(ctx)
+	stdin.Close()
+	cancel()
+	_ = cmd.Wait()
+
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("%w within %s", err, timeout)
+		}
+		if line := lastLine(stderr.String()); line != "" {
+			return nil, fmt.Errorf("%w: %s", err, line)
+		}
+		return nil, err
+	}
+	report.Elapsed = time.Since(start)
+	return report, nil
+}
+
+func (s *session) run(ctx context.Context) (*Report, error) {
+	var init struct {
+		ProtocolVersion string                     `json:"protocolVersion"`
+		Capabilities    map[string]json.RawMessage `json:"capabilities"`
+		ServerInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+		Instructions string `json:"instructions"`
+	}
+	err := s.call(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": "claudeup", "version": "mcp-test"},
+	}, &init)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.notify("notifications/initialized")
+
+	r := &Report{
+		ServerName:      init.ServerInfo.Name,
+		ServerVersion:   init.ServerInfo.Version,
+		ProtocolVersion: init.ProtocolVersion,
+		Instructions:    init.Instructions,
+		Capabilities:    []string{},
+		Tools:           []Tool{},
+		Resources:       []Resource{},
+		Prompts:         []Prompt{},
+	}
+	for name := range init.Capabilities {
+		r.Capabilities = append(r.Capabilities, name)
+	}
+	sort.Strings(r.Capabilities)
+
+	if _, ok := init.Capabilities["tools"]; ok {
+		if err := listAll(ctx, s, "tools/list", "tools", &r.Tools); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := init.Capabilities["resources"]; ok {
+		if err := listAll(ctx, s, "resources/list", "resources", &r.Resources); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := init.Capabilities["prompts"]; ok {
+		if err := listAll(ctx, s, "prompts/list", "prompts", &r.Prompts); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// listAll calls a paginated list method and collects every page's items
+func listAll[T any](ctx context.Context, s *session, method, key string, items *[]T) error {
+	cursor := ""
+	for page := 0; page < maxPages; page++ {
+		params := map[string]string{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var result map[string]json.RawMessage
+		if err := s.call(ctx, method, params, &result); err != nil {
+			return err
+		}
+		var batch []T
+		if raw, ok := result[key]; ok {
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return fmt.Errorf("%s: %w", method, err)
+			}
+		}
+		*items = append(*items, batch...)
+		cursor = ""
+		if raw, ok := result["nextCursor"]; ok {
+			_ = json.Unmarshal(raw, &cursor)
+		}
+		if cursor == "" {
+			return nil
+		}
+	}
+	return nil
+}
+
+type rpcResponse struct {
+	Result json.RawMessage
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+}
+
+// session is a JSON-RPC connection over the server's stdin and stdout
+type session struct {
+	stdin   io.Writer
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan rpcResponse
+	done    chan struct{} // closed when stdout ends
+}
+
+func (s *session) call(ctx context.Context, method string, params, result interface{}) error {
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	reply := make(chan rpcResponse, 1)
+	s.pending[id] = reply
+	s.mu.Unlock()
+
+	// A failed write means the server exited, which is reported below
+	_ = s.send(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	var resp rpcResponse
+	select {
+	case resp = <-reply:
+	case <-s.done:
+		// The answer may have come just before the server exited
+		select {
+		case resp = <-reply:
+		default:
+			return fmt.Errorf("exited before answering %s", method)
+		}
+	case <-ctx.Done():
+		return fmt.Errorf("no answer to %s", method)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%s failed: %s", method, resp.Error.Message)
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("%s: invalid result: %w", method, err)
+	}
+	return nil
+}
+
+func (s *session) notify(method string) error {
+	return s.send(map[string]interface{}{"jsonrpc": "2.0", "method": method})
+}
+
+func (s *session) send(msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = s.stdin.Write(append(data, '\n'))
+	return err
+}
+
+// read routes responses to their calls until stdout closes. Lines that
+// aren't responses, such as log output or server requests, are skipped.
+func (s *session) read(stdout io.Reader) {
+	defer close(s.done)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var msg struct {
+			ID     *int64          `json:"id"`
+			Method string          `json:"method"`
+			Result json.RawMessage `json:"result"`
+			Error  *struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &msg) != nil || msg.ID == nil || msg.Method != "" {
+			continue
+		}
+		s.mu.Lock()
+		reply, ok := s.pending[*msg.ID]
+		delete(s.pending, *msg.ID)
+		s.mu.Unlock()
+		if ok {
+			reply <- rpcResponse{Result: msg.Result, Error: msg.Error}
+		}
+	}
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
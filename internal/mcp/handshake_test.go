@@ -0,0 +1,133 @@
+// ABOUTME: Tests for the one-shot MCP handshake
+// ABOUTME: Runs shell-script servers that answer initialize and paginated list calls
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServer answers each JSON-RPC request line by matching its method,
+// with promptsResult as the prompts/list result or error member
+func fakeServer(promptsResult string) string {
+	return `#!/bin/sh
+while read -r line; do
+  id=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+  case "$line" in
+    *'"initialize"'*)
+      echo "starting up"
+      echo '{"jsonrpc":"2.0","method":"notifications/message","params":{}}'
+      echo '{"jsonrpc":"2.0","id":'$id',"result":{"protocolVersion":"2025-06-18","capabilities":{"tools":{},"prompts":{}},"serverInfo":{"name":"demo","version":"1.2.0"}}}' ;;
+    *'"tools/list"'*'"cursor"'*)
+      printf '%s\n' '{"jsonrpc":"2.0","id":'$id',"result":{"tools":[{"name":"write","description":"Write a file\nin detail"}]}}' ;;
+    *'"tools/list"'*)
+      echo '{"jsonrpc":"2.0","id":'$id',"result":{"tools":[{"name":"read"}],"nextCursor":"p2"}}' ;;
+    *'"prompts/list"'*)
+      echo '{"jsonrpc":"2.0","id":'$id',` + promptsResult + `}' ;;
+  esac
+done
+`
+}
+
+func writeServer(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "server")
+	if err := os.WriteFile(path, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestHandshakeListsWhatServerOffers(t *testing.T) {
+	server := writeServer(t, fakeServer(`"result":{"prompts":[{"name":"review"}]}`))
+
+	r, err := Handshake(context.Background(), Launch{Command: server}, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ServerName != "demo" || r.ServerVersion != "1.2.0" || r.ProtocolVersion != "2025-06-18" {
+		t.Errorf("Unexpected server info %+v", r)
+	}
+	if !reflect.DeepEqual(r.Capabilities, []string{"prompts", "tools"}) {
+		t.Errorf("Expected sorted capabilities, got %v", r.Capabilities)
+	}
+	if len(r.Tools) != 2 || r.Tools[0].Name != "read" || r.Tools[1].Name != "write" {
+		t.Errorf("Expected both pages of tools, got %+v", r.Tools)
+	}
+	if len(r.Prompts) != 1 || len(r.Resources) != 0 {
+		t.Errorf("Expected one prompt and no resources, got %+v, %+v", r.Prompts, r.Resources)
+	}
+}
+
+func TestHandshakeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		server string
+		want   string
+	}{
+		{"list error", fakeServer(`"error":{"code":-32601,"message":"not implemented"}`), "prompts/list failed: not implemented"},
+		{"exits", "#!/bin/sh\necho 'missing API_KEY' >&2\nexit 1\n", "exited before answering initialize: missing API_KEY"},
+		{"silent", "#!/bin/sh\nsleep 5\n", "no answer to initialize within 200ms"},
+	}
+	for _, tt := range tests {
+		_, err := Handshake(context.Background(), Launch{Command: writeServer(t, tt.server)}, 200*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	if _, err := Handshake(context.Background(), Launch{Command: "no-such-mcp-server"}, time.Second); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a missing command to be reported, got %v", err)
+	}
+}
+
+func TestDefinitionLaunchExpandsVariables(t *testing.T) {
+	t.Setenv("MCP_TEST_TOKEN", "secret")
+	d := Definition{
+		Name:   "db",
+		Source: SourcePlugin,
+		Plugin: "tools@acme",
+		Path:   "/plugins/tools",
+		Server: ServerDefinition{
+			Command: "${CLAUDE_PLUGIN_ROOT}/bin/db",
+			Args:    []string{"--port", "${MCP_TEST_PORT:-5432}", "$HOME"},
+			Env:     map[string]string{"TOKEN": "${MCP_TEST_TOKEN}"},
+		},
+	}
+	l := d.Launch("/work")
+	if l.Command != "/plugins/tools/bin/db" || l.Dir != "/work" {
+		t.Errorf("Unexpected launch %+v", l)
+	}
+	if !reflect.DeepEqual(l.Args, []string{"--port", "5432", "$HOME"}) {
+		t.Errorf("Unexpected args %v", l.Args)
+	}
+	if !reflect.DeepEqual(l.Env, []string{"CLAUDE_PLUGIN_ROOT=/plugins/tools", "TOKEN=secret"}) {
+		t.Errorf("Unexpected env %v", l.Env)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	defs := []Definition{
+		{Name: "db", Source: SourcePlugin, Plugin: "a@m"},
+		{Name: "db", Source: SourceUser},
+		{Name: "web", Source: SourcePlugin, Plugin: "a@m"},
+		{Name: "web", Source: SourcePlugin, Plugin: "b@m"},
+	}
+	if d, err := Lookup(defs, "db"); err != nil || d.Source != SourceUser {
+		t.Errorf("Expected the user definition to win, got %+v, %v", d, err)
+	}
+	if d, err := Lookup(defs, "a@m:db"); err != nil || d.Plugin != "a@m" {
+		t.Errorf("Expected the plugin's definition, got %+v, %v", d, err)
+	}
+	if _, err := Lookup(defs, "web"); err == nil || !strings.Contains(err.Error(), "a@m:web") {
+		t.Errorf("Expected two plugins to be ambiguous, got %v", err)
+	}
+	if _, err := Lookup(defs, "cache"); err == nil {
+		t.Error("Expected an unknown server to fail")
+	}
+}
@@ -0,0 +1,147 @@
+// ABOUTME: MCP server exposing claudeup as tools over stdio JSON-RPC
+// ABOUTME: Lets Claude Code inspect and, with explicit confirmation, change its own configuration
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/claudeup/claudeup/pkg/claudeup"
+)
+
+// ProtocolVersion is the MCP protocol revision this server speaks
+const ProtocolVersion = "2024-11-05"
+
+// Options configures the MCP server
+type Options struct {
+	Client  *claudeup.Client
+	Version string
+
+	// Doctor returns a diagnostics report that is serialized as JSON
+	Doctor func() (interface{}, error)
+
+	// BeforeApply is called once a profile apply is approved, before any
+	// change. The context it returns is used for the apply and AfterApply.
+	BeforeApply func(ctx context.Context, name string, diff *claudeup.Diff) context.Context
+
+	// AfterApply is called after a profile is applied successfully
+	AfterApply func(ctx context.Context, name string, diff *claudeup.Diff, result *claudeup.ApplyResult)
+}
+
+// Server answers MCP requests for a single client connection
+type Server struct {
+	opts Options
+	out  io.Writer
+	mu   sync.Mutex
+}
+
+// New creates an MCP server
+func New(opts Options) *Server {
+	return &Server{opts: opts}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Serve reads newline-delimited JSON-RPC messages from r and writes
+// responses to w until r is exhausted or ctx is cancelled
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.out = w
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			s.write(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: err.Error()}})
+			continue
+		}
+
+		result, rerr := s.dispatch(ctx, req)
+
+		// Notifications have no id and never get a response
+		if len(req.ID) == 0 {
+			continue
+		}
+		resp := response{JSONRPC: "2.0", ID: req.ID}
+		if rerr != nil {
+			resp.Error = rerr
+		} else {
+			resp.Result = result
+		}
+		s.write(resp)
+	}
+
+	return scanner.Err()
+}
+
+func (s *Server) write(resp response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, _ := json.Marshal(resp)
+	s.out.Write(append(data, '\n'))
+}
+
+func (s *Server) dispatch(ctx context.Context, req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "claudeup", "version": s.opts.Version},
+		}, nil
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": toolDefinitions()}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.callTool(ctx, params.Name, params.Arguments), nil
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	}
+}
@@ -0,0 +1,230 @@
+// ABOUTME: Tests for the claudeup MCP server
+// ABOUTME: Drives the JSON-RPC loop with scripted requests and a fake executor
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/pkg/claudeup"
+)
+
+type fakeExecutor struct {
+	calls [][]string
+}
+
+func (e *fakeExecutor) Run(ctx context.Context, args ...string) error {
+	e.calls = append(e.calls, args)
+	return nil
+}
+
+func (e *fakeExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	e.calls = append(e.calls, args)
+	return "", nil
+}
+
+func newTestServer(t *testing.T) (*Server, *fakeExecutor) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	if err := os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(claudeDir, "plugins", "installed_plugins.json"), `{"version": 2, "plugins": {}}`)
+	writeFile(t, filepath.Join(claudeDir, "plugins", "known_marketplaces.json"), `{}`)
+	writeFile(t, filepath.Join(tmpDir, ".claude.json"), `{"mcpServers": {}}`)
+
+	profilesDir := filepath.Join(tmpDir, "profiles")
+	if err := os.MkdirAll(profilesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(profilesDir, "dev.json"), `{"name": "dev", "description": "Dev tools", "plugins": ["tool@market"]}`)
+
+	executor := &fakeExecutor{}
+	client, err := claudeup.New(claudeup.Options{
+		ClaudeDir:      claudeDir,
+		ClaudeJSONPath: filepath.Join(tmpDir, ".claude.json"),
+		ProfilesDir:    profilesDir,
+		Executor:       executor,
+		Secrets:        claudeup.NewSecretChain(claudeup.EnvResolver()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return New(Options{
+		Client:  client,
+		Version: "test",
+		Doctor: func() (interface{}, error) {
+			return map[string]int{"issues": 0}, nil
+		},
+	}), executor
+}
+
+// roundTrip sends requests and returns the decoded responses in order
+func roundTrip(t *testing.T, s *Server, requests ...string) []response {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := s.Serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var responses []response
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var r response
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, r)
+	}
+	return responses
+}
+
+func resultText(t *testing.T, r response) (string, bool) {
+	t.Helper()
+
+	data, _ := json.Marshal(r.Result)
+	var result toolResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Content) == 0 {
+		t.Fatalf("Expected tool content, got %s", data)
+	}
+	return result.Content[0].Text, result.IsError
+}
+
+func TestInitializeAndListTools(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	responses := roundTrip(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+	)
+
+	if len(responses) != 2 {
+		t.Fatalf("Expected 2 responses (notification has none), got %d", len(responses))
+	}
+
+	data, _ := json.Marshal(responses[1].Result)
+	for _, name := range []string{"list_profiles", "show_diff", "apply_profile", "doctor_report"} {
+		if !strings.Contains(string(data), `"`+name+`"`) {
+			t.Errorf("Expected tool %s in list, got %s", name, data)
+		}
+	}
+}
+
+func TestApplyRequiresConfirm(t *testing.T) {
+	s, executor := newTestServer(t)
+
+	responses := roundTrip(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"apply_profile","arguments":{"name":"dev"}}}`,
+	)
+	text, isErr := resultText(t, responses[0])
+	if isErr || !strings.Contains(text, "confirm=true") {
+		t.Errorf("Expected a confirmation request, got %q", text)
+	}
+	if len(executor.calls) != 0 {
+		t.Errorf("Expected no commands without confirm, got %v", executor.calls)
+	}
+	plan := planFrom(t, text)
+
+	responses = roundTrip(t, s,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"apply_profile","arguments":{"name":"dev","confirm":true,"plan":"`+plan+`"}}}`,
+	)
+	text, isErr = resultText(t, responses[0])
+	if isErr || !strings.Contains(text, "tool@market") {
+		t.Errorf("Expected applied plugin in result, got %q", text)
+	}
+	if len(executor.calls) != 1 {
+		t.Errorf("Expected one install command, got %v", executor.calls)
+	}
+}
+
+func TestApplyRefusesUnapprovedPlan(t *testing.T) {
+	s, executor := newTestServer(t)
+
+	responses := roundTrip(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"apply_profile","arguments":{"name":"dev"}}}`,
+	)
+	text, _ := resultText(t, responses[0])
+	plan := planFrom(t, text)
+
+	// The profile changes between the preview and the confirmation
+	profilesDir := s.opts.Client.Options().ProfilesDir
+	writeFile(t, filepath.Join(profilesDir, "dev.json"), `{"name": "dev", "plugins": ["tool@market", "extra@market"]}`)
+
+	responses = roundTrip(t, s,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"apply_profile","arguments":{"name":"dev","confirm":true}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"apply_profile","arguments":{"name":"dev","confirm":true,"plan":"`+plan+`"}}}`,
+	)
+	if text, isErr := resultText(t, responses[0]); !isErr || !strings.Contains(text, "plan is required") {
+		t.Errorf("Expected confirm without a plan to be refused, got %q", text)
+	}
+	text, isErr := resultText(t, responses[1])
+	if !isErr || !strings.Contains(text, "no longer match") || !strings.Contains(text, "extra@market") {
+		t.Errorf("Expected a stale plan to be refused with the new changes, got %q", text)
+	}
+	if newPlan := planFrom(t, text); newPlan == plan {
+		t.Error("Expected the changed profile to have a different plan")
+	}
+	if len(executor.calls) != 0 {
+		t.Errorf("Expected no commands for a refused plan, got %v", executor.calls)
+	}
+}
+
+// planFrom returns the plan apply_profile reported in text
+func planFrom(t *testing.T, text string) string {
+	t.Helper()
+	_, rest, ok := strings.Cut(text, "Plan: ")
+	if !ok {
+		t.Fatalf("Expected a plan in %q", text)
+	}
+	plan, _, _ := strings.Cut(rest, "\n")
+	return plan
+}
+
+func TestListProfilesAndDoctor(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	responses := roundTrip(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_profiles"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"doctor_report"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"show_diff","arguments":{"name":"missing"}}}`,
+	)
+
+	if text, _ := resultText(t, responses[0]); !strings.Contains(text, "dev - Dev tools") {
+		t.Errorf("Expected dev profile listed, got %q", text)
+	}
+	if text, _ := resultText(t, responses[1]); !strings.Contains(text, `"issues": 0`) {
+		t.Errorf("Expected doctor JSON, got %q", text)
+	}
+	if _, isErr := resultText(t, responses[2]); !isErr {
+		t.Error("Expected error for unknown profile")
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	responses := roundTrip(t, s, `{"jsonrpc":"2.0","id":7,"method":"resources/list"}`)
+	if responses[0].Error == nil || responses[0].Error.Code != codeMethodNotFound {
+		t.Errorf("Expected method-not-found error, got %+v", responses[0])
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
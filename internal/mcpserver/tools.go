@@ -0,0 +1,261 @@
+// ABOUTME: Tool definitions and handlers for the claudeup MCP server
+// ABOUTME: list_profiles, show_diff, apply_profile (requires confirm and the previewed plan), and doctor_report
+package mcpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/claudeup/claudeup/pkg/claudeup"
+)
+
+type tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+type toolResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func toolDefinitions() []tool {
+	nameProp := map[string]interface{}{"type": "string", "description": "Profile name"}
+	return []tool{
+		{
+			Name:        "list_profiles",
+			Description: "List saved claudeup profiles",
+			InputSchema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+		},
+		{
+			Name:        "show_diff",
+			Description: "Show what applying a profile would change in the Claude Code configuration",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"name": nameProp},
+				"required":   []string{"name"},
+			},
+		},
+		{
+			Name: "apply_profile",
+			Description: "Apply a profile to the Claude Code configuration. Without confirm=true this only " +
+				"returns the pending changes and their plan; ask the user before calling again with confirm=true " +
+				"and that plan.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":    nameProp,
+					"confirm": map[string]interface{}{"type": "boolean", "description": "Set only after the user approved the changes"},
+					"plan":    map[string]interface{}{"type": "string", "description": "The plan returned with the changes the user approved"},
+				},
+				"required": []string{"name"},
+			},
+		},
+		{
+			Name:        "doctor_report",
+			Description: "Run claudeup diagnostics and return the report as JSON",
+			InputSchema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+		},
+	}
+}
+
+type toolArgs struct {
+	Name    string `json:"name"`
+	Confirm bool   `json:"confirm"`
+	Plan    string `json:"plan"`
+}
+
+func (s *Server) callTool(ctx context.Context, name string, raw json.RawMessage) toolResult {
+	var args toolArgs
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return errorResult(fmt.Errorf("invalid arguments: %w", err))
+		}
+	}
+
+	switch name {
+	case "list_profiles":
+		return s.listProfiles(ctx)
+	case "show_diff":
+		return s.showDiff(ctx, args)
+	case "apply_profile":
+		return s.applyProfile(ctx, args)
+	case "doctor_report":
+		return s.doctorReport()
+	default:
+		return errorResult(fmt.Errorf("unknown tool %q", name))
+	}
+}
+
+func (s *Server) listProfiles(ctx context.Context) toolResult {
+	profiles, err := s.opts.Client.ListProfiles(ctx)
+	if err != nil {
+		return errorResult(err)
+	}
+	if len(profiles) == 0 {
+		return textResult("No profiles saved.")
+	}
+
+	var b strings.Builder
+	for _, p := range profiles {
+		if p.Description != "" {
+			fmt.Fprintf(&b, "%s - %s\n", p.Name, p.Description)
+		} else {
+			fmt.Fprintf(&b, "%s\n", p.Name)
+		}
+	}
+	return textResult(b.String())
+}
+
+func (s *Server) showDiff(ctx context.Context, args toolArgs) toolResult {
+	if args.Name == "" {
+		return errorResult(fmt.Errorf("name is required"))
+	}
+	p, err := s.opts.Client.LoadProfile(ctx, args.Name)
+	if err != nil {
+		return errorResult(fmt.Errorf("profile %q not found: %w", args.Name, err))
+	}
+	diff, err := s.opts.Client.Diff(ctx, p)
+	if err != nil {
+		return errorResult(err)
+	}
+	return textResult(formatDiff(args.Name, diff))
+}
+
+func (s *Server) applyProfile(ctx context.Context, args toolArgs) toolResult {
+	if args.Name == "" {
+		return errorResult(fmt.Errorf("name is required"))
+	}
+	p, err := s.opts.Client.LoadProfile(ctx, args.Name)
+	if err != nil {
+		return errorResult(fmt.Errorf("profile %q not found: %w", args.Name, err))
+	}
+	diff, err := s.opts.Client.Diff(ctx, p)
+	if err != nil {
+		return errorResult(err)
+	}
+	if diff.Count() == 0 {
+		return textResult(fmt.Sprintf("Profile %q already matches the current configuration.", args.Name))
+	}
+	plan := planHash(diff)
+	if !args.Confirm {
+		return textResult(formatDiff(args.Name, diff) + fmt.Sprintf("\nPlan: %s\n", plan) +
+			fmt.Sprintf("\nNothing was changed. Ask the user to approve these changes, then call apply_profile again with confirm=true and plan=%q.", plan))
+	}
+	// The configuration may have changed since the user saw the changes, so
+	// only the plan they approved is applied
+	if args.Plan == "" {
+		return errorResult(fmt.Errorf("plan is required with confirm=true; call apply_profile without confirm to get it"))
+	}
+	if args.Plan != plan {
+		return errorResult(fmt.Errorf("the changes no longer match the approved plan, so nothing was changed. Ask the user to approve them again:\n\n%s\nPlan: %s",
+			formatDiff(args.Name, diff), plan))
+	}
+
+	if s.opts.Client.Options().ReadOnly {
+		return errorResult(claudeup.ErrReadOnly)
+	}
+	if s.opts.BeforeApply != nil {
+		ctx = s.opts.BeforeApply(ctx, args.Name, diff)
+	}
+	result, err := s.opts.Client.Apply(ctx, p)
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to apply profile: %w", err))
+	}
+	if s.opts.AfterApply != nil {
+		s.opts.AfterApply(ctx, args.Name, diff, result)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Applied profile %q.\n", args.Name)
+	writeList(&b, "Plugins installed", result.PluginsInstalled)
+	writeList(&b, "Plugins removed", result.PluginsRemoved)
+	writeList(&b, "MCP servers installed", result.MCPServersInstalled)
+	writeList(&b, "MCP servers removed", result.MCPServersRemoved)
+	writeList(&b, "Marketplaces added", result.MarketplacesAdded)
+	for _, e := range result.Errors {
+		fmt.Fprintf(&b, "Error: %v\n", e)
+	}
+	return toolResult{Content: []textContent{{Type: "text", Text: b.String()}}, IsError: len(result.Errors) > 0}
+}
+
+func (s *Server) doctorReport() toolResult {
+	if s.opts.Doctor == nil {
+		return errorResult(fmt.Errorf("doctor is not available"))
+	}
+	report, err := s.opts.Doctor()
+	if err != nil {
+		return errorResult(err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errorResult(err)
+	}
+	return textResult(string(data))
+}
+
+// planHash fingerprints the changes in diff, leaving out the skipped entries
+// and installed plugins, which aren't changes
+func planHash(diff *claudeup.Diff) string {
+	changes := *diff
+	changes.Skipped = nil
+	changes.InstalledPlugins = nil
+	data, _ := json.Marshal(changes)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+func formatDiff(name string, diff *claudeup.Diff) string {
+	if diff.Count() == 0 {
+		return fmt.Sprintf("Profile %q already matches the current configuration.", name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Applying profile %q would make %d changes:\n", name, diff.Count())
+	writeList(&b, "Remove plugins", diff.PluginsToRemove)
+	writeList(&b, "Install plugins", diff.PluginsToInstall)
+	writeList(&b, "Remove MCP servers", diff.MCPToRemove)
+	var mcpNames []string
+	for _, m := range diff.MCPToInstall {
+		mcpNames = append(mcpNames, m.Name)
+	}
+	writeList(&b, "Install MCP servers", mcpNames)
+	var marketplaces []string
+	for _, m := range diff.MarketplacesToAdd {
+		marketplaces = append(marketplaces, m.DisplayName())
+	}
+	writeList(&b, "Add marketplaces", marketplaces)
+	writeList(&b, "Disable plugins", diff.PluginsToDisable)
+	writeList(&b, "Enable plugins", diff.PluginsToEnable)
+	writeList(&b, "Disable MCP servers", diff.MCPToDisable)
+	writeList(&b, "Enable MCP servers", diff.MCPToEnable)
+	return b.String()
+}
+
+func writeList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", title)
+	for _, item := range items {
+		fmt.Fprintf(b, "  - %s\n", item)
+	}
+}
+
+func textResult(text string) toolResult {
+	return toolResult{Content: []textContent{{Type: "text", Text: text}}}
+}
+
+func errorResult(err error) toolResult {
+	return toolResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}
+}
@@ -0,0 +1,175 @@
+// ABOUTME: Notifier subsystem for reporting problems from background or unattended runs
+// ABOUTME: Sends desktop notifications (osascript, notify-send) and JSON/Slack webhooks
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+)
+
+// Levels
+const (
+	LevelInfo    = "info"
+	LevelWarning = "warning"
+	LevelError   = "error"
+)
+
+// Event is something worth telling the user about
+type Event struct {
+	Kind    string // e.g. "apply-failed", "updates-available", "doctor-issues"
+	Level   string
+	Title   string
+	Message string
+}
+
+// ApplyFailed builds the event sent when a profile apply fails or partially fails
+func ApplyFailed(profile, source, detail string) Event {
+	return Event{
+		Kind:    "apply-failed",
+		Level:   LevelError,
+		Title:   "claudeup: apply failed",
+		Message: fmt.Sprintf("Profile %q (via %s): %s", profile, source, detail),
+	}
+}
+
+// Notifier delivers events
+type Notifier interface {
+	Notify(e Event) error
+}
+
+// New builds a notifier from the notifications config. Returns nil if
+// nothing is configured.
+func New(cfg config.Notifications) Notifier {
+	var m Multi
+	if cfg.Desktop {
+		m = append(m, &Desktop{})
+	}
+	if cfg.WebhookURL != "" {
+		m = append(m, &Webhook{URL: cfg.WebhookURL})
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Multi sends to every notifier, collecting failures
+type Multi []Notifier
+
+// Notify implements Notifier
+func (m Multi) Notify(e Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Desktop shows a native notification
+type Desktop struct {
+	// GOOS overrides runtime.GOOS, for tests
+	GOOS string
+	// Run executes the notification command; defaults to exec
+	Run func(name string, args ...string) error
+}
+
+// Notify implements Notifier
+func (d *Desktop) Notify(e Event) error {
+	name, args, err := d.command(e)
+	if err != nil {
+		return err
+	}
+	run := d.Run
+	if run == nil {
+		run = func(name string, args ...string) error {
+			return exec.Command(name, args...).Run()
+		}
+	}
+	if err := run(name, args...); err != nil {
+		return fmt.Errorf("desktop notification failed: %w", err)
+	}
+	return nil
+}
+
+func (d *Desktop) command(e Event) (string, []string, error) {
+	goos := d.GOOS
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(e.Message), appleScriptString(e.Title))
+		return "osascript", []string{"-e", script}, nil
+	case "linux":
+		urgency := "normal"
+		if e.Level == LevelError {
+			urgency = "critical"
+		}
+		return "notify-send", []string{"-u", urgency, "-a", "claudeup", e.Title, e.Message}, nil
+	}
+	return "", nil, fmt.Errorf("desktop notifications are not supported on %s", goos)
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// Webhook posts events as JSON. The "text" field makes the payload a valid
+// Slack incoming webhook message; other fields are for generic receivers.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+type webhookPayload struct {
+	Text    string    `json:"text"`
+	Kind    string    `json:"kind"`
+	Level   string    `json:"level"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Notify implements Notifier
+func (w *Webhook) Notify(e Event) error {
+	body, err := json.Marshal(webhookPayload{
+		Text:    fmt.Sprintf("*%s*\n%s", e.Title, e.Message),
+		Kind:    e.Kind,
+		Level:   e.Level,
+		Title:   e.Title,
+		Message: e.Message,
+		Time:    time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook notification failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook notification failed: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// ABOUTME: Tests for desktop and webhook notifiers
+// ABOUTME: Uses a fake command runner and an httptest server
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/config"
+)
+
+func TestNewReturnsNilWhenUnconfigured(t *testing.T) {
+	if n := New(config.Notifications{}); n != nil {
+		t.Errorf("Expected nil notifier, got %#v", n)
+	}
+	if n := New(config.Notifications{Desktop: true, WebhookURL: "http://x"}); len(n.(Multi)) != 2 {
+		t.Errorf("Expected desktop and webhook notifiers, got %#v", n)
+	}
+}
+
+func TestDesktopCommands(t *testing.T) {
+	e := Event{Level: LevelError, Title: "Apply failed", Message: `profile "work" had 2 errors`}
+
+	tests := []struct {
+		goos string
+		name string
+		want string
+	}{
+		{"darwin", "osascript", `display notification "profile \"work\" had 2 errors" with title "Apply failed"`},
+		{"linux", "notify-send", "critical"},
+	}
+
+	for _, tt := range tests {
+		var gotName string
+		var gotArgs []string
+		d := &Desktop{GOOS: tt.goos, Run: func(name string, args ...string) error {
+			gotName, gotArgs = name, args
+			return nil
+		}}
+		if err := d.Notify(e); err != nil {
+			t.Fatalf("%s: %v", tt.goos, err)
+		}
+		if gotName != tt.name || !strings.Contains(strings.Join(gotArgs, " "), tt.want) {
+			t.Errorf("%s: got %s %q", tt.goos, gotName, gotArgs)
+		}
+	}
+
+	if err := (&Desktop{GOOS: "plan9"}).Notify(e); err == nil {
+		t.Error("Expected error on unsupported OS")
+	}
+}
+
+func TestWebhookPostsSlackCompatiblePayload(t *testing.T) {
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	w := &Webhook{URL: srv.URL}
+	if err := w.Notify(Event{Kind: "apply-failed", Level: LevelError, Title: "T", Message: "M"}); err != nil {
+		t.Fatal(err)
+	}
+	if got["text"] != "*T*\nM" || got["kind"] != "apply-failed" {
+		t.Errorf("Unexpected payload: %v", got)
+	}
+}
+
+func TestWebhookReportsHTTPErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	if err := (&Webhook{URL: srv.URL}).Notify(Event{}); err == nil {
+		t.Error("Expected error for 403 response")
+	}
+}
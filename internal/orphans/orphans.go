@@ -0,0 +1,308 @@
+// ABOUTME: Finds settings.json and .claude.json entries that refer to plugins or MCP servers that no longer exist
+// ABOUTME: Removes the dangling entries and leaves every other entry in place
+package orphans
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// Ref is one dangling entry in a Claude config file
+type Ref struct {
+	File   string `json:"file"`
+	Key    string `json:"key"`   // e.g. enabledPlugins or permissions.allow
+	Value  string `json:"value"` // plugin name, permission rule, or server name
+	Reason string `json:"reason"`
+
+	// Project is set for per-project entries in .claude.json
+	Project string `json:"project,omitempty"`
+}
+
+// Location returns a short description of where the entry lives
+func (r Ref) Location() string {
+	if r.Project != "" {
+		return fmt.Sprintf("projects[%q].%s", r.Project, r.Key)
+	}
+	return r.Key
+}
+
+// Known lists the plugins and MCP servers that still exist
+type Known struct {
+	Plugins    map[string]bool
+	MCPServers map[string]bool
+}
+
+var permissionLists = []string{"allow", "deny", "ask"}
+
+// FindInSettings scans settings.json for enabledPlugins entries naming
+// unknown plugins and permission rules for unknown MCP servers
+func FindInSettings(path string, known Known) ([]Ref, error) {
+	top, err := readObject(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Ref
+
+	var enabled map[string]json.RawMessage
+	if raw, ok := top["enabledPlugins"]; ok && json.Unmarshal(raw, &enabled) == nil {
+		for name := range enabled {
+			if !known.Plugins[name] {
+				refs = append(refs, Ref{File: path, Key: "enabledPlugins", Value: name, Reason: "plugin is not installed"})
+			}
+		}
+	}
+
+	var permissions map[string]json.RawMessage
+	if raw, ok := top["permissions"]; ok && json.Unmarshal(raw, &permissions) == nil {
+		for _, list := range permissionLists {
+			var rules []string
+			if json.Unmarshal(permissions[list], &rules) != nil {
+				continue
+			}
+			for _, rule := range rules {
+				server, ok := mcpServerOf(rule)
+				if ok && !known.MCPServers[server] {
+					refs = append(refs, Ref{File: path, Key: "permissions." + list, Value: rule, Reason: fmt.Sprintf("MCP server %s is not configured", server)})
+				}
+			}
+		}
+	}
+
+	sortRefs(refs)
+	return refs, nil
+}
+
+// mcpServerOf returns the server named by an mcp__<server>[__<tool>]
+// permission rule. Plugin-provided servers use generated names and are
+// never reported.
+func mcpServerOf(rule string) (string, bool) {
+	rest, ok := strings.CutPrefix(rule, "mcp__")
+	if !ok {
+		return "", false
+	}
+	server, _, _ := strings.Cut(rest, "__")
+	if server == "" || strings.HasPrefix(server, "plugin_") {
+		return "", false
+	}
+	return server, true
+}
+
+type projectEntry struct {
+	MCPServers             map[string]json.RawMessage `json:"mcpServers"`
+	EnabledMcpjsonServers  []string                   `json:"enabledMcpjsonServers"`
+	DisabledMcpjsonServers []string                   `json:"disabledMcpjsonServers"`
+}
+
+// ProjectServers returns the names of MCP servers defined per project in
+// .claude.json and in each existing project's .mcp.json
+func ProjectServers(claudeJSONPath string) (map[string]bool, error) {
+	projects, err := readProjects(claudeJSONPath)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for dir, p := range projects {
+		for name := range p.MCPServers {
+			names[name] = true
+		}
+		for name := range mcpJSONServers(dir) {
+			names[name] = true
+		}
+	}
+	return names, nil
+}
+
+// FindInClaudeJSON scans .claude.json for per-project approvals of .mcp.json
+// servers that the project's .mcp.json no longer defines. Projects whose
+// directory no longer exists are skipped.
+func FindInClaudeJSON(claudeJSONPath string) ([]Ref, error) {
+	projects, err := readProjects(claudeJSONPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Ref
+	for dir, p := range projects {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		defined := mcpJSONServers(dir)
+		for key, list := range map[string][]string{
+			"enabledMcpjsonServers":  p.EnabledMcpjsonServers,
+			"disabledMcpjsonServers": p.DisabledMcpjsonServers,
+		} {
+			for _, name := range list {
+				if !defined[name] {
+					refs = append(refs, Ref{File: claudeJSONPath, Project: dir, Key: key, Value: name, Reason: "not defined in the project's .mcp.json"})
+				}
+			}
+		}
+	}
+
+	sortRefs(refs)
+	return refs, nil
+}
+
+// Remove deletes refs from their files, merging with any changes Claude Code
+// makes meanwhile. What each file held before is kept in <file>.bak.
+// Returns the backup paths.
+func Remove(refs []Ref) ([]string, error) {
+	byFile := make(map[string][]Ref)
+	var files []string
+	for _, r := range refs {
+		if _, ok := byFile[r.File]; !ok {
+			files = append(files, r.File)
+		}
+		byFile[r.File] = append(byFile[r.File], r)
+	}
+	sort.Strings(files)
+
+	var backups []string
+	for _, file := range files {
+		backup, err := removeFromFile(file, byFile[file])
+		if err != nil {
+			return backups, fmt.Errorf("failed to update %s: %w", file, err)
+		}
+		backups = append(backups, backup)
+	}
+	return backups, nil
+}
+
+func removeFromFile(path string, refs []Ref) (string, error) {
+	original, err := state.EditJSON(path, func(top map[string]json.RawMessage) error {
+		for _, r := range refs {
+			var err error
+			switch {
+			case r.Project != "":
+				err = editObject(top, "projects", func(projects map[string]json.RawMessage) error {
+					return editObject(projects, r.Project, func(project map[string]json.RawMessage) error {
+						return removeFromList(project, r.Key, r.Value)
+					})
+				})
+			case r.Key == "enabledPlugins":
+				err = editObject(top, "enabledPlugins", func(enabled map[string]json.RawMessage) error {
+					delete(enabled, r.Value)
+					return nil
+				})
+			case strings.HasPrefix(r.Key, "permissions."):
+				err = editObject(top, "permissions", func(permissions map[string]json.RawMessage) error {
+					return removeFromList(permissions, strings.TrimPrefix(r.Key, "permissions."), r.Value)
+				})
+			default:
+				err = fmt.Errorf("unsupported key %s", r.Key)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	backup := path + ".bak"
+	return backup, os.WriteFile(backup, original, info.Mode().Perm())
+}
+
+// editObject decodes obj[key] as a JSON object, applies fn, and stores it back
+func editObject(obj map[string]json.RawMessage, key string, fn func(map[string]json.RawMessage) error) error {
+	var inner map[string]json.RawMessage
+	if err := json.Unmarshal(obj[key], &inner); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if err := fn(inner); err != nil {
+		return err
+	}
+	data, err := json.Marshal(inner)
+	if err != nil {
+		return err
+	}
+	obj[key] = data
+	return nil
+}
+
+func removeFromList(obj map[string]json.RawMessage, key, value string) error {
+	var list []string
+	if err := json.Unmarshal(obj[key], &list); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	kept := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != value {
+			kept = append(kept, item)
+		}
+	}
+	data, err := json.Marshal(kept)
+	if err != nil {
+		return err
+	}
+	obj[key] = data
+	return nil
+}
+
+func readObject(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func readProjects(claudeJSONPath string) (map[string]projectEntry, error) {
+	top, err := readObject(claudeJSONPath)
+	if err != nil {
+		return nil, err
+	}
+	projects := make(map[string]projectEntry)
+	if raw, ok := top["projects"]; ok {
+		if err := json.Unmarshal(raw, &projects); err != nil {
+			return nil, fmt.Errorf("projects: %w", err)
+		}
+	}
+	return projects, nil
+}
+
+func mcpJSONServers(dir string) map[string]bool {
+	names := make(map[string]bool)
+	data, err := os.ReadFile(filepath.Join(dir, ".mcp.json"))
+	if err != nil {
+		return names
+	}
+	var file struct {
+		MCPServers map[string]json.RawMessage `json:"mcpServers"`
+	}
+	if json.Unmarshal(data, &file) == nil {
+		for name := range file.MCPServers {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+func sortRefs(refs []Ref) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Project != refs[j].Project {
+			return refs[i].Project < refs[j].Project
+		}
+		if refs[i].Key != refs[j].Key {
+			return refs[i].Key < refs[j].Key
+		}
+		return refs[i].Value < refs[j].Value
+	})
+}
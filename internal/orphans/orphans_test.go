@@ -0,0 +1,138 @@
+// ABOUTME: Unit tests for finding and removing dangling config entries
+// ABOUTME: Covers enabledPlugins, MCP permission rules, and per-project .mcp.json approvals
+package orphans
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindAndRemoveInSettings(t *testing.T) {
+	settings := filepath.Join(t.TempDir(), "settings.json")
+	writeFile(t, settings, `{
+  "model": "opus",
+  "enabledPlugins": {"kept@m": true, "gone@m": true},
+  "permissions": {
+    "allow": ["Bash(go test:*)", "mcp__github__create_issue", "mcp__gone", "mcp__plugin_x_y__tool"],
+    "deny": ["mcp__gone__drop"]
+  }
+}`)
+
+	known := Known{
+		Plugins:    map[string]bool{"kept@m": true},
+		MCPServers: map[string]bool{"github": true},
+	}
+	refs, err := FindInSettings(settings, known)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, r := range refs {
+		got = append(got, r.Key+"="+r.Value)
+	}
+	want := []string{"enabledPlugins=gone@m", "permissions.allow=mcp__gone", "permissions.deny=mcp__gone__drop"}
+	if len(got) != len(want) {
+		t.Fatalf("Got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Ref %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	backups, err := Remove(refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Errorf("Expected one backup, got %v", backups)
+	}
+
+	var result struct {
+		Model          string          `json:"model"`
+		EnabledPlugins map[string]bool `json:"enabledPlugins"`
+		Permissions    struct {
+			Allow []string `json:"allow"`
+			Deny  []string `json:"deny"`
+		} `json:"permissions"`
+	}
+	data, _ := os.ReadFile(settings)
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Model != "opus" || len(result.EnabledPlugins) != 1 || !result.EnabledPlugins["kept@m"] {
+		t.Errorf("Unexpected settings after removal: %s", data)
+	}
+	if len(result.Permissions.Allow) != 3 || len(result.Permissions.Deny) != 0 {
+		t.Errorf("Unexpected permissions after removal: %+v", result.Permissions)
+	}
+}
+
+func TestFindInClaudeJSON(t *testing.T) {
+	tmp := t.TempDir()
+	project := filepath.Join(tmp, "project")
+	writeFile(t, filepath.Join(project, ".mcp.json"), `{"mcpServers": {"db": {"command": "pg"}}}`)
+
+	claudeJSON := filepath.Join(tmp, ".claude.json")
+	data, _ := json.Marshal(map[string]interface{}{
+		"numStartups": 42,
+		"projects": map[string]interface{}{
+			project: map[string]interface{}{
+				"enabledMcpjsonServers":  []string{"db", "old"},
+				"disabledMcpjsonServers": []string{},
+				"mcpServers":             map[string]interface{}{"local": map[string]string{"command": "x"}},
+			},
+			filepath.Join(tmp, "deleted"): map[string]interface{}{
+				"enabledMcpjsonServers": []string{"whatever"},
+			},
+		},
+	})
+	writeFile(t, claudeJSON, string(data))
+
+	refs, err := FindInClaudeJSON(claudeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].Value != "old" || refs[0].Project != project {
+		t.Fatalf("Unexpected refs %+v", refs)
+	}
+
+	servers, err := ProjectServers(claudeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !servers["db"] || !servers["local"] {
+		t.Errorf("Expected project servers db and local, got %v", servers)
+	}
+
+	if _, err := Remove(refs); err != nil {
+		t.Fatal(err)
+	}
+	refs, err = FindInClaudeJSON(claudeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 0 {
+		t.Errorf("Expected no refs after removal, got %+v", refs)
+	}
+
+	var after map[string]json.RawMessage
+	data, _ = os.ReadFile(claudeJSON)
+	json.Unmarshal(data, &after)
+	if string(after["numStartups"]) != "42" {
+		t.Errorf("Unrelated keys must be preserved, got %s", data)
+	}
+}
@@ -0,0 +1,109 @@
+// ABOUTME: The directories claudeup works in, resolved in one place and carried on the command context
+// ABOUTME: Tests inject a Paths to redirect HOME, the Claude directory, and ~/.claudeup together
+package pathctx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Paths are the directories and files claudeup reads and writes
+type Paths struct {
+	Home        string // the user's home directory
+	ClaudeDir   string // Claude Code's configuration: CLAUDE_CONFIG_DIR or ~/.claude
+	ClaudeJSON  string // Claude Code's .claude.json
+	ClaudeupDir string // claudeup's own files: ~/.claudeup
+	ProfilesDir string // saved profiles: ~/.claudeup/profiles
+}
+
+// ForHome lays the paths out under home the way Claude Code and claudeup
+// do. CLAUDE_CONFIG_DIR, when set, moves the Claude directory and puts
+// .claude.json inside it.
+func ForHome(home string) Paths {
+	p := Paths{
+		Home:        home,
+		ClaudeDir:   filepath.Join(home, ".claude"),
+		ClaudeJSON:  filepath.Join(home, ".claude.json"),
+		ClaudeupDir: filepath.Join(home, ".claudeup"),
+	}
+	if override := os.Getenv("CLAUDE_CONFIG_DIR"); override != "" {
+		p.ClaudeDir = override
+		p.ClaudeJSON = filepath.Join(override, ".claude.json")
+	}
+	p.ProfilesDir = filepath.Join(p.ClaudeupDir, "profiles")
+	return p
+}
+
+// Claudeup returns a path inside the claudeup directory
+func (p Paths) Claudeup(elem ...string) string {
+	return filepath.Join(append([]string{p.ClaudeupDir}, elem...)...)
+}
+
+// HomeRelative reports whether path starts with the ~ that ExpandHome
+// expands: "~" alone or followed by a separator. "~user" isn't.
+func HomeRelative(path string) bool {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return true
+	}
+	return filepath.Separator == '\\' && strings.HasPrefix(path, `~\`)
+}
+
+// ExpandHome replaces a leading ~ in path with home. Paths that aren't
+// HomeRelative, and every path when home is unknown, are returned as is.
+func ExpandHome(path, home string) string {
+	if home == "" || !HomeRelative(path) {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+var (
+	mu       sync.Mutex
+	override *Paths
+)
+
+// Default returns the paths set by Override, or else the layout under the
+// user's home directory. Home is empty if it can't be determined.
+func Default() Paths {
+	mu.Lock()
+	defer mu.Unlock()
+	if override != nil {
+		return *override
+	}
+	home, _ := os.UserHomeDir()
+	return ForHome(home)
+}
+
+// Override makes p the Default for code that has no command context to
+// read it from, until restore is called
+func Override(p Paths) (restore func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	previous := override
+	override = &p
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		override = previous
+	}
+}
+
+type pathsKey struct{}
+
+// With returns a context that carries p
+func With(ctx context.Context, p Paths) context.Context {
+	return context.WithValue(ctx, pathsKey{}, p)
+}
+
+// From returns the Paths carried by ctx, or Default
+func From(ctx context.Context) Paths {
+	if ctx != nil {
+		if p, ok := ctx.Value(pathsKey{}).(Paths); ok {
+			return p
+		}
+	}
+	return Default()
+}
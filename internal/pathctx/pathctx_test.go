@@ -0,0 +1,84 @@
+// ABOUTME: Tests for resolving and carrying claudeup's directories
+// ABOUTME: Covers the home layout, CLAUDE_CONFIG_DIR, context injection, and overrides
+package pathctx
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestForHome(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	home := filepath.FromSlash("/home/me")
+	p := ForHome(home)
+	want := Paths{
+		Home:        home,
+		ClaudeDir:   filepath.Join(home, ".claude"),
+		ClaudeJSON:  filepath.Join(home, ".claude.json"),
+		ClaudeupDir: filepath.Join(home, ".claudeup"),
+		ProfilesDir: filepath.Join(home, ".claudeup", "profiles"),
+	}
+	if p != want {
+		t.Errorf("Expected %+v, got %+v", want, p)
+	}
+	if got := p.Claudeup("history.jsonl"); got != filepath.Join(home, ".claudeup", "history.jsonl") {
+		t.Errorf("Unexpected claudeup path %q", got)
+	}
+
+	override := filepath.FromSlash("/opt/claude")
+	t.Setenv("CLAUDE_CONFIG_DIR", override)
+	p = ForHome(home)
+	if p.ClaudeDir != override || p.ClaudeJSON != filepath.Join(override, ".claude.json") || p.ClaudeupDir != want.ClaudeupDir {
+		t.Errorf("Expected CLAUDE_CONFIG_DIR to move only Claude's files, got %+v", p)
+	}
+}
+
+func TestFromAndOverride(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+
+	if got := From(context.Background()); got.Home != home {
+		t.Errorf("Expected the default layout without injected paths, got %+v", got)
+	}
+	injected := ForHome("/injected")
+	if got := From(With(context.Background(), injected)); got != injected {
+		t.Errorf("Expected the injected paths, got %+v", got)
+	}
+
+	restore := Override(injected)
+	if got := Default(); got != injected {
+		t.Errorf("Expected the override as the default, got %+v", got)
+	}
+	restore()
+	if got := Default(); got.Home != home {
+		t.Errorf("Expected the override undone, got %+v", got)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := filepath.FromSlash("/home/me")
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"~", home},
+		{"~/work", filepath.Join(home, "work")},
+		{"~user/work", "~user/work"},
+		{"/srv/work", "/srv/work"},
+		{"work/~", "work/~"},
+	}
+	if filepath.Separator == '\\' {
+		tests = append(tests, struct{ path, want string }{`~\work`, filepath.Join(home, "work")})
+	}
+	for _, tt := range tests {
+		if got := ExpandHome(tt.path, home); got != tt.want {
+			t.Errorf("ExpandHome(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	if got := ExpandHome("~/work", ""); got != "~/work" {
+		t.Errorf("Expected ~ kept without a home directory, got %q", got)
+	}
+}
@@ -0,0 +1,253 @@
+// ABOUTME: Detects the host's OS, CPU architecture, and C library, and reads the same from a binary
+// ABOUTME: Lets doctor, claude install, and the sandbox notice builds meant for another platform
+package platform
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// C libraries a Linux binary can be linked against
+const (
+	LibcGlibc = "glibc"
+	LibcMusl  = "musl"
+)
+
+// ErrNotBinary is returned by ReadBinary for scripts and other files that
+// aren't executables, like an npm shim
+var ErrNotBinary = errors.New("not an executable binary")
+
+// Platform is an OS, a CPU architecture in Go's naming, and on Linux the C
+// library programs are linked against
+type Platform struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	Libc string `json:"libc,omitempty"`
+	// Translated is set when claudeup itself runs under Rosetta, so Arch
+	// (the hardware's) differs from the build's
+	Translated bool `json:"translated,omitempty"`
+}
+
+// String formats the platform as os/arch, with a non-glibc libc appended
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Arch
+	if p.Libc == LibcMusl {
+		s += " (musl)"
+	}
+	return s
+}
+
+// Host returns the machine claudeup runs on. Arch is the hardware's, so an
+// amd64 claudeup under Rosetta on Apple silicon reports arm64.
+func Host() Platform {
+	p := Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "amd64" && rosetta() {
+		p.Arch = "arm64"
+		p.Translated = true
+	}
+	if runtime.GOOS == "linux" {
+		p.Libc = LibcGlibc
+		if musl() {
+			p.Libc = LibcMusl
+		}
+	}
+	return p
+}
+
+// rosetta reports whether this process is being translated by Rosetta
+func rosetta() bool {
+	out, err := exec.Command("sysctl", "-n", "sysctl.proc_translated").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "1"
+}
+
+// muslPatterns match the musl loader and libc that Alpine and other musl
+// distributions install
+var muslPatterns = []string{"/lib/ld-musl-*.so.1", "/lib/libc.musl-*.so.1"}
+
+// musl reports whether this Linux system uses musl, like Alpine
+func musl() bool {
+	for _, pattern := range muslPatterns {
+		if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Binary is the platform an executable was built for
+type Binary struct {
+	OS string `json:"os"`
+	// Archs has more than one entry for a universal macOS binary
+	Archs []string `json:"archs"`
+	// Libc is the C library a dynamically linked Linux binary loads; it's
+	// empty for a static one, which runs with either
+	Libc string `json:"libc,omitempty"`
+}
+
+// String formats the binary's platform like Platform.String, joining the
+// architectures of a universal binary with +
+func (b Binary) String() string {
+	s := b.OS + "/" + strings.Join(b.Archs, "+")
+	if b.Libc != "" {
+		s += " (" + b.Libc + ")"
+	}
+	return s
+}
+
+// Mismatch says why b won't run natively on host, or returns "" when it
+// will. Apple silicon and Windows on Arm run amd64 builds under emulation,
+// which is slower but works; elsewhere a mismatch means the binary won't
+// start.
+func (b Binary) Mismatch(host Platform) string {
+	if b.OS != host.OS {
+		return fmt.Sprintf("built for %s, but this machine runs %s", b.OS, host.OS)
+	}
+	if !slices.Contains(b.Archs, host.Arch) {
+		return fmt.Sprintf("built for %s, but this machine is %s", strings.Join(b.Archs, "+"), host.Arch)
+	}
+	if b.Libc != "" && host.Libc != "" && b.Libc != host.Libc {
+		return fmt.Sprintf("linked against %s, but this system uses %s", b.Libc, host.Libc)
+	}
+	return ""
+}
+
+// Emulated reports whether host can still run b, under Rosetta or Windows'
+// x64 emulation, despite an architecture mismatch
+func (b Binary) Emulated(host Platform) bool {
+	return host.Arch == "arm64" && (host.OS == "darwin" || host.OS == "windows") &&
+		b.OS == host.OS && slices.Contains(b.Archs, "amd64") && !slices.Contains(b.Archs, "arm64")
+}
+
+// ReadBinary reads the platform an ELF, Mach-O, or PE executable was built
+// for. Symlinks are followed.
+func ReadBinary(path string) (Binary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Binary{}, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return Binary{}, ErrNotBinary
+	}
+	switch {
+	case bytes.Equal(magic, []byte(elf.ELFMAG)):
+		return readELF(f)
+	case bytes.HasPrefix(magic, []byte("MZ")):
+		return readPE(f)
+	case isMachO(magic):
+		return readMachO(f)
+	}
+	return Binary{}, ErrNotBinary
+}
+
+func isMachO(magic []byte) bool {
+	for _, m := range []uint32{macho.Magic32, macho.Magic64, macho.MagicFat} {
+		be := []byte{byte(m >> 24), byte(m >> 16), byte(m >> 8), byte(m)}
+		le := []byte{be[3], be[2], be[1], be[0]}
+		if bytes.Equal(magic, be) || bytes.Equal(magic, le) {
+			return true
+		}
+	}
+	return false
+}
+
+func readELF(r io.ReaderAt) (Binary, error) {
+	f, err := elf.NewFile(r)
+	if err != nil {
+		return Binary{}, fmt.Errorf("invalid ELF binary: %w", err)
+	}
+	b := Binary{OS: "linux", Archs: []string{elfArch(f.Machine)}}
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		interp, err := io.ReadAll(prog.Open())
+		if err != nil {
+			return Binary{}, fmt.Errorf("invalid ELF interpreter: %w", err)
+		}
+		b.Libc = LibcGlibc
+		if strings.Contains(string(interp), "ld-musl") {
+			b.Libc = LibcMusl
+		}
+	}
+	return b, nil
+}
+
+func elfArch(m elf.Machine) string {
+	switch m {
+	case elf.EM_X86_64:
+		return "amd64"
+	case elf.EM_AARCH64:
+		return "arm64"
+	case elf.EM_386:
+		return "386"
+	case elf.EM_ARM:
+		return "arm"
+	}
+	return strings.ToLower(strings.TrimPrefix(m.String(), "EM_"))
+}
+
+func readMachO(r io.ReaderAt) (Binary, error) {
+	b := Binary{OS: "darwin"}
+	fat, err := macho.NewFatFile(r)
+	if err == nil {
+		for _, a := range fat.Arches {
+			b.Archs = append(b.Archs, machoArch(a.Cpu))
+		}
+		return b, nil
+	}
+	if !errors.Is(err, macho.ErrNotFat) {
+		return Binary{}, fmt.Errorf("invalid Mach-O binary: %w", err)
+	}
+	f, err := macho.NewFile(r)
+	if err != nil {
+		return Binary{}, fmt.Errorf("invalid Mach-O binary: %w", err)
+	}
+	b.Archs = []string{machoArch(f.Cpu)}
+	return b, nil
+}
+
+func machoArch(c macho.Cpu) string {
+	switch c {
+	case macho.CpuAmd64:
+		return "amd64"
+	case macho.CpuArm64:
+		return "arm64"
+	case macho.Cpu386:
+		return "386"
+	case macho.CpuArm:
+		return "arm"
+	}
+	return strings.ToLower(strings.TrimPrefix(c.String(), "Cpu"))
+}
+
+func readPE(r io.ReaderAt) (Binary, error) {
+	f, err := pe.NewFile(r)
+	if err != nil {
+		// MZ alone isn't much of a signature
+		return Binary{}, ErrNotBinary
+	}
+	arch := fmt.Sprintf("0x%x", f.Machine)
+	switch f.Machine {
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		arch = "amd64"
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		arch = "arm64"
+	case pe.IMAGE_FILE_MACHINE_I386:
+		arch = "386"
+	}
+	return Binary{OS: "windows", Archs: []string{arch}}, nil
+}
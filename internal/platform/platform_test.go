@@ -0,0 +1,103 @@
+// ABOUTME: Tests for host platform detection and reading a binary's platform
+// ABOUTME: Reads the test binary itself and compares made-up binaries against made-up hosts
+package platform
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestReadBinaryOfTestExecutable(t *testing.T) {
+	b, err := ReadBinary(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.OS != runtime.GOOS || !slices.Contains(b.Archs, runtime.GOARCH) {
+		t.Errorf("Expected %s/%s, got %s", runtime.GOOS, runtime.GOARCH, b)
+	}
+	if host := Host(); !host.Translated {
+		if reason := b.Mismatch(host); reason != "" {
+			t.Errorf("Expected the test binary to suit %s, got %q", host, reason)
+		}
+	}
+}
+
+func TestReadBinaryRefusesScripts(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"shim":  "#!/usr/bin/env node\nrequire('./cli.js')\n",
+		"short": "MZ",
+		"empty": "",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadBinary(path); !errors.Is(err, ErrNotBinary) {
+			t.Errorf("%s: expected ErrNotBinary, got %v", name, err)
+		}
+	}
+}
+
+func TestMismatch(t *testing.T) {
+	glibcARM := Platform{OS: "linux", Arch: "arm64", Libc: LibcGlibc}
+	alpine := Platform{OS: "linux", Arch: "amd64", Libc: LibcMusl}
+	appleSilicon := Platform{OS: "darwin", Arch: "arm64"}
+
+	tests := []struct {
+		name     string
+		binary   Binary
+		host     Platform
+		want     string
+		emulated bool
+	}{
+		{"native", Binary{OS: "linux", Archs: []string{"arm64"}, Libc: LibcGlibc}, glibcARM, "", false},
+		{"static on musl", Binary{OS: "linux", Archs: []string{"amd64"}}, alpine, "", false},
+		{"wrong arch", Binary{OS: "linux", Archs: []string{"amd64"}, Libc: LibcGlibc}, glibcARM, "built for amd64, but this machine is arm64", false},
+		{"glibc on musl", Binary{OS: "linux", Archs: []string{"amd64"}, Libc: LibcGlibc}, alpine, "linked against glibc, but this system uses musl", false},
+		{"universal", Binary{OS: "darwin", Archs: []string{"amd64", "arm64"}}, appleSilicon, "", false},
+		{"rosetta", Binary{OS: "darwin", Archs: []string{"amd64"}}, appleSilicon, "built for amd64, but this machine is arm64", true},
+		{"wrong os", Binary{OS: "linux", Archs: []string{"arm64"}}, appleSilicon, "built for linux, but this machine runs darwin", false},
+	}
+	for _, tt := range tests {
+		if got := tt.binary.Mismatch(tt.host); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+		if got := tt.binary.Emulated(tt.host); got != tt.emulated {
+			t.Errorf("%s: expected emulated %v, got %v", tt.name, tt.emulated, got)
+		}
+	}
+}
+
+func TestMuslDetection(t *testing.T) {
+	dir := t.TempDir()
+	saved := muslPatterns
+	t.Cleanup(func() { muslPatterns = saved })
+
+	muslPatterns = []string{filepath.Join(dir, "ld-musl-*.so.1")}
+	if musl() {
+		t.Error("Expected no musl without its loader")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ld-musl-aarch64.so.1"), nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !musl() {
+		t.Error("Expected the musl loader to be found")
+	}
+}
+
+func TestPlatformString(t *testing.T) {
+	if s := (Platform{OS: "linux", Arch: "arm64", Libc: LibcMusl}).String(); s != "linux/arm64 (musl)" {
+		t.Errorf("Unexpected %q", s)
+	}
+	if s := (Platform{OS: "linux", Arch: "amd64", Libc: LibcGlibc}).String(); s != "linux/amd64" {
+		t.Errorf("Unexpected %q", s)
+	}
+	if s := (Binary{OS: "darwin", Archs: []string{"amd64", "arm64"}}).String(); s != "darwin/amd64+arm64" {
+		t.Errorf("Unexpected %q", s)
+	}
+}
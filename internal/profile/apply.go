@@ -3,32 +3,139 @@
 package profile
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/claudeup/claudeup/internal/cmdline"
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
 	"github.com/claudeup/claudeup/internal/secrets"
+	"github.com/claudeup/claudeup/internal/telemetry"
+	"github.com/claudeup/claudeup/internal/ui"
 )
 
-// CommandExecutor runs claude CLI commands
+// CommandExecutor runs claude CLI commands. Cancelling ctx stops a running
+// command.
 type CommandExecutor interface {
-	Run(args ...string) error
-	RunWithOutput(args ...string) (string, error)
+	Run(ctx context.Context, args ...string) error
+	RunWithOutput(ctx context.Context, args ...string) (string, error)
 }
 
 // DefaultExecutor runs commands using the real claude CLI
 type DefaultExecutor struct{}
 
 // Run executes the claude CLI with the given arguments
-func (e *DefaultExecutor) Run(args ...string) error {
-	return runClaude(args...)
+func (e *DefaultExecutor) Run(ctx context.Context, args ...string) error {
+	return runClaude(ctx, args...)
 }
 
 // RunWithOutput executes the claude CLI and returns captured output
-func (e *DefaultExecutor) RunWithOutput(args ...string) (string, error) {
-	return runClaudeWithOutput(args...)
+func (e *DefaultExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	return runClaudeWithOutput(ctx, args...)
+}
+
+// CapturingExecutor runs the real claude CLI without attaching it to the
+// terminal, for callers that own stdin/stdout (e.g. the MCP server)
+type CapturingExecutor struct{}
+
+// Run executes the claude CLI, folding its output into any error
+func (e *CapturingExecutor) Run(ctx context.Context, args ...string) error {
+	output, err := runClaudeWithOutput(ctx, args...)
+	if err != nil && strings.TrimSpace(output) != "" {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(output))
+	}
+	return err
+}
+
+// RunWithOutput executes the claude CLI and returns captured output
+func (e *CapturingExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	return runClaudeWithOutput(ctx, args...)
+}
+
+// tracingExecutor prints each claude command line before running it,
+// hiding resolved secret values
+type tracingExecutor struct {
+	CommandExecutor
+	hide []string
+}
+
+// traceCommands wraps executor so verbose output shows the commands it
+// runs, and an apply's trace has a span for each. Values in resolved are
+// hidden from the printed command lines and spans, and from the recording
+// when executor is a Recorder.
+func traceCommands(ctx context.Context, executor CommandExecutor, resolved ...map[string]string) CommandExecutor {
+	var hide []string
+	for _, values := range resolved {
+		for _, value := range values {
+			hide = append(hide, value)
+		}
+	}
+	if r, ok := executor.(*Recorder); ok {
+		r.hideValues(hide)
+	}
+	if telemetry.SpanFrom(ctx) != nil {
+		executor = &spanExecutor{CommandExecutor: executor, hide: hide}
+	}
+	if !ui.PrinterFrom(ctx).Verbose() {
+		return executor
+	}
+	return &tracingExecutor{CommandExecutor: executor, hide: hide}
+}
+
+func (e *tracingExecutor) Run(ctx context.Context, args ...string) error {
+	ui.PrinterFrom(ctx).Verbosef("  → %s\n", ui.CommandLine("claude", args, e.hide...))
+	return e.CommandExecutor.Run(ctx, args...)
+}
+
+func (e *tracingExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	ui.PrinterFrom(ctx).Verbosef("  → %s\n", ui.CommandLine("claude", args, e.hide...))
+	return e.CommandExecutor.RunWithOutput(ctx, args...)
+}
+
+// spanExecutor records each claude command as a span of the trace in its
+// context, hiding resolved secret values
+type spanExecutor struct {
+	CommandExecutor
+	hide []string
+}
+
+func (e *spanExecutor) Run(ctx context.Context, args ...string) error {
+	ctx, span := e.start(ctx, args)
+	err := e.CommandExecutor.Run(ctx, args...)
+	span.End(err)
+	return err
+}
+
+func (e *spanExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	ctx, span := e.start(ctx, args)
+	output, err := e.CommandExecutor.RunWithOutput(ctx, args...)
+	span.End(err)
+	return output, err
+}
+
+func (e *spanExecutor) start(ctx context.Context, args []string) (context.Context, *telemetry.Span) {
+	return telemetry.Start(ctx, commandName(args), telemetry.String("claude.command", ui.CommandLine("claude", args, e.hide...)))
+}
+
+// commandName names a claude command by its subcommands, such as
+// "claude plugin marketplace add", leaving out what it acts on
+func commandName(args []string) string {
+	name := "claude"
+	for _, arg := range args {
+		name += " " + arg
+		if arg != "plugin" && arg != "marketplace" && arg != "mcp" {
+			break
+		}
+	}
+	return name
 }
 
 // ApplyResult contains the results of applying a profile
@@ -40,34 +147,85 @@ type ApplyResult struct {
 	MCPServersRemoved     []string
 	MCPServersInstalled   []string
 	MarketplacesAdded     []string
+	PluginsDisabled       []string
+	PluginsEnabled        []string
+	MCPServersDisabled    []string
+	MCPServersEnabled     []string
+	PluginsPurged         []string    // Removed plugins whose cached copy was deleted
+	ReclaimedBytes        int64       // Disk space freed by purging
+	Skipped               []Subsystem // Subsystems left untouched by --only/--skip
+	UnresolvedSecrets     []UnresolvedSecret
+	Steps                 []ApplyStep   // Every attempted change, in order
+	Elapsed               time.Duration // Wall time of the whole apply
 	Errors                []error
 }
 
+// UnresolvedSecret is an optional secret an MCP server was installed without
+type UnresolvedSecret struct {
+	Server MCPServer
+	EnvVar string
+}
+
 // Diff represents what needs to change to apply a profile
 type Diff struct {
-	PluginsToRemove  []string
-	PluginsToInstall []string
-	MCPToRemove      []string
-	MCPToInstall     []MCPServer
+	PluginsToRemove   []string
+	PluginsToInstall  []string
+	MCPToRemove       []string
+	MCPToInstall      []MCPServer
 	MarketplacesToAdd []Marketplace
+	PluginsToDisable  []string
+	PluginsToEnable   []string
+	MCPToDisable      []string
+	MCPToEnable       []string
+
+	// Skipped lists the entries left out because this machine doesn't meet
+	// their conditions. They aren't changes.
+	Skipped []Skipped
+
+	// InstalledPlugins lists the plugins recorded as installed when the
+	// diff was computed. Only their uninstalls count a "plugin not found"
+	// from the claude CLI as already done.
+	InstalledPlugins []string
+}
+
+// Count returns the total number of changes in the diff
+func (d *Diff) Count() int {
+	return len(d.PluginsToRemove) + len(d.PluginsToInstall) +
+		len(d.MCPToRemove) + len(d.MCPToInstall) + len(d.MarketplacesToAdd) +
+		len(d.PluginsToDisable) + len(d.PluginsToEnable) +
+		len(d.MCPToDisable) + len(d.MCPToEnable)
 }
 
 // ComputeDiff calculates what changes are needed to apply a profile
 func ComputeDiff(profile *Profile, claudeDir, claudeJSONPath string) (*Diff, error) {
+	overrides, err := LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
+	profile = profile.ForMachine(ThisMachine()).WithOverrides(overrides)
 	current, err := Snapshot("current", claudeDir, claudeJSONPath)
 	if err != nil {
 		// If we can't read current state, treat as empty
 		current = &Profile{}
 	}
 
-	diff := &Diff{}
+	diff := &Diff{Skipped: profile.SkippedOn(ThisMachine()), InstalledPlugins: current.Plugins}
 
 	// Plugins to remove (in current but not in profile)
 	currentPlugins := toSet(current.Plugins)
-	profilePlugins := toSet(profile.Plugins)
+	profilePlugins := toSet(appendMissing(append([]string(nil), profile.Plugins...), profile.Disabled.Plugins...))
+	currentDisabled := toSet(current.Disabled.Plugins)
+	profileDisabled := toSet(profile.Disabled.Plugins)
+
+	// Addons only add items, so nothing is ever removed
+	addOnly := profile.IsAddon()
 
 	for plugin := range currentPlugins {
-		if _, exists := profilePlugins[plugin]; !exists {
+		// Disabled plugins aren't in Claude's registry, so there's nothing to uninstall
+		if _, disabled := currentDisabled[plugin]; disabled {
+			continue
+		}
+		if _, exists := profilePlugins[plugin]; !exists && !addOnly {
 			diff.PluginsToRemove = append(diff.PluginsToRemove, plugin)
 		}
 	}
@@ -75,11 +233,18 @@ func ComputeDiff(profile *Profile, claudeDir, claudeJSONPath string) (*Diff, err
 	// Plugins to install - always include ALL profile plugins to ensure
 	// they're properly registered with Claude CLI, even if they appear
 	// in the current state (they may be in a broken state where JSON
-	// shows them but Claude CLI doesn't recognize them)
+	// shows them but Claude CLI doesn't recognize them). Plugins that
+	// are already disabled are left alone unless they'll be re-enabled.
 	for plugin := range profilePlugins {
-		diff.PluginsToInstall = append(diff.PluginsToInstall, plugin)
+		_, wasDisabled := currentDisabled[plugin]
+		_, staysDisabled := profileDisabled[plugin]
+		if !wasDisabled || (!staysDisabled && !addOnly) {
+			diff.PluginsToInstall = append(diff.PluginsToInstall, plugin)
+		}
 	}
 
+	diffDisabled(diff, current, profile, profilePlugins)
+
 	// MCP servers to remove/install
 	currentMCP := make(map[string]bool)
 	for _, mcp := range current.MCPServers {
@@ -92,7 +257,7 @@ func ComputeDiff(profile *Profile, claudeDir, claudeJSONPath string) (*Diff, err
 	}
 
 	for name := range currentMCP {
-		if _, exists := profileMCP[name]; !exists {
+		if _, exists := profileMCP[name]; !exists && !addOnly {
 			diff.MCPToRemove = append(diff.MCPToRemove, name)
 		}
 	}
@@ -103,14 +268,20 @@ func ComputeDiff(profile *Profile, claudeDir, claudeJSONPath string) (*Diff, err
 		}
 	}
 
-	// Marketplaces to add (we don't remove marketplaces - just add missing ones)
+	// Marketplaces to add (we don't remove marketplaces - just add missing ones).
+	// Configured URL rewrites point them at a mirror first.
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
 	currentMarketplaces := make(map[string]bool)
 	for _, m := range current.Marketplaces {
-		currentMarketplaces[m.Repo] = true
+		currentMarketplaces[m.DisplayName()] = true
 	}
 
 	for _, m := range profile.Marketplaces {
-		if !currentMarketplaces[m.Repo] {
+		m = m.Mirrored(cfg).withAbsPath()
+		if !currentMarketplaces[m.DisplayName()] {
 			diff.MarketplacesToAdd = append(diff.MarketplacesToAdd, m)
 		}
 	}
@@ -119,117 +290,272 @@ func ComputeDiff(profile *Profile, claudeDir, claudeJSONPath string) (*Diff, err
 }
 
 // Apply executes the profile changes using the default executor
-func Apply(profile *Profile, claudeDir, claudeJSONPath string, secretChain *secrets.Chain) (*ApplyResult, error) {
-	return ApplyWithExecutor(profile, claudeDir, claudeJSONPath, secretChain, &DefaultExecutor{})
+func Apply(ctx context.Context, profile *Profile, claudeDir, claudeJSONPath string, secretChain *secrets.Chain) (*ApplyResult, error) {
+	return ApplyWithExecutor(ctx, profile, claudeDir, claudeJSONPath, secretChain, &DefaultExecutor{})
 }
 
 // ApplyWithExecutor executes the profile changes using the provided executor
-func ApplyWithExecutor(profile *Profile, claudeDir, claudeJSONPath string, secretChain *secrets.Chain, executor CommandExecutor) (*ApplyResult, error) {
+func ApplyWithExecutor(ctx context.Context, profile *Profile, claudeDir, claudeJSONPath string, secretChain *secrets.Chain, executor CommandExecutor) (*ApplyResult, error) {
+	return ApplySelected(ctx, profile, claudeDir, claudeJSONPath, secretChain, executor, nil)
+}
+
+// ApplySelected executes only the changes belonging to the selected
+// subsystems. A nil selection applies everything.
+func ApplySelected(ctx context.Context, profile *Profile, claudeDir, claudeJSONPath string, secretChain *secrets.Chain, executor CommandExecutor, selected Subsystems) (*ApplyResult, error) {
 	diff, err := ComputeDiff(profile, claudeDir, claudeJSONPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compute diff: %w", err)
 	}
+	result, err := ApplyPlanned(ctx, diff.Filter(selected), claudeDir, secretChain, executor)
+	if err != nil {
+		return result, err
+	}
+	result.Skipped = selected.Skipped()
+	return result, nil
+}
 
+// ApplyPlanned executes a diff the caller has already computed and trimmed,
+// including its disabled-state changes
+func ApplyPlanned(ctx context.Context, diff *Diff, claudeDir string, secretChain *secrets.Chain, executor CommandExecutor) (*ApplyResult, error) {
+	result, err := ApplyDiff(ctx, diff, secretChain, executor)
+	if err != nil {
+		return result, err
+	}
+	start := time.Now()
+	ApplyDisabledState(diff, claudeDir, result)
+	result.Elapsed += time.Since(start)
+	return result, nil
+}
+
+// ApplyDiff executes a previously computed diff. Callers may trim the diff
+// first, e.g. when plugins were already restored from another source.
+// If ctx is cancelled, ApplyDiff stops before the next change and returns
+// the changes that finished along with an error wrapping ctx.Err().
+func ApplyDiff(ctx context.Context, diff *Diff, secretChain *secrets.Chain, executor CommandExecutor) (*ApplyResult, error) {
 	result := &ApplyResult{}
+	defer func(start time.Time) { result.Elapsed = time.Since(start) }(time.Now())
 
 	// Resolve secrets for MCP servers before making any changes
 	resolvedMCP := make(map[string]map[string]string) // mcp name -> env var -> value
 	for _, mcp := range diff.MCPToInstall {
-		if len(mcp.Secrets) > 0 {
-			resolved := make(map[string]string)
-			for envVar, ref := range mcp.Secrets {
-				// Try each source in order
-				var value string
-				var resolveErr error
-				for _, source := range ref.Sources {
-					switch source.Type {
-					case "env":
-						value, _, resolveErr = secretChain.Resolve(source.Key)
-					case "1password":
-						value, _, resolveErr = secretChain.Resolve(source.Ref)
-					case "keychain":
-						keychainRef := source.Service
-						if source.Account != "" {
-							keychainRef = source.Service + ":" + source.Account
-						}
-						value, _, resolveErr = secretChain.Resolve(keychainRef)
-					}
-					if resolveErr == nil && value != "" {
-						break
-					}
-				}
-				if value == "" {
-					return nil, fmt.Errorf("could not resolve secret %s for MCP server %s", envVar, mcp.Name)
-				}
-				resolved[envVar] = value
-			}
-			resolvedMCP[mcp.Name] = resolved
+		resolved, unresolved, err := resolveMCPSecrets(ctx, mcp, secretChain, nil)
+		if err != nil {
+			return nil, err
+		}
+		resolvedMCP[mcp.Name] = resolved
+		for _, envVar := range unresolved {
+			result.UnresolvedSecrets = append(result.UnresolvedSecrets, UnresolvedSecret{Server: mcp, EnvVar: envVar})
 		}
 	}
+	executor = traceCommands(ctx, executor, slices.Collect(maps.Values(resolvedMCP))...)
 
 	// Remove plugins
 	for _, plugin := range diff.PluginsToRemove {
-		output, err := executor.RunWithOutput("plugin", "uninstall", plugin)
+		if err := ctx.Err(); err != nil {
+			return result, interrupted(err)
+		}
+		start := time.Now()
+		output, err := executor.RunWithOutput(ctx, "plugin", "uninstall", plugin)
 		if err != nil {
 			// Check if the error is just "already uninstalled" - treat as success
-			if strings.Contains(output, "already uninstalled") {
+			if IsAlreadyUninstalledOutput(output) || (IsPluginNotFoundOutput(output) && slices.Contains(diff.InstalledPlugins, plugin)) {
 				result.PluginsAlreadyRemoved = append(result.PluginsAlreadyRemoved, plugin)
+				result.recordOutcome("remove", SubsystemPlugins, plugin, start, StepAlready, nil)
 			} else {
-				result.Errors = append(result.Errors, fmt.Errorf("failed to uninstall plugin %s: %w (output: %s)", plugin, err, output))
+				result.record("remove", SubsystemPlugins, plugin, start, fmt.Errorf("failed to uninstall plugin %s: %w (output: %s)", plugin, err, output))
 			}
 		} else {
 			result.PluginsRemoved = append(result.PluginsRemoved, plugin)
+			result.record("remove", SubsystemPlugins, plugin, start, nil)
 		}
 	}
 
 	// Remove MCP servers
 	for _, mcp := range diff.MCPToRemove {
-		if err := executor.Run("mcp", "remove", mcp); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("failed to remove MCP server %s: %w", mcp, err))
+		if err := ctx.Err(); err != nil {
+			return result, interrupted(err)
+		}
+		start := time.Now()
+		if err := executor.Run(ctx, "mcp", "remove", mcp); err != nil {
+			result.record("remove", SubsystemMCP, mcp, start, fmt.Errorf("failed to remove MCP server %s: %w", mcp, err))
 		} else {
 			result.MCPServersRemoved = append(result.MCPServersRemoved, mcp)
+			result.record("remove", SubsystemMCP, mcp, start, nil)
 		}
 	}
 
 	// Add marketplaces
 	for _, m := range diff.MarketplacesToAdd {
-		if m.Repo != "" {
-			if err := executor.Run("plugin", "marketplace", "add", m.Repo); err != nil {
-				result.Errors = append(result.Errors, fmt.Errorf("failed to add marketplace %s: %w", m.Repo, err))
+		if err := ctx.Err(); err != nil {
+			return result, interrupted(err)
+		}
+		if source := m.DisplayName(); source != "" {
+			start := time.Now()
+			arg, err := marketplaceAddArg(ctx, m)
+			if err == nil {
+				err = executor.Run(ctx, "plugin", "marketplace", "add", arg)
+			}
+			if err != nil {
+				result.record("add", SubsystemMarketplaces, source, start, fmt.Errorf("failed to add marketplace %s: %w", source, err))
 			} else {
-				result.MarketplacesAdded = append(result.MarketplacesAdded, m.Repo)
+				result.MarketplacesAdded = append(result.MarketplacesAdded, source)
+				result.record("add", SubsystemMarketplaces, source, start, nil)
 			}
 		}
 	}
 
 	// Install plugins
 	for _, plugin := range diff.PluginsToInstall {
-		output, err := executor.RunWithOutput("plugin", "install", plugin)
+		if err := ctx.Err(); err != nil {
+			return result, interrupted(err)
+		}
+		start := time.Now()
+		output, err := executor.RunWithOutput(ctx, "plugin", "install", plugin)
 		if err != nil {
 			// Check if the error is just "already installed" - treat as success
-			if strings.Contains(output, "already installed") {
+			if IsAlreadyInstalledOutput(output) {
 				result.PluginsAlreadyPresent = append(result.PluginsAlreadyPresent, plugin)
+				result.recordOutcome("install", SubsystemPlugins, plugin, start, StepAlready, nil)
 			} else {
-				result.Errors = append(result.Errors, fmt.Errorf("failed to install plugin %s: %w (output: %s)", plugin, err, output))
+				result.record("install", SubsystemPlugins, plugin, start, fmt.Errorf("failed to install plugin %s: %w (output: %s)", plugin, err, output))
 			}
 		} else {
 			result.PluginsInstalled = append(result.PluginsInstalled, plugin)
+			result.record("install", SubsystemPlugins, plugin, start, nil)
 		}
 	}
 
 	// Install MCP servers
 	for _, mcp := range diff.MCPToInstall {
+		if err := ctx.Err(); err != nil {
+			return result, interrupted(err)
+		}
+		start := time.Now()
+		if err := checkPin(ctx, mcp); err != nil {
+			result.record("install", SubsystemMCP, mcp.Name, start, err)
+			continue
+		}
 		args := buildMCPAddArgs(mcp, resolvedMCP[mcp.Name])
-		if err := executor.Run(args...); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("failed to add MCP server %s: %w", mcp.Name, err))
+		if err := executor.Run(ctx, args...); err != nil {
+			result.record("install", SubsystemMCP, mcp.Name, start, fmt.Errorf("failed to add MCP server %s: %w", mcp.Name, err))
 		} else {
 			result.MCPServersInstalled = append(result.MCPServersInstalled, mcp.Name)
+			result.record("install", SubsystemMCP, mcp.Name, start, nil)
 		}
 	}
 
 	return result, nil
 }
 
+// resolveMCPSecrets resolves and validates mcp's secrets, preferring values
+// in given. Optional secrets that can't be resolved are returned sorted
+// rather than failing.
+func resolveMCPSecrets(ctx context.Context, mcp MCPServer, secretChain *secrets.Chain, given map[string]string) (map[string]string, []string, error) {
+	resolved := make(map[string]string)
+	var unresolved []string
+	for envVar, ref := range mcp.Secrets {
+		_, span := telemetry.Start(ctx, "resolve secret", telemetry.String("mcp.server", mcp.Name), telemetry.String("secret.name", envVar))
+		value, ok := given[envVar]
+		if !ok || value == "" {
+			value, ok = ResolveSecret(ref, secretChain)
+		}
+		span.SetAttributes(telemetry.Bool("secret.resolved", ok))
+		if !ok {
+			if ref.Optional {
+				span.End(nil)
+				unresolved = append(unresolved, envVar)
+				continue
+			}
+			err := fmt.Errorf("could not resolve secret %s for MCP server %s", envVar, mcp.Name)
+			span.End(err)
+			return nil, nil, err
+		}
+		if err := ValidateSecret(ctx, envVar, value, ref.Validate); err != nil {
+			err = fmt.Errorf("MCP server %s: %w", mcp.Name, err)
+			span.End(err)
+			return nil, nil, err
+		}
+		span.End(nil)
+		resolved[envVar] = value
+	}
+	sort.Strings(unresolved)
+	return resolved, unresolved, nil
+}
+
+// MCPLaunch resolves m's secrets and returns the args Claude Code starts
+// it with, and the secrets as KEY=value environment entries
+func MCPLaunch(ctx context.Context, m MCPServer, secretChain *secrets.Chain) ([]string, []string, error) {
+	resolved, _, err := resolveMCPSecrets(ctx, m, secretChain, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mcpCommandArgs(m, resolved), secretEnv(resolved), nil
+}
+
+// secretEnv lists resolved secrets as KEY=value, sorted by name
+func secretEnv(resolved map[string]string) []string {
+	var env []string
+	for _, envVar := range slices.Sorted(maps.Keys(resolved)) {
+		env = append(env, envVar+"="+resolved[envVar])
+	}
+	return env
+}
+
+// ReinstallMCP removes and re-adds an MCP server so it picks up secrets that
+// have been set since it was installed. Values in given take precedence
+// over the secret chain.
+func ReinstallMCP(ctx context.Context, mcp MCPServer, secretChain *secrets.Chain, executor CommandExecutor, given map[string]string) error {
+	resolved, _, err := resolveMCPSecrets(ctx, mcp, secretChain, given)
+	if err != nil {
+		return err
+	}
+	executor = traceCommands(ctx, executor, resolved)
+	// The server may already be gone; a failed add below is the real error
+	executor.Run(ctx, "mcp", "remove", mcp.Name)
+	if err := executor.Run(ctx, buildMCPAddArgs(mcp, resolved)...); err != nil {
+		return fmt.Errorf("failed to add MCP server %s: %w", mcp.Name, err)
+	}
+	return nil
+}
+
+// interrupted wraps the error of a cancelled context so callers can tell an
+// interrupted apply from a failed one
+func interrupted(err error) error {
+	return fmt.Errorf("interrupted: %w", err)
+}
+
+// ResolveSecret tries each of ref's sources that this machine matches, in
+// order, and returns the first non-empty value
+func ResolveSecret(ref SecretRef, secretChain *secrets.Chain) (string, bool) {
+	for _, source := range ref.Sources {
+		if !source.Condition.Matches(ThisMachine()) {
+			continue
+		}
+		var value string
+		var err error
+		switch source.Type {
+		case "env":
+			value, _, err = secretChain.Resolve(source.Key)
+		case "1password":
+			value, _, err = secretChain.Resolve(source.Ref)
+		case "keychain":
+			keychainRef := source.Service
+			if source.Account != "" {
+				keychainRef = source.Service + ":" + source.Account
+			}
+			value, _, err = secretChain.Resolve(keychainRef)
+		case "bitwarden":
+			value, _, err = secretChain.Resolve(secrets.BitwardenRef(source.Item, source.Field))
+		case "sops":
+			value, _, err = secretChain.Resolve(secrets.SopsRef(source.File, source.Key))
+		}
+		if err == nil && value != "" {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 func buildMCPAddArgs(mcp MCPServer, resolvedSecrets map[string]string) []string {
 	args := []string{"mcp", "add", mcp.Name}
 
@@ -242,9 +568,14 @@ func buildMCPAddArgs(mcp MCPServer, resolvedSecrets map[string]string) []string
 
 	// Add separator and command
 	args = append(args, "--", mcp.Command)
+	return append(args, mcpCommandArgs(mcp, resolvedSecrets)...)
+}
 
-	// Add command args, substituting secrets
-	for _, arg := range mcp.Args {
+// mcpCommandArgs returns the server's command args, pinning the package and
+// substituting secrets
+func mcpCommandArgs(mcp MCPServer, resolvedSecrets map[string]string) []string {
+	var args []string
+	for _, arg := range mcp.PinnedArgs() {
 		if strings.HasPrefix(arg, "$") {
 			envVar := strings.TrimPrefix(arg, "$")
 			if value, ok := resolvedSecrets[envVar]; ok {
@@ -258,19 +589,22 @@ func buildMCPAddArgs(mcp MCPServer, resolvedSecrets map[string]string) []string
 			args = append(args, arg)
 		}
 	}
-
 	return args
 }
 
-func runClaude(args ...string) error {
+func runClaude(ctx context.Context, args ...string) error {
 	claudePath, err := exec.LookPath("claude")
 	if err != nil {
 		return fmt.Errorf("claude CLI not found: %w", err)
 	}
 
-	cmd := exec.Command(claudePath, args...)
+	cmd, err := cmdline.Command(ctx, claudePath, args...)
+	if err != nil {
+		return err
+	}
+	cmd.Env = claudeEnv()
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = ui.Out()
 	cmd.Stderr = os.Stderr
 
 	return cmd.Run()
@@ -278,13 +612,17 @@ func runClaude(args ...string) error {
 
 // runClaudeWithOutput runs claude and captures combined output
 // Returns (output, error) - useful for checking error messages
-func runClaudeWithOutput(args ...string) (string, error) {
+func runClaudeWithOutput(ctx context.Context, args ...string) (string, error) {
 	claudePath, err := exec.LookPath("claude")
 	if err != nil {
 		return "", fmt.Errorf("claude CLI not found: %w", err)
 	}
 
-	cmd := exec.Command(claudePath, args...)
+	cmd, err := cmdline.Command(ctx, claudePath, args...)
+	if err != nil {
+		return "", err
+	}
+	cmd.Env = claudeEnv()
 	output, err := cmd.CombinedOutput()
 	return string(output), err
 }
@@ -292,20 +630,23 @@ func runClaudeWithOutput(args ...string) (string, error) {
 // DefaultClaudeDir returns the Claude configuration directory
 // Respects CLAUDE_CONFIG_DIR environment variable if set
 func DefaultClaudeDir() string {
-	if override := os.Getenv("CLAUDE_CONFIG_DIR"); override != "" {
-		return override
-	}
-	return filepath.Join(MustHomeDir(), ".claude")
+	return mustBeAbs(pathctx.Default().ClaudeDir)
 }
 
 // DefaultClaudeJSONPath returns the path to .claude.json
 // When CLAUDE_CONFIG_DIR is set, it's inside that directory
 // Otherwise it's at ~/.claude.json
 func DefaultClaudeJSONPath() string {
-	if override := os.Getenv("CLAUDE_CONFIG_DIR"); override != "" {
-		return filepath.Join(override, ".claude.json")
+	return mustBeAbs(pathctx.Default().ClaudeJSON)
+}
+
+// mustBeAbs panics, like MustHomeDir, on a path left relative because the
+// home directory is unknown
+func mustBeAbs(path string) string {
+	if !filepath.IsAbs(path) {
+		MustHomeDir()
 	}
-	return filepath.Join(MustHomeDir(), ".claude.json")
+	return path
 }
 
 func toSet(slice []string) map[string]struct{} {
@@ -319,9 +660,9 @@ func toSet(slice []string) map[string]struct{} {
 // MustHomeDir returns the user's home directory or panics if it cannot be determined.
 // This is appropriate because the tool cannot function without knowing the home directory.
 func MustHomeDir() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		panic(fmt.Sprintf("cannot determine home directory: %v", err))
+	if home := pathctx.Default().Home; home != "" {
+		return home
 	}
-	return homeDir
+	_, err := os.UserHomeDir()
+	panic(fmt.Sprintf("cannot determine home directory: %v", err))
 }
@@ -3,10 +3,22 @@
 package profile
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/claudeup/claudeup/internal/marketplace"
+	"github.com/claudeup/claudeup/internal/secrets"
+	"github.com/claudeup/claudeup/internal/state"
+	"github.com/claudeup/claudeup/internal/telemetry"
 )
 
 func TestComputeDiffPlugins(t *testing.T) {
@@ -318,6 +330,55 @@ func TestComputeDiffMarketplacesOnlyAdd(t *testing.T) {
 	// Verify no mechanism exists to remove marketplaces (by design)
 }
 
+func TestComputeDiffMirrorsMarketplaces(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	ResetSnapshotCache()
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	pluginsDir := filepath.Join(claudeDir, "plugins")
+	os.MkdirAll(pluginsDir, 0755)
+	os.MkdirAll(filepath.Join(tmpDir, ".claudeup"), 0755)
+
+	// The mirror of org/already is installed; org/new isn't
+	writeTestJSON(t, filepath.Join(pluginsDir, "installed_plugins.json"), map[string]interface{}{"version": 2, "plugins": map[string]interface{}{}})
+	writeTestJSON(t, filepath.Join(pluginsDir, "known_marketplaces.json"), map[string]interface{}{
+		"already": map[string]interface{}{
+			"source": map[string]interface{}{"source": "git", "url": "https://git.corp/mirror/org/already"},
+		},
+	})
+	writeTestJSON(t, filepath.Join(tmpDir, ".claude.json"), map[string]interface{}{})
+	writeTestJSON(t, filepath.Join(tmpDir, ".claudeup", "config.json"), map[string]interface{}{
+		"urlRewrites": map[string]string{"github.com/org": "git.corp/mirror/org"},
+	})
+
+	profile := &Profile{
+		Name: "test",
+		Marketplaces: []Marketplace{
+			{Source: "github", Repo: "org/already"},
+			{Source: "github", Repo: "org/new"},
+			{Source: "github", Repo: "other/tools"},
+		},
+	}
+
+	diff, err := ComputeDiff(profile, claudeDir, filepath.Join(tmpDir, ".claude.json"))
+	if err != nil {
+		t.Fatalf("ComputeDiff failed: %v", err)
+	}
+
+	want := []Marketplace{
+		{Source: "git", URL: "https://git.corp/mirror/org/new"},
+		{Source: "github", Repo: "other/tools"},
+	}
+	if len(diff.MarketplacesToAdd) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, diff.MarketplacesToAdd)
+	}
+	for i, m := range want {
+		if diff.MarketplacesToAdd[i] != m {
+			t.Errorf("Marketplace %d = %+v, want %+v", i, diff.MarketplacesToAdd[i], m)
+		}
+	}
+}
+
 func writeTestJSON(t *testing.T, path string, data interface{}) {
 	t.Helper()
 	bytes, err := json.MarshalIndent(data, "", "  ")
@@ -328,3 +389,119 @@ func writeTestJSON(t *testing.T, path string, data interface{}) {
 		t.Fatal(err)
 	}
 }
+
+// cancellingExecutor cancels its context after the first command, as if
+// the user pressed Ctrl-C while it ran
+type cancellingExecutor struct {
+	cancel context.CancelFunc
+	calls  [][]string
+}
+
+func (e *cancellingExecutor) Run(ctx context.Context, args ...string) error {
+	e.calls = append(e.calls, args)
+	e.cancel()
+	return nil
+}
+
+func (e *cancellingExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	return "", e.Run(ctx, args...)
+}
+
+func TestApplyDiffStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	executor := &cancellingExecutor{cancel: cancel}
+
+	diff := &Diff{PluginsToInstall: []string{"a@m", "b@m", "c@m"}}
+	result, err := ApplyDiff(ctx, diff, nil, executor)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a cancellation error, got %v", err)
+	}
+	if len(executor.calls) != 1 {
+		t.Errorf("Expected no commands after cancelling, got %v", executor.calls)
+	}
+	if result == nil || len(result.PluginsInstalled) != 1 || result.PluginsInstalled[0] != "a@m" {
+		t.Errorf("Expected the finished install to be reported, got %+v", result)
+	}
+}
+
+func TestApplyDiffAddsLocalMarketplace(t *testing.T) {
+	dir := t.TempDir()
+	executor := &okExecutor{}
+	diff := &Diff{MarketplacesToAdd: []Marketplace{{Source: MarketplaceLocal, Path: dir}}}
+
+	result, err := ApplyDiff(context.Background(), diff, nil, executor)
+	if err != nil {
+		t.Fatalf("ApplyDiff failed: %v", err)
+	}
+	if len(executor.calls) != 1 || executor.calls[0][len(executor.calls[0])-1] != dir {
+		t.Errorf("Expected 'marketplace add %s', got %v", dir, executor.calls)
+	}
+	if len(result.MarketplacesAdded) != 1 {
+		t.Errorf("Expected the marketplace to be added, got %+v", result)
+	}
+
+	missing := &Diff{MarketplacesToAdd: []Marketplace{{Source: MarketplaceLocal, Path: filepath.Join(dir, "missing")}}}
+	result, _ = ApplyDiff(context.Background(), missing, nil, &okExecutor{})
+	if len(result.Errors) != 1 {
+		t.Errorf("Expected a missing directory to fail, got %+v", result)
+	}
+}
+
+func TestRegisteredMarketplace(t *testing.T) {
+	dir := t.TempDir()
+	if got := RegisteredMarketplace(state.MarketplaceSource{Source: "directory", Path: dir}); got != (Marketplace{Source: MarketplaceLocal, Path: dir}) {
+		t.Errorf("Expected a local marketplace, got %+v", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, marketplace.ArchiveMarker), []byte(`{"url": "https://example.com/t.tar.gz", "sha256": "abc"}`), 0644)
+	want := Marketplace{Source: MarketplaceArchive, URL: "https://example.com/t.tar.gz", SHA256: "abc"}
+	if got := RegisteredMarketplace(state.MarketplaceSource{Source: "directory", Path: dir}); got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestApplyDiffRecordsSpans(t *testing.T) {
+	t.Setenv("DB_URL", "postgres://secret-host")
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	ctx, root := telemetry.StartTrace(context.Background(), &telemetry.Exporter{Endpoint: srv.URL}, "apply")
+	diff := &Diff{
+		PluginsToInstall: []string{"a@m"},
+		MCPToInstall: []MCPServer{{Name: "db", Command: "pg-mcp", Args: []string{"$DB_URL"},
+			Secrets: map[string]SecretRef{"DB_URL": {Sources: []SecretSource{{Type: "env", Key: "DB_URL"}}}}}},
+	}
+	if _, err := ApplyDiff(ctx, diff, secrets.NewChain(secrets.NewEnvResolver()), &okExecutor{}); err != nil {
+		t.Fatal(err)
+	}
+	root.End(nil)
+	if err := root.Export(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	var request struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct{ Name string }
+			}
+		}
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range request.ResourceSpans[0].ScopeSpans[0].Spans {
+		names = append(names, s.Name)
+	}
+	want := []string{"apply", "resolve secret", "claude plugin install", "claude mcp add"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected spans %v, got %v", want, names)
+	}
+	if strings.Contains(string(body), "secret-host") {
+		t.Error("Expected the secret value hidden from the spans")
+	}
+}
@@ -0,0 +1,95 @@
+// ABOUTME: Interprets claude CLI output for idempotency detection
+// ABOUTME: Holds the known output variants and the stable locale used when invoking claude
+package profile
+
+import (
+	"os"
+	"strings"
+)
+
+// claudeLocaleEnv pins the language of the claude CLI's messages so that
+// those matched below are not translated underneath us. Only messages are
+// pinned: the character set stays the user's, so UTF-8 plugin names and
+// paths come through intact.
+var claudeLocaleEnv = []string{
+	"LC_MESSAGES=C",
+}
+
+// alreadyInstalledVariants are known claude CLI outputs meaning a plugin
+// install was a no-op because the plugin is already present
+var alreadyInstalledVariants = []string{
+	"already installed",
+	"is already enabled",
+}
+
+// alreadyUninstalledVariants are known claude CLI outputs meaning a plugin
+// uninstall was a no-op because the plugin is already gone
+var alreadyUninstalledVariants = []string{
+	"already uninstalled",
+	"is not installed",
+	"not currently installed",
+}
+
+// pluginNotFoundVariants are known claude CLI outputs for a plugin it
+// doesn't know at all: gone already, or a misspelled name
+var pluginNotFoundVariants = []string{
+	"plugin not found",
+}
+
+// claudeEnv returns the environment used when running the claude CLI
+// The current environment is preserved, with the language of messages
+// overridden. LC_ALL and LANGUAGE would win over LC_MESSAGES, so they're
+// dropped, and LC_ALL's character set is kept as LC_CTYPE.
+func claudeEnv() []string {
+	env := make([]string, 0, len(os.Environ())+len(claudeLocaleEnv)+1)
+	ctype := ""
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		switch name {
+		case "LC_ALL":
+			ctype = value
+			continue
+		case "LC_CTYPE":
+			if ctype == "" {
+				ctype = value
+			}
+			continue
+		case "LANGUAGE", "LC_MESSAGES":
+			continue
+		}
+		env = append(env, kv)
+	}
+	if ctype != "" {
+		env = append(env, "LC_CTYPE="+ctype)
+	}
+	return append(env, claudeLocaleEnv...)
+}
+
+// IsAlreadyInstalledOutput reports whether claude CLI output indicates
+// that a plugin was already installed
+func IsAlreadyInstalledOutput(output string) bool {
+	return matchesAny(output, alreadyInstalledVariants)
+}
+
+// IsAlreadyUninstalledOutput reports whether claude CLI output indicates
+// that a plugin was already uninstalled
+func IsAlreadyUninstalledOutput(output string) bool {
+	return matchesAny(output, alreadyUninstalledVariants)
+}
+
+// IsPluginNotFoundOutput reports whether claude CLI output indicates that
+// it doesn't know the plugin. That's only an uninstall already done when
+// the plugin was recorded as installed; otherwise its name is likely wrong.
+func IsPluginNotFoundOutput(output string) bool {
+	return matchesAny(output, pluginNotFoundVariants)
+}
+
+func matchesAny(output string, variants []string) bool {
+	lower := strings.ToLower(output)
+	for _, v := range variants {
+		if strings.Contains(lower, v) {
+			return true
+		}
+	}
+	return false
+}
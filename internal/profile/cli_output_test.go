@@ -0,0 +1,97 @@
+// ABOUTME: Tests for claude CLI output interpretation
+// ABOUTME: Compatibility table of known output variants and locale pinning
+package profile
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsAlreadyInstalledOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"Error: plugin-a@marketplace is already installed", true},
+		{"✘ Plugin \"plugin-a@marketplace\" is already installed", true},
+		{"ERROR: PLUGIN-A@MARKETPLACE IS ALREADY INSTALLED", true},
+		{"Plugin plugin-a@marketplace is already enabled", true},
+		{"✔ Successfully installed plugin-a@marketplace", false},
+		{"Error: network timeout while downloading plugin", false},
+		{"Error: plugin-a@marketplace is already uninstalled", false},
+		{"", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.output, func(t *testing.T) {
+			if got := IsAlreadyInstalledOutput(tc.output); got != tc.want {
+				t.Errorf("IsAlreadyInstalledOutput(%q) = %v, want %v", tc.output, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsAlreadyUninstalledOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"Error: plugin-a@marketplace is already uninstalled", true},
+		{"Error: Plugin \"plugin-a@marketplace\" is not installed", true},
+		{"Plugin plugin-a@marketplace is not currently installed", true},
+		{"Error: Plugin not found: plugin-a@marketplace", false},
+		{"✔ Successfully uninstalled plugin-a@marketplace", false},
+		{"Error: permission denied", false},
+		{"", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.output, func(t *testing.T) {
+			if got := IsAlreadyUninstalledOutput(tc.output); got != tc.want {
+				t.Errorf("IsAlreadyUninstalledOutput(%q) = %v, want %v", tc.output, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsPluginNotFoundOutput(t *testing.T) {
+	if !IsPluginNotFoundOutput("Error: Plugin not found: plugin-a@marketplace") {
+		t.Error("Expected a plugin not found error to be recognized")
+	}
+	if IsPluginNotFoundOutput("Error: plugin-a@marketplace is not installed") {
+		t.Error("Expected not installed to be a different answer")
+	}
+}
+
+func TestClaudeEnvPinsLocale(t *testing.T) {
+	t.Setenv("LANG", "de_DE.UTF-8")
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	t.Setenv("LC_MESSAGES", "de_DE.UTF-8")
+	t.Setenv("LANGUAGE", "de")
+	t.Setenv("CLAUDEUP_TEST_VAR", "kept")
+
+	env := claudeEnv()
+
+	seen := make(map[string][]string)
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		seen[name] = append(seen[name], value)
+	}
+
+	want := map[string]string{
+		"LC_MESSAGES":       "C",
+		"LC_CTYPE":          "de_DE.UTF-8", // the character set LC_ALL chose
+		"LANG":              "de_DE.UTF-8",
+		"CLAUDEUP_TEST_VAR": "kept",
+	}
+	for name, value := range want {
+		if len(seen[name]) != 1 || seen[name][0] != value {
+			t.Errorf("Expected %s=%s exactly once, got %v", name, value, seen[name])
+		}
+	}
+	for _, name := range []string{"LC_ALL", "LANGUAGE"} {
+		if _, ok := seen[name]; ok {
+			t.Errorf("Expected %s to be removed, since it would win over LC_MESSAGES, got %v", name, seen[name])
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// ABOUTME: Conditions limiting profile entries to machines by OS, architecture, programs, or environment
+// ABOUTME: Entries this machine doesn't match are left out of the profile it applies
+package profile
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/claudeup/claudeup/internal/platform"
+)
+
+// Condition limits an entry to some machines. It's written inline on MCP
+// servers, secret sources, and sandbox mounts, and in pluginConditions and
+// groupConditions for plugins and plugin groups. An empty field matches
+// every machine.
+type Condition struct {
+	OS       []string `json:"os,omitempty"`       // Go OS names such as darwin, linux, windows
+	Arch     []string `json:"arch,omitempty"`     // Go architecture names such as amd64, arm64
+	Requires []string `json:"requires,omitempty"` // programs that must be on the PATH, such as docker
+	EnvSet   []string `json:"envSet,omitempty"`   // environment variables that must be set and non-empty, such as CI
+}
+
+// Machine is what conditions are checked against
+type Machine struct {
+	OS   string
+	Arch string
+	// HasCommand reports whether a program is on the PATH
+	HasCommand func(name string) bool
+	// HasEnv reports whether an environment variable is set and non-empty
+	HasEnv func(name string) bool
+}
+
+var thisMachine = sync.OnceValue(func() Machine {
+	host := platform.Host()
+	return Machine{
+		OS:   host.OS,
+		Arch: host.Arch,
+		HasCommand: func(name string) bool {
+			_, err := exec.LookPath(name)
+			return err == nil
+		},
+		HasEnv: func(name string) bool { return os.Getenv(name) != "" },
+	}
+})
+
+// ThisMachine returns the machine claudeup runs on. Arch is the hardware's,
+// so Apple silicon matches arm64 even under Rosetta.
+func ThisMachine() Machine {
+	return thisMachine()
+}
+
+// IsZero reports whether c matches every machine
+func (c Condition) IsZero() bool {
+	return len(c.OS) == 0 && len(c.Arch) == 0 && len(c.Requires) == 0 && len(c.EnvSet) == 0
+}
+
+// Matches reports whether m meets every part of c
+func (c Condition) Matches(m Machine) bool {
+	if len(c.OS) > 0 && !slices.Contains(c.OS, m.OS) {
+		return false
+	}
+	if len(c.Arch) > 0 && !slices.Contains(c.Arch, m.Arch) {
+		return false
+	}
+	for _, name := range c.Requires {
+		if m.HasCommand == nil || !m.HasCommand(name) {
+			return false
+		}
+	}
+	for _, name := range c.EnvSet {
+		if m.HasEnv == nil || !m.HasEnv(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes c, such as "darwin/arm64 with docker when $CI set", or
+// "" if it matches every machine
+func (c Condition) String() string {
+	var parts []string
+	switch {
+	case len(c.OS) > 0 && len(c.Arch) > 0:
+		parts = append(parts, strings.Join(c.OS, ",")+"/"+strings.Join(c.Arch, ","))
+	case len(c.OS) > 0:
+		parts = append(parts, strings.Join(c.OS, ","))
+	case len(c.Arch) > 0:
+		parts = append(parts, strings.Join(c.Arch, ","))
+	}
+	if len(c.Requires) > 0 {
+		parts = append(parts, "with "+strings.Join(c.Requires, ", "))
+	}
+	if len(c.EnvSet) > 0 {
+		parts = append(parts, "when $"+strings.Join(c.EnvSet, ", $")+" set")
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c Condition) clone() Condition {
+	return Condition{
+		OS:       slices.Clone(c.OS),
+		Arch:     slices.Clone(c.Arch),
+		Requires: slices.Clone(c.Requires),
+		EnvSet:   slices.Clone(c.EnvSet),
+	}
+}
+
+// Skipped is a profile entry left out of a diff because this machine
+// doesn't meet its condition
+type Skipped struct {
+	Kind      string `json:"kind"` // "mcp", "plugin", or "group"
+	Name      string `json:"name"`
+	Condition string `json:"condition"`
+}
+
+// SkippedOn lists the MCP servers, plugins, and enabled plugin groups m
+// doesn't match, which ForMachine leaves out. A plugin is listed only when
+// it would otherwise be applied, not when its whole group is skipped. For
+// a profile ForMachine returned, it's what was left out then.
+func (p *Profile) SkippedOn(m Machine) []Skipped {
+	skipped := slices.Clone(p.skipped)
+	for _, plugin := range p.pluginsOn(m) {
+		if c := p.PluginConditions[plugin]; !c.Matches(m) {
+			skipped = append(skipped, Skipped{Kind: "plugin", Name: plugin, Condition: c.String()})
+		}
+	}
+	for _, name := range p.GroupNames() {
+		if c := p.GroupConditions[name]; p.GroupEnabled(name) && !c.Matches(m) {
+			skipped = append(skipped, Skipped{Kind: "group", Name: name, Condition: c.String()})
+		}
+	}
+	for _, srv := range p.MCPServers {
+		if !srv.Condition.Matches(m) {
+			skipped = append(skipped, Skipped{Kind: "mcp", Name: srv.Name, Condition: srv.Condition.String()})
+		}
+	}
+	return skipped
+}
+
+// pluginsOn returns the profile's plugins, disabled ones included, and
+// those of its enabled groups whose conditions m meets, before plugin
+// conditions are checked
+func (p *Profile) pluginsOn(m Machine) []string {
+	plugins := appendMissing(append([]string(nil), p.Plugins...), p.Disabled.Plugins...)
+	for _, name := range p.GroupNames() {
+		if p.GroupEnabled(name) && p.GroupConditions[name].Matches(m) {
+			plugins = appendMissing(plugins, p.Groups[name]...)
+		}
+	}
+	return plugins
+}
+
+// hasConditions reports whether any of the profile's entries is limited
+// to some machines
+func (p *Profile) hasConditions() bool {
+	if len(p.PluginConditions) > 0 || len(p.GroupConditions) > 0 {
+		return true
+	}
+	for _, m := range p.MCPServers {
+		if !m.Condition.IsZero() || refsHaveConditions(m.Secrets) {
+			return true
+		}
+	}
+	for _, mount := range p.Sandbox.Mounts {
+		if !mount.Condition.IsZero() {
+			return true
+		}
+	}
+	for _, env := range p.Env {
+		if sourcesHaveConditions(env.Sources) {
+			return true
+		}
+	}
+	return refsHaveConditions(p.ShellEnv.Secrets)
+}
+
+func refsHaveConditions(refs map[string]SecretRef) bool {
+	for _, ref := range refs {
+		if sourcesHaveConditions(ref.Sources) {
+			return true
+		}
+	}
+	return false
+}
+
+func sourcesHaveConditions(sources []SecretSource) bool {
+	return slices.ContainsFunc(sources, func(s SecretSource) bool { return !s.Condition.IsZero() })
+}
+
+// ForMachine returns the profile as it applies on m: the plugins of enabled
+// groups whose conditions m meets folded into Plugins, no groups left, and
+// plugins, MCP servers, secret sources, and sandbox mounts m doesn't match
+// left out.
+// Of MCP servers sharing a name, the first m matches is kept. The entries
+// kept have no conditions. A profile without groups or conditions is
+// returned as is.
+func (p *Profile) ForMachine(m Machine) *Profile {
+	if p == nil || (len(p.Groups) == 0 && !p.hasConditions()) {
+		return p
+	}
+	resolved := p.Clone(p.Name)
+	resolved.skipped = p.SkippedOn(m)
+	for name := range resolved.Groups {
+		if !p.GroupConditions[name].Matches(m) {
+			delete(resolved.Groups, name)
+		}
+	}
+	resolved.Plugins = pluginsForMachine(resolved.EffectivePlugins(), p.PluginConditions, m)
+	resolved.Disabled.Plugins = pluginsForMachine(resolved.Disabled.Plugins, p.PluginConditions, m)
+	resolved.Groups = nil
+	resolved.DisabledGroups = nil
+	resolved.GroupConditions = nil
+	resolved.PluginConditions = nil
+
+	servers := resolved.MCPServers[:0]
+	seen := make(map[string]bool)
+	for _, srv := range resolved.MCPServers {
+		if seen[srv.Name] || !srv.Condition.Matches(m) {
+			continue
+		}
+		seen[srv.Name] = true
+		srv.Condition = Condition{}
+		srv.Secrets = refsForMachine(srv.Secrets, m)
+		servers = append(servers, srv)
+	}
+	resolved.MCPServers = servers
+	if len(resolved.MCPServers) == 0 {
+		resolved.MCPServers = nil
+	}
+
+	mounts := resolved.Sandbox.Mounts[:0]
+	for _, mount := range resolved.Sandbox.Mounts {
+		if mount.Condition.Matches(m) {
+			mount.Condition = Condition{}
+			mounts = append(mounts, mount)
+		}
+	}
+	resolved.Sandbox.Mounts = mounts
+	if len(resolved.Sandbox.Mounts) == 0 {
+		resolved.Sandbox.Mounts = nil
+	}
+
+	for name, env := range resolved.Env {
+		env.Sources = sourcesForMachine(env.Sources, m)
+		resolved.Env[name] = env
+	}
+	resolved.ShellEnv.Secrets = refsForMachine(resolved.ShellEnv.Secrets, m)
+	return resolved
+}
+
+func pluginsForMachine(plugins []string, conditions map[string]Condition, m Machine) []string {
+	kept := plugins[:0]
+	for _, plugin := range plugins {
+		if conditions[plugin].Matches(m) {
+			kept = append(kept, plugin)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+func refsForMachine(refs map[string]SecretRef, m Machine) map[string]SecretRef {
+	for name, ref := range refs {
+		ref.Sources = sourcesForMachine(ref.Sources, m)
+		refs[name] = ref
+	}
+	return refs
+}
+
+func sourcesForMachine(sources []SecretSource, m Machine) []SecretSource {
+	kept := sources[:0]
+	for _, s := range sources {
+		if s.Condition.Matches(m) {
+			s.Condition = Condition{}
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
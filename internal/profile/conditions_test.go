@@ -0,0 +1,181 @@
+// ABOUTME: Tests for conditions limiting profile entries to some machines
+// ABOUTME: Checks matching, descriptions, and the profile ForMachine leaves for a given machine
+package profile
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func machine(os, arch string, commands ...string) Machine {
+	return Machine{OS: os, Arch: arch, HasCommand: func(name string) bool { return slices.Contains(commands, name) }}
+}
+
+func TestConditionMatches(t *testing.T) {
+	mac := machine("darwin", "arm64")
+	linux := machine("linux", "amd64", "docker")
+	tests := []struct {
+		c           Condition
+		mac, linux  bool
+		description string
+	}{
+		{Condition{}, true, true, ""},
+		{Condition{OS: []string{"darwin"}}, true, false, "darwin"},
+		{Condition{OS: []string{"darwin", "linux"}, Arch: []string{"amd64"}}, false, true, "darwin,linux/amd64"},
+		{Condition{Requires: []string{"docker"}}, false, true, "with docker"},
+		{Condition{Arch: []string{"arm64"}, Requires: []string{"docker"}}, false, false, "arm64 with docker"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.Matches(mac); got != tt.mac {
+			t.Errorf("%+v on darwin/arm64: expected %t", tt.c, tt.mac)
+		}
+		if got := tt.c.Matches(linux); got != tt.linux {
+			t.Errorf("%+v on linux/amd64 with docker: expected %t", tt.c, tt.linux)
+		}
+		if got := tt.c.String(); got != tt.description {
+			t.Errorf("Expected %q, got %q", tt.description, got)
+		}
+	}
+}
+
+func TestConditionInlineJSON(t *testing.T) {
+	var m MCPServer
+	if err := json.Unmarshal([]byte(`{"name": "github", "command": "docker", "os": ["linux"], "requires": ["docker"]}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	want := Condition{OS: []string{"linux"}, Requires: []string{"docker"}}
+	if !reflect.DeepEqual(m.Condition, want) {
+		t.Errorf("Expected %+v, got %+v", want, m.Condition)
+	}
+	data, _ := json.Marshal(MCPServer{Name: "plain", Command: "x"})
+	if string(data) != `{"name":"plain","command":"x"}` {
+		t.Errorf("Expected no condition fields written, got %s", data)
+	}
+}
+
+func TestForMachine(t *testing.T) {
+	keychain := SecretSource{Type: "keychain", Service: "gh", Condition: Condition{OS: []string{"darwin"}}}
+	env := SecretSource{Type: "env", Key: "GH_TOKEN"}
+	p := &Profile{
+		Name:            "team",
+		Plugins:         []string{"lint@tools"},
+		Groups:          map[string][]string{"mac": {"raycast@tools"}, "extras": {"fmt@tools"}},
+		GroupConditions: map[string]Condition{"mac": {OS: []string{"darwin"}}},
+		MCPServers: []MCPServer{
+			{Name: "github", Command: "docker", Condition: Condition{Requires: []string{"docker"}},
+				Secrets: map[string]SecretRef{"GH_TOKEN": {Sources: []SecretSource{keychain, env}}}},
+			{Name: "github", Command: "npx"},
+			{Name: "finder", Command: "finder-mcp", Condition: Condition{OS: []string{"darwin"}}},
+		},
+		Sandbox: SandboxConfig{Mounts: []SandboxMount{
+			{Host: "~/Library", Container: "/lib", Condition: Condition{OS: []string{"darwin"}}},
+			{Host: "~/src", Container: "/src"},
+		}},
+	}
+
+	linux := p.ForMachine(machine("linux", "amd64", "docker"))
+	if want := []string{"lint@tools", "fmt@tools"}; !reflect.DeepEqual(linux.Plugins, want) {
+		t.Errorf("Expected plugins %v, got %v", want, linux.Plugins)
+	}
+	if len(linux.MCPServers) != 1 || linux.MCPServers[0].Command != "docker" || !linux.MCPServers[0].Condition.IsZero() {
+		t.Fatalf("Expected only the docker github server without its condition, got %+v", linux.MCPServers)
+	}
+	if sources := linux.MCPServers[0].Secrets["GH_TOKEN"].Sources; !reflect.DeepEqual(sources, []SecretSource{env}) {
+		t.Errorf("Expected only the env source, got %+v", sources)
+	}
+	if len(linux.Sandbox.Mounts) != 1 || linux.Sandbox.Mounts[0].Host != "~/src" {
+		t.Errorf("Expected only the unconditional mount, got %+v", linux.Sandbox.Mounts)
+	}
+
+	mac := p.ForMachine(machine("darwin", "arm64"))
+	if want := []string{"lint@tools", "fmt@tools", "raycast@tools"}; !reflect.DeepEqual(mac.Plugins, want) {
+		t.Errorf("Expected plugins %v, got %v", want, mac.Plugins)
+	}
+	if len(mac.MCPServers) != 2 || mac.MCPServers[0].Command != "npx" || mac.MCPServers[1].Name != "finder" {
+		t.Errorf("Expected the npx github server and finder, got %+v", mac.MCPServers)
+	}
+
+	if len(p.MCPServers) != 3 || len(p.MCPServers[0].Secrets["GH_TOKEN"].Sources) != 2 || len(p.Sandbox.Mounts) != 2 {
+		t.Error("ForMachine must not modify the profile")
+	}
+}
+
+func TestForMachinePluginConditions(t *testing.T) {
+	docker := Condition{Requires: []string{"docker"}}
+	p := &Profile{
+		Name:            "team",
+		Plugins:         []string{"lint@tools", "docker-tools@tools"},
+		Disabled:        DisabledConfig{Plugins: []string{"docker-extras@tools"}},
+		Groups:          map[string][]string{"mac": {"raycast@tools", "docker-tools@tools"}},
+		GroupConditions: map[string]Condition{"mac": {OS: []string{"darwin"}}},
+		PluginConditions: map[string]Condition{
+			"docker-tools@tools":  docker,
+			"docker-extras@tools": docker,
+			"raycast@tools":       {Arch: []string{"arm64"}},
+		},
+	}
+
+	linux := machine("linux", "amd64")
+	resolved := p.ForMachine(linux)
+	if want := []string{"lint@tools"}; !reflect.DeepEqual(resolved.Plugins, want) {
+		t.Errorf("Expected plugins %v, got %v", want, resolved.Plugins)
+	}
+	if resolved.Disabled.Plugins != nil || resolved.PluginConditions != nil {
+		t.Errorf("Expected the disabled plugin and the conditions left out, got %+v", resolved)
+	}
+	// The mac group's plugins aren't listed on their own when the group is skipped
+	want := []Skipped{
+		{Kind: "plugin", Name: "docker-tools@tools", Condition: "with docker"},
+		{Kind: "plugin", Name: "docker-extras@tools", Condition: "with docker"},
+		{Kind: "group", Name: "mac", Condition: "darwin"},
+	}
+	if got := resolved.SkippedOn(linux); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	intelMac := p.ForMachine(machine("darwin", "amd64", "docker"))
+	if want := []string{"lint@tools", "docker-tools@tools"}; !reflect.DeepEqual(intelMac.Plugins, want) {
+		t.Errorf("Expected plugins %v, got %v", want, intelMac.Plugins)
+	}
+	if want := []string{"docker-extras@tools"}; !reflect.DeepEqual(intelMac.Disabled.Plugins, want) {
+		t.Errorf("Expected disabled plugins %v, got %v", want, intelMac.Disabled.Plugins)
+	}
+
+	if len(p.Plugins) != 2 || len(p.Disabled.Plugins) != 1 || len(p.PluginConditions) != 3 {
+		t.Error("ForMachine must not modify the profile")
+	}
+}
+
+func TestSkippedOnSurvivesResolving(t *testing.T) {
+	p := &Profile{
+		Name:            "team",
+		Groups:          map[string][]string{"mac": {"raycast@tools"}, "off": {"x@tools"}},
+		DisabledGroups:  []string{"off"},
+		GroupConditions: map[string]Condition{"mac": {OS: []string{"darwin"}}, "off": {OS: []string{"darwin"}}},
+		MCPServers: []MCPServer{
+			{Name: "ci-reporter", Command: "report-mcp", Condition: Condition{EnvSet: []string{"CI"}}},
+			{Name: "docs", Command: "docs-mcp"},
+		},
+	}
+	linux := Machine{OS: "linux", Arch: "amd64", HasEnv: func(string) bool { return false }}
+	want := []Skipped{
+		{Kind: "group", Name: "mac", Condition: "darwin"},
+		{Kind: "mcp", Name: "ci-reporter", Condition: "when $CI set"},
+	}
+	if got := p.SkippedOn(linux); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	// What was skipped is still known once the profile is resolved
+	resolved := p.ForMachine(linux)
+	if got := resolved.SkippedOn(linux); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v from the resolved profile, got %+v", want, got)
+	}
+
+	ci := Machine{OS: "linux", Arch: "amd64", HasEnv: func(name string) bool { return name == "CI" }}
+	if got := p.ForMachine(ci).MCPServers; len(got) != 2 {
+		t.Errorf("Expected both servers with CI set, got %+v", got)
+	}
+}
@@ -0,0 +1,362 @@
+// ABOUTME: DirectExecutor makes the changes the claude CLI would by editing Claude's registry files itself
+// ABOUTME: Backs 'profile simulate' and the self-test, where no real claude CLI should run
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/marketplace"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// DirectExecutor implements CommandExecutor by writing the plugin,
+// marketplace, and MCP server changes apply asks for straight to the
+// registry files under ClaudeDir and to ClaudeJSON. It runs and fetches
+// nothing: a marketplace that isn't a local directory is registered
+// without being cloned, and its plugins are taken on trust.
+type DirectExecutor struct {
+	ClaudeDir  string
+	ClaudeJSON string
+	// ProjectDir is the project for local- and project-scope MCP servers;
+	// project-scope ones are written to its .mcp.json
+	ProjectDir string
+}
+
+// Run applies one claude command
+func (d *DirectExecutor) Run(ctx context.Context, args ...string) error {
+	_, err := d.RunWithOutput(ctx, args...)
+	return err
+}
+
+// RunWithOutput applies one claude command and returns what claude would
+// print
+func (d *DirectExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	unexpected := fmt.Errorf("unexpected command: claude %s", strings.Join(args, " "))
+	var output string
+	var err error
+	switch strings.Join(args[:min(len(args), 2)], " ") {
+	case "plugin marketplace":
+		if len(args) != 4 || args[2] != "add" {
+			return "", unexpected
+		}
+		output, err = d.addMarketplace(args[3])
+	case "plugin install":
+		if len(args) != 3 {
+			return "", unexpected
+		}
+		output, err = d.installPlugin(args[2])
+	case "plugin uninstall":
+		if len(args) != 3 {
+			return "", unexpected
+		}
+		output, err = d.uninstallPlugin(args[2])
+	case "mcp add":
+		output, err = d.addServer(args[2:])
+	case "mcp remove":
+		if len(args) != 3 {
+			return "", unexpected
+		}
+		output, err = d.removeServer(args[2])
+	default:
+		return "", unexpected
+	}
+	if err != nil {
+		return err.Error(), err
+	}
+	return output, nil
+}
+
+// githubRepo matches the owner/repo form of a GitHub marketplace
+var githubRepo = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// addMarketplace registers src under the name its manifest gives, or for
+// a marketplace that isn't on disk, the last element of its repo or URL
+func (d *DirectExecutor) addMarketplace(src string) (string, error) {
+	var meta state.MarketplaceMetadata
+	var name string
+	if info, err := os.Stat(src); err == nil && info.IsDir() {
+		m, err := marketplace.LoadManifest(src)
+		if err != nil {
+			return "", fmt.Errorf("%s is not a marketplace: %w", src, err)
+		}
+		name = m.Name
+		meta = state.MarketplaceMetadata{Source: state.MarketplaceSource{Source: "directory", Path: src}, InstallLocation: src}
+	} else {
+		switch {
+		case strings.Contains(src, "://") || strings.HasPrefix(src, "git@"):
+			meta.Source = state.MarketplaceSource{Source: "git", URL: src}
+		case githubRepo.MatchString(src):
+			meta.Source = state.MarketplaceSource{Source: "github", Repo: src}
+		default:
+			return "", fmt.Errorf("marketplace source %s is not a directory, a GitHub repo, or a git URL", src)
+		}
+		name = strings.TrimSuffix(src[strings.LastIndexAny(src, "/:")+1:], ".git")
+		meta.InstallLocation = filepath.Join(d.ClaudeDir, "plugins", "marketplaces", name)
+	}
+	if name == "" {
+		return "", fmt.Errorf("marketplace %s has no name", src)
+	}
+	meta.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+
+	registry, err := d.marketplaces()
+	if err != nil {
+		return "", err
+	}
+	if existing, ok := registry[name]; ok {
+		if existing.Source.Key() == meta.Source.Key() {
+			return fmt.Sprintf("Marketplace '%s' is already installed", name), nil
+		}
+		return "", fmt.Errorf("marketplace '%s' is already installed from %s", name, existing.Source.Key())
+	}
+	registry[name] = meta
+	if err := os.MkdirAll(filepath.Join(d.ClaudeDir, "plugins"), 0755); err != nil {
+		return "", err
+	}
+	if err := state.SaveMarketplaces(d.ClaudeDir, registry); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("✔ Successfully added marketplace: %s", name), nil
+}
+
+func (d *DirectExecutor) marketplaces() (state.MarketplaceRegistry, error) {
+	registry, err := state.LoadMarketplaces(d.ClaudeDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return state.MarketplaceRegistry{}, nil
+	}
+	return registry, err
+}
+
+// installPlugin registers plugin name@marketplace. When the marketplace is
+// on disk the plugin must be in its manifest, and is installed from its
+// directory there.
+func (d *DirectExecutor) installPlugin(plugin string) (string, error) {
+	at := strings.LastIndex(plugin, "@")
+	if at <= 0 {
+		return "", fmt.Errorf("plugin %s should be name@marketplace", plugin)
+	}
+	name, mkt := plugin[:at], plugin[at+1:]
+	markets, err := d.marketplaces()
+	if err != nil {
+		return "", err
+	}
+	known, ok := markets[mkt]
+	if !ok {
+		return "", fmt.Errorf("marketplace %s not found", mkt)
+	}
+
+	version := "unknown"
+	installPath := filepath.Join(d.ClaudeDir, "plugins", "cache", mkt, name, version)
+	if m, err := marketplace.LoadManifest(known.InstallLocation); err == nil {
+		found := false
+		for _, p := range m.Plugins {
+			found = found || p.Name == name
+		}
+		if !found {
+			return "", fmt.Errorf("plugin %s not found in marketplace %s", name, mkt)
+		}
+		if dir, ok := marketplace.PluginDir(known.InstallLocation, name); ok {
+			installPath = dir
+			version = pluginVersion(dir)
+		}
+	}
+
+	registry, err := d.plugins()
+	if err != nil {
+		return "", err
+	}
+	if registry.PluginExists(plugin) {
+		return fmt.Sprintf("Plugin '%s' is already installed", plugin), fmt.Errorf("plugin %s is already installed", plugin)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	registry.SetPlugin(plugin, state.PluginMetadata{Scope: "user", Version: version, InstalledAt: now, LastUpdated: now, InstallPath: installPath})
+	if err := d.savePlugins(registry); err != nil {
+		return "", err
+	}
+	if err := d.setEnabled(plugin, true); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("✔ Successfully installed plugin: %s", plugin), nil
+}
+
+// pluginVersion reads the version from a plugin's plugin.json
+func pluginVersion(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, ".claude-plugin", "plugin.json"))
+	if err != nil {
+		return "unknown"
+	}
+	var manifest struct {
+		Version string `json:"version"`
+	}
+	if json.Unmarshal(data, &manifest) != nil || manifest.Version == "" {
+		return "unknown"
+	}
+	return manifest.Version
+}
+
+func (d *DirectExecutor) uninstallPlugin(plugin string) (string, error) {
+	registry, err := d.plugins()
+	if err != nil {
+		return "", err
+	}
+	if !registry.DisablePlugin(plugin) {
+		return "", fmt.Errorf("plugin %s is not installed", plugin)
+	}
+	if err := d.savePlugins(registry); err != nil {
+		return "", err
+	}
+	if err := d.setEnabled(plugin, false); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("✔ Successfully uninstalled plugin: %s", plugin), nil
+}
+
+func (d *DirectExecutor) plugins() (*state.PluginRegistry, error) {
+	registry, err := state.LoadPlugins(d.ClaudeDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return &state.PluginRegistry{Version: 2, Plugins: map[string][]state.PluginMetadata{}}, nil
+	}
+	return registry, err
+}
+
+func (d *DirectExecutor) savePlugins(registry *state.PluginRegistry) error {
+	if err := os.MkdirAll(filepath.Join(d.ClaudeDir, "plugins"), 0755); err != nil {
+		return err
+	}
+	return state.SavePlugins(d.ClaudeDir, registry)
+}
+
+// setEnabled adds plugin to settings.json's enabledPlugins, or removes it,
+// as the claude CLI does when installing and uninstalling
+func (d *DirectExecutor) setEnabled(plugin string, enabled bool) error {
+	return editJSONObject(filepath.Join(d.ClaudeDir, "settings.json"), "enabledPlugins", func(plugins map[string]json.RawMessage) {
+		if enabled {
+			plugins[plugin] = json.RawMessage("true")
+		} else {
+			delete(plugins, plugin)
+		}
+	})
+}
+
+// addServer handles "name [-s scope] [-e KEY=value]... -- command args..."
+func (d *DirectExecutor) addServer(args []string) (string, error) {
+	sep := -1
+	for i, a := range args {
+		if a == "--" {
+			sep = i
+			break
+		}
+	}
+	if sep < 1 || sep+1 >= len(args) {
+		return "", fmt.Errorf("mcp add: expected a name, and a command after --")
+	}
+	name, scope := args[0], "local"
+	server := state.MCPServer{Type: "stdio", Command: args[sep+1], Args: append([]string{}, args[sep+2:]...), Env: map[string]string{}}
+	for i := 1; i < sep; i++ {
+		if i+1 >= sep {
+			return "", fmt.Errorf("mcp add: %s needs a value", args[i])
+		}
+		switch args[i] {
+		case "-s", "--scope":
+			scope = args[i+1]
+		case "-e", "--env":
+			key, value, ok := strings.Cut(args[i+1], "=")
+			if !ok {
+				return "", fmt.Errorf("mcp add: environment variable %s should be KEY=value", args[i+1])
+			}
+			server.Env[key] = value
+		default:
+			return "", fmt.Errorf("mcp add: unexpected option %s", args[i])
+		}
+		i++
+	}
+
+	entry, err := json.Marshal(server)
+	if err != nil {
+		return "", err
+	}
+	add := func(servers map[string]json.RawMessage) { servers[name] = entry }
+	switch scope {
+	case "user":
+		err = editJSONObject(d.ClaudeJSON, "mcpServers", add)
+	case "project":
+		err = editJSONObject(filepath.Join(d.ProjectDir, ".mcp.json"), "mcpServers", add)
+	case "local":
+		err = d.editLocalServers(add)
+	default:
+		return "", fmt.Errorf("mcp add: unknown scope %s", scope)
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Added stdio MCP server %s to %s config", name, scope), nil
+}
+
+// removeServer removes a user-scope MCP server, the only scope apply
+// removes from
+func (d *DirectExecutor) removeServer(name string) (string, error) {
+	found := false
+	err := editJSONObject(d.ClaudeJSON, "mcpServers", func(servers map[string]json.RawMessage) {
+		_, found = servers[name]
+		delete(servers, name)
+	})
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("no MCP server found with name: %s", name)
+	}
+	return fmt.Sprintf("Removed MCP server %s from user config", name), nil
+}
+
+// editLocalServers edits the project's entry under "projects" in ClaudeJSON
+func (d *DirectExecutor) editLocalServers(edit func(map[string]json.RawMessage)) error {
+	if d.ProjectDir == "" {
+		return fmt.Errorf("mcp add: no project directory for a local-scope server")
+	}
+	return editJSONObject(d.ClaudeJSON, "projects", func(projects map[string]json.RawMessage) {
+		project := map[string]json.RawMessage{}
+		_ = json.Unmarshal(projects[d.ProjectDir], &project)
+		servers := map[string]json.RawMessage{}
+		_ = json.Unmarshal(project["mcpServers"], &servers)
+		edit(servers)
+		project["mcpServers"], _ = json.Marshal(servers)
+		projects[d.ProjectDir], _ = json.Marshal(project)
+	})
+}
+
+// editJSONObject edits the object under key in the JSON file at path,
+// creating the file and the object when they don't exist
+func editJSONObject(path, key string, edit func(map[string]json.RawMessage)) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte("{}\n"), 0644); err != nil {
+			return err
+		}
+	}
+	_, err := state.EditJSON(path, func(obj map[string]json.RawMessage) error {
+		inner := map[string]json.RawMessage{}
+		if raw, ok := obj[key]; ok {
+			if err := json.Unmarshal(raw, &inner); err != nil {
+				return fmt.Errorf("%s in %s: %w", key, path, err)
+			}
+		}
+		edit(inner)
+		data, err := json.Marshal(inner)
+		if err != nil {
+			return err
+		}
+		obj[key] = data
+		return nil
+	})
+	return err
+}
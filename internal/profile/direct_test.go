@@ -0,0 +1,208 @@
+// ABOUTME: Tests for DirectExecutor, which edits Claude's registry files in place of the claude CLI
+// ABOUTME: Applies a profile into an empty directory and checks each command's effect on the files
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// writeMarketplace creates a local marketplace named name with one plugin
+func writeMarketplace(t *testing.T, name, plugin, version string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	for _, sub := range []string{".claude-plugin", filepath.Join("plugins", plugin, ".claude-plugin")} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestJSON(t, filepath.Join(dir, ".claude-plugin", "marketplace.json"), map[string]interface{}{
+		"name":    name,
+		"plugins": []map[string]string{{"name": plugin, "source": "./plugins/" + plugin}},
+	})
+	writeTestJSON(t, filepath.Join(dir, "plugins", plugin, ".claude-plugin", "plugin.json"), map[string]string{"name": plugin, "version": version})
+	return dir
+}
+
+func readTestJSON(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestDirectExecutorAppliesProfileIntoEmptyDir(t *testing.T) {
+	home := t.TempDir()
+	defer pathctx.Override(pathctx.ForHome(home))()
+	ResetSnapshotCache()
+	dir := filepath.Join(t.TempDir(), "scratch")
+	d := &DirectExecutor{ClaudeDir: dir, ClaudeJSON: filepath.Join(dir, ".claude.json"), ProjectDir: home}
+	local := writeMarketplace(t, "tools", "lint", "2.1.0")
+
+	p := &Profile{
+		Name:         "team",
+		Marketplaces: []Marketplace{{Source: MarketplaceLocal, Path: local}, {Source: "github", Repo: "acme/plugins"}},
+		Plugins:      []string{"lint@tools", "deploy@plugins"},
+		MCPServers:   []MCPServer{{Name: "db", Command: "db-mcp", Args: []string{"--port", "5432"}, Scope: "user"}},
+	}
+	result, err := ApplyWithExecutor(context.Background(), p, d.ClaudeDir, d.ClaudeJSON, nil, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected a clean apply, got %v", result.Errors)
+	}
+
+	plugins, err := state.LoadPlugins(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lint, _ := plugins.GetPlugin("lint@tools")
+	if lint.Version != "2.1.0" || lint.InstallPath != filepath.Join(local, "plugins", "lint") {
+		t.Errorf("Expected lint installed from the marketplace directory, got %+v", lint)
+	}
+	deploy, _ := plugins.GetPlugin("deploy@plugins")
+	if deploy.InstallPath != filepath.Join(dir, "plugins", "cache", "plugins", "deploy", "unknown") {
+		t.Errorf("Expected deploy under the plugin cache, got %+v", deploy)
+	}
+	settings := readTestJSON(t, filepath.Join(dir, "settings.json"))
+	if !reflect.DeepEqual(settings["enabledPlugins"], map[string]interface{}{"lint@tools": true, "deploy@plugins": true}) {
+		t.Errorf("Expected both plugins enabled, got %v", settings["enabledPlugins"])
+	}
+
+	// Applying again finds nothing left to do
+	ResetSnapshotCache()
+	again, err := ComputeDiff(p, d.ClaudeDir, d.ClaudeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(again.PluginsToRemove) + len(again.MCPToRemove) + len(again.MCPToInstall) + len(again.MarketplacesToAdd); n > 0 {
+		t.Errorf("Expected the applied state to match the profile, got %+v", again)
+	}
+}
+
+func TestDirectExecutorMarketplaces(t *testing.T) {
+	dir := t.TempDir()
+	d := &DirectExecutor{ClaudeDir: dir, ClaudeJSON: filepath.Join(dir, ".claude.json")}
+	ctx := context.Background()
+
+	for _, src := range []string{"acme/plugins", "https://git.corp/team/tools.git"} {
+		if err := d.Run(ctx, "plugin", "marketplace", "add", src); err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+	}
+	registry, err := state.LoadMarketplaces(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registry["plugins"].Source.Repo != "acme/plugins" || registry["tools"].Source.URL != "https://git.corp/team/tools.git" {
+		t.Errorf("Expected both marketplaces named after their source, got %+v", registry)
+	}
+
+	if out, err := d.RunWithOutput(ctx, "plugin", "marketplace", "add", "acme/plugins"); err != nil || !strings.Contains(out, "already installed") {
+		t.Errorf("Expected adding the same source again to be a no-op, got %q, %v", out, err)
+	}
+	if err := d.Run(ctx, "plugin", "marketplace", "add", "other/plugins"); err == nil {
+		t.Error("Expected a second source with the same name to be refused")
+	}
+	if err := d.Run(ctx, "plugin", "marketplace", "add", "not a source"); err == nil {
+		t.Error("Expected an unrecognized source to be refused")
+	}
+}
+
+func TestDirectExecutorPlugins(t *testing.T) {
+	dir := t.TempDir()
+	d := &DirectExecutor{ClaudeDir: dir, ClaudeJSON: filepath.Join(dir, ".claude.json")}
+	ctx := context.Background()
+	if err := d.Run(ctx, "plugin", "marketplace", "add", writeMarketplace(t, "tools", "lint", "1.0.0")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Run(ctx, "plugin", "install", "format@tools"); err == nil || !strings.Contains(err.Error(), "not found in marketplace") {
+		t.Errorf("Expected a plugin missing from the manifest to fail, got %v", err)
+	}
+	if err := d.Run(ctx, "plugin", "install", "lint@elsewhere"); err == nil || !strings.Contains(err.Error(), "marketplace elsewhere not found") {
+		t.Errorf("Expected an unknown marketplace to fail, got %v", err)
+	}
+	if err := d.Run(ctx, "plugin", "install", "lint@tools"); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := d.RunWithOutput(ctx, "plugin", "install", "lint@tools"); !IsAlreadyInstalledOutput(out) {
+		t.Errorf("Expected a second install to read as already installed, got %q", out)
+	}
+
+	if err := d.Run(ctx, "plugin", "uninstall", "lint@tools"); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := d.RunWithOutput(ctx, "plugin", "uninstall", "lint@tools"); !IsAlreadyUninstalledOutput(out) {
+		t.Errorf("Expected a second uninstall to read as already uninstalled, got %q", out)
+	}
+	settings := readTestJSON(t, filepath.Join(dir, "settings.json"))
+	if enabled := settings["enabledPlugins"].(map[string]interface{}); len(enabled) != 0 {
+		t.Errorf("Expected the plugin to be dropped from enabledPlugins, got %v", enabled)
+	}
+
+	if err := d.Run(ctx, "plugin", "update", "lint@tools"); err == nil {
+		t.Error("Expected a command apply doesn't use to be refused")
+	}
+}
+
+func TestDirectExecutorMCPServers(t *testing.T) {
+	dir := t.TempDir()
+	project := t.TempDir()
+	d := &DirectExecutor{ClaudeDir: dir, ClaudeJSON: filepath.Join(dir, ".claude.json"), ProjectDir: project}
+	ctx := context.Background()
+
+	for _, args := range [][]string{
+		{"mcp", "add", "db", "-s", "user", "-e", "TOKEN=abc", "--", "db-mcp", "--port", "5432"},
+		{"mcp", "add", "docs", "-s", "project", "--", "docs-mcp"},
+		{"mcp", "add", "scratch", "--", "scratch-mcp"},
+	} {
+		if err := d.Run(ctx, args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+
+	servers, err := state.LoadMCPServers(d.ClaudeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := servers["db"]
+	if db.Command != "db-mcp" || !reflect.DeepEqual(db.Args, []string{"--port", "5432"}) || db.Env["TOKEN"] != "abc" {
+		t.Errorf("Unexpected user server %+v", db)
+	}
+	if len(servers) != 1 {
+		t.Errorf("Expected only the user server at the top level, got %v", servers)
+	}
+	if _, ok := readTestJSON(t, filepath.Join(project, ".mcp.json"))["mcpServers"].(map[string]interface{})["docs"]; !ok {
+		t.Error("Expected the project server in the project's .mcp.json")
+	}
+	projects := readTestJSON(t, d.ClaudeJSON)["projects"].(map[string]interface{})
+	if _, ok := projects[project].(map[string]interface{})["mcpServers"].(map[string]interface{})["scratch"]; !ok {
+		t.Errorf("Expected the local server under the project, got %v", projects)
+	}
+
+	if err := d.Run(ctx, "mcp", "remove", "db"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Run(ctx, "mcp", "remove", "db"); err == nil {
+		t.Error("Expected removing a missing server to fail")
+	}
+	if err := d.Run(ctx, "mcp", "add", "bad", "-s", "global", "--", "x"); err == nil {
+		t.Error("Expected an unknown scope to be refused")
+	}
+}
@@ -0,0 +1,149 @@
+// ABOUTME: Captures and restores claudeup's installed-but-disabled plugins and MCP servers
+// ABOUTME: Disabled plugins are installed first, then moved from Claude's registry into config
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// diffDisabled fills in the disabled-state changes between current and profile.
+// wanted is the set of every plugin the profile installs.
+func diffDisabled(diff *Diff, current, profile *Profile, wanted map[string]struct{}) {
+	addOnly := profile.IsAddon()
+
+	currentDisabled := toSet(current.Disabled.Plugins)
+	profileDisabled := toSet(profile.Disabled.Plugins)
+	for plugin := range profileDisabled {
+		if _, ok := currentDisabled[plugin]; !ok {
+			diff.PluginsToDisable = append(diff.PluginsToDisable, plugin)
+		}
+	}
+	// A plugin the profile lists as enabled comes back from a local disable.
+	// Disabled plugins the profile doesn't mention at all are left alone.
+	for plugin := range currentDisabled {
+		_, stillDisabled := profileDisabled[plugin]
+		_, listed := wanted[plugin]
+		if listed && !stillDisabled && !addOnly {
+			diff.PluginsToEnable = append(diff.PluginsToEnable, plugin)
+		}
+	}
+
+	currentMCP := toSet(current.Disabled.MCPServers)
+	profileMCP := toSet(profile.Disabled.MCPServers)
+	for ref := range profileMCP {
+		if _, ok := currentMCP[ref]; !ok {
+			diff.MCPToDisable = append(diff.MCPToDisable, ref)
+		}
+	}
+	for ref := range currentMCP {
+		if _, ok := profileMCP[ref]; !ok && !addOnly {
+			diff.MCPToEnable = append(diff.MCPToEnable, ref)
+		}
+	}
+
+	sort.Strings(diff.PluginsToDisable)
+	sort.Strings(diff.PluginsToEnable)
+	sort.Strings(diff.MCPToDisable)
+	sort.Strings(diff.MCPToEnable)
+}
+
+// ApplyDisabledState re-creates the disabled state described by diff once
+// plugins are installed. It mirrors 'claudeup disable' and 'claudeup enable':
+// disabled plugin metadata moves from Claude's registry into claudeup's config.
+// Problems are recorded in result.Errors.
+func ApplyDisabledState(diff *Diff, claudeDir string, result *ApplyResult) {
+	if len(diff.PluginsToDisable)+len(diff.PluginsToEnable)+len(diff.MCPToDisable)+len(diff.MCPToEnable) == 0 {
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Errorf("failed to load config: %w", err))
+		return
+	}
+
+	registry, err := state.LoadPlugins(claudeDir)
+	if err != nil {
+		registry = &state.PluginRegistry{Version: 2, Plugins: make(map[string][]state.PluginMetadata)}
+	}
+	registryChanged := false
+
+	for _, plugin := range diff.PluginsToDisable {
+		start := time.Now()
+		meta, ok := registry.GetPlugin(plugin)
+		if !ok {
+			result.record("disable", SubsystemPlugins, plugin, start, fmt.Errorf("failed to disable plugin %s: not installed", plugin))
+			continue
+		}
+		cfg.DisablePlugin(plugin, config.DisabledPlugin{
+			Version:      meta.Version,
+			InstalledAt:  meta.InstalledAt,
+			LastUpdated:  meta.LastUpdated,
+			InstallPath:  meta.InstallPath,
+			GitCommitSha: meta.GitCommitSha,
+			IsLocal:      meta.IsLocal,
+		})
+		registry.DisablePlugin(plugin)
+		registryChanged = true
+		result.PluginsDisabled = append(result.PluginsDisabled, plugin)
+		result.record("disable", SubsystemPlugins, plugin, start, nil)
+	}
+
+	for _, plugin := range diff.PluginsToEnable {
+		start := time.Now()
+		meta, ok := cfg.EnablePlugin(plugin)
+		if !ok {
+			result.recordOutcome("enable", SubsystemPlugins, plugin, start, StepAlready, nil)
+			continue
+		}
+		// The install step normally re-registers the plugin; restore the
+		// saved metadata only if it didn't
+		if !registry.PluginExists(plugin) {
+			registry.EnablePlugin(plugin, state.PluginMetadata{
+				Version:      meta.Version,
+				InstalledAt:  meta.InstalledAt,
+				LastUpdated:  meta.LastUpdated,
+				InstallPath:  meta.InstallPath,
+				GitCommitSha: meta.GitCommitSha,
+				IsLocal:      meta.IsLocal,
+			})
+			registryChanged = true
+		}
+		result.PluginsEnabled = append(result.PluginsEnabled, plugin)
+		result.record("enable", SubsystemPlugins, plugin, start, nil)
+	}
+
+	for _, ref := range diff.MCPToDisable {
+		start := time.Now()
+		if cfg.DisableMCPServer(ref) {
+			result.MCPServersDisabled = append(result.MCPServersDisabled, ref)
+			result.record("disable", SubsystemMCP, ref, start, nil)
+		} else {
+			result.recordOutcome("disable", SubsystemMCP, ref, start, StepAlready, nil)
+		}
+	}
+	for _, ref := range diff.MCPToEnable {
+		start := time.Now()
+		if cfg.EnableMCPServer(ref) {
+			result.MCPServersEnabled = append(result.MCPServersEnabled, ref)
+			result.record("enable", SubsystemMCP, ref, start, nil)
+		} else {
+			result.recordOutcome("enable", SubsystemMCP, ref, start, StepAlready, nil)
+		}
+	}
+
+	if err := config.Save(cfg); err != nil {
+		result.Errors = append(result.Errors, fmt.Errorf("failed to save config: %w", err))
+		return
+	}
+	if registryChanged {
+		if err := state.SavePlugins(claudeDir, registry); err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("failed to save plugins: %w", err))
+		}
+	}
+}
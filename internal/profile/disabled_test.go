@@ -0,0 +1,168 @@
+// ABOUTME: Tests for capturing and restoring disabled plugins and MCP servers
+// ABOUTME: Uses a temporary HOME so claudeup's global config is isolated
+package profile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+type okExecutor struct{ calls [][]string }
+
+func (e *okExecutor) Run(ctx context.Context, args ...string) error {
+	e.calls = append(e.calls, args)
+	return nil
+}
+
+func (e *okExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	e.calls = append(e.calls, args)
+	return "", nil
+}
+
+func setupDisabledTest(t *testing.T, installed ...string) (claudeDir, claudeJSON string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	ResetSnapshotCache()
+
+	claudeDir = filepath.Join(home, ".claude")
+	pluginsDir := filepath.Join(claudeDir, "plugins")
+	if err := os.MkdirAll(pluginsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	plugins := make(map[string]interface{})
+	for _, name := range installed {
+		plugins[name] = []map[string]interface{}{{"scope": "user", "version": "1.0.0", "installPath": "/cache/" + name}}
+	}
+	writeTestJSON(t, filepath.Join(pluginsDir, "installed_plugins.json"), map[string]interface{}{"version": 2, "plugins": plugins})
+	writeTestJSON(t, filepath.Join(pluginsDir, "known_marketplaces.json"), map[string]interface{}{})
+	claudeJSON = filepath.Join(home, ".claude.json")
+	writeTestJSON(t, claudeJSON, map[string]interface{}{})
+	return claudeDir, claudeJSON
+}
+
+func TestSnapshotCapturesDisabledState(t *testing.T) {
+	claudeDir, claudeJSON := setupDisabledTest(t, "a@m")
+
+	cfg := config.DefaultConfig()
+	cfg.DisablePlugin("old@m", config.DisabledPlugin{Version: "2.0.0"})
+	cfg.DisableMCPServer("old@m:db")
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Snapshot("snap", claudeDir, claudeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p.Plugins, []string{"a@m", "old@m"}) {
+		t.Errorf("Expected disabled plugin among installed plugins, got %v", p.Plugins)
+	}
+	want := DisabledConfig{Plugins: []string{"old@m"}, MCPServers: []string{"old@m:db"}}
+	if !reflect.DeepEqual(p.Disabled, want) {
+		t.Errorf("Disabled = %+v, want %+v", p.Disabled, want)
+	}
+
+	// Applying the snapshot again changes nothing about disabled state
+	diff, err := ComputeDiff(p, claudeDir, claudeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.PluginsToDisable)+len(diff.PluginsToEnable)+len(diff.MCPToDisable)+len(diff.MCPToEnable) != 0 {
+		t.Errorf("Expected no disabled-state changes, got %+v", diff)
+	}
+	if len(diff.PluginsToRemove) != 0 || !reflect.DeepEqual(diff.PluginsToInstall, []string{"a@m"}) {
+		t.Errorf("Disabled plugin must be neither removed nor reinstalled, got %+v", diff)
+	}
+}
+
+func TestApplyRecreatesDisabledState(t *testing.T) {
+	// b@m appears in the registry as if 'claude plugin install' had just run
+	claudeDir, claudeJSON := setupDisabledTest(t, "a@m", "b@m")
+
+	p := &Profile{
+		Name:     "work",
+		Plugins:  []string{"a@m", "b@m"},
+		Disabled: DisabledConfig{Plugins: []string{"b@m"}, MCPServers: []string{"a@m:db"}},
+	}
+
+	result, err := ApplyWithExecutor(context.Background(), p, claudeDir, claudeJSON, nil, &okExecutor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+	if !reflect.DeepEqual(result.PluginsDisabled, []string{"b@m"}) || !reflect.DeepEqual(result.MCPServersDisabled, []string{"a@m:db"}) {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, ok := cfg.GetDisabledPlugin("b@m")
+	if !ok || meta.Version != "1.0.0" || meta.InstallPath != "/cache/b@m" {
+		t.Errorf("Expected b@m metadata saved in config, got %+v", meta)
+	}
+	if !cfg.IsMCPServerDisabled("a@m:db") {
+		t.Error("Expected a@m:db disabled")
+	}
+
+	registry, err := state.LoadPlugins(claudeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registry.PluginExists("b@m") || !registry.PluginExists("a@m") {
+		t.Errorf("Expected only a@m in the registry, got %v", registry.Plugins)
+	}
+}
+
+func TestApplyReenablesPluginListedAsEnabled(t *testing.T) {
+	claudeDir, claudeJSON := setupDisabledTest(t, "a@m")
+
+	cfg := config.DefaultConfig()
+	cfg.DisablePlugin("b@m", config.DisabledPlugin{Version: "1.2.0", InstallPath: "/cache/b@m"})
+	cfg.DisableMCPServer("b@m:db")
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Profile{Name: "work", Plugins: []string{"a@m", "b@m"}}
+	diff, err := ComputeDiff(p, claudeDir, claudeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(diff.PluginsToEnable, []string{"b@m"}) || !reflect.DeepEqual(diff.MCPToEnable, []string{"b@m:db"}) {
+		t.Fatalf("Unexpected diff %+v", diff)
+	}
+
+	result, err := ApplyWithExecutor(context.Background(), p, claudeDir, claudeJSON, nil, &okExecutor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.PluginsEnabled, []string{"b@m"}) {
+		t.Errorf("Expected b@m re-enabled, got %+v", result)
+	}
+
+	registry, err := state.LoadPlugins(claudeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta, ok := registry.GetPlugin("b@m"); !ok || meta.Version != "1.2.0" {
+		t.Errorf("Expected b@m restored to the registry, got %+v", registry.Plugins)
+	}
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.IsPluginDisabled("b@m") || cfg.IsMCPServerDisabled("b@m:db") {
+		t.Error("Expected b@m and b@m:db removed from disabled config")
+	}
+}
@@ -0,0 +1,109 @@
+// ABOUTME: Cheap drift detection between a profile and the current Claude state
+// ABOUTME: StateHash fingerprints files by size and mtime; ContentHash fingerprints the item sets
+package profile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// StateHash fingerprints every file Snapshot reads, plus any extra files,
+// from their size and modification time. It changes whenever the state
+// might have, without reading a single file.
+func StateHash(claudeDir, claudeJSONPath string, extra ...string) string {
+	stamps := stateStamps(claudeDir, claudeJSONPath)
+	h := sha256.New()
+	for _, s := range stamps {
+		fmt.Fprintf(h, "%t %d %d\n", s.exists, s.modTime.UnixNano(), s.size)
+	}
+	for _, path := range extra {
+		s := stampFile(path)
+		fmt.Fprintf(h, "%s %t %d %d\n", path, s.exists, s.modTime.UnixNano(), s.size)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Drift counts the items in current that differ from p as it applies on
+// this machine with overrides: plugins and MCP servers added or removed,
+// marketplaces missing, and items disabled or re-enabled. Extra items
+// don't count against an addon, which only adds.
+func Drift(p, current *Profile, overrides *Overrides) int {
+	want := p.ForMachine(ThisMachine()).WithOverrides(overrides).Clone(p.Name)
+	want.Plugins = appendMissing(want.Plugins, want.Disabled.Plugins...)
+
+	drift := 0
+	for _, s := range Sections {
+		c := compareItems(itemNames(want, s), itemNames(current, s))
+		drift += len(c.Removed)
+		if s != SectionMarketplaces && !p.IsAddon() {
+			drift += len(c.Added)
+		}
+	}
+	return drift
+}
+
+// itemNames is sectionItems keyed by identity alone, since a snapshot
+// holds resolved secrets where the profile holds references
+func itemNames(p *Profile, s Section) map[string]string {
+	names := make(map[string]string)
+	for k := range sectionItems(p, s) {
+		names[k] = k
+	}
+	return names
+}
+
+// ContentHash fingerprints the items drift is measured on: the plugin, MCP
+// server, and marketplace names and the disabled items. Two states with the
+// same hash have no drift between them.
+func ContentHash(p *Profile) string {
+	want := p.Clone(p.Name)
+	want.Plugins = appendMissing(want.Plugins, want.Disabled.Plugins...)
+
+	h := sha256.New()
+	for _, s := range Sections {
+		var names []string
+		for k := range itemNames(want, s) {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(h, "%s\x00%s\n", s, strings.Join(names, "\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SaveContentHash records the content hash of the state a profile was
+// applied to, next to its applied copy
+func SaveContentHash(appliedDir, name, hash string) error {
+	path := contentHashPath(appliedDir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(hash+"\n"), 0644)
+}
+
+// LoadContentHash returns the hash saved by SaveContentHash
+func LoadContentHash(appliedDir, name string) (string, error) {
+	data, err := os.ReadFile(contentHashPath(appliedDir, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// RemoveContentHash forgets the saved hash, so drift is always measured in full
+func RemoveContentHash(appliedDir, name string) error {
+	err := os.Remove(contentHashPath(appliedDir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func contentHashPath(appliedDir, name string) string {
+	return filepath.Join(appliedDir, name+".hash")
+}
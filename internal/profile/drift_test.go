@@ -0,0 +1,96 @@
+// ABOUTME: Tests for drift counting and the state file fingerprint
+// ABOUTME: Checks which differences count as drift and that the hash follows file changes
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDrift(t *testing.T) {
+	p := &Profile{
+		Name:         "frontend",
+		Marketplaces: []Marketplace{{Source: "github", Repo: "org/market"}},
+		Plugins:      []string{"a@m", "b@m"},
+		MCPServers:   []MCPServer{{Name: "db", Command: "db-mcp", Args: []string{"$DB_URL"}}},
+		Disabled:     DisabledConfig{Plugins: []string{"c@m"}},
+	}
+	current := &Profile{
+		Marketplaces: []Marketplace{{Source: "github", Repo: "org/market"}, {Source: "github", Repo: "org/extra"}},
+		Plugins:      []string{"a@m", "b@m", "c@m"},
+		MCPServers:   []MCPServer{{Name: "db", Command: "db-mcp", Args: []string{"postgres://secret"}}},
+		Disabled:     DisabledConfig{Plugins: []string{"c@m"}},
+	}
+	if got := Drift(p, current, nil); got != 0 {
+		t.Errorf("Drift() = %d for a matching state, want 0", got)
+	}
+
+	current.Plugins = []string{"a@m", "c@m", "d@m"}
+	current.MCPServers = nil
+	if got := Drift(p, current, nil); got != 3 {
+		t.Errorf("Drift() = %d, want 3 (b@m removed, d@m added, db removed)", got)
+	}
+
+	addon := &Profile{Name: "extra", Type: TypeAddon, Plugins: []string{"a@m"}}
+	if got := Drift(addon, current, nil); got != 0 {
+		t.Errorf("Drift() = %d for an addon, want 0", got)
+	}
+}
+
+func TestStateHashFollowsFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	claudeDir := t.TempDir()
+	claudeJSON := filepath.Join(claudeDir, ".claude.json")
+	extra := filepath.Join(claudeDir, "profile.json")
+
+	before := StateHash(claudeDir, claudeJSON, extra)
+	if again := StateHash(claudeDir, claudeJSON, extra); again != before {
+		t.Fatal("Expected the same hash for unchanged files")
+	}
+
+	if err := os.WriteFile(extra, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	after := StateHash(claudeDir, claudeJSON, extra)
+	if after == before {
+		t.Error("Expected the hash to change when an extra file appears")
+	}
+
+	later := time.Now().Add(time.Minute)
+	os.WriteFile(claudeJSON, []byte("{}"), 0644)
+	os.Chtimes(claudeJSON, later, later)
+	if StateHash(claudeDir, claudeJSON, extra) == after {
+		t.Error("Expected the hash to change when a state file changes")
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	a := &Profile{Plugins: []string{"a@m", "b@m"}, MCPServers: []MCPServer{{Name: "db", Command: "x"}}}
+	b := &Profile{Plugins: []string{"b@m", "a@m"}, MCPServers: []MCPServer{{Name: "db", Command: "y"}}}
+	if ContentHash(a) != ContentHash(b) {
+		t.Error("Expected the same hash regardless of order and MCP definitions")
+	}
+	b.Plugins = append(b.Plugins, "c@m")
+	if ContentHash(a) == ContentHash(b) {
+		t.Error("Expected a different hash with an extra plugin")
+	}
+
+	dir := t.TempDir()
+	if err := SaveContentHash(dir, "frontend", ContentHash(a)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadContentHash(dir, "frontend"); err != nil || got != ContentHash(a) {
+		t.Errorf("LoadContentHash() = %q, %v", got, err)
+	}
+	if _, err := List(dir); err != nil {
+		t.Errorf("Expected the hash file to be ignored by List, got %v", err)
+	}
+	if err := RemoveContentHash(dir, "frontend"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveContentHash(dir, "frontend"); err != nil {
+		t.Errorf("Expected removing a missing hash to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// ABOUTME: Environment variable manifest declared in a profile's "env" section
+// ABOUTME: Resolves each variable from the environment, secret sources, or its default
+package profile
+
+import (
+	"os"
+	"sort"
+
+	"github.com/claudeup/claudeup/internal/secrets"
+)
+
+// EnvVar declares an environment variable the profile's tools expect
+type EnvVar struct {
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Default     string         `json:"default,omitempty"`
+	Sources     []SecretSource `json:"sources,omitempty"` // tried when the variable isn't already set
+}
+
+// Where an EnvStatus value came from
+const (
+	EnvFromEnvironment = "environment"
+	EnvFromSources     = "sources"
+	EnvFromDefault     = "default"
+)
+
+// EnvStatus is the resolved state of one manifest variable
+type EnvStatus struct {
+	Name   string
+	Var    EnvVar
+	Value  string
+	Source string // one of the EnvFrom constants, or "" when unset
+}
+
+// Missing reports whether a required variable has no value
+func (s EnvStatus) Missing() bool {
+	return s.Var.Required && s.Source == ""
+}
+
+// CheckEnv resolves the profile's env manifest, sorted by name. Each
+// variable is looked up in the process environment, then its sources, then
+// its default. A nil secretChain skips the sources, e.g. to avoid password
+// manager prompts when only displaying the manifest.
+func (p *Profile) CheckEnv(secretChain *secrets.Chain) []EnvStatus {
+	names := make([]string, 0, len(p.Env))
+	for name := range p.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	statuses := make([]EnvStatus, 0, len(names))
+	for _, name := range names {
+		v := p.Env[name]
+		s := EnvStatus{Name: name, Var: v}
+		if value, ok := os.LookupEnv(name); ok && value != "" {
+			s.Value, s.Source = value, EnvFromEnvironment
+		} else if value, ok := resolveEnvSources(v, secretChain); ok {
+			s.Value, s.Source = value, EnvFromSources
+		} else if v.Default != "" {
+			s.Value, s.Source = v.Default, EnvFromDefault
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+func resolveEnvSources(v EnvVar, secretChain *secrets.Chain) (string, bool) {
+	if secretChain == nil || len(v.Sources) == 0 {
+		return "", false
+	}
+	return ResolveSecret(SecretRef{Sources: v.Sources}, secretChain)
+}
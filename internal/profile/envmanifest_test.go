@@ -0,0 +1,74 @@
+// ABOUTME: Unit tests for the profile env manifest
+// ABOUTME: Covers lookup order, required variables, and merging addons
+package profile
+
+import (
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/secrets"
+)
+
+func TestCheckEnv(t *testing.T) {
+	t.Setenv("FROM_ENV", "set")
+	t.Setenv("FROM_SOURCE_VALUE", "resolved")
+	t.Setenv("MISSING", "")
+	t.Setenv("DEFAULTED", "")
+	t.Setenv("FROM_SOURCE", "")
+
+	p := &Profile{Env: map[string]EnvVar{
+		"FROM_ENV":    {Required: true, Default: "unused"},
+		"FROM_SOURCE": {Required: true, Sources: []SecretSource{{Type: "env", Key: "FROM_SOURCE_VALUE"}}},
+		"DEFAULTED":   {Default: "info"},
+		"MISSING":     {Required: true, Description: "API token"},
+		"OPTIONAL":    {},
+	}}
+
+	got := make(map[string]EnvStatus)
+	for _, s := range p.CheckEnv(secrets.NewChain(secrets.NewEnvResolver())) {
+		got[s.Name] = s
+	}
+	want := map[string]string{
+		"FROM_ENV":    EnvFromEnvironment,
+		"FROM_SOURCE": EnvFromSources,
+		"DEFAULTED":   EnvFromDefault,
+		"MISSING":     "",
+		"OPTIONAL":    "",
+	}
+	for name, source := range want {
+		if got[name].Source != source {
+			t.Errorf("%s: expected source %q, got %q", name, source, got[name].Source)
+		}
+	}
+	if got["FROM_SOURCE"].Value != "resolved" || got["DEFAULTED"].Value != "info" {
+		t.Errorf("Unexpected values: %+v", got)
+	}
+	if !got["MISSING"].Missing() || got["OPTIONAL"].Missing() {
+		t.Error("Only unset required variables should be missing")
+	}
+
+	// Without a chain, sources are skipped
+	for _, s := range p.CheckEnv(nil) {
+		if s.Name == "FROM_SOURCE" && !s.Missing() {
+			t.Error("Expected sources to be skipped without a secret chain")
+		}
+	}
+}
+
+func TestMergeEnvManifest(t *testing.T) {
+	base := &Profile{Name: "base", Env: map[string]EnvVar{"TOKEN": {Required: true}}}
+	addon := &Profile{Name: "extra", Type: TypeAddon, Env: map[string]EnvVar{
+		"TOKEN":  {Default: "ignored"},
+		"REGION": {Default: "us-east-1"},
+	}}
+
+	merged, err := Merge(base, addon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merged.Env["TOKEN"].Required || merged.Env["REGION"].Default != "us-east-1" {
+		t.Errorf("Unexpected merged manifest: %+v", merged.Env)
+	}
+	if len(base.Env) != 1 {
+		t.Error("Merge should not modify the base profile")
+	}
+}
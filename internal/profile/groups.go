@@ -0,0 +1,69 @@
+// ABOUTME: Named plugin groups inside a profile that can be turned on and off
+// ABOUTME: The plugins of enabled groups are applied along with the profile's own
+package profile
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// GroupNames returns the profile's plugin group names, sorted
+func (p *Profile) GroupNames() []string {
+	names := make([]string, 0, len(p.Groups))
+	for name := range p.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GroupEnabled reports whether the named group's plugins are applied
+func (p *Profile) GroupEnabled(name string) bool {
+	_, ok := p.Groups[name]
+	return ok && !slices.Contains(p.DisabledGroups, name)
+}
+
+// SetGroupEnabled turns a plugin group on or off
+func (p *Profile) SetGroupEnabled(name string, enabled bool) error {
+	if _, ok := p.Groups[name]; !ok {
+		if len(p.Groups) == 0 {
+			return fmt.Errorf("profile %q has no plugin groups", p.Name)
+		}
+		return fmt.Errorf("profile %q has no plugin group %q (have %s)", p.Name, name, strings.Join(p.GroupNames(), ", "))
+	}
+	p.DisabledGroups = slices.DeleteFunc(p.DisabledGroups, func(g string) bool { return g == name })
+	if !enabled {
+		p.DisabledGroups = append(p.DisabledGroups, name)
+		sort.Strings(p.DisabledGroups)
+	}
+	if len(p.DisabledGroups) == 0 {
+		p.DisabledGroups = nil
+	}
+	return nil
+}
+
+// EffectivePlugins returns the profile's plugins followed by those of its
+// enabled groups, in group name order, without duplicates
+func (p *Profile) EffectivePlugins() []string {
+	plugins := append([]string(nil), p.Plugins...)
+	for _, name := range p.GroupNames() {
+		if p.GroupEnabled(name) {
+			plugins = appendMissing(plugins, p.Groups[name]...)
+		}
+	}
+	return plugins
+}
+
+// ForThisMachine returns the profile as it applies here: its enabled
+// groups folded into Plugins, without the entries this machine doesn't
+// match, and with the overrides file read and merged in. See ForMachine
+// and WithOverrides.
+func (p *Profile) ForThisMachine() (*Profile, error) {
+	overrides, err := LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
+	return p.ForMachine(ThisMachine()).WithOverrides(overrides), nil
+}
@@ -0,0 +1,127 @@
+// ABOUTME: Tests for named plugin groups in profiles
+// ABOUTME: Covers toggling groups, effective plugins, diffs, drift, and merging addons
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func groupedProfile() *Profile {
+	return &Profile{
+		Name:    "backend",
+		Plugins: []string{"core@m"},
+		Groups: map[string][]string{
+			"testing": {"tdd@m", "core@m"},
+			"docs":    {"writer@m"},
+		},
+		DisabledGroups: []string{"docs"},
+	}
+}
+
+func TestEffectivePlugins(t *testing.T) {
+	p := groupedProfile()
+	if got := strings.Join(p.EffectivePlugins(), " "); got != "core@m tdd@m" {
+		t.Errorf("Expected core@m tdd@m, got %s", got)
+	}
+	if !p.GroupEnabled("testing") || p.GroupEnabled("docs") || p.GroupEnabled("missing") {
+		t.Error("Unexpected enabled state")
+	}
+
+	resolved := p.ForMachine(ThisMachine())
+	if resolved.Groups != nil || resolved.DisabledGroups != nil || len(resolved.Plugins) != 2 {
+		t.Errorf("Expected groups folded into plugins, got %+v", resolved)
+	}
+	if len(p.Plugins) != 1 {
+		t.Error("ForMachine must not modify the profile")
+	}
+
+	plain := &Profile{Name: "plain"}
+	if plain.ForMachine(ThisMachine()) != plain {
+		t.Error("Expected a profile without groups to be returned as is")
+	}
+}
+
+func TestSetGroupEnabled(t *testing.T) {
+	p := groupedProfile()
+
+	if err := p.SetGroupEnabled("docs", true); err != nil {
+		t.Fatal(err)
+	}
+	if p.DisabledGroups != nil {
+		t.Errorf("Expected no disabled groups, got %v", p.DisabledGroups)
+	}
+	if err := p.SetGroupEnabled("testing", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetGroupEnabled("testing", false); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(p.DisabledGroups, []string{"testing"}) {
+		t.Errorf("Expected testing disabled once, got %v", p.DisabledGroups)
+	}
+
+	if err := p.SetGroupEnabled("lint", true); err == nil || !strings.Contains(err.Error(), "docs, testing") {
+		t.Errorf("Expected error listing the groups, got %v", err)
+	}
+}
+
+func TestGroupsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if err := Save(dir, groupedProfile()); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(dir, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Groups) != 2 || !slices.Equal(loaded.DisabledGroups, []string{"docs"}) {
+		t.Errorf("Groups didn't survive a save, got %+v %v", loaded.Groups, loaded.DisabledGroups)
+	}
+
+	clone := loaded.Clone("copy")
+	clone.Groups["testing"][0] = "changed@m"
+	if loaded.Groups["testing"][0] != "tdd@m" {
+		t.Error("Clone must copy group plugin lists")
+	}
+}
+
+func TestComputeDiffIncludesEnabledGroups(t *testing.T) {
+	claudeDir := t.TempDir()
+	claudeJSON := filepath.Join(t.TempDir(), ".claude.json")
+	os.WriteFile(claudeJSON, []byte(`{}`), 0644)
+
+	diff, err := ComputeDiff(groupedProfile(), claudeDir, claudeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(diff.PluginsToInstall, "tdd@m") || slices.Contains(diff.PluginsToInstall, "writer@m") {
+		t.Errorf("Expected the testing group's plugins and not the docs group's, got %v", diff.PluginsToInstall)
+	}
+}
+
+func TestDriftCountsEnabledGroups(t *testing.T) {
+	current := &Profile{Plugins: []string{"core@m"}}
+	if got := Drift(groupedProfile(), current, &Overrides{}); got != 1 {
+		t.Errorf("Expected the missing tdd@m to count as drift, got %d", got)
+	}
+}
+
+func TestMergeAddsAddonGroups(t *testing.T) {
+	addon := &Profile{
+		Name:           "extras",
+		Type:           TypeAddon,
+		Groups:         map[string][]string{"lint": {"lint@m"}, "off": {"off@m"}},
+		DisabledGroups: []string{"off"},
+	}
+	merged, err := Merge(&Profile{Name: "base"}, addon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(merged.Plugins, []string{"lint@m"}) {
+		t.Errorf("Expected the addon's enabled group plugins, got %v", merged.Plugins)
+	}
+}
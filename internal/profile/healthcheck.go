@@ -0,0 +1,353 @@
+// ABOUTME: Health checks for an applied profile: plugin files, MCP servers, marketplaces, secrets
+// ABOUTME: Each declared item gets a pass, warn, or fail result for 'claudeup profile verify'
+package profile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/cmdline"
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/marketplace"
+	"github.com/claudeup/claudeup/internal/secrets"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// Health check statuses
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+)
+
+// Health check sections, in report order
+const (
+	CheckPlugins      = "Plugins"
+	CheckMCPServers   = "MCP Servers"
+	CheckMarketplaces = "Marketplaces"
+	CheckSecrets      = "Secrets"
+)
+
+// Check is the result of verifying one declared item
+type Check struct {
+	Section string `json:"section"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// pluginJSONFiles are the files in a plugin that must parse when present
+var pluginJSONFiles = []string{
+	filepath.Join(".claude-plugin", "plugin.json"),
+	".mcp.json",
+	filepath.Join("hooks", "hooks.json"),
+}
+
+// mcpInitialize is the JSON-RPC request an MCP server must answer on stdin
+const mcpInitialize = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"claudeup","version":"verify"}}}`
+
+// HealthCheck verifies the profile's installed stack: each plugin's files
+// exist and parse, each MCP server starts and answers initialize within
+// timeout, each marketplace clone is at the commit its plugins were
+// installed from, and each secret resolves
+func (p *Profile) HealthCheck(ctx context.Context, claudeDir string, chain *secrets.Chain, timeout time.Duration) ([]Check, error) {
+	p, err := p.ForThisMachine()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadExisting()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	registry, err := state.LoadPlugins(claudeDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read plugin registry: %w", err)
+	}
+	if registry == nil {
+		registry = &state.PluginRegistry{}
+	}
+	marketplaces, err := state.LoadMarketplaces(claudeDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read marketplace registry: %w", err)
+	}
+
+	var checks []Check
+	for _, name := range p.Plugins {
+		checks = append(checks, checkPlugin(name, registry, cfg))
+	}
+
+	var secretChecks []Check
+	for _, m := range p.MCPServers {
+		resolved := make(map[string]string)
+		for _, envVar := range slices.Sorted(maps.Keys(m.Secrets)) {
+			c, value := checkSecret(ctx, envVar+" for "+m.Name, envVar, m.Secrets[envVar], chain)
+			secretChecks = append(secretChecks, c)
+			if c.Status == CheckPass {
+				resolved[envVar] = value
+			}
+		}
+		checks = append(checks, checkMCPServer(ctx, m, resolved, timeout))
+	}
+
+	for _, m := range p.Marketplaces {
+		checks = append(checks, checkMarketplace(ctx, m.Mirrored(cfg).withAbsPath(), marketplaces, registry, p.Plugins))
+	}
+
+	for _, envVar := range slices.Sorted(maps.Keys(p.ShellEnv.Secrets)) {
+		c, _ := checkSecret(ctx, envVar, envVar, p.ShellEnv.Secrets[envVar], chain)
+		secretChecks = append(secretChecks, c)
+	}
+	for _, s := range p.CheckEnv(chain) {
+		switch {
+		case s.Missing():
+			secretChecks = append(secretChecks, Check{Section: CheckSecrets, Name: s.Name, Status: CheckFail, Detail: "required but not set"})
+		case s.Var.Required:
+			secretChecks = append(secretChecks, Check{Section: CheckSecrets, Name: s.Name, Status: CheckPass, Detail: "from " + s.Source})
+		}
+	}
+
+	return append(checks, secretChecks...), nil
+}
+
+func checkPlugin(name string, registry *state.PluginRegistry, cfg *config.GlobalConfig) Check {
+	c := Check{Section: CheckPlugins, Name: name}
+	installPath, note := "", ""
+	if meta, ok := registry.GetPlugin(name); ok {
+		installPath = meta.InstallPath
+	} else if meta, ok := cfg.GetDisabledPlugin(name); ok {
+		installPath, note = meta.InstallPath, " (disabled)"
+	} else {
+		c.Status, c.Detail = CheckFail, "not installed"
+		return c
+	}
+
+	if installPath == "" {
+		c.Status, c.Detail = CheckFail, "no install path in the registry"
+		return c
+	}
+	if _, err := os.Stat(installPath); err != nil {
+		c.Status, c.Detail = CheckFail, "install path missing: "+installPath
+		return c
+	}
+	for _, file := range pluginJSONFiles {
+		data, err := os.ReadFile(filepath.Join(installPath, file))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err == nil {
+			var v interface{}
+			err = json.Unmarshal(data, &v)
+		}
+		if err != nil {
+			c.Status, c.Detail = CheckFail, fmt.Sprintf("%s: %v", file, err)
+			return c
+		}
+	}
+	c.Status, c.Detail = CheckPass, installPath+note
+	return c
+}
+
+// checkSecret resolves and validates ref, returning the value when it passes.
+// An optional secret that doesn't resolve is a warning.
+func checkSecret(ctx context.Context, label, envVar string, ref SecretRef, chain *secrets.Chain) (Check, string) {
+	c := Check{Section: CheckSecrets, Name: label}
+	value, ok := ResolveSecret(ref, chain)
+	if !ok {
+		c.Status, c.Detail = CheckFail, "could not resolve from any source"
+		if ref.Optional {
+			c.Status, c.Detail = CheckWarn, "optional; not set"
+		}
+		return c, ""
+	}
+	if err := ValidateSecret(ctx, envVar, value, ref.Validate); err != nil {
+		c.Status, c.Detail = CheckFail, err.Error()
+		return c, ""
+	}
+	c.Status, c.Detail = CheckPass, "resolved"
+	return c, value
+}
+
+func checkMCPServer(ctx context.Context, m MCPServer, resolved map[string]string, timeout time.Duration) Check {
+	c := Check{Section: CheckMCPServers, Name: m.Name}
+	path, err := exec.LookPath(m.Command)
+	if err != nil {
+		c.Status, c.Detail = CheckFail, fmt.Sprintf("command %s not found", m.Command)
+		return c
+	}
+	info, err := probeMCPServer(ctx, path, mcpCommandArgs(m, resolved), secretEnv(resolved), timeout)
+	if err != nil {
+		c.Status, c.Detail = CheckFail, err.Error()
+		return c
+	}
+	c.Status, c.Detail = CheckPass, "responded to initialize"
+	if info != "" {
+		c.Detail += " (" + info + ")"
+	}
+	return c
+}
+
+// probeMCPServer starts a stdio MCP server, sends initialize, and waits for
+// the response. It returns the server's reported name and version.
+func probeMCPServer(ctx context.Context, path string, args, env []string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd, err := cmdline.Command(ctx, path, args...)
+	if err != nil {
+		return "", err
+	}
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// A server that spawns children (npx, uvx) can hold the pipes open
+	cmd.WaitDelay = time.Second
+	// stdin stays open until the probe ends; servers exit when it closes
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return "", err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start: %w", err)
+	}
+	_, _ = io.WriteString(stdin, mcpInitialize+"\n")
+
+	type reply struct {
+		info string
+		err  error
+	}
+	replies := make(chan reply, 1)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var resp struct {
+				ID     json.RawMessage `json:"id"`
+				Result *struct {
+					ServerInfo struct {
+						Name    string `json:"name"`
+						Version string `json:"version"`
+					} `json:"serverInfo"`
+				} `json:"result"`
+				Error *struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if json.Unmarshal(scanner.Bytes(), &resp) != nil || string(resp.ID) != "1" {
+				continue // log lines or notifications
+			}
+			switch {
+			case resp.Error != nil:
+				replies <- reply{err: fmt.Errorf("initialize failed: %s", resp.Error.Message)}
+			case resp.Result != nil:
+				replies <- reply{info: strings.TrimSpace(resp.Result.ServerInfo.Name + " " + resp.Result.ServerInfo.Version)}
+			default:
+				continue
+			}
+			return
+		}
+		replies <- reply{err: errors.New("exited before responding to initialize")}
+	}()
+
+	var r reply
+	select {
+	case r = <-replies:
+	case <-ctx.Done():
+		r.err = fmt.Errorf("no response to initialize within %s", timeout)
+	}
+	stdin.Close()
+	cancel()
+	_ = cmd.Wait()
+
+	if r.err != nil {
+		if line := lastNonEmptyLine(stderr.String()); line != "" {
+			return "", fmt.Errorf("%w: %s", r.err, line)
+		}
+		return "", r.err
+	}
+	return r.info, nil
+}
+
+// checkMarketplace finds m's clone and compares its HEAD with the commits
+// the named plugins from it were installed at
+func checkMarketplace(ctx context.Context, m Marketplace, registry state.MarketplaceRegistry, plugins *state.PluginRegistry, pluginNames []string) Check {
+	c := Check{Section: CheckMarketplaces, Name: m.DisplayName()}
+	var name string
+	var meta state.MarketplaceMetadata
+	for n, md := range registry {
+		if RegisteredMarketplace(md.Source).DisplayName() == m.DisplayName() {
+			name, meta = n, md
+			break
+		}
+	}
+	if name == "" {
+		c.Status, c.Detail = CheckFail, "not registered with Claude"
+		return c
+	}
+
+	dir := meta.InstallLocation
+	if dir == "" {
+		dir = meta.Source.Path
+	}
+	if _, err := os.Stat(dir); err != nil {
+		c.Status, c.Detail = CheckFail, "clone missing: "+dir
+		return c
+	}
+
+	switch m.Source {
+	case MarketplaceLocal:
+		c.Status, c.Detail = CheckPass, dir
+		return c
+	case MarketplaceArchive:
+		a, ok := marketplace.ReadArchiveSource(dir)
+		if !ok || (m.SHA256 != "" && !strings.EqualFold(a.SHA256, m.SHA256)) {
+			c.Status, c.Detail = CheckFail, "unpacked archive doesn't match the profile's sha256"
+			return c
+		}
+		c.Status, c.Detail = CheckPass, "sha256 "+shortSHA(a.SHA256)
+		return c
+	}
+
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		c.Status, c.Detail = CheckFail, "not a git clone: "+dir
+		return c
+	}
+	head := strings.TrimSpace(string(out))
+
+	for _, pluginName := range pluginNames {
+		if !strings.HasSuffix(pluginName, "@"+name) {
+			continue
+		}
+		meta, _ := plugins.GetPlugin(pluginName)
+		if sha := meta.GitCommitSha; sha != "" && !strings.HasPrefix(head, sha) && !strings.HasPrefix(sha, head) {
+			c.Status = CheckFail
+			c.Detail = fmt.Sprintf("clone at %s but %s was installed from %s; run 'claudeup update'", shortSHA(head), pluginName, shortSHA(meta.GitCommitSha))
+			return c
+		}
+	}
+	c.Status, c.Detail = CheckPass, "at "+shortSHA(head)
+	return c
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,195 @@
+// ABOUTME: Tests for profile health checks
+// ABOUTME: Covers plugin file parsing, MCP initialize probes, marketplace commits, and secrets
+package profile
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/secrets"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+func TestCheckPlugin(t *testing.T) {
+	tmpDir := t.TempDir()
+	good := filepath.Join(tmpDir, "good")
+	bad := filepath.Join(tmpDir, "bad")
+	os.MkdirAll(filepath.Join(good, ".claude-plugin"), 0755)
+	os.MkdirAll(filepath.Join(bad, "hooks"), 0755)
+	os.WriteFile(filepath.Join(good, ".claude-plugin", "plugin.json"), []byte(`{"name":"good"}`), 0644)
+	os.WriteFile(filepath.Join(bad, "hooks", "hooks.json"), []byte(`{"hooks":`), 0644)
+
+	registry := &state.PluginRegistry{Plugins: map[string][]state.PluginMetadata{
+		"good@m": {{Scope: "user", InstallPath: good}},
+		"bad@m":  {{Scope: "user", InstallPath: bad}},
+		"gone@m": {{Scope: "user", InstallPath: filepath.Join(tmpDir, "gone")}},
+	}}
+	cfg := &config.GlobalConfig{}
+
+	tests := []struct {
+		name   string
+		status string
+		detail string
+	}{
+		{"good@m", CheckPass, good},
+		{"bad@m", CheckFail, "hooks.json"},
+		{"gone@m", CheckFail, "install path missing"},
+		{"missing@m", CheckFail, "not installed"},
+	}
+	for _, tt := range tests {
+		c := checkPlugin(tt.name, registry, cfg)
+		if c.Status != tt.status || !strings.Contains(c.Detail, tt.detail) {
+			t.Errorf("checkPlugin(%s) = %s %q, want %s containing %q", tt.name, c.Status, c.Detail, tt.status, tt.detail)
+		}
+	}
+}
+
+func writeMCPScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "server")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheckMCPServer(t *testing.T) {
+	ctx := context.Background()
+
+	ok := writeMCPScript(t, `read line
+echo "starting" >&2
+echo '{"jsonrpc":"2.0","method":"notifications/message"}'
+echo '{"jsonrpc":"2.0","id":1,"result":{"serverInfo":{"name":"demo","version":"1.2.0"}}}'
+`)
+	c := checkMCPServer(ctx, MCPServer{Name: "demo", Command: ok}, nil, 5*time.Second)
+	if c.Status != CheckPass || !strings.Contains(c.Detail, "demo 1.2.0") {
+		t.Errorf("working server = %s %q", c.Status, c.Detail)
+	}
+
+	crash := writeMCPScript(t, `echo "missing API key" >&2
+exit 1
+`)
+	c = checkMCPServer(ctx, MCPServer{Name: "crash", Command: crash}, nil, 5*time.Second)
+	if c.Status != CheckFail || !strings.Contains(c.Detail, "missing API key") {
+		t.Errorf("crashing server = %s %q", c.Status, c.Detail)
+	}
+
+	hang := writeMCPScript(t, `sleep 30
+`)
+	c = checkMCPServer(ctx, MCPServer{Name: "hang", Command: hang}, nil, 200*time.Millisecond)
+	if c.Status != CheckFail || !strings.Contains(c.Detail, "no response") {
+		t.Errorf("hanging server = %s %q", c.Status, c.Detail)
+	}
+
+	c = checkMCPServer(ctx, MCPServer{Name: "none", Command: "claudeup-no-such-server"}, nil, time.Second)
+	if c.Status != CheckFail || !strings.Contains(c.Detail, "not found") {
+		t.Errorf("missing command = %s %q", c.Status, c.Detail)
+	}
+}
+
+func TestCheckMCPServerPassesSecrets(t *testing.T) {
+	server := writeMCPScript(t, `read line
+if [ "$1" != "--token=s3cret" ] || [ "$API_TOKEN" != "s3cret" ]; then echo "bad token" >&2; exit 1; fi
+echo '{"jsonrpc":"2.0","id":1,"result":{}}'
+`)
+	m := MCPServer{Name: "api", Command: server, Args: []string{"--token=s3cret"}}
+	c := checkMCPServer(context.Background(), m, map[string]string{"API_TOKEN": "s3cret"}, 5*time.Second)
+	if c.Status != CheckPass {
+		t.Errorf("status = %s %q, want pass", c.Status, c.Detail)
+	}
+}
+
+func TestCheckMarketplaceCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	clone := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", clone, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "one")
+	first := git("rev-parse", "HEAD")
+	git("commit", "-q", "--allow-empty", "-m", "two")
+
+	registry := state.MarketplaceRegistry{"tools": {
+		Source:          state.MarketplaceSource{Source: "github", Repo: "org/tools"},
+		InstallLocation: clone,
+	}}
+	plugins := &state.PluginRegistry{Plugins: map[string][]state.PluginMetadata{
+		"lint@tools": {{Scope: "user", GitCommitSha: first}},
+	}}
+	m := Marketplace{Source: "github", Repo: "org/tools"}
+
+	c := checkMarketplace(context.Background(), m, registry, plugins, []string{"lint@tools"})
+	if c.Status != CheckFail || !strings.Contains(c.Detail, "claudeup update") {
+		t.Errorf("stale plugin = %s %q, want fail", c.Status, c.Detail)
+	}
+
+	plugins.Plugins["lint@tools"][0].GitCommitSha = git("rev-parse", "HEAD")
+	c = checkMarketplace(context.Background(), m, registry, plugins, []string{"lint@tools"})
+	if c.Status != CheckPass {
+		t.Errorf("current plugin = %s %q, want pass", c.Status, c.Detail)
+	}
+
+	c = checkMarketplace(context.Background(), Marketplace{Source: "github", Repo: "org/other"}, registry, plugins, nil)
+	if c.Status != CheckFail || !strings.Contains(c.Detail, "not registered") {
+		t.Errorf("unregistered marketplace = %s %q", c.Status, c.Detail)
+	}
+}
+
+func TestHealthCheckSecrets(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("VERIFY_SET", "value")
+	claudeDir := filepath.Join(tmpDir, ".claude")
+
+	p := &Profile{
+		Name: "test",
+		Env: map[string]EnvVar{
+			"VERIFY_REQUIRED": {Required: true},
+			"VERIFY_OPTIONAL": {},
+		},
+		ShellEnv: ShellEnvConfig{Secrets: map[string]SecretRef{
+			"VERIFY_SET":      {Sources: []SecretSource{{Type: "env", Key: "VERIFY_SET"}}},
+			"VERIFY_UNSET":    {Sources: []SecretSource{{Type: "env", Key: "VERIFY_UNSET"}}},
+			"VERIFY_OPTIONAL": {Sources: []SecretSource{{Type: "env", Key: "VERIFY_OPTIONAL"}}, Optional: true},
+		}},
+	}
+	chain := secrets.NewChain(secrets.NewEnvResolver())
+
+	checks, err := p.HealthCheck(context.Background(), claudeDir, chain, time.Second)
+	if err != nil {
+		t.Fatalf("HealthCheck: %v", err)
+	}
+	got := make(map[string]string)
+	for _, c := range checks {
+		if c.Section != CheckSecrets {
+			t.Errorf("unexpected %s check %s", c.Section, c.Name)
+		}
+		got[c.Name] = c.Status
+	}
+	want := map[string]string{
+		"VERIFY_SET":      CheckPass,
+		"VERIFY_UNSET":    CheckFail,
+		"VERIFY_OPTIONAL": CheckWarn,
+		"VERIFY_REQUIRED": CheckFail,
+	}
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s = %q, want %q", name, got[name], status)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Breaks a Diff into individual changes so each can be accepted or declined
+// ABOUTME: Used by 'profile use --interactive' to build a diff from per-item answers
+package profile
+
+import "fmt"
+
+// DiffItem is a single planned change
+type DiffItem struct {
+	Action    string // remove, install, add, disable, enable
+	Subsystem Subsystem
+	Name      string
+}
+
+// String describes the change, e.g. "install plugin tdd@superpowers"
+func (i DiffItem) String() string {
+	return fmt.Sprintf("%s %s", i.Action, i.Target())
+}
+
+// Target names what the change applies to, e.g. "plugin tdd@superpowers"
+func (i DiffItem) Target() string {
+	noun := "plugin"
+	switch i.Subsystem {
+	case SubsystemMCP:
+		noun = "MCP server"
+	case SubsystemMarketplaces:
+		noun = "marketplace"
+	}
+	return noun + " " + i.Name
+}
+
+// Items lists every change in the order it would be applied
+func (d *Diff) Items() []DiffItem {
+	var items []DiffItem
+	add := func(action string, sub Subsystem, names ...string) {
+		for _, name := range names {
+			items = append(items, DiffItem{Action: action, Subsystem: sub, Name: name})
+		}
+	}
+
+	add("remove", SubsystemPlugins, d.PluginsToRemove...)
+	add("remove", SubsystemMCP, d.MCPToRemove...)
+	for _, m := range d.MarketplacesToAdd {
+		add("add", SubsystemMarketplaces, m.DisplayName())
+	}
+	add("install", SubsystemPlugins, d.PluginsToInstall...)
+	for _, m := range d.MCPToInstall {
+		add("install", SubsystemMCP, m.Name)
+	}
+	add("disable", SubsystemPlugins, d.PluginsToDisable...)
+	add("disable", SubsystemMCP, d.MCPToDisable...)
+	add("enable", SubsystemPlugins, d.PluginsToEnable...)
+	add("enable", SubsystemMCP, d.MCPToEnable...)
+	return items
+}
+
+// Select returns a copy of d containing only the items keep accepts
+func (d *Diff) Select(keep func(DiffItem) bool) *Diff {
+	kept := make(map[DiffItem]bool)
+	for _, item := range d.Items() {
+		if keep(item) {
+			kept[item] = true
+		}
+	}
+
+	names := func(action string, sub Subsystem, all []string) []string {
+		var out []string
+		for _, name := range all {
+			if kept[DiffItem{Action: action, Subsystem: sub, Name: name}] {
+				out = append(out, name)
+			}
+		}
+		return out
+	}
+
+	selected := &Diff{
+		PluginsToRemove:  names("remove", SubsystemPlugins, d.PluginsToRemove),
+		PluginsToInstall: names("install", SubsystemPlugins, d.PluginsToInstall),
+		MCPToRemove:      names("remove", SubsystemMCP, d.MCPToRemove),
+		PluginsToDisable: names("disable", SubsystemPlugins, d.PluginsToDisable),
+		PluginsToEnable:  names("enable", SubsystemPlugins, d.PluginsToEnable),
+		MCPToDisable:     names("disable", SubsystemMCP, d.MCPToDisable),
+		MCPToEnable:      names("enable", SubsystemMCP, d.MCPToEnable),
+		Skipped:          d.Skipped,
+		InstalledPlugins: d.InstalledPlugins,
+	}
+	for _, m := range d.MCPToInstall {
+		if kept[DiffItem{Action: "install", Subsystem: SubsystemMCP, Name: m.Name}] {
+			selected.MCPToInstall = append(selected.MCPToInstall, m)
+		}
+	}
+	for _, m := range d.MarketplacesToAdd {
+		if kept[DiffItem{Action: "add", Subsystem: SubsystemMarketplaces, Name: m.DisplayName()}] {
+			selected.MarketplacesToAdd = append(selected.MarketplacesToAdd, m)
+		}
+	}
+	return selected
+}
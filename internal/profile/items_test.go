@@ -0,0 +1,59 @@
+// ABOUTME: Unit tests for splitting a Diff into individually confirmable changes
+// ABOUTME: Covers item ordering and rebuilding a diff from accepted items
+package profile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffItems(t *testing.T) {
+	diff := &Diff{
+		PluginsToRemove:   []string{"old@m"},
+		PluginsToInstall:  []string{"new@m"},
+		MCPToInstall:      []MCPServer{{Name: "db", Command: "pg-mcp"}},
+		MarketplacesToAdd: []Marketplace{{Source: "github", Repo: "org/m"}},
+		MCPToDisable:      []string{"new@m:figma"},
+	}
+
+	var got []string
+	for _, item := range diff.Items() {
+		got = append(got, item.String())
+	}
+	want := []string{
+		"remove plugin old@m",
+		"add marketplace org/m",
+		"install plugin new@m",
+		"install MCP server db",
+		"disable MCP server new@m:figma",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Items() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffSelect(t *testing.T) {
+	diff := &Diff{
+		PluginsToRemove:   []string{"old@m", "keep@m"},
+		PluginsToInstall:  []string{"new@m"},
+		MCPToInstall:      []MCPServer{{Name: "db", Command: "pg-mcp"}, {Name: "cache", Command: "redis-mcp"}},
+		MarketplacesToAdd: []Marketplace{{Source: "github", Repo: "org/m"}},
+	}
+
+	selected := diff.Select(func(item DiffItem) bool {
+		return item.Name != "keep@m" && item.Name != "cache"
+	})
+
+	if !reflect.DeepEqual(selected.PluginsToRemove, []string{"old@m"}) {
+		t.Errorf("Unexpected removals %v", selected.PluginsToRemove)
+	}
+	if len(selected.MCPToInstall) != 1 || selected.MCPToInstall[0].Command != "pg-mcp" {
+		t.Errorf("Expected db with its definition, got %+v", selected.MCPToInstall)
+	}
+	if selected.Count() != 4 {
+		t.Errorf("Expected 4 changes, got %d", selected.Count())
+	}
+	if diff.Select(func(DiffItem) bool { return false }).Count() != 0 {
+		t.Error("Declining everything should leave an empty diff")
+	}
+}
@@ -0,0 +1,144 @@
+// ABOUTME: A profile's history of changes, from git when its file is committed or from the versions kept on Save
+// ABOUTME: Each revision lists the plugins, MCP servers, and marketplaces it added and removed
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Where a profile's log comes from
+const (
+	LogSourceGit      = "git"
+	LogSourceVersions = "versions"
+)
+
+// Revision is one version of a profile and what changed since the one before
+type Revision struct {
+	ID      string           `json:"id"` // git commit, the saved copy's timestamp, or "current"
+	Time    time.Time        `json:"time"`
+	Author  string           `json:"author,omitempty"`
+	Message string           `json:"message,omitempty"`
+	Changes []RevisionChange `json:"changes"`
+}
+
+// RevisionChange is an item a revision added or removed
+type RevisionChange struct {
+	Action string `json:"action"` // "add" or "remove"
+	Kind   string `json:"kind"`   // "plugin", "mcp", or "marketplace"
+	Name   string `json:"name"`
+}
+
+// version is one profile as it was at some point
+type version struct {
+	id, author, message string
+	time                time.Time
+	profile             *Profile
+}
+
+// Log returns the named profile's revisions, newest first, and where they
+// came from: git when the profile's file is committed to a repository,
+// otherwise the versions kept each time it was saved over followed by its
+// current file
+func Log(profilesDir, name string) ([]Revision, string, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, "", err
+	}
+	versions, err := gitVersions(filepath.Join(profilesDir, filepath.FromSlash(name)+".json"))
+	source := LogSourceGit
+	if err != nil || len(versions) == 0 {
+		source = LogSourceVersions
+		if versions, err = savedVersions(profilesDir, name); err != nil {
+			return nil, "", err
+		}
+	}
+
+	revisions := make([]Revision, 0, len(versions))
+	previous := &Profile{}
+	for _, v := range versions {
+		revisions = append(revisions, Revision{
+			ID:      v.id,
+			Time:    v.time,
+			Author:  v.author,
+			Message: v.message,
+			Changes: revisionChanges(previous, v.profile),
+		})
+		previous = v.profile
+	}
+	for i, j := 0, len(revisions)-1; i < j; i, j = i+1, j-1 {
+		revisions[i], revisions[j] = revisions[j], revisions[i]
+	}
+	return revisions, source, nil
+}
+
+// gitVersions reads every committed version of the profile file at path,
+// oldest first. It fails when path isn't in a git repository.
+func gitVersions(path string) ([]version, error) {
+	dir, file := filepath.Dir(path), filepath.Base(path)
+	out, err := exec.Command("git", "-C", dir, "log", "--reverse", "--format=%H%x1f%an%x1f%aI%x1f%s", "--", file).Output()
+	if err != nil {
+		return nil, err
+	}
+	var versions []version
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		content, err := exec.Command("git", "-C", dir, "show", fields[0]+":./"+file).Output()
+		if err != nil {
+			// Deleted in this commit
+			content = []byte("{}")
+		}
+		var p Profile
+		if json.Unmarshal(bytes.TrimSpace(content), &p) != nil {
+			continue
+		}
+		t, _ := time.Parse(time.RFC3339, fields[2])
+		versions = append(versions, version{id: fields[0][:12], author: fields[1], time: t, message: fields[3], profile: &p})
+	}
+	return versions, nil
+}
+
+// revisionChanges lists the items to has and from doesn't as added, and
+// the reverse as removed
+func revisionChanges(from, to *Profile) []RevisionChange {
+	changes := []RevisionChange{}
+	diffNames := func(kind string, before, after []string) {
+		was, is := toSet(before), toSet(after)
+		for _, name := range after {
+			if _, ok := was[name]; !ok {
+				changes = append(changes, RevisionChange{Action: "add", Kind: kind, Name: name})
+			}
+		}
+		for _, name := range before {
+			if _, ok := is[name]; !ok {
+				changes = append(changes, RevisionChange{Action: "remove", Kind: kind, Name: name})
+			}
+		}
+	}
+	diffNames("plugin", from.EffectivePlugins(), to.EffectivePlugins())
+	diffNames("mcp", mcpNames(from), mcpNames(to))
+	diffNames("marketplace", marketplaceKeys(from), marketplaceKeys(to))
+	return changes
+}
+
+func mcpNames(p *Profile) []string {
+	var names []string
+	for _, m := range p.MCPServers {
+		names = appendMissing(names, m.Name)
+	}
+	return names
+}
+
+func marketplaceKeys(p *Profile) []string {
+	var keys []string
+	for _, m := range p.Marketplaces {
+		keys = appendMissing(keys, m.DisplayName())
+	}
+	return keys
+}
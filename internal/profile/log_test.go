@@ -0,0 +1,108 @@
+// ABOUTME: Tests for a profile's log of revisions
+// ABOUTME: Covers versions kept on Save, reading git history, and what each revision changed
+package profile
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+)
+
+func TestLogFromSavedVersions(t *testing.T) {
+	paths := pathctx.ForHome(t.TempDir())
+	defer pathctx.Override(paths)()
+
+	p := &Profile{Name: "backend", Plugins: []string{"a@m", "b@m"}}
+	if err := Save(paths.ProfilesDir, p); err != nil {
+		t.Fatal(err)
+	}
+	p.Plugins = []string{"b@m", "c@m"}
+	p.MCPServers = []MCPServer{{Name: "ctx", Command: "npx"}}
+	if err := Save(paths.ProfilesDir, p); err != nil {
+		t.Fatal(err)
+	}
+	// The record of applied profiles is saved too, but has no history
+	if err := SaveRecord(paths.Claudeup("applied"), p); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveRecord(paths.Claudeup("applied"), p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(paths.Claudeup("applied", ".versions")); !os.IsNotExist(err) {
+		t.Errorf("Expected no versions of the applied record, got %v", err)
+	}
+
+	revisions, source, err := Log(paths.ProfilesDir, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != LogSourceVersions || len(revisions) != 2 || revisions[0].ID != "current" {
+		t.Fatalf("Expected the current file and 1 kept version, got %d from %s", len(revisions), source)
+	}
+	want := []RevisionChange{
+		{Action: "add", Kind: "plugin", Name: "c@m"},
+		{Action: "remove", Kind: "plugin", Name: "a@m"},
+		{Action: "add", Kind: "mcp", Name: "ctx"},
+	}
+	if !reflect.DeepEqual(revisions[0].Changes, want) {
+		t.Errorf("Expected the newest revision first with %v, got %v", want, revisions[0].Changes)
+	}
+	if len(revisions[1].Changes) != 2 || revisions[1].Changes[0].Action != "add" {
+		t.Errorf("Expected the first revision to add everything, got %v", revisions[1].Changes)
+	}
+}
+
+func TestLogFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	paths := pathctx.ForHome(t.TempDir())
+	defer pathctx.Override(paths)()
+	dir := paths.ProfilesDir
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Dana", "-c", "user.email=dana@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "backend.json"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	write(`{"name": "backend", "plugins": ["a@m"]}`)
+	git("add", "backend.json")
+	git("commit", "-q", "-m", "Add backend")
+	write(`{"name": "backend", "plugins": ["a@m"], "groups": {"testing": ["tdd@m"]}, "marketplaces": [{"source": "github", "repo": "acme/m"}]}`)
+	git("commit", "-q", "-am", "Add testing group")
+
+	revisions, source, err := Log(dir, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != LogSourceGit || len(revisions) != 2 {
+		t.Fatalf("Expected 2 commits from git, got %d from %s", len(revisions), source)
+	}
+	latest := revisions[0]
+	if latest.Message != "Add testing group" || latest.Author != "Dana" || len(latest.ID) != 12 || latest.Time.IsZero() {
+		t.Errorf("Unexpected commit details %+v", latest)
+	}
+	want := []RevisionChange{
+		{Action: "add", Kind: "plugin", Name: "tdd@m"},
+		{Action: "add", Kind: "marketplace", Name: "acme/m"},
+	}
+	if !reflect.DeepEqual(latest.Changes, want) {
+		t.Errorf("Expected %v, got %v", want, latest.Changes)
+	}
+}
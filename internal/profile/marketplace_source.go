@@ -0,0 +1,76 @@
+// ABOUTME: Local-directory and archive marketplace sources, alongside github and git
+// ABOUTME: Maps them to and from Claude's "directory" registry entries and the claude CLI
+package profile
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/marketplace"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// RegisteredMarketplace converts a source from known_marketplaces.json. A
+// directory that claudeup unpacked from an archive maps back to the archive.
+func RegisteredMarketplace(src state.MarketplaceSource) Marketplace {
+	if src.Source == "directory" {
+		if a, ok := marketplace.ReadArchiveSource(src.Path); ok {
+			return Marketplace{Source: MarketplaceArchive, URL: a.URL, SHA256: a.SHA256}
+		}
+		return Marketplace{Source: MarketplaceLocal, Path: src.Path}
+	}
+	return Marketplace{Source: src.Source, Repo: src.Repo, URL: src.URL}
+}
+
+// withAbsPath returns a local marketplace with its path made absolute,
+// expanding ~ and resolving relative paths against the working directory
+func (m Marketplace) withAbsPath() Marketplace {
+	if m.Source != MarketplaceLocal || m.Path == "" {
+		return m
+	}
+	p := m.Path
+	if pathctx.HomeRelative(p) {
+		p = pathctx.ExpandHome(p, MustHomeDir())
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	m.Path = p
+	return m
+}
+
+// archiveDir is where an archive marketplace is unpacked
+func archiveDir(url string) string {
+	name := filepath.Base(url)
+	for _, ext := range []string{".tar.gz", ".tgz"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	sum := sha256.Sum256([]byte(url))
+	return mustBeAbs(pathctx.Default().Claudeup("marketplaces", name+"-"+hex.EncodeToString(sum[:4])))
+}
+
+// marketplaceAddArg returns what to pass to 'claude plugin marketplace add'
+// for m, unpacking archive marketplaces first
+func marketplaceAddArg(ctx context.Context, m Marketplace) (string, error) {
+	switch m.Source {
+	case MarketplaceLocal:
+		m = m.withAbsPath()
+		if info, err := os.Stat(m.Path); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("local marketplace %s is not a directory", m.Path)
+		}
+		return m.Path, nil
+	case MarketplaceArchive:
+		dir := archiveDir(m.URL)
+		if err := marketplace.InstallArchive(ctx, marketplace.ArchiveSource{URL: m.URL, SHA256: m.SHA256}, dir); err != nil {
+			return "", err
+		}
+		return dir, nil
+	}
+	return m.DisplayName(), nil
+}
@@ -0,0 +1,242 @@
+// ABOUTME: Merges addon profiles into a base profile for 'profile use base +addon'
+// ABOUTME: Addons only add items; conflicting definitions are reported, not resolved
+package profile
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// TypeAddon marks a profile that only adds items and never triggers removals
+const TypeAddon = "addon"
+
+// IsAddon reports whether the profile is an addon
+func (p *Profile) IsAddon() bool {
+	return p.Type == TypeAddon
+}
+
+// Conflict describes two profiles defining the same item differently
+type Conflict struct {
+	Kind     string // "mcp" or "env"
+	Name     string
+	Profiles [2]string
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s %q is defined differently by %s and %s", c.Kind, c.Name, c.Profiles[0], c.Profiles[1])
+}
+
+// ConflictError is returned when merged profiles disagree
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	lines := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		lines[i] = "  " + c.String()
+	}
+	return "profiles conflict:\n" + strings.Join(lines, "\n")
+}
+
+// Merge combines base with addons. The result has base's name, detection,
+// and sandbox settings, and the union of plugins, marketplaces, MCP servers,
+// disabled items, permissions, shell environment, and env manifest (the
+// first definition of a manifest variable wins). Addon plugins and groups keep
+// their conditions. A nil base merges addons alone and the
+// result is an addon, so applying it never removes anything.
+func Merge(base *Profile, addons ...*Profile) (*Profile, error) {
+	var merged *Profile
+	if base != nil {
+		merged = base.Clone(base.Name)
+	} else {
+		merged = &Profile{Type: TypeAddon}
+	}
+
+	var names []string
+	if base != nil {
+		names = append(names, base.Name)
+	}
+
+	// Track which profile contributed each MCP server and env var
+	mcpOwner := make(map[string]string)
+	for _, m := range merged.MCPServers {
+		mcpOwner[m.Name] = merged.Name
+	}
+	envOwner := make(map[string]string)
+	for k := range merged.ShellEnv.Env {
+		envOwner[k] = merged.Name
+	}
+
+	var conflicts []Conflict
+	for _, addon := range addons {
+		names = append(names, addon.Name)
+
+		// An addon's enabled groups are part of what it adds. Their
+		// conditions come along, so ForMachine still leaves out what this
+		// machine doesn't match.
+		mergePlugins(merged, addon, addon.Plugins, &merged.Plugins)
+		mergePlugins(merged, addon, addon.Disabled.Plugins, &merged.Disabled.Plugins)
+		for _, name := range addon.GroupNames() {
+			if !addon.GroupEnabled(name) {
+				continue
+			}
+			c, ok := addon.GroupConditions[name]
+			if !ok || c.IsZero() {
+				mergePlugins(merged, addon, addon.Groups[name], &merged.Plugins)
+				continue
+			}
+			group := name
+			if _, taken := merged.Groups[group]; taken {
+				group = addon.Name + ":" + name
+			}
+			var plugins []string
+			mergePlugins(merged, addon, addon.Groups[name], &plugins)
+			if merged.Groups == nil {
+				merged.Groups = make(map[string][]string)
+			}
+			if merged.GroupConditions == nil {
+				merged.GroupConditions = make(map[string]Condition)
+			}
+			merged.Groups[group] = plugins
+			merged.GroupConditions[group] = c.clone()
+		}
+		merged.Disabled.MCPServers = appendMissing(merged.Disabled.MCPServers, addon.Disabled.MCPServers...)
+
+		for _, m := range addon.Marketplaces {
+			if !hasMarketplace(merged.Marketplaces, m) {
+				merged.Marketplaces = append(merged.Marketplaces, m)
+			}
+		}
+
+		for _, m := range addon.MCPServers {
+			i := mcpIndex(merged.MCPServers, m.Name)
+			if i < 0 {
+				merged.MCPServers = append(merged.MCPServers, m)
+				mcpOwner[m.Name] = addon.Name
+				continue
+			}
+			if !reflect.DeepEqual(merged.MCPServers[i], m) {
+				conflicts = append(conflicts, Conflict{Kind: "mcp", Name: m.Name, Profiles: [2]string{mcpOwner[m.Name], addon.Name}})
+			}
+		}
+
+		for k, v := range addon.ShellEnv.Env {
+			existing, ok := merged.ShellEnv.Env[k]
+			if !ok {
+				if merged.ShellEnv.Env == nil {
+					merged.ShellEnv.Env = make(map[string]string)
+				}
+				merged.ShellEnv.Env[k] = v
+				envOwner[k] = addon.Name
+			} else if existing != v {
+				conflicts = append(conflicts, Conflict{Kind: "env", Name: k, Profiles: [2]string{envOwner[k], addon.Name}})
+			}
+		}
+		for k, v := range addon.Env {
+			if _, ok := merged.Env[k]; !ok {
+				if merged.Env == nil {
+					merged.Env = make(map[string]EnvVar)
+				}
+				merged.Env[k] = v
+			}
+		}
+		for k, v := range addon.ShellEnv.Secrets {
+			if _, ok := merged.ShellEnv.Secrets[k]; !ok {
+				if merged.ShellEnv.Secrets == nil {
+					merged.ShellEnv.Secrets = make(map[string]SecretRef)
+				}
+				merged.ShellEnv.Secrets[k] = v
+			}
+		}
+		merged.Permissions.Allow = appendMissing(merged.Permissions.Allow, addon.Permissions.Allow...)
+		merged.Permissions.AdditionalDirectories = appendMissing(merged.Permissions.AdditionalDirectories, addon.Permissions.AdditionalDirectories...)
+		merged.Permissions.TrustProject = merged.Permissions.TrustProject || addon.Permissions.TrustProject
+
+		// Wizard categories are offered together; the first definition of a name wins
+		if addon.SetupWizard != nil {
+			if merged.SetupWizard == nil {
+				merged.SetupWizard = &SetupWizard{Prompt: addon.SetupWizard.Prompt}
+			}
+			for _, c := range addon.SetupWizard.clone().Categories {
+				if !slices.ContainsFunc(merged.SetupWizard.Categories, func(m WizardCategory) bool { return strings.EqualFold(m.Name, c.Name) }) {
+					merged.SetupWizard.Categories = append(merged.SetupWizard.Categories, c)
+				}
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, &ConflictError{Conflicts: conflicts}
+	}
+
+	if base == nil {
+		merged.Name = strings.Join(names, "+")
+	}
+	return merged, nil
+}
+
+// mergePlugins appends an addon's plugins to list with their conditions.
+// A plugin applies wherever a profile lists it for the machine, so one
+// listed anywhere without a condition keeps none; when two profiles limit
+// it differently, the first condition is kept.
+func mergePlugins(merged, addon *Profile, plugins []string, list *[]string) {
+	for _, plugin := range plugins {
+		c := addon.PluginConditions[plugin]
+		_, conditioned := merged.PluginConditions[plugin]
+		switch {
+		case c.IsZero():
+			delete(merged.PluginConditions, plugin)
+		case !conditioned && !merged.lists(plugin):
+			if merged.PluginConditions == nil {
+				merged.PluginConditions = make(map[string]Condition)
+			}
+			merged.PluginConditions[plugin] = c.clone()
+		}
+		*list = appendMissing(*list, plugin)
+	}
+}
+
+// lists reports whether p names plugin anywhere, in a group or not
+func (p *Profile) lists(plugin string) bool {
+	if slices.Contains(p.Plugins, plugin) || slices.Contains(p.Disabled.Plugins, plugin) {
+		return true
+	}
+	for _, plugins := range p.Groups {
+		if slices.Contains(plugins, plugin) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendMissing(list []string, items ...string) []string {
+	seen := toSet(list)
+	for _, item := range items {
+		if _, ok := seen[item]; !ok {
+			list = append(list, item)
+			seen[item] = struct{}{}
+		}
+	}
+	return list
+}
+
+func hasMarketplace(list []Marketplace, m Marketplace) bool {
+	for _, existing := range list {
+		if existing.DisplayName() == m.DisplayName() {
+			return true
+		}
+	}
+	return false
+}
+
+func mcpIndex(list []MCPServer, name string) int {
+	for i, m := range list {
+		if m.Name == name {
+			return i
+		}
+	}
+	return -1
+}
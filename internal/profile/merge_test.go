@@ -0,0 +1,129 @@
+// ABOUTME: Tests for merging addon profiles into a base profile
+// ABOUTME: Covers unions, duplicate handling, conflicts, and add-only diffs
+package profile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestMergeUnionsItems(t *testing.T) {
+	base := &Profile{
+		Name:         "base",
+		Plugins:      []string{"a@m", "b@m"},
+		Marketplaces: []Marketplace{{Source: "github", Repo: "org/m"}},
+		MCPServers:   []MCPServer{{Name: "ctx", Command: "npx", Args: []string{"ctx"}}},
+	}
+	security := &Profile{
+		Name:         "security",
+		Type:         TypeAddon,
+		Plugins:      []string{"b@m", "scan@sec"},
+		Marketplaces: []Marketplace{{Source: "github", Repo: "org/m"}, {Source: "github", Repo: "org/sec"}},
+		MCPServers:   []MCPServer{{Name: "ctx", Command: "npx", Args: []string{"ctx"}}, {Name: "vault", Command: "vault-mcp"}},
+	}
+
+	merged, err := Merge(base, security)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Name != "base" || merged.IsAddon() {
+		t.Errorf("Expected full profile named base, got %q type %q", merged.Name, merged.Type)
+	}
+	if len(merged.Plugins) != 3 || len(merged.Marketplaces) != 2 || len(merged.MCPServers) != 2 {
+		t.Errorf("Unexpected merge result: plugins %v, marketplaces %v, mcp %v", merged.Plugins, merged.Marketplaces, merged.MCPServers)
+	}
+	if len(base.Plugins) != 2 {
+		t.Error("Merge must not modify the base profile")
+	}
+}
+
+func TestMergeReportsConflicts(t *testing.T) {
+	base := &Profile{Name: "base", MCPServers: []MCPServer{{Name: "db", Command: "pg-mcp"}}}
+	data := &Profile{Name: "data", Type: TypeAddon, MCPServers: []MCPServer{{Name: "db", Command: "mysql-mcp"}}}
+
+	_, err := Merge(base, data)
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected ConflictError, got %v", err)
+	}
+	c := conflictErr.Conflicts[0]
+	if c.Kind != "mcp" || c.Name != "db" || c.Profiles != [2]string{"base", "data"} {
+		t.Errorf("Unexpected conflict %+v", c)
+	}
+}
+
+func TestAddonDiffNeverRemoves(t *testing.T) {
+	tmpDir := t.TempDir()
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	pluginsDir := filepath.Join(claudeDir, "plugins")
+	os.MkdirAll(pluginsDir, 0755)
+	writeTestJSON(t, filepath.Join(pluginsDir, "installed_plugins.json"), map[string]interface{}{
+		"version": 2,
+		"plugins": map[string]interface{}{
+			"existing@m": []map[string]interface{}{{"scope": "user"}},
+		},
+	})
+	writeTestJSON(t, filepath.Join(pluginsDir, "known_marketplaces.json"), map[string]interface{}{})
+	writeTestJSON(t, filepath.Join(tmpDir, ".claude.json"), map[string]interface{}{
+		"mcpServers": map[string]interface{}{"existing": map[string]interface{}{"command": "x"}},
+	})
+
+	merged, err := Merge(nil, &Profile{Name: "security", Type: TypeAddon, Plugins: []string{"scan@sec"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merged.IsAddon() || merged.Name != "security" {
+		t.Errorf("Expected addon named security, got %+v", merged)
+	}
+
+	diff, err := ComputeDiff(merged, claudeDir, filepath.Join(tmpDir, ".claude.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.PluginsToRemove) != 0 || len(diff.MCPToRemove) != 0 {
+		t.Errorf("Addon must not remove anything, got %+v", diff)
+	}
+	if len(diff.PluginsToInstall) != 1 {
+		t.Errorf("Expected 1 plugin to install, got %v", diff.PluginsToInstall)
+	}
+}
+
+func TestMergeKeepsAddonConditions(t *testing.T) {
+	base := &Profile{Name: "base", Plugins: []string{"a@m", "shared@m"}}
+	addon := &Profile{
+		Name:             "extras",
+		Type:             TypeAddon,
+		Plugins:          []string{"mac-only@m", "shared@m"},
+		Groups:           map[string][]string{"win": {"win@m"}, "all": {"fmt@m"}},
+		GroupConditions:  map[string]Condition{"win": {OS: []string{"windows"}}},
+		PluginConditions: map[string]Condition{"mac-only@m": {OS: []string{"darwin"}}, "shared@m": {OS: []string{"darwin"}}},
+	}
+
+	merged, err := Merge(base, addon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linux := merged.ForMachine(machine("linux", "amd64"))
+	if want := []string{"a@m", "shared@m", "fmt@m"}; !reflect.DeepEqual(linux.Plugins, want) {
+		t.Errorf("Expected plugins %v on linux, got %v", want, linux.Plugins)
+	}
+	alone := addon.ForMachine(machine("linux", "amd64"))
+	for _, plugin := range []string{"mac-only@m", "win@m"} {
+		if slices.Contains(linux.Plugins, plugin) || slices.Contains(alone.Plugins, plugin) {
+			t.Errorf("Expected %s left out on linux, merged or alone", plugin)
+		}
+	}
+
+	mac := merged.ForMachine(machine("darwin", "arm64"))
+	if want := []string{"a@m", "shared@m", "mac-only@m", "fmt@m"}; !reflect.DeepEqual(mac.Plugins, want) {
+		t.Errorf("Expected plugins %v on macOS, got %v", want, mac.Plugins)
+	}
+	windows := merged.ForMachine(machine("windows", "amd64"))
+	if !slices.Contains(windows.Plugins, "win@m") {
+		t.Errorf("Expected the windows group applied on windows, got %v", windows.Plugins)
+	}
+}
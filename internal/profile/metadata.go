@@ -0,0 +1,78 @@
+// ABOUTME: Profile metadata kept up to date by Save and apply: author, timestamps, last apply
+// ABOUTME: Also matches profiles by tag for 'profile list --tag'
+package profile
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// now returns the time stamped into profile metadata; replaced in tests
+var now = func() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// defaultAuthor names whoever is saving a profile: git's user.name, or the
+// login name when git isn't configured; replaced in tests
+var defaultAuthor = func() string {
+	if out, err := exec.Command("git", "config", "--get", "user.name").Output(); err == nil {
+		if name := strings.TrimSpace(string(out)); name != "" {
+			return name
+		}
+	}
+	return os.Getenv("USER")
+}
+
+// stampSaved sets UpdatedAt and fills in the rest of the metadata from the
+// file being replaced. The last apply is only ever recorded by
+// RecordApplied, so it always comes from the file.
+func (p *Profile) stampSaved(profilesDir string) {
+	t := now()
+	p.AppliedAt, p.AppliedOn = time.Time{}, ""
+	if existing, err := Load(profilesDir, p.Name); err == nil {
+		if !existing.CreatedAt.IsZero() {
+			p.CreatedAt = existing.CreatedAt
+		}
+		if p.Author == "" {
+			p.Author = existing.Author
+		}
+		if p.Tags == nil {
+			p.Tags = existing.Tags
+		}
+		p.AppliedAt, p.AppliedOn = existing.AppliedAt, existing.AppliedOn
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = t
+	}
+	if p.Author == "" {
+		p.Author = defaultAuthor()
+	}
+	p.UpdatedAt = t
+}
+
+// RecordApplied stamps the saved profile name with the time and machine of
+// an apply. Built-in profiles that were never saved are skipped.
+func RecordApplied(profilesDir, name string) error {
+	p, err := Load(profilesDir, name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	p.AppliedAt = now()
+	p.AppliedOn, _ = os.Hostname()
+	return write(profilesDir, p)
+}
+
+// HasTag reports whether the profile is tagged tag, ignoring case
+func (p *Profile) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
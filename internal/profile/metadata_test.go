@@ -0,0 +1,83 @@
+// ABOUTME: Tests for profile metadata stamped by Save and RecordApplied
+// ABOUTME: Covers creation and update times, author defaults, and last-apply records
+package profile
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func stubMetadata(t *testing.T, at *time.Time) {
+	t.Helper()
+	origNow, origAuthor := now, defaultAuthor
+	now = func() time.Time { return *at }
+	defaultAuthor = func() string { return "Test Author" }
+	t.Cleanup(func() { now, defaultAuthor = origNow, origAuthor })
+}
+
+func TestSaveStampsMetadata(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	stubMetadata(t, &at)
+
+	if err := Save(dir, &Profile{Name: "work", Tags: []string{"backend"}}); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(dir, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.CreatedAt.Equal(at) || !p.UpdatedAt.Equal(at) || p.Author != "Test Author" {
+		t.Errorf("new profile metadata = %v %v %q", p.CreatedAt, p.UpdatedAt, p.Author)
+	}
+
+	// An apply is recorded without counting as an update
+	at = at.Add(time.Hour)
+	if err := RecordApplied(dir, "work"); err != nil {
+		t.Fatal(err)
+	}
+	p, _ = Load(dir, "work")
+	host, _ := os.Hostname()
+	if !p.AppliedAt.Equal(at) || p.AppliedOn != host || !p.UpdatedAt.Equal(at.Add(-time.Hour)) {
+		t.Errorf("after apply: applied %v on %q, updated %v", p.AppliedAt, p.AppliedOn, p.UpdatedAt)
+	}
+
+	// Re-saving a snapshot keeps the creation, author, tags, and last apply
+	at = at.Add(time.Hour)
+	if err := Save(dir, &Profile{Name: "work", Plugins: []string{"a@m"}}); err != nil {
+		t.Fatal(err)
+	}
+	p, _ = Load(dir, "work")
+	if !p.CreatedAt.Equal(at.Add(-2*time.Hour)) || !p.UpdatedAt.Equal(at) {
+		t.Errorf("re-save times: created %v, updated %v", p.CreatedAt, p.UpdatedAt)
+	}
+	if p.Author != "Test Author" || !p.HasTag("BACKEND") || !p.AppliedAt.Equal(at.Add(-time.Hour)) {
+		t.Errorf("re-save lost metadata: %+v", p)
+	}
+}
+
+func TestSaveIgnoresCarriedApplyRecord(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	stubMetadata(t, &at)
+
+	// A profile from elsewhere, e.g. a bundle, hasn't been applied here
+	p := &Profile{Name: "shared", Author: "Someone Else", AppliedAt: at.Add(-24 * time.Hour), AppliedOn: "their-laptop"}
+	if err := Save(dir, p); err != nil {
+		t.Fatal(err)
+	}
+	if !p.AppliedAt.IsZero() || p.AppliedOn != "" || p.Author != "Someone Else" {
+		t.Errorf("saved metadata = applied %v on %q by %q", p.AppliedAt, p.AppliedOn, p.Author)
+	}
+}
+
+func TestRecordAppliedSkipsUnsavedProfiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := RecordApplied(dir, "built-in"); err != nil {
+		t.Errorf("RecordApplied = %v, want nil", err)
+	}
+	if _, err := os.Stat(dir + "/built-in.json"); !os.IsNotExist(err) {
+		t.Error("RecordApplied created a profile file")
+	}
+}
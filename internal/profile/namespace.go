@@ -0,0 +1,96 @@
+// ABOUTME: Namespaced profiles such as team/backend, kept in subdirectories of the profiles directory
+// ABOUTME: Resolves bare names to a namespace by the configured precedence
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Namespace returns the namespace of a profile name, or "" for a bare name
+func Namespace(name string) string {
+	ns, _, found := strings.Cut(name, "/")
+	if !found {
+		return ""
+	}
+	return ns
+}
+
+// ValidateName rejects names that don't map to a file in the profiles
+// directory or one of its namespaces
+func ValidateName(name string) error {
+	parts := strings.Split(name, "/")
+	if len(parts) > 2 || strings.ContainsRune(name, '\\') {
+		return fmt.Errorf("invalid profile name %q: use <name> or <namespace>/<name>", name)
+	}
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." || strings.HasPrefix(part, ".") {
+			return fmt.Errorf("invalid profile name %q: use <name> or <namespace>/<name>", name)
+		}
+	}
+	return nil
+}
+
+// Namespaces lists the namespace directories of the profiles directory,
+// sorted. Hidden directories such as .git are not namespaces.
+func Namespaces(profilesDir string) ([]string, error) {
+	entries, err := os.ReadDir(profilesDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var namespaces []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			namespaces = append(namespaces, entry.Name())
+		}
+	}
+	return namespaces, nil
+}
+
+// ResolveName finds the profile a name refers to. A namespaced name is
+// returned as given. A bare name is a profile outside any namespace if one
+// exists, else the first namespace in precedence holding it, else the only
+// other namespace holding it; several such namespaces are an error. A bare
+// name found nowhere is returned unchanged, so built-in profiles still load.
+func ResolveName(profilesDir, name string, precedence []string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	if Namespace(name) != "" || profileExists(profilesDir, name) {
+		return name, nil
+	}
+	for _, ns := range precedence {
+		if profileExists(profilesDir, ns+"/"+name) {
+			return ns + "/" + name, nil
+		}
+	}
+
+	namespaces, err := Namespaces(profilesDir)
+	if err != nil {
+		return "", err
+	}
+	var matches []string
+	for _, ns := range namespaces {
+		if !slices.Contains(precedence, ns) && profileExists(profilesDir, ns+"/"+name) {
+			matches = append(matches, ns+"/"+name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return name, nil
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("profile %q is in several namespaces (%s): name one, or set preferences.profileNamespaces to choose", name, strings.Join(matches, ", "))
+}
+
+func profileExists(profilesDir, name string) bool {
+	info, err := os.Stat(filepath.Join(profilesDir, name+".json"))
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,98 @@
+// ABOUTME: Tests for namespaced profiles
+// ABOUTME: Covers name validation, saving and listing under namespaces, and bare-name precedence
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"backend", "team/backend", "my-profile.v2"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "a/b/c", "../x", "team/..", "/backend", "team/", ".git/x", `team\backend`} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestNamespacedSaveLoadList(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []*Profile{{Name: "solo"}, {Name: "team/backend"}, {Name: "personal/backend"}} {
+		if err := Save(dir, p); err != nil {
+			t.Fatalf("Save %s: %v", p.Name, err)
+		}
+	}
+	// A synced file names itself without its namespace
+	if err := os.WriteFile(filepath.Join(dir, "team", "docs.json"), []byte(`{"name": "docs"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(dir, "team/docs")
+	if err != nil || p.Name != "team/docs" {
+		t.Fatalf("Expected team/docs, got %+v, %v", p, err)
+	}
+
+	profiles, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range profiles {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, " "); got != "personal/backend solo team/backend team/docs" {
+		t.Errorf("Unexpected profiles %q", got)
+	}
+
+	if err := Save(dir, &Profile{Name: "../escape"}); err == nil {
+		t.Error("Expected a name outside the profiles directory to be refused")
+	}
+}
+
+func TestResolveName(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"solo", "team/backend", "personal/backend", "team/docs", "team/solo", "ops/deploy"} {
+		if err := Save(dir, &Profile{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		precedence []string
+		want       string
+		wantErr    string
+	}{
+		{"solo", []string{"team"}, "solo", ""},
+		{"team/solo", nil, "team/solo", ""},
+		{"docs", nil, "team/docs", ""},
+		{"backend", []string{"personal", "team"}, "personal/backend", ""},
+		{"backend", []string{"team"}, "team/backend", ""},
+		{"backend", nil, "", "several namespaces"},
+		{"deploy", []string{"team"}, "ops/deploy", ""},
+		{"default", nil, "default", ""},
+		{"a/b/c", nil, "", "invalid profile name"},
+	}
+	for _, tt := range tests {
+		got, err := ResolveName(dir, tt.name, tt.precedence)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s %v: expected error containing %q, got %q, %v", tt.name, tt.precedence, tt.wantErr, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s %v: expected %q, got %q, %v", tt.name, tt.precedence, tt.want, got, err)
+		}
+	}
+}
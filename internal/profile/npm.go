@@ -0,0 +1,174 @@
+// ABOUTME: Recognises MCP servers launched with npx and the npm package they run
+// ABOUTME: Pins them to exact versions and checks the registry's integrity hash
+package profile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// npxFlagsWithValue are npx/npm exec options whose value is a separate argument
+var npxFlagsWithValue = map[string]bool{
+	"-c": true, "--call": true, "--registry": true, "--cache": true, "--userconfig": true,
+}
+
+// NpmPackages returns the npm packages an npx-launched server runs: its
+// pinned Package, each --package/-p value, or else the first positional
+// argument. It returns nil for servers not started with npx.
+func (m MCPServer) NpmPackages() []string {
+	if m.Command != "npx" && !strings.HasSuffix(m.Command, "/npx") {
+		return nil
+	}
+	if m.Package != "" && m.Version != "" {
+		return []string{m.Package + "@" + m.Version}
+	}
+
+	var packages []string
+	var first string
+	for i := 0; i < len(m.Args); i++ {
+		arg := m.Args[i]
+		switch {
+		case arg == "--":
+			i = len(m.Args)
+		case arg == "-p" || arg == "--package":
+			if i+1 < len(m.Args) {
+				packages = append(packages, m.Args[i+1])
+				i++
+			}
+		case strings.HasPrefix(arg, "--package="):
+			packages = append(packages, strings.TrimPrefix(arg, "--package="))
+		case npxFlagsWithValue[arg]:
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			if first == "" {
+				first = arg
+			}
+			// Everything after the command is the server's own arguments
+			i = len(m.Args)
+		}
+	}
+	if len(packages) == 0 && first != "" {
+		packages = []string{first}
+	}
+	return packages
+}
+
+// SplitPackageSpec splits "name@version" into its parts, allowing for
+// scoped names like "@scope/name@1.2.3"
+func SplitPackageSpec(spec string) (name, version string) {
+	at := strings.LastIndex(spec, "@")
+	if at <= 0 {
+		return spec, ""
+	}
+	return spec[:at], spec[at+1:]
+}
+
+// PinnedArgs returns Args with the server's Package pinned to Version.
+// Servers without a pin get Args unchanged.
+func (m MCPServer) PinnedArgs() []string {
+	args := append([]string(nil), m.Args...)
+	if m.Package == "" || m.Version == "" {
+		return args
+	}
+	if i, ok := m.packageArg(); ok {
+		prefix := ""
+		if strings.HasPrefix(args[i], "--package=") {
+			prefix = "--package="
+		}
+		args[i] = prefix + m.Package + "@" + m.Version
+	}
+	return args
+}
+
+// packageArg finds the index of the argument naming Package
+func (m MCPServer) packageArg() (int, bool) {
+	for i, arg := range m.Args {
+		if name, _ := SplitPackageSpec(strings.TrimPrefix(arg, "--package=")); name == m.Package {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// NpmPin is a package version and its registry integrity hash
+type NpmPin struct {
+	Version   string
+	Integrity string
+}
+
+// npmView looks up spec in the npm registry; replaced in tests
+var npmView = func(ctx context.Context, spec string) (NpmPin, error) {
+	cmd := exec.CommandContext(ctx, "npm", "view", spec, "version", "dist.integrity", "--json")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return NpmPin{}, fmt.Errorf("npm view %s: %s", spec, lastNonEmptyLine(msg))
+		}
+		return NpmPin{}, fmt.Errorf("npm view %s: %w", spec, err)
+	}
+	return parseNpmView(stdout.Bytes())
+}
+
+// parseNpmView reads 'npm view --json' output for version and
+// dist.integrity. A range matching several versions yields an array; the
+// last entry is the newest.
+func parseNpmView(data []byte) (NpmPin, error) {
+	type view struct {
+		Version   string `json:"version"`
+		Integrity string `json:"dist.integrity"`
+	}
+	var one view
+	if err := json.Unmarshal(data, &one); err != nil {
+		var many []view
+		if err := json.Unmarshal(data, &many); err != nil || len(many) == 0 {
+			return NpmPin{}, fmt.Errorf("unexpected output from npm view")
+		}
+		one = many[len(many)-1]
+	}
+	if one.Version == "" {
+		return NpmPin{}, fmt.Errorf("npm view returned no version")
+	}
+	return NpmPin{Version: one.Version, Integrity: one.Integrity}, nil
+}
+
+// ResolveNpmPin looks up the exact version and integrity spec resolves to,
+// e.g. "foo" or "foo@^2" to the newest matching version
+func ResolveNpmPin(ctx context.Context, spec string) (NpmPin, error) {
+	return npmView(ctx, spec)
+}
+
+// checkPin verifies a pinned server before it's installed: its args must
+// run Package, and the registry's integrity for Version must match the
+// recorded one
+func checkPin(ctx context.Context, m MCPServer) error {
+	if m.Package == "" {
+		return nil
+	}
+	if _, ok := m.packageArg(); !ok {
+		return fmt.Errorf("MCP server %s pins %s, but its args don't run it", m.Name, m.Package)
+	}
+	if m.Version == "" || m.Integrity == "" {
+		return nil
+	}
+	spec := m.Package + "@" + m.Version
+	pin, err := npmView(ctx, spec)
+	if err != nil {
+		return fmt.Errorf("could not verify %s: %w", spec, err)
+	}
+	if pin.Integrity != m.Integrity {
+		return fmt.Errorf("integrity mismatch for %s: profile has %s, registry has %s", spec, m.Integrity, pin.Integrity)
+	}
+	return nil
+}
+
+func lastNonEmptyLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
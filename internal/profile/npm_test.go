@@ -0,0 +1,96 @@
+// ABOUTME: Tests for finding the npm packages of npx-launched MCP servers
+// ABOUTME: Covers package detection, version pinning, and integrity checks
+package profile
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNpmPackages(t *testing.T) {
+	tests := []struct {
+		name   string
+		server MCPServer
+		want   []string
+	}{
+		{"positional", MCPServer{Command: "npx", Args: []string{"-y", "@context7/mcp", "--port", "3000"}}, []string{"@context7/mcp"}},
+		{"versioned", MCPServer{Command: "npx", Args: []string{"--yes", "server-github@1.2.0"}}, []string{"server-github@1.2.0"}},
+		{"package flags", MCPServer{Command: "/usr/local/bin/npx", Args: []string{"-y", "-p", "a", "--package=b", "run-a"}}, []string{"a", "b"}},
+		{"registry value skipped", MCPServer{Command: "npx", Args: []string{"--registry", "https://npm.corp", "tool"}}, []string{"tool"}},
+		{"not npx", MCPServer{Command: "uvx", Args: []string{"mcp-server-fetch"}}, nil},
+		{"no package", MCPServer{Command: "npx", Args: []string{"-y"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.server.NpmPackages(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NpmPackages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitPackageSpec(t *testing.T) {
+	tests := []struct{ spec, name, version string }{
+		{"foo", "foo", ""},
+		{"foo@1.2.3", "foo", "1.2.3"},
+		{"@scope/foo", "@scope/foo", ""},
+		{"@scope/foo@^2", "@scope/foo", "^2"},
+	}
+	for _, tt := range tests {
+		name, version := SplitPackageSpec(tt.spec)
+		if name != tt.name || version != tt.version {
+			t.Errorf("SplitPackageSpec(%q) = %q, %q; want %q, %q", tt.spec, name, version, tt.name, tt.version)
+		}
+	}
+}
+
+func TestPinnedArgs(t *testing.T) {
+	server := MCPServer{Command: "npx", Args: []string{"-y", "@scope/foo@^2", "--port", "1"}, Package: "@scope/foo", Version: "2.4.1"}
+	want := []string{"-y", "@scope/foo@2.4.1", "--port", "1"}
+	if got := server.PinnedArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PinnedArgs() = %v, want %v", got, want)
+	}
+	if server.Args[1] != "@scope/foo@^2" {
+		t.Error("Expected PinnedArgs to leave Args untouched")
+	}
+
+	flag := MCPServer{Command: "npx", Args: []string{"--package=foo", "foo-server"}, Package: "foo", Version: "1.0.0"}
+	if got := flag.PinnedArgs(); got[0] != "--package=foo@1.0.0" {
+		t.Errorf("Expected --package to be pinned, got %v", got)
+	}
+}
+
+func TestParseNpmView(t *testing.T) {
+	pin, err := parseNpmView([]byte(`{"version": "1.2.3", "dist.integrity": "sha512-abc"}`))
+	if err != nil || pin != (NpmPin{Version: "1.2.3", Integrity: "sha512-abc"}) {
+		t.Errorf("Unexpected pin %+v, %v", pin, err)
+	}
+	pin, err = parseNpmView([]byte(`[{"version": "2.0.0", "dist.integrity": "a"}, {"version": "2.1.0", "dist.integrity": "b"}]`))
+	if err != nil || pin.Version != "2.1.0" {
+		t.Errorf("Expected the newest match of a range, got %+v, %v", pin, err)
+	}
+}
+
+func TestApplyDiffChecksPinIntegrity(t *testing.T) {
+	defer func(f func(context.Context, string) (NpmPin, error)) { npmView = f }(npmView)
+	npmView = func(ctx context.Context, spec string) (NpmPin, error) {
+		return NpmPin{Version: "1.0.0", Integrity: "sha512-registry"}, nil
+	}
+
+	good := MCPServer{Name: "good", Command: "npx", Args: []string{"-y", "foo"}, Package: "foo", Version: "1.0.0", Integrity: "sha512-registry"}
+	bad := MCPServer{Name: "bad", Command: "npx", Args: []string{"-y", "bar"}, Package: "bar", Version: "1.0.0", Integrity: "sha512-recorded"}
+	executor := &okExecutor{}
+
+	result, err := ApplyDiff(context.Background(), &Diff{MCPToInstall: []MCPServer{good, bad}}, nil, executor)
+	if err != nil {
+		t.Fatalf("ApplyDiff failed: %v", err)
+	}
+	if len(executor.calls) != 1 || executor.calls[0][len(executor.calls[0])-1] != "foo@1.0.0" {
+		t.Errorf("Expected only the pinned good server to be added, got %v", executor.calls)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "integrity mismatch") {
+		t.Errorf("Expected an integrity mismatch for bad, got %v", result.Errors)
+	}
+}
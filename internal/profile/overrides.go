@@ -0,0 +1,123 @@
+// ABOUTME: Machine-specific overrides kept in ~/.claudeup/overrides.json, outside any profile
+// ABOUTME: Merged into every profile as it applies here, and kept out of the profiles 'profile save' writes
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+)
+
+// Overrides are this machine's additions to every profile it applies, such
+// as a mount of a local checkout or a plugin that doesn't work here. They
+// live outside the profiles, so they're never synced or shared.
+type Overrides struct {
+	// Mounts are added to the sandbox, replacing a profile mount at the
+	// same container path
+	Mounts []SandboxMount `json:"mounts,omitempty"`
+
+	// Env sets variables in both the shell environment and the sandbox,
+	// replacing the profile's values
+	Env map[string]string `json:"env,omitempty"`
+
+	// DisabledPlugins are disabled wherever a profile installs them
+	DisabledPlugins []string `json:"disabledPlugins,omitempty"`
+}
+
+// OverridesPath returns the path of this machine's overrides file
+func OverridesPath() string {
+	return pathctx.Default().Claudeup("overrides.json")
+}
+
+// LoadOverrides reads this machine's overrides. A missing file is no
+// overrides.
+func LoadOverrides() (*Overrides, error) {
+	data, err := os.ReadFile(OverridesPath())
+	if os.IsNotExist(err) {
+		return &Overrides{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var o Overrides
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", OverridesPath(), err)
+	}
+	return &o, nil
+}
+
+// SaveOverrides writes this machine's overrides
+func SaveOverrides(o *Overrides) error {
+	path := OverridesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// IsZero reports whether o changes nothing
+func (o *Overrides) IsZero() bool {
+	return o == nil || (len(o.Mounts) == 0 && len(o.Env) == 0 && len(o.DisabledPlugins) == 0)
+}
+
+// WithOverrides returns the profile with o merged in. Only plugins the
+// profile installs are disabled, so an override never installs anything.
+// Applying the same overrides twice changes nothing more.
+func (p *Profile) WithOverrides(o *Overrides) *Profile {
+	if p == nil || o.IsZero() {
+		return p
+	}
+	merged := p.Clone(p.Name)
+	merged.skipped = p.skipped
+
+	for _, mount := range o.Mounts {
+		merged.Sandbox.Mounts = slices.DeleteFunc(merged.Sandbox.Mounts, func(m SandboxMount) bool {
+			return m.Container == mount.Container
+		})
+		merged.Sandbox.Mounts = append(merged.Sandbox.Mounts, mount)
+	}
+
+	if len(o.Env) > 0 {
+		if merged.ShellEnv.Env == nil {
+			merged.ShellEnv.Env = make(map[string]string)
+		}
+		if merged.Sandbox.Env == nil {
+			merged.Sandbox.Env = make(map[string]string)
+		}
+		maps.Copy(merged.ShellEnv.Env, o.Env)
+		maps.Copy(merged.Sandbox.Env, o.Env)
+	}
+
+	for _, plugin := range o.DisabledPlugins {
+		if slices.Contains(merged.Plugins, plugin) {
+			merged.Disabled.Plugins = appendMissing(merged.Disabled.Plugins, plugin)
+		}
+	}
+	return merged
+}
+
+// WithoutOverrides returns the profile without the plugins o disables
+// marked disabled, so saving this machine's state doesn't write its
+// overrides into a shared profile
+func (p *Profile) WithoutOverrides(o *Overrides) *Profile {
+	if p == nil || o.IsZero() || len(o.DisabledPlugins) == 0 {
+		return p
+	}
+	kept := p.Clone(p.Name)
+	kept.Disabled.Plugins = slices.DeleteFunc(kept.Disabled.Plugins, func(plugin string) bool {
+		return slices.Contains(o.DisabledPlugins, plugin)
+	})
+	if len(kept.Disabled.Plugins) == 0 {
+		kept.Disabled.Plugins = nil
+	}
+	return kept
+}
@@ -0,0 +1,98 @@
+// ABOUTME: Tests for this machine's overrides file and merging it into profiles
+// ABOUTME: Covers loading and saving, what WithOverrides adds, and keeping overrides out of saved profiles
+package profile
+
+import (
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+)
+
+func TestLoadAndSaveOverrides(t *testing.T) {
+	defer pathctx.Override(pathctx.ForHome(t.TempDir()))()
+
+	o, err := LoadOverrides()
+	if err != nil || !o.IsZero() {
+		t.Fatalf("Expected no overrides without a file, got %+v, %v", o, err)
+	}
+	o.Env = map[string]string{"JAVA_HOME": "/opt/jdk"}
+	if err := SaveOverrides(o); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadOverrides()
+	if err != nil || !reflect.DeepEqual(loaded, o) {
+		t.Errorf("Expected %+v back, got %+v, %v", o, loaded, err)
+	}
+
+	if err := os.WriteFile(OverridesPath(), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOverrides(); err == nil {
+		t.Error("Expected an error for a malformed overrides file")
+	}
+	if _, err := ComputeDiff(&Profile{Name: "p"}, t.TempDir(), ""); err == nil {
+		t.Error("Expected ComputeDiff to report a malformed overrides file")
+	}
+}
+
+func TestWithOverrides(t *testing.T) {
+	p := &Profile{
+		Name:     "backend",
+		Plugins:  []string{"gpu@acme", "tdd@acme"},
+		ShellEnv: ShellEnvConfig{Env: map[string]string{"JAVA_HOME": "/usr/lib/jvm", "EDITOR": "vim"}},
+		Sandbox:  SandboxConfig{Mounts: []SandboxMount{{Host: "/srv/sdk", Container: "/sdk"}, {Host: "/data", Container: "/data"}}},
+	}
+	o := &Overrides{
+		Mounts:          []SandboxMount{{Host: "/home/me/sdk", Container: "/sdk", ReadOnly: true}},
+		Env:             map[string]string{"JAVA_HOME": "/opt/jdk"},
+		DisabledPlugins: []string{"gpu@acme", "other@acme"},
+	}
+
+	got := p.WithOverrides(o)
+	if got.ShellEnv.Env["JAVA_HOME"] != "/opt/jdk" || got.ShellEnv.Env["EDITOR"] != "vim" || got.Sandbox.Env["JAVA_HOME"] != "/opt/jdk" {
+		t.Errorf("Expected the override env in the shell and sandbox, got %v and %v", got.ShellEnv.Env, got.Sandbox.Env)
+	}
+	wantMounts := []SandboxMount{{Host: "/data", Container: "/data"}, {Host: "/home/me/sdk", Container: "/sdk", ReadOnly: true}}
+	if !reflect.DeepEqual(got.Sandbox.Mounts, wantMounts) {
+		t.Errorf("Expected the override to replace the /sdk mount, got %+v", got.Sandbox.Mounts)
+	}
+	if !reflect.DeepEqual(got.Disabled.Plugins, []string{"gpu@acme"}) || !slices.Contains(got.Plugins, "gpu@acme") {
+		t.Errorf("Expected only the installed plugin disabled, got plugins %v, disabled %v", got.Plugins, got.Disabled.Plugins)
+	}
+	if p.ShellEnv.Env["JAVA_HOME"] != "/usr/lib/jvm" || len(p.Disabled.Plugins) != 0 {
+		t.Error("WithOverrides must not modify the profile")
+	}
+	if again := got.WithOverrides(o); !reflect.DeepEqual(again, got) {
+		t.Errorf("Expected applying overrides twice to change nothing, got %+v", again)
+	}
+
+	saved := got.WithoutOverrides(o)
+	if len(saved.Disabled.Plugins) != 0 || !slices.Contains(saved.Plugins, "gpu@acme") {
+		t.Errorf("Expected the plugin saved as enabled, got plugins %v, disabled %v", saved.Plugins, saved.Disabled.Plugins)
+	}
+}
+
+func TestForThisMachineAppliesOverrides(t *testing.T) {
+	defer pathctx.Override(pathctx.ForHome(t.TempDir()))()
+	if err := SaveOverrides(&Overrides{DisabledPlugins: []string{"gpu@acme"}}); err != nil {
+		t.Fatal(err)
+	}
+	p := &Profile{Name: "p", Groups: map[string][]string{"ml": {"gpu@acme"}}}
+	got, err := p.ForThisMachine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(got.Disabled.Plugins, "gpu@acme") {
+		t.Errorf("Expected the group's plugin disabled by the override, got %+v", got.Disabled)
+	}
+
+	if err := os.WriteFile(OverridesPath(), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.ForThisMachine(); err == nil {
+		t.Error("Expected an unreadable overrides file to be reported")
+	}
+}
@@ -0,0 +1,126 @@
+// ABOUTME: Profile permissions that pre-seed Claude Code's tool allow-list and project trust
+// ABOUTME: Computes what settings.json and .claude.json lack, and adds it once the user agrees
+package profile
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// PermissionsConfig pre-seeds Claude Code permissions, so a freshly applied
+// profile doesn't open with a string of permission prompts. Entries are
+// only ever added, never removed.
+type PermissionsConfig struct {
+	// Allow are settings.json permission rules, e.g. "Bash(npm test:*)" or
+	// "mcp__github"
+	Allow []string `json:"allow,omitempty"`
+
+	// AdditionalDirectories are directories outside the project Claude Code
+	// may read and edit; ~ is expanded
+	AdditionalDirectories []string `json:"additionalDirectories,omitempty"`
+
+	// TrustProject marks the directory the profile is applied in as trusted
+	TrustProject bool `json:"trustProject,omitempty"`
+}
+
+// IsEmpty reports whether the config grants nothing
+func (c PermissionsConfig) IsEmpty() bool {
+	return len(c.Allow) == 0 && len(c.AdditionalDirectories) == 0 && !c.TrustProject
+}
+
+// Validate rejects blank rules and relative directories
+func (c PermissionsConfig) Validate() error {
+	for _, rule := range c.Allow {
+		if strings.TrimSpace(rule) == "" {
+			return fmt.Errorf("permissions: empty allow rule")
+		}
+	}
+	for _, dir := range c.AdditionalDirectories {
+		if !filepath.IsAbs(expandHome(dir)) {
+			return fmt.Errorf("permissions: additional directory %q must be absolute or start with ~/", dir)
+		}
+	}
+	return nil
+}
+
+func (c PermissionsConfig) clone() PermissionsConfig {
+	return PermissionsConfig{
+		Allow:                 append([]string(nil), c.Allow...),
+		AdditionalDirectories: append([]string(nil), c.AdditionalDirectories...),
+		TrustProject:          c.TrustProject,
+	}
+}
+
+// PermissionChanges is what applying a profile's permissions would add
+type PermissionChanges struct {
+	Allow       []string
+	Directories []string
+	Trust       string // project directory to mark trusted, if any
+}
+
+// IsEmpty reports whether there is nothing to add
+func (c PermissionChanges) IsEmpty() bool {
+	return len(c.Allow) == 0 && len(c.Directories) == 0 && c.Trust == ""
+}
+
+// PendingPermissions returns the profile's permissions that settings.json
+// and .claude.json don't already have, for a profile applied in project
+func PendingPermissions(p *Profile, claudeDir, claudeJSONPath, project string) (PermissionChanges, error) {
+	var changes PermissionChanges
+	if p.Permissions.IsEmpty() {
+		return changes, nil
+	}
+
+	current, err := state.LoadPermissions(claudeDir)
+	if err != nil {
+		return changes, err
+	}
+	for _, rule := range p.Permissions.Allow {
+		if !slices.Contains(current.Allow, rule) && !slices.Contains(changes.Allow, rule) {
+			changes.Allow = append(changes.Allow, rule)
+		}
+	}
+	for _, dir := range p.Permissions.AdditionalDirectories {
+		dir = expandHome(dir)
+		if !slices.Contains(current.AdditionalDirectories, dir) && !slices.Contains(changes.Directories, dir) {
+			changes.Directories = append(changes.Directories, dir)
+		}
+	}
+
+	if p.Permissions.TrustProject && project != "" {
+		trusted, err := state.ProjectTrusted(claudeJSONPath, project)
+		if err != nil {
+			return changes, err
+		}
+		if !trusted {
+			changes.Trust = project
+		}
+	}
+	return changes, nil
+}
+
+// ApplyPermissions adds the changes to settings.json and .claude.json
+func ApplyPermissions(changes PermissionChanges, claudeDir, claudeJSONPath string) error {
+	if len(changes.Allow) > 0 || len(changes.Directories) > 0 {
+		add := state.Permissions{Allow: changes.Allow, AdditionalDirectories: changes.Directories}
+		if err := state.AddPermissions(claudeDir, add); err != nil {
+			return fmt.Errorf("failed to update settings.json: %w", err)
+		}
+	}
+	if changes.Trust != "" {
+		if err := state.TrustProject(claudeJSONPath, changes.Trust); err != nil {
+			return fmt.Errorf("failed to trust %s: %w", changes.Trust, err)
+		}
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	return pathctx.ExpandHome(path, pathctx.Default().Home)
+}
@@ -0,0 +1,74 @@
+// ABOUTME: Tests for profile permissions pre-seeding
+// ABOUTME: Covers validation, computing what's missing, applying, and merging addons
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPermissionsValidate(t *testing.T) {
+	if err := (PermissionsConfig{Allow: []string{"Read"}, AdditionalDirectories: []string{"~/src", "/opt"}}).Validate(); err != nil {
+		t.Errorf("Expected valid, got %v", err)
+	}
+	if err := (PermissionsConfig{Allow: []string{" "}}).Validate(); err == nil {
+		t.Error("Expected an empty rule to be rejected")
+	}
+	if err := (PermissionsConfig{AdditionalDirectories: []string{"src"}}).Validate(); err == nil {
+		t.Error("Expected a relative directory to be rejected")
+	}
+}
+
+func TestPendingPermissions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	claudeDir := filepath.Join(home, ".claude")
+	claudeJSONPath := filepath.Join(home, ".claude.json")
+	os.MkdirAll(claudeDir, 0755)
+	os.WriteFile(filepath.Join(claudeDir, "settings.json"), []byte(`{"permissions": {"allow": ["Read"]}}`), 0644)
+
+	p := &Profile{Name: "dev", Permissions: PermissionsConfig{
+		Allow:                 []string{"Read", "Bash(go test:*)"},
+		AdditionalDirectories: []string{"~/shared"},
+		TrustProject:          true,
+	}}
+
+	changes, err := PendingPermissions(p, claudeDir, claudeJSONPath, "/work/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.Allow) != 1 || changes.Allow[0] != "Bash(go test:*)" {
+		t.Errorf("Expected only the missing rule, got %v", changes.Allow)
+	}
+	if len(changes.Directories) != 1 || changes.Directories[0] != filepath.Join(home, "shared") {
+		t.Errorf("Expected the expanded directory, got %v", changes.Directories)
+	}
+	if changes.Trust != "/work/app" {
+		t.Errorf("Expected the project to need trust, got %q", changes.Trust)
+	}
+
+	if err := ApplyPermissions(changes, claudeDir, claudeJSONPath); err != nil {
+		t.Fatal(err)
+	}
+	changes, err = PendingPermissions(p, claudeDir, claudeJSONPath, "/work/app")
+	if err != nil || !changes.IsEmpty() {
+		t.Errorf("Expected nothing left after applying, got %+v, %v", changes, err)
+	}
+}
+
+func TestMergeUnionsPermissions(t *testing.T) {
+	base := &Profile{Name: "base", Permissions: PermissionsConfig{Allow: []string{"Read"}}}
+	addon := &Profile{Name: "web", Type: TypeAddon, Permissions: PermissionsConfig{Allow: []string{"Read", "WebFetch"}, TrustProject: true}}
+
+	merged, err := Merge(base, addon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged.Permissions.Allow) != 2 || !merged.Permissions.TrustProject {
+		t.Errorf("Unexpected merged permissions %+v", merged.Permissions)
+	}
+	if len(base.Permissions.Allow) != 1 {
+		t.Errorf("Expected the base profile unchanged, got %v", base.Permissions.Allow)
+	}
+}
@@ -0,0 +1,113 @@
+// ABOUTME: Versioned, machine-readable form of a Diff for wrappers and scripts
+// ABOUTME: Field names are stable; additions keep the version, removals bump it
+package profile
+
+import (
+	"sort"
+)
+
+// PlanSchemaVersion identifies the Plan layout. It changes only when a field
+// is removed or changes meaning; new fields may appear at any time.
+const PlanSchemaVersion = 1
+
+// Plan lists the changes applying a profile would make
+type Plan struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Profile       string           `json:"profile"`
+	Changes       int              `json:"changes"`
+	Plugins       PlanPlugins      `json:"plugins"`
+	MCPServers    PlanMCPServers   `json:"mcpServers"`
+	Marketplaces  PlanMarketplaces `json:"marketplaces"`
+	Skipped       []Skipped        `json:"skipped"` // left out because their conditions aren't met here
+}
+
+// PlanPlugins lists plugin changes by name@marketplace
+type PlanPlugins struct {
+	Install []string `json:"install"`
+	Remove  []string `json:"remove"`
+	Disable []string `json:"disable"`
+	Enable  []string `json:"enable"`
+}
+
+// PlanMCPServers lists MCP server changes. Disable and Enable hold
+// <plugin>:<server> references.
+type PlanMCPServers struct {
+	Install []PlanMCPServer `json:"install"`
+	Remove  []string        `json:"remove"`
+	Disable []string        `json:"disable"`
+	Enable  []string        `json:"enable"`
+}
+
+// PlanMCPServer is an MCP server to add. Secrets lists the environment
+// variables that will be resolved; values never appear in a plan.
+type PlanMCPServer struct {
+	Name    string   `json:"name"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Scope   string   `json:"scope"`
+	Secrets []string `json:"secrets"`
+}
+
+// PlanMarketplaces lists marketplaces to add
+type PlanMarketplaces struct {
+	Add []Marketplace `json:"add"`
+}
+
+// NewPlan builds the plan for applying the named profile. Lists are sorted
+// and never null, so consumers can rely on their presence.
+func NewPlan(name string, diff *Diff) *Plan {
+	plan := &Plan{
+		SchemaVersion: PlanSchemaVersion,
+		Profile:       name,
+		Changes:       diff.Count(),
+		Plugins: PlanPlugins{
+			Install: sortedCopy(diff.PluginsToInstall),
+			Remove:  sortedCopy(diff.PluginsToRemove),
+			Disable: sortedCopy(diff.PluginsToDisable),
+			Enable:  sortedCopy(diff.PluginsToEnable),
+		},
+		MCPServers: PlanMCPServers{
+			Install: []PlanMCPServer{},
+			Remove:  sortedCopy(diff.MCPToRemove),
+			Disable: sortedCopy(diff.MCPToDisable),
+			Enable:  sortedCopy(diff.MCPToEnable),
+		},
+		Marketplaces: PlanMarketplaces{Add: []Marketplace{}},
+		Skipped:      append([]Skipped{}, diff.Skipped...),
+	}
+
+	for _, m := range diff.MCPToInstall {
+		scope := m.Scope
+		if scope == "" {
+			scope = "user"
+		}
+		secrets := make([]string, 0, len(m.Secrets))
+		for envVar := range m.Secrets {
+			secrets = append(secrets, envVar)
+		}
+		sort.Strings(secrets)
+		plan.MCPServers.Install = append(plan.MCPServers.Install, PlanMCPServer{
+			Name:    m.Name,
+			Command: m.Command,
+			Args:    append([]string{}, m.PinnedArgs()...),
+			Scope:   scope,
+			Secrets: secrets,
+		})
+	}
+	sort.Slice(plan.MCPServers.Install, func(i, j int) bool {
+		return plan.MCPServers.Install[i].Name < plan.MCPServers.Install[j].Name
+	})
+
+	plan.Marketplaces.Add = append(plan.Marketplaces.Add, diff.MarketplacesToAdd...)
+	sort.Slice(plan.Marketplaces.Add, func(i, j int) bool {
+		return plan.Marketplaces.Add[i].DisplayName() < plan.Marketplaces.Add[j].DisplayName()
+	})
+
+	return plan
+}
+
+func sortedCopy(items []string) []string {
+	out := append([]string{}, items...)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,58 @@
+// ABOUTME: Tests for the versioned machine-readable plan
+// ABOUTME: Guards the JSON field names consumers depend on
+package profile
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewPlan(t *testing.T) {
+	diff := &Diff{
+		PluginsToInstall: []string{"b@m", "a@m"},
+		MCPToInstall: []MCPServer{{
+			Name:    "gh",
+			Command: "npx",
+			Args:    []string{"gh-mcp"},
+			Secrets: map[string]SecretRef{"GITHUB_TOKEN": {Sources: []SecretSource{{Type: "env", Key: "GH"}}}},
+		}},
+		MarketplacesToAdd: []Marketplace{{Source: "github", Repo: "org/m"}},
+	}
+
+	plan := NewPlan("backend", diff)
+	if plan.SchemaVersion != PlanSchemaVersion || plan.Changes != 4 {
+		t.Errorf("Unexpected header %+v", plan)
+	}
+	if plan.Plugins.Install[0] != "a@m" {
+		t.Errorf("Expected sorted plugins, got %v", plan.Plugins.Install)
+	}
+	if len(diff.PluginsToInstall) != 2 || diff.PluginsToInstall[0] != "b@m" {
+		t.Error("NewPlan must not reorder the diff")
+	}
+	mcp := plan.MCPServers.Install[0]
+	if mcp.Scope != "user" || len(mcp.Secrets) != 1 || mcp.Secrets[0] != "GITHUB_TOKEN" {
+		t.Errorf("Unexpected MCP server %+v", mcp)
+	}
+
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		`"schemaVersion":1`,
+		`"profile":"backend"`,
+		`"remove":[]`,
+		`"disable":[]`,
+		`"secrets":["GITHUB_TOKEN"]`,
+		`"marketplaces":{"add":[`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Plan JSON missing %s: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "null") {
+		t.Errorf("Plan JSON must not contain null lists: %s", out)
+	}
+}
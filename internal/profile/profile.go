@@ -4,21 +4,84 @@ package profile
 
 import (
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
 )
 
 // Profile represents a Claude Code configuration profile
 type Profile struct {
-	Name         string        `json:"name"`
-	Description  string        `json:"description,omitempty"`
-	MCPServers   []MCPServer   `json:"mcpServers,omitempty"`
-	Marketplaces []Marketplace `json:"marketplaces,omitempty"`
-	Plugins      []string      `json:"plugins,omitempty"`
-	Detect       DetectRules   `json:"detect,omitempty"`
-	Sandbox      SandboxConfig `json:"sandbox,omitempty"`
+	Name           string              `json:"name"`
+	Description    string              `json:"description,omitempty"`
+	Type           string              `json:"type,omitempty"` // "" for a full profile, "addon" for add-only
+	Tags           []string            `json:"tags,omitempty"`
+	Author         string              `json:"author,omitempty"`
+	CreatedAt      time.Time           `json:"createdAt,omitzero"`
+	UpdatedAt      time.Time           `json:"updatedAt,omitzero"`
+	AppliedAt      time.Time           `json:"appliedAt,omitzero"`
+	AppliedOn      string              `json:"appliedOn,omitempty"` // hostname of the last apply
+	MCPServers     []MCPServer         `json:"mcpServers,omitempty"`
+	Marketplaces   []Marketplace       `json:"marketplaces,omitempty"`
+	Plugins        []string            `json:"plugins,omitempty"`
+	Groups         map[string][]string `json:"groups,omitempty"`         // named plugin sets, applied unless disabled
+	DisabledGroups []string            `json:"disabledGroups,omitempty"` // groups whose plugins aren't applied
+	Detect         DetectRules         `json:"detect,omitempty"`
+	Sandbox        SandboxConfig       `json:"sandbox,omitempty"`
+	ShellEnv       ShellEnvConfig      `json:"shellEnv,omitempty"`
+	Env            map[string]EnvVar   `json:"env,omitempty"`
+	Disabled       DisabledConfig      `json:"disabled,omitempty"`
+	SetupWizard    *SetupWizard        `json:"setupWizard,omitempty"`
+	Permissions    PermissionsConfig   `json:"permissions,omitzero"`
+
+	// ProvidedMCPServers documents the MCP servers the profile's plugins
+	// bring with them. It's written by 'profile save --provided-mcp' and
+	// never applied; the plugins install these servers themselves.
+	ProvidedMCPServers []ProvidedMCPServer `json:"providedMCPServers,omitempty"`
+
+	// PluginConditions limit plugins to some machines, wherever the profile
+	// lists them. A plugin this machine doesn't match isn't applied.
+	PluginConditions map[string]Condition `json:"pluginConditions,omitempty"`
+
+	// GroupConditions limit plugin groups to some machines, e.g. a group
+	// of macOS-only plugins. A group this machine doesn't match isn't applied.
+	GroupConditions map[string]Condition `json:"groupConditions,omitempty"`
+
+	// skipped is what ForMachine left out of this profile
+	skipped []Skipped
+}
+
+// ProvidedMCPServer is an MCP server defined by a plugin
+type ProvidedMCPServer struct {
+	Name    string   `json:"name"`
+	Plugin  string   `json:"plugin"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	Env     []string `json:"env,omitempty"` // variable names only; values can be secrets
+}
+
+// ShellEnvConfig defines variables exported to normal shell sessions by
+// 'claudeup env'. Secrets from the profile's MCP servers are always included.
+type ShellEnvConfig struct {
+	// Env are static environment variables to export
+	Env map[string]string `json:"env,omitempty"`
+
+	// Secrets are resolved on demand and never written to disk
+	Secrets map[string]SecretRef `json:"secrets,omitempty"`
+}
+
+// DisabledConfig lists items that are installed but turned off with
+// 'claudeup disable' and 'claudeup mcp disable'
+type DisabledConfig struct {
+	// Plugins are installed, then moved out of Claude's registry
+	Plugins []string `json:"plugins,omitempty"`
+
+	// MCPServers are plugin MCP server references in <plugin>:<server> form
+	MCPServers []string `json:"mcpServers,omitempty"`
 }
 
 // SandboxConfig defines sandbox-specific settings for a profile
@@ -29,6 +92,10 @@ type SandboxConfig struct {
 	// Mounts are additional host:container path mappings
 	Mounts []SandboxMount `json:"mounts,omitempty"`
 
+	// Ports are container ports to publish on the host, as
+	// [host:]container[/udp]
+	Ports []string `json:"ports,omitempty"`
+
 	// Env are static environment variables to set
 	Env map[string]string `json:"env,omitempty"`
 }
@@ -38,6 +105,7 @@ type SandboxMount struct {
 	Host      string `json:"host"`
 	Container string `json:"container"`
 	ReadOnly  bool   `json:"readonly,omitempty"`
+	Condition
 }
 
 // MCPServer represents an MCP server configuration
@@ -47,36 +115,94 @@ type MCPServer struct {
 	Args    []string             `json:"args,omitempty"`
 	Scope   string               `json:"scope,omitempty"`
 	Secrets map[string]SecretRef `json:"secrets,omitempty"`
+
+	// For npx servers: the npm package in Args to pin to Version, and the
+	// registry's dist.integrity for that version, checked before install
+	Package   string `json:"package,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Integrity string `json:"integrity,omitempty"`
+
+	// Condition limits the server to some machines, e.g. where docker is
+	// installed
+	Condition
 }
 
+// Marketplace source types besides "github" and "git"
+const (
+	MarketplaceLocal   = "local"   // a directory on this machine, e.g. inside a project repo
+	MarketplaceArchive = "archive" // a .tar.gz at URL, checked against SHA256
+)
+
 // Marketplace represents a plugin marketplace source
 type Marketplace struct {
 	Source string `json:"source"`
 	Repo   string `json:"repo,omitempty"`   // Used for github sources
-	URL    string `json:"url,omitempty"`    // Used for git sources
+	URL    string `json:"url,omitempty"`    // Used for git and archive sources
+	Path   string `json:"path,omitempty"`   // Used for local sources
+	SHA256 string `json:"sha256,omitempty"` // Used for archive sources
 }
 
-// DisplayName returns the repo or URL for display purposes
+// DisplayName returns the repo, URL, or path for display purposes
 func (m Marketplace) DisplayName() string {
 	if m.Repo != "" {
 		return m.Repo
 	}
+	if m.URL != "" {
+		return m.URL
+	}
+	return m.Path
+}
+
+// CloneURL returns the git URL the marketplace is cloned from
+func (m Marketplace) CloneURL() string {
+	if m.Repo != "" {
+		return "https://github.com/" + m.Repo
+	}
 	return m.URL
 }
 
+// Mirrored returns m as a git source at its mirror when one of cfg's URL
+// rewrites matches, or m unchanged
+func (m Marketplace) Mirrored(cfg *config.GlobalConfig) Marketplace {
+	if m.Source == MarketplaceLocal || m.Source == MarketplaceArchive {
+		return m
+	}
+	if url, ok := cfg.RewriteURL(m.CloneURL()); ok {
+		return Marketplace{Source: "git", URL: url}
+	}
+	return m
+}
+
 // SecretRef defines a secret requirement with multiple resolution sources
 type SecretRef struct {
-	Description string         `json:"description,omitempty"`
-	Sources     []SecretSource `json:"sources"`
+	Description string            `json:"description,omitempty"`
+	Sources     []SecretSource    `json:"sources"`
+	Validate    *SecretValidation `json:"validate,omitempty"`
+	Optional    bool              `json:"optional,omitempty"` // install the server without it if unresolved
+}
+
+// clone returns a deep copy of the secret reference
+func (r SecretRef) clone() SecretRef {
+	c := SecretRef{Description: r.Description, Sources: make([]SecretSource, len(r.Sources)), Optional: r.Optional}
+	copy(c.Sources, r.Sources)
+	if r.Validate != nil {
+		v := *r.Validate
+		c.Validate = &v
+	}
+	return c
 }
 
 // SecretSource defines a single source for resolving a secret
 type SecretSource struct {
-	Type    string `json:"type"`              // env, 1password, keychain
-	Key     string `json:"key,omitempty"`     // for env
+	Type    string `json:"type"`              // env, 1password, keychain, bitwarden, sops
+	Key     string `json:"key,omitempty"`     // for env; for sops, a dotted path in the file
 	Ref     string `json:"ref,omitempty"`     // for 1password
 	Service string `json:"service,omitempty"` // for keychain
 	Account string `json:"account,omitempty"` // for keychain
+	Item    string `json:"item,omitempty"`    // for bitwarden: item ID or name
+	Field   string `json:"field,omitempty"`   // for bitwarden: defaults to password
+	File    string `json:"file,omitempty"`    // for sops: defaults to ~/.claudeup/secrets.enc.yaml
+	Condition
 }
 
 // DetectRules defines how to auto-detect if a profile matches a project
@@ -85,13 +211,47 @@ type DetectRules struct {
 	Contains map[string]string `json:"contains,omitempty"`
 }
 
-// Save writes a profile to the profiles directory
+// Save writes a profile to the profiles directory, stamping its metadata.
+// The content it replaces is kept for 'profile undo' and 'profile log'.
 func Save(profilesDir string, p *Profile) error {
-	if err := os.MkdirAll(profilesDir, 0755); err != nil {
+	p.stampSaved(profilesDir)
+	if ValidateName(p.Name) == nil {
+		keepVersion(profilesDir, p.Name)
+	}
+	return write(profilesDir, p)
+}
+
+// SaveRecord writes a profile like Save without keeping the content it
+// replaces, for copies claudeup keeps for itself, such as the record of
+// applied profiles
+func SaveRecord(dir string, p *Profile) error {
+	p.stampSaved(dir)
+	return write(dir, p)
+}
+
+// SaveDocument writes the named profile's file byte for byte, for content
+// edited by hand. The content it replaces is kept like Save's.
+func SaveDocument(profilesDir, name string, data []byte) error {
+	if err := ValidateName(name); err != nil {
 		return err
 	}
+	keepVersion(profilesDir, name)
+	profilePath := filepath.Join(profilesDir, filepath.FromSlash(name)+".json")
+	if err := os.MkdirAll(filepath.Dir(profilePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(profilePath, data, 0644)
+}
 
+// write saves p as-is, without touching its metadata
+func write(profilesDir string, p *Profile) error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
 	profilePath := filepath.Join(profilesDir, p.Name+".json")
+	if err := os.MkdirAll(filepath.Dir(profilePath), 0755); err != nil {
+		return err
+	}
 
 	data, err := json.MarshalIndent(p, "", "  ")
 	if err != nil {
@@ -101,7 +261,8 @@ func Save(profilesDir string, p *Profile) error {
 	return os.WriteFile(profilePath, data, 0644)
 }
 
-// Load reads a profile from the profiles directory
+// Load reads a profile from the profiles directory. A namespaced profile
+// is named by its path, e.g. team/backend, whatever its file says.
 func Load(profilesDir, name string) (*Profile, error) {
 	profilePath := filepath.Join(profilesDir, name+".json")
 
@@ -114,19 +275,62 @@ func Load(profilesDir, name string) (*Profile, error) {
 	if err := json.Unmarshal(data, &p); err != nil {
 		return nil, err
 	}
+	if Namespace(name) != "" {
+		p.Name = name
+	}
 
 	return &p, nil
 }
 
-// List returns all profiles in the profiles directory, sorted by name
+// Read parses a profile that isn't in a profiles directory, such as one
+// piped in or passed as a path
+func Read(r io.Reader) (*Profile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// List returns all profiles in the profiles directory and its namespaces,
+// sorted by name
 func List(profilesDir string) ([]*Profile, error) {
-	entries, err := os.ReadDir(profilesDir)
+	profiles, err := listDir(profilesDir, "")
 	if os.IsNotExist(err) {
 		return []*Profile{}, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	namespaces, err := Namespaces(profilesDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, ns := range namespaces {
+		inNamespace, err := listDir(profilesDir, ns)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, inNamespace...)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	return profiles, nil
+}
+
+// listDir loads the profiles directly in the namespace ns, "" for none
+func listDir(profilesDir, ns string) ([]*Profile, error) {
+	entries, err := os.ReadDir(filepath.Join(profilesDir, ns))
+	if err != nil {
+		return nil, err
+	}
 
 	var profiles []*Profile
 	for _, entry := range entries {
@@ -138,17 +342,15 @@ func List(profilesDir string) ([]*Profile, error) {
 		}
 
 		name := strings.TrimSuffix(entry.Name(), ".json")
+		if ns != "" {
+			name = ns + "/" + name
+		}
 		p, err := Load(profilesDir, name)
 		if err != nil {
 			continue // Skip invalid profiles
 		}
 		profiles = append(profiles, p)
 	}
-
-	sort.Slice(profiles, func(i, j int) bool {
-		return profiles[i].Name < profiles[j].Name
-	})
-
 	return profiles, nil
 }
 
@@ -157,6 +359,14 @@ func (p *Profile) Clone(newName string) *Profile {
 	clone := &Profile{
 		Name:        newName,
 		Description: p.Description,
+		Type:        p.Type,
+	}
+
+	// Tags describe the contents; the rest of the metadata belongs to the
+	// saved file and is stamped again when the clone is saved
+	if len(p.Tags) > 0 {
+		clone.Tags = make([]string, len(p.Tags))
+		copy(clone.Tags, p.Tags)
 	}
 
 	// Deep copy MCPServers
@@ -164,9 +374,13 @@ func (p *Profile) Clone(newName string) *Profile {
 		clone.MCPServers = make([]MCPServer, len(p.MCPServers))
 		for i, srv := range p.MCPServers {
 			clone.MCPServers[i] = MCPServer{
-				Name:    srv.Name,
-				Command: srv.Command,
-				Scope:   srv.Scope,
+				Name:      srv.Name,
+				Command:   srv.Command,
+				Scope:     srv.Scope,
+				Package:   srv.Package,
+				Version:   srv.Version,
+				Integrity: srv.Integrity,
+				Condition: srv.Condition.clone(),
 			}
 			if len(srv.Args) > 0 {
 				clone.MCPServers[i].Args = make([]string, len(srv.Args))
@@ -175,12 +389,7 @@ func (p *Profile) Clone(newName string) *Profile {
 			if len(srv.Secrets) > 0 {
 				clone.MCPServers[i].Secrets = make(map[string]SecretRef)
 				for k, v := range srv.Secrets {
-					sources := make([]SecretSource, len(v.Sources))
-					copy(sources, v.Sources)
-					clone.MCPServers[i].Secrets[k] = SecretRef{
-						Description: v.Description,
-						Sources:     sources,
-					}
+					clone.MCPServers[i].Secrets[k] = v.clone()
 				}
 			}
 		}
@@ -198,6 +407,29 @@ func (p *Profile) Clone(newName string) *Profile {
 		copy(clone.Plugins, p.Plugins)
 	}
 
+	// Deep copy Groups
+	if len(p.Groups) > 0 {
+		clone.Groups = make(map[string][]string, len(p.Groups))
+		for name, plugins := range p.Groups {
+			clone.Groups[name] = append([]string(nil), plugins...)
+		}
+	}
+	if len(p.DisabledGroups) > 0 {
+		clone.DisabledGroups = append([]string(nil), p.DisabledGroups...)
+	}
+	if len(p.PluginConditions) > 0 {
+		clone.PluginConditions = make(map[string]Condition, len(p.PluginConditions))
+		for plugin, c := range p.PluginConditions {
+			clone.PluginConditions[plugin] = c.clone()
+		}
+	}
+	if len(p.GroupConditions) > 0 {
+		clone.GroupConditions = make(map[string]Condition, len(p.GroupConditions))
+		for name, c := range p.GroupConditions {
+			clone.GroupConditions[name] = c.clone()
+		}
+	}
+
 	// Deep copy Detect
 	if len(p.Detect.Files) > 0 {
 		clone.Detect.Files = make([]string, len(p.Detect.Files))
@@ -219,6 +451,9 @@ func (p *Profile) Clone(newName string) *Profile {
 		clone.Sandbox.Mounts = make([]SandboxMount, len(p.Sandbox.Mounts))
 		copy(clone.Sandbox.Mounts, p.Sandbox.Mounts)
 	}
+	if len(p.Sandbox.Ports) > 0 {
+		clone.Sandbox.Ports = append([]string(nil), p.Sandbox.Ports...)
+	}
 	if len(p.Sandbox.Env) > 0 {
 		clone.Sandbox.Env = make(map[string]string)
 		for k, v := range p.Sandbox.Env {
@@ -226,5 +461,50 @@ func (p *Profile) Clone(newName string) *Profile {
 		}
 	}
 
+	// Deep copy ProvidedMCPServers
+	for _, s := range p.ProvidedMCPServers {
+		s.Args = append([]string(nil), s.Args...)
+		s.Env = append([]string(nil), s.Env...)
+		clone.ProvidedMCPServers = append(clone.ProvidedMCPServers, s)
+	}
+
+	// Deep copy Env
+	if len(p.Env) > 0 {
+		clone.Env = make(map[string]EnvVar)
+		for k, v := range p.Env {
+			if len(v.Sources) > 0 {
+				v.Sources = append([]SecretSource(nil), v.Sources...)
+			}
+			clone.Env[k] = v
+		}
+	}
+
+	// Deep copy ShellEnv
+	if len(p.ShellEnv.Env) > 0 {
+		clone.ShellEnv.Env = make(map[string]string)
+		for k, v := range p.ShellEnv.Env {
+			clone.ShellEnv.Env[k] = v
+		}
+	}
+	if len(p.ShellEnv.Secrets) > 0 {
+		clone.ShellEnv.Secrets = make(map[string]SecretRef)
+		for k, v := range p.ShellEnv.Secrets {
+			clone.ShellEnv.Secrets[k] = v.clone()
+		}
+	}
+
+	clone.SetupWizard = p.SetupWizard.clone()
+	clone.Permissions = p.Permissions.clone()
+
+	// Deep copy Disabled
+	if len(p.Disabled.Plugins) > 0 {
+		clone.Disabled.Plugins = make([]string, len(p.Disabled.Plugins))
+		copy(clone.Disabled.Plugins, p.Disabled.Plugins)
+	}
+	if len(p.Disabled.MCPServers) > 0 {
+		clone.Disabled.MCPServers = make([]string, len(p.Disabled.MCPServers))
+		copy(clone.Disabled.MCPServers, p.Disabled.MCPServers)
+	}
+
 	return clone
 }
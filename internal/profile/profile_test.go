@@ -186,6 +186,7 @@ func TestProfile_Clone(t *testing.T) {
 		Description: "Original description",
 		MCPServers: []MCPServer{
 			{Name: "server1", Command: "cmd1", Args: []string{"arg1"}},
+			{Name: "server2", Command: "npx", Args: []string{"-y", "pkg"}, Package: "pkg", Version: "1.0.0", Integrity: "sha512-abc"},
 		},
 		Marketplaces: []Marketplace{
 			{Source: "github", Repo: "org/repo"},
@@ -195,6 +196,11 @@ func TestProfile_Clone(t *testing.T) {
 
 	cloned := original.Clone("cloned")
 
+	// Verify npx pins copied
+	if got := cloned.MCPServers[1]; got.Package != "pkg" || got.Version != "1.0.0" || got.Integrity != "sha512-abc" {
+		t.Errorf("Clone dropped the npx pin: %+v", got)
+	}
+
 	// Verify name changed
 	if cloned.Name != "cloned" {
 		t.Errorf("Expected cloned name 'cloned', got %q", cloned.Name)
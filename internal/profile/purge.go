@@ -0,0 +1,135 @@
+// ABOUTME: Post-apply phase deleting the cached copies of uninstalled plugins
+// ABOUTME: Only removes cache directories nothing else installed or disabled still points at
+package profile
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// InstallPaths maps each installed plugin to its install path. Capture it
+// before applying: uninstalling drops the plugin from the registry.
+func InstallPaths(claudeDir string) map[string]string {
+	paths := make(map[string]string)
+	registry, err := state.LoadPlugins(claudeDir)
+	if err != nil {
+		return paths
+	}
+	for name, instances := range registry.Plugins {
+		for _, meta := range instances {
+			if meta.InstallPath != "" {
+				paths[name] = meta.InstallPath
+				break
+			}
+		}
+	}
+	return paths
+}
+
+// PurgePluginCaches deletes the install paths, taken from paths, of the
+// plugins the apply removed. A path is kept if it lies outside the plugin
+// cache, e.g. a local plugin, or if a plugin still installed or disabled by
+// claudeup points into it. Deleted paths and their size are added to result.
+func PurgePluginCaches(claudeDir string, paths map[string]string, result *ApplyResult) {
+	if len(result.PluginsRemoved)+len(result.PluginsAlreadyRemoved) == 0 {
+		return
+	}
+	defer func(start time.Time) { result.Elapsed += time.Since(start) }(time.Now())
+	cacheDir := filepath.Join(claudeDir, "plugins", "cache")
+	referenced := referencedInstallPaths(claudeDir)
+
+	for _, plugin := range append(result.PluginsRemoved, result.PluginsAlreadyRemoved...) {
+		path := paths[plugin]
+		if path == "" || !within(path, cacheDir) || path == cacheDir {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if overlapsAny(path, referenced) {
+			continue
+		}
+
+		start := time.Now()
+		size := dirSize(path)
+		if err := os.RemoveAll(path); err != nil {
+			result.record("purge", SubsystemPlugins, plugin, start, fmt.Errorf("failed to delete cache of plugin %s: %w", plugin, err))
+			continue
+		}
+		removeEmptyParents(filepath.Dir(path), cacheDir)
+		result.PluginsPurged = append(result.PluginsPurged, plugin)
+		result.ReclaimedBytes += size
+		result.record("purge", SubsystemPlugins, plugin, start, nil)
+	}
+}
+
+// referencedInstallPaths lists the install paths still in use: every
+// installation in the registry, in any scope, and plugins claudeup disabled
+func referencedInstallPaths(claudeDir string) []string {
+	var paths []string
+	if registry, err := state.LoadPlugins(claudeDir); err == nil {
+		for _, instances := range registry.Plugins {
+			for _, meta := range instances {
+				if meta.InstallPath != "" {
+					paths = append(paths, meta.InstallPath)
+				}
+			}
+		}
+	}
+	if cfg, err := config.Load(); err == nil {
+		for _, disabled := range cfg.DisabledPlugins {
+			if disabled.InstallPath != "" {
+				paths = append(paths, disabled.InstallPath)
+			}
+		}
+	}
+	return paths
+}
+
+// overlapsAny reports whether path is, contains, or lies within any of others
+func overlapsAny(path string, others []string) bool {
+	for _, other := range others {
+		if within(other, path) || within(path, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether path is dir or lies inside it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// removeEmptyParents deletes dir and its ancestors while they are empty,
+// stopping at stop
+func removeEmptyParents(dir, stop string) {
+	for within(dir, stop) && filepath.Clean(dir) != filepath.Clean(stop) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// dirSize sums the sizes of the regular files under dir
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
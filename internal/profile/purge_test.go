@@ -0,0 +1,75 @@
+// ABOUTME: Tests for purging the caches of uninstalled plugins after an apply
+// ABOUTME: Builds a fake plugin cache with shared, disabled, and local install paths
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPurgePluginCaches(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	cache := filepath.Join(claudeDir, "plugins", "cache")
+	os.MkdirAll(filepath.Join(tmpDir, ".claudeup"), 0755)
+
+	paths := map[string]string{
+		"gone@m":   filepath.Join(cache, "m", "gone", "1.0.0"),
+		"shared@m": filepath.Join(cache, "m", "shared", "1.0.0"),
+		"off@m":    filepath.Join(cache, "m", "off", "1.0.0"),
+		"local@m":  filepath.Join(tmpDir, "src", "local"),
+	}
+	for _, dir := range paths {
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "README.md"), []byte("0123456789"), 0644)
+	}
+
+	// After the apply, a project install still uses shared@m's copy and
+	// claudeup keeps off@m's for re-enabling
+	writeTestJSON(t, filepath.Join(claudeDir, "plugins", "installed_plugins.json"), map[string]interface{}{
+		"version": 2,
+		"plugins": map[string]interface{}{
+			"shared@m": []map[string]interface{}{{"scope": "project", "installPath": paths["shared@m"]}},
+		},
+	})
+	writeTestJSON(t, filepath.Join(tmpDir, ".claudeup", "config.json"), map[string]interface{}{
+		"disabledPlugins": map[string]interface{}{"off@m": map[string]interface{}{"installPath": paths["off@m"]}},
+	})
+
+	result := &ApplyResult{PluginsRemoved: []string{"gone@m", "shared@m", "off@m", "local@m"}}
+	PurgePluginCaches(claudeDir, paths, result)
+
+	if len(result.PluginsPurged) != 1 || result.PluginsPurged[0] != "gone@m" {
+		t.Fatalf("Expected only gone@m purged, got %v", result.PluginsPurged)
+	}
+	if result.ReclaimedBytes != 10 {
+		t.Errorf("Expected 10 bytes reclaimed, got %d", result.ReclaimedBytes)
+	}
+	if _, err := os.Stat(filepath.Join(cache, "m", "gone")); !os.IsNotExist(err) {
+		t.Errorf("Expected the emptied plugin directory removed, got %v", err)
+	}
+	for _, kept := range []string{"shared@m", "off@m", "local@m"} {
+		if _, err := os.Stat(paths[kept]); err != nil {
+			t.Errorf("Expected %s's install path kept: %v", kept, err)
+		}
+	}
+	if len(result.Steps) != 1 || result.Steps[0].Item.Action != "purge" || len(result.Errors) != 0 {
+		t.Errorf("Expected one purge step, got %+v", result.Steps)
+	}
+}
+
+func TestInstallPaths(t *testing.T) {
+	claudeDir := t.TempDir()
+	os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755)
+	writeTestJSON(t, filepath.Join(claudeDir, "plugins", "installed_plugins.json"), map[string]interface{}{
+		"version": 2,
+		"plugins": map[string]interface{}{
+			"a@m": []map[string]interface{}{{"scope": "user", "installPath": "/cache/m/a/1.0.0"}},
+		},
+	})
+	if got := InstallPaths(claudeDir); len(got) != 1 || got["a@m"] != "/cache/m/a/1.0.0" {
+		t.Errorf("Unexpected install paths %v", got)
+	}
+}
@@ -0,0 +1,225 @@
+// ABOUTME: Records claude CLI invocations to a fixtures file and replays them later
+// ABOUTME: Lets applies run deterministically in tests and captures traces for bug reports
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+// FixturesVersion is the current format of a fixtures file
+const FixturesVersion = 1
+
+const redacted = "[REDACTED]"
+
+// Invocation is one recorded claude CLI call. Output is only recorded for
+// calls whose output claudeup reads.
+type Invocation struct {
+	Args     []string `json:"args"`
+	Output   string   `json:"output,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	ExitCode int      `json:"exitCode,omitempty"`
+}
+
+// Fixtures is a recording of claude CLI calls
+type Fixtures struct {
+	Version     int          `json:"version"`
+	Recorded    time.Time    `json:"recorded"`
+	Claudeup    string       `json:"claudeupVersion,omitempty"`
+	Invocations []Invocation `json:"invocations"`
+}
+
+// LoadFixtures reads a fixtures file written by a Recorder
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f Fixtures
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid fixtures file %s: %w", path, err)
+	}
+	if f.Version > FixturesVersion {
+		return nil, fmt.Errorf("fixtures file %s has version %d; this claudeup reads up to %d", path, f.Version, FixturesVersion)
+	}
+	return &f, nil
+}
+
+// Save writes the fixtures to path
+func (f *Fixtures) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// Recorder runs commands with another executor and records each call and
+// its result. Resolved secrets are replaced with [REDACTED].
+type Recorder struct {
+	executor CommandExecutor
+
+	mu       sync.Mutex
+	hide     []string
+	fixtures Fixtures
+}
+
+// NewRecorder records the calls made through executor
+func NewRecorder(executor CommandExecutor) *Recorder {
+	return &Recorder{executor: executor, fixtures: Fixtures{Version: FixturesVersion, Recorded: time.Now().UTC()}}
+}
+
+// Run runs and records a command whose output isn't read
+func (r *Recorder) Run(ctx context.Context, args ...string) error {
+	err := r.executor.Run(ctx, args...)
+	r.record(args, "", err)
+	return err
+}
+
+// RunWithOutput runs and records a command along with its output
+func (r *Recorder) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	output, err := r.executor.RunWithOutput(ctx, args...)
+	r.record(args, output, err)
+	return output, err
+}
+
+// Fixtures returns what has been recorded so far
+func (r *Recorder) Fixtures() *Fixtures {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.fixtures
+	f.Invocations = append([]Invocation(nil), f.Invocations...)
+	return &f
+}
+
+// hideValues adds secret values to redact from later recordings
+func (r *Recorder) hideValues(values []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range values {
+		if v != "" {
+			r.hide = append(r.hide, v)
+		}
+	}
+}
+
+func (r *Recorder) record(args []string, output string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	inv := Invocation{Args: make([]string, len(args)), Output: r.redact(output)}
+	for i, a := range args {
+		inv.Args[i] = r.redact(a)
+	}
+	if err != nil {
+		inv.Error = r.redact(err.Error())
+		inv.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			inv.ExitCode = exitErr.ExitCode()
+		}
+	}
+	r.fixtures.Invocations = append(r.fixtures.Invocations, inv)
+}
+
+func (r *Recorder) redact(s string) string {
+	for _, secret := range r.hide {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+// Replayer answers claude commands from a recording instead of running the
+// CLI. Each call is matched against the first unused invocation with the
+// same arguments, so changes may be replayed in any order; [REDACTED] in a
+// recorded argument matches any text.
+type Replayer struct {
+	mu       sync.Mutex
+	fixtures *Fixtures
+	used     []bool
+}
+
+// NewReplayer replays the invocations in fixtures
+func NewReplayer(fixtures *Fixtures) *Replayer {
+	return &Replayer{fixtures: fixtures, used: make([]bool, len(fixtures.Invocations))}
+}
+
+// ReplayedError is the recorded failure of a replayed command
+type ReplayedError struct {
+	Message  string
+	ExitCode int
+}
+
+func (e *ReplayedError) Error() string {
+	return e.Message
+}
+
+// Run replays a command, returning its recorded error
+func (p *Replayer) Run(ctx context.Context, args ...string) error {
+	_, err := p.RunWithOutput(ctx, args...)
+	return err
+}
+
+// RunWithOutput replays a command, returning its recorded output and error
+func (p *Replayer) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, inv := range p.fixtures.Invocations {
+		if p.used[i] || !argsMatch(inv.Args, args) {
+			continue
+		}
+		p.used[i] = true
+		if inv.Error != "" {
+			return inv.Output, &ReplayedError{Message: inv.Error, ExitCode: inv.ExitCode}
+		}
+		return inv.Output, nil
+	}
+	return "", fmt.Errorf("no recorded invocation matches: %s", ui.CommandLine("claude", args))
+}
+
+// Unused returns the recorded invocations that were never replayed
+func (p *Replayer) Unused() []Invocation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var unused []Invocation
+	for i, inv := range p.fixtures.Invocations {
+		if !p.used[i] {
+			unused = append(unused, inv)
+		}
+	}
+	return unused
+}
+
+func argsMatch(recorded, args []string) bool {
+	if len(recorded) != len(args) {
+		return false
+	}
+	for i, want := range recorded {
+		if want == args[i] {
+			continue
+		}
+		if !strings.Contains(want, redacted) {
+			return false
+		}
+		parts := strings.Split(want, redacted)
+		for j, part := range parts {
+			parts[j] = regexp.QuoteMeta(part)
+		}
+		if !regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(args[i]) {
+			return false
+		}
+	}
+	return true
+}
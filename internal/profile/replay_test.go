@@ -0,0 +1,82 @@
+// ABOUTME: Tests for recording claude invocations and replaying them
+// ABOUTME: Covers secret redaction, the fixtures file round trip, and replay matching
+package profile
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewRecorder(&scriptedExecutor{
+		already: map[string]bool{"b@m": true},
+	})
+	executor := traceCommands(ctx, recorder, map[string]string{"API_KEY": "sk-secret"})
+
+	executor.RunWithOutput(ctx, "plugin", "install", "a@m")
+	executor.RunWithOutput(ctx, "plugin", "install", "b@m")
+	executor.Run(ctx, "mcp", "add", "db", "-s", "user", "--", "db-mcp", "--key=sk-secret")
+
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	fixtures := recorder.Fixtures()
+	if err := fixtures.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if got := fixtures.Invocations[2].Args[7]; got != "--key=[REDACTED]" {
+		t.Errorf("Expected the secret redacted, got %q", got)
+	}
+	if got := fixtures.Invocations[1]; got.Output != "Plugin is already installed" || got.Error != "exit status 1" {
+		t.Errorf("Expected the failure recorded, got %+v", got)
+	}
+
+	loaded, err := LoadFixtures(path)
+	if err != nil {
+		t.Fatalf("LoadFixtures failed: %v", err)
+	}
+	replayer := NewReplayer(loaded)
+
+	// Any order, and any value where a secret was redacted
+	if err := replayer.Run(ctx, "mcp", "add", "db", "-s", "user", "--", "db-mcp", "--key=sk-other"); err != nil {
+		t.Errorf("Expected the redacted argument to match, got %v", err)
+	}
+	output, err := replayer.RunWithOutput(ctx, "plugin", "install", "b@m")
+	var replayed *ReplayedError
+	if !errors.As(err, &replayed) || !IsAlreadyInstalledOutput(output) {
+		t.Errorf("Expected the recorded failure, got %q, %v", output, err)
+	}
+	if _, err := replayer.RunWithOutput(ctx, "plugin", "install", "c@m"); err == nil || !strings.Contains(err.Error(), "no recorded invocation") {
+		t.Errorf("Expected an unrecorded command to fail, got %v", err)
+	}
+
+	unused := replayer.Unused()
+	if len(unused) != 1 || unused[0].Args[2] != "a@m" {
+		t.Errorf("Unused() = %+v, want the a@m install", unused)
+	}
+}
+
+func TestReplayEachInvocationOnce(t *testing.T) {
+	replayer := NewReplayer(&Fixtures{Version: FixturesVersion, Invocations: []Invocation{
+		{Args: []string{"mcp", "remove", "db"}},
+	}})
+	ctx := context.Background()
+	if err := replayer.Run(ctx, "mcp", "remove", "db"); err != nil {
+		t.Fatalf("First replay failed: %v", err)
+	}
+	if err := replayer.Run(ctx, "mcp", "remove", "db"); err == nil {
+		t.Error("Expected the second call to find nothing left to replay")
+	}
+}
+
+func TestLoadFixturesRejectsNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	if err := (&Fixtures{Version: FixturesVersion + 1}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFixtures(path); err == nil {
+		t.Error("Expected an error for a newer fixtures version")
+	}
+}
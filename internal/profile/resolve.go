@@ -0,0 +1,100 @@
+// ABOUTME: Resolves a profile with its addons and plugin groups into the flat profile that gets applied
+// ABOUTME: Records which profile, or which of its groups, contributed each entry
+package profile
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Kinds of entries in a Provenance
+const (
+	EntryMarketplace    = "marketplace"
+	EntryPlugin         = "plugin"
+	EntryMCPServer      = "mcp"
+	EntryDisabledPlugin = "disabled-plugin"
+	EntryDisabledMCP    = "disabled-mcp"
+	EntryEnv            = "env"
+	EntryAllow          = "allow"
+	EntryDirectory      = "directory"
+)
+
+// Provenance records where each entry of a resolved profile came from: a
+// profile's name, followed by the plugin group when one added it
+type Provenance map[string]string
+
+// Of returns where the entry of the given kind and name came from
+func (pv Provenance) Of(kind, name string) string {
+	return pv[kind+" "+name]
+}
+
+// Resolve merges base and addons, folds in every enabled plugin group, and
+// sorts each list, giving the profile that would be applied. The first
+// profile to add an entry is credited with it, as Merge keeps the first.
+func Resolve(base *Profile, addons ...*Profile) (*Profile, Provenance, error) {
+	merged, err := Merge(base, addons...)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolved, err := merged.ForThisMachine()
+	if err != nil {
+		return nil, nil, err
+	}
+	resolved.sortEntries()
+
+	pv := make(Provenance)
+	if base != nil {
+		pv.credit(base)
+	}
+	for _, addon := range addons {
+		pv.credit(addon)
+	}
+	return resolved, pv, nil
+}
+
+// credit records p as the source of its entries not yet credited
+func (pv Provenance) credit(p *Profile) {
+	add := func(kind, from string, names ...string) {
+		for _, name := range names {
+			key := kind + " " + name
+			if _, ok := pv[key]; !ok {
+				pv[key] = from
+			}
+		}
+	}
+	for _, m := range p.Marketplaces {
+		add(EntryMarketplace, p.Name, m.DisplayName())
+	}
+	add(EntryPlugin, p.Name, p.Plugins...)
+	for _, group := range p.GroupNames() {
+		if p.GroupEnabled(group) {
+			add(EntryPlugin, p.Name+" (group "+group+")", p.Groups[group]...)
+		}
+	}
+	for _, m := range p.MCPServers {
+		add(EntryMCPServer, p.Name, m.Name)
+	}
+	add(EntryDisabledPlugin, p.Name, p.Disabled.Plugins...)
+	add(EntryDisabledMCP, p.Name, p.Disabled.MCPServers...)
+	for k := range p.ShellEnv.Env {
+		add(EntryEnv, p.Name, k)
+	}
+	add(EntryAllow, p.Name, p.Permissions.Allow...)
+	add(EntryDirectory, p.Name, p.Permissions.AdditionalDirectories...)
+}
+
+// sortEntries puts every list in a stable order for display and diffing
+func (p *Profile) sortEntries() {
+	sort.Strings(p.Plugins)
+	sort.Strings(p.Disabled.Plugins)
+	sort.Strings(p.Disabled.MCPServers)
+	sort.Strings(p.Permissions.Allow)
+	sort.Strings(p.Permissions.AdditionalDirectories)
+	slices.SortStableFunc(p.Marketplaces, func(a, b Marketplace) int {
+		return strings.Compare(a.DisplayName(), b.DisplayName())
+	})
+	slices.SortStableFunc(p.MCPServers, func(a, b MCPServer) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
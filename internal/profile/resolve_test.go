@@ -0,0 +1,67 @@
+// ABOUTME: Tests for resolving a profile with addons and plugin groups
+// ABOUTME: Checks the flattened, sorted result and the source credited for each entry
+package profile
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	base := &Profile{
+		Name:           "backend",
+		Marketplaces:   []Marketplace{{Source: "github", Repo: "obra/superpowers"}},
+		Plugins:        []string{"zeta@sp", "alpha@sp"},
+		Groups:         map[string][]string{"db": {"pg@sp"}, "off": {"x@sp"}},
+		DisabledGroups: []string{"off"},
+		MCPServers:     []MCPServer{{Name: "db", Command: "pg-mcp"}},
+	}
+	addon := &Profile{
+		Name:         "frontend",
+		Type:         TypeAddon,
+		Marketplaces: []Marketplace{{Source: "github", Repo: "acme/web"}},
+		Plugins:      []string{"alpha@sp", "web@acme"},
+		MCPServers:   []MCPServer{{Name: "browser", Command: "npx"}},
+		Permissions:  PermissionsConfig{Allow: []string{"Bash(npm test)"}},
+	}
+
+	resolved, pv, err := Resolve(base, addon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(resolved.Plugins, " "); got != "alpha@sp pg@sp web@acme zeta@sp" {
+		t.Errorf("Expected sorted plugins with the enabled group folded in, got %q", got)
+	}
+	if len(resolved.Groups) != 0 || resolved.Name != "backend" || resolved.IsAddon() {
+		t.Errorf("Expected a flat full profile, got %+v", resolved)
+	}
+	if resolved.MCPServers[0].Name != "browser" || resolved.Marketplaces[0].Repo != "acme/web" {
+		t.Errorf("Expected sorted MCP servers and marketplaces, got %+v %+v", resolved.MCPServers, resolved.Marketplaces)
+	}
+	if base.Plugins[0] != "zeta@sp" {
+		t.Errorf("Expected the base profile left unsorted, got %v", base.Plugins)
+	}
+
+	tests := []struct{ kind, name, want string }{
+		{EntryPlugin, "alpha@sp", "backend"},
+		{EntryPlugin, "pg@sp", "backend (group db)"},
+		{EntryPlugin, "web@acme", "frontend"},
+		{EntryPlugin, "x@sp", ""},
+		{EntryMCPServer, "browser", "frontend"},
+		{EntryMarketplace, "obra/superpowers", "backend"},
+		{EntryAllow, "Bash(npm test)", "frontend"},
+	}
+	for _, tt := range tests {
+		if got := pv.Of(tt.kind, tt.name); got != tt.want {
+			t.Errorf("%s %s: expected source %q, got %q", tt.kind, tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestResolveConflict(t *testing.T) {
+	base := &Profile{Name: "a", MCPServers: []MCPServer{{Name: "db", Command: "one"}}}
+	addon := &Profile{Name: "b", Type: TypeAddon, MCPServers: []MCPServer{{Name: "db", Command: "two"}}}
+	if _, _, err := Resolve(base, addon); err == nil {
+		t.Error("Expected conflicting MCP servers to fail")
+	}
+}
@@ -0,0 +1,65 @@
+// ABOUTME: Optional validation of resolved secrets before they are used
+// ABOUTME: A secret can require a regex pattern and/or a probe command that must succeed
+package profile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/cmdline"
+)
+
+// SecretValidation checks a resolved secret so a typo'd or expired value
+// fails before anything is configured with it
+type SecretValidation struct {
+	Pattern string `json:"pattern,omitempty"` // regular expression the whole value must match
+	Command string `json:"command,omitempty"` // shell command that must exit 0; the value is in $<NAME>
+}
+
+// secretProbeTimeout bounds how long a validation command may run
+const secretProbeTimeout = 10 * time.Second
+
+// ValidateSecret checks value, the resolved secret called name, against v.
+// A nil v always passes. Errors never include the value.
+func ValidateSecret(ctx context.Context, name, value string, v *SecretValidation) error {
+	if v == nil {
+		return nil
+	}
+
+	if v.Pattern != "" {
+		re, err := regexp.Compile("^(?:" + v.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("secret %s has an invalid validation pattern: %w", name, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("secret %s does not match the expected pattern %s", name, v.Pattern)
+		}
+	}
+
+	if v.Command != "" {
+		ctx, cancel := context.WithTimeout(ctx, secretProbeTimeout)
+		defer cancel()
+
+		cmd := cmdline.Shell(ctx, v.Command)
+		cmd.Env = append(os.Environ(), name+"="+value)
+		var output bytes.Buffer
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		if err := cmd.Run(); err != nil {
+			detail := strings.TrimSpace(strings.ReplaceAll(output.String(), value, "***"))
+			if detail == "" {
+				detail = err.Error()
+			}
+			if ctx.Err() == context.DeadlineExceeded {
+				detail = fmt.Sprintf("timed out after %s", secretProbeTimeout)
+			}
+			return fmt.Errorf("secret %s failed its validation command: %s", name, detail)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// ABOUTME: Unit tests for validating resolved secrets and optional secrets
+// ABOUTME: Covers patterns, probe commands, and how applies treat invalid or missing values
+package profile
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/secrets"
+	"github.com/claudeup/claudeup/internal/ui"
+)
+
+func TestValidateSecretPattern(t *testing.T) {
+	v := &SecretValidation{Pattern: `sk-[A-Za-z0-9]+`}
+	if err := ValidateSecret(context.Background(), "OPENAI_API_KEY", "sk-abc123", v); err != nil {
+		t.Errorf("Expected a match, got %v", err)
+	}
+	err := ValidateSecret(context.Background(), "OPENAI_API_KEY", "xsk-abc123 ", v)
+	if err == nil || !strings.Contains(err.Error(), "OPENAI_API_KEY") {
+		t.Errorf("Expected the whole value to be matched, got %v", err)
+	}
+	if err := ValidateSecret(context.Background(), "KEY", "value", &SecretValidation{Pattern: "("}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+	if err := ValidateSecret(context.Background(), "KEY", "anything", nil); err != nil {
+		t.Errorf("No validation should always pass, got %v", err)
+	}
+}
+
+func TestValidateSecretCommand(t *testing.T) {
+	ok := &SecretValidation{Command: `test "$API_TOKEN" = good`}
+	if err := ValidateSecret(context.Background(), "API_TOKEN", "good", ok); err != nil {
+		t.Errorf("Expected the probe to pass, got %v", err)
+	}
+
+	probe := &SecretValidation{Command: `echo "token $API_TOKEN is expired" >&2; exit 1`}
+	err := ValidateSecret(context.Background(), "API_TOKEN", "tok-secret", probe)
+	if err == nil {
+		t.Fatal("Expected the probe to fail")
+	}
+	if strings.Contains(err.Error(), "tok-secret") {
+		t.Errorf("Error must not include the secret, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "is expired") {
+		t.Errorf("Expected the probe's output in the error, got %v", err)
+	}
+}
+
+func TestApplyDiffStopsOnInvalidSecret(t *testing.T) {
+	t.Setenv("DB_URL", "not-a-url")
+	executor := &okExecutor{}
+	diff := &Diff{
+		PluginsToInstall: []string{"a@m"},
+		MCPToInstall: []MCPServer{{
+			Name:    "db",
+			Command: "pg-mcp",
+			Secrets: map[string]SecretRef{"DB_URL": {
+				Sources:  []SecretSource{{Type: "env", Key: "DB_URL"}},
+				Validate: &SecretValidation{Pattern: `postgres://.+`},
+			}},
+		}},
+	}
+
+	_, err := ApplyDiff(context.Background(), diff, secrets.NewChain(secrets.NewEnvResolver()), executor)
+	if err == nil || !strings.Contains(err.Error(), "MCP server db") {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	if len(executor.calls) != 0 {
+		t.Errorf("Nothing should change when a secret is invalid, got %v", executor.calls)
+	}
+}
+
+func TestApplyDiffInstallsWithoutOptionalSecret(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	server := MCPServer{
+		Name:    "gh",
+		Command: "gh-mcp",
+		Args:    []string{"$GH_TOKEN"},
+		Secrets: map[string]SecretRef{"GH_TOKEN": {
+			Optional: true,
+			Sources:  []SecretSource{{Type: "env", Key: "GH_TOKEN"}},
+		}},
+	}
+	chain := secrets.NewChain(secrets.NewEnvResolver())
+	executor := &okExecutor{}
+
+	result, err := ApplyDiff(context.Background(), &Diff{MCPToInstall: []MCPServer{server}}, chain, executor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.UnresolvedSecrets) != 1 || result.UnresolvedSecrets[0].EnvVar != "GH_TOKEN" {
+		t.Errorf("Expected GH_TOKEN reported as unresolved, got %+v", result.UnresolvedSecrets)
+	}
+
+	executor.calls = nil
+	if err := ReinstallMCP(context.Background(), server, chain, executor, map[string]string{"GH_TOKEN": "tok"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "mcp remove gh | mcp add gh -s user -- gh-mcp tok"
+	var got []string
+	for _, call := range executor.calls {
+		got = append(got, strings.Join(call, " "))
+	}
+	if strings.Join(got, " | ") != want {
+		t.Errorf("Expected %q, got %q", want, strings.Join(got, " | "))
+	}
+}
+
+func TestApplyDiffVerboseHidesSecrets(t *testing.T) {
+	t.Setenv("GH_TOKEN", "ghp_supersecret")
+	var buf bytes.Buffer
+	p := ui.NewPrinter(&buf, &buf, false)
+	p.SetVerbose(true)
+	ctx := ui.WithPrinter(context.Background(), p)
+
+	diff := &Diff{MCPToInstall: []MCPServer{{
+		Name:    "gh",
+		Command: "gh-mcp",
+		Args:    []string{"--token", "$GH_TOKEN"},
+		Secrets: map[string]SecretRef{"GH_TOKEN": {Sources: []SecretSource{{Type: "env", Key: "GH_TOKEN"}}}},
+	}}}
+	executor := &okExecutor{}
+	if _, err := ApplyDiff(ctx, diff, secrets.NewChain(secrets.NewEnvResolver()), executor); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := executor.calls[0][len(executor.calls[0])-1]; got != "ghp_supersecret" {
+		t.Errorf("the executor should get the real secret, got %q", got)
+	}
+	want := "  → claude mcp add gh -s user -- gh-mcp --token '[REDACTED]'\n"
+	if buf.String() != want {
+		t.Errorf("verbose output = %q, want %q", buf.String(), want)
+	}
+}
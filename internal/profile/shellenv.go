@@ -0,0 +1,57 @@
+// ABOUTME: Resolves the environment variables a profile exports to shell sessions
+// ABOUTME: Combines shellEnv settings with the secrets the profile's MCP servers need
+package profile
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/claudeup/claudeup/internal/secrets"
+)
+
+// ResolveShellEnv returns the variables 'claudeup env' exports for the
+// profile: MCP server secrets, then shellEnv secrets, then static shellEnv
+// values, later entries winning. Secrets that can't be resolved or fail
+// validation are skipped and reported in the returned errors.
+func (p *Profile) ResolveShellEnv(secretChain *secrets.Chain) (map[string]string, []error) {
+	vars := make(map[string]string)
+	var errs []error
+
+	for _, mcp := range p.MCPServers {
+		for envVar, ref := range mcp.Secrets {
+			value, ok := ResolveSecret(ref, secretChain)
+			if !ok {
+				if !ref.Optional {
+					errs = append(errs, fmt.Errorf("could not resolve secret %s for MCP server %s", envVar, mcp.Name))
+				}
+				continue
+			}
+			if err := ValidateSecret(context.Background(), envVar, value, ref.Validate); err != nil {
+				errs = append(errs, fmt.Errorf("MCP server %s: %w", mcp.Name, err))
+				continue
+			}
+			vars[envVar] = value
+		}
+	}
+
+	for envVar, ref := range p.ShellEnv.Secrets {
+		value, ok := ResolveSecret(ref, secretChain)
+		if !ok {
+			delete(vars, envVar)
+			errs = append(errs, fmt.Errorf("could not resolve secret %s", envVar))
+			continue
+		}
+		if err := ValidateSecret(context.Background(), envVar, value, ref.Validate); err != nil {
+			delete(vars, envVar)
+			errs = append(errs, err)
+			continue
+		}
+		vars[envVar] = value
+	}
+
+	for envVar, value := range p.ShellEnv.Env {
+		vars[envVar] = value
+	}
+
+	return vars, errs
+}
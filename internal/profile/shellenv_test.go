@@ -0,0 +1,46 @@
+// ABOUTME: Tests for resolving a profile's shell environment
+// ABOUTME: Uses the env resolver so no external secret managers are needed
+package profile
+
+import (
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/secrets"
+)
+
+func TestResolveShellEnv(t *testing.T) {
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_123")
+	t.Setenv("TEST_OVERRIDE", "from-secret")
+
+	p := &Profile{
+		Name: "test",
+		MCPServers: []MCPServer{{
+			Name: "github",
+			Secrets: map[string]SecretRef{
+				"GITHUB_TOKEN": {Sources: []SecretSource{{Type: "env", Key: "TEST_GITHUB_TOKEN"}}},
+				"MISSING_KEY":  {Sources: []SecretSource{{Type: "env", Key: "TEST_NOT_SET"}}},
+			},
+		}},
+		ShellEnv: ShellEnvConfig{
+			Env: map[string]string{"EDITOR": "vim"},
+			Secrets: map[string]SecretRef{
+				"OVERRIDE": {Sources: []SecretSource{{Type: "env", Key: "TEST_OVERRIDE"}}},
+			},
+		},
+	}
+
+	vars, errs := p.ResolveShellEnv(secrets.NewChain(secrets.NewEnvResolver()))
+
+	want := map[string]string{"GITHUB_TOKEN": "ghp_123", "OVERRIDE": "from-secret", "EDITOR": "vim"}
+	for k, v := range want {
+		if vars[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, vars[k])
+		}
+	}
+	if _, ok := vars["MISSING_KEY"]; ok {
+		t.Error("Expected unresolved secret to be skipped")
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected 1 resolution error, got %v", errs)
+	}
+}
@@ -0,0 +1,133 @@
+// ABOUTME: Simulate applies a profile into a scratch Claude config directory through DirectExecutor
+// ABOUTME: Secrets stay $NAME placeholders, so nothing is resolved and no real setup is touched
+package profile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/secrets"
+)
+
+// Simulation is what applying a profile into a scratch directory did
+type Simulation struct {
+	Dir    string
+	Diff   *Diff
+	Result *ApplyResult
+	Files  []string // Files created or changed, slash-separated and relative to Dir
+}
+
+// SimulationProject is the directory under a simulation's Dir that stands in
+// for the current project, holding project-scoped MCP servers
+const SimulationProject = "project"
+
+// Simulate applies p into dir as if dir were CLAUDE_CONFIG_DIR, editing the
+// registry files directly instead of running the claude CLI. claudeup's own
+// state goes to dir/.claudeup. Whatever dir already holds is the starting
+// point, so an empty dir shows the profile on a fresh machine.
+//
+// Secrets aren't resolved: MCP servers get $NAME placeholders in place of
+// their values, and npm pins aren't checked against the registry. Archive
+// marketplaces are still downloaded; git marketplaces aren't cloned, so their
+// plugins are recorded without checking they exist.
+func Simulate(ctx context.Context, p *Profile, dir string) (*Simulation, error) {
+	paths := pathctx.Default()
+	paths.ClaudeDir = dir
+	paths.ClaudeJSON = filepath.Join(dir, ".claude.json")
+	paths.ClaudeupDir = filepath.Join(dir, ".claudeup")
+	defer pathctx.Override(paths)()
+	ResetSnapshotCache()
+	defer ResetSnapshotCache()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	before, err := readTree(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	p = withPlaceholderSecrets(p)
+	diff, err := ComputeDiff(p, dir, paths.ClaudeJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute diff: %w", err)
+	}
+	executor := &DirectExecutor{ClaudeDir: dir, ClaudeJSON: paths.ClaudeJSON, ProjectDir: filepath.Join(dir, SimulationProject)}
+	result, err := ApplyPlanned(ctx, diff, dir, secrets.NewChain(placeholderResolver{}), executor)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := readTree(dir)
+	if err != nil {
+		return nil, err
+	}
+	sim := &Simulation{Dir: dir, Diff: diff, Result: result}
+	for path, data := range after {
+		if old, ok := before[path]; !ok || !bytes.Equal(old, data) {
+			sim.Files = append(sim.Files, path)
+		}
+	}
+	sort.Strings(sim.Files)
+	return sim, nil
+}
+
+// withPlaceholderSecrets copies p with every MCP secret read from an env
+// variable of its own name, for placeholderResolver to answer, and without
+// npm integrity pins
+func withPlaceholderSecrets(p *Profile) *Profile {
+	c := p.Clone(p.Name)
+	for i := range c.MCPServers {
+		c.MCPServers[i].Integrity = ""
+		for envVar, ref := range c.MCPServers[i].Secrets {
+			c.MCPServers[i].Secrets[envVar] = SecretRef{
+				Description: ref.Description,
+				Sources:     []SecretSource{{Type: "env", Key: envVar}},
+				Optional:    ref.Optional,
+			}
+		}
+	}
+	return c
+}
+
+// placeholderResolver resolves every secret to a $NAME reference to itself
+type placeholderResolver struct{}
+
+func (placeholderResolver) Name() string                       { return "placeholder" }
+func (placeholderResolver) Available() bool                    { return true }
+func (placeholderResolver) Resolve(ref string) (string, error) { return "$" + ref, nil }
+
+// readTree reads every regular file under dir, keyed by its slash-separated
+// path relative to dir
+func readTree(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
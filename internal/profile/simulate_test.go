@@ -0,0 +1,69 @@
+// ABOUTME: Tests for Simulate, which applies a profile into a scratch Claude config directory
+// ABOUTME: Checks the files written, the secret placeholders, and that the real paths are untouched
+package profile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+func TestSimulateWritesIntoScratchDir(t *testing.T) {
+	home := t.TempDir()
+	defer pathctx.Override(pathctx.ForHome(home))()
+	t.Setenv("API_TOKEN", "real-value")
+	dir := filepath.Join(t.TempDir(), "scratch")
+
+	p := &Profile{
+		Name:         "team",
+		Marketplaces: []Marketplace{{Source: MarketplaceLocal, Path: writeMarketplace(t, "tools", "lint", "1.0.0")}},
+		Plugins:      []string{"lint@tools"},
+		MCPServers: []MCPServer{
+			{Name: "api", Command: "api-mcp", Args: []string{"--token", "$API_TOKEN"}, Integrity: "sha512-abc",
+				Secrets: map[string]SecretRef{"API_TOKEN": {Sources: []SecretSource{{Type: "1password", Ref: "op://vault/api"}}}}},
+			{Name: "docs", Command: "docs-mcp", Scope: "project"},
+		},
+	}
+	sim, err := Simulate(context.Background(), p, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sim.Result.Errors) > 0 {
+		t.Fatalf("Expected a clean simulation, got %v", sim.Result.Errors)
+	}
+
+	want := []string{".claude.json", "plugins/installed_plugins.json", "plugins/known_marketplaces.json", "project/.mcp.json", "settings.json"}
+	if !reflect.DeepEqual(sim.Files, want) {
+		t.Errorf("Expected files %v, got %v", want, sim.Files)
+	}
+	servers, err := state.LoadMCPServers(filepath.Join(dir, ".claude.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args := servers["api"].Args; !reflect.DeepEqual(args, []string{"--token", "$API_TOKEN"}) {
+		t.Errorf("Expected the secret left as a placeholder, got %v", args)
+	}
+	if p.MCPServers[0].Integrity == "" || p.MCPServers[0].Secrets["API_TOKEN"].Sources[0].Type != "1password" {
+		t.Error("Expected the caller's profile to be left unchanged")
+	}
+	if _, err := os.Stat(filepath.Join(home, ".claude")); !os.IsNotExist(err) {
+		t.Errorf("Expected the real Claude directory untouched, got %v", err)
+	}
+	if pathctx.Default().ClaudeDir != filepath.Join(home, ".claude") {
+		t.Error("Expected the paths restored after the simulation")
+	}
+
+	// A second run starts from the first one's files and changes nothing
+	again, err := Simulate(context.Background(), p, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Files) != 0 {
+		t.Errorf("Expected no files changed, got %v", again.Files)
+	}
+}
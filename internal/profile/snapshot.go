@@ -1,50 +1,28 @@
 // ABOUTME: Creates a profile from current Claude Code state
-// ABOUTME: Reads installed plugins, marketplaces, and MCP servers
+// ABOUTME: Reads installed plugins, marketplaces, MCP servers, and disabled items
 package profile
 
 import (
-	"encoding/json"
-	"os"
-	"path/filepath"
 	"sort"
 
-	"github.com/claudeup/claudeup/internal/claude"
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/state"
 )
 
-// ClaudeJSON represents the ~/.claude.json file structure (relevant parts)
-type ClaudeJSON struct {
-	MCPServers map[string]ClaudeMCPServer `json:"mcpServers"`
-}
-
-// ClaudeMCPServer represents an MCP server in ~/.claude.json
-type ClaudeMCPServer struct {
-	Type    string            `json:"type"`
-	Command string            `json:"command"`
-	Args    []string          `json:"args"`
-	Env     map[string]string `json:"env"`
-}
-
-// MarketplaceRegistry represents known_marketplaces.json
-type MarketplaceRegistry map[string]MarketplaceMetadata
+// Snapshot creates a Profile from the current Claude Code state
+// Results are cached in-process and reused until the underlying files change
+func Snapshot(name, claudeDir, claudeJSONPath string) (*Profile, error) {
+	state := cachedState(claudeDir, claudeJSONPath)
 
-// MarketplaceMetadata represents metadata for a marketplace
-type MarketplaceMetadata struct {
-	Source MarketplaceSource `json:"source"`
+	p := state.Clone(name)
+	p.Description = "Snapshot of current Claude Code configuration"
+	return p, nil
 }
 
-// MarketplaceSource represents the source of a marketplace
-type MarketplaceSource struct {
-	Source string `json:"source"`
-	Repo   string `json:"repo,omitempty"`
-	URL    string `json:"url,omitempty"`
-}
-
-// Snapshot creates a Profile from the current Claude Code state
-func Snapshot(name, claudeDir, claudeJSONPath string) (*Profile, error) {
-	p := &Profile{
-		Name:        name,
-		Description: "Snapshot of current Claude Code configuration",
-	}
+// readState reads plugins, marketplaces, MCP servers, and claudeup's
+// disabled items from disk
+func readState(claudeDir, claudeJSONPath string) *Profile {
+	p := &Profile{}
 
 	// Read plugins
 	plugins, err := readPlugins(claudeDir)
@@ -64,16 +42,51 @@ func Snapshot(name, claudeDir, claudeJSONPath string) (*Profile, error) {
 		p.MCPServers = mcpServers
 	}
 
-	return p, nil
+	// Disabled plugins are absent from Claude's registry but still installed
+	cfg, err := config.LoadExisting()
+	if err == nil {
+		p.Disabled = readDisabled(cfg)
+		p.Plugins = appendMissing(p.Plugins, p.Disabled.Plugins...)
+		sort.Strings(p.Plugins)
+	}
+
+	return p
+}
+
+func readDisabled(cfg *config.GlobalConfig) DisabledConfig {
+	var disabled DisabledConfig
+	for name := range cfg.DisabledPlugins {
+		disabled.Plugins = append(disabled.Plugins, name)
+	}
+	sort.Strings(disabled.Plugins)
+
+	disabled.MCPServers = append(disabled.MCPServers, cfg.DisabledMCPServers...)
+	sort.Strings(disabled.MCPServers)
+
+	return disabled
+}
+
+// FromState builds a profile from a captured state, such as a stored
+// snapshot's: its plugins, marketplaces, and user MCP servers
+func FromState(name string, st *state.FullState) *Profile {
+	return &Profile{
+		Name:         name,
+		Plugins:      pluginNames(&state.PluginRegistry{Plugins: st.Plugins}),
+		Marketplaces: registeredMarketplaces(st.Marketplaces),
+		MCPServers:   userMCPServers(st.MCPServers),
+	}
 }
 
 func readPlugins(claudeDir string) ([]string, error) {
-	// Use claude.LoadPlugins to get V1→V2 migration for free
-	registry, err := claude.LoadPlugins(claudeDir)
+	// state.LoadPlugins normalizes V1 registries to V2
+	registry, err := state.LoadPlugins(claudeDir)
 	if err != nil {
 		return nil, err
 	}
+	return pluginNames(registry), nil
+}
 
+func pluginNames(registry *state.PluginRegistry) []string {
 	// Extract plugin names using GetAllPlugins (returns user-scoped plugins)
 	allPlugins := registry.GetAllPlugins()
 	plugins := make([]string, 0, len(allPlugins))
@@ -82,60 +95,38 @@ func readPlugins(claudeDir string) ([]string, error) {
 	}
 	sort.Strings(plugins)
 
-	return plugins, nil
+	return plugins
 }
 
 func readMarketplaces(claudeDir string) ([]Marketplace, error) {
-	marketplacesPath := filepath.Join(claudeDir, "plugins", "known_marketplaces.json")
-
-	data, err := os.ReadFile(marketplacesPath)
+	registry, err := state.LoadMarketplaces(claudeDir)
 	if err != nil {
 		return nil, err
 	}
+	return registeredMarketplaces(registry), nil
+}
 
-	var registry MarketplaceRegistry
-	if err := json.Unmarshal(data, &registry); err != nil {
-		return nil, err
-	}
-
+func registeredMarketplaces(registry state.MarketplaceRegistry) []Marketplace {
+	// Sources are sorted by repo (or URL for git sources) for consistent output
 	var marketplaces []Marketplace
-	for _, meta := range registry {
-		marketplaces = append(marketplaces, Marketplace{
-			Source: meta.Source.Source,
-			Repo:   meta.Source.Repo,
-			URL:    meta.Source.URL,
-		})
+	for _, src := range registry.Sources() {
+		marketplaces = append(marketplaces, RegisteredMarketplace(src))
 	}
 
-	// Sort by repo (or URL for git sources) for consistent output
-	sort.Slice(marketplaces, func(i, j int) bool {
-		keyI := marketplaces[i].Repo
-		if keyI == "" {
-			keyI = marketplaces[i].URL
-		}
-		keyJ := marketplaces[j].Repo
-		if keyJ == "" {
-			keyJ = marketplaces[j].URL
-		}
-		return keyI < keyJ
-	})
-
-	return marketplaces, nil
+	return marketplaces
 }
 
 func readMCPServers(claudeJSONPath string) ([]MCPServer, error) {
-	data, err := os.ReadFile(claudeJSONPath)
+	mcpServers, err := state.LoadMCPServers(claudeJSONPath)
 	if err != nil {
 		return nil, err
 	}
+	return userMCPServers(mcpServers), nil
+}
 
-	var claudeJSON ClaudeJSON
-	if err := json.Unmarshal(data, &claudeJSON); err != nil {
-		return nil, err
-	}
-
+func userMCPServers(mcpServers map[string]state.MCPServer) []MCPServer {
 	var servers []MCPServer
-	for name, server := range claudeJSON.MCPServers {
+	for name, server := range mcpServers {
 		servers = append(servers, MCPServer{
 			Name:    name,
 			Command: server.Command,
@@ -149,5 +140,5 @@ func readMCPServers(claudeJSONPath string) ([]MCPServer, error) {
 		return servers[i].Name < servers[j].Name
 	})
 
-	return servers, nil
+	return servers
 }
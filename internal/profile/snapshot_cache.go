@@ -0,0 +1,78 @@
+// ABOUTME: In-process cache for Snapshot keyed by registry file modification times
+// ABOUTME: Avoids re-reading large Claude state files when ComputeDiff runs repeatedly
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+)
+
+// fileStamp identifies a version of a file on disk
+type fileStamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+type snapshotCacheEntry struct {
+	stamps [4]fileStamp
+	state  *Profile
+}
+
+var (
+	snapshotCacheMu sync.Mutex
+	snapshotCache   = make(map[string]snapshotCacheEntry)
+)
+
+// cachedState returns the current Claude state, reading from disk only
+// when one of the source files has changed since the last read.
+// Callers must not modify the returned profile; Snapshot clones it.
+func cachedState(claudeDir, claudeJSONPath string) *Profile {
+	key := claudeDir + "\x00" + claudeJSONPath
+	stamps := stateStamps(claudeDir, claudeJSONPath)
+
+	snapshotCacheMu.Lock()
+	entry, ok := snapshotCache[key]
+	snapshotCacheMu.Unlock()
+
+	if ok && entry.stamps == stamps {
+		return entry.state
+	}
+
+	state := readState(claudeDir, claudeJSONPath)
+
+	snapshotCacheMu.Lock()
+	snapshotCache[key] = snapshotCacheEntry{stamps: stamps, state: state}
+	snapshotCacheMu.Unlock()
+
+	return state
+}
+
+// stateStamps returns the stamps of every file Snapshot reads
+func stateStamps(claudeDir, claudeJSONPath string) [4]fileStamp {
+	return [4]fileStamp{
+		stampFile(filepath.Join(claudeDir, "plugins", "installed_plugins.json")),
+		stampFile(filepath.Join(claudeDir, "plugins", "known_marketplaces.json")),
+		stampFile(claudeJSONPath),
+		stampFile(config.Path()),
+	}
+}
+
+func stampFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+// ResetSnapshotCache discards all cached snapshot state
+func ResetSnapshotCache() {
+	snapshotCacheMu.Lock()
+	defer snapshotCacheMu.Unlock()
+	snapshotCache = make(map[string]snapshotCacheEntry)
+}
@@ -0,0 +1,151 @@
+// ABOUTME: Tests and benchmarks for snapshot caching and streaming decode
+// ABOUTME: Guards against regressions when reading large Claude state files
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotCacheInvalidatesOnChange(t *testing.T) {
+	claudeDir, claudeJSONPath := setupSnapshotState(t, 2, 1)
+
+	first, _ := Snapshot("first", claudeDir, claudeJSONPath)
+	if len(first.Plugins) != 2 {
+		t.Fatalf("Expected 2 plugins, got %v", first.Plugins)
+	}
+
+	// Rewrite the registry with different content and a later mtime
+	writeLargePluginRegistry(t, claudeDir, 3)
+	later := time.Now().Add(2 * time.Second)
+	os.Chtimes(filepath.Join(claudeDir, "plugins", "installed_plugins.json"), later, later)
+
+	second, _ := Snapshot("second", claudeDir, claudeJSONPath)
+	if len(second.Plugins) != 3 {
+		t.Errorf("Expected cache to pick up 3 plugins after change, got %v", second.Plugins)
+	}
+}
+
+func TestSnapshotCacheReturnsIndependentCopies(t *testing.T) {
+	claudeDir, claudeJSONPath := setupSnapshotState(t, 2, 2)
+
+	first, _ := Snapshot("first", claudeDir, claudeJSONPath)
+	first.Plugins[0] = "mutated"
+	first.MCPServers[0].Name = "mutated"
+
+	second, _ := Snapshot("second", claudeDir, claudeJSONPath)
+	if second.Plugins[0] == "mutated" || second.MCPServers[0].Name == "mutated" {
+		t.Error("Mutating a snapshot must not affect the cached state")
+	}
+	if second.Name != "second" {
+		t.Errorf("Expected snapshot name 'second', got %q", second.Name)
+	}
+}
+
+func BenchmarkSnapshotUncached(b *testing.B) {
+	claudeDir, claudeJSONPath := setupSnapshotState(b, 200, 50)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ResetSnapshotCache()
+		Snapshot("bench", claudeDir, claudeJSONPath)
+	}
+}
+
+func BenchmarkSnapshotCached(b *testing.B) {
+	claudeDir, claudeJSONPath := setupSnapshotState(b, 200, 50)
+	Snapshot("warm", claudeDir, claudeJSONPath)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Snapshot("bench", claudeDir, claudeJSONPath)
+	}
+}
+
+func BenchmarkReadMCPServersLargeClaudeJSON(b *testing.B) {
+	_, claudeJSONPath := setupSnapshotState(b, 0, 50)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := readMCPServers(claudeJSONPath); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// setupSnapshotState writes a Claude installation with the given number of
+// plugins and MCP servers, plus a few megabytes of unrelated project history
+func setupSnapshotState(tb testing.TB, pluginCount, mcpCount int) (string, string) {
+	tb.Helper()
+
+	tmpDir := tb.TempDir()
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	if err := os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755); err != nil {
+		tb.Fatal(err)
+	}
+
+	writeLargePluginRegistry(tb, claudeDir, pluginCount)
+
+	marketplaces := map[string]interface{}{
+		"marketplace": map[string]interface{}{
+			"source": map[string]interface{}{"source": "github", "repo": "org/marketplace"},
+		},
+	}
+	writeBenchJSON(tb, filepath.Join(claudeDir, "plugins", "known_marketplaces.json"), marketplaces)
+
+	servers := make(map[string]interface{})
+	for i := 0; i < mcpCount; i++ {
+		servers[fmt.Sprintf("server-%03d", i)] = map[string]interface{}{
+			"type": "stdio", "command": "npx", "args": []string{"-y", fmt.Sprintf("pkg-%d", i)},
+		}
+	}
+	projects := make(map[string]interface{})
+	for i := 0; i < 500; i++ {
+		history := make([]map[string]string, 20)
+		for j := range history {
+			history[j] = map[string]string{"display": strings.Repeat("x", 200)}
+		}
+		projects[fmt.Sprintf("/home/user/project-%d", i)] = map[string]interface{}{"history": history}
+	}
+	claudeJSONPath := filepath.Join(tmpDir, ".claude.json")
+	writeBenchJSON(tb, claudeJSONPath, map[string]interface{}{
+		"projects":   projects,
+		"mcpServers": servers,
+	})
+
+	return claudeDir, claudeJSONPath
+}
+
+func writeLargePluginRegistry(tb testing.TB, claudeDir string, count int) {
+	tb.Helper()
+
+	plugins := make(map[string]interface{})
+	for i := 0; i < count; i++ {
+		plugins[fmt.Sprintf("plugin-%03d@marketplace", i)] = []map[string]interface{}{{
+			"scope":       "user",
+			"version":     "1.0.0",
+			"installPath": fmt.Sprintf("/path/to/plugin-%03d", i),
+		}}
+	}
+	writeBenchJSON(tb, filepath.Join(claudeDir, "plugins", "installed_plugins.json"), map[string]interface{}{
+		"version": 2,
+		"plugins": plugins,
+	})
+}
+
+func writeBenchJSON(tb testing.TB, path string, data interface{}) {
+	tb.Helper()
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		tb.Fatal(err)
+	}
+}
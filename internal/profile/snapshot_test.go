@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/claudeup/claudeup/internal/state"
 )
 
 func TestSnapshotFromState(t *testing.T) {
@@ -245,3 +248,74 @@ func writeJSON(t *testing.T, path string, data interface{}) {
 		t.Fatal(err)
 	}
 }
+
+func TestSnapshotMatchesStateLoaders(t *testing.T) {
+	tmpDir := t.TempDir()
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	if err := os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	claudeJSONPath := filepath.Join(tmpDir, ".claude.json")
+
+	// Write through the shared savers, including a git source identified by URL
+	registry := &state.PluginRegistry{Version: 2, Plugins: map[string][]state.PluginMetadata{}}
+	registry.SetPlugin("tool@internal", state.PluginMetadata{Version: "1.0.0"})
+	registry.SetPlugin("lint@public", state.PluginMetadata{Version: "2.0.0"})
+	if err := state.SavePlugins(claudeDir, registry); err != nil {
+		t.Fatal(err)
+	}
+	marketplaces := state.MarketplaceRegistry{
+		"internal": {Source: state.MarketplaceSource{Source: "git", URL: "https://git.example.com/internal.git"}},
+		"public":   {Source: state.MarketplaceSource{Source: "github", Repo: "org/public"}},
+	}
+	if err := state.SaveMarketplaces(claudeDir, marketplaces); err != nil {
+		t.Fatal(err)
+	}
+	writeJSON(t, claudeJSONPath, map[string]interface{}{
+		"mcpServers": map[string]interface{}{
+			"ctx": map[string]interface{}{"type": "stdio", "command": "npx", "args": []string{"-y", "ctx"}},
+		},
+	})
+
+	p, err := Snapshot("adapter", claudeDir, claudeJSONPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loadedPlugins, _ := state.LoadPlugins(claudeDir)
+	if len(p.Plugins) != len(loadedPlugins.GetAllPlugins()) {
+		t.Errorf("Snapshot has %d plugins, state loader has %d", len(p.Plugins), len(loadedPlugins.GetAllPlugins()))
+	}
+	for _, name := range p.Plugins {
+		if !loadedPlugins.PluginExists(name) {
+			t.Errorf("Snapshot plugin %q not found by state loader", name)
+		}
+	}
+
+	loadedMarketplaces, _ := state.LoadMarketplaces(claudeDir)
+	sources := loadedMarketplaces.Sources()
+	if len(p.Marketplaces) != len(sources) {
+		t.Fatalf("Snapshot has %d marketplaces, state loader has %d", len(p.Marketplaces), len(sources))
+	}
+	for i, src := range sources {
+		m := p.Marketplaces[i]
+		if m.Source != src.Source || m.Repo != src.Repo || m.URL != src.URL {
+			t.Errorf("Marketplace %d differs: snapshot %+v, state %+v", i, m, src)
+		}
+	}
+
+	loadedServers, _ := state.LoadMCPServers(claudeJSONPath)
+	if len(p.MCPServers) != len(loadedServers) || p.MCPServers[0].Command != loadedServers["ctx"].Command {
+		t.Errorf("Snapshot MCP servers %+v differ from state loader %+v", p.MCPServers, loadedServers)
+	}
+
+	// A stored snapshot of the same state yields the same profile
+	st, err := state.Capture(claudeDir, claudeJSONPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromState := FromState("adapter", st)
+	if !reflect.DeepEqual(fromState.Plugins, p.Plugins) || !reflect.DeepEqual(fromState.Marketplaces, p.Marketplaces) || !reflect.DeepEqual(fromState.MCPServers, p.MCPServers) {
+		t.Errorf("FromState %+v differs from Snapshot %+v", fromState, p)
+	}
+}
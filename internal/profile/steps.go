@@ -0,0 +1,67 @@
+// ABOUTME: Per-change records of an apply: what was attempted, the outcome, and how long it took
+// ABOUTME: Rendered as the apply summary table and used to decide the exit status
+package profile
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Outcomes of an ApplyStep
+const (
+	StepDone    = "done"
+	StepAlready = "already" // nothing to do, e.g. the plugin was already installed
+	StepFailed  = "failed"
+)
+
+// ApplyStep is the outcome of one change an apply attempted
+type ApplyStep struct {
+	Item     DiffItem
+	Result   string
+	Duration time.Duration
+	Err      error
+}
+
+// record appends a step that started at start, and its error if it failed
+func (r *ApplyResult) record(action string, sub Subsystem, name string, start time.Time, err error) {
+	outcome := StepDone
+	if err != nil {
+		outcome = StepFailed
+		r.Errors = append(r.Errors, err)
+	}
+	r.recordOutcome(action, sub, name, start, outcome, err)
+}
+
+// recordOutcome appends a step without touching Errors
+func (r *ApplyResult) recordOutcome(action string, sub Subsystem, name string, start time.Time, outcome string, err error) {
+	r.Steps = append(r.Steps, ApplyStep{
+		Item:     DiffItem{Action: action, Subsystem: sub, Name: name},
+		Result:   outcome,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+}
+
+// Failed returns the steps that failed
+func (r *ApplyResult) Failed() []ApplyStep {
+	var failed []ApplyStep
+	for _, s := range r.Steps {
+		if s.Result == StepFailed {
+			failed = append(failed, s)
+		}
+	}
+	return failed
+}
+
+// Slowest returns up to n timed steps, longest first
+func (r *ApplyResult) Slowest(n int) []ApplyStep {
+	var timed []ApplyStep
+	for _, s := range r.Steps {
+		if s.Duration > 0 {
+			timed = append(timed, s)
+		}
+	}
+	slices.SortStableFunc(timed, func(a, b ApplyStep) int { return cmp.Compare(b.Duration, a.Duration) })
+	return timed[:min(n, len(timed))]
+}
@@ -0,0 +1,116 @@
+// ABOUTME: Tests for the per-change steps recorded by an apply
+// ABOUTME: Uses a scripted executor so some commands fail or report no change
+package profile
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedExecutor fails or reports "already installed" or "not found"
+// for chosen plugins
+type scriptedExecutor struct {
+	fail     map[string]bool
+	already  map[string]bool
+	notFound map[string]bool
+}
+
+func (e *scriptedExecutor) Run(ctx context.Context, args ...string) error {
+	_, err := e.RunWithOutput(ctx, args...)
+	return err
+}
+
+func (e *scriptedExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	name := args[len(args)-1]
+	switch {
+	case e.already[name]:
+		return "Plugin is already installed", errors.New("exit status 1")
+	case e.fail[name]:
+		return "boom", errors.New("exit status 1")
+	case e.notFound[name]:
+		return "Error: Plugin not found: " + name, errors.New("exit status 1")
+	}
+	return "", nil
+}
+
+func TestApplyDiffPluginNotFoundOnlyDoneWhenRecorded(t *testing.T) {
+	executor := &scriptedExecutor{notFound: map[string]bool{"gone@m": true, "tpyo@m": true}}
+	diff := &Diff{
+		PluginsToRemove:  []string{"gone@m", "tpyo@m"},
+		InstalledPlugins: []string{"gone@m"},
+	}
+
+	result, err := ApplyDiff(context.Background(), diff, nil, executor)
+	if err != nil {
+		t.Fatalf("ApplyDiff failed: %v", err)
+	}
+	if len(result.Steps) != 2 || result.Steps[0].Result != StepAlready || result.Steps[1].Result != StepFailed {
+		t.Fatalf("Expected the recorded plugin already removed and the unknown one failed, got %+v", result.Steps)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "tpyo@m") {
+		t.Errorf("Expected the unknown plugin reported, got %v", result.Errors)
+	}
+}
+
+func TestApplyDiffRecordsSteps(t *testing.T) {
+	executor := &scriptedExecutor{
+		fail:    map[string]bool{"b@m": true},
+		already: map[string]bool{"c@m": true},
+	}
+	diff := &Diff{
+		PluginsToRemove:  []string{"old@m"},
+		PluginsToInstall: []string{"a@m", "b@m", "c@m"},
+	}
+
+	result, err := ApplyDiff(context.Background(), diff, nil, executor)
+	if err != nil {
+		t.Fatalf("ApplyDiff failed: %v", err)
+	}
+
+	want := []struct{ action, name, result string }{
+		{"remove", "old@m", StepDone},
+		{"install", "a@m", StepDone},
+		{"install", "b@m", StepFailed},
+		{"install", "c@m", StepAlready},
+	}
+	if len(result.Steps) != len(want) {
+		t.Fatalf("Expected %d steps, got %+v", len(want), result.Steps)
+	}
+	for i, w := range want {
+		s := result.Steps[i]
+		if s.Item.Action != w.action || s.Item.Name != w.name || s.Result != w.result {
+			t.Errorf("Step %d = %s %s %s, want %s %s %s", i, s.Item.Action, s.Item.Name, s.Result, w.action, w.name, w.result)
+		}
+	}
+
+	failed := result.Failed()
+	if len(failed) != 1 || failed[0].Err == nil || len(result.Errors) != 1 {
+		t.Errorf("Expected one failed step with its error, got %+v", failed)
+	}
+	if result.Elapsed <= 0 {
+		t.Errorf("Expected the apply to be timed, got %v", result.Elapsed)
+	}
+}
+
+func TestSlowest(t *testing.T) {
+	step := func(name string, d time.Duration) ApplyStep {
+		return ApplyStep{Item: DiffItem{Action: "install", Subsystem: SubsystemPlugins, Name: name}, Duration: d}
+	}
+	result := &ApplyResult{Steps: []ApplyStep{
+		step("a", 2*time.Second), step("b", 0), step("c", 5*time.Second), step("d", time.Second), step("e", 3*time.Second),
+	}}
+
+	var names []string
+	for _, s := range result.Slowest(3) {
+		names = append(names, s.Item.Name)
+	}
+	if strings.Join(names, " ") != "c e a" {
+		t.Errorf("Expected c e a, got %v", names)
+	}
+	if got := (&ApplyResult{Steps: []ApplyStep{step("b", 0)}}).Slowest(3); len(got) != 0 {
+		t.Errorf("Expected untimed steps to be left out, got %+v", got)
+	}
+}
@@ -0,0 +1,104 @@
+// ABOUTME: Limits a profile apply to selected subsystems (plugins, MCP servers, marketplaces)
+// ABOUTME: Filters a computed Diff so unselected parts of Claude's config are left untouched
+package profile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Subsystem is a part of Claude's configuration that a profile manages
+type Subsystem string
+
+const (
+	SubsystemPlugins      Subsystem = "plugins"
+	SubsystemMCP          Subsystem = "mcp"
+	SubsystemMarketplaces Subsystem = "marketplaces"
+)
+
+// AllSubsystems lists every subsystem in apply order
+var AllSubsystems = []Subsystem{SubsystemPlugins, SubsystemMCP, SubsystemMarketplaces}
+
+// Subsystems is a set of selected subsystems. A nil set selects everything.
+type Subsystems map[Subsystem]bool
+
+// SelectSubsystems builds the selection for --only and --skip. At most one
+// of the two may be given; an empty selection means everything.
+func SelectSubsystems(only, skip []string) (Subsystems, error) {
+	if len(only) > 0 && len(skip) > 0 {
+		return nil, fmt.Errorf("--only and --skip can't be used together")
+	}
+	if len(only) == 0 && len(skip) == 0 {
+		return nil, nil
+	}
+
+	names := only
+	if len(skip) > 0 {
+		names = skip
+	}
+	listed := make(map[Subsystem]bool)
+	for _, name := range names {
+		s, err := parseSubsystem(name)
+		if err != nil {
+			return nil, err
+		}
+		listed[s] = true
+	}
+
+	selected := make(Subsystems)
+	for _, s := range AllSubsystems {
+		if listed[s] == (len(only) > 0) {
+			selected[s] = true
+		}
+	}
+	return selected, nil
+}
+
+func parseSubsystem(name string) (Subsystem, error) {
+	s := Subsystem(strings.ToLower(strings.TrimSpace(name)))
+	for _, known := range AllSubsystems {
+		if s == known {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown subsystem %q (valid: plugins, mcp, marketplaces)", name)
+}
+
+// Includes reports whether s is selected
+func (s Subsystems) Includes(sub Subsystem) bool {
+	return s == nil || s[sub]
+}
+
+// Skipped lists the subsystems that are not selected
+func (s Subsystems) Skipped() []Subsystem {
+	var skipped []Subsystem
+	for _, sub := range AllSubsystems {
+		if !s.Includes(sub) {
+			skipped = append(skipped, sub)
+		}
+	}
+	return skipped
+}
+
+// Filter returns a copy of d with only the selected subsystems' changes.
+// Disabling and re-enabling plugins belongs to plugins; plugin MCP server
+// toggles belong to mcp.
+func (d *Diff) Filter(s Subsystems) *Diff {
+	filtered := *d
+	if !s.Includes(SubsystemPlugins) {
+		filtered.PluginsToRemove = nil
+		filtered.PluginsToInstall = nil
+		filtered.PluginsToDisable = nil
+		filtered.PluginsToEnable = nil
+	}
+	if !s.Includes(SubsystemMCP) {
+		filtered.MCPToRemove = nil
+		filtered.MCPToInstall = nil
+		filtered.MCPToDisable = nil
+		filtered.MCPToEnable = nil
+	}
+	if !s.Includes(SubsystemMarketplaces) {
+		filtered.MarketplacesToAdd = nil
+	}
+	return &filtered
+}
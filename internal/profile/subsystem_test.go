@@ -0,0 +1,88 @@
+// ABOUTME: Unit tests for partial applies limited to selected subsystems
+// ABOUTME: Covers --only/--skip parsing, Diff filtering, and ApplySelected
+package profile
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSelectSubsystems(t *testing.T) {
+	all, err := SelectSubsystems(nil, nil)
+	if err != nil || all != nil {
+		t.Fatalf("Expected nil selection, got %v, %v", all, err)
+	}
+
+	only, err := SelectSubsystems([]string{"plugins", "Marketplaces"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(only.Skipped(), []Subsystem{SubsystemMCP}) {
+		t.Errorf("--only plugins,marketplaces should skip mcp, got %v", only.Skipped())
+	}
+
+	skip, err := SelectSubsystems(nil, []string{"mcp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !skip.Includes(SubsystemPlugins) || skip.Includes(SubsystemMCP) {
+		t.Errorf("Unexpected selection for --skip mcp: %v", skip)
+	}
+
+	if _, err := SelectSubsystems([]string{"plugins"}, []string{"mcp"}); err == nil {
+		t.Error("Expected error combining --only and --skip")
+	}
+	if _, err := SelectSubsystems([]string{"hooks"}, nil); err == nil {
+		t.Error("Expected error for unknown subsystem")
+	}
+}
+
+func TestDiffFilter(t *testing.T) {
+	diff := &Diff{
+		PluginsToInstall:  []string{"a@m"},
+		PluginsToDisable:  []string{"b@m"},
+		MCPToInstall:      []MCPServer{{Name: "db"}},
+		MCPToEnable:       []string{"a@m:db"},
+		MarketplacesToAdd: []Marketplace{{Source: "github", Repo: "org/m"}},
+	}
+
+	filtered := diff.Filter(Subsystems{SubsystemPlugins: true})
+	if filtered.Count() != 2 || len(filtered.PluginsToInstall) != 1 || len(filtered.PluginsToDisable) != 1 {
+		t.Errorf("Expected only plugin changes, got %+v", filtered)
+	}
+	if diff.Count() != 5 {
+		t.Error("Filter should not modify the original diff")
+	}
+	if diff.Filter(nil).Count() != 5 {
+		t.Error("A nil selection should keep every change")
+	}
+}
+
+func TestApplySelectedSkipsMCP(t *testing.T) {
+	claudeDir, claudeJSON := setupDisabledTest(t)
+
+	p := &Profile{
+		Name:       "work",
+		Plugins:    []string{"a@m"},
+		MCPServers: []MCPServer{{Name: "db", Command: "pg-mcp"}},
+	}
+
+	executor := &okExecutor{}
+	result, err := ApplySelected(context.Background(), p, claudeDir, claudeJSON, nil, executor, Subsystems{SubsystemPlugins: true, SubsystemMarketplaces: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, call := range executor.calls {
+		if call[0] == "mcp" {
+			t.Errorf("MCP servers should be untouched, got call %v", call)
+		}
+	}
+	if !reflect.DeepEqual(result.PluginsInstalled, []string{"a@m"}) {
+		t.Errorf("Expected a@m installed, got %+v", result)
+	}
+	if !reflect.DeepEqual(result.Skipped, []Subsystem{SubsystemMCP}) {
+		t.Errorf("Expected mcp reported as skipped, got %v", result.Skipped)
+	}
+}
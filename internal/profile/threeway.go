@@ -0,0 +1,167 @@
+// ABOUTME: Three-way comparison of a saved profile, its last-applied copy, and the current state
+// ABOUTME: Finds sections edited on disk that a save would overwrite and merges per-section choices
+package profile
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Section names a part of a profile that 'profile save' captures
+type Section string
+
+const (
+	SectionMarketplaces Section = "marketplaces"
+	SectionPlugins      Section = "plugins"
+	SectionMCPServers   Section = "mcpServers"
+	SectionDisabled     Section = "disabled"
+)
+
+// Sections lists the captured sections in display order
+var Sections = []Section{SectionMarketplaces, SectionPlugins, SectionMCPServers, SectionDisabled}
+
+// Title returns the section's display name
+func (s Section) Title() string {
+	switch s {
+	case SectionMarketplaces:
+		return "Marketplaces"
+	case SectionPlugins:
+		return "Plugins"
+	case SectionMCPServers:
+		return "MCP Servers"
+	case SectionDisabled:
+		return "Disabled"
+	}
+	return string(s)
+}
+
+// SectionChanges lists items added, removed, or modified relative to a base
+type SectionChanges struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+// Empty reports whether there are no changes
+func (c SectionChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// SectionConflict is a section edited on disk since the profile was last
+// applied that also differs from the current state
+type SectionConflict struct {
+	Section Section
+	// Disk holds the on-disk edits relative to the last-applied profile
+	Disk SectionChanges
+	// Current holds the changes to the live state since the last apply
+	Current SectionChanges
+}
+
+// FindSaveConflicts compares each section of the on-disk profile with the
+// last-applied copy and the current snapshot. Sections where disk matches
+// either one are safe to overwrite and are not reported.
+func FindSaveConflicts(applied, disk, current *Profile) []SectionConflict {
+	var conflicts []SectionConflict
+	for _, s := range Sections {
+		base, onDisk, live := sectionItems(applied, s), sectionItems(disk, s), sectionItems(current, s)
+		if equalItems(onDisk, base) || equalItems(onDisk, live) {
+			continue
+		}
+		conflicts = append(conflicts, SectionConflict{
+			Section: s,
+			Disk:    compareItems(base, onDisk),
+			Current: compareItems(base, live),
+		})
+	}
+	return conflicts
+}
+
+// MergeSections returns a copy of current with the sections in keepDisk
+// taken from disk instead
+func MergeSections(disk, current *Profile, keepDisk map[Section]bool) *Profile {
+	merged := current.Clone(current.Name)
+	src := disk.Clone(disk.Name)
+	for s, keep := range keepDisk {
+		if !keep {
+			continue
+		}
+		switch s {
+		case SectionMarketplaces:
+			merged.Marketplaces = src.Marketplaces
+		case SectionPlugins:
+			merged.Plugins = src.Plugins
+		case SectionMCPServers:
+			merged.MCPServers = src.MCPServers
+		case SectionDisabled:
+			merged.Disabled = src.Disabled
+		}
+	}
+	return merged
+}
+
+// sectionItems keys each item in a section by its identity, with its full
+// definition as the value so modified items can be told apart
+func sectionItems(p *Profile, s Section) map[string]string {
+	items := make(map[string]string)
+	switch s {
+	case SectionMarketplaces:
+		for _, m := range p.Marketplaces {
+			items[m.DisplayName()] = encodeItem(m)
+		}
+	case SectionPlugins:
+		for _, name := range p.Plugins {
+			items[name] = name
+		}
+	case SectionMCPServers:
+		for _, m := range p.MCPServers {
+			items[m.Name] = encodeItem(m)
+		}
+	case SectionDisabled:
+		for _, name := range p.Disabled.Plugins {
+			items[name] = name
+		}
+		for _, ref := range p.Disabled.MCPServers {
+			items[ref] = ref
+		}
+	}
+	return items
+}
+
+func encodeItem(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func equalItems(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+func compareItems(base, other map[string]string) SectionChanges {
+	var c SectionChanges
+	for k, v := range other {
+		w, ok := base[k]
+		switch {
+		case !ok:
+			c.Added = append(c.Added, k)
+		case w != v:
+			c.Modified = append(c.Modified, k)
+		}
+	}
+	for k := range base {
+		if _, ok := other[k]; !ok {
+			c.Removed = append(c.Removed, k)
+		}
+	}
+	sort.Strings(c.Added)
+	sort.Strings(c.Removed)
+	sort.Strings(c.Modified)
+	return c
+}
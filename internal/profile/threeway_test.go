@@ -0,0 +1,94 @@
+// ABOUTME: Unit tests for the three-way comparison used by profile save
+// ABOUTME: Covers which sections conflict and how per-section choices are merged
+package profile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindSaveConflicts(t *testing.T) {
+	applied := &Profile{
+		Name:       "work",
+		Plugins:    []string{"a@m", "b@m"},
+		MCPServers: []MCPServer{{Name: "db", Command: "pg-mcp"}},
+		Marketplaces: []Marketplace{
+			{Source: "github", Repo: "org/market"},
+		},
+	}
+
+	// Plugins edited on disk and changed live: conflict.
+	// MCP servers edited on disk only: conflict, saving would drop the edit.
+	// Marketplaces changed live only: safe to overwrite.
+	disk := applied.Clone("work")
+	disk.Plugins = []string{"a@m", "c@m"}
+	disk.MCPServers[0].Command = "pg-mcp-v2"
+
+	current := applied.Clone("work")
+	current.Plugins = []string{"a@m", "b@m", "d@m"}
+	current.Marketplaces = append(current.Marketplaces, Marketplace{Source: "github", Repo: "org/other"})
+
+	conflicts := FindSaveConflicts(applied, disk, current)
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %+v", conflicts)
+	}
+
+	plugins := conflicts[0]
+	if plugins.Section != SectionPlugins {
+		t.Fatalf("Expected plugins first, got %s", plugins.Section)
+	}
+	if !reflect.DeepEqual(plugins.Disk, SectionChanges{Added: []string{"c@m"}, Removed: []string{"b@m"}}) {
+		t.Errorf("Unexpected disk changes %+v", plugins.Disk)
+	}
+	if !reflect.DeepEqual(plugins.Current, SectionChanges{Added: []string{"d@m"}}) {
+		t.Errorf("Unexpected current changes %+v", plugins.Current)
+	}
+
+	mcp := conflicts[1]
+	if mcp.Section != SectionMCPServers || !reflect.DeepEqual(mcp.Disk.Modified, []string{"db"}) || !mcp.Current.Empty() {
+		t.Errorf("Unexpected MCP conflict %+v", mcp)
+	}
+}
+
+func TestFindSaveConflictsNoEdits(t *testing.T) {
+	applied := &Profile{Name: "work", Plugins: []string{"a@m"}}
+	current := &Profile{Name: "work", Plugins: []string{"a@m", "b@m"}}
+
+	if conflicts := FindSaveConflicts(applied, applied.Clone("work"), current); len(conflicts) != 0 {
+		t.Errorf("Unedited profile should not conflict, got %+v", conflicts)
+	}
+	if conflicts := FindSaveConflicts(applied, current.Clone("work"), current); len(conflicts) != 0 {
+		t.Errorf("Disk matching current should not conflict, got %+v", conflicts)
+	}
+}
+
+func TestMergeSections(t *testing.T) {
+	disk := &Profile{
+		Name:       "work",
+		Plugins:    []string{"disk@m"},
+		MCPServers: []MCPServer{{Name: "disk-server"}},
+	}
+	current := &Profile{
+		Name:       "work",
+		Plugins:    []string{"live@m"},
+		MCPServers: []MCPServer{{Name: "live-server"}},
+		Disabled:   DisabledConfig{Plugins: []string{"off@m"}},
+	}
+
+	merged := MergeSections(disk, current, map[Section]bool{SectionPlugins: true, SectionMCPServers: false})
+
+	if !reflect.DeepEqual(merged.Plugins, []string{"disk@m"}) {
+		t.Errorf("Expected disk plugins, got %v", merged.Plugins)
+	}
+	if merged.MCPServers[0].Name != "live-server" {
+		t.Errorf("Expected current MCP servers, got %v", merged.MCPServers)
+	}
+	if !reflect.DeepEqual(merged.Disabled.Plugins, []string{"off@m"}) {
+		t.Errorf("Expected current disabled section, got %v", merged.Disabled)
+	}
+
+	merged.Plugins[0] = "changed"
+	if disk.Plugins[0] != "disk@m" {
+		t.Error("MergeSections should not share slices with disk")
+	}
+}
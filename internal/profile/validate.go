@@ -0,0 +1,370 @@
+// ABOUTME: Validates a profile document as written, before anything is applied
+// ABOUTME: Checks its fields, plugin and marketplace references, secret declarations, and npm pins
+package profile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Problem severities
+const (
+	ProblemError   = "error"
+	ProblemWarning = "warning"
+)
+
+// Problem is something wrong with a profile document, at a line of the
+// file when it can be found
+type Problem struct {
+	Severity string `json:"severity"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ValidateOptions chooses the checks that need more than the document
+type ValidateOptions struct {
+	// Registry checks pinned npm packages' integrity against the registry
+	Registry bool
+}
+
+// validator collects problems, locating each by the first line of the
+// document containing the JSON string it's about
+type validator struct {
+	data     []byte
+	problems []Problem
+}
+
+func (v *validator) add(severity, near, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{Severity: severity, Line: lineOf(v.data, near), Message: fmt.Sprintf(format, args...)})
+}
+
+// lineOf returns the 1-based line of the first occurrence of s as a JSON
+// string in data, or 0
+func lineOf(data []byte, s string) int {
+	if s == "" {
+		return 0
+	}
+	quoted, _ := json.Marshal(s)
+	i := bytes.Index(data, quoted)
+	if i < 0 {
+		return 0
+	}
+	return bytes.Count(data[:i], []byte("\n")) + 1
+}
+
+// lineAt returns the 1-based line of a byte offset in data
+func lineAt(data []byte, offset int64) int {
+	if offset < 0 || offset > int64(len(data)) {
+		return 0
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+var unknownField = regexp.MustCompile(`unknown field "([^"]+)"`)
+
+// ValidateDocument parses data as a profile and reports what would stop it
+// applying as written, or apply something other than intended. The profile
+// is nil when data doesn't parse.
+func ValidateDocument(ctx context.Context, data []byte, opts ValidateOptions) (*Profile, []Problem) {
+	v := &validator{data: data}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var p Profile
+	if err := dec.Decode(&p); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch m := unknownField.FindStringSubmatch(err.Error()); {
+		case m != nil:
+			// Parse again without the unknown fields to check the rest
+			v.add(ProblemError, m[1], "unknown field %q; it would be ignored", m[1])
+			p = Profile{}
+			if err := json.Unmarshal(data, &p); err != nil {
+				return nil, v.problems
+			}
+		case errors.As(err, &syntaxErr):
+			v.problems = append(v.problems, Problem{Severity: ProblemError, Line: lineAt(data, syntaxErr.Offset), Message: "invalid JSON: " + syntaxErr.Error()})
+			return nil, v.problems
+		case errors.As(err, &typeErr):
+			v.problems = append(v.problems, Problem{Severity: ProblemError, Line: lineAt(data, typeErr.Offset), Message: fmt.Sprintf("%s must be a %s, not a %s", typeErr.Field, typeErr.Type, typeErr.Value)})
+			return nil, v.problems
+		default:
+			v.problems = append(v.problems, Problem{Severity: ProblemError, Message: err.Error()})
+			return nil, v.problems
+		}
+	}
+
+	if p.Name != "" {
+		if err := ValidateName(p.Name); err != nil {
+			v.add(ProblemError, p.Name, "%v", err)
+		}
+	}
+	if p.Type != "" && p.Type != TypeAddon {
+		v.add(ProblemError, p.Type, "type %q isn't known; use %q or leave it out", p.Type, TypeAddon)
+	}
+	v.checkMarketplaces(&p)
+	v.checkPlugins(&p)
+	v.checkMCPServers(ctx, &p, opts)
+	for i, mount := range p.Sandbox.Mounts {
+		v.checkCondition(fmt.Sprintf("sandbox mount %d", i+1), mount.Condition)
+	}
+	for name, ref := range p.ShellEnv.Secrets {
+		v.checkSecret("shellEnv secret "+name, name, ref)
+	}
+	for name, env := range p.Env {
+		v.checkSources("env "+name, name, env.Sources)
+	}
+	if err := p.Permissions.Validate(); err != nil {
+		v.add(ProblemError, "permissions", "%v", err)
+	}
+	if p.SetupWizard != nil {
+		if err := p.SetupWizard.Validate(); err != nil {
+			v.add(ProblemError, "setupWizard", "%v", err)
+		}
+	}
+	// Map order would shuffle the report between runs
+	slices.SortStableFunc(v.problems, func(a, b Problem) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return strings.Compare(a.Message, b.Message)
+	})
+	return &p, v.problems
+}
+
+func (v *validator) checkMarketplaces(p *Profile) {
+	for _, m := range p.Marketplaces {
+		near := m.DisplayName()
+		switch m.Source {
+		case "github":
+			if m.Repo == "" {
+				v.add(ProblemError, "github", "github marketplace needs a repo")
+			}
+		case "git":
+			if m.URL == "" {
+				v.add(ProblemError, "git", "git marketplace needs a url")
+			}
+		case MarketplaceArchive:
+			if m.URL == "" {
+				v.add(ProblemError, MarketplaceArchive, "archive marketplace needs a url")
+			} else if m.SHA256 == "" {
+				v.add(ProblemError, near, "archive marketplace %s needs a sha256 to be checked against", near)
+			}
+		case MarketplaceLocal:
+			if m.Path == "" {
+				v.add(ProblemError, MarketplaceLocal, "local marketplace needs a path")
+			}
+		default:
+			v.add(ProblemError, m.Source, "marketplace %s has unknown source %q", near, m.Source)
+		}
+	}
+}
+
+// marketplaceNames guesses the names the profile's marketplaces register
+// under: the last element of their repo, URL, or path. A marketplace's
+// manifest can choose another, so a miss is only a warning.
+func marketplaceNames(p *Profile) map[string]bool {
+	names := make(map[string]bool)
+	for _, m := range p.Marketplaces {
+		src := strings.TrimRight(strings.ReplaceAll(m.DisplayName(), "\\", "/"), "/")
+		name := path.Base(src[strings.LastIndex(src, ":")+1:])
+		for _, ext := range []string{".git", ".tar.gz", ".tgz"} {
+			name = strings.TrimSuffix(name, ext)
+		}
+		names[name] = true
+	}
+	return names
+}
+
+func (v *validator) checkPlugins(p *Profile) {
+	declared := marketplaceNames(p)
+	plugins := slices.Concat(p.Plugins, p.Disabled.Plugins)
+	for _, name := range p.GroupNames() {
+		plugins = append(plugins, p.Groups[name]...)
+	}
+	seen := make(map[string]bool)
+	for _, plugin := range plugins {
+		if seen[plugin] {
+			continue
+		}
+		seen[plugin] = true
+		name, marketplace, ok := strings.Cut(plugin, "@")
+		switch {
+		case !ok || name == "" || marketplace == "":
+			v.add(ProblemError, plugin, "plugin %q must be written name@marketplace", plugin)
+		case !declared[marketplace] && !p.IsAddon():
+			v.add(ProblemWarning, plugin, "plugin %s comes from marketplace %s, which none of the profile's marketplaces appears to provide", plugin, marketplace)
+		}
+	}
+	for _, group := range p.DisabledGroups {
+		if _, ok := p.Groups[group]; !ok {
+			v.add(ProblemError, group, "disabled group %q isn't defined in groups", group)
+		}
+	}
+	if p.SetupWizard != nil {
+		for _, c := range p.SetupWizard.Categories {
+			for _, plugin := range c.Plugins {
+				seen[plugin] = true
+			}
+		}
+	}
+	for plugin, c := range p.PluginConditions {
+		if !seen[plugin] {
+			v.add(ProblemError, plugin, "pluginConditions names plugin %q, which the profile doesn't list", plugin)
+		}
+		v.checkCondition("plugin "+plugin, c)
+	}
+	for group, c := range p.GroupConditions {
+		if _, ok := p.Groups[group]; !ok {
+			v.add(ProblemError, group, "groupConditions names group %q, which isn't defined in groups", group)
+		}
+		v.checkCondition("group "+group, c)
+	}
+}
+
+func (v *validator) checkMCPServers(ctx context.Context, p *Profile, opts ValidateOptions) {
+	seen := make(map[string][]Condition)
+	for _, m := range p.MCPServers {
+		if m.Name == "" {
+			v.add(ProblemError, m.Command, "MCP server running %q has no name", m.Command)
+			continue
+		}
+		// A server can be defined again for other machines
+		for _, c := range seen[m.Name] {
+			if c.IsZero() || m.Condition.IsZero() || reflect.DeepEqual(c, m.Condition) {
+				v.add(ProblemError, m.Name, "MCP server %s is defined twice for the same machines", m.Name)
+				break
+			}
+		}
+		seen[m.Name] = append(seen[m.Name], m.Condition)
+		v.checkCondition("MCP server "+m.Name, m.Condition)
+		if m.Command == "" {
+			v.add(ProblemError, m.Name, "MCP server %s has no command", m.Name)
+		}
+		if m.Scope != "" && m.Scope != "user" && m.Scope != "project" && m.Scope != "local" {
+			v.add(ProblemError, m.Scope, "MCP server %s has unknown scope %q; use user, project, or local", m.Name, m.Scope)
+		}
+
+		for _, arg := range m.Args {
+			envVar, ok := strings.CutPrefix(arg, "$")
+			if !ok || envVar == "" {
+				continue
+			}
+			if _, declared := m.Secrets[envVar]; !declared {
+				if _, declared := p.Env[envVar]; !declared {
+					v.add(ProblemError, arg, "MCP server %s uses %s, which isn't declared in its secrets or the profile's env", m.Name, arg)
+				}
+			}
+		}
+		for envVar, ref := range m.Secrets {
+			v.checkSecret(fmt.Sprintf("secret %s of MCP server %s", envVar, m.Name), envVar, ref)
+		}
+		v.checkPin(ctx, m, opts)
+	}
+}
+
+// checkPin treats a server's npm pin as its lock: an npx server must have
+// one, and it must still match the package its args run
+func (v *validator) checkPin(ctx context.Context, m MCPServer, opts ValidateOptions) {
+	if m.NpmPackages() == nil {
+		return
+	}
+	if m.Package == "" || m.Version == "" || m.Integrity == "" {
+		v.add(ProblemError, m.Name, "MCP server %s runs an npm package without a pinned version and integrity; run 'claudeup mcp pin'", m.Name)
+		return
+	}
+	if _, ok := m.packageArg(); !ok {
+		v.add(ProblemError, m.Package, "MCP server %s pins %s, but its args no longer run it; run 'claudeup mcp pin --update'", m.Name, m.Package)
+		return
+	}
+	if opts.Registry {
+		if err := checkPin(ctx, m); err != nil {
+			v.add(ProblemError, m.Integrity, "%v", err)
+		}
+	}
+}
+
+// checkSecret reports a secret with nowhere to resolve it from
+func (v *validator) checkSecret(what, near string, ref SecretRef) {
+	if len(ref.Sources) == 0 {
+		v.add(ProblemError, near, "%s has no sources", what)
+		return
+	}
+	v.checkSources(what, near, ref.Sources)
+}
+
+// checkSources reports secret sources that can't be resolved because
+// they're missing the field their type reads
+func (v *validator) checkSources(what, near string, sources []SecretSource) {
+	for _, s := range sources {
+		v.checkCondition(what, s.Condition)
+		missing := ""
+		switch s.Type {
+		case "env":
+			if s.Key == "" {
+				missing = "key"
+			}
+		case "1password":
+			if s.Ref == "" {
+				missing = "ref"
+			}
+		case "keychain":
+			if s.Service == "" {
+				missing = "service"
+			}
+		case "bitwarden":
+			if s.Item == "" {
+				missing = "item"
+			}
+		case "sops":
+			if s.Key == "" {
+				missing = "key"
+			}
+		default:
+			v.add(ProblemError, s.Type, "%s has unknown source type %q", what, s.Type)
+			continue
+		}
+		if missing != "" {
+			v.add(ProblemError, near, "%s has a %s source without a %s", what, s.Type, missing)
+		}
+	}
+}
+
+// Names conditions accept, with the spellings people reach for instead
+var (
+	knownOS   = []string{"darwin", "linux", "windows", "freebsd", "openbsd", "netbsd"}
+	knownArch = []string{"amd64", "arm64", "386", "arm", "riscv64"}
+	goNames   = map[string]string{
+		"macos": "darwin", "osx": "darwin", "mac": "darwin", "win": "windows", "win32": "windows",
+		"x86_64": "amd64", "x64": "amd64", "aarch64": "arm64", "x86": "386", "i386": "386",
+	}
+)
+
+// checkCondition reports OS and architecture names a condition would
+// never match, since they're compared with Go's names
+func (v *validator) checkCondition(what string, c Condition) {
+	for _, list := range []struct {
+		field string
+		names []string
+		known []string
+	}{{"os", c.OS, knownOS}, {"arch", c.Arch, knownArch}} {
+		for _, name := range list.names {
+			if slices.Contains(list.known, name) {
+				continue
+			}
+			if goName, ok := goNames[strings.ToLower(name)]; ok {
+				v.add(ProblemError, name, "%s has %s %q, which never matches; use %q", what, list.field, name, goName)
+			} else {
+				v.add(ProblemWarning, name, "%s has %s %q, which isn't one claudeup knows (%s)", what, list.field, name, strings.Join(list.known, ", "))
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// ABOUTME: Tests for ValidateDocument, which checks a profile file as written
+// ABOUTME: Covers parse errors with lines, cross-references, secret sources, and npm pins
+package profile
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValidateDocumentValidProfile(t *testing.T) {
+	doc := `{
+  "name": "team",
+  "marketplaces": [{"source": "github", "repo": "acme/tools"}],
+  "plugins": ["lint@tools"],
+  "mcpServers": [{
+    "name": "api",
+    "command": "npx",
+    "args": ["-y", "@acme/api-mcp@1.2.0", "--token", "$API_TOKEN"],
+    "package": "@acme/api-mcp", "version": "1.2.0", "integrity": "sha512-abc",
+    "secrets": {"API_TOKEN": {"sources": [{"type": "env", "key": "API_TOKEN"}]}}
+  }]
+}`
+	p, problems := ValidateDocument(context.Background(), []byte(doc), ValidateOptions{})
+	if p == nil || len(problems) > 0 {
+		t.Fatalf("Expected no problems, got %+v", problems)
+	}
+}
+
+func TestValidateDocumentParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		line int
+		want string
+	}{
+		{"syntax", "{\n  \"name\": \"team\",\n  \"plugins\": [\n}", 4, "invalid JSON"},
+		{"type", "{\n  \"plugins\": \"lint@tools\"\n}", 2, "must be a []string"},
+		{"unknown field", "{\n  \"name\": \"team\",\n  \"plugin\": []\n}", 3, `unknown field "plugin"`},
+	}
+	for _, tt := range tests {
+		_, problems := ValidateDocument(context.Background(), []byte(tt.doc), ValidateOptions{})
+		if len(problems) != 1 || problems[0].Line != tt.line || !strings.Contains(problems[0].Message, tt.want) {
+			t.Errorf("%s: expected %q on line %d, got %+v", tt.name, tt.want, tt.line, problems)
+		}
+	}
+}
+
+func TestValidateDocumentProblems(t *testing.T) {
+	doc := `{
+  "marketplaces": [{"source": "github", "repo": "acme/tools"}, {"source": "archive", "url": "https://x/mkt.tar.gz"}],
+  "plugins": ["lint@tools", "deploy@elsewhere", "bare"],
+  "disabledGroups": ["extras"],
+  "mcpServers": [
+    {"name": "db", "command": "db-mcp", "args": ["$DB_URL"], "scope": "global"},
+    {"name": "docs", "command": "npx", "args": ["docs-mcp"]},
+    {"name": "api", "command": "npx", "args": ["other-mcp"], "package": "api-mcp", "version": "1.0.0", "integrity": "sha512-x",
+     "secrets": {"TOKEN": {"sources": [{"type": "1password"}]}, "EMPTY": {"sources": []}}}
+  ]
+}`
+	_, problems := ValidateDocument(context.Background(), []byte(doc), ValidateOptions{})
+	want := []struct {
+		severity string
+		line     int
+		message  string
+	}{
+		{ProblemError, 2, "archive marketplace https://x/mkt.tar.gz needs a sha256"},
+		{ProblemError, 3, `plugin "bare" must be written name@marketplace`},
+		{ProblemWarning, 3, "plugin deploy@elsewhere comes from marketplace elsewhere"},
+		{ProblemError, 4, `disabled group "extras" isn't defined`},
+		{ProblemError, 6, `unknown scope "global"`},
+		{ProblemError, 6, "uses $DB_URL, which isn't declared"},
+		{ProblemError, 7, "MCP server docs runs an npm package without a pinned version"},
+		{ProblemError, 8, "MCP server api pins api-mcp, but its args no longer run it"},
+		{ProblemError, 9, "secret EMPTY of MCP server api has no sources"},
+		{ProblemError, 9, "secret TOKEN of MCP server api has a 1password source without a ref"},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("Expected %d problems, got %d: %+v", len(want), len(problems), problems)
+	}
+	for i, w := range want {
+		got := problems[i]
+		if got.Severity != w.severity || got.Line != w.line || !strings.Contains(got.Message, w.message) {
+			t.Errorf("Problem %d: expected %s on line %d containing %q, got %+v", i, w.severity, w.line, w.message, got)
+		}
+	}
+}
+
+func TestValidateDocumentChecksRegistry(t *testing.T) {
+	saved := npmView
+	t.Cleanup(func() { npmView = saved })
+	npmView = func(ctx context.Context, spec string) (NpmPin, error) {
+		return NpmPin{Version: "1.0.0", Integrity: "sha512-new"}, nil
+	}
+	doc := `{"mcpServers": [{"name": "api", "command": "npx", "args": ["api-mcp@1.0.0"], "package": "api-mcp", "version": "1.0.0", "integrity": "sha512-old"}]}`
+
+	if _, problems := ValidateDocument(context.Background(), []byte(doc), ValidateOptions{}); len(problems) != 0 {
+		t.Errorf("Expected no registry lookup by default, got %+v", problems)
+	}
+	_, problems := ValidateDocument(context.Background(), []byte(doc), ValidateOptions{Registry: true})
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "integrity mismatch") {
+		t.Errorf("Expected an integrity mismatch, got %+v", problems)
+	}
+}
+
+func TestValidateDocumentConditions(t *testing.T) {
+	doc := `{
+  "marketplaces": [{"source": "github", "repo": "acme/tools"}],
+  "plugins": ["lint@tools"],
+  "pluginConditions": {"lint@tools": {"arch": ["x86_64"]}, "typo@tools": {"os": ["linux"]}},
+  "groups": {"mac": ["raycast@tools"]},
+  "groupConditions": {"mac": {"os": ["macos"]}, "gone": {"os": ["linux"]}},
+  "mcpServers": [
+    {"name": "github", "command": "docker", "requires": ["docker"], "os": ["linux"]},
+    {"name": "github", "command": "npx", "os": ["darwin"], "arch": ["aarch64"]},
+    {"name": "db", "command": "db-mcp"},
+    {"name": "db", "command": "db-mcp", "os": ["linux"]}
+  ]
+}`
+	_, problems := ValidateDocument(context.Background(), []byte(doc), ValidateOptions{})
+	want := []string{
+		`group mac has os "macos", which never matches; use "darwin"`,
+		`groupConditions names group "gone"`,
+		`plugin lint@tools has arch "x86_64", which never matches; use "amd64"`,
+		`pluginConditions names plugin "typo@tools"`,
+		`MCP server github has arch "aarch64", which never matches; use "arm64"`,
+		"MCP server db is defined twice for the same machines",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("Expected %d problems, got %+v", len(want), problems)
+	}
+	for _, w := range want {
+		found := false
+		for _, p := range problems {
+			found = found || strings.Contains(p.Message, w)
+		}
+		if !found {
+			t.Errorf("Expected a problem containing %q, got %+v", w, problems)
+		}
+	}
+}
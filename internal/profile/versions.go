@@ -0,0 +1,290 @@
+// ABOUTME: Earlier versions of each saved profile, kept under profiles/.versions whenever Save overwrites one
+// ABOUTME: Lists them with what restoring each would change, and restores one as the profile's content
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxVersions is how many earlier versions of each profile are kept
+const maxVersions = 50
+
+// keptTimeLayout is how a kept version's file records when it was saved
+// over, after its sequence number
+const keptTimeLayout = "20060102-150405.000000000"
+
+// currentVersionID names the profile's file as it is now in its log
+const currentVersionID = "current"
+
+// undoMarker names the file recording the version 'profile undo' last
+// restored, so undoing again steps further back. Profile names can't
+// start with a dot, so it never clashes with a namespace.
+const undoMarker = ".undo"
+
+// Version is an earlier content of a profile, kept when it was saved over
+type Version struct {
+	ID      string           `json:"id"`
+	Time    time.Time        `json:"time,omitzero"` // when the content was written, if known
+	Changes []RevisionChange `json:"changes"`       // what restoring it would change
+}
+
+// keptVersion is a file in a profile's versions directory. Seq increases
+// with every version kept, and names it; Replaced is when it was saved over.
+type keptVersion struct {
+	seq      int
+	replaced time.Time
+	path     string
+}
+
+func (k keptVersion) id() string {
+	return strconv.Itoa(k.seq)
+}
+
+// versionsDir returns where earlier versions of the named profile in
+// profilesDir are kept
+func versionsDir(profilesDir, name string) string {
+	return filepath.Join(profilesDir, ".versions", filepath.FromSlash(name))
+}
+
+// keepVersion copies the named profile's file, as it is before being
+// overwritten, into its versions, dropping the oldest beyond maxVersions.
+// Hand edits are kept too, since the file is copied as it is. Best-effort:
+// history is never worth failing a save. Saving ends a run of undos.
+func keepVersion(profilesDir, name string) {
+	dir := versionsDir(profilesDir, name)
+	os.Remove(filepath.Join(dir, undoMarker))
+	data, err := os.ReadFile(filepath.Join(profilesDir, filepath.FromSlash(name)+".json"))
+	if err != nil {
+		return
+	}
+	kept, _ := keptVersions(dir)
+	seq := 1
+	if len(kept) > 0 {
+		last := kept[len(kept)-1]
+		if lastData, err := os.ReadFile(last.path); err == nil && bytes.Equal(lastData, data) {
+			return
+		}
+		seq = last.seq + 1
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	file := fmt.Sprintf("%06d-%s.json", seq, time.Now().UTC().Format(keptTimeLayout))
+	f, err := os.OpenFile(filepath.Join(dir, file), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err != nil || cerr != nil {
+		os.Remove(f.Name())
+		return
+	}
+	if kept, err = keptVersions(dir); err != nil {
+		return
+	}
+	for len(kept) > maxVersions {
+		os.Remove(kept[0].path)
+		kept = kept[1:]
+	}
+}
+
+// keptVersions lists the versions kept in dir, oldest first. Files not
+// named by keepVersion are ignored.
+func keptVersions(dir string) ([]keptVersion, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var kept []keptVersion
+	for _, e := range entries {
+		base, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+		seqText, timeText, _ := strings.Cut(base, "-")
+		seq, err := strconv.Atoi(seqText)
+		if err != nil || seq <= 0 {
+			continue
+		}
+		replaced, err := time.Parse(keptTimeLayout, timeText)
+		if err != nil {
+			continue
+		}
+		kept = append(kept, keptVersion{seq: seq, replaced: replaced, path: filepath.Join(dir, e.Name())})
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].seq < kept[j].seq })
+	return kept, nil
+}
+
+// savedVersions reads the kept versions of the named profile, oldest
+// first, followed by its current file when it has one. Each content was
+// written when the one before it was saved over; the oldest has only its
+// updatedAt.
+func savedVersions(profilesDir, name string) ([]version, error) {
+	kept, err := keptVersions(versionsDir(profilesDir, name))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	var versions []version
+	var written time.Time
+	for _, k := range kept {
+		p, err := readVersion(k.path)
+		if err == nil {
+			t := written
+			if t.IsZero() {
+				t = p.UpdatedAt
+			}
+			versions = append(versions, version{id: k.id(), time: t, profile: p})
+		}
+		written = k.replaced
+	}
+	if len(versions) == 0 {
+		return nil, nil
+	}
+	if p, err := readVersion(filepath.Join(profilesDir, filepath.FromSlash(name)+".json")); err == nil {
+		versions = append(versions, version{id: currentVersionID, time: written, profile: p})
+	}
+	return versions, nil
+}
+
+func readVersion(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Versions returns the kept earlier versions of the named profile, newest
+// first, each with what restoring it would change in the current profile
+func Versions(profilesDir, name string) ([]Version, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	saved, err := savedVersions(profilesDir, name)
+	if err != nil {
+		return nil, err
+	}
+	current := &Profile{}
+	if n := len(saved); n > 0 && saved[n-1].id == currentVersionID {
+		current = saved[n-1].profile
+		saved = saved[:n-1]
+	}
+	versions := make([]Version, 0, len(saved))
+	for i := len(saved) - 1; i >= 0; i-- {
+		v := saved[i]
+		versions = append(versions, Version{ID: v.id, Time: v.time, Changes: revisionChanges(current, v.profile)})
+	}
+	return versions, nil
+}
+
+// undoState returns the kept versions of the named profile, oldest first,
+// and the index of the one 'profile undo' last restored while the file is
+// still as restored, or -1
+func undoState(profilesDir, name string) ([]keptVersion, int, error) {
+	dir := versionsDir(profilesDir, name)
+	kept, err := keptVersions(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, -1, err
+	}
+	marked, err := os.ReadFile(filepath.Join(dir, undoMarker))
+	if err != nil {
+		return kept, -1, nil
+	}
+	current, err := os.ReadFile(filepath.Join(profilesDir, filepath.FromSlash(name)+".json"))
+	if err != nil {
+		return kept, -1, nil
+	}
+	for i, k := range kept {
+		if k.id() == strings.TrimSpace(string(marked)) {
+			if data, err := os.ReadFile(k.path); err == nil && bytes.Equal(data, current) {
+				return kept, i, nil
+			}
+			break
+		}
+	}
+	return kept, -1, nil
+}
+
+// UndoTarget returns the ID of the version undo restores: the one before
+// the version last restored, while the file is still as restored, so each
+// undo steps further back, or else the newest
+func UndoTarget(profilesDir, name string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	kept, undone, err := undoState(profilesDir, name)
+	switch {
+	case err != nil:
+		return "", err
+	case len(kept) == 0:
+		return "", fmt.Errorf("no earlier versions of profile %q", name)
+	case undone == 0:
+		return "", fmt.Errorf("profile %q is already at its oldest kept version, %s", name, kept[0].id())
+	case undone > 0:
+		return kept[undone-1].id(), nil
+	default:
+		return kept[len(kept)-1].id(), nil
+	}
+}
+
+// RestoreVersion writes the kept version id of the named profile back as
+// its file, byte for byte, or the version UndoTarget names when id is
+// empty. The content it replaces is kept as a version first, unless it's
+// the version last restored, so undoing several times keeps one copy of
+// where it started. Returns the ID of the version restored.
+func RestoreVersion(profilesDir, name, id string) (string, error) {
+	if id == "" {
+		var err error
+		if id, err = UndoTarget(profilesDir, name); err != nil {
+			return "", err
+		}
+	}
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	kept, undone, err := undoState(profilesDir, name)
+	if err != nil {
+		return "", err
+	}
+	i := slices.IndexFunc(kept, func(k keptVersion) bool { return k.id() == id })
+	if i < 0 {
+		return "", fmt.Errorf("profile %q has no version %q", name, id)
+	}
+
+	data, err := os.ReadFile(kept[i].path)
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(data, &Profile{}); err != nil {
+		return "", fmt.Errorf("version %s of profile %q is invalid: %w", id, name, err)
+	}
+	if undone < 0 {
+		keepVersion(profilesDir, name)
+	}
+	path := filepath.Join(profilesDir, filepath.FromSlash(name)+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to restore profile %q: %w", name, err)
+	}
+	// Best-effort: without it, the next undo restores the newest version
+	os.WriteFile(filepath.Join(versionsDir(profilesDir, name), undoMarker), []byte(id+"\n"), 0644)
+	return id, nil
+}
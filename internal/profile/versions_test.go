@@ -0,0 +1,189 @@
+// ABOUTME: Tests for the earlier versions kept of saved profiles
+// ABOUTME: Covers keeping hand edits, ordering and pruning, listing what a restore changes, and stepping back with undo
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+)
+
+func TestSaveKeepsHandEditedContent(t *testing.T) {
+	paths := pathctx.ForHome(t.TempDir())
+	defer pathctx.Override(paths)()
+
+	p := &Profile{Name: "backend", Plugins: []string{"a@m"}}
+	if err := Save(paths.ProfilesDir, p); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(paths.ProfilesDir, "backend.json")
+	edited := []byte(`{"name": "backend", "plugins": ["a@m", "edited@m"]}`)
+	if err := os.WriteFile(path, edited, 0644); err != nil {
+		t.Fatal(err)
+	}
+	p.Plugins = []string{"b@m"}
+	if err := Save(paths.ProfilesDir, p); err != nil {
+		t.Fatal(err)
+	}
+
+	kept, err := keptVersions(filepath.Join(paths.ProfilesDir, ".versions", "backend"))
+	if err != nil || len(kept) != 1 {
+		t.Fatalf("Expected the replaced content kept once, got %v, %v", kept, err)
+	}
+	if data, _ := os.ReadFile(kept[0].path); string(data) != string(edited) {
+		t.Errorf("Expected the hand edit kept byte for byte, got %s", data)
+	}
+	if names, _ := Namespaces(paths.ProfilesDir); len(names) != 0 {
+		t.Errorf("Expected the versions directory not to be a namespace, got %v", names)
+	}
+}
+
+func TestKeepVersionOrdersAndPrunes(t *testing.T) {
+	paths := pathctx.ForHome(t.TempDir())
+	defer pathctx.Override(paths)()
+
+	path := filepath.Join(paths.ProfilesDir, "team", "backend.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(24 * time.Hour)
+	for i := range maxVersions + 3 {
+		if err := os.WriteFile(path, fmt.Appendf(nil, `{"description": "v%d"}`, i), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// Modification times don't order versions, as a copy or sync can change them
+		if err := os.Chtimes(path, future, future.Add(-time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		keepVersion(paths.ProfilesDir, "team/backend")
+	}
+	kept, err := keptVersions(filepath.Join(paths.ProfilesDir, ".versions", "team", "backend"))
+	if err != nil || len(kept) != maxVersions {
+		t.Fatalf("Expected %d versions kept, got %d, %v", maxVersions, len(kept), err)
+	}
+	if kept[0].seq != 4 || kept[len(kept)-1].seq != maxVersions+3 {
+		t.Errorf("Expected versions 4 to %d, got %d to %d", maxVersions+3, kept[0].seq, kept[len(kept)-1].seq)
+	}
+	versions, err := Versions(paths.ProfilesDir, "team/backend")
+	if err != nil || len(versions) != maxVersions {
+		t.Fatalf("Expected %d versions, got %d, %v", maxVersions, len(versions), err)
+	}
+	if versions[0].ID != strconv.Itoa(maxVersions+3) || versions[0].Time.Before(versions[1].Time) {
+		t.Errorf("Expected the newest version first, got %s at %v then %s at %v", versions[0].ID, versions[0].Time, versions[1].ID, versions[1].Time)
+	}
+}
+
+func TestRestoreVersionUndoesAndRedoes(t *testing.T) {
+	paths := pathctx.ForHome(t.TempDir())
+	defer pathctx.Override(paths)()
+
+	p := &Profile{Name: "backend", Plugins: []string{"a@m"}}
+	if err := Save(paths.ProfilesDir, p); err != nil {
+		t.Fatal(err)
+	}
+	p.Plugins = []string{"b@m"}
+	if err := Save(paths.ProfilesDir, p); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := Versions(paths.ProfilesDir, "backend")
+	if err != nil || len(versions) != 1 {
+		t.Fatalf("Expected 1 earlier version, got %v, %v", versions, err)
+	}
+	want := []RevisionChange{
+		{Action: "add", Kind: "plugin", Name: "a@m"},
+		{Action: "remove", Kind: "plugin", Name: "b@m"},
+	}
+	if !reflect.DeepEqual(versions[0].Changes, want) {
+		t.Errorf("Expected restoring to change %v, got %v", want, versions[0].Changes)
+	}
+
+	plugins := func() []string {
+		t.Helper()
+		loaded, err := Load(paths.ProfilesDir, "backend")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return loaded.Plugins
+	}
+	if _, err := RestoreVersion(paths.ProfilesDir, "backend", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := plugins(); !reflect.DeepEqual(got, []string{"a@m"}) {
+		t.Errorf("Expected undo to restore a@m, got %v", got)
+	}
+	if _, err := RestoreVersion(paths.ProfilesDir, "backend", ""); err == nil {
+		t.Error("Expected undo at the oldest version to fail rather than redo")
+	}
+
+	// The content undo replaced is kept once, and restoring it redoes
+	versions, err = Versions(paths.ProfilesDir, "backend")
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("Expected 2 earlier versions, got %v, %v", versions, err)
+	}
+	if _, err := RestoreVersion(paths.ProfilesDir, "backend", versions[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := plugins(); !reflect.DeepEqual(got, []string{"b@m"}) {
+		t.Errorf("Expected restoring the newest version to bring back b@m, got %v", got)
+	}
+
+	if _, err := RestoreVersion(paths.ProfilesDir, "backend", "../../config"); err == nil {
+		t.Error("Expected an unknown version to be rejected")
+	}
+	if _, err := RestoreVersion(paths.ProfilesDir, "frontend", ""); err == nil {
+		t.Error("Expected an error for a profile with no versions")
+	}
+}
+
+func TestRestoreVersionStepsBack(t *testing.T) {
+	paths := pathctx.ForHome(t.TempDir())
+	defer pathctx.Override(paths)()
+
+	p := &Profile{Name: "backend"}
+	for _, plugin := range []string{"a@m", "b@m", "c@m", "d@m"} {
+		p.Plugins = []string{plugin}
+		if err := Save(paths.ProfilesDir, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plugins := func() string {
+		t.Helper()
+		loaded, err := Load(paths.ProfilesDir, "backend")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(loaded.Plugins, " ")
+	}
+
+	for _, want := range []string{"c@m", "b@m", "a@m"} {
+		if _, err := RestoreVersion(paths.ProfilesDir, "backend", ""); err != nil {
+			t.Fatal(err)
+		}
+		if got := plugins(); got != want {
+			t.Errorf("Expected undo to step back to %s, got %s", want, got)
+		}
+	}
+	if versions, _ := Versions(paths.ProfilesDir, "backend"); len(versions) != 4 {
+		t.Errorf("Expected only d@m kept on top of the 3 saved over, got %d versions", len(versions))
+	}
+
+	// A save ends the run of undos, so the next undo starts from the newest
+	p.Plugins = []string{"e@m"}
+	if err := Save(paths.ProfilesDir, p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreVersion(paths.ProfilesDir, "backend", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := plugins(); got != "a@m" {
+		t.Errorf("Expected undo after a save to restore the content it replaced, got %s", got)
+	}
+}
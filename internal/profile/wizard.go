@@ -0,0 +1,109 @@
+// ABOUTME: Setup wizards that let a profile offer optional plugin sets by category
+// ABOUTME: Resolves the chosen categories into the plain profile that gets applied
+package profile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetupWizard groups optional plugins into categories that are chosen when
+// the profile is applied. The profile's own plugins are always installed.
+type SetupWizard struct {
+	Prompt     string           `json:"prompt,omitempty"`
+	Categories []WizardCategory `json:"categories"`
+}
+
+// WizardCategory is a set of plugins installed when the category is chosen
+type WizardCategory struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Plugins     []string `json:"plugins"`
+	Default     bool     `json:"default,omitempty"` // chosen unless deselected
+}
+
+// Question returns the prompt shown above the categories
+func (w *SetupWizard) Question() string {
+	if w.Prompt != "" {
+		return w.Prompt
+	}
+	return "Which plugins do you want to install?"
+}
+
+// Defaults returns the names of the categories chosen by default
+func (w *SetupWizard) Defaults() []string {
+	var names []string
+	for _, c := range w.Categories {
+		if c.Default {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// Validate checks that categories are named, unique, and not empty
+func (w *SetupWizard) Validate() error {
+	seen := make(map[string]bool)
+	for _, c := range w.Categories {
+		key := strings.ToLower(c.Name)
+		switch {
+		case c.Name == "":
+			return fmt.Errorf("setup wizard category without a name")
+		case seen[key]:
+			return fmt.Errorf("setup wizard category %q is defined twice", c.Name)
+		case len(c.Plugins) == 0:
+			return fmt.Errorf("setup wizard category %q lists no plugins", c.Name)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// WithCategories returns a copy of p that also installs the plugins of the
+// chosen categories, matched by name ignoring case. The copy has no wizard,
+// so it describes exactly what gets applied.
+func (p *Profile) WithCategories(chosen []string) (*Profile, error) {
+	resolved := p.Clone(p.Name)
+	resolved.SetupWizard = nil
+	if p.SetupWizard == nil {
+		if len(chosen) > 0 {
+			return nil, fmt.Errorf("profile %q has no setup wizard categories", p.Name)
+		}
+		return resolved, nil
+	}
+
+	for _, name := range chosen {
+		found := false
+		for _, c := range p.SetupWizard.Categories {
+			if strings.EqualFold(c.Name, name) {
+				resolved.Plugins = appendMissing(resolved.Plugins, c.Plugins...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("profile %q has no setup wizard category %q (have %s)", p.Name, name, strings.Join(p.SetupWizard.names(), ", "))
+		}
+	}
+	return resolved, nil
+}
+
+func (w *SetupWizard) names() []string {
+	names := make([]string, len(w.Categories))
+	for i, c := range w.Categories {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func (w *SetupWizard) clone() *SetupWizard {
+	if w == nil {
+		return nil
+	}
+	c := &SetupWizard{Prompt: w.Prompt}
+	for _, cat := range w.Categories {
+		cat.Plugins = append([]string(nil), cat.Plugins...)
+		c.Categories = append(c.Categories, cat)
+	}
+	return c
+}
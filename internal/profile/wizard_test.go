@@ -0,0 +1,138 @@
+// ABOUTME: Tests for setup wizards declared in profile JSON
+// ABOUTME: Covers parsing, validation, resolving categories, cloning, and merging addons
+package profile
+
+import (
+	"strings"
+	"testing"
+)
+
+const wizardProfileJSON = `{
+  "name": "team",
+  "plugins": ["core@m"],
+  "setupWizard": {
+    "prompt": "Which stacks do you work on?",
+    "categories": [
+      {"name": "frontend", "description": "React and CSS", "plugins": ["react@m", "css@m"], "default": true},
+      {"name": "backend", "plugins": ["go@m", "core@m"]}
+    ]
+  }
+}`
+
+func readWizardProfile(t *testing.T) *Profile {
+	t.Helper()
+	p, err := Read(strings.NewReader(wizardProfileJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestSetupWizardParses(t *testing.T) {
+	p := readWizardProfile(t)
+	w := p.SetupWizard
+	if w == nil || len(w.Categories) != 2 {
+		t.Fatalf("Expected two categories, got %+v", w)
+	}
+	if w.Question() != "Which stacks do you work on?" {
+		t.Errorf("Unexpected question %q", w.Question())
+	}
+	if got := w.Defaults(); len(got) != 1 || got[0] != "frontend" {
+		t.Errorf("Expected frontend as the only default, got %v", got)
+	}
+	if err := w.Validate(); err != nil {
+		t.Errorf("Expected valid wizard, got %v", err)
+	}
+}
+
+func TestWithCategories(t *testing.T) {
+	p := readWizardProfile(t)
+
+	resolved, err := p.WithCategories([]string{"Backend", "frontend"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"core@m", "go@m", "react@m", "css@m"}
+	if strings.Join(resolved.Plugins, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected plugins %v, got %v", want, resolved.Plugins)
+	}
+	if resolved.SetupWizard != nil {
+		t.Error("Resolved profile should have no wizard")
+	}
+	if len(p.Plugins) != 1 || p.SetupWizard == nil {
+		t.Error("WithCategories must not modify the profile")
+	}
+
+	none, err := p.WithCategories(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(none.Plugins) != 1 {
+		t.Errorf("Expected only the profile's own plugins, got %v", none.Plugins)
+	}
+}
+
+func TestWithCategoriesUnknown(t *testing.T) {
+	p := readWizardProfile(t)
+	if _, err := p.WithCategories([]string{"mobile"}); err == nil || !strings.Contains(err.Error(), "frontend, backend") {
+		t.Errorf("Expected error listing the categories, got %v", err)
+	}
+
+	plain := &Profile{Name: "plain"}
+	if _, err := plain.WithCategories([]string{"frontend"}); err == nil {
+		t.Error("Expected error choosing a category of a profile without a wizard")
+	}
+}
+
+func TestSetupWizardValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []WizardCategory
+		wantErr    string
+	}{
+		{"unnamed", []WizardCategory{{Plugins: []string{"a@m"}}}, "without a name"},
+		{"duplicate", []WizardCategory{{Name: "a", Plugins: []string{"a@m"}}, {Name: "A", Plugins: []string{"b@m"}}}, "defined twice"},
+		{"empty", []WizardCategory{{Name: "a"}}, "lists no plugins"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&SetupWizard{Categories: tt.categories}).Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSetupWizardCloneIsDeep(t *testing.T) {
+	p := readWizardProfile(t)
+	clone := p.Clone("copy")
+	clone.SetupWizard.Categories[0].Plugins[0] = "changed@m"
+	if p.SetupWizard.Categories[0].Plugins[0] != "react@m" {
+		t.Error("Clone must copy the wizard's plugin lists")
+	}
+}
+
+func TestMergeAddsAddonWizardCategories(t *testing.T) {
+	base := readWizardProfile(t)
+	addon := &Profile{
+		Name: "extras",
+		Type: TypeAddon,
+		SetupWizard: &SetupWizard{Categories: []WizardCategory{
+			{Name: "Frontend", Plugins: []string{"vue@m"}},
+			{Name: "data", Plugins: []string{"sql@m"}},
+		}},
+	}
+
+	merged, err := Merge(base, addon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := merged.SetupWizard.names()
+	if strings.Join(names, " ") != "frontend backend data" {
+		t.Errorf("Expected base categories first and duplicates skipped, got %v", names)
+	}
+	if merged.SetupWizard.Question() != "Which stacks do you work on?" {
+		t.Errorf("Expected the base profile's prompt, got %q", merged.SetupWizard.Question())
+	}
+}
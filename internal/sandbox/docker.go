@@ -3,10 +3,18 @@
 package sandbox
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
 )
 
 // DockerRunner implements Runner using Docker
@@ -33,14 +41,17 @@ func (r *DockerRunner) Available() error {
 }
 
 // Run starts a sandbox session
-func (r *DockerRunner) Run(opts Options) error {
+func (r *DockerRunner) Run(ctx context.Context, opts Options) error {
 	if err := r.Available(); err != nil {
 		return err
 	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
 
-	args := r.buildArgs(opts)
+	args := r.RunArgs(opts)
 
-	cmd := exec.Command("docker", args...)
+	cmd := exec.CommandContext(ctx, "docker", args...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -48,8 +59,8 @@ func (r *DockerRunner) Run(opts Options) error {
 	return cmd.Run()
 }
 
-// buildArgs constructs the docker run command arguments
-func (r *DockerRunner) buildArgs(opts Options) []string {
+// RunArgs constructs the docker run command arguments
+func (r *DockerRunner) RunArgs(opts Options) []string {
 	args := []string{"run", "-it", "--rm"}
 
 	// Image
@@ -64,12 +75,17 @@ func (r *DockerRunner) buildArgs(opts Options) []string {
 	}
 
 	// Persistent state mount (if using a profile)
-	if opts.Profile != "" {
-		stateDir, err := StateDir(r.ClaudePMDir, opts.Profile)
-		if err == nil {
-			args = append(args, "-v", fmt.Sprintf("%s:/root/.claude", stateDir))
+	stateDir := opts.StateDir
+	if stateDir == "" && opts.Profile != "" {
+		if dir, err := StateDir(r.ClaudePMDir, opts.Profile); err == nil {
+			stateDir = dir
 		}
 	}
+	if stateDir != "" {
+		args = append(args, "-v", fmt.Sprintf("%s:%s/.claude", stateDir, ContainerHome))
+	}
+
+	args = append(args, securityArgs(opts)...)
 
 	// Additional mounts
 	for _, m := range opts.Mounts {
@@ -80,16 +96,29 @@ func (r *DockerRunner) buildArgs(opts Options) []string {
 		args = append(args, "-v", mountArg)
 	}
 
+	// Published ports, on loopback only so the sandbox isn't exposed to the
+	// network
+	for _, p := range opts.Ports {
+		args = append(args, "-p", p.String())
+	}
+
 	// Environment variables
-	for key, value := range opts.Env {
-		args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
+	for _, key := range slices.Sorted(maps.Keys(opts.Env)) {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", key, opts.Env[key]))
 	}
 
 	// Secrets (already resolved to values)
 	// Note: In the actual integration, secrets will be resolved before calling Run
 
-	// Network (default bridge is fine)
-	args = append(args, "--network", "bridge")
+	// Network (default bridge is fine). Docker Desktop resolves
+	// host.docker.internal itself; Linux needs it mapped to the gateway.
+	args = append(args, "--network", "bridge", "--add-host", HostGateway+":host-gateway")
+
+	// Platform, so a local image of another architecture isn't run under
+	// emulation
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
 
 	// Image
 	args = append(args, image)
@@ -103,6 +132,43 @@ func (r *DockerRunner) buildArgs(opts Options) []string {
 	return args
 }
 
+// securityArgs confines the container: a non-root user, no capabilities,
+// and no privilege escalation, unless opts.PrivilegedOK relaxes them
+func securityArgs(opts Options) []string {
+	var args []string
+	if opts.PrivilegedOK {
+		args = append(args, "--user", "0:0")
+	} else {
+		user := opts.User
+		if user == "" {
+			user = ContainerUser()
+		}
+		args = append(args, "--user", user, "--cap-drop", "ALL")
+		for _, c := range keptCapabilities {
+			args = append(args, "--cap-add", c)
+		}
+		args = append(args, "--security-opt", "no-new-privileges")
+	}
+	if opts.SeccompProfile != "" {
+		args = append(args, "--security-opt", "seccomp="+expandHome(opts.SeccompProfile))
+	}
+	if opts.AppArmorProfile != "" {
+		args = append(args, "--security-opt", "apparmor="+opts.AppArmorProfile)
+	}
+	return args
+}
+
+// ContainerUser returns the uid:gid the sandbox runs as. On Linux it's the
+// host user, so files written to /workspace belong to them; elsewhere, and
+// for root, it's the image's claude user (1000:1000).
+func ContainerUser() string {
+	uid, gid := os.Getuid(), os.Getgid()
+	if runtime.GOOS != "linux" || uid <= 0 {
+		return "1000:1000"
+	}
+	return fmt.Sprintf("%d:%d", uid, gid)
+}
+
 // insertBeforeImage inserts arguments before the image name in the args slice
 func insertBeforeImage(args []string, image string, toInsert ...string) []string {
 	for i, arg := range args {
@@ -117,13 +183,17 @@ func insertBeforeImage(args []string, image string, toInsert ...string) []string
 	return args
 }
 
-// PullImage pulls the sandbox image
-func (r *DockerRunner) PullImage(image string) error {
+// PullImage pulls the sandbox image, for platform when it isn't empty
+func (r *DockerRunner) PullImage(ctx context.Context, image, platform string) error {
 	if image == "" {
 		image = DefaultImage()
 	}
 
-	cmd := exec.Command("docker", "pull", image)
+	args := []string{"pull"}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	cmd := exec.CommandContext(ctx, "docker", append(args, image)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -143,15 +213,18 @@ func (r *DockerRunner) ImageExists(image string) bool {
 	return cmd.Run() == nil
 }
 
-// ParseMount parses a mount string in host:container[:ro] format
+// ParseMount parses a mount string in host:container[:ro] format. A
+// Windows host path keeps its drive letter, as in C:\data:/data.
 func ParseMount(s string) (Mount, error) {
+	drive := filepath.VolumeName(s)
+	s = s[len(drive):]
 	parts := strings.Split(s, ":")
 
 	if len(parts) < 2 || len(parts) > 3 {
-		return Mount{}, fmt.Errorf("invalid mount format: %s (expected host:container[:ro])", s)
+		return Mount{}, fmt.Errorf("invalid mount format: %s (expected host:container[:ro])", drive+s)
 	}
 
-	host := expandHome(parts[0])
+	host := drive + expandHome(parts[0])
 	container := parts[1]
 
 	if host == "" {
@@ -177,21 +250,52 @@ func ParseMount(s string) (Mount, error) {
 	return m, nil
 }
 
-// expandHome expands ~ to the user's home directory
-func expandHome(path string) string {
-	if strings.HasPrefix(path, "~/") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return path
-		}
-		return home + path[1:]
+// ParsePort parses a port string in [host:]container[/tcp|/udp] format.
+// A lone port is published on the same host port.
+func ParsePort(s string) (Port, error) {
+	spec, proto, hasProto := strings.Cut(s, "/")
+	if hasProto && proto != "tcp" && proto != "udp" {
+		return Port{}, fmt.Errorf("invalid port protocol: %s (expected tcp or udp)", proto)
 	}
-	if path == "~" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return path
+
+	hostPart, containerPart, hasHost := strings.Cut(spec, ":")
+	if !hasHost {
+		containerPart = hostPart
+	}
+	container, err := parsePortNumber(containerPart)
+	if err != nil {
+		return Port{}, fmt.Errorf("invalid port %q: %w", s, err)
+	}
+	host := container
+	if hasHost {
+		if host, err = parsePortNumber(hostPart); err != nil {
+			return Port{}, fmt.Errorf("invalid port %q: %w", s, err)
 		}
-		return home
 	}
-	return path
+	if proto == "tcp" {
+		proto = ""
+	}
+	return Port{Host: host, Container: container, Protocol: proto}, nil
+}
+
+func parsePortNumber(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > 65535 {
+		return 0, fmt.Errorf("%q is not a port number (expected host:container[/udp])", s)
+	}
+	return n, nil
+}
+
+// String returns the docker -p value, bound to the host's loopback interface
+func (p Port) String() string {
+	spec := fmt.Sprintf("127.0.0.1:%d:%d", p.Host, p.Container)
+	if p.Protocol != "" && p.Protocol != "tcp" {
+		spec += "/" + p.Protocol
+	}
+	return spec
+}
+
+// expandHome expands ~ to the user's home directory
+func expandHome(path string) string {
+	return pathctx.ExpandHome(path, pathctx.Default().Home)
 }
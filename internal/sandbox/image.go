@@ -0,0 +1,125 @@
+// ABOUTME: Sandbox image channels and staleness checks against the registry
+// ABOUTME: Compares the local image's build time with the upstream tag's via docker
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/platform"
+)
+
+// ImageRepo is where the sandbox images are published
+const ImageRepo = "ghcr.io/claudeup/claudeup-sandbox"
+
+// Image channels
+const (
+	ChannelStable  = "stable"
+	ChannelNightly = "nightly"
+)
+
+// DefaultStaleAfter is how far the local image may fall behind upstream
+// before the staleness check warns
+const DefaultStaleAfter = 14 * 24 * time.Hour
+
+// ImageForChannel returns the image tag for channel; empty means stable
+func ImageForChannel(channel string) (string, error) {
+	switch channel {
+	case "", ChannelStable:
+		return ImageRepo + ":latest", nil
+	case ChannelNightly:
+		return ImageRepo + ":nightly", nil
+	}
+	return "", fmt.Errorf("unknown sandbox image channel %q (expected %s or %s)", channel, ChannelStable, ChannelNightly)
+}
+
+// LocalImage describes a pulled image
+type LocalImage struct {
+	ID      string
+	Created time.Time
+	// Platform is the image's os/arch, like linux/arm64
+	Platform string
+}
+
+// InspectImage returns the local copy of image; ok is false if it hasn't
+// been pulled
+func (r *DockerRunner) InspectImage(ctx context.Context, image string) (img LocalImage, ok bool, err error) {
+	out, err := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{.Id}} {{.Created}} {{.Os}}/{{.Architecture}}", image).Output()
+	if err != nil {
+		// inspect fails the same way for a missing image and a stopped
+		// daemon; Available tells them apart
+		if availErr := r.Available(); availErr != nil {
+			return LocalImage{}, false, availErr
+		}
+		return LocalImage{}, false, nil
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 3 {
+		return LocalImage{}, false, fmt.Errorf("unexpected image description %q", strings.TrimSpace(string(out)))
+	}
+	id, created := fields[0], fields[1]
+	img.ID = id
+	img.Platform = fields[2]
+	if img.Created, err = time.Parse(time.RFC3339Nano, created); err != nil {
+		return LocalImage{}, false, fmt.Errorf("unexpected image creation time %q: %w", created, err)
+	}
+	return img, true, nil
+}
+
+// EnginePlatform returns the os/arch Docker runs containers as, which is
+// what images must be built for. It's asked of the daemon rather than taken
+// from claudeup's build: Docker Desktop on Apple silicon runs linux/arm64
+// even for an amd64 claudeup under Rosetta.
+func (r *DockerRunner) EnginePlatform(ctx context.Context) string {
+	out, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Os}}/{{.Server.Arch}}").Output()
+	if p := strings.TrimSpace(string(out)); err == nil && strings.Count(p, "/") == 1 && !strings.HasSuffix(p, "/") {
+		return p
+	}
+	return "linux/" + platform.Host().Arch
+}
+
+// UpstreamCreated asks the registry when image's current tag was built for
+// platform, without pulling it. It needs docker buildx.
+func (r *DockerRunner) UpstreamCreated(ctx context.Context, image, platform string) (time.Time, error) {
+	cmd := exec.CommandContext(ctx, "docker", "buildx", "imagetools", "inspect", "--format", "{{json .Image}}", image)
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return time.Time{}, fmt.Errorf("failed to inspect %s: %s", image, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return time.Time{}, fmt.Errorf("failed to inspect %s: %w", image, err)
+	}
+	return parseImageCreated(out, platform)
+}
+
+// parseImageCreated reads the build time from imagetools' image config,
+// which is a single config for one-platform images or a map of platform to
+// config for multi-platform ones
+func parseImageCreated(data []byte, platform string) (time.Time, error) {
+	type config struct {
+		Created time.Time `json:"created"`
+	}
+	var single config
+	if err := json.Unmarshal(data, &single); err == nil && !single.Created.IsZero() {
+		return single.Created, nil
+	}
+
+	var byPlatform map[string]config
+	if err := json.Unmarshal(data, &byPlatform); err != nil {
+		return time.Time{}, fmt.Errorf("unexpected image description: %w", err)
+	}
+	if c, ok := byPlatform[platform]; ok && !c.Created.IsZero() {
+		return c.Created, nil
+	}
+	// Every platform is built together; any of them will do
+	for _, c := range byPlatform {
+		if !c.Created.IsZero() {
+			return c.Created, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("image description has no creation time")
+}
@@ -0,0 +1,49 @@
+// ABOUTME: Tests for sandbox image channels and reading upstream build times
+// ABOUTME: Covers single- and multi-platform imagetools output
+package sandbox
+
+import (
+	"testing"
+	"time"
+)
+
+func TestImageForChannel(t *testing.T) {
+	for channel, want := range map[string]string{
+		"":        ImageRepo + ":latest",
+		"stable":  ImageRepo + ":latest",
+		"nightly": ImageRepo + ":nightly",
+	} {
+		got, err := ImageForChannel(channel)
+		if err != nil || got != want {
+			t.Errorf("ImageForChannel(%q) = %q, %v; want %q", channel, got, err, want)
+		}
+	}
+	if _, err := ImageForChannel("beta"); err == nil {
+		t.Error("expected an error for an unknown channel")
+	}
+}
+
+func TestParseImageCreated(t *testing.T) {
+	amd := time.Date(2026, 10, 1, 4, 0, 0, 0, time.UTC)
+	arm := time.Date(2026, 10, 1, 4, 5, 0, 0, time.UTC)
+
+	single := `{"created": "2026-10-01T04:00:00Z", "architecture": "amd64", "os": "linux"}`
+	if got, err := parseImageCreated([]byte(single), "linux/arm64"); err != nil || !got.Equal(amd) {
+		t.Errorf("single platform = %v, %v", got, err)
+	}
+
+	multi := `{
+		"linux/amd64": {"created": "2026-10-01T04:00:00Z"},
+		"linux/arm64": {"created": "2026-10-01T04:05:00Z"}
+	}`
+	if got, err := parseImageCreated([]byte(multi), "linux/arm64"); err != nil || !got.Equal(arm) {
+		t.Errorf("multi platform = %v, %v; want the matching platform", got, err)
+	}
+	if got, err := parseImageCreated([]byte(`{"linux/amd64": {"created": "2026-10-01T04:00:00Z"}}`), "linux/riscv64"); err != nil || !got.Equal(amd) {
+		t.Errorf("missing platform = %v, %v; want any platform's time", got, err)
+	}
+
+	if _, err := parseImageCreated([]byte(`{}`), "linux/amd64"); err == nil {
+		t.Error("expected an error without a creation time")
+	}
+}
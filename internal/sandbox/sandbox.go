@@ -3,7 +3,9 @@
 package sandbox
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 )
@@ -13,6 +15,10 @@ type Options struct {
 	// Profile name for persistent state (empty = ephemeral)
 	Profile string
 
+	// StateDir overrides the host directory mounted as Claude's config
+	// directory, e.g. a throwaway copy of the profile's state
+	StateDir string
+
 	// WorkDir is the host directory to mount at /workspace
 	// Empty string means no mount
 	WorkDir string
@@ -20,6 +26,9 @@ type Options struct {
 	// Mounts are additional host:container path mappings
 	Mounts []Mount
 
+	// Ports are container ports published on the host's loopback interface
+	Ports []Port
+
 	// Secrets are environment variable names to resolve and inject
 	Secrets []string
 
@@ -34,8 +43,45 @@ type Options struct {
 
 	// Image overrides the default sandbox image
 	Image string
+
+	// PrivilegedOK runs the container as root with Docker's default
+	// capabilities, for images that need more than the hardened defaults
+	PrivilegedOK bool
+
+	// SeccompProfile is a host path to a custom seccomp profile; empty uses
+	// Docker's default profile
+	SeccompProfile string
+
+	// AppArmorProfile is the name of a loaded AppArmor profile; empty uses
+	// Docker's default
+	AppArmorProfile string
+
+	// User is the uid:gid to run as; empty uses the host user (see
+	// ContainerUser). Ignored when PrivilegedOK is set.
+	User string
+
+	// Platform is the os/arch to run the image as, like linux/arm64; empty
+	// leaves it to Docker
+	Platform string
+}
+
+// Validate checks settings that would otherwise fail inside docker run
+func (o Options) Validate() error {
+	if o.SeccompProfile != "" {
+		if _, err := os.Stat(expandHome(o.SeccompProfile)); err != nil {
+			return fmt.Errorf("seccomp profile: %w", err)
+		}
+	}
+	return nil
 }
 
+// ContainerHome is the sandbox user's home directory inside the container
+const ContainerHome = "/home/claude"
+
+// keptCapabilities are added back after dropping all capabilities. Claude,
+// git, and node need none when running as a non-root user.
+var keptCapabilities []string
+
 // Mount represents a host-to-container path mapping
 type Mount struct {
 	Host      string
@@ -43,11 +89,21 @@ type Mount struct {
 	ReadOnly  bool
 }
 
+// Port publishes a container port on the host
+type Port struct {
+	Host      int
+	Container int
+	Protocol  string // "tcp" or "udp"; empty means tcp
+}
+
+// HostGateway is the name the container uses to reach services on the host
+const HostGateway = "host.docker.internal"
+
 // Runner executes sandbox sessions
 type Runner interface {
 	// Run starts a sandbox session with the given options
-	// It blocks until the session ends
-	Run(opts Options) error
+	// It blocks until the session ends or ctx is cancelled
+	Run(ctx context.Context, opts Options) error
 
 	// Available returns true if this runner can be used
 	Available() error
@@ -68,6 +124,63 @@ func StateDir(claudePMDir, profile string) (string, error) {
 	return dir, nil
 }
 
+// CopyState copies a profile's sandbox state into a new temporary
+// directory, so a session can start from it without changing it. The
+// caller removes the copy with the returned cleanup function.
+func CopyState(claudePMDir, profile string) (dir string, cleanup func(), err error) {
+	src, err := StateDir(claudePMDir, profile)
+	if err != nil {
+		return "", nil, err
+	}
+	dir, err = os.MkdirTemp("", "claudeup-sandbox-"+profile+"-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary sandbox state: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	if err := copyTree(src, dir); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to copy sandbox state: %w", err)
+	}
+	return dir, cleanup, nil
+}
+
+// copyTree copies the contents of src into dst, keeping file modes and
+// symlinks
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.Mkdir(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(target, data, info.Mode().Perm())
+		}
+		// Sockets and other special files don't survive a restart anyway
+		return nil
+	})
+}
+
 // CleanState removes the sandbox state directory for a profile
 func CleanState(claudePMDir, profile string) error {
 	if profile == "" {
@@ -82,7 +195,8 @@ func CleanState(claudePMDir, profile string) error {
 	return nil
 }
 
-// DefaultImage returns the default sandbox image name
+// DefaultImage returns the default sandbox image name, the stable channel
 func DefaultImage() string {
-	return "ghcr.io/claudeup/claudeup-sandbox:latest"
+	image, _ := ImageForChannel(ChannelStable)
+	return image
 }
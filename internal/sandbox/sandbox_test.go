@@ -1,10 +1,12 @@
 // ABOUTME: Unit tests for sandbox package.
-// ABOUTME: Tests state management and mount parsing functionality.
+// ABOUTME: Tests state management, mount parsing, and docker run hardening.
 package sandbox
 
 import (
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -99,8 +101,9 @@ func TestParseMount(t *testing.T) {
 		{
 			name:  "home directory expansion",
 			input: "~/data:/data",
-			want:  Mount{Host: home + "/data", Container: "/data", ReadOnly: false},
+			want:  Mount{Host: filepath.Join(home, "data"), Container: "/data", ReadOnly: false},
 		},
+
 		{
 			name:  "home directory only",
 			input: "~:/home",
@@ -133,6 +136,19 @@ func TestParseMount(t *testing.T) {
 		},
 	}
 
+	if runtime.GOOS == "windows" {
+		tests = append(tests, []struct {
+			name    string
+			input   string
+			want    Mount
+			wantErr bool
+		}{
+			{name: "drive letter", input: `C:\Users\me\data:/data:ro`, want: Mount{Host: `C:\Users\me\data`, Container: "/data", ReadOnly: true}},
+			{name: "drive letter with forward slashes", input: "D:/src:/workspace", want: Mount{Host: "D:/src", Container: "/workspace"}},
+			{name: "drive letter only", input: `C:\data`, wantErr: true},
+		}...)
+	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, err := ParseMount(tt.input)
@@ -159,7 +175,7 @@ func TestExpandHome(t *testing.T) {
 		input string
 		want  string
 	}{
-		{"~/foo", home + "/foo"},
+		{"~/foo", filepath.Join(home, "foo")},
 		{"~", home},
 		{"/absolute/path", "/absolute/path"},
 		{"relative/path", "relative/path"},
@@ -185,3 +201,129 @@ func TestDefaultImage(t *testing.T) {
 		t.Errorf("unexpected default image: %s", image)
 	}
 }
+
+func TestRunArgsHardening(t *testing.T) {
+	r := NewDockerRunner(t.TempDir())
+
+	args := strings.Join(r.RunArgs(Options{User: "501:20"}), " ")
+	for _, want := range []string{"--user 501:20", "--cap-drop ALL", "--security-opt no-new-privileges", "--add-host host.docker.internal:host-gateway"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("hardened args %q missing %q", args, want)
+		}
+	}
+	if !strings.HasSuffix(args, DefaultImage()) {
+		t.Errorf("the image should come last: %q", args)
+	}
+
+	args = strings.Join(r.RunArgs(Options{PrivilegedOK: true, SeccompProfile: "/etc/seccomp.json", AppArmorProfile: "claude-sandbox"}), " ")
+	if !strings.Contains(args, "--user 0:0") || strings.Contains(args, "--cap-drop") || strings.Contains(args, "no-new-privileges") {
+		t.Errorf("--privileged-ok args %q", args)
+	}
+	for _, want := range []string{"--security-opt seccomp=/etc/seccomp.json", "--security-opt apparmor=claude-sandbox"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("args %q missing %q", args, want)
+		}
+	}
+
+	args = strings.Join(r.RunArgs(Options{Profile: "untrusted"}), " ")
+	if !strings.Contains(args, ":"+ContainerHome+"/.claude") {
+		t.Errorf("state should mount in the sandbox user's home: %q", args)
+	}
+}
+
+func TestRunArgsPlatform(t *testing.T) {
+	r := NewDockerRunner(t.TempDir())
+
+	args := strings.Join(r.RunArgs(Options{Platform: "linux/arm64", Shell: true}), " ")
+	if !strings.Contains(args, "--platform linux/arm64 --entrypoint bash "+DefaultImage()) {
+		t.Errorf("expected --platform before the image: %q", args)
+	}
+	if args = strings.Join(r.RunArgs(Options{}), " "); strings.Contains(args, "--platform") {
+		t.Errorf("expected no --platform without one: %q", args)
+	}
+}
+
+func TestParsePort(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Port
+		wantErr bool
+	}{
+		{input: "3000", want: Port{Host: 3000, Container: 3000}},
+		{input: "8080:80", want: Port{Host: 8080, Container: 80}},
+		{input: "5353:53/udp", want: Port{Host: 5353, Container: 53, Protocol: "udp"}},
+		{input: "8080:80/tcp", want: Port{Host: 8080, Container: 80}},
+		{input: "80/sctp", wantErr: true},
+		{input: "http", wantErr: true},
+		{input: "0:80", wantErr: true},
+		{input: "8080:70000", wantErr: true},
+		{input: "127.0.0.1:8080:80", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePort(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// Published ports never listen beyond the host's loopback interface
+	if got := (Port{Host: 5353, Container: 53, Protocol: "udp"}).String(); got != "127.0.0.1:5353:53/udp" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestCopyState(t *testing.T) {
+	tmpDir := t.TempDir()
+	state, err := StateDir(tmpDir, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(state, "plugins"), 0755)
+	os.WriteFile(filepath.Join(state, "settings.json"), []byte(`{"theme":"dark"}`), 0600)
+	os.Symlink("settings.json", filepath.Join(state, "link.json"))
+
+	dir, cleanup, err := CopyState(tmpDir, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "settings.json"))
+	if err != nil || string(data) != `{"theme":"dark"}` {
+		t.Errorf("copied settings = %q, %v", data, err)
+	}
+	// Windows has no permission bits to copy
+	if info, err := os.Stat(filepath.Join(dir, "settings.json")); err != nil || (runtime.GOOS != "windows" && info.Mode().Perm() != 0600) {
+		t.Errorf("copied mode = %v, %v", info.Mode().Perm(), err)
+	}
+	if link, err := os.Readlink(filepath.Join(dir, "link.json")); err != nil || link != "settings.json" {
+		t.Errorf("copied symlink = %q, %v", link, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "plugins")); err != nil {
+		t.Errorf("copied directory: %v", err)
+	}
+
+	// Changes to the copy never reach the profile's state
+	os.WriteFile(filepath.Join(dir, "settings.json"), []byte(`{}`), 0600)
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("cleanup left the copy behind")
+	}
+	if data, _ := os.ReadFile(filepath.Join(state, "settings.json")); string(data) != `{"theme":"dark"}` {
+		t.Errorf("profile state changed to %q", data)
+	}
+
+	args := strings.Join(NewDockerRunner(tmpDir).RunArgs(Options{Profile: "work", StateDir: dir}), " ")
+	if !strings.Contains(args, dir+":"+ContainerHome+"/.claude") {
+		t.Errorf("StateDir should replace the profile's state mount: %q", args)
+	}
+}
@@ -0,0 +1,303 @@
+// ABOUTME: Installs a per-user launchd agent or systemd timer for periodic maintenance checks
+// ABOUTME: Renders the unit files and drives launchctl/systemctl to load, unload, and query them
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Label identifies the scheduled job to launchd and systemd
+const Label = "com.claudeup.maintenance"
+
+const systemdName = "claudeup-maintenance"
+
+// MinInterval is the shortest supported cadence
+const MinInterval = 15 * time.Minute
+
+// Plan describes what to run and how often
+type Plan struct {
+	Executable string
+	Interval   time.Duration
+	LogPath    string
+}
+
+// Commands returns the argument lists run on each tick
+func (p Plan) Commands() [][]string {
+	return [][]string{
+		{p.Executable, "update", "--check-only", "--json", "--scheduled"},
+		{p.Executable, "doctor", "--json", "--scheduled"},
+		{p.Executable, "sandbox", "update-image", "--check", "--scheduled"},
+	}
+}
+
+// Status reports whether the job is installed and loaded
+type Status struct {
+	Installed bool
+	Active    bool
+	Interval  time.Duration
+	UnitPath  string
+}
+
+// Backend installs the job with the platform's service manager
+type Backend interface {
+	Install(p Plan) error
+	Remove() error
+	Status() (Status, error)
+}
+
+// Runner executes a service manager command
+type Runner func(name string, args ...string) error
+
+func execRunner(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w (%s)", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ForOS returns the backend for goos, rooted at the user's home directory.
+// A nil run uses exec.
+func ForOS(goos, home string, run Runner) (Backend, error) {
+	if run == nil {
+		run = execRunner
+	}
+	switch goos {
+	case "darwin":
+		return &Launchd{Dir: filepath.Join(home, "Library", "LaunchAgents"), Run: run}, nil
+	case "linux":
+		return &Systemd{Dir: filepath.Join(home, ".config", "systemd", "user"), Run: run}, nil
+	}
+	return nil, fmt.Errorf("scheduling is not supported on %s (use cron to run 'claudeup update --check-only --json' and 'claudeup doctor --json')", goos)
+}
+
+// ParseInterval accepts "hourly", "daily", "weekly", or a Go duration like "6h"
+func ParseInterval(s string) (time.Duration, error) {
+	var d time.Duration
+	switch s {
+	case "hourly":
+		d = time.Hour
+	case "daily":
+		d = 24 * time.Hour
+	case "weekly":
+		d = 7 * 24 * time.Hour
+	default:
+		var err error
+		d, err = time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q (use hourly, daily, weekly, or a duration like 6h)", s)
+		}
+	}
+	if d < MinInterval {
+		return 0, fmt.Errorf("interval %s is shorter than the minimum %s", d, MinInterval)
+	}
+	return d, nil
+}
+
+// Launchd manages a macOS user agent
+type Launchd struct {
+	Dir string
+	Run Runner
+}
+
+func (l *Launchd) path() string {
+	return filepath.Join(l.Dir, Label+".plist")
+}
+
+// Install implements Backend
+func (l *Launchd) Install(p Plan) error {
+	if err := os.MkdirAll(l.Dir, 0755); err != nil {
+		return err
+	}
+	// Reinstalling replaces the previous agent
+	if _, err := os.Stat(l.path()); err == nil {
+		l.Run("launchctl", "unload", l.path())
+	}
+	if err := os.WriteFile(l.path(), []byte(LaunchdPlist(p)), 0644); err != nil {
+		return err
+	}
+	return l.Run("launchctl", "load", "-w", l.path())
+}
+
+// Remove implements Backend
+func (l *Launchd) Remove() error {
+	if _, err := os.Stat(l.path()); os.IsNotExist(err) {
+		return nil
+	}
+	l.Run("launchctl", "unload", "-w", l.path())
+	return os.Remove(l.path())
+}
+
+// Status implements Backend
+func (l *Launchd) Status() (Status, error) {
+	s := Status{UnitPath: l.path()}
+	data, err := os.ReadFile(l.path())
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	s.Installed = true
+	if m := plistInterval.FindSubmatch(data); m != nil {
+		secs, _ := strconv.Atoi(string(m[1]))
+		s.Interval = time.Duration(secs) * time.Second
+	}
+	s.Active = l.Run("launchctl", "list", Label) == nil
+	return s, nil
+}
+
+var plistInterval = regexp.MustCompile(`<key>StartInterval</key>\s*<integer>(\d+)</integer>`)
+
+// LaunchdPlist renders the launch agent for p
+func LaunchdPlist(p Plan) string {
+	var script []string
+	for _, args := range p.Commands() {
+		quoted := make([]string, len(args))
+		for i, a := range args {
+			quoted[i] = shellQuote(a)
+		}
+		script = append(script, strings.Join(quoted, " "))
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Label</key>
+  <string>%s</string>
+  <key>ProgramArguments</key>
+  <array>
+    <string>/bin/sh</string>
+    <string>-c</string>
+    <string>%s</string>
+  </array>
+  <key>StartInterval</key>
+  <integer>%d</integer>
+  <key>RunAtLoad</key>
+  <false/>
+  <key>StandardOutPath</key>
+  <string>%s</string>
+  <key>StandardErrorPath</key>
+  <string>%s</string>
+</dict>
+</plist>
+`, Label, xmlEscape(strings.Join(script, "; ")), int(p.Interval.Seconds()), xmlEscape(p.LogPath), xmlEscape(p.LogPath))
+}
+
+// Systemd manages a Linux user timer
+type Systemd struct {
+	Dir string
+	Run Runner
+}
+
+func (s *Systemd) servicePath() string {
+	return filepath.Join(s.Dir, systemdName+".service")
+}
+
+func (s *Systemd) timerPath() string {
+	return filepath.Join(s.Dir, systemdName+".timer")
+}
+
+// Install implements Backend
+func (s *Systemd) Install(p Plan) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	service, timer := SystemdUnits(p)
+	if err := os.WriteFile(s.servicePath(), []byte(service), 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.timerPath(), []byte(timer), 0644); err != nil {
+		return err
+	}
+	if err := s.Run("systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	return s.Run("systemctl", "--user", "enable", "--now", systemdName+".timer")
+}
+
+// Remove implements Backend
+func (s *Systemd) Remove() error {
+	if _, err := os.Stat(s.timerPath()); os.IsNotExist(err) {
+		return nil
+	}
+	s.Run("systemctl", "--user", "disable", "--now", systemdName+".timer")
+	for _, p := range []string{s.timerPath(), s.servicePath()} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return s.Run("systemctl", "--user", "daemon-reload")
+}
+
+// Status implements Backend
+func (s *Systemd) Status() (Status, error) {
+	st := Status{UnitPath: s.timerPath()}
+	data, err := os.ReadFile(s.timerPath())
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	st.Installed = true
+	if m := timerInterval.FindSubmatch(data); m != nil {
+		st.Interval, _ = time.ParseDuration(string(m[1]))
+	}
+	st.Active = s.Run("systemctl", "--user", "is-active", "--quiet", systemdName+".timer") == nil
+	return st, nil
+}
+
+var timerInterval = regexp.MustCompile(`(?m)^OnUnitActiveSec=(\S+)$`)
+
+// SystemdUnits renders the oneshot service and the timer that triggers it
+func SystemdUnits(p Plan) (service, timer string) {
+	var b strings.Builder
+	b.WriteString("[Unit]\nDescription=claudeup maintenance checks\n\n[Service]\nType=oneshot\n")
+	for _, args := range p.Commands() {
+		quoted := make([]string, len(args))
+		for i, a := range args {
+			quoted[i] = systemdQuote(a)
+		}
+		// A failed check should not stop the next one from running
+		fmt.Fprintf(&b, "ExecStart=-%s\n", strings.Join(quoted, " "))
+	}
+	fmt.Fprintf(&b, "StandardOutput=append:%s\nStandardError=append:%s\n", p.LogPath, p.LogPath)
+
+	timer = fmt.Sprintf(`[Unit]
+Description=Run claudeup maintenance checks periodically
+
+[Timer]
+OnBootSec=5min
+OnUnitActiveSec=%ds
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, int(p.Interval.Seconds()))
+
+	return b.String(), timer
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func systemdQuote(s string) string {
+	if !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,111 @@
+// ABOUTME: Tests for launchd/systemd unit rendering and install/remove/status
+// ABOUTME: Service manager commands are captured by a fake runner
+package schedule
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeRunner struct {
+	calls  []string
+	active bool
+}
+
+func (f *fakeRunner) run(name string, args ...string) error {
+	call := name + " " + strings.Join(args, " ")
+	f.calls = append(f.calls, call)
+	if (strings.Contains(call, "is-active") || strings.Contains(call, "launchctl list")) && !f.active {
+		return errors.New("inactive")
+	}
+	return nil
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := map[string]time.Duration{"hourly": time.Hour, "daily": 24 * time.Hour, "6h": 6 * time.Hour}
+	for in, want := range tests {
+		got, err := ParseInterval(in)
+		if err != nil || got != want {
+			t.Errorf("ParseInterval(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"1m", "often", ""} {
+		if _, err := ParseInterval(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestSystemdInstallStatusRemove(t *testing.T) {
+	home := t.TempDir()
+	runner := &fakeRunner{active: true}
+	b, err := ForOS("linux", home, runner.run)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := Plan{Executable: "/opt/my tools/claudeup", Interval: 6 * time.Hour, LogPath: "/tmp/schedule.log"}
+	if err := b.Install(plan); err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := os.ReadFile(b.(*Systemd).servicePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(service), `ExecStart=-"/opt/my tools/claudeup" doctor --json --scheduled`) {
+		t.Errorf("Expected quoted doctor ExecStart, got:\n%s", service)
+	}
+
+	st, err := b.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Installed || !st.Active || st.Interval != 6*time.Hour {
+		t.Errorf("Unexpected status %+v", st)
+	}
+
+	if err := b.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	st, _ = b.Status()
+	if st.Installed {
+		t.Error("Expected timer to be removed")
+	}
+	if !strings.Contains(strings.Join(runner.calls, "\n"), "enable --now claudeup-maintenance.timer") {
+		t.Errorf("Expected timer to be enabled, got %v", runner.calls)
+	}
+}
+
+func TestLaunchdInstallStatus(t *testing.T) {
+	home := t.TempDir()
+	runner := &fakeRunner{}
+	b, _ := ForOS("darwin", home, runner.run)
+
+	plan := Plan{Executable: "/usr/local/bin/claudeup", Interval: 24 * time.Hour, LogPath: "/tmp/schedule.log"}
+	if err := b.Install(plan); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := b.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Installed || st.Active || st.Interval != 24*time.Hour {
+		t.Errorf("Unexpected status %+v", st)
+	}
+
+	plist := LaunchdPlist(plan)
+	if !strings.Contains(plist, "'/usr/local/bin/claudeup' 'update' '--check-only' '--json' '--scheduled'") {
+		t.Errorf("Expected update command in plist, got:\n%s", plist)
+	}
+}
+
+func TestUnsupportedOS(t *testing.T) {
+	if _, err := ForOS("windows", t.TempDir(), nil); err == nil {
+		t.Error("Expected error on windows")
+	}
+}
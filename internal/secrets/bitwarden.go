@@ -0,0 +1,181 @@
+// ABOUTME: Bitwarden (and Vaultwarden) secret resolver using the bw CLI
+// ABOUTME: Resolves bw://<item>/<field> refs, unlocking once per process
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// BitwardenResolver resolves secrets with the Bitwarden CLI. The vault must
+// be unlocked: either BW_SESSION is set, or BW_PASSWORD is set so the
+// resolver can unlock it (logging in first with BW_CLIENTID and
+// BW_CLIENTSECRET if needed).
+type BitwardenResolver struct {
+	available *bool
+	session   *string
+
+	// run executes bw with the given arguments; replaced in tests
+	run func(args ...string) ([]byte, error)
+}
+
+// NewBitwardenResolver creates a new Bitwarden resolver
+func NewBitwardenResolver() *BitwardenResolver {
+	return &BitwardenResolver{run: runBW}
+}
+
+// BitwardenRef builds the reference resolved by BitwardenResolver. field
+// defaults to the item's password.
+func BitwardenRef(item, field string) string {
+	if field == "" {
+		field = "password"
+	}
+	return "bw://" + item + "/" + field
+}
+
+// Name returns the resolver identifier
+func (b *BitwardenResolver) Name() string {
+	return "bitwarden"
+}
+
+// Available returns true if the 'bw' CLI is installed
+func (b *BitwardenResolver) Available() bool {
+	if b.available != nil {
+		return *b.available
+	}
+
+	_, err := exec.LookPath("bw")
+	available := err == nil
+	b.available = &available
+	return available
+}
+
+type bitwardenItem struct {
+	Notes string `json:"notes"`
+	Login *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"login"`
+	Fields []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"fields"`
+}
+
+// Resolve fetches a field from a Bitwarden item
+// ref should be in the format: bw://<item id or name>/<field>
+func (b *BitwardenResolver) Resolve(ref string) (string, error) {
+	path, ok := strings.CutPrefix(ref, "bw://")
+	if !ok {
+		return "", fmt.Errorf("not a Bitwarden reference: %s", ref)
+	}
+	i := strings.LastIndex(path, "/")
+	if i <= 0 || i == len(path)-1 {
+		return "", fmt.Errorf("invalid Bitwarden reference: %s", ref)
+	}
+	itemName, field := path[:i], path[i+1:]
+
+	session, err := b.unlock()
+	if err != nil {
+		return "", err
+	}
+	args := []string{"get", "item", itemName}
+	if session != "" {
+		args = append(args, "--session", session)
+	}
+	data, err := b.run(args...)
+	if err != nil {
+		return "", err
+	}
+	var item bitwardenItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return "", fmt.Errorf("unexpected output from bw: %w", err)
+	}
+
+	switch strings.ToLower(field) {
+	case "password":
+		if item.Login != nil {
+			return item.Login.Password, nil
+		}
+	case "username":
+		if item.Login != nil {
+			return item.Login.Username, nil
+		}
+	case "notes":
+		return item.Notes, nil
+	}
+	for _, f := range item.Fields {
+		if strings.EqualFold(f.Name, field) {
+			return f.Value, nil
+		}
+	}
+	return "", fmt.Errorf("field %q not found in Bitwarden item %q", field, itemName)
+}
+
+// unlock returns a session key for the vault, unlocking it at most once
+// per process
+func (b *BitwardenResolver) unlock() (string, error) {
+	if b.session != nil {
+		return *b.session, nil
+	}
+	if s := os.Getenv("BW_SESSION"); s != "" {
+		b.session = &s
+		return s, nil
+	}
+
+	data, err := b.run("status")
+	if err != nil {
+		return "", err
+	}
+	var status struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return "", fmt.Errorf("unexpected output from bw status: %w", err)
+	}
+
+	if status.Status == "unauthenticated" {
+		if os.Getenv("BW_CLIENTID") == "" || os.Getenv("BW_CLIENTSECRET") == "" {
+			return "", errors.New("not logged in to Bitwarden; run 'bw login' or set BW_CLIENTID and BW_CLIENTSECRET")
+		}
+		if _, err := b.run("login", "--apikey"); err != nil {
+			return "", err
+		}
+		status.Status = "locked"
+	}
+
+	session := ""
+	if status.Status == "locked" {
+		if os.Getenv("BW_PASSWORD") == "" {
+			return "", errors.New("Bitwarden vault is locked; export BW_SESSION from 'bw unlock' or set BW_PASSWORD")
+		}
+		out, err := b.run("unlock", "--passwordenv", "BW_PASSWORD", "--raw")
+		if err != nil {
+			return "", err
+		}
+		session = strings.TrimSpace(string(out))
+	}
+	b.session = &session
+	return session, nil
+}
+
+func runBW(args ...string) ([]byte, error) {
+	cmd := exec.Command("bw", args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("bw %s: %s", args[0], msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Tests for the Bitwarden resolver
+// ABOUTME: Uses a fake bw CLI to cover field lookup and unlocking
+package secrets
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const bwItem = `{
+  "notes": "some notes",
+  "login": {"username": "me", "password": "hunter2"},
+  "fields": [{"name": "API Key", "value": "sk-123"}]
+}`
+
+// fakeBW answers bw commands and records them
+func fakeBW(status string, calls *[]string) func(args ...string) ([]byte, error) {
+	return func(args ...string) ([]byte, error) {
+		*calls = append(*calls, strings.Join(args, " "))
+		switch args[0] {
+		case "status":
+			return []byte(`{"status":"` + status + `"}`), nil
+		case "login":
+			return nil, nil
+		case "unlock":
+			return []byte("session-key\n"), nil
+		case "get":
+			if args[2] == "OpenAI" {
+				return []byte(bwItem), nil
+			}
+			return nil, errors.New("Not found.")
+		}
+		return nil, errors.New("unexpected command")
+	}
+}
+
+func TestBitwardenResolveFields(t *testing.T) {
+	t.Setenv("BW_SESSION", "from-env")
+	var calls []string
+	b := &BitwardenResolver{run: fakeBW("unlocked", &calls)}
+
+	tests := map[string]string{
+		BitwardenRef("OpenAI", ""):         "hunter2",
+		BitwardenRef("OpenAI", "username"): "me",
+		BitwardenRef("OpenAI", "notes"):    "some notes",
+		BitwardenRef("OpenAI", "api key"):  "sk-123",
+	}
+	for ref, want := range tests {
+		got, err := b.Resolve(ref)
+		if err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+	if calls[0] != "get item OpenAI --session from-env" {
+		t.Errorf("Expected BW_SESSION to be passed, got %q", calls[0])
+	}
+
+	if _, err := b.Resolve(BitwardenRef("OpenAI", "missing")); err == nil {
+		t.Error("Expected an error for a missing field")
+	}
+	if _, err := b.Resolve("op://Private/OpenAI/credential"); err == nil {
+		t.Error("Expected other references to be rejected")
+	}
+}
+
+func TestBitwardenUnlocksOnce(t *testing.T) {
+	t.Setenv("BW_SESSION", "")
+	t.Setenv("BW_CLIENTID", "id")
+	t.Setenv("BW_CLIENTSECRET", "secret")
+	t.Setenv("BW_PASSWORD", "master")
+	var calls []string
+	b := &BitwardenResolver{run: fakeBW("unauthenticated", &calls)}
+
+	b.Resolve(BitwardenRef("OpenAI", "password"))
+	b.Resolve(BitwardenRef("OpenAI", "username"))
+
+	want := []string{
+		"status",
+		"login --apikey",
+		"unlock --passwordenv BW_PASSWORD --raw",
+		"get item OpenAI --session session-key",
+		"get item OpenAI --session session-key",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected bw calls:\n%s", strings.Join(calls, "\n"))
+	}
+}
+
+func TestBitwardenLockedWithoutPassword(t *testing.T) {
+	t.Setenv("BW_SESSION", "")
+	t.Setenv("BW_PASSWORD", "")
+	var calls []string
+	b := &BitwardenResolver{run: fakeBW("locked", &calls)}
+
+	_, err := b.Resolve(BitwardenRef("OpenAI", "password"))
+	if err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("Expected a locked vault error, got %v", err)
+	}
+}
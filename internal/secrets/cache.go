@@ -0,0 +1,196 @@
+// ABOUTME: Opt-in cache of resolved secrets, encrypted at rest with a session key
+// ABOUTME: The key lives in a private per-user directory, by preference the runtime one cleared at logout
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache stores resolved secrets for a limited time so backends such as
+// 1Password are not asked again on every apply. Entries are encrypted with
+// a key kept outside the cache file; without the key the file is unreadable.
+type Cache struct {
+	path    string
+	keyPath string
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	Value   string    `json:"value"`
+	Source  string    `json:"source"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewCache returns a cache of entries in path, encrypted with the key in
+// keyPath, that expire after ttl
+func NewCache(path, keyPath string, ttl time.Duration) *Cache {
+	return &Cache{path: path, keyPath: keyPath, ttl: ttl, now: time.Now}
+}
+
+// DefaultKeyPath returns where the session key is kept: $XDG_RUNTIME_DIR
+// when set (a tmpfs cleared at logout), otherwise the user's cache
+// directory, and only failing that a per-user directory under the system
+// temp dir
+func DefaultKeyPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "claudeup", "secrets.key")
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "claudeup", "secrets.key")
+	}
+	// Windows has no uids, but its temp dir is already per user
+	name := "claudeup"
+	if uid := os.Getuid(); uid != -1 {
+		name = fmt.Sprintf("claudeup-%d", uid)
+	}
+	return filepath.Join(os.TempDir(), name, "secrets.key")
+}
+
+// Get returns the cached value for ref and the resolver that produced it
+func (c *Cache) Get(ref string) (value, source string, ok bool) {
+	entries, err := c.load()
+	if err != nil {
+		return "", "", false
+	}
+	entry, ok := entries[ref]
+	if !ok || !c.now().Before(entry.Expires) {
+		return "", "", false
+	}
+	return entry.Value, entry.Source, true
+}
+
+// Put caches value for ref until the TTL runs out, dropping expired entries
+func (c *Cache) Put(ref, value, source string) error {
+	entries, err := c.load()
+	if err != nil {
+		entries = make(map[string]cacheEntry)
+	}
+	now := c.now()
+	for k, entry := range entries {
+		if !now.Before(entry.Expires) {
+			delete(entries, k)
+		}
+	}
+	entries[ref] = cacheEntry{Value: value, Source: source, Expires: now.Add(c.ttl)}
+	return c.save(entries)
+}
+
+// Purge deletes the cache and its key
+func (c *Cache) Purge() error {
+	for _, path := range []string{c.path, c.keyPath} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cache) load() (map[string]cacheEntry, error) {
+	if err := checkKeyDir(filepath.Dir(c.keyPath)); err != nil {
+		return nil, err
+	}
+	key, err := os.ReadFile(c.keyPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("secret cache is truncated")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]cacheEntry
+	if err := json.Unmarshal(plain, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (c *Cache) save(entries map[string]cacheEntry) error {
+	key, err := c.sessionKey()
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, gcm.Seal(nonce, nonce, plain, nil), 0600)
+}
+
+// sessionKey reads the key, creating one if the session has none yet
+func (c *Cache) sessionKey() ([]byte, error) {
+	dir := filepath.Dir(c.keyPath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := checkKeyDir(dir); err != nil {
+		return nil, err
+	}
+	key, err := os.ReadFile(c.keyPath)
+	if err == nil && len(key) == 32 {
+		return key, nil
+	}
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(c.keyPath, key, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// checkKeyDir refuses a key directory that is a symlink or that another
+// user could have created or can read, since in a shared temp dir the name
+// is predictable and could be planted
+func checkKeyDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("secret cache key directory %s is a symlink", dir)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("secret cache key directory %s is not a directory", dir)
+	}
+	return checkPrivate(dir, info)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
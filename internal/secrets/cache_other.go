@@ -0,0 +1,21 @@
+//go:build !windows
+
+// ABOUTME: Non-Windows half of the key directory check: owner and permission bits
+// ABOUTME: The directory must belong to the current user and be mode 0700
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func checkPrivate(dir string, info os.FileInfo) error {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("secret cache key directory %s is owned by another user", dir)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		return fmt.Errorf("secret cache key directory %s has mode %04o, want 0700", dir, perm)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// ABOUTME: Tests for the encrypted secret cache
+// ABOUTME: Covers expiry, encryption at rest, purging, and use by the chain
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestCache(t *testing.T, ttl time.Duration) *Cache {
+	t.Helper()
+	dir := t.TempDir()
+	return NewCache(filepath.Join(dir, "secrets.cache"), filepath.Join(dir, "run", "secrets.key"), ttl)
+}
+
+func TestCacheExpires(t *testing.T) {
+	cache := newTestCache(t, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	if err := cache.Put("op://vault/item/field", "s3cret", "1password"); err != nil {
+		t.Fatal(err)
+	}
+	value, source, ok := cache.Get("op://vault/item/field")
+	if !ok || value != "s3cret" || source != "1password" {
+		t.Fatalf("Expected cached value, got %q, %q, %v", value, source, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, _, ok := cache.Get("op://vault/item/field"); ok {
+		t.Error("Expected entry to expire after the TTL")
+	}
+}
+
+func TestCacheEncryptedAtRest(t *testing.T) {
+	cache := newTestCache(t, time.Minute)
+	if err := cache.Put("ref", "plaintext-value", "keychain"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cache.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "plaintext-value") {
+		t.Error("Cache file should not contain the secret in plain text")
+	}
+
+	// A new session key makes the old cache unreadable
+	os.Remove(cache.keyPath)
+	if _, _, ok := cache.Get("ref"); ok {
+		t.Error("Expected a miss without the session key")
+	}
+}
+
+func TestCachePurge(t *testing.T) {
+	cache := newTestCache(t, time.Minute)
+	if err := cache.Purge(); err != nil {
+		t.Fatalf("Purging an empty cache should succeed: %v", err)
+	}
+	cache.Put("ref", "value", "1password")
+	if err := cache.Purge(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := cache.Get("ref"); ok {
+		t.Error("Expected a miss after purging")
+	}
+}
+
+func TestCacheRefusesUnsafeKeyDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no mode bits to check")
+	}
+	dir := t.TempDir()
+
+	open := filepath.Join(dir, "open")
+	if err := os.Mkdir(open, 0700); err != nil {
+		t.Fatal(err)
+	}
+	os.Chmod(open, 0755)
+	cache := NewCache(filepath.Join(dir, "open.cache"), filepath.Join(open, "secrets.key"), time.Minute)
+	if err := cache.Put("ref", "value", "1password"); err == nil || !strings.Contains(err.Error(), "mode 0755") {
+		t.Errorf("Expected a group-readable key directory to be refused, got %v", err)
+	}
+
+	planted := filepath.Join(dir, "planted")
+	if err := os.Mkdir(planted, 0700); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(planted, link); err != nil {
+		t.Fatal(err)
+	}
+	cache = NewCache(filepath.Join(dir, "link.cache"), filepath.Join(link, "secrets.key"), time.Minute)
+	if err := cache.Put("ref", "value", "1password"); err == nil || !strings.Contains(err.Error(), "symlink") {
+		t.Errorf("Expected a symlinked key directory to be refused, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(planted, "secrets.key")); !os.IsNotExist(err) {
+		t.Error("No key should be written through the symlink")
+	}
+}
+
+type countingResolver struct {
+	mockResolver
+	calls int
+}
+
+func (c *countingResolver) Resolve(ref string) (string, error) {
+	c.calls++
+	return c.mockResolver.Resolve(ref)
+}
+
+func TestChainUsesCache(t *testing.T) {
+	op := &countingResolver{mockResolver: mockResolver{name: "1password", available: true, value: "from-op"}}
+	chain := NewChain(op)
+	chain.SetCache(newTestCache(t, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		value, source, err := chain.Resolve("op://vault/item/field")
+		if err != nil || value != "from-op" || source != "1password" {
+			t.Fatalf("Unexpected result %q, %q, %v", value, source, err)
+		}
+	}
+	if op.calls != 1 {
+		t.Errorf("Expected 1Password to be asked once, got %d calls", op.calls)
+	}
+}
+
+func TestChainDoesNotCacheEnv(t *testing.T) {
+	env := &countingResolver{mockResolver: mockResolver{name: "env", available: true, value: "from-env"}}
+	chain := NewChain(env)
+	chain.SetCache(newTestCache(t, time.Minute))
+
+	chain.Resolve("API_KEY")
+	chain.Resolve("API_KEY")
+	if env.calls != 2 {
+		t.Errorf("Expected env to be read every time, got %d calls", env.calls)
+	}
+}
@@ -0,0 +1,9 @@
+// ABOUTME: Windows half of the key directory check, which has no owner or mode bits to compare
+// ABOUTME: The key lives under the user's profile, whose ACLs already keep other users out
+package secrets
+
+import "os"
+
+func checkPrivate(dir string, info os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,143 @@
+// ABOUTME: 1Password Connect client for resolving op:// references without the op CLI
+// ABOUTME: Used by the 1Password resolver when OP_CONNECT_HOST and OP_CONNECT_TOKEN are set
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ConnectClient reads items from a 1Password Connect server
+type ConnectClient struct {
+	Host   string // e.g. http://localhost:8080
+	Token  string
+	Client *http.Client
+}
+
+// NewConnectClientFromEnv returns a client for OP_CONNECT_HOST using
+// OP_CONNECT_TOKEN, or nil if either is unset
+func NewConnectClientFromEnv() *ConnectClient {
+	host, token := os.Getenv("OP_CONNECT_HOST"), os.Getenv("OP_CONNECT_TOKEN")
+	if host == "" || token == "" {
+		return nil
+	}
+	return &ConnectClient{
+		Host:   strings.TrimRight(host, "/"),
+		Token:  token,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type connectRef struct {
+	vault, item, section, field string
+}
+
+// parseOPRef splits op://vault/item/field or op://vault/item/section/field
+func parseOPRef(ref string) (connectRef, error) {
+	path, ok := strings.CutPrefix(ref, "op://")
+	if !ok {
+		return connectRef{}, fmt.Errorf("not a 1Password reference: %s", ref)
+	}
+	path, _, _ = strings.Cut(path, "?")
+	parts := strings.Split(path, "/")
+	switch len(parts) {
+	case 3:
+		return connectRef{vault: parts[0], item: parts[1], field: parts[2]}, nil
+	case 4:
+		return connectRef{vault: parts[0], item: parts[1], section: parts[2], field: parts[3]}, nil
+	}
+	return connectRef{}, fmt.Errorf("invalid 1Password reference: %s", ref)
+}
+
+type connectItem struct {
+	Fields []struct {
+		ID      string `json:"id"`
+		Label   string `json:"label"`
+		Value   string `json:"value"`
+		Section *struct {
+			ID string `json:"id"`
+		} `json:"section"`
+	} `json:"fields"`
+	Sections []struct {
+		ID    string `json:"id"`
+		Label string `json:"label"`
+	} `json:"sections"`
+}
+
+// Read resolves an op:// reference
+func (c *ConnectClient) Read(ref string) (string, error) {
+	r, err := parseOPRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	vaultID, err := c.lookup("/v1/vaults", "name", r.vault)
+	if err != nil {
+		return "", fmt.Errorf("vault %q: %w", r.vault, err)
+	}
+	itemID, err := c.lookup("/v1/vaults/"+vaultID+"/items", "title", r.item)
+	if err != nil {
+		return "", fmt.Errorf("item %q: %w", r.item, err)
+	}
+
+	var item connectItem
+	if err := c.get("/v1/vaults/"+vaultID+"/items/"+itemID, &item); err != nil {
+		return "", fmt.Errorf("item %q: %w", r.item, err)
+	}
+
+	sectionIDs := make(map[string]bool)
+	for _, s := range item.Sections {
+		if r.section != "" && (strings.EqualFold(s.Label, r.section) || s.ID == r.section) {
+			sectionIDs[s.ID] = true
+		}
+	}
+	for _, f := range item.Fields {
+		if !strings.EqualFold(f.Label, r.field) && f.ID != r.field {
+			continue
+		}
+		if r.section != "" && (f.Section == nil || !sectionIDs[f.Section.ID]) {
+			continue
+		}
+		return f.Value, nil
+	}
+	return "", fmt.Errorf("field %q not found in item %q", r.field, r.item)
+}
+
+// lookup finds the ID of the vault or item called name, falling back to
+// treating name as an ID, since references may use either
+func (c *ConnectClient) lookup(path, attr, name string) (string, error) {
+	var matches []struct {
+		ID string `json:"id"`
+	}
+	filter := fmt.Sprintf("%s eq %q", attr, name)
+	if err := c.get(path+"?filter="+url.QueryEscape(filter), &matches); err != nil {
+		return "", err
+	}
+	if len(matches) > 0 {
+		return matches[0].ID, nil
+	}
+	return name, nil
+}
+
+func (c *ConnectClient) get(path string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.Host+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("1Password Connect returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,101 @@
+// ABOUTME: Tests for the 1Password Connect client
+// ABOUTME: Runs lookups against a fake Connect server
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func fakeConnect(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		filter := r.URL.Query().Get("filter")
+		var body interface{}
+		switch {
+		case r.URL.Path == "/v1/vaults" && filter == `name eq "Private"`:
+			body = []map[string]string{{"id": "v1"}}
+		case r.URL.Path == "/v1/vaults/v1/items" && filter == `title eq "OpenAI"`:
+			body = []map[string]string{{"id": "i1"}}
+		case r.URL.Path == "/v1/vaults/v1/items/i1":
+			body = map[string]interface{}{
+				"sections": []map[string]string{{"id": "s1", "label": "prod"}},
+				"fields": []map[string]interface{}{
+					{"id": "credential", "label": "credential", "value": "sk-default"},
+					{"id": "f2", "label": "credential", "value": "sk-prod", "section": map[string]string{"id": "s1"}},
+				},
+			}
+		case strings.HasSuffix(r.URL.Path, "/items") || r.URL.Path == "/v1/vaults":
+			body = []interface{}{}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestConnectRead(t *testing.T) {
+	srv := fakeConnect(t)
+	client := &ConnectClient{Host: srv.URL, Token: "test-token", Client: srv.Client()}
+
+	value, err := client.Read("op://Private/OpenAI/credential")
+	if err != nil || value != "sk-default" {
+		t.Errorf("Expected sk-default, got %q, %v", value, err)
+	}
+
+	value, err = client.Read("op://Private/OpenAI/prod/credential")
+	if err != nil || value != "sk-prod" {
+		t.Errorf("Expected the field from the prod section, got %q, %v", value, err)
+	}
+
+	if _, err := client.Read("op://Private/OpenAI/missing"); err == nil {
+		t.Error("Expected an error for a missing field")
+	}
+	if _, err := client.Read("op://Shared/OpenAI/credential"); err == nil {
+		t.Error("Expected an error for an unknown vault")
+	}
+
+	client.Token = "wrong"
+	if _, err := client.Read("op://Private/OpenAI/credential"); err == nil {
+		t.Error("Expected an error for a rejected token")
+	}
+}
+
+func TestParseOPRef(t *testing.T) {
+	if _, err := parseOPRef("Private/OpenAI/credential"); err == nil {
+		t.Error("Expected an error without the op:// prefix")
+	}
+	if _, err := parseOPRef("op://Private/OpenAI"); err == nil {
+		t.Error("Expected an error without a field")
+	}
+	r, err := parseOPRef("op://Private/OpenAI/credential?attribute=otp")
+	if err != nil || r.field != "credential" {
+		t.Errorf("Expected the query to be dropped, got %+v, %v", r, err)
+	}
+}
+
+func TestOnePasswordUsesConnectFromEnv(t *testing.T) {
+	srv := fakeConnect(t)
+	t.Setenv("OP_CONNECT_HOST", srv.URL+"/")
+	t.Setenv("OP_CONNECT_TOKEN", "test-token")
+	t.Setenv("PATH", t.TempDir())
+
+	r := NewOnePasswordResolver()
+	if !r.Available() {
+		t.Fatal("Expected the resolver to be available through Connect without op installed")
+	}
+	value, err := r.Resolve("op://Private/OpenAI/credential")
+	if err != nil || value != "sk-default" {
+		t.Errorf("Expected sk-default, got %q, %v", value, err)
+	}
+}
@@ -0,0 +1,120 @@
+// ABOUTME: Env file secret resolver for values saved to ~/.claudeup/secrets.env
+// ABOUTME: Resolves the same names as env sources; the file is plain text with 0600 permissions
+package secrets
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// EnvFileResolver resolves secrets from a dotenv-style file of NAME=value
+// lines, for values saved by the secret setup wizard
+type EnvFileResolver struct {
+	path string
+}
+
+// NewEnvFileResolver creates a resolver for the env file at path
+func NewEnvFileResolver(path string) *EnvFileResolver {
+	return &EnvFileResolver{path: path}
+}
+
+// Name returns the resolver identifier
+func (e *EnvFileResolver) Name() string {
+	return "envfile"
+}
+
+// Available returns true if the env file exists
+func (e *EnvFileResolver) Available() bool {
+	_, err := os.Stat(e.path)
+	return err == nil
+}
+
+// Resolve returns the value of name in the env file
+func (e *EnvFileResolver) Resolve(name string) (string, error) {
+	values, err := readEnvFile(e.path)
+	if err != nil {
+		return "", err
+	}
+	value, ok := values[name]
+	if !ok || value == "" {
+		return "", fmt.Errorf("%s not set in %s", name, e.path)
+	}
+	return value, nil
+}
+
+// StoreEnvFile sets name to value in the env file at path, creating it
+// with owner-only permissions
+func StoreEnvFile(path, name, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	line := name + "=" + strconv.Quote(value)
+	var lines []string
+	replaced := false
+	for _, l := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if l == "" {
+			continue
+		}
+		if k, _, ok := parseEnvLine(l); ok && k == name {
+			l, replaced = line, true
+		}
+		lines = append(lines, l)
+	}
+	if !replaced {
+		lines = append(lines, line)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(path, 0600)
+}
+
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if k, v, ok := parseEnvLine(scanner.Text()); ok {
+			values[k] = v
+		}
+	}
+	return values, scanner.Err()
+}
+
+// parseEnvLine parses NAME=value, optionally prefixed with "export" and
+// with a double- or single-quoted value
+func parseEnvLine(line string) (name, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	line = strings.TrimPrefix(line, "export ")
+	name, value, ok = strings.Cut(line, "=")
+	if !ok {
+		return "", "", false
+	}
+	name = strings.TrimSpace(name)
+	value = strings.TrimSpace(value)
+	if unquoted, err := strconv.Unquote(value); err == nil && strings.HasPrefix(value, `"`) {
+		value = unquoted
+	} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		value = value[1 : len(value)-1]
+	}
+	return name, value, true
+}
@@ -0,0 +1,45 @@
+// ABOUTME: Tests for the env file resolver and StoreEnvFile
+// ABOUTME: Covers parsing, quoting round trips, and file permissions
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.env")
+	os.WriteFile(path, []byte("# saved by hand\nexport OTHER='kept'\nTOKEN=old\n"), 0644)
+
+	if err := StoreEnvFile(path, "TOKEN", `new "quoted" value`); err != nil {
+		t.Fatal(err)
+	}
+	if err := StoreEnvFile(path, "ADDED", "x=y"); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewEnvFileResolver(path)
+	for name, want := range map[string]string{"TOKEN": `new "quoted" value`, "ADDED": "x=y", "OTHER": "kept"} {
+		if got, err := r.Resolve(name); err != nil || got != want {
+			t.Errorf("Resolve(%s) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := r.Resolve("MISSING"); err == nil {
+		t.Error("Expected an error for a missing name")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected 0600 permissions, got %v", info.Mode().Perm())
+	}
+}
+
+func TestEnvFileUnavailableWithoutFile(t *testing.T) {
+	if NewEnvFileResolver(filepath.Join(t.TempDir(), "none.env")).Available() {
+		t.Error("Expected the resolver to be unavailable without a file")
+	}
+}
@@ -4,6 +4,7 @@ package secrets
 
 import (
 	"bytes"
+	"fmt"
 	"os/exec"
 	"runtime"
 	"strings"
@@ -62,3 +63,23 @@ func (k *KeychainResolver) Resolve(ref string) (string, error) {
 
 	return strings.TrimSpace(stdout.String()), nil
 }
+
+// StoreKeychain saves value as the generic password for service and
+// account, replacing an existing one
+func StoreKeychain(service, account, value string) error {
+	args := []string{"add-generic-password", "-U", "-s", service, "-w", value}
+	if account != "" {
+		args = append(args, "-a", account)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("security", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("security: %s", msg)
+		}
+		return err
+	}
+	return nil
+}
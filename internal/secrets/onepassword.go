@@ -1,9 +1,10 @@
-// ABOUTME: 1Password CLI secret resolver
-// ABOUTME: Uses 'op read' to fetch secrets from 1Password vaults
+// ABOUTME: 1Password secret resolver using the op CLI or a Connect server
+// ABOUTME: 'op read' also works headless when OP_SERVICE_ACCOUNT_TOKEN is set
 package secrets
 
 import (
 	"bytes"
+	"fmt"
 	"os/exec"
 	"strings"
 )
@@ -11,11 +12,14 @@ import (
 // OnePasswordResolver resolves secrets using 1Password CLI
 type OnePasswordResolver struct {
 	available *bool
+	connect   *ConnectClient
 }
 
-// NewOnePasswordResolver creates a new 1Password resolver
+// NewOnePasswordResolver creates a new 1Password resolver. When
+// OP_CONNECT_HOST and OP_CONNECT_TOKEN are set, references are read from
+// that Connect server instead of through the op CLI.
 func NewOnePasswordResolver() *OnePasswordResolver {
-	return &OnePasswordResolver{}
+	return &OnePasswordResolver{connect: NewConnectClientFromEnv()}
 }
 
 // Name returns the resolver identifier
@@ -23,8 +27,12 @@ func (o *OnePasswordResolver) Name() string {
 	return "1password"
 }
 
-// Available returns true if the 'op' CLI is installed
+// Available returns true if a Connect server is configured or the 'op'
+// CLI is installed
 func (o *OnePasswordResolver) Available() bool {
+	if o.connect != nil {
+		return true
+	}
 	if o.available != nil {
 		return *o.available
 	}
@@ -35,9 +43,13 @@ func (o *OnePasswordResolver) Available() bool {
 	return available
 }
 
-// Resolve fetches a secret from 1Password using 'op read'
+// Resolve fetches a secret from 1Password using Connect or 'op read'
 // ref should be in the format: op://vault/item/field
 func (o *OnePasswordResolver) Resolve(ref string) (string, error) {
+	if o.connect != nil {
+		return o.connect.Read(ref)
+	}
+
 	cmd := exec.Command("op", "read", ref)
 
 	var stdout, stderr bytes.Buffer
@@ -45,8 +57,42 @@ func (o *OnePasswordResolver) Resolve(ref string) (string, error) {
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("op read: %s", msg)
+		}
 		return "", err
 	}
 
 	return strings.TrimSpace(stdout.String()), nil
 }
+
+// StoreOnePassword saves value at an op://vault/item/field reference with
+// the op CLI, creating the item if it doesn't exist
+func StoreOnePassword(ref, value string) error {
+	r, err := parseOPRef(ref)
+	if err != nil {
+		return err
+	}
+	assignment := r.field + "=" + value
+	if r.section != "" {
+		assignment = r.section + "." + assignment
+	}
+
+	if err := runOP("item", "edit", r.item, "--vault", r.vault, assignment); err == nil {
+		return nil
+	}
+	return runOP("item", "create", "--category", "API Credential", "--title", r.item, "--vault", r.vault, assignment)
+}
+
+func runOP(args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("op", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("op %s: %s", args[0], msg)
+		}
+		return err
+	}
+	return nil
+}
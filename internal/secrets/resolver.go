@@ -21,6 +21,7 @@ type Resolver interface {
 // Chain holds multiple resolvers and tries them in order
 type Chain struct {
 	resolvers []Resolver
+	cache     *Cache
 }
 
 // NewChain creates a new resolution chain with the given resolvers
@@ -35,6 +36,12 @@ func (c *Chain) Resolve(ref string) (string, string, error) {
 		return "", "", errors.New("no resolvers configured")
 	}
 
+	if c.cache != nil {
+		if value, source, ok := c.cache.Get(ref); ok {
+			return value, source, nil
+		}
+	}
+
 	var lastErr error
 	for _, r := range c.resolvers {
 		if !r.Available() {
@@ -47,6 +54,11 @@ func (c *Chain) Resolve(ref string) (string, string, error) {
 			continue
 		}
 
+		// Local env vars and files are cheap to read and may change, so
+		// only other backends are cached
+		if c.cache != nil && r.Name() != "env" && r.Name() != "envfile" {
+			c.cache.Put(ref, value, r.Name())
+		}
 		return value, r.Name(), nil
 	}
 
@@ -61,3 +73,9 @@ func (c *Chain) Resolve(ref string) (string, string, error) {
 func (c *Chain) AddResolver(r Resolver) {
 	c.resolvers = append(c.resolvers, r)
 }
+
+// SetCache makes the chain check cache before its resolvers and remember
+// what they return
+func (c *Chain) SetCache(cache *Cache) {
+	c.cache = cache
+}
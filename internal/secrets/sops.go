@@ -0,0 +1,121 @@
+// ABOUTME: SOPS secret resolver for encrypted files such as ~/.claudeup/secrets.enc.yaml
+// ABOUTME: Decrypts with the sops CLI (age, PGP, or cloud KMS keys) once per file per process
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultSopsFile is the file read when a sops source doesn't name one
+const DefaultSopsFile = "secrets.enc.yaml"
+
+// SopsResolver resolves values from SOPS-encrypted YAML or JSON files
+type SopsResolver struct {
+	dir       string
+	available *bool
+	docs      map[string]map[string]interface{}
+
+	// decrypt returns a file's decrypted contents as JSON; replaced in tests
+	decrypt func(path string) ([]byte, error)
+}
+
+// NewSopsResolver creates a SOPS resolver. Relative file names are
+// resolved against dir.
+func NewSopsResolver(dir string) *SopsResolver {
+	return &SopsResolver{dir: dir, docs: make(map[string]map[string]interface{}), decrypt: sopsDecrypt}
+}
+
+// SopsRef builds the reference resolved by SopsResolver. key is a dotted
+// path into the file, e.g. "openai.api_key"; an empty file means
+// DefaultSopsFile.
+func SopsRef(file, key string) string {
+	return "sops://" + file + "#" + key
+}
+
+// Name returns the resolver identifier
+func (s *SopsResolver) Name() string {
+	return "sops"
+}
+
+// Available returns true if the 'sops' CLI is installed
+func (s *SopsResolver) Available() bool {
+	if s.available != nil {
+		return *s.available
+	}
+
+	_, err := exec.LookPath("sops")
+	available := err == nil
+	s.available = &available
+	return available
+}
+
+// Resolve decrypts the referenced file and returns the value at key
+// ref should be in the format: sops://<file>#<key>
+func (s *SopsResolver) Resolve(ref string) (string, error) {
+	rest, ok := strings.CutPrefix(ref, "sops://")
+	if !ok {
+		return "", fmt.Errorf("not a SOPS reference: %s", ref)
+	}
+	i := strings.LastIndex(rest, "#")
+	if i < 0 || i == len(rest)-1 {
+		return "", fmt.Errorf("invalid SOPS reference: %s", ref)
+	}
+	file, key := rest[:i], rest[i+1:]
+	if file == "" {
+		file = DefaultSopsFile
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(s.dir, file)
+	}
+
+	doc, ok := s.docs[file]
+	if !ok {
+		data, err := s.decrypt(file)
+		if err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return "", fmt.Errorf("unexpected output from sops: %w", err)
+		}
+		s.docs[file] = doc
+	}
+
+	var value interface{} = doc
+	for _, part := range strings.Split(key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("key %q not found in %s", key, file)
+		}
+		if value, ok = m[part]; !ok {
+			return "", fmt.Errorf("key %q not found in %s", key, file)
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64, bool:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("key %q in %s is not a single value", key, file)
+}
+
+func sopsDecrypt(path string) ([]byte, error) {
+	cmd := exec.Command("sops", "--decrypt", "--output-type", "json", path)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("sops: %s", msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
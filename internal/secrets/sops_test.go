@@ -0,0 +1,51 @@
+// ABOUTME: Tests for the SOPS resolver
+// ABOUTME: Uses a fake decrypt step to cover file lookup and key paths
+package secrets
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSopsResolve(t *testing.T) {
+	var decrypted []string
+	s := NewSopsResolver("/home/me/.claudeup")
+	s.decrypt = func(path string) ([]byte, error) {
+		decrypted = append(decrypted, path)
+		switch path {
+		case "/home/me/.claudeup/secrets.enc.yaml":
+			return []byte(`{"openai": {"api_key": "sk-123"}, "port": 5432, "list": ["a"]}`), nil
+		case "/team/secrets.enc.yaml":
+			return []byte(`{"db_url": "postgres://team"}`), nil
+		}
+		return nil, errors.New("no such file")
+	}
+
+	tests := map[string]string{
+		SopsRef("", "openai.api_key"):               "sk-123",
+		SopsRef("secrets.enc.yaml", "port"):         "5432",
+		SopsRef("/team/secrets.enc.yaml", "db_url"): "postgres://team",
+	}
+	for ref, want := range tests {
+		got, err := s.Resolve(ref)
+		if err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+	if len(decrypted) != 2 {
+		t.Errorf("Expected each file to be decrypted once, got %v", decrypted)
+	}
+
+	for _, ref := range []string{
+		SopsRef("", "openai.missing"),
+		SopsRef("", "openai"),
+		SopsRef("", "list"),
+		SopsRef("other.yaml", "key"),
+		SopsRef("", ""),
+		"op://vault/item/field",
+	} {
+		if _, err := s.Resolve(ref); err == nil {
+			t.Errorf("Expected an error for %q", ref)
+		}
+	}
+}
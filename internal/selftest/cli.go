@@ -0,0 +1,33 @@
+// ABOUTME: The real claude CLI as the self-test's executor
+// ABOUTME: Keeps claude's output out of the report unless a command fails
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+// CLI runs the real claude CLI, keeping its output out of the report
+// unless a command fails
+type CLI struct {
+	profile.DefaultExecutor
+}
+
+// Run runs claude and includes its output in any error
+func (c *CLI) Run(ctx context.Context, args ...string) error {
+	output, err := c.RunWithOutput(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// VersionStep checks that the claude CLI runs at all
+func (c *CLI) VersionStep() Step {
+	return Step{"claude CLI runs", func(ctx context.Context) error {
+		return c.Run(ctx, "--version")
+	}}
+}
@@ -0,0 +1,332 @@
+// ABOUTME: End-to-end self-test: applies a small profile to a throwaway Claude config
+// ABOUTME: Checks snapshot, diff, apply, and the state afterwards, with the direct executor or the real claude CLI
+package selftest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// Step outcomes
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Names of what the test profile sets up
+const (
+	MarketplaceName = "selftest"
+	PluginName      = "hello@" + MarketplaceName
+	ServerName      = "selftest-echo"
+
+	seededPlugin = "stale@" + MarketplaceName
+	seededServer = "selftest-stale"
+)
+
+// Step is one stage of the self-test. Each builds on the ones before it.
+type Step struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Result records how a step finished
+type Result struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"-"`
+	Millis   int64         `json:"durationMs"`
+}
+
+// OK reports whether the step passed
+func (r Result) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Run runs steps in order. Once one fails, the rest are skipped.
+func Run(ctx context.Context, steps []Step) []Result {
+	results := make([]Result, len(steps))
+	failed := false
+	for i, s := range steps {
+		results[i] = Result{Name: s.Name, Status: StatusSkipped}
+		if failed {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			results[i].Status = StatusFailed
+			results[i].Error = err.Error()
+			failed = true
+			continue
+		}
+		start := time.Now()
+		err := s.Run(ctx)
+		results[i].Duration = time.Since(start)
+		results[i].Millis = results[i].Duration.Milliseconds()
+		results[i].Status = StatusOK
+		if err != nil {
+			results[i].Status = StatusFailed
+			results[i].Error = err.Error()
+			failed = true
+		}
+	}
+	return results
+}
+
+// Env is a throwaway home directory with its own Claude config. While it's
+// open, HOME and CLAUDE_CONFIG_DIR point into it, so nothing claudeup or
+// the claude CLI does touches the real configuration.
+type Env struct {
+	Home        string
+	ClaudeDir   string
+	ClaudeJSON  string
+	Marketplace string
+	ProfilesDir string
+
+	saved        map[string]*string
+	restorePaths func()
+}
+
+// NewEnv creates an Env in a new temporary directory and switches to it
+func NewEnv() (*Env, error) {
+	home, err := os.MkdirTemp("", "claudeup-selftest-")
+	if err != nil {
+		return nil, err
+	}
+	claudeDir := filepath.Join(home, ".claude")
+	e := &Env{
+		Home:        home,
+		ClaudeDir:   claudeDir,
+		ClaudeJSON:  filepath.Join(claudeDir, ".claude.json"),
+		Marketplace: filepath.Join(home, "marketplace"),
+		ProfilesDir: filepath.Join(home, ".claudeup", "profiles"),
+		saved:       map[string]*string{},
+	}
+	if err := e.writeFixtures(); err != nil {
+		os.RemoveAll(home)
+		return nil, err
+	}
+
+	for _, name := range []string{"HOME", "CLAUDE_CONFIG_DIR"} {
+		if v, ok := os.LookupEnv(name); ok {
+			e.saved[name] = &v
+		} else {
+			e.saved[name] = nil
+		}
+	}
+	os.Setenv("HOME", home)
+	os.Setenv("CLAUDE_CONFIG_DIR", claudeDir)
+	// The running command has pinned the real paths; point them here too
+	e.restorePaths = pathctx.Override(pathctx.ForHome(home))
+	profile.ResetSnapshotCache()
+	return e, nil
+}
+
+// Close restores the environment. Unless keep is set, the directory is
+// removed.
+func (e *Env) Close(keep bool) error {
+	for name, v := range e.saved {
+		if v == nil {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, *v)
+		}
+	}
+	e.restorePaths()
+	profile.ResetSnapshotCache()
+	if keep {
+		return nil
+	}
+	return os.RemoveAll(e.Home)
+}
+
+// writeFixtures creates an empty Claude config and a local marketplace with
+// one plugin
+func (e *Env) writeFixtures() error {
+	files := map[string]string{
+		filepath.Join(e.ClaudeDir, "plugins", "installed_plugins.json"):  `{"version": 2, "plugins": {}}`,
+		filepath.Join(e.ClaudeDir, "plugins", "known_marketplaces.json"): `{}`,
+		e.ClaudeJSON: `{"mcpServers": {}}`,
+		filepath.Join(e.Marketplace, ".claude-plugin", "marketplace.json"): `{
+  "name": "` + MarketplaceName + `",
+  "owner": {"name": "claudeup"},
+  "plugins": [{"name": "hello", "source": "./plugins/hello", "description": "claudeup self-test plugin"}]
+}`,
+		filepath.Join(e.Marketplace, "plugins", "hello", ".claude-plugin", "plugin.json"): `{"name": "hello", "version": "1.0.0", "description": "claudeup self-test plugin"}`,
+		filepath.Join(e.Marketplace, "plugins", "hello", "commands", "hello.md"):          "Say hello.\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	return os.MkdirAll(e.ProfilesDir, 0755)
+}
+
+// Seed adds an MCP server, and with plugin set a plugin, for the profile
+// to remove. The real claude CLI can't uninstall a plugin it never
+// installed, so it's only seeded for the direct executor.
+func (e *Env) Seed(plugin bool) error {
+	if plugin {
+		registry, err := state.LoadPlugins(e.ClaudeDir)
+		if err != nil {
+			return err
+		}
+		registry.SetPlugin(seededPlugin, state.PluginMetadata{Version: "0.1.0", InstallPath: e.Marketplace})
+		if err := state.SavePlugins(e.ClaudeDir, registry); err != nil {
+			return err
+		}
+	}
+	_, err := state.EditJSON(e.ClaudeJSON, func(obj map[string]json.RawMessage) error {
+		obj["mcpServers"] = json.RawMessage(`{"` + seededServer + `": {"type": "stdio", "command": "true", "args": []}}`)
+		return nil
+	})
+	return err
+}
+
+// Direct returns an executor that makes the claude CLI's changes to the
+// Env's config files without running it
+func (e *Env) Direct() *profile.DirectExecutor {
+	return &profile.DirectExecutor{ClaudeDir: e.ClaudeDir, ClaudeJSON: e.ClaudeJSON, ProjectDir: e.Home}
+}
+
+// Profile is the profile the self-test applies
+func (e *Env) Profile() *profile.Profile {
+	return &profile.Profile{
+		Name:         "selftest",
+		Description:  "claudeup self-test",
+		Marketplaces: []profile.Marketplace{{Source: profile.MarketplaceLocal, Path: e.Marketplace}},
+		Plugins:      []string{PluginName},
+		MCPServers:   []profile.MCPServer{{Name: ServerName, Command: "echo", Args: []string{"hello"}}},
+	}
+}
+
+// Steps checks each stage of applying the test profile with executor,
+// starting from what Seed added. withPlugin matches what was passed to Seed.
+func (e *Env) Steps(executor profile.CommandExecutor, withPlugin bool) []Step {
+	var diff *profile.Diff
+	return []Step{
+		{"Snapshot reads the current config", func(ctx context.Context) error {
+			want := []string{seededServer}
+			var wantPlugins []string
+			if withPlugin {
+				wantPlugins = []string{seededPlugin}
+			}
+			current := e.snapshot()
+			if got := current.Plugins; !sameStrings(got, wantPlugins) {
+				return fmt.Errorf("plugins = %v, want %v", got, wantPlugins)
+			}
+			if got := serverNames(current); !sameStrings(got, want) {
+				return fmt.Errorf("MCP servers = %v, want %v", got, want)
+			}
+			return nil
+		}},
+		{"Profile saves and loads", func(ctx context.Context) error {
+			p := e.Profile()
+			if err := profile.Save(e.ProfilesDir, p); err != nil {
+				return err
+			}
+			loaded, err := profile.Load(e.ProfilesDir, p.Name)
+			if err != nil {
+				return err
+			}
+			if !sameStrings(loaded.Plugins, p.Plugins) || len(loaded.MCPServers) != 1 || len(loaded.Marketplaces) != 1 {
+				return fmt.Errorf("loaded profile differs from the saved one")
+			}
+			return nil
+		}},
+		{"Diff plans the changes", func(ctx context.Context) error {
+			var err error
+			diff, err = profile.ComputeDiff(e.Profile(), e.ClaudeDir, e.ClaudeJSON)
+			if err != nil {
+				return err
+			}
+			var wantRemove []string
+			if withPlugin {
+				wantRemove = []string{seededPlugin}
+			}
+			switch {
+			case !sameStrings(diff.PluginsToRemove, wantRemove):
+				return fmt.Errorf("plugins to remove = %v, want %v", diff.PluginsToRemove, wantRemove)
+			case !sameStrings(diff.PluginsToInstall, []string{PluginName}):
+				return fmt.Errorf("plugins to install = %v, want [%s]", diff.PluginsToInstall, PluginName)
+			case !sameStrings(diff.MCPToRemove, []string{seededServer}):
+				return fmt.Errorf("MCP servers to remove = %v, want [%s]", diff.MCPToRemove, seededServer)
+			case len(diff.MCPToInstall) != 1 || diff.MCPToInstall[0].Name != ServerName:
+				return fmt.Errorf("expected MCP server %s to be added", ServerName)
+			case len(diff.MarketplacesToAdd) != 1:
+				return fmt.Errorf("expected the %s marketplace to be added", MarketplaceName)
+			}
+			return nil
+		}},
+		{"Apply makes the changes", func(ctx context.Context) error {
+			result, err := profile.ApplyPlanned(ctx, diff, e.ClaudeDir, nil, executor)
+			if err != nil {
+				return err
+			}
+			if failed := result.Failed(); len(failed) > 0 {
+				return fmt.Errorf("%d of %d changes failed: %v", len(failed), len(result.Steps), result.Errors[0])
+			}
+			if len(result.Errors) > 0 {
+				return result.Errors[0]
+			}
+			return nil
+		}},
+		{"Config matches the profile", func(ctx context.Context) error {
+			current := e.snapshot()
+			if !sameStrings(current.Plugins, []string{PluginName}) {
+				return fmt.Errorf("plugins = %v, want [%s]", current.Plugins, PluginName)
+			}
+			if got := serverNames(current); !sameStrings(got, []string{ServerName}) {
+				return fmt.Errorf("MCP servers = %v, want [%s]", got, ServerName)
+			}
+			again, err := profile.ComputeDiff(e.Profile(), e.ClaudeDir, e.ClaudeJSON)
+			if err != nil {
+				return err
+			}
+			if n := len(again.PluginsToRemove) + len(again.MCPToRemove) + len(again.MCPToInstall) + len(again.MarketplacesToAdd); n > 0 {
+				return fmt.Errorf("applying again would still make %d changes", n)
+			}
+			return nil
+		}},
+	}
+}
+
+func (e *Env) snapshot() *profile.Profile {
+	profile.ResetSnapshotCache()
+	p, _ := profile.Snapshot("current", e.ClaudeDir, e.ClaudeJSON)
+	return p
+}
+
+func serverNames(p *profile.Profile) []string {
+	names := make([]string, len(p.MCPServers))
+	for i, s := range p.MCPServers {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// sameStrings compares a and b ignoring order
+func sameStrings(a, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
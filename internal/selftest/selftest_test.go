@@ -0,0 +1,68 @@
+// ABOUTME: Tests for the self-test runner and its stand-in claude CLI
+// ABOUTME: Runs the full scenario against a throwaway config and checks the environment is restored
+package selftest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestStepsPassWithDirectExecutor(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+
+	env, err := NewEnv()
+	if err != nil {
+		t.Fatalf("NewEnv failed: %v", err)
+	}
+	if os.Getenv("CLAUDE_CONFIG_DIR") != env.ClaudeDir {
+		t.Errorf("Expected CLAUDE_CONFIG_DIR to point at the test config")
+	}
+	if err := env.Seed(true); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+
+	for _, r := range Run(context.Background(), env.Steps(env.Direct(), true)) {
+		if !r.OK() {
+			t.Errorf("%s: %s %s", r.Name, r.Status, r.Error)
+		}
+	}
+
+	if err := env.Close(false); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if os.Getenv("HOME") != home {
+		t.Errorf("Expected HOME restored to %s, got %s", home, os.Getenv("HOME"))
+	}
+	if _, ok := os.LookupEnv("CLAUDE_CONFIG_DIR"); !ok {
+		t.Error("Expected CLAUDE_CONFIG_DIR restored")
+	}
+	if _, err := os.Stat(env.Home); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed", env.Home)
+	}
+}
+
+func TestRunSkipsAfterFailure(t *testing.T) {
+	ran := 0
+	step := func(err error) Step {
+		return Step{Name: "step", Run: func(context.Context) error {
+			ran++
+			return err
+		}}
+	}
+
+	results := Run(context.Background(), []Step{step(nil), step(errors.New("broken")), step(nil)})
+
+	want := []string{StatusOK, StatusFailed, StatusSkipped}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("results[%d].Status = %s, want %s", i, r.Status, want[i])
+		}
+	}
+	if ran != 2 || results[1].Error != "broken" {
+		t.Errorf("ran %d steps, error %q", ran, results[1].Error)
+	}
+}
@@ -0,0 +1,367 @@
+// ABOUTME: Local HTTP API for managing Claude configuration through claudeup
+// ABOUTME: Serves profiles, diffs, apply, status, and history behind a bearer token
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/history"
+	"github.com/claudeup/claudeup/internal/notify"
+	"github.com/claudeup/claudeup/pkg/claudeup"
+)
+
+// Server handles API requests against a single Claude installation
+type Server struct {
+	client      *claudeup.Client
+	token       string
+	historyPath string
+
+	// Notifier, if set, is told about failed applies
+	Notifier notify.Notifier
+
+	// BeforeApply, if set, runs once the diff is computed, before any
+	// change, and an error refuses the apply. The context it returns is
+	// used for the apply and AfterApply.
+	BeforeApply func(ctx context.Context, p *claudeup.Profile, diff *claudeup.Diff) (context.Context, error)
+
+	// AfterApply, if set, records an apply that ran, err being why it
+	// failed. Its error is returned to the client. Without it, a successful
+	// apply only makes the profile the active one.
+	AfterApply func(ctx context.Context, p *claudeup.Profile, result *claudeup.ApplyResult, err error) error
+
+	// applyMu serializes apply requests so two frontends can't interleave
+	applyMu sync.Mutex
+}
+
+// New creates a server that requires token on every request
+func New(client *claudeup.Client, token, historyPath string) *Server {
+	return &Server{client: client, token: token, historyPath: historyPath}
+}
+
+// Handler returns the HTTP handler for the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/profiles", s.handleListProfiles)
+	// Namespaced names like team/backend span segments, so the action is
+	// read from the end of the path
+	mux.HandleFunc("GET /v1/profiles/{path...}", s.handleProfileGet)
+	mux.HandleFunc("POST /v1/profiles/{path...}", s.handleProfilePost)
+	mux.HandleFunc("GET /v1/status", s.handleStatus)
+	mux.HandleFunc("GET /v1/history", s.handleHistory)
+	return s.requireToken(mux)
+}
+
+// GenerateToken returns a random token suitable for bearer auth
+func GenerateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// CheckLoopback rejects listen addresses that aren't bound to localhost
+func CheckLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("refusing to listen on %q: only loopback addresses are allowed", addr)
+	}
+	return nil
+}
+
+func (s *Server) requireToken(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + s.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, expected) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// profilePath splits the path after /v1/profiles/ into a profile name and
+// the action after it: "diff", "apply", or "" for the profile itself. A
+// name's slashes may be escaped as %2F, which also addresses a namespaced
+// profile whose last segment is an action's name.
+func profilePath(r *http.Request) (name, action string, err error) {
+	rest := strings.TrimPrefix(r.URL.EscapedPath(), "/v1/profiles/")
+	segments := strings.Split(rest, "/")
+	if n := len(segments); n > 1 && (segments[n-1] == "diff" || segments[n-1] == "apply") {
+		action = segments[n-1]
+		segments = segments[:n-1]
+	}
+	name, err = url.PathUnescape(strings.Join(segments, "/"))
+	if err != nil || name == "" {
+		return "", "", fmt.Errorf("invalid profile path %q", rest)
+	}
+	return name, action, nil
+}
+
+func (s *Server) handleProfileGet(w http.ResponseWriter, r *http.Request) {
+	name, action, err := profilePath(r)
+	switch {
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+	case action == "":
+		s.handleGetProfile(w, r, name)
+	case action == "diff":
+		s.handleDiff(w, r, name)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s needs POST", action))
+	}
+}
+
+func (s *Server) handleProfilePost(w http.ResponseWriter, r *http.Request) {
+	name, action, err := profilePath(r)
+	switch {
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+	case action == "apply":
+		s.handleApply(w, r, name)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.New("only apply accepts POST"))
+	}
+}
+
+type profileSummary struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+func (s *Server) handleListProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := s.client.ListProfiles(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	summaries := make([]profileSummary, 0, len(profiles))
+	for _, p := range profiles {
+		summaries = append(summaries, profileSummary{Name: p.Name, Description: p.Description})
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func (s *Server) handleGetProfile(w http.ResponseWriter, r *http.Request, name string) {
+	p, err := s.client.LoadProfile(r.Context(), name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+type diffResponse struct {
+	Changes int            `json:"changes"`
+	Diff    *claudeup.Diff `json:"diff"`
+}
+
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request, name string) {
+	p, err := s.client.LoadProfile(r.Context(), name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	diff, err := s.client.Diff(r.Context(), p)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, diffResponse{Changes: diff.Count(), Diff: diff})
+}
+
+type applyResponse struct {
+	Profile               string         `json:"profile"`
+	PluginsRemoved        []string       `json:"pluginsRemoved"`
+	PluginsInstalled      []string       `json:"pluginsInstalled"`
+	PluginsAlreadyRemoved []string       `json:"pluginsAlreadyRemoved"`
+	PluginsAlreadyPresent []string       `json:"pluginsAlreadyPresent"`
+	MCPServersRemoved     []string       `json:"mcpServersRemoved"`
+	MCPServersInstalled   []string       `json:"mcpServersInstalled"`
+	MarketplacesAdded     []string       `json:"marketplacesAdded"`
+	Steps                 []stepResponse `json:"steps"`
+	Elapsed               int64          `json:"elapsedMs"`
+	Errors                []string       `json:"errors"`
+}
+
+// stepResponse is one change an apply attempted and how long it took
+type stepResponse struct {
+	Action    string `json:"action"`
+	Subsystem string `json:"subsystem"`
+	Name      string `json:"name"`
+	Result    string `json:"result"` // done, already, or failed
+	Duration  int64  `json:"durationMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (s *Server) handleApply(w http.ResponseWriter, r *http.Request, name string) {
+	if s.client.Options().ReadOnly {
+		writeError(w, http.StatusForbidden, claudeup.ErrReadOnly)
+		return
+	}
+	p, err := s.client.LoadProfile(r.Context(), name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	diff, err := s.client.Diff(r.Context(), p)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	ctx := r.Context()
+	if s.BeforeApply != nil {
+		if ctx, err = s.BeforeApply(ctx, p, diff); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+	}
+
+	result, err := s.client.Apply(ctx, p)
+	entry := history.Entry{Action: "apply", Profile: name, Source: "serve", Changes: diff.Count()}
+	if err != nil {
+		entry.Error = err.Error()
+		history.Append(s.historyPath, entry)
+		s.notifyFailure(entry)
+		if s.AfterApply != nil {
+			s.AfterApply(ctx, p, result, err)
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := applyResponse{
+		Profile:               name,
+		PluginsRemoved:        result.PluginsRemoved,
+		PluginsInstalled:      result.PluginsInstalled,
+		PluginsAlreadyRemoved: result.PluginsAlreadyRemoved,
+		PluginsAlreadyPresent: result.PluginsAlreadyPresent,
+		MCPServersRemoved:     result.MCPServersRemoved,
+		MCPServersInstalled:   result.MCPServersInstalled,
+		MarketplacesAdded:     result.MarketplacesAdded,
+		Steps:                 []stepResponse{},
+		Elapsed:               result.Elapsed.Milliseconds(),
+	}
+	for _, step := range result.Steps {
+		sr := stepResponse{Action: step.Item.Action, Subsystem: string(step.Item.Subsystem), Name: step.Item.Name, Result: step.Result, Duration: step.Duration.Milliseconds()}
+		if step.Err != nil {
+			sr.Error = step.Err.Error()
+		}
+		resp.Steps = append(resp.Steps, sr)
+	}
+	entry.Elapsed = resp.Elapsed
+	for _, e := range result.Errors {
+		resp.Errors = append(resp.Errors, e.Error())
+	}
+	if len(resp.Errors) > 0 {
+		entry.Error = fmt.Sprintf("%d errors", len(resp.Errors))
+	}
+	for _, step := range result.Failed() {
+		entry.Failed = append(entry.Failed, history.Failure{Action: step.Item.Action, Subsystem: string(step.Item.Subsystem), Name: step.Item.Name, Error: step.Err.Error(), Duration: step.Duration.Milliseconds()})
+	}
+	history.Append(s.historyPath, entry)
+	s.notifyFailure(entry)
+
+	if err := s.recordApply(ctx, p, result); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("applied profile %s, but %w", p.Name, err))
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// recordApply records a successful apply with AfterApply, or else makes
+// p the active profile, as the CLI does
+func (s *Server) recordApply(ctx context.Context, p *claudeup.Profile, result *claudeup.ApplyResult) error {
+	if s.AfterApply != nil {
+		return s.AfterApply(ctx, p, result, nil)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.Preferences.ActiveProfile = p.Name
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save the active profile: %w", err)
+	}
+	return nil
+}
+
+type statusResponse struct {
+	ActiveProfile string                 `json:"activeProfile,omitempty"`
+	Plugins       []string               `json:"plugins"`
+	MCPServers    []claudeup.MCPServer   `json:"mcpServers"`
+	Marketplaces  []claudeup.Marketplace `json:"marketplaces"`
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	current, err := s.client.Snapshot(r.Context(), "current")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := statusResponse{
+		Plugins:      current.Plugins,
+		MCPServers:   current.MCPServers,
+		Marketplaces: current.Marketplaces,
+	}
+	if cfg, err := config.Load(); err == nil {
+		resp.ActiveProfile = cfg.Preferences.ActiveProfile
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	entries, err := history.Load(s.historyPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if entries == nil {
+		entries = []history.Entry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func (s *Server) notifyFailure(entry history.Entry) {
+	if s.Notifier == nil || entry.Error == "" {
+		return
+	}
+	s.Notifier.Notify(notify.ApplyFailed(entry.Profile, entry.Source, entry.Error))
+}
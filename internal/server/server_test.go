@@ -0,0 +1,344 @@
+// ABOUTME: Tests for the local HTTP API
+// ABOUTME: Covers token auth, loopback checks, and the profile endpoints
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/history"
+	"github.com/claudeup/claudeup/internal/notify"
+	"github.com/claudeup/claudeup/pkg/claudeup"
+)
+
+type fakeExecutor struct {
+	calls [][]string
+	fail  bool
+}
+
+func (e *fakeExecutor) Run(ctx context.Context, args ...string) error {
+	e.calls = append(e.calls, args)
+	return nil
+}
+
+func (e *fakeExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	e.calls = append(e.calls, args)
+	if e.fail {
+		return "network unreachable", errors.New("exit status 1")
+	}
+	return "", nil
+}
+
+type recordingNotifier struct {
+	events []notify.Event
+}
+
+func (n *recordingNotifier) Notify(e notify.Event) error {
+	n.events = append(n.events, e)
+	return nil
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *fakeExecutor, string) {
+	t.Helper()
+	api, executor, historyPath := newTestAPI(t)
+	ts := httptest.NewServer(api.Handler())
+	t.Cleanup(ts.Close)
+	return ts, executor, historyPath
+}
+
+func newTestAPI(t *testing.T) (*Server, *fakeExecutor, string) {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	if err := os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(claudeDir, "plugins", "installed_plugins.json"), `{"version": 2, "plugins": {}}`)
+	writeFile(t, filepath.Join(claudeDir, "plugins", "known_marketplaces.json"), `{}`)
+	writeFile(t, filepath.Join(home, ".claude.json"), `{"mcpServers": {}}`)
+
+	profilesDir := filepath.Join(home, ".claudeup", "profiles")
+	if err := os.MkdirAll(profilesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(profilesDir, "dev.json"), `{"name": "dev", "plugins": ["tool@market"]}`)
+
+	executor := &fakeExecutor{}
+	client, err := claudeup.New(claudeup.Options{
+		ClaudeDir:      claudeDir,
+		ClaudeJSONPath: filepath.Join(home, ".claude.json"),
+		ProfilesDir:    profilesDir,
+		Executor:       executor,
+		Secrets:        claudeup.NewSecretChain(claudeup.EnvResolver()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	historyPath := filepath.Join(home, ".claudeup", "history.jsonl")
+	return New(client, "secret", historyPath), executor, historyPath
+}
+
+func TestRequiresToken(t *testing.T) {
+	ts, _, _ := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/v1/profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", resp.StatusCode)
+	}
+}
+
+func TestListDiffAndApply(t *testing.T) {
+	ts, executor, historyPath := newTestServer(t)
+
+	var profiles []profileSummary
+	doRequest(t, "GET", ts.URL+"/v1/profiles", &profiles)
+	if len(profiles) != 1 || profiles[0].Name != "dev" {
+		t.Errorf("Expected dev profile, got %+v", profiles)
+	}
+
+	var diff diffResponse
+	doRequest(t, "GET", ts.URL+"/v1/profiles/dev/diff", &diff)
+	if diff.Changes != 1 {
+		t.Errorf("Expected 1 change, got %d", diff.Changes)
+	}
+	if len(executor.calls) != 0 {
+		t.Errorf("Diff must not run commands, got %v", executor.calls)
+	}
+
+	var applied applyResponse
+	doRequest(t, "POST", ts.URL+"/v1/profiles/dev/apply", &applied)
+	if len(applied.PluginsInstalled) != 1 {
+		t.Errorf("Expected 1 plugin installed, got %+v", applied)
+	}
+	if len(applied.Steps) != 1 || applied.Steps[0].Name != "tool@market" || applied.Steps[0].Result != "done" {
+		t.Errorf("Expected the install step, got %+v", applied.Steps)
+	}
+
+	entries, _ := history.Load(historyPath)
+	if len(entries) != 1 || entries[0].Profile != "dev" || entries[0].Source != "serve" {
+		t.Errorf("Expected apply to be recorded in history, got %+v", entries)
+	}
+
+	var status statusResponse
+	doRequest(t, "GET", ts.URL+"/v1/status", &status)
+	if status.ActiveProfile != "dev" {
+		t.Errorf("Expected active profile dev, got %q", status.ActiveProfile)
+	}
+}
+
+func TestApplyHooks(t *testing.T) {
+	api, executor, _ := newTestAPI(t)
+	api.BeforeApply = func(ctx context.Context, p *claudeup.Profile, diff *claudeup.Diff) (context.Context, error) {
+		return ctx, errors.New("Claude Code is running")
+	}
+	ts := httptest.NewServer(api.Handler())
+	defer ts.Close()
+
+	post := func() (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest("POST", ts.URL+"/v1/profiles/dev/apply", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	if code, body := post(); code != http.StatusConflict || !strings.Contains(body, "Claude Code is running") {
+		t.Errorf("Expected the apply refused with 409, got %d: %s", code, body)
+	}
+	if len(executor.calls) != 0 {
+		t.Errorf("Expected a refused apply to change nothing, got %v", executor.calls)
+	}
+
+	api.BeforeApply = nil
+	var recorded string
+	api.AfterApply = func(ctx context.Context, p *claudeup.Profile, result *claudeup.ApplyResult, err error) error {
+		recorded = p.Name
+		return errors.New("could not save the active profile: disk full")
+	}
+	if code, body := post(); code != http.StatusInternalServerError || !strings.Contains(body, "disk full") {
+		t.Errorf("Expected the record error returned, got %d: %s", code, body)
+	}
+	if recorded != "dev" {
+		t.Errorf("Expected AfterApply called for dev, got %q", recorded)
+	}
+}
+
+func TestFailedApplyNotifies(t *testing.T) {
+	api, executor, _ := newTestAPI(t)
+	executor.fail = true
+	notifier := &recordingNotifier{}
+	api.Notifier = notifier
+	ts := httptest.NewServer(api.Handler())
+	defer ts.Close()
+
+	var applied applyResponse
+	doRequest(t, "POST", ts.URL+"/v1/profiles/dev/apply", &applied)
+	if len(applied.Errors) != 1 {
+		t.Fatalf("Expected 1 apply error, got %+v", applied)
+	}
+	if len(notifier.events) != 1 || notifier.events[0].Kind != "apply-failed" {
+		t.Errorf("Expected apply-failed notification, got %+v", notifier.events)
+	}
+}
+
+func TestReadOnlyRefusesApply(t *testing.T) {
+	api, executor, _ := newTestAPI(t)
+	opts := api.client.Options()
+	opts.ReadOnly = true
+	client, err := claudeup.New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.client = client
+	ts := httptest.NewServer(api.Handler())
+	defer ts.Close()
+
+	req, _ := http.NewRequest("POST", ts.URL+"/v1/profiles/dev/apply", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || len(executor.calls) != 0 {
+		t.Errorf("Expected 403 and no commands, got %d and %v", resp.StatusCode, executor.calls)
+	}
+}
+
+func TestUnknownProfileIsNotFound(t *testing.T) {
+	ts, _, _ := newTestServer(t)
+
+	req, _ := http.NewRequest("GET", ts.URL+"/v1/profiles/nope/diff", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestNamespacedProfiles(t *testing.T) {
+	api, _, historyPath := newTestAPI(t)
+	ts := httptest.NewServer(api.Handler())
+	defer ts.Close()
+	profilesDir := api.client.Options().ProfilesDir
+	if err := os.MkdirAll(filepath.Join(profilesDir, "team"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(profilesDir, "team", "backend.json"), `{"name": "backend", "plugins": ["api@market"]}`)
+	writeFile(t, filepath.Join(profilesDir, "team", "diff.json"), `{"name": "diff", "description": "named like an action"}`)
+
+	for _, path := range []string{"team/backend", "team%2Fbackend"} {
+		var p claudeup.Profile
+		doRequest(t, "GET", ts.URL+"/v1/profiles/"+path, &p)
+		if p.Name != "team/backend" {
+			t.Errorf("GET %s: expected team/backend, got %q", path, p.Name)
+		}
+	}
+
+	var p claudeup.Profile
+	doRequest(t, "GET", ts.URL+"/v1/profiles/team%2Fdiff", &p)
+	if p.Description != "named like an action" {
+		t.Errorf("Expected the escaped slash to address team/diff, got %+v", p)
+	}
+
+	var diff diffResponse
+	doRequest(t, "GET", ts.URL+"/v1/profiles/team/backend/diff", &diff)
+	if diff.Changes != 1 {
+		t.Errorf("Expected 1 change, got %d", diff.Changes)
+	}
+
+	var applied applyResponse
+	doRequest(t, "POST", ts.URL+"/v1/profiles/team/backend/apply", &applied)
+	if applied.Profile != "team/backend" || len(applied.PluginsInstalled) != 1 {
+		t.Errorf("Expected team/backend applied, got %+v", applied)
+	}
+	if entries, _ := history.Load(historyPath); len(entries) != 1 || entries[0].Profile != "team/backend" {
+		t.Errorf("Expected the apply recorded under team/backend, got %+v", entries)
+	}
+
+	req, _ := http.NewRequest("POST", ts.URL+"/v1/profiles/team/backend", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for a POST without apply, got %d", resp.StatusCode)
+	}
+}
+
+func TestCheckLoopback(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{"127.0.0.1:7420", false},
+		{"localhost:7420", false},
+		{"[::1]:7420", false},
+		{"0.0.0.0:7420", true},
+		{":7420", true},
+		{"192.168.1.10:7420", true},
+	}
+
+	for _, tc := range tests {
+		err := CheckLoopback(tc.addr)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("CheckLoopback(%q) error = %v, wantErr %v", tc.addr, err, tc.wantErr)
+		}
+	}
+}
+
+func doRequest(t *testing.T, method, url string, out interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("%s %s returned %d", method, strings.TrimPrefix(url, "http://"), resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
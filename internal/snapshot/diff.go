@@ -0,0 +1,162 @@
+// ABOUTME: Computes changes between two full-state snapshots
+// ABOUTME: Reports added, removed, and changed plugins, MCP servers, marketplaces, and settings
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// Change kinds
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change is a single difference between two states
+type Change struct {
+	Section string `json:"section"` // "plugins", "marketplaces", "mcpServers", "settings"
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// DiffSchemaVersion identifies the DiffReport layout. It changes only when a
+// field is removed or changes meaning.
+const DiffSchemaVersion = 1
+
+// DiffReport is the machine-readable form of a diff between two snapshots
+type DiffReport struct {
+	SchemaVersion int      `json:"schemaVersion"`
+	From          string   `json:"from"`
+	To            string   `json:"to"`
+	Changes       []Change `json:"changes"`
+}
+
+// NewDiffReport wraps changes between the snapshots referenced by from and to
+func NewDiffReport(from, to string, changes []Change) *DiffReport {
+	if changes == nil {
+		changes = []Change{}
+	}
+	return &DiffReport{SchemaVersion: DiffSchemaVersion, From: from, To: to, Changes: changes}
+}
+
+// Diff returns the changes needed to go from a to b, ordered by section then name
+func Diff(a, b *state.FullState) []Change {
+	var changes []Change
+
+	changes = append(changes, diffPlugins(a.Plugins, b.Plugins)...)
+	changes = append(changes, diffMaps("marketplaces", toRaw(a.Marketplaces), toRaw(b.Marketplaces))...)
+	changes = append(changes, diffMaps("mcpServers", toRaw(a.MCPServers), toRaw(b.MCPServers))...)
+	changes = append(changes, diffMaps("settings", a.Settings, b.Settings)...)
+
+	return changes
+}
+
+func diffPlugins(a, b map[string][]state.PluginMetadata) []Change {
+	var changes []Change
+	for _, name := range unionKeys(a, b) {
+		before, inA := a[name]
+		after, inB := b[name]
+		switch {
+		case !inA:
+			changes = append(changes, Change{Section: "plugins", Name: name, Kind: Added, Detail: pluginVersion(after)})
+		case !inB:
+			changes = append(changes, Change{Section: "plugins", Name: name, Kind: Removed, Detail: pluginVersion(before)})
+		case !jsonEqual(before, after):
+			detail := ""
+			if vb, va := pluginVersion(before), pluginVersion(after); vb != va {
+				detail = fmt.Sprintf("%s → %s", vb, va)
+			}
+			changes = append(changes, Change{Section: "plugins", Name: name, Kind: Changed, Detail: detail})
+		}
+	}
+	return changes
+}
+
+func pluginVersion(instances []state.PluginMetadata) string {
+	for _, inst := range instances {
+		if inst.Scope == "user" || inst.Scope == "" {
+			return inst.Version
+		}
+	}
+	if len(instances) > 0 {
+		return instances[0].Version
+	}
+	return ""
+}
+
+func diffMaps(section string, a, b map[string]json.RawMessage) []Change {
+	var changes []Change
+	for _, name := range unionKeys(a, b) {
+		before, inA := a[name]
+		after, inB := b[name]
+		switch {
+		case !inA:
+			changes = append(changes, Change{Section: section, Name: name, Kind: Added})
+		case !inB:
+			changes = append(changes, Change{Section: section, Name: name, Kind: Removed})
+		case !rawEqual(before, after):
+			changes = append(changes, Change{Section: section, Name: name, Kind: Changed, Detail: fmt.Sprintf("%s → %s", compact(before), compact(after))})
+		}
+	}
+	return changes
+}
+
+// toRaw converts a typed map into raw JSON values so all sections compare the same way
+func toRaw[V any](m map[string]V) map[string]json.RawMessage {
+	out := make(map[string]json.RawMessage, len(m))
+	for k, v := range m {
+		data, _ := json.Marshal(v)
+		out[k] = data
+	}
+	return out
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func jsonEqual(a, b interface{}) bool {
+	da, _ := json.Marshal(a)
+	db, _ := json.Marshal(b)
+	return bytes.Equal(da, db)
+}
+
+// rawEqual compares JSON values semantically, ignoring formatting and key order
+func rawEqual(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	return jsonEqual(va, vb)
+}
+
+func compact(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	s := buf.String()
+	if len(s) > 60 {
+		s = s[:57] + "..."
+	}
+	return s
+}
@@ -0,0 +1,179 @@
+// ABOUTME: Timestamped full-state snapshots stored under ~/.claudeup/snapshots
+// ABOUTME: Independent of profiles; used to see what changed between two points in time
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+// Snapshot is a stored copy of the full Claude state
+type Snapshot struct {
+	ID    string           `json:"id"`
+	Label string           `json:"label,omitempty"`
+	Taken time.Time        `json:"taken"`
+	State *state.FullState `json:"state"`
+}
+
+// DefaultDir returns the snapshot storage directory
+func DefaultDir() string {
+	return pathctx.Default().Claudeup("snapshots")
+}
+
+const idTimeFormat = "20060102-150405"
+
+var labelUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Take captures the current state and writes it to dir
+func Take(dir, label, claudeDir, claudeJSONPath string) (*Snapshot, error) {
+	st, err := state.Capture(claudeDir, claudeJSONPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Claude state: %w", err)
+	}
+
+	now := time.Now().UTC()
+	id := now.Format(idTimeFormat)
+	if label != "" {
+		id += "-" + strings.Trim(labelUnsafe.ReplaceAllString(label, "-"), "-")
+	}
+
+	snap := &Snapshot{ID: id, Label: label, Taken: now, State: st}
+	if err := Save(dir, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// Save writes a snapshot to dir, refusing to overwrite an existing one
+func Save(dir string, snap *Snapshot) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, snap.ID+".json"), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("snapshot %q already exists", snap.ID)
+		}
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(data)
+	return err
+}
+
+// Remove deletes the snapshot with the given ID from dir
+func Remove(dir, id string) error {
+	if id == "" || id != filepath.Base(id) {
+		return fmt.Errorf("invalid snapshot ID %q", id)
+	}
+	return os.Remove(filepath.Join(dir, id+".json"))
+}
+
+// List returns all snapshots in dir, oldest first
+func List(dir string) ([]*Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snaps []*Snapshot
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		snap, err := loadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].Taken.Before(snaps[j].Taken)
+	})
+	return snaps, nil
+}
+
+// Find resolves a reference to a stored snapshot. A reference is an ID,
+// a label (the most recent snapshot with that label wins), or a unique ID prefix.
+func Find(dir, ref string) (*Snapshot, error) {
+	snaps, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range snaps {
+		if s.ID == ref {
+			return s, nil
+		}
+	}
+
+	for i := len(snaps) - 1; i >= 0; i-- {
+		if snaps[i].Label == ref {
+			return snaps[i], nil
+		}
+	}
+
+	var matches []*Snapshot
+	for _, s := range snaps {
+		if strings.HasPrefix(s.ID, ref) {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return nil, fmt.Errorf("snapshot %q not found", ref)
+	default:
+		return nil, fmt.Errorf("snapshot %q is ambiguous (%d matches)", ref, len(matches))
+	}
+}
+
+// Open loads a snapshot from a file path, such as one copied from another
+// machine, or else resolves ref in dir like Find
+func Open(dir, ref string) (*Snapshot, error) {
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		snap, err := loadFile(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %s: %w", ref, err)
+		}
+		return snap, nil
+	}
+	return Find(dir, ref)
+}
+
+func loadFile(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	if snap.State == nil {
+		return nil, fmt.Errorf("%s: missing state", path)
+	}
+	return &snap, nil
+}
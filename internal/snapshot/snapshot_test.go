@@ -0,0 +1,142 @@
+// ABOUTME: Tests for full-state snapshots
+// ABOUTME: Covers take/find round trips and diffing between two states
+package snapshot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/state"
+)
+
+func setupClaude(t *testing.T) (string, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	if err := os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(claudeDir, "plugins", "installed_plugins.json"),
+		`{"version": 2, "plugins": {"tool@market": [{"scope": "user", "version": "1.0.0"}]}}`)
+	writeFile(t, filepath.Join(claudeDir, "settings.json"), `{"model": "opus"}`)
+	claudeJSONPath := filepath.Join(tmpDir, ".claude.json")
+	writeFile(t, claudeJSONPath, `{"mcpServers": {"ctx": {"command": "npx"}}}`)
+	return claudeDir, claudeJSONPath
+}
+
+func TestTakeAndFind(t *testing.T) {
+	claudeDir, claudeJSONPath := setupClaude(t)
+	dir := filepath.Join(t.TempDir(), "snapshots")
+
+	snap, err := Take(dir, "before install", claudeDir, claudeJSONPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Label != "before install" {
+		t.Errorf("Expected label to be kept, got %q", snap.Label)
+	}
+
+	byLabel, err := Find(dir, "before install")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byLabel.ID != snap.ID {
+		t.Errorf("Expected %s, got %s", snap.ID, byLabel.ID)
+	}
+	if len(byLabel.State.Plugins) != 1 || byLabel.State.MCPServers["ctx"].Command != "npx" {
+		t.Errorf("Expected stored state to round trip, got %+v", byLabel.State)
+	}
+
+	if _, err := Find(dir, "nope"); err == nil {
+		t.Error("Expected error for unknown snapshot")
+	}
+
+	if err := Remove(dir, "../"+snap.ID); err == nil {
+		t.Error("Expected an ID with a path to be refused")
+	}
+	if err := Remove(dir, snap.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Find(dir, snap.ID); err == nil {
+		t.Error("Expected the removed snapshot to be gone")
+	}
+}
+
+func TestOpenFileOrRef(t *testing.T) {
+	claudeDir, claudeJSONPath := setupClaude(t)
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	snap, err := Take(dir, "laptop", claudeDir, claudeJSONPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A snapshot file copied to a machine with no snapshots of its own
+	copied := filepath.Join(t.TempDir(), "laptop.json")
+	data, _ := os.ReadFile(filepath.Join(dir, snap.ID+".json"))
+	writeFile(t, copied, string(data))
+	fromFile, err := Open(filepath.Join(t.TempDir(), "none"), copied)
+	if err != nil || fromFile.ID != snap.ID {
+		t.Fatalf("Expected %s from the file, got %+v, %v", snap.ID, fromFile, err)
+	}
+
+	if byRef, err := Open(dir, "laptop"); err != nil || byRef.ID != snap.ID {
+		t.Errorf("Expected %s by label, got %+v, %v", snap.ID, byRef, err)
+	}
+
+	writeFile(t, copied, `{"id": "x"}`)
+	if _, err := Open(dir, copied); err == nil {
+		t.Error("Expected a file without state to be refused")
+	}
+}
+
+func TestDiffReportsChanges(t *testing.T) {
+	a := &state.FullState{
+		Plugins: map[string][]state.PluginMetadata{
+			"kept@m":    {{Scope: "user", Version: "1.0.0"}},
+			"removed@m": {{Scope: "user", Version: "1.0.0"}},
+			"bumped@m":  {{Scope: "user", Version: "1.0.0"}},
+		},
+		MCPServers: map[string]state.MCPServer{"ctx": {Command: "npx"}},
+		Settings:   map[string]json.RawMessage{"model": json.RawMessage(`"opus"`), "hooks": json.RawMessage(`{"a": 1}`)},
+	}
+	b := &state.FullState{
+		Plugins: map[string][]state.PluginMetadata{
+			"kept@m":   {{Scope: "user", Version: "1.0.0"}},
+			"bumped@m": {{Scope: "user", Version: "1.1.0"}},
+			"added@m":  {{Scope: "user", Version: "2.0.0"}},
+		},
+		MCPServers: map[string]state.MCPServer{"ctx": {Command: "uvx"}},
+		Settings:   map[string]json.RawMessage{"model": json.RawMessage(`"opus"`), "hooks": json.RawMessage(`{ "a" : 1 }`)},
+	}
+
+	changes := Diff(a, b)
+
+	want := []Change{
+		{Section: "plugins", Name: "added@m", Kind: Added, Detail: "2.0.0"},
+		{Section: "plugins", Name: "bumped@m", Kind: Changed, Detail: "1.0.0 → 1.1.0"},
+		{Section: "plugins", Name: "removed@m", Kind: Removed, Detail: "1.0.0"},
+		{Section: "mcpServers", Name: "ctx", Kind: Changed},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for i, w := range want {
+		got := changes[i]
+		if got.Section != w.Section || got.Name != w.Name || got.Kind != w.Kind {
+			t.Errorf("Change %d: expected %+v, got %+v", i, w, got)
+		}
+		if w.Detail != "" && got.Detail != w.Detail {
+			t.Errorf("Change %d: expected detail %q, got %q", i, w.Detail, got.Detail)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
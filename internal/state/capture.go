@@ -0,0 +1,76 @@
+// ABOUTME: Captures the complete Claude Code state in one structure
+// ABOUTME: Plugins (all scopes), marketplaces, MCP servers, and settings.json
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// FullState is everything claudeup tracks about a Claude installation
+type FullState struct {
+	Plugins      map[string][]PluginMetadata `json:"plugins"`
+	Marketplaces MarketplaceRegistry         `json:"marketplaces"`
+	MCPServers   map[string]MCPServer        `json:"mcpServers"`
+	Settings     map[string]json.RawMessage  `json:"settings"`
+}
+
+// Capture reads the full state from disk. Missing files yield empty
+// sections; malformed files are reported as errors.
+func Capture(claudeDir, claudeJSONPath string) (*FullState, error) {
+	st := &FullState{
+		Plugins:      make(map[string][]PluginMetadata),
+		Marketplaces: make(MarketplaceRegistry),
+		MCPServers:   make(map[string]MCPServer),
+		Settings:     make(map[string]json.RawMessage),
+	}
+
+	plugins, err := LoadPlugins(claudeDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if plugins != nil {
+		st.Plugins = plugins.Plugins
+	}
+
+	marketplaces, err := LoadMarketplaces(claudeDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if marketplaces != nil {
+		st.Marketplaces = marketplaces
+	}
+
+	servers, err := LoadMCPServers(claudeJSONPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if servers != nil {
+		st.MCPServers = servers
+	}
+
+	settings, err := LoadSettings(claudeDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if settings != nil {
+		st.Settings = settings
+	}
+
+	return st, nil
+}
+
+// LoadSettings reads settings.json as raw top-level keys
+func LoadSettings(claudeDir string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(filepath.Join(claudeDir, "settings.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
@@ -0,0 +1,80 @@
+// ABOUTME: Edits JSON files that Claude Code may rewrite at the same time
+// ABOUTME: Re-reads just before writing and reapplies the edit if the file changed underneath
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// maxEditAttempts bounds how often an edit is retried against a file that
+// keeps changing
+const maxEditAttempts = 5
+
+// EditJSON applies edit to the JSON object in path and writes the result.
+// A running Claude Code rewrites .claude.json often, so the file is read
+// again just before writing; if it changed, edit is reapplied to the new
+// contents instead of overwriting them. The file is replaced atomically and
+// keeps its mode. Returns the contents the edit was applied to.
+func EditJSON(path string, edit func(map[string]json.RawMessage) error) ([]byte, error) {
+	// Replace the file a symlink points to, not the symlink
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < maxEditAttempts; attempt++ {
+		original, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(original, &obj); err != nil {
+			return nil, err
+		}
+		if err := edit(obj); err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		current, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(current, original) {
+			continue
+		}
+		return original, WriteFileAtomic(path, append(data, '\n'), info.Mode().Perm())
+	}
+	return nil, fmt.Errorf("%s kept changing while being edited; is Claude Code running?", path)
+}
+
+// WriteFileAtomic writes data to a temporary file beside path and renames it
+// into place, so readers see either the old contents or the new
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,71 @@
+// ABOUTME: Tests for editing JSON files that another process also writes
+// ABOUTME: Verifies concurrent changes are kept and the file mode is preserved
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditJSONKeepsConcurrentChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".claude.json")
+	if err := os.WriteFile(path, []byte(`{"numStartups": 1, "enabledPlugins": {"a": true}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	original, err := EditJSON(path, func(obj map[string]json.RawMessage) error {
+		calls++
+		if calls == 1 {
+			// Claude Code writes while the edit is in progress
+			os.WriteFile(path, []byte(`{"numStartups": 2, "enabledPlugins": {"a": true}}`), 0600)
+		}
+		delete(obj, "enabledPlugins")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EditJSON failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the edit to be reapplied once, ran %d times", calls)
+	}
+	if string(original) != `{"numStartups": 2, "enabledPlugins": {"a": true}}` {
+		t.Errorf("original = %s", original)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "{\n  \"numStartups\": 2\n}\n" {
+		t.Errorf("Expected the concurrent change kept and the edit applied, got %s", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestEditJSONThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles.json")
+	link := filepath.Join(dir, ".claude.json")
+	if err := os.WriteFile(target, []byte(`{"a": 1, "b": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	if _, err := EditJSON(link, func(obj map[string]json.RawMessage) error {
+		delete(obj, "b")
+		return nil
+	}); err != nil {
+		t.Fatalf("EditJSON failed: %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("Expected the symlink to be left in place")
+	}
+	if data, _ := os.ReadFile(target); string(data) != "{\n  \"a\": 1\n}\n" {
+		t.Errorf("Expected the target edited, got %s", data)
+	}
+}
@@ -0,0 +1,81 @@
+// ABOUTME: Data structures and functions for managing Claude Code marketplaces
+// ABOUTME: Handles reading and writing known_marketplaces.json
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// MarketplaceRegistry represents the known_marketplaces.json file structure
+type MarketplaceRegistry map[string]MarketplaceMetadata
+
+// MarketplaceMetadata represents metadata for an installed marketplace
+type MarketplaceMetadata struct {
+	Source          MarketplaceSource `json:"source"`
+	InstallLocation string            `json:"installLocation"`
+	LastUpdated     string            `json:"lastUpdated"`
+}
+
+// MarketplaceSource represents the source of a marketplace
+// GitHub sources use Repo, plain git sources use URL, directory sources use Path
+type MarketplaceSource struct {
+	Source string `json:"source"`
+	Repo   string `json:"repo,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+// Key returns the identifier used to match a source across profiles
+func (s MarketplaceSource) Key() string {
+	if s.Repo != "" {
+		return s.Repo
+	}
+	if s.URL != "" {
+		return s.URL
+	}
+	return s.Path
+}
+
+// LoadMarketplaces reads and parses the known_marketplaces.json file
+func LoadMarketplaces(claudeDir string) (MarketplaceRegistry, error) {
+	data, err := os.ReadFile(marketplacesPath(claudeDir))
+	if err != nil {
+		return nil, err
+	}
+
+	var registry MarketplaceRegistry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+// SaveMarketplaces writes the marketplace registry back to known_marketplaces.json
+func SaveMarketplaces(claudeDir string, registry MarketplaceRegistry) error {
+	data, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(marketplacesPath(claudeDir), data, 0644)
+}
+
+// Sources returns every marketplace source sorted by Key
+func (r MarketplaceRegistry) Sources() []MarketplaceSource {
+	sources := make([]MarketplaceSource, 0, len(r))
+	for _, meta := range r {
+		sources = append(sources, meta.Source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Key() < sources[j].Key()
+	})
+	return sources
+}
+
+func marketplacesPath(claudeDir string) string {
+	return filepath.Join(claudeDir, "plugins", "known_marketplaces.json")
+}
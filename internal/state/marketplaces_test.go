@@ -0,0 +1,64 @@
+// ABOUTME: Tests for the known_marketplaces.json loader and saver
+// ABOUTME: Covers git sources that are identified by URL instead of repo
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMarketplacesRoundTripPreservesURL(t *testing.T) {
+	claudeDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := MarketplaceRegistry{
+		"internal": {
+			Source:          MarketplaceSource{Source: "git", URL: "https://git.example.com/tools.git"},
+			InstallLocation: "/path/internal",
+		},
+		"public": {
+			Source: MarketplaceSource{Source: "github", Repo: "org/public"},
+		},
+	}
+	if err := SaveMarketplaces(claudeDir, registry); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(marketplacesPath(claudeDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"repo": ""`) {
+		t.Errorf("Expected empty repo to be omitted, got %s", data)
+	}
+
+	loaded, err := LoadMarketplaces(claudeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded["internal"].Source.URL; got != "https://git.example.com/tools.git" {
+		t.Errorf("Expected git URL to survive round trip, got %q", got)
+	}
+}
+
+func TestMarketplaceSourcesSortedByKey(t *testing.T) {
+	registry := MarketplaceRegistry{
+		"b": {Source: MarketplaceSource{Source: "github", Repo: "org/zeta"}},
+		"a": {Source: MarketplaceSource{Source: "git", URL: "https://example.com/alpha.git"}},
+		"c": {Source: MarketplaceSource{Source: "github", Repo: "org/beta"}},
+	}
+
+	var keys []string
+	for _, src := range registry.Sources() {
+		keys = append(keys, src.Key())
+	}
+
+	want := []string{"https://example.com/alpha.git", "org/beta", "org/zeta"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, keys)
+	}
+}
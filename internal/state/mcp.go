@@ -0,0 +1,78 @@
+// ABOUTME: Data structures and functions for user-scoped MCP servers
+// ABOUTME: Reads the mcpServers section of ~/.claude.json without loading the rest
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ClaudeJSON represents the ~/.claude.json file structure (relevant parts)
+type ClaudeJSON struct {
+	MCPServers map[string]MCPServer `json:"mcpServers"`
+}
+
+// MCPServer represents an MCP server in ~/.claude.json
+type MCPServer struct {
+	Type    string            `json:"type"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+}
+
+// LoadMCPServers reads the user-scoped MCP servers from .claude.json
+func LoadMCPServers(claudeJSONPath string) (map[string]MCPServer, error) {
+	f, err := os.Open(claudeJSONPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return DecodeMCPServers(f)
+}
+
+// DecodeMCPServers stream-decodes only the top-level mcpServers key from
+// .claude.json, which can grow to several megabytes of per-project history
+func DecodeMCPServers(r io.Reader) (map[string]MCPServer, error) {
+	var servers map[string]MCPServer
+	if err := decodeMCPSection(r, &servers); err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// decodeMCPSection decodes the top-level mcpServers value into v, leaving v
+// untouched if there is none
+func decodeMCPSection(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected JSON object, got %v", tok)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+
+		if key == "mcpServers" {
+			return dec.Decode(v)
+		}
+
+		// Skip values we don't care about without building Go structures
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
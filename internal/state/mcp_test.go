@@ -0,0 +1,41 @@
+// ABOUTME: Tests for reading MCP servers from .claude.json
+// ABOUTME: Verifies the streaming decoder skips unrelated keys
+package state
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeMCPServersSkipsOtherKeys(t *testing.T) {
+	input := `{
+		"numStartups": 12,
+		"projects": {"/a": {"history": [{"display": "x"}]}},
+		"mcpServers": {"context7": {"type": "stdio", "command": "npx", "args": ["-y", "ctx"]}},
+		"trailing": [1, 2, 3]
+	}`
+
+	servers, err := DecodeMCPServers(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("DecodeMCPServers failed: %v", err)
+	}
+	if len(servers) != 1 || servers["context7"].Command != "npx" {
+		t.Errorf("Expected context7 server, got %v", servers)
+	}
+}
+
+func TestDecodeMCPServersMissingKey(t *testing.T) {
+	servers, err := DecodeMCPServers(strings.NewReader(`{"projects": {}}`))
+	if err != nil {
+		t.Fatalf("DecodeMCPServers failed: %v", err)
+	}
+	if len(servers) != 0 {
+		t.Errorf("Expected no servers, got %v", servers)
+	}
+}
+
+func TestDecodeMCPServersRejectsNonObject(t *testing.T) {
+	if _, err := DecodeMCPServers(strings.NewReader(`[1, 2]`)); err == nil {
+		t.Error("Expected error for non-object JSON")
+	}
+}
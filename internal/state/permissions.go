@@ -0,0 +1,156 @@
+// ABOUTME: Reads and adds Claude Code tool permissions and project trust
+// ABOUTME: Permissions live in settings.json; trust is per project in .claude.json
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Permissions is the permissions section of settings.json that claudeup
+// pre-seeds from profiles. Other keys in the section are left alone.
+type Permissions struct {
+	Allow                 []string `json:"allow,omitempty"`
+	AdditionalDirectories []string `json:"additionalDirectories,omitempty"`
+}
+
+func settingsPath(claudeDir string) string {
+	return filepath.Join(claudeDir, "settings.json")
+}
+
+// LoadPermissions reads the permissions section of settings.json. A missing
+// file has no permissions.
+func LoadPermissions(claudeDir string) (Permissions, error) {
+	var settings struct {
+		Permissions Permissions `json:"permissions"`
+	}
+	data, err := os.ReadFile(settingsPath(claudeDir))
+	if os.IsNotExist(err) {
+		return settings.Permissions, nil
+	}
+	if err != nil {
+		return settings.Permissions, err
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings.Permissions, fmt.Errorf("%s: %w", settingsPath(claudeDir), err)
+	}
+	return settings.Permissions, nil
+}
+
+// AddPermissions appends allow rules and directories missing from
+// settings.json, creating the file if there is none
+func AddPermissions(claudeDir string, add Permissions) error {
+	path := settingsPath(claudeDir)
+	if err := ensureJSONFile(path); err != nil {
+		return err
+	}
+	_, err := EditJSON(path, func(top map[string]json.RawMessage) error {
+		section := make(map[string]json.RawMessage)
+		if raw, ok := top["permissions"]; ok {
+			if err := json.Unmarshal(raw, &section); err != nil {
+				return fmt.Errorf("permissions: %w", err)
+			}
+		}
+		if err := appendToList(section, "allow", add.Allow); err != nil {
+			return err
+		}
+		if err := appendToList(section, "additionalDirectories", add.AdditionalDirectories); err != nil {
+			return err
+		}
+		data, err := json.Marshal(section)
+		if err != nil {
+			return err
+		}
+		top["permissions"] = data
+		return nil
+	})
+	return err
+}
+
+// ProjectTrusted reports whether the user accepted Claude Code's trust
+// dialog for the project directory
+func ProjectTrusted(claudeJSONPath, project string) (bool, error) {
+	var claudeJSON struct {
+		Projects map[string]struct {
+			HasTrustDialogAccepted bool `json:"hasTrustDialogAccepted"`
+		} `json:"projects"`
+	}
+	data, err := os.ReadFile(claudeJSONPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, &claudeJSON); err != nil {
+		return false, fmt.Errorf("%s: %w", claudeJSONPath, err)
+	}
+	return claudeJSON.Projects[project].HasTrustDialogAccepted, nil
+}
+
+// TrustProject marks the project directory as trusted in .claude.json, so
+// Claude Code doesn't ask on first start there. The project's other
+// settings are kept.
+func TrustProject(claudeJSONPath, project string) error {
+	if err := ensureJSONFile(claudeJSONPath); err != nil {
+		return err
+	}
+	_, err := EditJSON(claudeJSONPath, func(top map[string]json.RawMessage) error {
+		projects := make(map[string]map[string]json.RawMessage)
+		if raw, ok := top["projects"]; ok {
+			if err := json.Unmarshal(raw, &projects); err != nil {
+				return fmt.Errorf("projects: %w", err)
+			}
+		}
+		if projects[project] == nil {
+			projects[project] = make(map[string]json.RawMessage)
+		}
+		projects[project]["hasTrustDialogAccepted"] = json.RawMessage("true")
+		data, err := json.Marshal(projects)
+		if err != nil {
+			return err
+		}
+		top["projects"] = data
+		return nil
+	})
+	return err
+}
+
+// ensureJSONFile creates path holding an empty object if it doesn't exist
+func ensureJSONFile(path string) error {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte("{}\n"), 0644)
+}
+
+// appendToList adds the values missing from the JSON string list at
+// section[key]
+func appendToList(section map[string]json.RawMessage, key string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	var list []string
+	if raw, ok := section[key]; ok {
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return fmt.Errorf("permissions.%s: %w", key, err)
+		}
+	}
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	section[key] = data
+	return nil
+}
@@ -0,0 +1,81 @@
+// ABOUTME: Tests for reading and adding settings.json permissions and project trust
+// ABOUTME: Checks that existing settings and project entries survive the edits
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddPermissionsMergesIntoSettings(t *testing.T) {
+	claudeDir := t.TempDir()
+	os.WriteFile(filepath.Join(claudeDir, "settings.json"),
+		[]byte(`{"model": "opus", "permissions": {"allow": ["Read"], "deny": ["Bash(rm:*)"]}}`), 0644)
+
+	err := AddPermissions(claudeDir, Permissions{Allow: []string{"Read", "Bash(npm test:*)"}, AdditionalDirectories: []string{"/src/shared"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	perms, err := LoadPermissions(claudeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perms.Allow) != 2 || perms.Allow[1] != "Bash(npm test:*)" {
+		t.Errorf("Expected the new rule appended once, got %v", perms.Allow)
+	}
+	if len(perms.AdditionalDirectories) != 1 || perms.AdditionalDirectories[0] != "/src/shared" {
+		t.Errorf("Unexpected directories %v", perms.AdditionalDirectories)
+	}
+
+	var settings struct {
+		Model       string `json:"model"`
+		Permissions struct {
+			Deny []string `json:"deny"`
+		} `json:"permissions"`
+	}
+	data, _ := os.ReadFile(filepath.Join(claudeDir, "settings.json"))
+	json.Unmarshal(data, &settings)
+	if settings.Model != "opus" || len(settings.Permissions.Deny) != 1 {
+		t.Errorf("Expected other settings kept, got %s", data)
+	}
+}
+
+func TestAddPermissionsCreatesSettings(t *testing.T) {
+	claudeDir := filepath.Join(t.TempDir(), ".claude")
+	if err := AddPermissions(claudeDir, Permissions{Allow: []string{"WebFetch"}}); err != nil {
+		t.Fatal(err)
+	}
+	perms, err := LoadPermissions(claudeDir)
+	if err != nil || len(perms.Allow) != 1 {
+		t.Errorf("Expected a new settings.json with the rule, got %v, %v", perms, err)
+	}
+}
+
+func TestTrustProject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".claude.json")
+	os.WriteFile(path, []byte(`{"projects": {"/work/app": {"allowedTools": ["Edit"]}}, "mcpServers": {}}`), 0644)
+
+	if trusted, _ := ProjectTrusted(path, "/work/app"); trusted {
+		t.Fatal("Expected the project to start untrusted")
+	}
+	if err := TrustProject(path, "/work/app"); err != nil {
+		t.Fatal(err)
+	}
+	if trusted, err := ProjectTrusted(path, "/work/app"); err != nil || !trusted {
+		t.Errorf("Expected the project trusted, got %v, %v", trusted, err)
+	}
+
+	var claudeJSON struct {
+		Projects map[string]struct {
+			AllowedTools []string `json:"allowedTools"`
+		} `json:"projects"`
+	}
+	data, _ := os.ReadFile(path)
+	json.Unmarshal(data, &claudeJSON)
+	if len(claudeJSON.Projects["/work/app"].AllowedTools) != 1 {
+		t.Errorf("Expected the project's other settings kept, got %s", data)
+	}
+}
@@ -0,0 +1,192 @@
+// ABOUTME: Finds state claudeup can't represent in a profile, for 'profile use --strict'
+// ABOUTME: Unknown registry fields and versions, non-user plugin scopes, and non-stdio MCP servers
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/claudeup/claudeup/internal/claude/registryversion"
+)
+
+// marketplaceSourceTypes are the registry source types claudeup maps to profiles
+var marketplaceSourceTypes = []string{"github", "git", "directory"}
+
+// Unrepresentable describes everything in Claude's plugin registry,
+// marketplace registry, and user MCP servers that a profile can't hold:
+// fields and schema versions claudeup doesn't know, plugins installed
+// outside user scope, and MCP servers that aren't stdio commands. Applying
+// a profile over such state can silently lose it. Missing files have
+// nothing to report.
+func Unrepresentable(claudeDir, claudeJSONPath string) ([]string, error) {
+	var issues []string
+
+	data, err := os.ReadFile(pluginsPath(claudeDir))
+	if err == nil {
+		found, err := pluginRegistryIssues(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pluginsPath(claudeDir), err)
+		}
+		issues = append(issues, found...)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	data, err = os.ReadFile(marketplacesPath(claudeDir))
+	if err == nil {
+		found, err := marketplaceRegistryIssues(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", marketplacesPath(claudeDir), err)
+		}
+		issues = append(issues, found...)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	f, err := os.Open(claudeJSONPath)
+	if err == nil {
+		defer f.Close()
+		var servers map[string]map[string]json.RawMessage
+		if err := decodeMCPSection(f, &servers); err != nil {
+			return nil, fmt.Errorf("%s: %w", claudeJSONPath, err)
+		}
+		issues = append(issues, mcpServerIssues(servers)...)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return issues, nil
+}
+
+func pluginRegistryIssues(data []byte) ([]string, error) {
+	version, err := registryversion.Detect(data)
+	if err != nil {
+		return nil, err
+	}
+	var raw struct {
+		Version int                        `json:"version"`
+		Plugins map[string]json.RawMessage `json:"plugins"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	issues := unknownFields(data, "installed_plugins.json", raw)
+	if raw.Version > int(registryversion.Current) {
+		issues = append(issues, fmt.Sprintf("installed_plugins.json is schema v%d; claudeup understands up to %s", raw.Version, registryversion.Current))
+	}
+
+	for _, name := range sortedKeys(raw.Plugins) {
+		entry := raw.Plugins[name]
+		where := "plugin " + name
+		if version == registryversion.V1 {
+			issues = append(issues, unknownFields(entry, where, registryversion.PluginMetadataV1{})...)
+			continue
+		}
+		var scopes []json.RawMessage
+		if err := json.Unmarshal(entry, &scopes); err != nil {
+			return nil, fmt.Errorf("%s: %w", where, err)
+		}
+		for _, s := range scopes {
+			issues = append(issues, unknownFields(s, where, PluginMetadata{})...)
+			var meta PluginMetadata
+			if err := json.Unmarshal(s, &meta); err == nil && meta.Scope != "" && meta.Scope != "user" {
+				issues = append(issues, fmt.Sprintf("%s is installed at %s scope; profiles only hold user-scoped plugins", where, meta.Scope))
+			}
+		}
+	}
+	return issues, nil
+}
+
+func marketplaceRegistryIssues(data []byte) ([]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	var issues []string
+	for _, name := range sortedKeys(raw) {
+		where := "marketplace " + name
+		issues = append(issues, unknownFields(raw[name], where, MarketplaceMetadata{})...)
+
+		var entry struct {
+			Source json.RawMessage `json:"source"`
+		}
+		if err := json.Unmarshal(raw[name], &entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", where, err)
+		}
+		if len(entry.Source) == 0 {
+			continue
+		}
+		issues = append(issues, unknownFields(entry.Source, where+" source", MarketplaceSource{})...)
+		var src MarketplaceSource
+		if err := json.Unmarshal(entry.Source, &src); err == nil && !slices.Contains(marketplaceSourceTypes, src.Source) {
+			issues = append(issues, fmt.Sprintf("%s has source type %q; profiles hold %s", where, src.Source, strings.Join(marketplaceSourceTypes, ", ")))
+		}
+	}
+	return issues, nil
+}
+
+func mcpServerIssues(servers map[string]map[string]json.RawMessage) []string {
+	var issues []string
+	known := jsonFields(MCPServer{})
+	for _, name := range sortedKeys(servers) {
+		where := "MCP server " + name
+		var transport string
+		json.Unmarshal(servers[name]["type"], &transport)
+		if transport != "" && transport != "stdio" {
+			issues = append(issues, fmt.Sprintf("%s uses the %s transport; profiles only hold stdio commands", where, transport))
+			continue
+		}
+		for _, field := range sortedKeys(servers[name]) {
+			if !known[field] {
+				issues = append(issues, fmt.Sprintf("%s has unknown field %q", where, field))
+			}
+		}
+	}
+	return issues
+}
+
+// unknownFields reports the keys of the JSON object data that v's struct
+// fields don't decode
+func unknownFields(data []byte, where string, v interface{}) []string {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	known := jsonFields(v)
+	var issues []string
+	for _, key := range sortedKeys(raw) {
+		if !known[key] {
+			issues = append(issues, fmt.Sprintf("%s has unknown field %q", where, key))
+		}
+	}
+	return issues
+}
+
+// jsonFields returns the JSON names of a struct's fields
+func jsonFields(v interface{}) map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		fields[name] = true
+	}
+	return fields
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+// ABOUTME: Tests for finding Claude state a profile can't represent
+// ABOUTME: Covers unknown registry fields and versions, plugin scopes, and MCP transports
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeStrictFixture(t *testing.T, plugins, marketplaces, claudeJSON string) (string, string) {
+	t.Helper()
+	claudeDir := t.TempDir()
+	os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755)
+	if plugins != "" {
+		os.WriteFile(pluginsPath(claudeDir), []byte(plugins), 0644)
+	}
+	if marketplaces != "" {
+		os.WriteFile(marketplacesPath(claudeDir), []byte(marketplaces), 0644)
+	}
+	claudeJSONPath := filepath.Join(t.TempDir(), ".claude.json")
+	if claudeJSON != "" {
+		os.WriteFile(claudeJSONPath, []byte(claudeJSON), 0644)
+	}
+	return claudeDir, claudeJSONPath
+}
+
+func TestUnrepresentableCleanState(t *testing.T) {
+	claudeDir, claudeJSONPath := writeStrictFixture(t,
+		`{"version": 2, "plugins": {"tdd@sp": [{"scope": "user", "version": "1.0", "installPath": "/p", "gitCommitSha": "abc", "isLocal": false, "installedAt": "", "lastUpdated": ""}]}}`,
+		`{"sp": {"source": {"source": "github", "repo": "obra/sp"}, "installLocation": "/m", "lastUpdated": ""}}`,
+		`{"projects": {}, "mcpServers": {"ctx": {"type": "stdio", "command": "npx", "args": ["ctx"], "env": {}}}}`)
+
+	issues, err := Unrepresentable(claudeDir, claudeJSONPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestUnrepresentableMissingFiles(t *testing.T) {
+	claudeDir, claudeJSONPath := writeStrictFixture(t, "", "", "")
+	issues, err := Unrepresentable(claudeDir, claudeJSONPath)
+	if err != nil || len(issues) != 0 {
+		t.Errorf("Expected nothing to report on a fresh install, got %v, %v", issues, err)
+	}
+}
+
+func TestUnrepresentableFindsIssues(t *testing.T) {
+	claudeDir, claudeJSONPath := writeStrictFixture(t,
+		`{"version": 3, "plugins": {"tdd@sp": [{"scope": "project", "version": "1.0", "pinned": true}]}}`,
+		`{"sp": {"source": {"source": "npm", "package": "sp"}, "installLocation": "/m", "autoUpdate": true}}`,
+		`{"mcpServers": {"remote": {"type": "http", "url": "https://mcp.example.com"}, "local": {"command": "x", "cwd": "/tmp"}}}`)
+
+	issues, err := Unrepresentable(claudeDir, claudeJSONPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"installed_plugins.json is schema v3",
+		`plugin tdd@sp has unknown field "pinned"`,
+		"plugin tdd@sp is installed at project scope",
+		`marketplace sp has unknown field "autoUpdate"`,
+		`marketplace sp source has unknown field "package"`,
+		`marketplace sp has source type "npm"`,
+		`MCP server local has unknown field "cwd"`,
+		"MCP server remote uses the http transport",
+	}
+	all := strings.Join(issues, "\n")
+	for _, w := range want {
+		if !strings.Contains(all, w) {
+			t.Errorf("Expected an issue containing %q, got:\n%s", w, all)
+		}
+	}
+	if len(issues) != len(want) {
+		t.Errorf("Expected %d issues, got %d:\n%s", len(want), len(issues), all)
+	}
+}
@@ -0,0 +1,214 @@
+// ABOUTME: OTLP/HTTP exporter sending traces as JSON to an OpenTelemetry collector
+// ABOUTME: Configured from the OTEL_EXPORTER_OTLP_* environment variables or the "telemetry" config section
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+)
+
+// Exporter posts finished traces to an OTLP/HTTP traces endpoint
+type Exporter struct {
+	Endpoint string // full URL, ending in /v1/traces for a standard collector
+	Headers  map[string]string
+	Version  string // reported as service.version
+	Client   *http.Client
+}
+
+// NewExporter returns an exporter for the configured endpoint, or nil when
+// none is configured or OTEL_SDK_DISABLED is true. The standard environment
+// variables override cfg:
+//
+//	OTEL_EXPORTER_OTLP_TRACES_ENDPOINT  traces URL, used as is
+//	OTEL_EXPORTER_OTLP_ENDPOINT         base URL; /v1/traces is appended
+//	OTEL_EXPORTER_OTLP_HEADERS          k=v pairs separated by commas
+func NewExporter(cfg config.Telemetry, version string) *Exporter {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return nil
+	}
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if base == "" {
+			base = cfg.Endpoint
+		}
+		if base == "" {
+			return nil
+		}
+		endpoint = strings.TrimRight(base, "/") + "/v1/traces"
+	}
+
+	headers := make(map[string]string)
+	for k, v := range cfg.Headers {
+		headers[k] = os.ExpandEnv(v)
+	}
+	for _, env := range []string{"OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_TRACES_HEADERS"} {
+		for k, v := range parseHeaders(os.Getenv(env)) {
+			headers[k] = v
+		}
+	}
+	return &Exporter{Endpoint: endpoint, Headers: headers, Version: version}
+}
+
+// parseHeaders reads the OTEL_EXPORTER_OTLP_HEADERS format: k=v pairs
+// separated by commas, with URL-encoded values
+func parseHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			continue
+		}
+		if decoded, err := url.QueryUnescape(strings.TrimSpace(v)); err == nil {
+			v = decoded
+		}
+		headers[k] = strings.TrimSpace(v)
+	}
+	return headers
+}
+
+// OTLP JSON encoding, as specified for OTLP/HTTP. IDs are hex and
+// 64-bit integers are strings.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttr `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	otlpSpan struct {
+		TraceID           string      `json:"traceId"`
+		SpanID            string      `json:"spanId"`
+		ParentSpanID      string      `json:"parentSpanId,omitempty"`
+		Name              string      `json:"name"`
+		Kind              int         `json:"kind"`
+		StartTimeUnixNano string      `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+		Attributes        []otlpAttr  `json:"attributes,omitempty"`
+		Status            *otlpStatus `json:"status,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttr struct {
+		Key   string            `json:"key"`
+		Value map[string]string `json:"value"`
+	}
+)
+
+const (
+	spanKindInternal = 1
+	statusCodeError  = 2
+)
+
+func encodeAttrs(attrs []Attr) []otlpAttr {
+	encoded := make([]otlpAttr, 0, len(attrs))
+	for _, a := range attrs {
+		var value map[string]string
+		switch v := a.Value.(type) {
+		case int:
+			value = map[string]string{"intValue": strconv.Itoa(v)}
+		case bool:
+			value = map[string]string{"boolValue": strconv.FormatBool(v)}
+		default:
+			value = map[string]string{"stringValue": fmt.Sprint(v)}
+		}
+		encoded = append(encoded, otlpAttr{Key: a.Key, Value: value})
+	}
+	return encoded
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// payload encodes spans as an OTLP export request
+func (e *Exporter) payload(traceID [16]byte, spans []Span) otlpRequest {
+	host, _ := os.Hostname()
+	resource := []Attr{
+		String("service.name", "claudeup"),
+		String("service.version", e.Version),
+		String("host.name", host),
+		String("os.type", runtime.GOOS),
+	}
+
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(traceID[:]),
+			SpanID:            hex.EncodeToString(s.id[:]),
+			Name:              s.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: unixNano(s.start),
+			EndTimeUnixNano:   unixNano(s.end),
+			Attributes:        encodeAttrs(s.attrs),
+		}
+		if s.parent != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parent[:])
+		}
+		if s.err != "" {
+			span.Status = &otlpStatus{Code: statusCodeError, Message: s.err}
+		}
+		encoded = append(encoded, span)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: encodeAttrs(resource)},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "claudeup", Version: e.Version}, Spans: encoded}},
+	}}}
+}
+
+func (e *Exporter) export(ctx context.Context, traceID [16]byte, spans []Span) error {
+	body, err := json.Marshal(e.payload(traceID, spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := e.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", e.Endpoint, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// ABOUTME: Records applies as OpenTelemetry traces and exports them to an OTLP/HTTP collector
+// ABOUTME: Spans travel in the context; without a trace in it, starting a span does nothing
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Attr is a span attribute. Values are strings, ints, or bools.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute
+func String(key, value string) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Int returns an integer attribute
+func Int(key string, value int) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// trace holds every span started under one root, so the root can export
+// them together
+type trace struct {
+	id       [16]byte
+	exporter *Exporter
+	mu       sync.Mutex
+	spans    []*Span
+}
+
+// Span is one timed operation in a trace. A nil *Span is valid and every
+// method on it does nothing, so callers needn't check whether tracing is on.
+type Span struct {
+	trace  *trace
+	name   string
+	id     [8]byte
+	parent [8]byte
+	start  time.Time
+	end    time.Time
+	attrs  []Attr
+	err    string
+}
+
+type spanKey struct{}
+
+// StartTrace starts the root span of a new trace, to be sent to exporter
+// by Export. With a nil exporter it returns ctx unchanged and a nil span.
+func StartTrace(ctx context.Context, exporter *Exporter, name string, attrs ...Attr) (context.Context, *Span) {
+	if exporter == nil {
+		return ctx, nil
+	}
+	t := &trace{exporter: exporter}
+	rand.Read(t.id[:])
+	return t.start(ctx, name, [8]byte{}, attrs)
+}
+
+// Start starts a span as a child of the span in ctx. Without one it
+// returns ctx unchanged and a nil span.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	parent := SpanFrom(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.trace.start(ctx, name, parent.id, attrs)
+}
+
+func (t *trace) start(ctx context.Context, name string, parent [8]byte, attrs []Attr) (context.Context, *Span) {
+	s := &Span{trace: t, name: name, parent: parent, start: time.Now(), attrs: attrs}
+	rand.Read(s.id[:])
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// SpanFrom returns the span in ctx, or nil
+func SpanFrom(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// SetAttributes adds attributes to s
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.trace.mu.Lock()
+	defer s.trace.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// End records that s finished, failing if err is non-nil. Only the first
+// call counts.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.trace.mu.Lock()
+	defer s.trace.mu.Unlock()
+	if !s.end.IsZero() {
+		return
+	}
+	s.end = time.Now()
+	if err != nil {
+		s.err = err.Error()
+	}
+}
+
+// TraceID returns the hex ID of the trace s belongs to, or "" for a nil span
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.trace.id[:])
+}
+
+// Export sends every span in s's trace to its exporter, ending any still
+// open. Call it on the root span once the traced work is done.
+func (s *Span) Export(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	t := s.trace
+	t.mu.Lock()
+	now := time.Now()
+	spans := make([]Span, len(t.spans))
+	for i, span := range t.spans {
+		if span.end.IsZero() {
+			span.end = now
+		}
+		spans[i] = *span
+	}
+	t.mu.Unlock()
+	return t.exporter.export(ctx, t.id, spans)
+}
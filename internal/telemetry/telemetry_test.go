@@ -0,0 +1,141 @@
+// ABOUTME: Tests for recording spans and exporting them as OTLP JSON
+// ABOUTME: Uses an httptest collector and checks the IDs, parents, statuses, and configuration from env
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/config"
+)
+
+// collector records the last export request it received
+type collector struct {
+	srv     *httptest.Server
+	path    string
+	auth    string
+	request otlpRequest
+}
+
+func newCollector(t *testing.T) *collector {
+	c := &collector{}
+	c.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.path = r.URL.Path
+		c.auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&c.request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(c.srv.Close)
+	return c
+}
+
+func TestExportSendsTrace(t *testing.T) {
+	c := newCollector(t)
+	exporter := &Exporter{Endpoint: c.srv.URL + "/v1/traces", Headers: map[string]string{"Authorization": "Bearer t"}, Version: "1.2.3"}
+
+	ctx, root := StartTrace(context.Background(), exporter, "apply", String("profile", "backend"), Int("changes", 2))
+	_, install := Start(ctx, "claude plugin install")
+	install.End(errors.New("exit status 1"))
+	Start(ctx, "webhook apply-success") // left open, so export ends it
+	root.SetAttributes(Bool("interrupted", false))
+	root.End(nil)
+	if err := root.Export(context.Background()); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	if c.path != "/v1/traces" || c.auth != "Bearer t" {
+		t.Errorf("Expected a POST to /v1/traces with headers, got %s with %q", c.path, c.auth)
+	}
+	spans := c.request.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+	for _, s := range spans {
+		if s.TraceID != root.TraceID() || len(s.TraceID) != 32 || len(s.SpanID) != 16 {
+			t.Errorf("Span %s has trace %q and ID %q", s.Name, s.TraceID, s.SpanID)
+		}
+		if s.EndTimeUnixNano == "" || s.EndTimeUnixNano < s.StartTimeUnixNano {
+			t.Errorf("Expected span %s ended after it started", s.Name)
+		}
+	}
+	if spans[0].ParentSpanID != "" || spans[1].ParentSpanID != spans[0].SpanID {
+		t.Errorf("Expected the install to be a child of the root, got parents %q and %q", spans[0].ParentSpanID, spans[1].ParentSpanID)
+	}
+	if spans[0].Status != nil || spans[1].Status == nil || spans[1].Status.Message != "exit status 1" {
+		t.Errorf("Expected only the install to fail, got %+v and %+v", spans[0].Status, spans[1].Status)
+	}
+	attrs := map[string]map[string]string{}
+	for _, a := range spans[0].Attributes {
+		attrs[a.Key] = a.Value
+	}
+	if attrs["profile"]["stringValue"] != "backend" || attrs["changes"]["intValue"] != "2" || attrs["interrupted"]["boolValue"] != "false" {
+		t.Errorf("Unexpected root attributes %v", attrs)
+	}
+	resource := c.request.ResourceSpans[0].Resource.Attributes
+	if resource[0].Key != "service.name" || resource[0].Value["stringValue"] != "claudeup" {
+		t.Errorf("Expected service.name claudeup, got %v", resource[0])
+	}
+}
+
+func TestNoTraceWithoutExporter(t *testing.T) {
+	ctx, root := StartTrace(context.Background(), nil, "apply")
+	if root != nil || SpanFrom(ctx) != nil {
+		t.Fatal("Expected no trace without an exporter")
+	}
+	_, child := Start(ctx, "claude mcp add")
+	child.SetAttributes(String("k", "v"))
+	child.End(nil)
+	if err := root.Export(ctx); err != nil || child != nil {
+		t.Errorf("Expected spans without a trace to do nothing, got %v", err)
+	}
+}
+
+func TestExportReportsCollectorErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	_, root := StartTrace(context.Background(), &Exporter{Endpoint: srv.URL}, "apply")
+	root.End(nil)
+	if err := root.Export(context.Background()); err == nil {
+		t.Error("Expected an error from a failing collector")
+	}
+}
+
+func TestNewExporterConfiguration(t *testing.T) {
+	for _, env := range []string{"OTEL_SDK_DISABLED", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_TRACES_HEADERS"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("OTEL_TOKEN", "s3cret")
+	cfg := config.Telemetry{Endpoint: "http://otel:4318/", Headers: map[string]string{"Authorization": "Bearer $OTEL_TOKEN", "X-Team": "config"}}
+
+	if e := NewExporter(config.Telemetry{}, "1.0"); e != nil {
+		t.Errorf("Expected no exporter without an endpoint, got %+v", e)
+	}
+	e := NewExporter(cfg, "1.0")
+	if e == nil || e.Endpoint != "http://otel:4318/v1/traces" || e.Headers["Authorization"] != "Bearer s3cret" {
+		t.Fatalf("Expected the config endpoint and expanded headers, got %+v", e)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://collector.example.com")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "X-Team=platform%20eng, x-other=1")
+	e = NewExporter(cfg, "1.0")
+	if e.Endpoint != "https://collector.example.com/v1/traces" || e.Headers["X-Team"] != "platform eng" || e.Headers["x-other"] != "1" {
+		t.Errorf("Expected the environment to override the config, got %+v", e)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "https://traces.example.com/custom")
+	if e = NewExporter(cfg, "1.0"); e.Endpoint != "https://traces.example.com/custom" {
+		t.Errorf("Expected the traces endpoint used as is, got %s", e.Endpoint)
+	}
+
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	if e = NewExporter(cfg, "1.0"); e != nil {
+		t.Error("Expected OTEL_SDK_DISABLED to turn export off")
+	}
+}
@@ -0,0 +1,34 @@
+// ABOUTME: Formats external command lines for verbose output
+// ABOUTME: Quotes arguments the way a shell would and hides secret values
+package ui
+
+import (
+	"strings"
+)
+
+// CommandLine formats name and args as a shell-style command line. Any
+// occurrence of a value in hide is replaced with [REDACTED], so secrets
+// passed as arguments aren't echoed to the terminal.
+func CommandLine(name string, args []string, hide ...string) string {
+	parts := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{name}, args...) {
+		for _, secret := range hide {
+			if secret != "" {
+				arg = strings.ReplaceAll(arg, secret, "[REDACTED]")
+			}
+		}
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote single-quotes s if a shell would otherwise split or expand it
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(s, " \t\n'\"\\$`|&;<>()*?[]{}~#!") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,23 @@
+// ABOUTME: Tests for formatting command lines in verbose output
+// ABOUTME: Checks shell quoting and that secret values are hidden
+package ui
+
+import "testing"
+
+func TestCommandLine(t *testing.T) {
+	tests := []struct {
+		args []string
+		hide []string
+		want string
+	}{
+		{[]string{"plugin", "install", "tdd@superpowers"}, nil, "claude plugin install tdd@superpowers"},
+		{[]string{"mcp", "add", "db", "--", "psql", "host=x user=me"}, nil, "claude mcp add db -- psql 'host=x user=me'"},
+		{[]string{"--token", "s3cret", "--url=https://x/?k=s3cret"}, []string{"s3cret"}, "claude --token '[REDACTED]' '--url=https://x/?k=[REDACTED]'"},
+		{[]string{"it's", ""}, nil, `claude 'it'\''s' ''`},
+	}
+	for _, tt := range tests {
+		if got := CommandLine("claude", tt.args, tt.hide...); got != tt.want {
+			t.Errorf("CommandLine(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// ABOUTME: Optional gum (charmbracelet/gum) front end for interactive prompts
+// ABOUTME: Used when gum is installed and stdin is a terminal, with plain prompts otherwise
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// useGum reports whether prompts should go through gum
+func useGum() bool {
+	if os.Getenv("CLAUDEUP_NO_GUM") != "" || !stdinIsTerminal() {
+		return false
+	}
+	_, err := exec.LookPath("gum")
+	return err == nil
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runGum runs gum with the terminal attached and returns what it printed
+func runGum(args ...string) (string, error) {
+	cmd := exec.Command("gum", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok && exit.ExitCode() == 130 {
+			return "", ErrUserCancelled
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// Choose asks the user to pick one of options and returns its index. The
+// first option is the default.
+func Choose(prompt string, options []string) (int, error) {
+	if len(options) == 0 {
+		return 0, fmt.Errorf("nothing to choose from")
+	}
+	if useGum() {
+		picked, err := runGum(append([]string{"choose", "--header", prompt}, options...)...)
+		if err != nil {
+			return 0, err
+		}
+		for i, o := range options {
+			if o == picked {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("unexpected choice %q", picked)
+	}
+
+	std.Promptf("%s\n", prompt)
+	for i, o := range options {
+		std.Promptf("  %d) %s\n", i+1, o)
+	}
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		std.Promptf("Choice [1]: ")
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			if err != nil {
+				std.Promptf("\n")
+			}
+			return 0, nil
+		}
+		if n, convErr := strconv.Atoi(input); convErr == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		std.Promptf("Please enter a number from 1 to %d\n", len(options))
+	}
+}
@@ -0,0 +1,164 @@
+// ABOUTME: Printer interface for command output, carried on the command context
+// ABOUTME: --quiet discards messages and warnings; --verbose adds detail on the error stream
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Printer writes a command's human-readable output
+type Printer interface {
+	// Println, Printf, and Print write informational output
+	Println(a ...interface{})
+	Printf(format string, a ...interface{})
+	Print(a ...interface{})
+
+	// Warnf writes a non-fatal problem to the error stream
+	Warnf(format string, a ...interface{})
+
+	// Promptf writes a question that waits for input. Prompts are shown
+	// even when quiet, since hiding them would leave the command waiting
+	// silently.
+	Promptf(format string, a ...interface{})
+
+	// Out returns the writer for informational output, e.g. for
+	// subprocesses whose output should follow the same rules
+	Out() io.Writer
+
+	// Quiet reports whether informational output is suppressed
+	Quiet() bool
+
+	// Verbosef writes extra detail, such as the commands being run, to the
+	// error stream so it never mixes with machine-readable output. It's
+	// dropped unless verbose, and always when quiet.
+	Verbosef(format string, a ...interface{})
+
+	// VerboseOut returns the writer Verbosef uses, e.g. for a subprocess's
+	// output that's only worth showing in verbose mode
+	VerboseOut() io.Writer
+
+	// Verbose reports whether extra detail is shown
+	Verbose() bool
+}
+
+// StreamPrinter is a Printer over an output and an error stream
+type StreamPrinter struct {
+	out     io.Writer
+	err     io.Writer
+	quiet   bool
+	verbose bool
+}
+
+// NewPrinter returns a Printer writing to out and err
+func NewPrinter(out, err io.Writer, quiet bool) *StreamPrinter {
+	return &StreamPrinter{out: out, err: err, quiet: quiet}
+}
+
+func (p *StreamPrinter) Println(a ...interface{}) {
+	fmt.Fprintln(p.Out(), a...)
+}
+
+func (p *StreamPrinter) Printf(format string, a ...interface{}) {
+	fmt.Fprintf(p.Out(), format, a...)
+}
+
+func (p *StreamPrinter) Print(a ...interface{}) {
+	fmt.Fprint(p.Out(), a...)
+}
+
+func (p *StreamPrinter) Warnf(format string, a ...interface{}) {
+	if p.quiet {
+		return
+	}
+	fmt.Fprintf(p.err, format, a...)
+}
+
+func (p *StreamPrinter) Promptf(format string, a ...interface{}) {
+	fmt.Fprintf(p.out, format, a...)
+}
+
+func (p *StreamPrinter) Out() io.Writer {
+	if p.quiet {
+		return io.Discard
+	}
+	return p.out
+}
+
+func (p *StreamPrinter) Quiet() bool {
+	return p.quiet
+}
+
+func (p *StreamPrinter) Verbosef(format string, a ...interface{}) {
+	fmt.Fprintf(p.VerboseOut(), format, a...)
+}
+
+func (p *StreamPrinter) VerboseOut() io.Writer {
+	if !p.Verbose() {
+		return io.Discard
+	}
+	return p.err
+}
+
+func (p *StreamPrinter) Verbose() bool {
+	return p.verbose && !p.quiet
+}
+
+// SetVerbose turns the printer's extra detail on or off
+func (p *StreamPrinter) SetVerbose(v bool) {
+	p.verbose = v
+}
+
+// std prints to the process's standard streams
+var std = NewPrinter(os.Stdout, os.Stderr, false)
+
+// Default returns the Printer for the standard streams
+func Default() Printer {
+	return std
+}
+
+// SetQuiet turns the default Printer's informational output and warnings
+// off or on
+func SetQuiet(q bool) {
+	std.quiet = q
+}
+
+// Quiet reports whether --quiet is in effect
+func Quiet() bool {
+	return std.quiet
+}
+
+// SetVerbose turns the default Printer's extra detail on or off
+func SetVerbose(v bool) {
+	std.verbose = v
+}
+
+// Verbose reports whether --verbose (or the verboseOutput preference) is in
+// effect
+func Verbose() bool {
+	return std.Verbose()
+}
+
+// Out returns the default Printer's informational writer
+func Out() io.Writer {
+	return std.Out()
+}
+
+type printerKey struct{}
+
+// WithPrinter returns a context that carries p
+func WithPrinter(ctx context.Context, p Printer) context.Context {
+	return context.WithValue(ctx, printerKey{}, p)
+}
+
+// PrinterFrom returns the Printer carried by ctx, or the default Printer
+func PrinterFrom(ctx context.Context) Printer {
+	if ctx != nil {
+		if p, ok := ctx.Value(printerKey{}).(Printer); ok {
+			return p
+		}
+	}
+	return std
+}
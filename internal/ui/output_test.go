@@ -0,0 +1,70 @@
+// ABOUTME: Unit tests for the Printer output layer
+// ABOUTME: Verifies quiet handling and that printers travel on a context
+package ui
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestPrinterRespectsQuiet(t *testing.T) {
+	var out, errOut bytes.Buffer
+	p := NewPrinter(&out, &errOut, false)
+
+	p.Println("hello")
+	p.Warnf("careful\n")
+	if out.String() != "hello\n" || errOut.String() != "careful\n" {
+		t.Fatalf("Unexpected output %q / %q", out.String(), errOut.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	p = NewPrinter(&out, &errOut, true)
+	p.Println("hello")
+	p.Printf("%d\n", 1)
+	p.Warnf("careful\n")
+	p.Promptf("Proceed? ")
+	if out.String() != "Proceed? " {
+		t.Errorf("Quiet should only let prompts through, got %q", out.String())
+	}
+	if errOut.Len() != 0 {
+		t.Errorf("Quiet should drop warnings, got %q", errOut.String())
+	}
+}
+
+func TestPrinterFromContext(t *testing.T) {
+	if PrinterFrom(context.Background()) != Default() {
+		t.Error("Expected the default printer without one on the context")
+	}
+
+	var out bytes.Buffer
+	p := NewPrinter(&out, &out, false)
+	PrinterFrom(WithPrinter(context.Background(), p)).Println("hi")
+	if out.String() != "hi\n" {
+		t.Errorf("Expected the context's printer to be used, got %q", out.String())
+	}
+}
+
+func TestPrinterVerbose(t *testing.T) {
+	var out, errOut bytes.Buffer
+	p := NewPrinter(&out, &errOut, false)
+	p.Verbosef("detail\n")
+	if errOut.Len() != 0 {
+		t.Errorf("Verbose output without verbose: %q", errOut.String())
+	}
+
+	p.SetVerbose(true)
+	p.Verbosef("detail\n")
+	if errOut.String() != "detail\n" || out.Len() != 0 {
+		t.Errorf("Verbose output should go to the error stream, got %q / %q", out.String(), errOut.String())
+	}
+
+	errOut.Reset()
+	p = NewPrinter(&out, &errOut, true)
+	p.SetVerbose(true)
+	p.Verbosef("detail\n")
+	if p.Verbose() || errOut.Len() != 0 {
+		t.Errorf("Quiet should win over verbose, got %q", errOut.String())
+	}
+}
@@ -1,12 +1,15 @@
 // ABOUTME: Interactive prompt UI functions for user input
-// ABOUTME: Handles multi-select lists and yes/no confirmations
+// ABOUTME: Handles multi-select lists, yes/no confirmations, and hidden input
 package ui
 
 import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -48,13 +51,107 @@ func SelectFromList(prompt string, items []string) ([]string, error) {
 	return selected, nil
 }
 
+// ChooseMany asks the user to pick any number of options and returns their
+// indexes in order. The options at the defaults indexes start out picked,
+// and are the answer with --yes. Uses gum when it is installed; when stdin
+// isn't a terminal, a line of comma-separated numbers is read.
+func ChooseMany(prompt string, options []string, defaults []int) ([]int, error) {
+	if config.YesFlag || len(options) == 0 {
+		return defaults, nil
+	}
+
+	picked := make([]string, len(defaults))
+	for i, d := range defaults {
+		picked[i] = options[d]
+	}
+
+	var chosen []string
+	switch {
+	case useGum():
+		args := []string{"choose", "--no-limit", "--header", prompt}
+		if len(picked) > 0 {
+			args = append(args, "--selected", strings.Join(picked, ","))
+		}
+		output, err := runGum(append(args, options...)...)
+		if err != nil {
+			return nil, err
+		}
+		if output != "" {
+			chosen = strings.Split(output, "\n")
+		}
+
+	case !stdinIsTerminal():
+		return chooseManyPlain(prompt, options, defaults)
+
+	default:
+		err := survey.AskOne(&survey.MultiSelect{
+			Message: prompt,
+			Options: options,
+			Default: picked,
+			Help:    "↑/↓ move, space toggle, enter confirm",
+		}, &chosen)
+		if err != nil {
+			if err == terminal.InterruptErr {
+				return nil, ErrUserCancelled
+			}
+			return nil, err
+		}
+	}
+
+	var indexes []int
+	for i, o := range options {
+		if slices.Contains(chosen, o) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
+
+// chooseManyPlain reads comma-separated option numbers; an empty line keeps
+// the defaults and "none" picks nothing
+func chooseManyPlain(prompt string, options []string, defaults []int) ([]int, error) {
+	std.Promptf("%s\n", prompt)
+	for i, o := range options {
+		std.Promptf("  %d) %s\n", i+1, o)
+	}
+	var def []string
+	for _, d := range defaults {
+		def = append(def, strconv.Itoa(d+1))
+	}
+	std.Promptf("Choices [%s]: ", strings.Join(def, ","))
+
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	input = strings.TrimSpace(input)
+	switch {
+	case input == "" && err != nil && err != io.EOF:
+		return nil, err
+	case input == "":
+		return defaults, nil
+	case strings.EqualFold(input, "none"):
+		return nil, nil
+	}
+
+	var indexes []int
+	for _, field := range strings.Split(input, ",") {
+		n, convErr := strconv.Atoi(strings.TrimSpace(field))
+		if convErr != nil || n < 1 || n > len(options) {
+			return nil, fmt.Errorf("invalid choice %q: enter numbers from 1 to %d", field, len(options))
+		}
+		if !slices.Contains(indexes, n-1) {
+			indexes = append(indexes, n-1)
+		}
+	}
+	slices.Sort(indexes)
+	return indexes, nil
+}
+
 // ConfirmYesNo prompts for Y/n confirmation
 func ConfirmYesNo(prompt string) (bool, error) {
 	if config.YesFlag {
 		return true, nil
 	}
 
-	fmt.Printf("%s [Y/n]: ", prompt)
+	std.Promptf("%s [Y/n]: ", prompt)
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -70,3 +167,28 @@ func ConfirmYesNo(prompt string) (bool, error) {
 
 	return false, nil
 }
+
+// PromptSecret asks for a value without echoing it, through gum when it is
+// installed. When stdin isn't a terminal, a line is read as is.
+func PromptSecret(prompt string) (string, error) {
+	if useGum() {
+		return runGum("input", "--password", "--header", prompt)
+	}
+	if !stdinIsTerminal() {
+		std.Promptf("%s: ", prompt)
+		input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && input == "" {
+			return "", err
+		}
+		return strings.TrimSpace(input), nil
+	}
+
+	var value string
+	if err := survey.AskOne(&survey.Password{Message: prompt + ":"}, &value); err != nil {
+		if err == terminal.InterruptErr {
+			return "", ErrUserCancelled
+		}
+		return "", err
+	}
+	return strings.TrimSpace(value), nil
+}
@@ -96,3 +96,20 @@ func TestConfirmYesNo_WithYesFlag(t *testing.T) {
 		t.Error("expected confirmed to be true when YesFlag is set")
 	}
 }
+
+func TestChooseMany_WithYesFlag(t *testing.T) {
+	// Save and restore original flag value
+	originalFlag := config.YesFlag
+	defer func() { config.YesFlag = originalFlag }()
+
+	config.YesFlag = true
+
+	chosen, err := ChooseMany("Pick:", []string{"a", "b", "c"}, []int{0, 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(chosen) != 2 || chosen[0] != 0 || chosen[1] != 2 {
+		t.Errorf("expected the defaults [0 2], got %v", chosen)
+	}
+}
@@ -0,0 +1,174 @@
+// ABOUTME: Posts apply and drift events to webhooks configured in ~/.claudeup/config.json
+// ABOUTME: Bodies are the event as JSON or rendered from a per-webhook Go template
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/history"
+	"github.com/claudeup/claudeup/internal/telemetry"
+)
+
+// Event names
+const (
+	EventApplyStart   = "apply-start"
+	EventApplySuccess = "apply-success"
+	EventApplyFailed  = "apply-failed"
+	EventDrift        = "drift"
+	EventTest         = "test"
+)
+
+// Events lists the events a webhook can subscribe to
+var Events = []string{EventApplyStart, EventApplySuccess, EventApplyFailed, EventDrift}
+
+// Event is what a webhook receives, and the data its template is rendered with
+type Event struct {
+	Event   string            `json:"event"`
+	Profile string            `json:"profile,omitempty"`
+	Source  string            `json:"source,omitempty"` // cli, retry, bundle, mcp, or schedule
+	Changes int               `json:"changes"`
+	Failed  []history.Failure `json:"failed,omitempty"` // changes that failed to apply
+	Drift   int               `json:"drift,omitempty"`  // items changed since the profile was applied
+	Error   string            `json:"error,omitempty"`
+	Host    string            `json:"host"`
+	Version string            `json:"claudeupVersion,omitempty"`
+	Time    time.Time         `json:"time"`
+}
+
+// Subscribed reports whether hook wants event. The test event goes to every
+// webhook.
+func Subscribed(hook config.Webhook, event string) bool {
+	return event == EventTest || len(hook.Events) == 0 || slices.Contains(hook.Events, event)
+}
+
+// Validate checks a webhook's URL, events, and template
+func Validate(hook config.Webhook) error {
+	if !strings.HasPrefix(hook.URL, "https://") && !strings.HasPrefix(hook.URL, "http://") {
+		return fmt.Errorf("webhook URL %q must start with https:// or http://", hook.URL)
+	}
+	for _, e := range hook.Events {
+		if !slices.Contains(Events, e) {
+			return fmt.Errorf("webhook %s: unknown event %q (want one of %s)", hook.URL, e, strings.Join(Events, ", "))
+		}
+	}
+	if _, err := parse(hook); err != nil {
+		return fmt.Errorf("webhook %s: %w", hook.URL, err)
+	}
+	return nil
+}
+
+// Render returns the request body for e
+func Render(hook config.Webhook, e Event) ([]byte, error) {
+	tmpl, err := parse(hook)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil {
+		return json.Marshal(e)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, e); err != nil {
+		return nil, fmt.Errorf("template failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func parse(hook config.Webhook) (*template.Template, error) {
+	if hook.Template == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		// json renders a value as a JSON literal, so strings are quoted and escaped
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(hook.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Send posts e to every webhook subscribed to it, in parallel, and returns
+// the failures joined. Each delivery is a span of the trace in ctx, if any.
+func Send(ctx context.Context, client *http.Client, hooks []config.Webhook, e Event) error {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.Host == "" {
+		e.Host, _ = os.Hostname()
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, hook := range hooks {
+		if !Subscribed(hook, e.Event) {
+			continue
+		}
+		wg.Add(1)
+		go func(hook config.Webhook) {
+			defer wg.Done()
+			// Only the host: webhook URLs often carry their credentials
+			host := hook.URL
+			if u, err := url.Parse(hook.URL); err == nil {
+				host = u.Host
+			}
+			ctx, span := telemetry.Start(ctx, "webhook "+e.Event, telemetry.String("server.address", host))
+			err := post(ctx, client, hook, e)
+			span.End(err)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("webhook %s: %w", hook.URL, err))
+				mu.Unlock()
+			}
+		}(hook)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func post(ctx context.Context, client *http.Client, hook config.Webhook, e Event) error {
+	body, err := Render(hook, e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Claudeup-Event", e.Event)
+	for k, v := range hook.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
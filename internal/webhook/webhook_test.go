@@ -0,0 +1,111 @@
+// ABOUTME: Tests for posting events to configured webhooks
+// ABOUTME: Covers event filtering, templates, header expansion, and failed deliveries
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/history"
+)
+
+type received struct {
+	path  string
+	event string
+	auth  string
+	body  string
+}
+
+func recordingServer(t *testing.T) (*httptest.Server, func() []received) {
+	var (
+		mu   sync.Mutex
+		reqs []received
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		reqs = append(reqs, received{path: r.URL.Path, event: r.Header.Get("X-Claudeup-Event"), auth: r.Header.Get("Authorization"), body: string(body)})
+		mu.Unlock()
+		if r.URL.Path == "/broken" {
+			http.Error(w, "nope", http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []received {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]received(nil), reqs...)
+	}
+}
+
+func TestSendFiltersAndRenders(t *testing.T) {
+	t.Setenv("EVENTS_TOKEN", "s3cret")
+	srv, requests := recordingServer(t)
+	hooks := []config.Webhook{
+		{URL: srv.URL + "/all", Headers: map[string]string{"Authorization": "Bearer $EVENTS_TOKEN"}},
+		{URL: srv.URL + "/failures", Events: []string{EventApplyFailed}},
+		{URL: srv.URL + "/template", Events: []string{EventApplySuccess}, Template: `{"text": {{json .Profile}}, "changes": {{.Changes}}}`},
+	}
+
+	err := Send(context.Background(), nil, hooks, Event{Event: EventApplySuccess, Profile: `say "hi"`, Source: "cli", Changes: 2})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	got := map[string]received{}
+	for _, r := range requests() {
+		got[r.path] = r
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 deliveries, got %+v", got)
+	}
+	all := got["/all"]
+	var e Event
+	if err := json.Unmarshal([]byte(all.body), &e); err != nil || e.Profile != `say "hi"` || e.Host == "" || e.Time.IsZero() {
+		t.Errorf("Expected the event as JSON, got %s (%v)", all.body, err)
+	}
+	if all.auth != "Bearer s3cret" || all.event != EventApplySuccess {
+		t.Errorf("Expected headers set, got auth %q event %q", all.auth, all.event)
+	}
+	if body := got["/template"].body; body != `{"text": "say \"hi\"", "changes": 2}` {
+		t.Errorf("Unexpected templated body %s", body)
+	}
+}
+
+func TestSendReportsFailures(t *testing.T) {
+	srv, _ := recordingServer(t)
+	hooks := []config.Webhook{{URL: srv.URL + "/broken"}, {URL: srv.URL + "/ok"}}
+
+	err := Send(context.Background(), nil, hooks, Event{Event: EventApplyFailed, Failed: []history.Failure{{Action: "install", Subsystem: "plugins", Name: "a@m"}}})
+	if err == nil || !strings.Contains(err.Error(), "/broken") || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected the failed delivery reported, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		hook config.Webhook
+		want string
+	}{
+		{config.Webhook{URL: "https://example.com", Events: []string{EventDrift}}, ""},
+		{config.Webhook{URL: "example.com"}, "must start with"},
+		{config.Webhook{URL: "https://example.com", Events: []string{"deploy"}}, "unknown event"},
+		{config.Webhook{URL: "https://example.com", Template: "{{.Profile"}, "invalid template"},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.hook)
+		if tt.want == "" && err != nil {
+			t.Errorf("Validate(%+v) = %v, want nil", tt.hook, err)
+		}
+		if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("Validate(%+v) = %v, want %q", tt.hook, err, tt.want)
+		}
+	}
+}
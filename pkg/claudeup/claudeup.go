@@ -0,0 +1,202 @@
+// ABOUTME: Public Go API for embedding claudeup profile management in other tools
+// ABOUTME: Wraps the internal profile, state, and secrets packages behind a Client
+package claudeup
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/claudeup/claudeup/internal/config"
+	"github.com/claudeup/claudeup/internal/pathctx"
+	"github.com/claudeup/claudeup/internal/profile"
+)
+
+// Profile is a saved set of plugins, MCP servers, and marketplaces
+type Profile = profile.Profile
+
+// MCPServer is an MCP server entry in a profile
+type MCPServer = profile.MCPServer
+
+// Marketplace is a marketplace entry in a profile
+type Marketplace = profile.Marketplace
+
+// Diff describes what applying a profile would change
+type Diff = profile.Diff
+
+// ApplyResult describes what applying a profile changed
+type ApplyResult = profile.ApplyResult
+
+// CommandExecutor runs claude CLI commands on behalf of Apply
+type CommandExecutor = profile.CommandExecutor
+
+// CapturingExecutor returns an executor that runs the real claude CLI with
+// its output captured instead of attached to the terminal
+func CapturingExecutor() CommandExecutor {
+	return &profile.CapturingExecutor{}
+}
+
+// Options configures a Client. Zero values fall back to the same
+// defaults the claudeup CLI uses.
+type Options struct {
+	// ClaudeDir is the Claude Code configuration directory (default: $CLAUDE_CONFIG_DIR or ~/.claude)
+	ClaudeDir string
+
+	// ClaudeJSONPath is the path to .claude.json (default: next to ClaudeDir's parent)
+	ClaudeJSONPath string
+
+	// ProfilesDir is where user profiles are stored (default: ~/.claudeup/profiles)
+	ProfilesDir string
+
+	// ProfileNamespaces are searched in order for a bare profile name, such
+	// as team for team/backend (default: the profileNamespaces preference)
+	ProfileNamespaces []string
+
+	// Executor runs the claude CLI (default: the real claude binary)
+	Executor CommandExecutor
+
+	// Secrets resolves secret references in MCP server env (default: DefaultSecrets)
+	Secrets *SecretChain
+
+	// ReadOnly makes Apply and SaveProfile fail with ErrReadOnly, as on
+	// shared machines whose configuration is managed centrally
+	ReadOnly bool
+}
+
+// ErrReadOnly is returned by methods that change configuration when the
+// client is read-only
+var ErrReadOnly = errors.New("claudeup is read-only on this machine")
+
+// Client manages Claude Code configuration for a single installation
+type Client struct {
+	opts Options
+}
+
+// New returns a Client with defaults filled in for any unset options
+func New(opts Options) (*Client, error) {
+	if opts.ClaudeDir == "" || opts.ClaudeJSONPath == "" || opts.ProfilesDir == "" {
+		paths, err := defaultPaths()
+		if err != nil {
+			return nil, err
+		}
+		if opts.ClaudeDir == "" {
+			opts.ClaudeDir = paths.ClaudeDir
+		}
+		if opts.ClaudeJSONPath == "" {
+			opts.ClaudeJSONPath = paths.ClaudeJSON
+		}
+		if opts.ProfilesDir == "" {
+			opts.ProfilesDir = paths.ProfilesDir
+		}
+	}
+	if opts.ProfileNamespaces == nil {
+		if cfg, err := config.LoadExisting(); err == nil {
+			opts.ProfileNamespaces = cfg.Preferences.ProfileNamespaces
+		}
+	}
+	if opts.Executor == nil {
+		opts.Executor = &profile.DefaultExecutor{}
+	}
+	if opts.Secrets == nil {
+		chain, err := DefaultSecrets()
+		if err != nil {
+			return nil, err
+		}
+		opts.Secrets = chain
+	}
+	return &Client{opts: opts}, nil
+}
+
+// defaultPaths returns the paths the CLI uses, including any it has
+// overridden, failing when the home directory they're under is unknown
+func defaultPaths() (pathctx.Paths, error) {
+	paths := pathctx.Default()
+	if paths.Home == "" {
+		return paths, errors.New("cannot determine home directory")
+	}
+	return paths, nil
+}
+
+// Options returns the resolved options used by the client
+func (c *Client) Options() Options {
+	return c.opts
+}
+
+// LoadProfile loads a user profile, falling back to the built-in profiles.
+// A bare name is looked up in the ProfileNamespaces when it isn't saved
+// outside a namespace.
+func (c *Client) LoadProfile(ctx context.Context, name string) (*Profile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	name, err := profile.ResolveName(c.opts.ProfilesDir, name, c.opts.ProfileNamespaces)
+	if err != nil {
+		return nil, err
+	}
+	p, err := profile.Load(c.opts.ProfilesDir, name)
+	if err == nil {
+		return p, nil
+	}
+	if embedded, embErr := profile.GetEmbeddedProfile(name); embErr == nil {
+		return embedded, nil
+	}
+	return nil, err
+}
+
+// ListProfiles returns the user profiles stored in ProfilesDir
+func (c *Client) ListProfiles(ctx context.Context) ([]*Profile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	profiles, err := profile.List(c.opts.ProfilesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// SaveProfile writes a profile to ProfilesDir
+func (c *Client) SaveProfile(ctx context.Context, p *Profile) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	return profile.Save(c.opts.ProfilesDir, p)
+}
+
+// Snapshot captures the current Claude Code state as a profile
+func (c *Client) Snapshot(ctx context.Context, name string) (*Profile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return profile.Snapshot(name, c.opts.ClaudeDir, c.opts.ClaudeJSONPath)
+}
+
+// Diff computes what applying p would change
+func (c *Client) Diff(ctx context.Context, p *Profile) (*Diff, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return profile.ComputeDiff(p, c.opts.ClaudeDir, c.opts.ClaudeJSONPath)
+}
+
+// Apply makes the Claude Code state match p. Cancelling ctx stops the running
+// claude CLI command and skips the rest; the returned result lists what
+// finished. The saved profile in ProfilesDir records when and where it was
+// last applied.
+func (c *Client) Apply(ctx context.Context, p *Profile) (*ApplyResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if c.opts.ReadOnly {
+		return nil, ErrReadOnly
+	}
+	result, err := profile.ApplyWithExecutor(ctx, p, c.opts.ClaudeDir, c.opts.ClaudeJSONPath, c.opts.Secrets, c.opts.Executor)
+	if err == nil {
+		// Best-effort; the apply itself succeeded
+		_ = profile.RecordApplied(c.opts.ProfilesDir, p.Name)
+	}
+	return result, err
+}
@@ -0,0 +1,184 @@
+// ABOUTME: Tests for the public claudeup library API
+// ABOUTME: Exercises the Client against a temporary Claude installation
+package claudeup
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/claudeup/claudeup/internal/pathctx"
+)
+
+type recordingExecutor struct {
+	calls [][]string
+}
+
+func (e *recordingExecutor) Run(ctx context.Context, args ...string) error {
+	e.calls = append(e.calls, args)
+	return nil
+}
+
+func (e *recordingExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
+	e.calls = append(e.calls, args)
+	return "", nil
+}
+
+func newTestClient(t *testing.T) (*Client, *recordingExecutor) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	if err := os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestJSON(t, filepath.Join(claudeDir, "plugins", "installed_plugins.json"), map[string]interface{}{
+		"version": 2,
+		"plugins": map[string]interface{}{},
+	})
+	writeTestJSON(t, filepath.Join(claudeDir, "plugins", "known_marketplaces.json"), map[string]interface{}{})
+	writeTestJSON(t, filepath.Join(tmpDir, ".claude.json"), map[string]interface{}{"mcpServers": map[string]interface{}{}})
+
+	executor := &recordingExecutor{}
+	client, err := New(Options{
+		ClaudeDir:      claudeDir,
+		ClaudeJSONPath: filepath.Join(tmpDir, ".claude.json"),
+		ProfilesDir:    filepath.Join(tmpDir, "profiles"),
+		Executor:       executor,
+		Secrets:        NewSecretChain(EnvResolver()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, executor
+}
+
+func TestClientSaveLoadAndDiff(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	p := &Profile{Name: "lib", Plugins: []string{"tool@market"}}
+	if err := client.SaveProfile(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := client.LoadProfile(ctx, "lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := client.Diff(ctx, loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.PluginsToInstall) != 1 || diff.PluginsToInstall[0] != "tool@market" {
+		t.Errorf("Expected tool@market to be installed, got %v", diff.PluginsToInstall)
+	}
+
+	profiles, err := client.ListProfiles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 {
+		t.Errorf("Expected 1 profile, got %d", len(profiles))
+	}
+}
+
+func TestClientLoadProfileSearchesNamespaces(t *testing.T) {
+	client, _ := newTestClient(t)
+	client.opts.ProfileNamespaces = []string{"personal", "team"}
+	ctx := context.Background()
+	for _, name := range []string{"team/backend", "personal/backend"} {
+		if err := client.SaveProfile(ctx, &Profile{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := client.LoadProfile(ctx, "backend")
+	if err != nil || p.Name != "personal/backend" {
+		t.Errorf("Expected personal/backend, got %v, %v", p, err)
+	}
+	p, err = client.LoadProfile(ctx, "team/backend")
+	if err != nil || p.Name != "team/backend" {
+		t.Errorf("Expected team/backend, got %v, %v", p, err)
+	}
+}
+
+func TestClientApplyUsesExecutor(t *testing.T) {
+	client, executor := newTestClient(t)
+
+	_, err := client.Apply(context.Background(), &Profile{Name: "lib", Plugins: []string{"tool@market"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(executor.calls) != 1 || executor.calls[0][2] != "tool@market" {
+		t.Errorf("Expected one plugin install, got %v", executor.calls)
+	}
+}
+
+func TestClientReadOnly(t *testing.T) {
+	client, executor := newTestClient(t)
+	client.opts.ReadOnly = true
+	ctx := context.Background()
+
+	if _, err := client.Apply(ctx, &Profile{Name: "lib", Plugins: []string{"tool@market"}}); err != ErrReadOnly {
+		t.Errorf("Expected ErrReadOnly from Apply, got %v", err)
+	}
+	if err := client.SaveProfile(ctx, &Profile{Name: "lib"}); err != ErrReadOnly {
+		t.Errorf("Expected ErrReadOnly from SaveProfile, got %v", err)
+	}
+	if len(executor.calls) != 0 {
+		t.Errorf("Expected no commands, got %v", executor.calls)
+	}
+}
+
+func TestClientApplyHonorsCancelledContext(t *testing.T) {
+	client, executor := newTestClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.Apply(ctx, &Profile{Name: "lib", Plugins: []string{"tool@market"}}); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(executor.calls) != 0 {
+		t.Errorf("Expected no commands after cancellation, got %v", executor.calls)
+	}
+}
+
+func writeTestJSON(t *testing.T, path string, data interface{}) {
+	t.Helper()
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewUsesDefaultPaths(t *testing.T) {
+	paths := pathctx.ForHome(t.TempDir())
+	restore := pathctx.Override(paths)
+	defer restore()
+
+	client, err := New(Options{Executor: &recordingExecutor{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := client.Options()
+	if opts.ClaudeDir != paths.ClaudeDir || opts.ClaudeJSONPath != paths.ClaudeJSON || opts.ProfilesDir != paths.ProfilesDir {
+		t.Errorf("Expected the overridden paths, got %+v", opts)
+	}
+
+	pathctx.Override(pathctx.Paths{})
+	if _, err := New(Options{}); err == nil {
+		t.Error("Expected an error when the home directory is unknown")
+	}
+	if _, err := DefaultSecrets(); err == nil {
+		t.Error("Expected DefaultSecrets to fail when the home directory is unknown")
+	}
+}
@@ -0,0 +1,60 @@
+// ABOUTME: Public secret resolution types for library users
+// ABOUTME: Exposes the resolver chain used when applying MCP server secrets
+package claudeup
+
+import "github.com/claudeup/claudeup/internal/secrets"
+
+// SecretChain tries secret resolvers in order until one succeeds
+type SecretChain = secrets.Chain
+
+// SecretResolver resolves a secret reference to its value
+type SecretResolver = secrets.Resolver
+
+// NewSecretChain returns a chain of the given resolvers
+func NewSecretChain(resolvers ...SecretResolver) *SecretChain {
+	return secrets.NewChain(resolvers...)
+}
+
+// DefaultSecrets returns the chain the CLI uses: env, ~/.claudeup/secrets.env,
+// 1Password, Bitwarden, SOPS files in ~/.claudeup, then keychain
+func DefaultSecrets() (*SecretChain, error) {
+	paths, err := defaultPaths()
+	if err != nil {
+		return nil, err
+	}
+	return secrets.NewChain(
+		secrets.NewEnvResolver(),
+		secrets.NewEnvFileResolver(paths.Claudeup("secrets.env")),
+		secrets.NewOnePasswordResolver(),
+		secrets.NewBitwardenResolver(),
+		secrets.NewSopsResolver(paths.ClaudeupDir),
+		secrets.NewKeychainResolver(),
+	), nil
+}
+
+// EnvResolver resolves secrets from environment variables
+func EnvResolver() SecretResolver {
+	return secrets.NewEnvResolver()
+}
+
+// OnePasswordResolver resolves op:// references with the 1Password CLI
+func OnePasswordResolver() SecretResolver {
+	return secrets.NewOnePasswordResolver()
+}
+
+// BitwardenResolver resolves bw://<item>/<field> references with the
+// Bitwarden CLI
+func BitwardenResolver() SecretResolver {
+	return secrets.NewBitwardenResolver()
+}
+
+// SopsResolver resolves sops://<file>#<key> references by decrypting
+// files with the sops CLI. Relative files are found in dir.
+func SopsResolver(dir string) SecretResolver {
+	return secrets.NewSopsResolver(dir)
+}
+
+// KeychainResolver resolves secrets from the macOS keychain
+func KeychainResolver() SecretResolver {
+	return secrets.NewKeychainResolver()
+}
@@ -0,0 +1,84 @@
+// ABOUTME: Acceptance tests for CLAUDE_CONFIG_DIR across commands
+// ABOUTME: Puts the only Claude installation in the override and checks each command finds it
+package acceptance
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/test/helpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CLAUDE_CONFIG_DIR", func() {
+	var (
+		env      *helpers.TestEnv
+		override string
+		vars     []string
+	)
+
+	writeFile := func(path, content string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		env = helpers.NewTestEnv(binaryPath)
+		override = filepath.Join(env.TempDir, "alt-claude")
+
+		// ~/.claude is empty; the override has a plugin whose path is gone
+		// and an MCP server of its own
+		writeFile(filepath.Join(override, "plugins", "installed_plugins.json"),
+			`{"version": 2, "plugins": {"ghost@acme": [{"scope": "user", "version": "1.0.0", "installPath": "/nonexistent/ghost"}]}}`)
+		writeFile(filepath.Join(override, "plugins", "known_marketplaces.json"), `{}`)
+		writeFile(filepath.Join(override, ".claude.json"), `{"mcpServers": {"alt-db": {"command": "pg-mcp"}}}`)
+
+		// A claude CLI that accepts every command without doing anything
+		bin := filepath.Join(env.TempDir, "bin")
+		helpers.FakeClaude(bin)
+
+		vars = []string{"CLAUDE_CONFIG_DIR=" + override, "PATH=" + bin + string(os.PathListSeparator) + os.Getenv("PATH")}
+	})
+
+	It("is used by doctor", func() {
+		result := env.RunWithEnv(vars, "doctor")
+		Expect(result.Stdout).To(ContainSubstring("ghost@acme"))
+	})
+
+	It("is used by status", func() {
+		result := env.RunWithEnv(vars, "status")
+		Expect(result.ExitCode).To(Equal(0))
+		Expect(result.Stdout).To(ContainSubstring("ghost@acme"))
+	})
+
+	It("is used by cleanup", func() {
+		result := env.RunWithEnv(vars, "cleanup", "--dry-run")
+		Expect(result.ExitCode).To(Equal(0))
+		Expect(result.Stdout).To(ContainSubstring("ghost@acme"))
+	})
+
+	It("is used by profile save", func() {
+		result := env.RunWithEnv(vars, "profile", "save", "alt")
+		Expect(result.ExitCode).To(Equal(0))
+		saved := env.LoadProfile("alt")
+		Expect(saved.Plugins).To(ContainElement("ghost@acme"))
+		Expect(saved.MCPServers).To(HaveLen(1))
+		Expect(saved.MCPServers[0].Name).To(Equal("alt-db"))
+	})
+
+	It("is used by profile use and its stale plugin cleanup", func() {
+		env.CreateProfile(&profile.Profile{Name: "mcp-only", MCPServers: []profile.MCPServer{{Name: "alt-db", Command: "pg-mcp"}}})
+
+		result := env.RunWithEnv(vars, "profile", "use", "mcp-only", "-y")
+		Expect(result.ExitCode).To(Equal(0))
+		Expect(result.Stdout).To(ContainSubstring("ghost@acme"))
+		Expect(result.Stdout).To(ContainSubstring("Cleaned up 1 stale plugin entries"))
+	})
+
+	It("gives way to --claude-dir", func() {
+		result := env.RunWithEnv(vars, "cleanup", "--dry-run", "--claude-dir", env.ClaudeDir)
+		Expect(result.Stdout).NotTo(ContainSubstring("ghost@acme"))
+	})
+})
@@ -0,0 +1,70 @@
+// ABOUTME: Acceptance tests for behavior that differs between Windows and Unix-like systems
+// ABOUTME: Runs on every CI platform; Windows checks the home directory and PowerShell defaults
+package acceptance
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/test/helpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("platform paths and shells", func() {
+	var env *helpers.TestEnv
+
+	BeforeEach(func() {
+		env = helpers.NewTestEnv(binaryPath)
+		env.CreateClaudeSettings()
+		plugins := filepath.Join(env.ClaudeDir, "plugins")
+		Expect(os.MkdirAll(plugins, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(plugins, "installed_plugins.json"), []byte(`{"version": 2, "plugins": {}}`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(plugins, "known_marketplaces.json"), []byte(`{}`), 0644)).To(Succeed())
+		env.CreateProfile(&profile.Profile{
+			Name:     "team",
+			ShellEnv: profile.ShellEnvConfig{Env: map[string]string{"AWS_PROFILE": "it's dev"}},
+		})
+	})
+
+	It("keeps claudeup's files in the home directory", func() {
+		result := env.Run("snapshot", "take", "before")
+		Expect(result.ExitCode).To(Equal(0), result.Stderr)
+
+		snapshots, err := filepath.Glob(filepath.Join(env.ClaudeupDir, "snapshots", "*.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshots).To(HaveLen(1))
+	})
+
+	It("expands ~ in workspace paths to the home directory", func() {
+		work := "~/work"
+		if runtime.GOOS == "windows" {
+			work = `~\work`
+		}
+		Expect(env.Run("workspace", "add", work, "team").ExitCode).To(Equal(0))
+		project := filepath.Join(env.TempDir, "work", "api")
+		Expect(os.MkdirAll(project, 0755)).To(Succeed())
+
+		result := env.RunInDir(project, "workspace", "list")
+		Expect(result.ExitCode).To(Equal(0), result.Stderr)
+		Expect(result.Stdout).To(MatchRegexp(`\* ~.work +→ team`))
+	})
+
+	It("prints PowerShell assignments", func() {
+		result := env.Run("env", "team", "--shell", "powershell")
+		Expect(result.ExitCode).To(Equal(0), result.Stderr)
+		Expect(result.Stdout).To(ContainSubstring(`$env:AWS_PROFILE = 'it''s dev'`))
+	})
+
+	It("uses PowerShell syntax on Windows without $SHELL", func() {
+		result := env.RunWithEnv([]string{"SHELL="}, "env", "team")
+		Expect(result.ExitCode).To(Equal(0), result.Stderr)
+		if runtime.GOOS == "windows" {
+			Expect(result.Stdout).To(ContainSubstring("$env:AWS_PROFILE = "))
+		} else {
+			Expect(result.Stdout).To(ContainSubstring("export AWS_PROFILE="))
+		}
+	})
+})
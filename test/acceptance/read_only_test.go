@@ -0,0 +1,70 @@
+// ABOUTME: Acceptance tests for read-only mode on shared machines
+// ABOUTME: Mutating commands fail with a clear message while read-only ones keep working
+package acceptance
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/claudeup/claudeup/internal/profile"
+	"github.com/claudeup/claudeup/test/helpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("read-only mode", func() {
+	var (
+		env      *helpers.TestEnv
+		readOnly []string
+	)
+
+	BeforeEach(func() {
+		env = helpers.NewTestEnv(binaryPath)
+		env.CreateClaudeSettings()
+		plugins := filepath.Join(env.ClaudeDir, "plugins")
+		Expect(os.MkdirAll(plugins, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(plugins, "installed_plugins.json"), []byte(`{"version": 2, "plugins": {}}`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(plugins, "known_marketplaces.json"), []byte(`{}`), 0644)).To(Succeed())
+		env.CreateProfile(&profile.Profile{Name: "team", Plugins: []string{"tool@acme"}})
+		readOnly = []string{"CLAUDEUP_READONLY=1"}
+	})
+
+	It("keeps read-only commands working", func() {
+		Expect(env.RunWithEnv(readOnly, "profile", "list").ExitCode).To(Equal(0))
+		Expect(env.RunWithEnv(readOnly, "profile", "show", "team").ExitCode).To(Equal(0))
+		Expect(env.RunWithEnv(readOnly, "cleanup", "--dry-run").ExitCode).To(Equal(0))
+
+		result := env.RunWithEnv(readOnly, "profile", "use", "team", "--diff-format", "json")
+		Expect(result.ExitCode).To(Equal(0))
+		Expect(result.Stdout).To(ContainSubstring("tool@acme"))
+	})
+
+	DescribeTable("refuses mutating commands",
+		func(args ...string) {
+			result := env.RunWithEnv(readOnly, args...)
+			Expect(result.ExitCode).NotTo(Equal(0))
+			Expect(result.Stderr).To(ContainSubstring("claudeup is read-only (CLAUDEUP_READONLY is set)"))
+		},
+		Entry("profile use", "profile", "use", "team", "-y"),
+		Entry("profile save", "profile", "save", "mine"),
+		Entry("cleanup", "cleanup", "-y"),
+		Entry("update", "update"),
+		Entry("setup", "setup", "-y"),
+	)
+
+	It("leaves the profiles alone", func() {
+		env.RunWithEnv(readOnly, "profile", "save", "mine")
+		Expect(env.ProfileExists("mine")).To(BeFalse())
+	})
+
+	It("can be turned on in the config file", func() {
+		Expect(os.MkdirAll(filepath.Dir(env.ConfigFile), 0755)).To(Succeed())
+		Expect(os.WriteFile(env.ConfigFile, []byte(`{"preferences": {"readOnly": true}}`), 0644)).To(Succeed())
+
+		result := env.Run("profile", "save", "mine")
+		Expect(result.ExitCode).NotTo(Equal(0))
+		Expect(result.Stderr).To(ContainSubstring("readOnly is set in"))
+
+		Expect(env.RunWithEnv([]string{"CLAUDEUP_READONLY=0"}, "profile", "save", "mine").ExitCode).To(Equal(0))
+	})
+})
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/claudeup/claudeup/internal/profile"
@@ -52,10 +53,37 @@ func (e *TestEnv) Run(args ...string) *Result {
 
 // RunWithInput executes the CLI with stdin input
 func (e *TestEnv) RunWithInput(input string, args ...string) *Result {
+	return e.run("", input, nil, args...)
+}
+
+// RunWithEnv executes the CLI with extra environment variables, given as
+// KEY=value, taking precedence over the test environment's own
+func (e *TestEnv) RunWithEnv(env []string, args ...string) *Result {
+	return e.run("", "", env, args...)
+}
+
+// RunInDir executes the CLI from the working directory dir
+func (e *TestEnv) RunInDir(dir string, args ...string) *Result {
+	return e.run(dir, "", nil, args...)
+}
+
+func (e *TestEnv) run(dir, input string, env []string, args ...string) *Result {
 	cmd := exec.Command(e.Binary, args...)
+	cmd.Dir = dir
 	cmd.Env = append(os.Environ(),
 		"HOME="+e.TempDir,
+		// Windows finds the home directory in USERPROFILE, not HOME
+		"USERPROFILE="+e.TempDir,
+		// The machine's own Claude directory override doesn't leak in
+		"CLAUDE_CONFIG_DIR=",
+		// Nor does read-only mode set for the machine
+		"CLAUDEUP_READONLY=",
+		// A Claude Code running on the machine doesn't touch the temp HOME
+		"CLAUDEUP_IGNORE_RUNNING_CLAUDE=1",
+		// Assertions match English output whatever the machine's locale
+		"CLAUDEUP_LANG=en",
 	)
+	cmd.Env = append(cmd.Env, env...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -121,6 +149,17 @@ func (e *TestEnv) SetActiveProfile(name string) {
 	Expect(os.WriteFile(e.ConfigFile, data, 0644)).To(Succeed())
 }
 
+// FakeClaude writes a claude CLI into dir that accepts every command
+// without doing anything: a shell script, or a batch file on Windows
+func FakeClaude(dir string) {
+	Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+	if runtime.GOOS == "windows" {
+		Expect(os.WriteFile(filepath.Join(dir, "claude.cmd"), []byte("@exit /b 0\r\n"), 0644)).To(Succeed())
+		return
+	}
+	Expect(os.WriteFile(filepath.Join(dir, "claude"), []byte("#!/bin/sh\nexit 0\n"), 0755)).To(Succeed())
+}
+
 // CreateClaudeSettings creates a fake claude.json settings file
 func (e *TestEnv) CreateClaudeSettings() {
 	settingsPath := filepath.Join(e.TempDir, ".claude.json")
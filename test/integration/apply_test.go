@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -31,7 +32,7 @@ func NewMockExecutor() *MockExecutor {
 	}
 }
 
-func (m *MockExecutor) Run(args ...string) error {
+func (m *MockExecutor) Run(ctx context.Context, args ...string) error {
 	m.Commands = append(m.Commands, args)
 
 	// Check if we should return an error
@@ -42,7 +43,7 @@ func (m *MockExecutor) Run(args ...string) error {
 	return nil
 }
 
-func (m *MockExecutor) RunWithOutput(args ...string) (string, error) {
+func (m *MockExecutor) RunWithOutput(ctx context.Context, args ...string) (string, error) {
 	m.Commands = append(m.Commands, args)
 
 	// Check if we should return an error or custom output
@@ -166,7 +167,7 @@ var _ = Describe("ApplyInstallsPlugins", func() {
 		executor := NewMockExecutor()
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		result, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		result, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(executor.HasCommand("plugin", "install", "plugin-a@marketplace")).To(BeTrue(), "Expected plugin install command. Commands: %v", executor.Commands)
@@ -195,7 +196,7 @@ var _ = Describe("ApplyRemovesPlugins", func() {
 		executor := NewMockExecutor()
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		result, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		result, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(executor.HasCommand("plugin", "uninstall", "plugin-b@marketplace")).To(BeTrue(), "Expected plugin uninstall command for plugin-b. Commands: %v", executor.Commands)
@@ -226,7 +227,7 @@ var _ = Describe("ApplyAddsMCPServers", func() {
 		executor := NewMockExecutor()
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		result, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		result, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(executor.HasCommand("mcp", "add", "test-mcp")).To(BeTrue(), "Expected mcp add command. Commands: %v", executor.Commands)
@@ -259,7 +260,7 @@ var _ = Describe("ApplyRemovesMCPServers", func() {
 		executor := NewMockExecutor()
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		result, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		result, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(executor.HasCommand("mcp", "remove", "old-mcp")).To(BeTrue(), "Expected mcp remove command. Commands: %v", executor.Commands)
@@ -285,7 +286,7 @@ var _ = Describe("ApplyAddsMarketplaces", func() {
 		executor := NewMockExecutor()
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		result, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		result, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(executor.HasCommand("plugin", "marketplace", "add")).To(BeTrue(), "Expected marketplace add command. Commands: %v", executor.Commands)
@@ -328,7 +329,7 @@ var _ = Describe("ApplyWithSecrets", func() {
 		executor := NewMockExecutor()
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		result, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		result, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(result.MCPServersInstalled).To(HaveLen(1))
@@ -375,7 +376,7 @@ var _ = Describe("ApplyMissingSecretFails", func() {
 		executor := NewMockExecutor()
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		_, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		_, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).To(HaveOccurred())
 	})
 })
@@ -403,7 +404,7 @@ var _ = Describe("ApplyCommandOrder", func() {
 		executor := NewMockExecutor()
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		_, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		_, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).NotTo(HaveOccurred())
 
 		uninstallIdx := -1
@@ -456,7 +457,7 @@ var _ = Describe("ApplyPluginAlreadyUninstalled", func() {
 
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		result, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		result, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(result.PluginsAlreadyRemoved).To(HaveLen(1))
@@ -483,7 +484,7 @@ var _ = Describe("ApplyPluginAlreadyInstalled", func() {
 
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		result, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		result, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(result.PluginsAlreadyPresent).To(HaveLen(1))
@@ -511,7 +512,7 @@ var _ = Describe("ApplyAllProfilePluginsAttempted", func() {
 		executor := NewMockExecutor()
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		result, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		result, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(executor.HasCommand("plugin", "install", "plugin-a@marketplace")).To(BeTrue(), "Expected install attempt for plugin-a even though it's in JSON")
@@ -539,7 +540,7 @@ var _ = Describe("ApplyPluginInstallRealError", func() {
 
 		chain := secrets.NewChain(secrets.NewEnvResolver())
 
-		result, err := profile.ApplyWithExecutor(p, env.claudeDir, env.claudeJSON, chain, executor)
+		result, err := profile.ApplyWithExecutor(context.Background(), p, env.claudeDir, env.claudeJSON, chain, executor)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(result.Errors).To(HaveLen(1))